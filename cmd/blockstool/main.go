@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/oklog/ulid"
+	"github.com/weaveworks/common/logging"
+	"gopkg.in/yaml.v2"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/cortexproject/cortex/tools/blockstool"
+)
+
+const usage = `%s is a tool to inspect the blocks in a Cortex bucket and mark them for deletion or no compaction.
+Please see %s for instructions on how to run it.
+
+Usage: %s [flags] <command>
+
+Commands:
+  list             List the blocks of the tenant.
+  check-index      Verify the integrity of the index of the block, or of all the blocks of the tenant if no block is given.
+  mark-deletion    Mark the block of the tenant for deletion.
+  mark-no-compact  Mark the block of the tenant for no compaction.
+  usage            Print the storage usage of the tenant, or of all the tenants if no tenant is given.
+  migrate-layout   Copy all the objects of the bucket to the target storage prefix and tenant layout.
+
+`
+
+func main() {
+	var (
+		configFilename string
+		userID         string
+		blockID        string
+		details        string
+		dataDir        string
+		cfg            bucket.Config
+
+		targetStoragePrefix string
+		targetTenantLayout  string
+	)
+
+	logfmt, loglvl := logging.Format{}, logging.Level{}
+	logfmt.RegisterFlags(flag.CommandLine)
+	loglvl.RegisterFlags(flag.CommandLine)
+	cfg.RegisterFlags(flag.CommandLine)
+	flag.StringVar(&configFilename, "config", "", "Path to bucket config YAML")
+	flag.StringVar(&userID, "user", "", "Tenant whose blocks should be inspected or marked")
+	flag.StringVar(&blockID, "block", "", "ID of the block to check or mark")
+	flag.StringVar(&details, "details", "", "Details to store in the deletion or no-compact mark")
+	flag.StringVar(&dataDir, "data-dir", os.TempDir(), "Directory where the block indexes are downloaded to be checked")
+	flag.StringVar(&targetStoragePrefix, "target.storage-prefix", "", "Storage prefix of the bucket the objects are copied to by migrate-layout")
+	flag.StringVar(&targetTenantLayout, "target.tenant-layout", bucket.TenantLayoutFlat, "Tenant layout of the bucket the objects are copied to by migrate-layout")
+	flag.IntVar(&cfg.TenantLayoutDepth, "tenant-layout-depth", 0, "Number of path segments before the tenant ID in the object keys: 0 for the blocks storage, 1 for the ruler and alertmanager storages")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0], "https://cortexmetrics.io/docs/blocks-storage/bucket-inspection/", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "Flags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command := flag.Arg(0)
+
+	logger, err := log.NewPrometheusLogger(loglvl, logfmt)
+	if err != nil {
+		fatal("failed to create logger: %v", err)
+	}
+
+	if configFilename != "" {
+		buf, err := os.ReadFile(configFilename)
+		if err != nil {
+			fatal("failed to load config file from %s: %v", configFilename, err)
+		}
+		err = yaml.UnmarshalStrict(buf, &cfg)
+		if err != nil {
+			fatal("failed to parse config file: %v", err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fatal("bucket config is invalid: %v", err)
+	}
+
+	ctx := context.Background()
+
+	tool, err := blockstool.NewBlocksTool(ctx, cfg, logger)
+	if err != nil {
+		fatal("couldn't initialize the blocks tool: %v", err)
+	}
+
+	switch command {
+	case "list":
+		requireUser(userID)
+		blocks, err := tool.ListBlocks(ctx, userID)
+		if err != nil {
+			fatal("failed to list blocks: %v", err)
+		}
+		printBlocks(blocks)
+
+	case "check-index":
+		requireUser(userID)
+		var ids []ulid.ULID
+		if blockID != "" {
+			ids = append(ids, parseBlockID(blockID))
+		} else {
+			blocks, err := tool.ListBlocks(ctx, userID)
+			if err != nil {
+				fatal("failed to list blocks: %v", err)
+			}
+			for _, b := range blocks {
+				if b.DeletionTime.IsZero() {
+					ids = append(ids, b.ID)
+				}
+			}
+		}
+
+		failed := 0
+		for _, id := range ids {
+			if err := tool.CheckIndex(ctx, userID, id, dataDir); err != nil {
+				fmt.Printf("%s: FAILED: %v\n", id, err)
+				failed++
+				continue
+			}
+			fmt.Printf("%s: OK\n", id)
+		}
+		if failed > 0 {
+			fatal("%d of %d blocks failed the index check", failed, len(ids))
+		}
+
+	case "mark-deletion":
+		requireUser(userID)
+		if err := tool.MarkForDeletion(ctx, userID, parseBlockID(blockID), details); err != nil {
+			fatal("failed to mark block for deletion: %v", err)
+		}
+		fmt.Printf("Block %s of user %s marked for deletion\n", blockID, userID)
+
+	case "mark-no-compact":
+		requireUser(userID)
+		if err := tool.MarkForNoCompact(ctx, userID, parseBlockID(blockID), details); err != nil {
+			fatal("failed to mark block for no compaction: %v", err)
+		}
+		fmt.Printf("Block %s of user %s marked for no compaction\n", blockID, userID)
+
+	case "usage":
+		var userIDs []string
+		if userID != "" {
+			userIDs = append(userIDs, userID)
+		}
+		tenantsUsage, err := tool.Usage(ctx, userIDs)
+		if err != nil {
+			fatal("failed to compute usage: %v", err)
+		}
+		printUsage(tenantsUsage)
+
+	case "migrate-layout":
+		targetCfg := cfg
+		targetCfg.StoragePrefix = targetStoragePrefix
+		targetCfg.TenantLayout = targetTenantLayout
+		if err := targetCfg.Validate(); err != nil {
+			fatal("target bucket config is invalid: %v", err)
+		}
+		if targetCfg.StoragePrefix == cfg.StoragePrefix && targetCfg.TenantLayout == cfg.TenantLayout {
+			fatal("the target storage prefix and tenant layout are the same as the source ones")
+		}
+
+		src, err := bucket.NewClient(ctx, cfg, "blockstool", logger, nil)
+		if err != nil {
+			fatal("failed to create the source bucket client: %v", err)
+		}
+		dst, err := bucket.NewClient(ctx, targetCfg, "blockstool", logger, nil)
+		if err != nil {
+			fatal("failed to create the target bucket client: %v", err)
+		}
+
+		copied, err := blockstool.MigrateLayout(ctx, src, dst, cfg.TenantLayoutDepth, logger)
+		if err != nil {
+			fatal("failed to migrate the layout after copying %d objects: %v", copied, err)
+		}
+		fmt.Printf("Copied %d objects\n", copied)
+
+	default:
+		fatal("unknown command %q, supported commands: list, check-index, mark-deletion, mark-no-compact, usage, migrate-layout", command)
+	}
+}
+
+func printBlocks(blocks []blockstool.BlockInfo) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BLOCK\tMIN TIME\tMAX TIME\tLEVEL\tSERIES\tSAMPLES\tSIZE BYTES\tDELETION TIME\tNO COMPACT")
+	for _, b := range blocks {
+		deletionTime := ""
+		if !b.DeletionTime.IsZero() {
+			deletionTime = b.DeletionTime.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\t%t\n", b.ID, formatMillis(b.MinTime), formatMillis(b.MaxTime), b.CompactionLevel, b.NumSeries, b.NumSamples, b.SizeBytes, deletionTime, b.NoCompact)
+	}
+	w.Flush() //nolint:errcheck
+}
+
+func printUsage(tenantsUsage map[string]blockstool.TenantUsage) {
+	userIDs := make([]string, 0, len(tenantsUsage))
+	for userID := range tenantsUsage {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "USER\tBLOCKS\tBLOCKS MARKED FOR DELETION\tSERIES\tSAMPLES\tSIZE BYTES")
+	for _, userID := range userIDs {
+		u := tenantsUsage[userID]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\n", userID, u.NumBlocks, u.NumBlocksForDeletion, u.NumSeries, u.NumSamples, u.SizeBytes)
+	}
+	w.Flush() //nolint:errcheck
+}
+
+func formatMillis(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
+
+func requireUser(userID string) {
+	if strings.TrimSpace(userID) == "" {
+		fatal("the -user flag is required")
+	}
+}
+
+func parseBlockID(blockID string) ulid.ULID {
+	id, err := ulid.Parse(blockID)
+	if err != nil {
+		fatal("invalid block ID %q: %v", blockID, err)
+	}
+	return id
+}
+
+func fatal(msg string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, msg+"\n", args...)
+	os.Exit(1)
+}
@@ -80,9 +80,11 @@ func newAlertmanagerClientsPool(discovery client.PoolServiceDiscovery, amClientC
 	}
 
 	poolCfg := client.PoolConfig{
-		CheckInterval:      time.Minute,
-		HealthCheckEnabled: true,
-		HealthCheckTimeout: 10 * time.Second,
+		CheckInterval:          time.Minute,
+		HealthCheckEnabled:     true,
+		HealthCheckTimeout:     10 * time.Second,
+		CheckIntervalJitter:    0.2,
+		HealthCheckConcurrency: 16,
 	}
 
 	clientsCount := promauto.With(reg).NewGauge(prometheus.GaugeOpts{
@@ -43,11 +43,14 @@ var SyncRingOp = ring.NewOp([]ring.InstanceState{ring.ACTIVE, ring.JOINING}, fun
 // is used to strip down the config to the minimum, and avoid confusion
 // to the user.
 type RingConfig struct {
-	KVStore              kv.Config     `yaml:"kvstore" doc:"description=The key-value store used to share the hash ring across multiple instances."`
-	HeartbeatPeriod      time.Duration `yaml:"heartbeat_period"`
-	HeartbeatTimeout     time.Duration `yaml:"heartbeat_timeout"`
-	ReplicationFactor    int           `yaml:"replication_factor"`
-	ZoneAwarenessEnabled bool          `yaml:"zone_awareness_enabled"`
+	KVStore                      kv.Config     `yaml:"kvstore" doc:"description=The key-value store used to share the hash ring across multiple instances."`
+	HeartbeatPeriod              time.Duration `yaml:"heartbeat_period"`
+	HeartbeatTimeout             time.Duration `yaml:"heartbeat_timeout"`
+	ReplicationFactor            int           `yaml:"replication_factor"`
+	ZoneAwarenessEnabled         bool          `yaml:"zone_awareness_enabled"`
+	ZoneAwarenessMinSuccessZones int           `yaml:"zone_awareness_min_success_zones"`
+	TokensFilePath               string        `yaml:"tokens_file_path"`
+	TokensGeneratorStrategy      string        `yaml:"tokens_generator_strategy"`
 
 	FinalSleep               time.Duration `yaml:"final_sleep"`
 	WaitInstanceStateTimeout time.Duration `yaml:"wait_instance_state_timeout"`
@@ -85,6 +88,9 @@ func (cfg *RingConfig) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&cfg.FinalSleep, rfprefix+"final-sleep", 0*time.Second, "The sleep seconds when alertmanager is shutting down. Need to be close to or larger than KV Store information propagation delay")
 	f.IntVar(&cfg.ReplicationFactor, rfprefix+"replication-factor", 3, "The replication factor to use when sharding the alertmanager.")
 	f.BoolVar(&cfg.ZoneAwarenessEnabled, rfprefix+"zone-awareness-enabled", false, "True to enable zone-awareness and replicate alerts across different availability zones.")
+	f.IntVar(&cfg.ZoneAwarenessMinSuccessZones, rfprefix+"zone-awareness-min-success-zones", 0, "[Experimental] Minimum number of zones the alertmanager state must be replicated to, for the replication to succeed, when zone-awareness is enabled. 0 means the replication succeeds once replicated to a single alertmanager.")
+	f.StringVar(&cfg.TokensFilePath, rfprefix+"tokens-file-path", "", "File path where tokens are stored. If empty, tokens are not stored at shutdown and restored at startup.")
+	ring.RegisterTokensGeneratorStrategyFlag(f, rfprefix+"tokens-generator-strategy", &cfg.TokensGeneratorStrategy)
 
 	// Instance flags
 	cfg.InstanceInterfaceNames = []string{"eth0", "en0"}
@@ -111,13 +117,14 @@ func (cfg *RingConfig) ToLifecyclerConfig(logger log.Logger) (ring.BasicLifecycl
 	instancePort := ring.GetInstancePort(cfg.InstancePort, cfg.ListenPort)
 
 	return ring.BasicLifecyclerConfig{
-		ID:                  cfg.InstanceID,
-		Addr:                fmt.Sprintf("%s:%d", instanceAddr, instancePort),
-		HeartbeatPeriod:     cfg.HeartbeatPeriod,
-		TokensObservePeriod: 0,
-		Zone:                cfg.InstanceZone,
-		NumTokens:           RingNumTokens,
-		FinalSleep:          cfg.FinalSleep,
+		ID:                      cfg.InstanceID,
+		Addr:                    fmt.Sprintf("%s:%d", instanceAddr, instancePort),
+		HeartbeatPeriod:         cfg.HeartbeatPeriod,
+		TokensObservePeriod:     0,
+		Zone:                    cfg.InstanceZone,
+		NumTokens:               RingNumTokens,
+		TokensGeneratorStrategy: cfg.TokensGeneratorStrategy,
+		FinalSleep:              cfg.FinalSleep,
 	}, nil
 }
 
@@ -129,6 +136,16 @@ func (cfg *RingConfig) ToRingConfig() ring.Config {
 	rc.HeartbeatTimeout = cfg.HeartbeatTimeout
 	rc.ReplicationFactor = cfg.ReplicationFactor
 	rc.ZoneAwarenessEnabled = cfg.ZoneAwarenessEnabled
+	rc.ZoneAwarenessMinSuccessZones = cfg.ZoneAwarenessMinSuccessZones
 
 	return rc
 }
+
+// toDoBatchOptions returns the options used to replicate the alertmanager state
+// to the alertmanagers of the ring.
+func (cfg *RingConfig) toDoBatchOptions() ring.DoBatchOptions {
+	return ring.DoBatchOptions{
+		ZoneQuorum:      cfg.ZoneAwarenessEnabled && cfg.ZoneAwarenessMinSuccessZones > 0,
+		MinSuccessZones: cfg.ZoneAwarenessMinSuccessZones,
+	}
+}
@@ -53,3 +53,33 @@ func TestIsHealthyForAlertmanagerOperations(t *testing.T) {
 		})
 	}
 }
+
+func TestRingConfig_ToDoBatchOptions(t *testing.T) {
+	tests := map[string]struct {
+		zoneAwarenessEnabled bool
+		minSuccessZones      int
+		expected             ring.DoBatchOptions
+	}{
+		"zone-awareness disabled": {
+			minSuccessZones: 2,
+			expected:        ring.DoBatchOptions{MinSuccessZones: 2},
+		},
+		"zone-awareness enabled without min success zones": {
+			zoneAwarenessEnabled: true,
+			expected:             ring.DoBatchOptions{},
+		},
+		"zone-awareness enabled with min success zones": {
+			zoneAwarenessEnabled: true,
+			minSuccessZones:      2,
+			expected:             ring.DoBatchOptions{ZoneQuorum: true, MinSuccessZones: 2},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			cfg := RingConfig{ZoneAwarenessEnabled: testData.zoneAwarenessEnabled, ZoneAwarenessMinSuccessZones: testData.minSuccessZones}
+			assert.Equal(t, testData.expected, cfg.toDoBatchOptions())
+			assert.Equal(t, testData.minSuccessZones, cfg.ToRingConfig().ZoneAwarenessMinSuccessZones)
+		})
+	}
+}
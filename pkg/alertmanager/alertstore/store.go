@@ -62,6 +62,9 @@ func NewAlertStore(ctx context.Context, cfg Config, cfgProvider bucket.TenantCon
 		return local.NewStore(cfg.Local)
 	}
 
+	// The full state of each tenant is stored under alertmanager/<tenant>/.
+	cfg.Config.TenantLayoutDepth = 1
+
 	bucketClient, err := bucket.NewClient(ctx, cfg.Config, "alertmanager-storage", logger, reg)
 	if err != nil {
 		return nil, err
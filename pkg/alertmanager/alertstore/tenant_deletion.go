@@ -0,0 +1,65 @@
+package alertstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/thanos-io/objstore"
+
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+)
+
+// TenantDeletionAlertStore is an AlertStore hiding the alertmanager configuration of the tenants
+// marked for deletion in the blocks storage, so that their alertmanager is stopped. The
+// alertmanager configuration of a tenant is deleted once the grace period has elapsed since
+// the tenant has been marked for deletion.
+type TenantDeletionAlertStore struct {
+	AlertStore
+
+	bucketClient objstore.InstrumentedBucket
+	gracePeriod  time.Duration
+	logger       log.Logger
+}
+
+func NewTenantDeletionAlertStore(store AlertStore, bucketClient objstore.InstrumentedBucket, gracePeriod time.Duration, logger log.Logger) *TenantDeletionAlertStore {
+	return &TenantDeletionAlertStore{
+		AlertStore:   store,
+		bucketClient: bucketClient,
+		gracePeriod:  gracePeriod,
+		logger:       logger,
+	}
+}
+
+// ListAllUsers implements AlertStore.
+func (s *TenantDeletionAlertStore) ListAllUsers(ctx context.Context) ([]string, error) {
+	users, err := s.AlertStore.ListAllUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	marks, err := cortex_tsdb.ReadTenantDeletionMarks(ctx, s.bucketClient)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]string, 0, len(users))
+	for _, userID := range users {
+		mark, ok := marks[userID]
+		if !ok {
+			filtered = append(filtered, userID)
+			continue
+		}
+
+		if time.Since(time.Unix(mark.DeletionTime, 0)) < s.gracePeriod {
+			continue
+		}
+		if err := s.AlertStore.DeleteAlertConfig(ctx, userID); err != nil {
+			level.Warn(s.logger).Log("msg", "failed to delete alertmanager configuration of tenant marked for deletion", "user", userID, "err", err)
+			continue
+		}
+		level.Info(s.logger).Log("msg", "deleted alertmanager configuration of tenant marked for deletion", "user", userID)
+	}
+	return filtered, nil
+}
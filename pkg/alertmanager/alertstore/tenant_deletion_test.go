@@ -0,0 +1,38 @@
+package alertstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/alertmanager/alertspb"
+	"github.com/cortexproject/cortex/pkg/alertmanager/alertstore/bucketclient"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+)
+
+func TestTenantDeletionAlertStore_ListAllUsers(t *testing.T) {
+	ctx := context.Background()
+	blocksBucket := objstore.WithNoopInstr(objstore.NewInMemBucket())
+	store := NewTenantDeletionAlertStore(bucketclient.NewBucketAlertStore(objstore.NewInMemBucket(), nil, log.NewNopLogger()), blocksBucket, time.Hour, log.NewNopLogger())
+
+	for _, userID := range []string{"user-1", "user-2", "user-3"} {
+		require.NoError(t, store.SetAlertConfig(ctx, alertspb.AlertConfigDesc{User: userID, RawConfig: "content"}))
+	}
+	require.NoError(t, cortex_tsdb.WriteTenantDeletionMark(ctx, blocksBucket, "user-2", cortex_tsdb.NewTenantDeletionMark(time.Now())))
+	require.NoError(t, cortex_tsdb.WriteTenantDeletionMark(ctx, blocksBucket, "user-3", cortex_tsdb.NewTenantDeletionMark(time.Now().Add(-2*time.Hour))))
+
+	users, err := store.ListAllUsers(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user-1"}, users)
+
+	// The configuration of the tenant marked for deletion is only deleted after the grace period.
+	_, err = store.GetAlertConfig(ctx, "user-2")
+	require.NoError(t, err)
+	_, err = store.GetAlertConfig(ctx, "user-3")
+	require.ErrorIs(t, err, alertspb.ErrNotFound)
+}
@@ -1542,6 +1542,43 @@ func TestMultitenantAlertmanager_RingLifecyclerShouldAutoForgetUnhealthyInstance
 	})
 }
 
+func TestMultitenantAlertmanager_RingLifecyclerShouldRestoreTheTokensFromFile(t *testing.T) {
+	ctx := context.Background()
+	amConfig := mockAlertmanagerConfig(t)
+	amConfig.ShardingEnabled = true
+	amConfig.ShardingRing.TokensFilePath = filepath.Join(t.TempDir(), "tokens")
+
+	alertStore := prepareInMemoryAlertStore()
+
+	// Start the alertmanager with an empty ring, so that new tokens are generated and stored to file.
+	ringStore, closer := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	am, err := createMultitenantAlertmanager(amConfig, nil, nil, alertStore, ringStore, nil, log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, am))
+	require.NoError(t, services.StopAndAwaitTerminated(ctx, am))
+
+	tokenFile, err := ring.LoadTokenFile(amConfig.ShardingRing.TokensFilePath)
+	require.NoError(t, err)
+	require.Len(t, tokenFile.Tokens, RingNumTokens)
+
+	// Restart the alertmanager with a new ring, where the instance doesn't exist anymore.
+	newRingStore, newCloser := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, newCloser.Close()) })
+
+	am, err = createMultitenantAlertmanager(amConfig, nil, nil, alertStore, newRingStore, nil, log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, am))
+	defer services.StopAndAwaitTerminated(ctx, am) //nolint:errcheck
+
+	d, err := newRingStore.Get(ctx, RingKey)
+	require.NoError(t, err)
+	instance, ok := ring.GetOrCreateRingDesc(d).Ingesters[amConfig.ShardingRing.InstanceID]
+	require.True(t, ok)
+	assert.Equal(t, tokenFile.Tokens, ring.Tokens(instance.Tokens))
+}
+
 func TestMultitenantAlertmanager_InitialSyncFailureWithSharding(t *testing.T) {
 	ctx := context.Background()
 	amConfig := mockAlertmanagerConfig(t)
@@ -0,0 +1,284 @@
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gorilla/mux"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/tenant"
+	"github.com/cortexproject/cortex/pkg/util"
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+// uploadingMetaFilename is the meta.json of a block being uploaded. The meta.json of the block
+// is only uploaded once the upload of the block files has been completed and validated, so that
+// the block isn't picked up before.
+const uploadingMetaFilename = "uploading-meta.json"
+
+var chunksFilenameRegex = regexp.MustCompile(`^chunks/\d{6}$`)
+
+// StartBlockUpload starts the upload of a block of the tenant, given the meta.json of the block
+// in the request body.
+func (c *Compactor) StartBlockUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	blockID, userID, userBkt, ok := c.prepareBlockUpload(w, r)
+	if !ok {
+		return
+	}
+	logger := log.With(util_log.WithUserID(userID, c.logger), "block", blockID)
+
+	if !c.checkBlockNotExists(ctx, w, userBkt, blockID) {
+		return
+	}
+
+	meta, err := metadata.Read(r.Body)
+	if err != nil {
+		http.Error(w, errors.Wrap(err, "decode meta.json").Error(), http.StatusBadRequest)
+		return
+	}
+	if err := c.sanitizeUploadedBlockMeta(meta, blockID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := uploadBlockMeta(ctx, userBkt, path.Join(blockID.String(), uploadingMetaFilename), meta); err != nil {
+		level.Error(logger).Log("msg", "failed to start block upload", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	level.Info(logger).Log("msg", "started block upload", "min_time", meta.MinTime, "max_time", meta.MaxTime)
+	w.WriteHeader(http.StatusOK)
+}
+
+// UploadBlockFile uploads a file of a block whose upload has been started. The path of the file,
+// relative to the block location, is given by the path URL query parameter.
+func (c *Compactor) UploadBlockFile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	blockID, userID, userBkt, ok := c.prepareBlockUpload(w, r)
+	if !ok {
+		return
+	}
+	logger := log.With(util_log.WithUserID(userID, c.logger), "block", blockID)
+
+	filename := r.URL.Query().Get("path")
+	if filename != block.IndexFilename && !chunksFilenameRegex.MatchString(filename) {
+		http.Error(w, fmt.Sprintf("invalid block file path %q", filename), http.StatusBadRequest)
+		return
+	}
+
+	if !c.checkBlockNotExists(ctx, w, userBkt, blockID) {
+		return
+	}
+	if _, ok := c.readUploadingBlockMeta(ctx, w, userBkt, blockID, logger); !ok {
+		return
+	}
+
+	if err := userBkt.Upload(ctx, path.Join(blockID.String(), filename), r.Body); err != nil {
+		level.Error(logger).Log("msg", "failed to upload block file", "path", filename, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	level.Debug(logger).Log("msg", "uploaded block file", "path", filename)
+	w.WriteHeader(http.StatusOK)
+}
+
+// FinishBlockUpload validates the files of a block whose upload has been started, and completes
+// the upload by uploading the meta.json of the block.
+func (c *Compactor) FinishBlockUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	blockID, userID, userBkt, ok := c.prepareBlockUpload(w, r)
+	if !ok {
+		return
+	}
+	logger := log.With(util_log.WithUserID(userID, c.logger), "block", blockID)
+
+	if !c.checkBlockNotExists(ctx, w, userBkt, blockID) {
+		return
+	}
+	meta, ok := c.readUploadingBlockMeta(ctx, w, userBkt, blockID, logger)
+	if !ok {
+		return
+	}
+
+	files, err := c.validateUploadedBlock(ctx, userBkt, userID, meta, logger)
+	if err != nil {
+		level.Warn(logger).Log("msg", "uploaded block is invalid", "err", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	meta.Thanos.Files = files
+
+	if err := uploadBlockMeta(ctx, userBkt, path.Join(blockID.String(), metadata.MetaFilename), meta); err != nil {
+		level.Error(logger).Log("msg", "failed to finish block upload", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := userBkt.Delete(ctx, path.Join(blockID.String(), uploadingMetaFilename)); err != nil {
+		level.Warn(logger).Log("msg", "failed to delete the meta.json of the uploading block", "err", err)
+	}
+
+	level.Info(logger).Log("msg", "finished block upload")
+	w.WriteHeader(http.StatusOK)
+}
+
+// prepareBlockUpload returns the block ID, the tenant and the tenant's bucket of a block upload
+// request, or writes the error response and returns false if the request can't be served.
+func (c *Compactor) prepareBlockUpload(w http.ResponseWriter, r *http.Request) (ulid.ULID, string, objstore.InstrumentedBucket, bool) {
+	if c.State() != services.Running {
+		http.Error(w, "compactor is not running", http.StatusServiceUnavailable)
+		return ulid.ULID{}, "", nil, false
+	}
+
+	userID, err := tenant.TenantID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return ulid.ULID{}, "", nil, false
+	}
+
+	if !c.limits.CompactorBlockUploadEnabled(userID) {
+		http.Error(w, "block upload is disabled for the tenant", http.StatusForbidden)
+		return ulid.ULID{}, "", nil, false
+	}
+
+	blockID, err := ulid.Parse(mux.Vars(r)["block"])
+	if err != nil {
+		http.Error(w, errors.Wrap(err, "invalid block ID").Error(), http.StatusBadRequest)
+		return ulid.ULID{}, "", nil, false
+	}
+
+	return blockID, userID, bucket.NewUserBucketClient(userID, c.bucketClient, c.limits), true
+}
+
+func (c *Compactor) checkBlockNotExists(ctx context.Context, w http.ResponseWriter, userBkt objstore.InstrumentedBucket, blockID ulid.ULID) bool {
+	exists, err := userBkt.Exists(ctx, path.Join(blockID.String(), metadata.MetaFilename))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if exists {
+		http.Error(w, "block already exists", http.StatusConflict)
+		return false
+	}
+	return true
+}
+
+func (c *Compactor) readUploadingBlockMeta(ctx context.Context, w http.ResponseWriter, userBkt objstore.InstrumentedBucket, blockID ulid.ULID, logger log.Logger) (*metadata.Meta, bool) {
+	r, err := userBkt.WithExpectedErrs(userBkt.IsObjNotFoundErr).Get(ctx, path.Join(blockID.String(), uploadingMetaFilename))
+	if userBkt.IsObjNotFoundErr(err) {
+		http.Error(w, "block upload not started", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	meta, err := metadata.Read(r)
+	if err != nil {
+		level.Error(logger).Log("msg", "failed to read the meta.json of the uploading block", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return meta, true
+}
+
+// sanitizeUploadedBlockMeta validates the meta.json of a block to upload, and sets the Cortex
+// external labels of the tenant's blocks.
+func (c *Compactor) sanitizeUploadedBlockMeta(meta *metadata.Meta, blockID ulid.ULID, userID string) error {
+	if meta.ULID != blockID {
+		return fmt.Errorf("block ID %s of meta.json doesn't match the block ID of the request", meta.ULID)
+	}
+	if meta.MinTime < 0 || meta.MinTime >= meta.MaxTime {
+		return fmt.Errorf("invalid block time range [%d, %d)", meta.MinTime, meta.MaxTime)
+	}
+	if meta.MaxTime > util.TimeToMillis(time.Now()) {
+		return fmt.Errorf("block max time %d is in the future", meta.MaxTime)
+	}
+
+	// The compactor doesn't compact blocks crossing the boundaries of the block ranges.
+	largestRange := c.compactorCfg.BlockRanges[len(c.compactorCfg.BlockRanges)-1]
+	if meta.MinTime/largestRange.Milliseconds() != (meta.MaxTime-1)/largestRange.Milliseconds() {
+		return fmt.Errorf("block time range [%d, %d) crosses the boundary of the largest block range %s", meta.MinTime, meta.MaxTime, largestRange)
+	}
+
+	for name, value := range meta.Thanos.Labels {
+		if name != cortex_tsdb.TenantIDExternalLabel || value != userID {
+			return fmt.Errorf("unsupported external label %s=%q", name, value)
+		}
+	}
+	if meta.Thanos.Downsample.Resolution != 0 {
+		return errors.New("downsampled blocks are not supported")
+	}
+	for _, f := range meta.Thanos.Files {
+		if f.RelPath != block.IndexFilename && f.RelPath != metadata.MetaFilename && !chunksFilenameRegex.MatchString(f.RelPath) {
+			return fmt.Errorf("invalid block file path %q", f.RelPath)
+		}
+	}
+
+	meta.Thanos.Version = metadata.ThanosVersion1
+	meta.Thanos.Labels = map[string]string{cortex_tsdb.TenantIDExternalLabel: userID}
+	meta.Thanos.Source = metadata.BucketUploadSource
+	if meta.Compaction.Level < 1 {
+		meta.Compaction.Level = 1
+	}
+	if len(meta.Compaction.Sources) == 0 {
+		meta.Compaction.Sources = []ulid.ULID{blockID}
+	}
+	return nil
+}
+
+// validateUploadedBlock downloads the files of an uploaded block, verifies its index, and
+// returns the stats of the block files.
+func (c *Compactor) validateUploadedBlock(ctx context.Context, userBkt objstore.InstrumentedBucket, userID string, meta *metadata.Meta, logger log.Logger) ([]metadata.File, error) {
+	dir := filepath.Join(c.compactorCfg.DataDir, "block-upload", userID, meta.ULID.String())
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, errors.Wrap(err, "clean up the working directory")
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			level.Warn(logger).Log("msg", "failed to remove the working directory", "dir", dir, "err", err)
+		}
+	}()
+
+	if err := objstore.DownloadDir(ctx, logger, userBkt, meta.ULID.String(), meta.ULID.String(), dir, objstore.WithDownloadIgnoredPaths(uploadingMetaFilename)); err != nil {
+		return nil, errors.Wrap(err, "download block")
+	}
+
+	if err := block.VerifyIndex(ctx, logger, filepath.Join(dir, block.IndexFilename), meta.MinTime, meta.MaxTime); err != nil {
+		return nil, errors.Wrap(err, "verify block index")
+	}
+
+	// The stats of the block files include the meta.json.
+	if err := meta.WriteToDir(logger, dir); err != nil {
+		return nil, errors.Wrap(err, "write meta.json")
+	}
+	return block.GatherFileStats(dir, metadata.NoneFunc, logger)
+}
+
+func uploadBlockMeta(ctx context.Context, userBkt objstore.Bucket, name string, meta *metadata.Meta) error {
+	var buf bytes.Buffer
+	if err := meta.Write(&buf); err != nil {
+		return errors.Wrap(err, "encode meta.json")
+	}
+	return errors.Wrap(userBkt.Upload(ctx, name, &buf), "upload meta.json")
+}
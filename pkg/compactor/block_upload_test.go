@@ -0,0 +1,145 @@
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/oklog/ulid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/weaveworks/common/user"
+
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	cortex_storage_testutil "github.com/cortexproject/cortex/pkg/storage/tsdb/testutil"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestCompactor_BlockUpload(t *testing.T) {
+	const userID = "user-1"
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+
+	// Create the block to upload in another bucket.
+	srcBkt, _ := cortex_storage_testutil.PrepareFilesystemBucket(t)
+	blockID := createTSDBBlock(t, srcBkt, "source", 10, 20, nil)
+	readSourceFile := func(name string) []byte {
+		r, err := srcBkt.Get(ctx, path.Join("source", blockID.String(), name))
+		require.NoError(t, err)
+		defer r.Close()
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return content
+	}
+
+	var srcMeta metadata.Meta
+	require.NoError(t, json.Unmarshal(readSourceFile(metadata.MetaFilename), &srcMeta))
+
+	bkt, _ := cortex_storage_testutil.PrepareFilesystemBucket(t)
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.CompactorBlockUploadEnabled = true
+
+	c, _, _, _, _ := prepare(t, prepareConfig(), bkt, limits)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), c))
+	})
+
+	request := func(handler http.HandlerFunc, id ulid.ULID, params url.Values, body []byte) int {
+		req := httptest.NewRequest(http.MethodPost, "/?"+params.Encode(), bytes.NewReader(body)).WithContext(ctx)
+		req = mux.SetURLVars(req, map[string]string{"block": id.String()})
+
+		resp := httptest.NewRecorder()
+		handler(resp, req)
+		return resp.Code
+	}
+	encodeMeta := func(meta metadata.Meta) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, meta.Write(&buf))
+		return buf.Bytes()
+	}
+
+	t.Run("invalid meta.json", func(t *testing.T) {
+		for name, mutate := range map[string]func(meta *metadata.Meta){
+			"block ID not matching": func(meta *metadata.Meta) { meta.ULID = ulid.MustNew(1, nil) },
+			"invalid time range":    func(meta *metadata.Meta) { meta.MaxTime = meta.MinTime },
+			"max time in future":    func(meta *metadata.Meta) { meta.MaxTime = util.TimeToMillis(time.Now().Add(time.Hour)) },
+			"crossing block range":  func(meta *metadata.Meta) { meta.MaxTime = (24 * time.Hour).Milliseconds() + 1 },
+			"external labels":       func(meta *metadata.Meta) { meta.Thanos.Labels = map[string]string{"a": "1"} },
+			"downsampled":           func(meta *metadata.Meta) { meta.Thanos.Downsample.Resolution = 300000 },
+			"invalid file":          func(meta *metadata.Meta) { meta.Thanos.Files = []metadata.File{{RelPath: "../index"}} },
+		} {
+			t.Run(name, func(t *testing.T) {
+				meta := srcMeta
+				meta.Thanos.Labels = nil
+				mutate(&meta)
+				assert.Equal(t, http.StatusBadRequest, request(c.StartBlockUpload, blockID, nil, encodeMeta(meta)))
+			})
+		}
+	})
+
+	// The upload must be started before uploading the block files.
+	require.Equal(t, http.StatusNotFound, request(c.UploadBlockFile, blockID, url.Values{"path": {block.IndexFilename}}, readSourceFile(block.IndexFilename)))
+	require.Equal(t, http.StatusNotFound, request(c.FinishBlockUpload, blockID, nil, nil))
+
+	require.Equal(t, http.StatusOK, request(c.StartBlockUpload, blockID, nil, readSourceFile(metadata.MetaFilename)))
+
+	require.Equal(t, http.StatusBadRequest, request(c.UploadBlockFile, blockID, url.Values{"path": {"../index"}}, nil))
+	require.Equal(t, http.StatusBadRequest, request(c.UploadBlockFile, blockID, url.Values{"path": {metadata.MetaFilename}}, nil))
+
+	// The block index is validated.
+	require.Equal(t, http.StatusOK, request(c.UploadBlockFile, blockID, url.Values{"path": {block.IndexFilename}}, []byte("invalid")))
+	require.Equal(t, http.StatusBadRequest, request(c.FinishBlockUpload, blockID, nil, nil))
+
+	for _, name := range []string{block.IndexFilename, "chunks/000001"} {
+		require.Equal(t, http.StatusOK, request(c.UploadBlockFile, blockID, url.Values{"path": {name}}, readSourceFile(name)))
+	}
+	require.Equal(t, http.StatusOK, request(c.FinishBlockUpload, blockID, nil, nil))
+
+	// The block has been uploaded with the Cortex external labels.
+	meta, err := block.DownloadMeta(ctx, c.logger, objstore.NewPrefixedBucket(bkt, userID), blockID)
+	require.NoError(t, err)
+	assert.Equal(t, srcMeta.BlockMeta.Stats, meta.Stats)
+	assert.Equal(t, map[string]string{cortex_tsdb.TenantIDExternalLabel: userID}, meta.Thanos.Labels)
+	assert.Equal(t, metadata.BucketUploadSource, meta.Thanos.Source)
+	assert.Len(t, meta.Thanos.Files, 3)
+
+	exists, err := bkt.Exists(ctx, path.Join(userID, blockID.String(), uploadingMetaFilename))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// The block can't be uploaded again.
+	require.Equal(t, http.StatusConflict, request(c.StartBlockUpload, blockID, nil, readSourceFile(metadata.MetaFilename)))
+	require.Equal(t, http.StatusConflict, request(c.UploadBlockFile, blockID, url.Values{"path": {block.IndexFilename}}, nil))
+}
+
+func TestCompactor_BlockUploadDisabled(t *testing.T) {
+	bkt, _ := cortex_storage_testutil.PrepareFilesystemBucket(t)
+	c, _, _, _, _ := prepare(t, prepareConfig(), bkt, nil)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), c))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}")).WithContext(user.InjectOrgID(context.Background(), "user-1"))
+	req = mux.SetURLVars(req, map[string]string{"block": ulid.MustNew(1, nil).String()})
+	resp := httptest.NewRecorder()
+	c.StartBlockUpload(resp, req)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
@@ -29,16 +29,33 @@ import (
 const (
 	defaultDeleteBlocksConcurrency = 16
 	reasonValueRetention           = "retention"
+	reasonValuePartial             = "partial"
 	activeStatus                   = "active"
 	deletedStatus                  = "deleted"
+
+	// debugDir is the location, in the tenant's bucket, of the debug files written by the compactor.
+	debugDir = "debug"
 )
 
+var errPartialBlockNotStale = errors.New("partial block has been recently modified")
+
 type BlocksCleanerConfig struct {
 	DeletionDelay                      time.Duration
 	CleanupInterval                    time.Duration
 	CleanupConcurrency                 int
 	BlockDeletionMarksMigrationEnabled bool          // TODO Discuss whether we should remove it in Cortex 1.8.0 and document that upgrading to 1.7.0 before 1.8.0 is required.
 	TenantCleanupDelay                 time.Duration // Delay before removing tenant deletion mark and "debug".
+
+	// Series deletion. The series of the deletion requests are deleted from the blocks once the
+	// cancel period has elapsed, unless the cancel period is 0.
+	DeleteRequestCancelPeriod time.Duration
+	BlockRanges               cortex_tsdb.DurationList
+	DataDir                   string
+
+	// Partial blocks and debug files are deleted once not modified for longer than the delay,
+	// unless the delay is 0.
+	PartialBlockDeletionDelay time.Duration
+	DebugFilesDeletionDelay   time.Duration
 }
 
 type BlocksCleaner struct {
@@ -65,6 +82,7 @@ type BlocksCleaner struct {
 	runsLastSuccess                   *prometheus.GaugeVec
 	blocksCleanedTotal                prometheus.Counter
 	blocksFailedTotal                 prometheus.Counter
+	debugFilesCleanedTotal            prometheus.Counter
 	blocksMarkedForDeletion           *prometheus.CounterVec
 	tenantBlocks                      *prometheus.GaugeVec
 	tenantBlocksMarkedForDelete       *prometheus.GaugeVec
@@ -120,6 +138,10 @@ func NewBlocksCleaner(
 			Name: "cortex_compactor_block_cleanup_failures_total",
 			Help: "Total number of blocks failed to be deleted.",
 		}),
+		debugFilesCleanedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_debug_files_cleaned_total",
+			Help: "Total number of stale debug files deleted.",
+		}),
 		blocksMarkedForDeletion: blocksMarkedForDeletion,
 
 		// The following metrics don't have the "cortex_compactor" prefix because not strictly related to
@@ -531,6 +553,17 @@ func (c *BlocksCleaner) cleanUser(ctx context.Context, userLogger log.Logger, us
 		c.applyUserRetentionPeriod(ctx, idx, retention, userBucket, userLogger, userID)
 	}
 
+	// Delete the series of the deletion requests from the blocks. Note doing this before
+	// UpdateIndex, so it reads in the rewritten blocks and the processed requests.
+	if idx != nil && c.cfg.DeleteRequestCancelPeriod > 0 {
+		begin = time.Now()
+		if err := c.deleteSeries(ctx, idx, userBucket, userLogger, userID); err != nil {
+			// We do not want to stop the remaining work in the cleaner.
+			level.Warn(userLogger).Log("msg", "failed to delete series of the deletion requests", "err", err)
+		}
+		level.Info(userLogger).Log("msg", "finish deleting series", "duration", time.Since(begin), "duration_ms", time.Since(begin).Milliseconds())
+	}
+
 	// Generate an updated in-memory version of the bucket index.
 	begin = time.Now()
 	w := bucketindex.NewUpdater(c.bucketClient, userID, c.cfgProvider, c.logger)
@@ -585,6 +618,12 @@ func (c *BlocksCleaner) cleanUser(ctx context.Context, userLogger log.Logger, us
 		level.Info(userLogger).Log("msg", "finish cleaning partial blocks", "duration", time.Since(begin), "duration_ms", time.Since(begin).Milliseconds())
 	}
 
+	// The debug files are only useful to investigate recent issues. This is a best effort, so we
+	// don't return error if the cleanup of debug files fail.
+	if c.cfg.DebugFilesDeletionDelay > 0 {
+		c.cleanUserDebugFiles(ctx, userBucket, userLogger)
+	}
+
 	// Upload the updated index to the storage.
 	begin = time.Now()
 	if err := bucketindex.WriteIndex(ctx, c.bucketClient, userID, c.cfgProvider, idx); err != nil {
@@ -632,11 +671,23 @@ func (c *BlocksCleaner) cleanUserPartialBlocks(ctx context.Context, userID strin
 				}
 				return nil
 			})
-			if isEmpty || notVisitMarkerError != nil {
-				// skip deleting partial block if block directory
-				// is empty or non visit marker file exists
+			if isEmpty {
+				// skip deleting partial block if block directory is empty
 				return nil
 			}
+			if notVisitMarkerError != nil {
+				// skip deleting partial block if non visit marker file exists,
+				// unless the partial block is stale
+				if !c.isStalePartialBlock(ctx, userBucket, blockID, userLogger) {
+					return nil
+				}
+
+				reason := fmt.Sprintf("partial block not modified for longer than %v", c.cfg.PartialBlockDeletionDelay)
+				if err := block.MarkForDeletion(ctx, userLogger, userBucket, blockID, reason, c.blocksMarkedForDeletion.WithLabelValues(userID, reasonValuePartial)); err != nil {
+					level.Warn(userLogger).Log("msg", "failed to mark stale partial block for deletion", "block", blockID, "err", err)
+					return nil
+				}
+			}
 		} else if err != nil {
 			level.Warn(userLogger).Log("msg", "error reading partial block deletion mark", "block", blockID, "err", err)
 			return nil
@@ -663,6 +714,61 @@ func (c *BlocksCleaner) cleanUserPartialBlocks(ctx context.Context, userID strin
 	})
 }
 
+// isStalePartialBlock returns whether none of the files of the partial block has been modified
+// for longer than the partial block deletion delay.
+func (c *BlocksCleaner) isStalePartialBlock(ctx context.Context, userBucket objstore.InstrumentedBucket, blockID ulid.ULID, userLogger log.Logger) bool {
+	if c.cfg.PartialBlockDeletionDelay <= 0 {
+		return false
+	}
+
+	threshold := time.Now().Add(-c.cfg.PartialBlockDeletionDelay)
+	err := userBucket.Iter(ctx, blockID.String(), func(file string) error {
+		attrs, err := userBucket.Attributes(ctx, file)
+		if err != nil {
+			return err
+		}
+		if attrs.LastModified.After(threshold) {
+			return errPartialBlockNotStale
+		}
+		return nil
+	}, objstore.WithRecursiveIter)
+
+	if err != nil && !errors.Is(err, errPartialBlockNotStale) {
+		level.Warn(userLogger).Log("msg", "failed to check the files of partial block", "block", blockID, "err", err)
+	}
+	return err == nil
+}
+
+// cleanUserDebugFiles deletes the debug files not modified for longer than the debug files deletion delay.
+func (c *BlocksCleaner) cleanUserDebugFiles(ctx context.Context, userBucket objstore.InstrumentedBucket, userLogger log.Logger) {
+	threshold := time.Now().Add(-c.cfg.DebugFilesDeletionDelay)
+	deleted := 0
+
+	err := userBucket.Iter(ctx, debugDir, func(file string) error {
+		attrs, err := userBucket.Attributes(ctx, file)
+		if err != nil {
+			return err
+		}
+		if attrs.LastModified.After(threshold) {
+			return nil
+		}
+
+		if err := userBucket.Delete(ctx, file); err != nil {
+			return err
+		}
+		c.debugFilesCleanedTotal.Inc()
+		deleted++
+		return nil
+	}, objstore.WithRecursiveIter)
+
+	if err != nil {
+		level.Warn(userLogger).Log("msg", "failed to delete stale debug files", "err", err)
+	}
+	if deleted > 0 {
+		level.Info(userLogger).Log("msg", "deleted stale debug files", "count", deleted)
+	}
+}
+
 // applyUserRetentionPeriod marks blocks for deletion which have aged past the retention period.
 func (c *BlocksCleaner) applyUserRetentionPeriod(ctx context.Context, idx *bucketindex.Index, retention time.Duration, userBucket objstore.Bucket, userLogger log.Logger, userID string) {
 	// The retention period of zero is a special value indicating to never delete.
@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/rand"
 	"fmt"
+	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -386,6 +388,79 @@ func TestBlocksCleaner_ShouldContinueOnBlockDeletionFailure(t *testing.T) {
 	assert.ElementsMatch(t, []ulid.ULID{block3}, idx.BlockDeletionMarks.GetULIDs())
 }
 
+func TestBlocksCleaner_ShouldDeleteStalePartialBlocksAndDebugFiles(t *testing.T) {
+	const userID = "user-1"
+
+	bucketClient, storageDir := cortex_testutil.PrepareFilesystemBucket(t)
+	bucketClient = bucketindex.BucketWithGlobalMarkers(bucketClient)
+
+	ctx := context.Background()
+	stale := time.Now().Add(-2 * time.Hour)
+	setModTime := func(dir string) {
+		require.NoError(t, filepath.Walk(dir, func(path string, _ os.FileInfo, err error) error {
+			require.NoError(t, err)
+			return os.Chtimes(path, stale, stale)
+		}))
+	}
+
+	// Create a complete block, a stale partial block and a recently modified partial block.
+	block1 := createTSDBBlock(t, bucketClient, userID, 10, 20, nil)
+	block2 := createTSDBBlock(t, bucketClient, userID, 20, 30, nil)
+	block3 := createTSDBBlock(t, bucketClient, userID, 30, 40, nil)
+	for _, blockID := range []ulid.ULID{block2, block3} {
+		require.NoError(t, bucketClient.Delete(ctx, path.Join(userID, blockID.String(), metadata.MetaFilename)))
+	}
+	setModTime(filepath.Join(storageDir, userID, block2.String()))
+
+	// Create a stale and a recently modified debug file.
+	staleDebugFile := path.Join(userID, block.DebugMetas, block1.String()+".json")
+	recentDebugFile := path.Join(userID, block.DebugMetas, block3.String()+".json")
+	for _, file := range []string{staleDebugFile, recentDebugFile} {
+		require.NoError(t, bucketClient.Upload(ctx, file, strings.NewReader("{}")))
+	}
+	setModTime(filepath.Join(storageDir, staleDebugFile))
+
+	cfg := BlocksCleanerConfig{
+		DeletionDelay:             time.Hour,
+		CleanupInterval:           time.Minute,
+		CleanupConcurrency:        1,
+		PartialBlockDeletionDelay: time.Hour,
+		DebugFilesDeletionDelay:   time.Hour,
+	}
+
+	logger := log.NewNopLogger()
+	scanner := tsdb.NewUsersScanner(bucketClient, tsdb.AllUsers, logger)
+	cfgProvider := newMockConfigProvider()
+	blocksMarkedForDeletion := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: blocksMarkedForDeletionName,
+		Help: blocksMarkedForDeletionHelp,
+	}, append(commonLabels, reasonLabelName))
+
+	cleaner := NewBlocksCleaner(cfg, bucketClient, scanner, cfgProvider, logger, "test-cleaner", nil, time.Minute, 30*time.Second, blocksMarkedForDeletion)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, cleaner))
+	defer services.StopAndAwaitTerminated(ctx, cleaner) //nolint:errcheck
+
+	for _, tc := range []struct {
+		path           string
+		expectedExists bool
+	}{
+		{path: path.Join(userID, block1.String(), metadata.MetaFilename), expectedExists: true},
+		{path: path.Join(userID, block2.String(), block.IndexFilename), expectedExists: false},
+		{path: path.Join(userID, block3.String(), block.IndexFilename), expectedExists: true},
+		{path: staleDebugFile, expectedExists: false},
+		{path: recentDebugFile, expectedExists: true},
+	} {
+		exists, err := bucketClient.Exists(ctx, tc.path)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expectedExists, exists, tc.path)
+	}
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(blocksMarkedForDeletion.WithLabelValues(userID, reasonValuePartial)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(cleaner.blocksCleanedTotal))
+	assert.Equal(t, float64(1), testutil.ToFloat64(cleaner.debugFilesCleanedTotal))
+	assert.Equal(t, float64(1), testutil.ToFloat64(cleaner.tenantPartialBlocks.WithLabelValues(userID)))
+}
+
 func TestBlocksCleaner_ShouldRebuildBucketIndexOnCorruptedOne(t *testing.T) {
 	const userID = "user-1"
 
@@ -0,0 +1,114 @@
+package compactor
+
+import (
+	"github.com/oklog/ulid"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/thanos-io/thanos/pkg/compact"
+)
+
+// progressGrouper wraps a compact.Grouper to export the compaction progress of a tenant
+// each time the blocks of the tenant are grouped, which happens at every iteration of the
+// compaction of the tenant.
+type progressGrouper struct {
+	compact.Grouper
+
+	userID           string
+	ranges           []int64
+	metrics          *compactorMetrics
+	noCompBlocksFunc func() map[ulid.ULID]*metadata.NoCompactMark
+
+	// Whether the remaining planned compactions should be estimated. The shuffle-sharding
+	// grouper already tracks them while planning the groups owned by the compactor.
+	estimateRemainingCompactions bool
+}
+
+func newProgressGrouper(grouper compact.Grouper, userID string, ranges []int64, metrics *compactorMetrics, noCompBlocksFunc func() map[ulid.ULID]*metadata.NoCompactMark, estimateRemainingCompactions bool) *progressGrouper {
+	return &progressGrouper{
+		Grouper:                      grouper,
+		userID:                       userID,
+		ranges:                       ranges,
+		metrics:                      metrics,
+		noCompBlocksFunc:             noCompBlocksFunc,
+		estimateRemainingCompactions: estimateRemainingCompactions,
+	}
+}
+
+func (g *progressGrouper) Groups(blocks map[ulid.ULID]*metadata.Meta) ([]*compact.Group, error) {
+	noCompactMarked := g.noCompBlocksFunc()
+
+	compactable := make([]*metadata.Meta, 0, len(blocks))
+	for _, b := range blocks {
+		if _, excluded := noCompactMarked[b.ULID]; !excluded {
+			compactable = append(compactable, b)
+		}
+	}
+
+	var oldestBlockID ulid.ULID
+	for _, b := range compactable {
+		// The blocks which have never been compacted have the compaction level 1. The ones
+		// without any block to be compacted with, like the blocks already covering the
+		// largest range, are not waiting for a compaction.
+		if b.Compaction.Level > 1 || !hasCompactionPartner(b, compactable, g.ranges) {
+			continue
+		}
+		if oldestBlockID.Time() == 0 || b.ULID.Time() < oldestBlockID.Time() {
+			oldestBlockID = b.ULID
+		}
+	}
+
+	if oldestBlockID.Time() == 0 {
+		g.metrics.oldestUncompactedBlock.DeleteLabelValues(g.userID)
+	} else {
+		g.metrics.oldestUncompactedBlock.WithLabelValues(g.userID).Set(float64(oldestBlockID.Time()) / 1000)
+	}
+
+	if g.estimateRemainingCompactions {
+		g.metrics.remainingPlannedCompactions.WithLabelValues(g.userID).Set(float64(estimateRemainingCompactions(compactable, g.ranges)))
+	}
+
+	return g.Grouper.Groups(blocks)
+}
+
+// hasCompactionPartner returns whether another block of the same group overlaps the
+// range of any of the configured ranges in which the block fits, so that they can be
+// compacted together.
+func hasCompactionPartner(b *metadata.Meta, blocks []*metadata.Meta, ranges []int64) bool {
+	groupKey := b.Thanos.GroupKey()
+
+	for _, tr := range ranges {
+		rangeStart := b.MinTime - b.MinTime%tr
+		rangeEnd := rangeStart + tr
+		if b.MaxTime > rangeEnd {
+			continue
+		}
+
+		for _, other := range blocks {
+			if other.ULID == b.ULID || other.Thanos.GroupKey() != groupKey {
+				continue
+			}
+			if other.MinTime < rangeEnd && other.MaxTime > rangeStart {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// estimateRemainingCompactions returns the number of groups of blocks which can be compacted
+// right away. The groups of the larger ranges are only considered once the blocks of their
+// smaller ranges have been compacted, so it's a lower bound of the compactions left to do.
+func estimateRemainingCompactions(blocks []*metadata.Meta, ranges []int64) int {
+	// The blocks are grouped by downsample resolution and external labels first, like the
+	// Thanos grouper does.
+	mainGroups := map[string][]*metadata.Meta{}
+	for _, b := range blocks {
+		key := b.Thanos.GroupKey()
+		mainGroups[key] = append(mainGroups[key], b)
+	}
+
+	remaining := 0
+	for _, mainBlocks := range mainGroups {
+		remaining += len(groupBlocksByCompactableRanges(mainBlocks, ranges))
+	}
+	return remaining
+}
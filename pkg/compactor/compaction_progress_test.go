@@ -0,0 +1,130 @@
+package compactor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus"
+	prom_testutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/thanos-io/thanos/pkg/compact"
+)
+
+type groupsCounterGrouper struct {
+	calls int
+}
+
+func (g *groupsCounterGrouper) Groups(_ map[ulid.ULID]*metadata.Meta) ([]*compact.Group, error) {
+	g.calls++
+	return nil, nil
+}
+
+func TestProgressGrouper_Groups(t *testing.T) {
+	ranges := []int64{2 * time.Hour.Milliseconds(), 12 * time.Hour.Milliseconds()}
+
+	var (
+		block1 = ulid.MustNew(1000, nil)
+		block2 = ulid.MustNew(2000, nil)
+		block3 = ulid.MustNew(3000, nil)
+		block4 = ulid.MustNew(4000, nil)
+	)
+
+	newMeta := func(id ulid.ULID, minT, maxT int64, level int) *metadata.Meta {
+		return &metadata.Meta{BlockMeta: tsdb.BlockMeta{ULID: id, MinTime: minT, MaxTime: maxT, Compaction: tsdb.BlockMetaCompaction{Level: level}}}
+	}
+
+	tests := map[string]struct {
+		blocks                       map[ulid.ULID]*metadata.Meta
+		noCompactMarked              map[ulid.ULID]*metadata.NoCompactMark
+		estimateRemainingCompactions bool
+		expectedMetrics              string
+	}{
+		"should export the oldest uncompacted block and estimate the remaining compactions": {
+			blocks: map[ulid.ULID]*metadata.Meta{
+				block1: newMeta(block1, 0, ranges[0], 2),
+				block2: newMeta(block2, ranges[0], 2*ranges[0], 1),
+				block3: newMeta(block3, ranges[0], 2*ranges[0], 1),
+				block4: newMeta(block4, ranges[1], ranges[1]+ranges[0], 1),
+			},
+			estimateRemainingCompactions: true,
+			expectedMetrics: `
+				# HELP cortex_compactor_oldest_uncompacted_block_timestamp_seconds Unix timestamp of the creation of the oldest block of the tenant which has never been compacted, excluding the blocks marked for no compaction or without any block to be compacted with.
+				# TYPE cortex_compactor_oldest_uncompacted_block_timestamp_seconds gauge
+				cortex_compactor_oldest_uncompacted_block_timestamp_seconds{user="user-1"} 2
+
+				# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
+				# TYPE cortex_compactor_remaining_planned_compactions gauge
+				cortex_compactor_remaining_planned_compactions{user="user-1"} 1
+			`,
+		},
+		"should skip the blocks marked for no compaction": {
+			blocks: map[ulid.ULID]*metadata.Meta{
+				block1: newMeta(block1, ranges[1], ranges[1]+ranges[0], 2),
+				block2: newMeta(block2, ranges[0], 2*ranges[0], 1),
+				block3: newMeta(block3, ranges[0], 2*ranges[0], 1),
+			},
+			noCompactMarked: map[ulid.ULID]*metadata.NoCompactMark{
+				block2: {ID: block2},
+			},
+			estimateRemainingCompactions: true,
+			expectedMetrics: `
+				# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
+				# TYPE cortex_compactor_remaining_planned_compactions gauge
+				cortex_compactor_remaining_planned_compactions{user="user-1"} 0
+			`,
+		},
+		"should skip the blocks without any block to be compacted with": {
+			blocks: map[ulid.ULID]*metadata.Meta{
+				block1: newMeta(block1, 0, ranges[1], 1),
+				block2: newMeta(block2, ranges[1], ranges[1]+ranges[0], 1),
+				block3: newMeta(block3, ranges[1]+ranges[0], ranges[1]+2*ranges[0], 1),
+				block4: newMeta(block4, 2*ranges[1], 2*ranges[1]+ranges[0], 1),
+			},
+			estimateRemainingCompactions: false,
+			expectedMetrics: `
+				# HELP cortex_compactor_oldest_uncompacted_block_timestamp_seconds Unix timestamp of the creation of the oldest block of the tenant which has never been compacted, excluding the blocks marked for no compaction or without any block to be compacted with.
+				# TYPE cortex_compactor_oldest_uncompacted_block_timestamp_seconds gauge
+				cortex_compactor_oldest_uncompacted_block_timestamp_seconds{user="user-1"} 2
+			`,
+		},
+		"should not export the oldest uncompacted block if all blocks have been compacted": {
+			blocks: map[ulid.ULID]*metadata.Meta{
+				block1: newMeta(block1, 0, ranges[0], 2),
+			},
+			estimateRemainingCompactions: false,
+			expectedMetrics:              ``,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			reg := prometheus.NewPedanticRegistry()
+			metrics := newCompactorMetrics(reg)
+			wrapped := &groupsCounterGrouper{}
+
+			g := newProgressGrouper(wrapped, "user-1", ranges, metrics, func() map[ulid.ULID]*metadata.NoCompactMark {
+				return testData.noCompactMarked
+			}, testData.estimateRemainingCompactions)
+
+			_, err := g.Groups(testData.blocks)
+			require.NoError(t, err)
+			assert.Equal(t, 1, wrapped.calls)
+
+			assert.NoError(t, prom_testutil.GatherAndCompare(reg, strings.NewReader(testData.expectedMetrics),
+				"cortex_compactor_oldest_uncompacted_block_timestamp_seconds",
+				"cortex_compactor_remaining_planned_compactions",
+			))
+
+			metrics.deleteProgressMetrics("user-1")
+			assert.NoError(t, prom_testutil.GatherAndCompare(reg, strings.NewReader(""),
+				"cortex_compactor_oldest_uncompacted_block_timestamp_seconds",
+				"cortex_compactor_remaining_planned_compactions",
+			))
+		})
+	}
+}
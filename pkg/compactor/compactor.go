@@ -10,20 +10,24 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/alecthomas/units"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/oklog/ulid"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/thanos-io/objstore"
 	"github.com/thanos-io/thanos/pkg/block"
 	"github.com/thanos-io/thanos/pkg/block/metadata"
 	"github.com/thanos-io/thanos/pkg/compact"
 	"github.com/thanos-io/thanos/pkg/compact/downsample"
+	"github.com/thanos-io/thanos/pkg/dedup"
 	"github.com/thanos-io/thanos/pkg/errutil"
 	"github.com/thanos-io/thanos/pkg/extprom"
 	"google.golang.org/grpc/codes"
@@ -35,6 +39,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/backoff"
+	"github.com/cortexproject/cortex/pkg/util/concurrency"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
 	"github.com/cortexproject/cortex/pkg/util/services"
@@ -59,6 +64,12 @@ var (
 	supportedCompactionStrategies            = []string{util.CompactionStrategyDefault, util.CompactionStrategyPartitioning}
 	errInvalidCompactionStrategy             = errors.New("invalid compaction strategy")
 	errInvalidCompactionStrategyPartitioning = errors.New("compaction strategy partitioning can only be enabled when shuffle sharding is enabled")
+	supportedDeduplicationFuncs              = []string{DeduplicationFuncNone, DeduplicationFuncPenalty}
+	errInvalidDeduplicationFunc              = errors.New("invalid deduplication func")
+	supportedPlannerStrategies               = []string{PlannerStrategyTimeBased, PlannerStrategySizeBounded}
+	errInvalidPlannerStrategy                = errors.New("invalid planner strategy")
+	errInvalidPlannerMaxIndexSize            = errors.New("invalid planner max index size, the value must be greater than 0")
+	errInvalidTenantsConcurrency             = errors.New("invalid tenants concurrency, the value must be greater than 0")
 
 	DefaultBlocksGrouperFactory = func(ctx context.Context, cfg Config, bkt objstore.InstrumentedBucket, logger log.Logger, blocksMarkedForNoCompaction prometheus.Counter, _ prometheus.Counter, _ prometheus.Counter, syncerMetrics *compact.SyncerMetrics, compactorMetrics *compactorMetrics, _ *ring.Ring, _ *ring.Lifecycler, _ Limits, _ string, _ *compact.GatherNoCompactionMarkFilter) compact.Grouper {
 		return compact.NewDefaultGrouperWithMetrics(
@@ -80,66 +91,98 @@ var (
 	}
 
 	ShuffleShardingGrouperFactory = func(ctx context.Context, cfg Config, bkt objstore.InstrumentedBucket, logger log.Logger, blocksMarkedForNoCompaction prometheus.Counter, blockVisitMarkerReadFailed prometheus.Counter, blockVisitMarkerWriteFailed prometheus.Counter, syncerMetrics *compact.SyncerMetrics, compactorMetrics *compactorMetrics, ring *ring.Ring, ringLifecycle *ring.Lifecycler, limits Limits, userID string, noCompactionMarkFilter *compact.GatherNoCompactionMarkFilter) compact.Grouper {
+		grouper := NewShuffleShardingGrouper(
+			ctx,
+			logger,
+			bkt,
+			cfg.AcceptMalformedIndex,
+			true, // Enable vertical compaction
+			blocksMarkedForNoCompaction,
+			metadata.NoneFunc,
+			syncerMetrics,
+			compactorMetrics,
+			cfg,
+			ring,
+			ringLifecycle.Addr,
+			ringLifecycle.ID,
+			limits,
+			userID,
+			cfg.BlockFilesConcurrency,
+			cfg.BlocksFetchConcurrency,
+			cfg.CompactionConcurrency,
+			cfg.BlockVisitMarkerTimeout,
+			blockVisitMarkerReadFailed,
+			blockVisitMarkerWriteFailed,
+			noCompactionMarkFilter.NoCompactMarkedBlocks)
 		if cfg.CompactionStrategy == util.CompactionStrategyPartitioning {
-			return NewPartitionCompactionGrouper(ctx, logger, bkt)
-		} else {
-			return NewShuffleShardingGrouper(
-				ctx,
-				logger,
-				bkt,
-				cfg.AcceptMalformedIndex,
-				true, // Enable vertical compaction
-				blocksMarkedForNoCompaction,
-				metadata.NoneFunc,
-				syncerMetrics,
-				compactorMetrics,
-				cfg,
-				ring,
-				ringLifecycle.Addr,
-				ringLifecycle.ID,
-				limits,
-				userID,
-				cfg.BlockFilesConcurrency,
-				cfg.BlocksFetchConcurrency,
-				cfg.CompactionConcurrency,
-				cfg.BlockVisitMarkerTimeout,
-				blockVisitMarkerReadFailed,
-				blockVisitMarkerWriteFailed,
-				noCompactionMarkFilter.NoCompactMarkedBlocks)
+			return NewPartitionCompactionGrouper(grouper)
 		}
+		return grouper
 	}
 
 	DefaultBlocksCompactorFactory = func(ctx context.Context, cfg Config, logger log.Logger, reg prometheus.Registerer) (compact.Compactor, PlannerFactory, error) {
-		compactor, err := tsdb.NewLeveledCompactor(ctx, reg, logger, cfg.BlockRanges.ToMilliseconds(), downsample.NewPool(), nil)
+		compactor, err := newLeveledCompactor(ctx, cfg, logger, reg)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		plannerFactory := func(ctx context.Context, bkt objstore.InstrumentedBucket, logger log.Logger, cfg Config, noCompactionMarkFilter *compact.GatherNoCompactionMarkFilter, ringLifecycle *ring.Lifecycler, _ string, _ prometheus.Counter, _ prometheus.Counter, _ *compactorMetrics) compact.Planner {
-			return compact.NewPlanner(logger, cfg.BlockRanges.ToMilliseconds(), noCompactionMarkFilter)
+		plannerFactory := func(ctx context.Context, bkt objstore.InstrumentedBucket, logger log.Logger, cfg Config, noCompactionMarkFilter *compact.GatherNoCompactionMarkFilter, blocksMarkedForNoCompaction prometheus.Counter, ringLifecycle *ring.Lifecycler, _ string, _ prometheus.Counter, _ prometheus.Counter, _ *compactorMetrics) compact.Planner {
+			planner := compact.NewPlanner(logger, cfg.BlockRanges.ToMilliseconds(), noCompactionMarkFilter)
+			if cfg.PlannerStrategy == PlannerStrategySizeBounded {
+				return compact.WithLargeTotalIndexSizeFilter(planner, bkt, cfg.PlannerMaxIndexSizeBytes, blocksMarkedForNoCompaction)
+			}
+			return planner
 		}
 
 		return compactor, plannerFactory, nil
 	}
 
 	ShuffleShardingBlocksCompactorFactory = func(ctx context.Context, cfg Config, logger log.Logger, reg prometheus.Registerer) (compact.Compactor, PlannerFactory, error) {
-		compactor, err := tsdb.NewLeveledCompactor(ctx, reg, logger, cfg.BlockRanges.ToMilliseconds(), downsample.NewPool(), nil)
+		compactor, err := newLeveledCompactor(ctx, cfg, logger, reg)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		plannerFactory := func(ctx context.Context, bkt objstore.InstrumentedBucket, logger log.Logger, cfg Config, noCompactionMarkFilter *compact.GatherNoCompactionMarkFilter, ringLifecycle *ring.Lifecycler, userID string, blockVisitMarkerReadFailed prometheus.Counter, blockVisitMarkerWriteFailed prometheus.Counter, compactorMetrics *compactorMetrics) compact.Planner {
-
-			if cfg.CompactionStrategy == util.CompactionStrategyPartitioning {
-				return NewPartitionCompactionPlanner(ctx, bkt, logger)
-			} else {
-				return NewShuffleShardingPlanner(ctx, bkt, logger, cfg.BlockRanges.ToMilliseconds(), noCompactionMarkFilter.NoCompactMarkedBlocks, ringLifecycle.ID, cfg.BlockVisitMarkerTimeout, cfg.BlockVisitMarkerFileUpdateInterval, blockVisitMarkerReadFailed, blockVisitMarkerWriteFailed)
+		plannerFactory := func(ctx context.Context, bkt objstore.InstrumentedBucket, logger log.Logger, cfg Config, noCompactionMarkFilter *compact.GatherNoCompactionMarkFilter, blocksMarkedForNoCompaction prometheus.Counter, ringLifecycle *ring.Lifecycler, userID string, blockVisitMarkerReadFailed prometheus.Counter, blockVisitMarkerWriteFailed prometheus.Counter, compactorMetrics *compactorMetrics) compact.Planner {
+			// The blocks of the groups of each partition are planned like the shuffle sharding ones.
+			planner := NewShuffleShardingPlanner(ctx, bkt, logger, cfg.BlockRanges.ToMilliseconds(), noCompactionMarkFilter.NoCompactMarkedBlocks, ringLifecycle.ID, cfg.BlockVisitMarkerTimeout, cfg.BlockVisitMarkerFileUpdateInterval, blockVisitMarkerReadFailed, blockVisitMarkerWriteFailed)
+			if cfg.PlannerStrategy == PlannerStrategySizeBounded {
+				return newSizeBoundedPlanner(planner, bkt, logger, noCompactionMarkFilter.NoCompactMarkedBlocks, cfg.PlannerMaxIndexSizeBytes, blocksMarkedForNoCompaction)
 			}
+			return planner
 		}
 		return compactor, plannerFactory, nil
 	}
 )
 
+const (
+	// DeduplicationFuncNone only deduplicates the identical samples of the overlapping blocks.
+	DeduplicationFuncNone = ""
+
+	// DeduplicationFuncPenalty deduplicates the samples of the overlapping blocks with the
+	// penalty-based algorithm of the Thanos querier, which picks the samples of a single replica
+	// and switches to another one only when there's a gap in its samples.
+	DeduplicationFuncPenalty = "penalty"
+
+	// PlannerStrategyTimeBased plans the compaction of the blocks of each block range, once the range
+	// is complete. The shard-aware planner is used instead with the shuffle sharding strategy.
+	PlannerStrategyTimeBased = "time-based"
+
+	// PlannerStrategySizeBounded plans the compaction like the time-based strategy, but excludes from
+	// the compaction the blocks which would make the index of the compacted block exceed the max size.
+	PlannerStrategySizeBounded = "size-bounded"
+)
+
+// newLeveledCompactor creates the TSDB compactor, merging the series of the overlapping blocks
+// with the configured deduplication func.
+func newLeveledCompactor(ctx context.Context, cfg Config, logger log.Logger, reg prometheus.Registerer) (*tsdb.LeveledCompactor, error) {
+	var mergeFunc storage.VerticalChunkSeriesMergeFunc
+	if cfg.DeduplicationFunc == DeduplicationFuncPenalty {
+		mergeFunc = dedup.NewChunkSeriesMerger()
+	}
+	return tsdb.NewLeveledCompactor(ctx, reg, logger, cfg.BlockRanges.ToMilliseconds(), downsample.NewPool(), mergeFunc)
+}
+
 // BlocksGrouperFactory builds and returns the grouper to use to compact a tenant's blocks.
 type BlocksGrouperFactory func(
 	ctx context.Context,
@@ -158,7 +201,7 @@ type BlocksGrouperFactory func(
 	noCompactionMarkFilter *compact.GatherNoCompactionMarkFilter,
 ) compact.Grouper
 
-// BlocksCompactorFactory builds and returns the compactor and planner to use to compact a tenant's blocks.
+// BlocksCompactorFactory builds and returns the compactor and the factory of the planner to use to compact a tenant's blocks.
 type BlocksCompactorFactory func(
 	ctx context.Context,
 	cfg Config,
@@ -166,12 +209,14 @@ type BlocksCompactorFactory func(
 	reg prometheus.Registerer,
 ) (compact.Compactor, PlannerFactory, error)
 
+// PlannerFactory builds and returns the planner to use to compact a tenant's blocks.
 type PlannerFactory func(
 	ctx context.Context,
 	bkt objstore.InstrumentedBucket,
 	logger log.Logger,
 	cfg Config,
 	noCompactionMarkFilter *compact.GatherNoCompactionMarkFilter,
+	blocksMarkedForNoCompaction prometheus.Counter,
 	ringLifecycle *ring.Lifecycler,
 	userID string,
 	blockVisitMarkerReadFailed prometheus.Counter,
@@ -182,6 +227,7 @@ type PlannerFactory func(
 // Limits defines limits used by the Compactor.
 type Limits interface {
 	CompactorTenantShardSize(userID string) int
+	CompactorPartitionCount(userID string) int
 }
 
 // Config holds the Compactor config.
@@ -194,11 +240,15 @@ type Config struct {
 	CompactionInterval                    time.Duration            `yaml:"compaction_interval"`
 	CompactionRetries                     int                      `yaml:"compaction_retries"`
 	CompactionConcurrency                 int                      `yaml:"compaction_concurrency"`
+	TenantsConcurrency                    int                      `yaml:"tenants_concurrency"`
 	CleanupInterval                       time.Duration            `yaml:"cleanup_interval"`
 	CleanupConcurrency                    int                      `yaml:"cleanup_concurrency"`
 	DeletionDelay                         time.Duration            `yaml:"deletion_delay"`
 	TenantCleanupDelay                    time.Duration            `yaml:"tenant_cleanup_delay"`
+	PartialBlockDeletionDelay             time.Duration            `yaml:"partial_block_deletion_delay"`
+	DebugFilesDeletionDelay               time.Duration            `yaml:"debug_files_deletion_delay"`
 	SkipBlocksWithOutOfOrderChunksEnabled bool                     `yaml:"skip_blocks_with_out_of_order_chunks_enabled"`
+	SkipCorruptedBlocksEnabled            bool                     `yaml:"skip_corrupted_blocks_enabled"`
 	BlockFilesConcurrency                 int                      `yaml:"block_files_concurrency"`
 	BlocksFetchConcurrency                int                      `yaml:"blocks_fetch_concurrency"`
 
@@ -216,12 +266,24 @@ type Config struct {
 	// Compaction mode.
 	CompactionStrategy string `yaml:"compaction_mode"`
 
+	// Deduplication of the overlapping blocks of HA replicas.
+	DeduplicationReplicaLabels flagext.StringSliceCSV `yaml:"deduplication_replica_labels"`
+	DeduplicationFunc          string                 `yaml:"deduplication_func"`
+
+	// Compaction planner.
+	PlannerStrategy          string `yaml:"planner_strategy"`
+	PlannerMaxIndexSizeBytes int64  `yaml:"planner_max_index_size_bytes"`
+
 	// No need to add options to customize the retry backoff,
 	// given the defaults should be fine, but allow to override
 	// it in tests.
 	retryMinBackoff time.Duration `yaml:"-"`
 	retryMaxBackoff time.Duration `yaml:"-"`
 
+	// Period during which the series deletion requests can be cancelled, injected internally
+	// from the purger config. The series are not deleted from the blocks when 0.
+	DeleteRequestCancelPeriod time.Duration `yaml:"-"`
+
 	// Allow downstream projects to customise the blocks compactor.
 	BlocksGrouperFactory   BlocksGrouperFactory   `yaml:"-"`
 	BlocksCompactorFactory BlocksCompactorFactory `yaml:"-"`
@@ -253,7 +315,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&cfg.DataDir, "compactor.data-dir", "./data", "Data directory in which to cache blocks and process compactions")
 	f.DurationVar(&cfg.CompactionInterval, "compactor.compaction-interval", time.Hour, "The frequency at which the compaction runs")
 	f.IntVar(&cfg.CompactionRetries, "compactor.compaction-retries", 3, "How many times to retry a failed compaction within a single compaction run.")
-	f.IntVar(&cfg.CompactionConcurrency, "compactor.compaction-concurrency", 1, "Max number of concurrent compactions running.")
+	f.IntVar(&cfg.CompactionConcurrency, "compactor.compaction-concurrency", 1, "Max number of concurrent compactions running within a tenant.")
+	f.IntVar(&cfg.TenantsConcurrency, "compactor.tenants-concurrency", 1, "[Experimental] Max number of tenants compacted concurrently by a compactor. The max number of concurrent compactions running in the compactor is this value multiplied by -compactor.compaction-concurrency.")
 	f.DurationVar(&cfg.CleanupInterval, "compactor.cleanup-interval", 15*time.Minute, "How frequently compactor should run blocks cleanup and maintenance, as well as update the bucket index.")
 	f.IntVar(&cfg.CleanupConcurrency, "compactor.cleanup-concurrency", 20, "Max number of tenants for which blocks cleanup and maintenance should run concurrently.")
 	f.BoolVar(&cfg.ShardingEnabled, "compactor.sharding-enabled", false, "Shard tenants across multiple compactor instances. Sharding is required if you run multiple compactor instances, in order to coordinate compactions and avoid race conditions leading to the same tenant blocks simultaneously compacted by different instances.")
@@ -263,8 +326,11 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 		"If not 0, blocks will be marked for deletion and compactor component will permanently delete blocks marked for deletion from the bucket. "+
 		"If 0, blocks will be deleted straight away. Note that deleting blocks immediately can cause query failures.")
 	f.DurationVar(&cfg.TenantCleanupDelay, "compactor.tenant-cleanup-delay", 6*time.Hour, "For tenants marked for deletion, this is time between deleting of last block, and doing final cleanup (marker files, debug files) of the tenant.")
+	f.DurationVar(&cfg.PartialBlockDeletionDelay, "compactor.partial-block-deletion-delay", 0, "Time after which the partial blocks without a deletion mark, like the blocks whose upload has failed before the meta.json was uploaded, are marked for deletion and deleted, when none of their files has been modified in the meantime. It should be greater than the time it takes to upload a block. 0 to disable.")
+	f.DurationVar(&cfg.DebugFilesDeletionDelay, "compactor.debug-files-deletion-delay", 0, "Time after which the files under the debug/ location of the tenants are deleted, when not modified in the meantime. 0 to disable.")
 	f.BoolVar(&cfg.BlockDeletionMarksMigrationEnabled, "compactor.block-deletion-marks-migration-enabled", false, "When enabled, at compactor startup the bucket will be scanned and all found deletion marks inside the block location will be copied to the markers global location too. This option can (and should) be safely disabled as soon as the compactor has successfully run at least once.")
 	f.BoolVar(&cfg.SkipBlocksWithOutOfOrderChunksEnabled, "compactor.skip-blocks-with-out-of-order-chunks-enabled", false, "When enabled, mark blocks containing index with out-of-order chunks for no compact instead of halting the compaction.")
+	f.BoolVar(&cfg.SkipCorruptedBlocksEnabled, "compactor.skip-corrupted-blocks-enabled", false, "When enabled, mark blocks whose index is not healthy or can't be read because of an invalid size or checksum for no compact, with the compaction error as details of the mark, instead of halting or retrying forever the compaction of the tenant.")
 	f.IntVar(&cfg.BlockFilesConcurrency, "compactor.block-files-concurrency", 10, "Number of goroutines to use when fetching/uploading block files from object storage.")
 	f.IntVar(&cfg.BlocksFetchConcurrency, "compactor.blocks-fetch-concurrency", 3, "Number of goroutines to use when fetching blocks from object storage when compacting.")
 
@@ -277,6 +343,10 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&cfg.CleanerVisitMarkerTimeout, "compactor.cleaner-visit-marker-timeout", 10*time.Minute, "How long cleaner visit marker file should be considered as expired and able to be picked up by cleaner again. The value should be smaller than -compactor.cleanup-interval")
 	f.DurationVar(&cfg.CleanerVisitMarkerFileUpdateInterval, "compactor.cleaner-visit-marker-file-update-interval", 5*time.Minute, "How frequently cleaner visit marker file should be updated when cleaning user.")
 
+	f.Var(&cfg.DeduplicationReplicaLabels, "compactor.deduplication-replica-labels", "[Experimental] Comma separated list of external labels identifying the replica of the blocks, like the blocks shipped or backfilled by Prometheus HA pairs. The labels are removed from the external labels of the blocks, so that the overlapping blocks of the replicas are vertically compacted together.")
+	f.StringVar(&cfg.DeduplicationFunc, "compactor.deduplication-func", DeduplicationFuncNone, "[Experimental] Deduplication algorithm of the samples of the overlapping blocks vertically compacted together. When empty, only the identical samples are deduplicated. When set to 'penalty', the samples are deduplicated with the penalty-based algorithm of the Thanos querier, which keeps the samples of a single replica as long as it has no gap. Only use 'penalty' when all the overlapping blocks are replicas of the same data, as the samples of non-replicated overlapping blocks would be dropped.")
+	f.StringVar(&cfg.PlannerStrategy, "compactor.planner-strategy", PlannerStrategyTimeBased, fmt.Sprintf("[Experimental] The strategy to plan the compaction of the blocks of a tenant. Supported values are: %s. The 'size-bounded' strategy excludes from the compaction, and marks for no compact, the blocks which would make the index of the compacted block larger than -compactor.planner-max-index-size-bytes. With the shuffle sharding strategy, the strategies apply to the blocks of the groups planned by the shard-aware planner.", strings.Join(supportedPlannerStrategies, ", ")))
+	f.Int64Var(&cfg.PlannerMaxIndexSizeBytes, "compactor.planner-max-index-size-bytes", int64(64*units.GiB), "[Experimental] The max size, in bytes, of the index of a compacted block when -compactor.planner-strategy is 'size-bounded'. The size is estimated as the sum of the sizes of the indexes of the compacted blocks, with a 15% headroom.")
 	f.BoolVar(&cfg.AcceptMalformedIndex, "compactor.accept-malformed-index", false, "When enabled, index verification will ignore out of order label names.")
 	f.BoolVar(&cfg.CachingBucketEnabled, "compactor.caching-bucket-enabled", false, "When enabled, caching bucket will be used for compactor, except cleaner service, which serves as the source of truth for block status")
 }
@@ -305,15 +375,44 @@ func (cfg *Config) Validate(limits validation.Limits) error {
 		}
 	}
 
+	if cfg.ShardingEnabled {
+		if err := ring.ValidateTokensGeneratorStrategy(cfg.ShardingRing.TokensGeneratorStrategy); err != nil {
+			return err
+		}
+	}
+
 	// Make sure a valid compaction mode is being used
 	if !util.StringsContain(supportedCompactionStrategies, cfg.CompactionStrategy) {
 		return errInvalidCompactionStrategy
 	}
 
-	if !cfg.ShardingEnabled && cfg.CompactionStrategy == util.CompactionStrategyPartitioning {
+	if (!cfg.ShardingEnabled || cfg.ShardingStrategy != util.ShardingStrategyShuffle) && cfg.CompactionStrategy == util.CompactionStrategyPartitioning {
 		return errInvalidCompactionStrategyPartitioning
 	}
 
+	if !util.StringsContain(supportedDeduplicationFuncs, cfg.DeduplicationFunc) {
+		return errInvalidDeduplicationFunc
+	}
+
+	if !util.StringsContain(supportedPlannerStrategies, cfg.PlannerStrategy) {
+		return errInvalidPlannerStrategy
+	}
+
+	if cfg.TenantsConcurrency <= 0 {
+		return errInvalidTenantsConcurrency
+	}
+
+	if cfg.PlannerStrategy == PlannerStrategySizeBounded && cfg.PlannerMaxIndexSizeBytes <= 0 {
+		return errInvalidPlannerMaxIndexSize
+	}
+
+	if cfg.ShardingEnabled {
+		lifecyclerCfg := cfg.ShardingRing.ToLifecyclerConfig()
+		if err := lifecyclerCfg.Validate(); err != nil {
+			return errors.Wrap(err, "invalid compactor ring config")
+		}
+	}
+
 	return nil
 }
 
@@ -362,20 +461,23 @@ type Compactor struct {
 	ringSubservicesWatcher *services.FailureWatcher
 
 	// Metrics.
-	CompactorStartDurationSeconds  prometheus.Gauge
-	CompactionRunsStarted          prometheus.Counter
-	CompactionRunsInterrupted      prometheus.Counter
-	CompactionRunsCompleted        prometheus.Counter
-	CompactionRunsFailed           prometheus.Counter
-	CompactionRunsLastSuccess      prometheus.Gauge
-	CompactionRunDiscoveredTenants prometheus.Gauge
-	CompactionRunSkippedTenants    prometheus.Gauge
-	CompactionRunSucceededTenants  prometheus.Gauge
-	CompactionRunFailedTenants     prometheus.Gauge
-	CompactionRunInterval          prometheus.Gauge
-	BlocksMarkedForNoCompaction    prometheus.Counter
-	blockVisitMarkerReadFailed     prometheus.Counter
-	blockVisitMarkerWriteFailed    prometheus.Counter
+	CompactorStartDurationSeconds        prometheus.Gauge
+	CompactionRunsStarted                prometheus.Counter
+	CompactionRunsInterrupted            prometheus.Counter
+	CompactionRunsCompleted              prometheus.Counter
+	CompactionRunsFailed                 prometheus.Counter
+	CompactionRunsLastSuccess            prometheus.Gauge
+	CompactionRunDiscoveredTenants       prometheus.Gauge
+	CompactionRunSkippedTenants          prometheus.Gauge
+	CompactionRunSucceededTenants        prometheus.Gauge
+	CompactionRunFailedTenants           prometheus.Gauge
+	CompactionRunQueuedTenants           prometheus.Gauge
+	CompactionRunInProgressTenants       prometheus.Gauge
+	CompactionRunInterval                prometheus.Gauge
+	BlocksMarkedForNoCompaction          prometheus.Counter
+	CorruptedBlocksMarkedForNoCompaction prometheus.Counter
+	blockVisitMarkerReadFailed           prometheus.Counter
+	blockVisitMarkerWriteFailed          prometheus.Counter
 
 	// Thanos compactor metrics per user
 	compactorMetrics *compactorMetrics
@@ -480,6 +582,14 @@ func newCompactor(
 			Name: "cortex_compactor_tenants_processing_failed",
 			Help: "Number of tenants failed processing during the current compaction run. Reset to 0 when compactor is idle.",
 		}),
+		CompactionRunQueuedTenants: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_compactor_tenants_processing_queued",
+			Help: "Number of tenants waiting to be processed during the current compaction run. Reset to 0 when compactor is idle.",
+		}),
+		CompactionRunInProgressTenants: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_compactor_tenants_processing_in_progress",
+			Help: "Number of tenants being compacted during the current compaction run. Reset to 0 when compactor is idle.",
+		}),
 		CompactionRunInterval: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
 			Name: "cortex_compactor_compaction_interval_seconds",
 			Help: "The configured interval on which compaction is run in seconds. Useful when compared to the last successful run metric to accurately detect multiple failed compaction runs.",
@@ -488,6 +598,10 @@ func newCompactor(
 			Name: "cortex_compactor_blocks_marked_for_no_compaction_total",
 			Help: "Total number of blocks marked for no compact during a compaction run.",
 		}),
+		CorruptedBlocksMarkedForNoCompaction: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_corrupted_blocks_marked_for_no_compaction_total",
+			Help: "Total number of blocks with a corrupted index marked for no compact during a compaction run.",
+		}),
 		blockVisitMarkerReadFailed: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
 			Name: "cortex_compactor_block_visit_marker_read_failed",
 			Help: "Number of block visit marker file failed to be read.",
@@ -620,6 +734,11 @@ func (c *Compactor) starting(ctx context.Context) error {
 		CleanupConcurrency:                 c.compactorCfg.CleanupConcurrency,
 		BlockDeletionMarksMigrationEnabled: c.compactorCfg.BlockDeletionMarksMigrationEnabled,
 		TenantCleanupDelay:                 c.compactorCfg.TenantCleanupDelay,
+		DeleteRequestCancelPeriod:          c.compactorCfg.DeleteRequestCancelPeriod,
+		BlockRanges:                        c.compactorCfg.BlockRanges,
+		DataDir:                            c.compactorCfg.DataDir,
+		PartialBlockDeletionDelay:          c.compactorCfg.PartialBlockDeletionDelay,
+		DebugFilesDeletionDelay:            c.compactorCfg.DebugFilesDeletionDelay,
 	}, c.bucketClient, c.usersScanner, c.limits, c.parentLogger, cleanerRingLifecyclerID, c.registerer, c.compactorCfg.CleanerVisitMarkerTimeout, c.compactorCfg.CleanerVisitMarkerFileUpdateInterval,
 		c.compactorMetrics.syncerBlocksMarkedForDeletion)
 
@@ -703,6 +822,8 @@ func (c *Compactor) compactUsers(ctx context.Context) {
 		c.CompactionRunSkippedTenants.Set(0)
 		c.CompactionRunSucceededTenants.Set(0)
 		c.CompactionRunFailedTenants.Set(0)
+		c.CompactionRunQueuedTenants.Set(0)
+		c.CompactionRunInProgressTenants.Set(0)
 	}()
 
 	level.Info(c.logger).Log("msg", "discovering users from bucket")
@@ -725,23 +846,24 @@ func (c *Compactor) compactUsers(ctx context.Context) {
 
 	// Keep track of users owned by this shard, so that we can delete the local files for all other users.
 	ownedUsers := map[string]struct{}{}
-	for _, userID := range users {
-		// Ensure the context has not been canceled (ie. compactor shutdown has been triggered).
-		if ctx.Err() != nil {
-			interrupted = true
-			level.Info(c.logger).Log("msg", "interrupting compaction of user blocks", "user", userID)
-			return
-		}
+	ownedUsersMx := sync.Mutex{}
+
+	// The tenants are queued and compacted by up to -compactor.tenants-concurrency workers.
+	c.CompactionRunQueuedTenants.Set(float64(len(users)))
+
+	err = concurrency.ForEachUser(ctx, users, c.compactorCfg.TenantsConcurrency, func(ctx context.Context, userID string) error {
+		c.CompactionRunQueuedTenants.Dec()
 
 		// Ensure the user ID belongs to our shard.
 		if owned, err := c.ownUserForCompaction(userID); err != nil {
 			c.CompactionRunSkippedTenants.Inc()
 			level.Warn(c.logger).Log("msg", "unable to check if user is owned by this shard", "user", userID, "err", err)
-			continue
+			return nil
 		} else if !owned {
 			c.CompactionRunSkippedTenants.Inc()
+			c.compactorMetrics.deleteProgressMetrics(userID)
 			level.Debug(c.logger).Log("msg", "skipping user because it is not owned by this shard", "user", userID)
-			continue
+			return nil
 		}
 
 		// Skipping compaction if the  bucket index failed to sync due to CMK errors.
@@ -749,40 +871,57 @@ func (c *Compactor) compactUsers(ctx context.Context) {
 			if idxs.Status == bucketindex.CustomerManagedKeyError {
 				c.CompactionRunSkippedTenants.Inc()
 				level.Info(c.logger).Log("msg", "skipping compactUser due CustomerManagedKeyError", "user", userID)
-				continue
+				return nil
 			}
 		}
 
+		ownedUsersMx.Lock()
 		ownedUsers[userID] = struct{}{}
+		ownedUsersMx.Unlock()
 
 		if markedForDeletion, err := cortex_tsdb.TenantDeletionMarkExists(ctx, c.bucketClient, userID); err != nil {
 			c.CompactionRunSkippedTenants.Inc()
 			level.Warn(c.logger).Log("msg", "unable to check if user is marked for deletion", "user", userID, "err", err)
-			continue
+			return nil
 		} else if markedForDeletion {
 			c.CompactionRunSkippedTenants.Inc()
+			c.compactorMetrics.deleteProgressMetrics(userID)
 			level.Debug(c.logger).Log("msg", "skipping user because it is marked for deletion", "user", userID)
-			continue
+			return nil
 		}
 
 		level.Info(c.logger).Log("msg", "starting compaction of user blocks", "user", userID)
 
-		if err = c.compactUserWithRetries(ctx, userID); err != nil {
+		c.CompactionRunInProgressTenants.Inc()
+		err := c.compactUserWithRetries(ctx, userID)
+		c.CompactionRunInProgressTenants.Dec()
+
+		if err != nil {
 			// TODO: patch thanos error types to support errors.Is(err, context.Canceled) here
 			if ctx.Err() != nil && ctx.Err() == context.Canceled {
-				interrupted = true
 				level.Info(c.logger).Log("msg", "interrupting compaction of user blocks", "user", userID)
-				return
+				return nil
 			}
 
 			c.CompactionRunFailedTenants.Inc()
-			failed = true
 			level.Error(c.logger).Log("msg", "failed to compact user blocks", "user", userID, "err", err)
-			continue
+			return err
 		}
 
 		c.CompactionRunSucceededTenants.Inc()
+		c.compactorMetrics.lastSuccessfulCompaction.WithLabelValues(userID).SetToCurrentTime()
 		level.Info(c.logger).Log("msg", "successfully compacted user blocks", "user", userID)
+		return nil
+	})
+
+	// Ensure the context has not been canceled (ie. compactor shutdown has been triggered).
+	if ctx.Err() != nil {
+		interrupted = true
+		level.Info(c.logger).Log("msg", "interrupting compaction of users blocks")
+		return
+	}
+	if err != nil {
+		failed = true
 	}
 
 	// Delete local files for unowned tenants, if there are any. This cleans up
@@ -816,6 +955,10 @@ func (c *Compactor) compactUsers(ctx context.Context) {
 func (c *Compactor) compactUserWithRetries(ctx context.Context, userID string) error {
 	var lastErr error
 
+	// Keep track of the corrupted blocks marked for no compaction, to not mark them
+	// again if the compaction keeps failing on them.
+	corruptedBlocks := map[ulid.ULID]struct{}{}
+
 	retries := backoff.New(ctx, backoff.Config{
 		MinBackoff: c.compactorCfg.retryMinBackoff,
 		MaxBackoff: c.compactorCfg.retryMaxBackoff,
@@ -835,6 +978,15 @@ func (c *Compactor) compactUserWithRetries(ctx context.Context, userID string) e
 			c.compactorMetrics.compactionErrorsCount.WithLabelValues(userID, unauthorizedError).Inc()
 			return nil
 		}
+		if c.compactorCfg.SkipCorruptedBlocksEnabled {
+			if blockID, ok := corruptedBlockID(lastErr); ok {
+				if _, marked := corruptedBlocks[blockID]; !marked && c.markCorruptedBlockForNoCompaction(ctx, userID, blockID, lastErr) == nil {
+					// Compact again the tenant right away, the planners skip the blocks marked for no compaction.
+					corruptedBlocks[blockID] = struct{}{}
+					continue
+				}
+			}
+		}
 		if compact.IsHaltError(lastErr) {
 			level.Error(c.logger).Log("msg", "compactor returned critical error", "user", userID, "err", lastErr)
 			c.compactorMetrics.compactionErrorsCount.WithLabelValues(userID, haltError).Inc()
@@ -857,8 +1009,13 @@ func (c *Compactor) compactUser(ctx context.Context, userID string) error {
 	ulogger = util_log.WithExecutionID(ulid.MustNew(ulid.Now(), crypto_rand.Reader).String(), ulogger)
 
 	// Filters out duplicate blocks that can be formed from two or more overlapping
-	// blocks that fully submatches the source blocks of the older blocks.
-	deduplicateBlocksFilter := block.NewDeduplicateFilter(c.compactorCfg.BlockSyncConcurrency)
+	// blocks that fully submatches the source blocks of the older blocks. The blocks
+	// compacted into each partition have the same source blocks, so they are not filtered
+	// out with the partitioning compaction strategy.
+	var deduplicateBlocksFilter deduplicateFilter = block.NewDeduplicateFilter(c.compactorCfg.BlockSyncConcurrency)
+	if c.compactorCfg.CompactionStrategy == util.CompactionStrategyPartitioning {
+		deduplicateBlocksFilter = newPartitionedDeduplicateFilter(c.compactorCfg.BlockSyncConcurrency)
+	}
 
 	// While fetching blocks, we filter out blocks that were marked for deletion by using IgnoreDeletionMarkFilter.
 	// No delay is used -- all blocks with deletion marker are ignored, and not considered for compaction.
@@ -898,8 +1055,9 @@ func (c *Compactor) compactUser(ctx context.Context, userID string) error {
 		// List of filters to apply (order matters).
 		[]block.MetadataFilter{
 			// Remove the ingester ID because we don't shard blocks anymore, while still
-			// honoring the shard ID if sharding was done in the past.
-			NewLabelRemoverFilter([]string{cortex_tsdb.IngesterIDExternalLabel}),
+			// honoring the shard ID if sharding was done in the past. The replica labels
+			// are removed too, to vertically compact together the blocks of the replicas.
+			NewLabelRemoverFilter(append([]string{cortex_tsdb.IngesterIDExternalLabel}, c.compactorCfg.DeduplicationReplicaLabels...)),
 			block.NewConsistencyDelayMetaFilter(ulogger, c.compactorCfg.ConsistencyDelay, reg),
 			ignoreDeletionMarkFilter,
 			deduplicateBlocksFilter,
@@ -925,12 +1083,22 @@ func (c *Compactor) compactUser(ctx context.Context, userID string) error {
 
 	currentCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
-	compactor, err := compact.NewBucketCompactor(
+	grouper := c.blocksGrouperFactory(currentCtx, c.compactorCfg, bucket, ulogger, c.BlocksMarkedForNoCompaction, c.blockVisitMarkerReadFailed, c.blockVisitMarkerWriteFailed, syncerMetrics, c.compactorMetrics, c.ring, c.ringLifecycler, c.limits, userID, noCompactMarkerFilter)
+	var blockDeletableChecker compact.BlockDeletableChecker = compact.DefaultBlockDeletableChecker{}
+	var compactionLifecycleCallback compact.CompactionLifecycleCallback = compact.DefaultCompactionLifecycleCallback{}
+	if partitionGrouper, ok := grouper.(*PartitionCompactionGrouper); ok {
+		blockDeletableChecker = partitionGrouper
+		compactionLifecycleCallback = NewPartitionCompactionLifecycleCallback(bucket)
+	}
+	grouper = newProgressGrouper(grouper, userID, c.compactorCfg.BlockRanges.ToMilliseconds(), c.compactorMetrics, noCompactMarkerFilter.NoCompactMarkedBlocks, c.compactorCfg.ShardingStrategy != util.ShardingStrategyShuffle)
+	compactor, err := compact.NewBucketCompactorWithCheckerAndCallback(
 		ulogger,
 		syncer,
-		c.blocksGrouperFactory(currentCtx, c.compactorCfg, bucket, ulogger, c.BlocksMarkedForNoCompaction, c.blockVisitMarkerReadFailed, c.blockVisitMarkerWriteFailed, syncerMetrics, c.compactorMetrics, c.ring, c.ringLifecycler, c.limits, userID, noCompactMarkerFilter),
-		c.blocksPlannerFactory(currentCtx, bucket, ulogger, c.compactorCfg, noCompactMarkerFilter, c.ringLifecycler, userID, c.blockVisitMarkerReadFailed, c.blockVisitMarkerWriteFailed, c.compactorMetrics),
+		grouper,
+		c.blocksPlannerFactory(currentCtx, bucket, ulogger, c.compactorCfg, noCompactMarkerFilter, c.BlocksMarkedForNoCompaction, c.ringLifecycler, userID, c.blockVisitMarkerReadFailed, c.blockVisitMarkerWriteFailed, c.compactorMetrics),
 		c.blocksCompactor,
+		blockDeletableChecker,
+		compactionLifecycleCallback,
 		c.compactDirForUser(userID),
 		bucket,
 		c.compactorCfg.CompactionConcurrency,
@@ -946,9 +1114,14 @@ func (c *Compactor) compactUser(ctx context.Context, userID string) error {
 
 	// Remove all files on the compact root dir
 	// We do this only if there is no error because potentially on the next run we would not have to download
-	// everything again.
-	if err := os.RemoveAll(c.compactRootDir()); err != nil {
-		level.Error(c.logger).Log("msg", "failed to remove compaction work directory", "path", c.compactRootDir(), "err", err)
+	// everything again. When tenants are compacted concurrently, only the files of the tenant are removed,
+	// to not remove the blocks being compacted for the other tenants.
+	compactDir := c.compactRootDir()
+	if c.compactorCfg.TenantsConcurrency > 1 {
+		compactDir = c.compactDirForUser(userID)
+	}
+	if err := os.RemoveAll(compactDir); err != nil {
+		level.Error(c.logger).Log("msg", "failed to remove compaction work directory", "path", compactDir, "err", err)
 	}
 
 	return nil
@@ -51,3 +51,18 @@ func (c *Compactor) RingHandler(w http.ResponseWriter, req *http.Request) {
 
 	c.ring.ServeHTTP(w, req)
 }
+
+// RingDumpHandler responds with the full compactor ring descriptor as JSON.
+func (c *Compactor) RingDumpHandler(w http.ResponseWriter, req *http.Request) {
+	if !c.compactorCfg.ShardingEnabled {
+		http.Error(w, "Compactor has no ring because sharding is disabled.", http.StatusNotFound)
+		return
+	}
+
+	if c.State() != services.Running {
+		http.Error(w, "Compactor is not running yet.", http.StatusServiceUnavailable)
+		return
+	}
+
+	c.ring.DumpHandler(w, req)
+}
@@ -38,6 +38,8 @@ type compactorMetrics struct {
 	verticalCompactions         *prometheus.CounterVec
 	remainingPlannedCompactions *prometheus.GaugeVec
 	compactionErrorsCount       *prometheus.CounterVec
+	oldestUncompactedBlock      *prometheus.GaugeVec
+	lastSuccessfulCompaction    *prometheus.GaugeVec
 }
 
 const (
@@ -163,12 +165,20 @@ func newCompactorMetricsWithLabels(reg prometheus.Registerer, commonLabels []str
 	}, compactionLabels)
 	m.remainingPlannedCompactions = promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
 		Name: "cortex_compactor_remaining_planned_compactions",
-		Help: "Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy",
+		Help: "Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.",
 	}, commonLabels)
 	m.compactionErrorsCount = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 		Name: "cortex_compactor_compaction_error_total",
 		Help: "Total number of errors from compactions.",
 	}, append(commonLabels, compactionErrorTypesLabelName))
+	m.oldestUncompactedBlock = promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cortex_compactor_oldest_uncompacted_block_timestamp_seconds",
+		Help: "Unix timestamp of the creation of the oldest block of the tenant which has never been compacted, excluding the blocks marked for no compaction or without any block to be compacted with.",
+	}, commonLabels)
+	m.lastSuccessfulCompaction = promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cortex_compactor_tenant_last_successful_run_timestamp_seconds",
+		Help: "Unix timestamp of the last successful compaction of the tenant.",
+	}, commonLabels)
 
 	return &m
 }
@@ -200,6 +210,14 @@ func (m *compactorMetrics) getSyncerMetrics(userID string) *compact.SyncerMetric
 	return &syncerMetrics
 }
 
+// deleteProgressMetrics deletes the compaction progress metrics of a tenant no longer
+// compacted by the compactor.
+func (m *compactorMetrics) deleteProgressMetrics(userID string) {
+	m.remainingPlannedCompactions.DeleteLabelValues(userID)
+	m.oldestUncompactedBlock.DeleteLabelValues(userID)
+	m.lastSuccessfulCompaction.DeleteLabelValues(userID)
+}
+
 func (m *compactorMetrics) getCommonLabelValues(userID string) []string {
 	var labelValues []string
 	if len(m.commonLabels) > 0 {
@@ -22,17 +22,20 @@ type RingConfig struct {
 	HeartbeatPeriod  time.Duration `yaml:"heartbeat_period"`
 	HeartbeatTimeout time.Duration `yaml:"heartbeat_timeout"`
 
+	AutoForgetUnhealthyPeriod time.Duration `yaml:"auto_forget_unhealthy_period"`
+
 	// Wait ring stability.
 	WaitStabilityMinDuration time.Duration `yaml:"wait_stability_min_duration"`
 	WaitStabilityMaxDuration time.Duration `yaml:"wait_stability_max_duration"`
 
 	// Instance details
-	InstanceID             string   `yaml:"instance_id" doc:"hidden"`
-	InstanceInterfaceNames []string `yaml:"instance_interface_names"`
-	InstancePort           int      `yaml:"instance_port" doc:"hidden"`
-	InstanceAddr           string   `yaml:"instance_addr" doc:"hidden"`
-	TokensFilePath         string   `yaml:"tokens_file_path"`
-	UnregisterOnShutdown   bool     `yaml:"unregister_on_shutdown"`
+	InstanceID              string   `yaml:"instance_id" doc:"hidden"`
+	InstanceInterfaceNames  []string `yaml:"instance_interface_names"`
+	InstancePort            int      `yaml:"instance_port" doc:"hidden"`
+	InstanceAddr            string   `yaml:"instance_addr" doc:"hidden"`
+	TokensFilePath          string   `yaml:"tokens_file_path"`
+	TokensGeneratorStrategy string   `yaml:"tokens_generator_strategy"`
+	UnregisterOnShutdown    bool     `yaml:"unregister_on_shutdown"`
 
 	// Injected internally
 	ListenPort int `yaml:"-"`
@@ -54,6 +57,7 @@ func (cfg *RingConfig) RegisterFlags(f *flag.FlagSet) {
 	cfg.KVStore.RegisterFlagsWithPrefix("compactor.ring.", "collectors/", f)
 	f.DurationVar(&cfg.HeartbeatPeriod, "compactor.ring.heartbeat-period", 5*time.Second, "Period at which to heartbeat to the ring. 0 = disabled.")
 	f.DurationVar(&cfg.HeartbeatTimeout, "compactor.ring.heartbeat-timeout", time.Minute, "The heartbeat timeout after which compactors are considered unhealthy within the ring. 0 = never (timeout disabled).")
+	f.DurationVar(&cfg.AutoForgetUnhealthyPeriod, "compactor.ring.auto-forget-unhealthy-period", 0, "Automatically remove from the ring the compactors which have not heartbeated for this period, so that the tenants of the compactors which crashed and never came back are resharded to the healthy ones. Must be greater than the heartbeat timeout. 0 = disabled.")
 
 	// Wait stability flags.
 	f.DurationVar(&cfg.WaitStabilityMinDuration, "compactor.ring.wait-stability-min-duration", time.Minute, "Minimum time to wait for ring stability at startup. 0 to disable.")
@@ -66,6 +70,7 @@ func (cfg *RingConfig) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.InstancePort, "compactor.ring.instance-port", 0, "Port to advertise in the ring (defaults to server.grpc-listen-port).")
 	f.StringVar(&cfg.InstanceID, "compactor.ring.instance-id", hostname, "Instance ID to register in the ring.")
 	f.StringVar(&cfg.TokensFilePath, "compactor.ring.tokens-file-path", "", "File path where tokens are stored. If empty, tokens are not stored at shutdown and restored at startup.")
+	ring.RegisterTokensGeneratorStrategyFlag(f, "compactor.ring.tokens-generator-strategy", &cfg.TokensGeneratorStrategy)
 	f.BoolVar(&cfg.UnregisterOnShutdown, "compactor.ring.unregister-on-shutdown", true, "Unregister the compactor during shutdown if true.")
 
 	// Timeout durations
@@ -103,6 +108,8 @@ func (cfg *RingConfig) ToLifecyclerConfig() ring.LifecyclerConfig {
 	lc.MinReadyDuration = 0
 	lc.FinalSleep = 0
 	lc.TokensFilePath = cfg.TokensFilePath
+	lc.TokensGeneratorStrategy = cfg.TokensGeneratorStrategy
+	lc.AutoForgetUnhealthyPeriod = cfg.AutoForgetUnhealthyPeriod
 
 	// We use a safe default instead of exposing to config option to the user
 	// in order to simplify the config.
@@ -42,6 +42,7 @@ func TestRingConfig_CustomConfigToLifecyclerConfig(t *testing.T) {
 	cfg.InstanceAddr = "1.2.3.4"
 	cfg.ListenPort = 10
 	cfg.TokensFilePath = "testFilePath"
+	cfg.AutoForgetUnhealthyPeriod = time.Minute
 
 	// The lifecycler config should be generated based upon the compactor
 	// ring config
@@ -54,6 +55,7 @@ func TestRingConfig_CustomConfigToLifecyclerConfig(t *testing.T) {
 	expected.Addr = cfg.InstanceAddr
 	expected.ListenPort = cfg.ListenPort
 	expected.TokensFilePath = cfg.TokensFilePath
+	expected.AutoForgetUnhealthyPeriod = cfg.AutoForgetUnhealthyPeriod
 
 	// Hardcoded config
 	expected.RingConfig.ReplicationFactor = 1
@@ -14,6 +14,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -22,8 +23,12 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	prom_testutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/chunks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -140,6 +145,69 @@ func TestConfig_Validate(t *testing.T) {
 			initLimits: func(_ *validation.Limits) {},
 			expected:   errInvalidTenantShardSize.Error(),
 		},
+		"should fail with the partitioning compaction mode without the shuffle sharding strategy": {
+			setup: func(cfg *Config) {
+				cfg.ShardingEnabled = true
+				cfg.CompactionStrategy = util.CompactionStrategyPartitioning
+			},
+			initLimits: func(_ *validation.Limits) {},
+			expected:   errInvalidCompactionStrategyPartitioning.Error(),
+		},
+		"should fail with an auto-forget unhealthy period not greater than the ring heartbeat timeout": {
+			setup: func(cfg *Config) {
+				cfg.ShardingEnabled = true
+				cfg.ShardingRing.AutoForgetUnhealthyPeriod = cfg.ShardingRing.HeartbeatTimeout
+			},
+			initLimits: func(_ *validation.Limits) {},
+			expected:   "invalid compactor ring config: the auto-forget unhealthy period must be greater than the ring heartbeat timeout",
+		},
+		"should pass with the penalty deduplication func": {
+			setup: func(cfg *Config) {
+				cfg.DeduplicationFunc = DeduplicationFuncPenalty
+			},
+			initLimits: func(_ *validation.Limits) {},
+			expected:   "",
+		},
+		"should fail with an unsupported deduplication func": {
+			setup: func(cfg *Config) {
+				cfg.DeduplicationFunc = "unknown"
+			},
+			initLimits: func(_ *validation.Limits) {},
+			expected:   errInvalidDeduplicationFunc.Error(),
+		},
+		"should fail with an unsupported planner strategy": {
+			setup: func(cfg *Config) {
+				cfg.PlannerStrategy = "unknown"
+			},
+			initLimits: func(_ *validation.Limits) {},
+			expected:   errInvalidPlannerStrategy.Error(),
+		},
+		"should pass with the size-bounded planner strategy and shuffle sharding": {
+			setup: func(cfg *Config) {
+				cfg.ShardingEnabled = true
+				cfg.ShardingStrategy = util.ShardingStrategyShuffle
+				cfg.PlannerStrategy = PlannerStrategySizeBounded
+			},
+			initLimits: func(limits *validation.Limits) {
+				limits.CompactorTenantShardSize = 1
+			},
+			expected: "",
+		},
+		"should fail with the size-bounded planner strategy and no max index size": {
+			setup: func(cfg *Config) {
+				cfg.PlannerStrategy = PlannerStrategySizeBounded
+				cfg.PlannerMaxIndexSizeBytes = 0
+			},
+			initLimits: func(_ *validation.Limits) {},
+			expected:   errInvalidPlannerMaxIndexSize.Error(),
+		},
+		"should fail with zero tenants concurrency": {
+			setup: func(cfg *Config) {
+				cfg.TenantsConcurrency = 0
+			},
+			initLimits: func(_ *validation.Limits) {},
+			expected:   errInvalidTenantsConcurrency.Error(),
+		},
 	}
 
 	for testName, testData := range tests {
@@ -159,6 +227,115 @@ func TestConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestNewLeveledCompactor_DeduplicationFunc(t *testing.T) {
+	// Two replicas scraping the same target at different times.
+	replicaSamples := func(offset int64) []chunks.Sample {
+		var samples []chunks.Sample
+		for ts := offset; ts < 1000; ts += 100 {
+			samples = append(samples, sample{t: ts, v: float64(ts)})
+		}
+		return samples
+	}
+
+	for name, tc := range map[string]struct {
+		deduplicationFunc string
+		expectedSamples   int
+	}{
+		"only identical samples deduplicated": {
+			deduplicationFunc: DeduplicationFuncNone,
+			expectedSamples:   20,
+		},
+		"penalty deduplication": {
+			deduplicationFunc: DeduplicationFuncPenalty,
+			expectedSamples:   10,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			lbls := labels.FromStrings("__name__", "up")
+
+			var dirs []string
+			for _, offset := range []int64{0, 30} {
+				blockDir, err := tsdb.CreateBlock([]storage.Series{storage.NewListSeries(lbls, replicaSamples(offset))}, dir, 0, log.NewNopLogger())
+				require.NoError(t, err)
+				dirs = append(dirs, blockDir)
+			}
+
+			cfg := prepareConfig()
+			cfg.DeduplicationFunc = tc.deduplicationFunc
+			compactor, err := newLeveledCompactor(context.Background(), cfg, log.NewNopLogger(), nil)
+			require.NoError(t, err)
+
+			blockIDs, err := compactor.Compact(dir, dirs, nil)
+			require.NoError(t, err)
+			require.Len(t, blockIDs, 1)
+
+			b, err := tsdb.OpenBlock(log.NewNopLogger(), filepath.Join(dir, blockIDs[0].String()), nil)
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, b.Close()) })
+			assert.Equal(t, uint64(1), b.Meta().Stats.NumSeries)
+			assert.Equal(t, uint64(tc.expectedSamples), b.Meta().Stats.NumSamples)
+		})
+	}
+}
+
+func TestDefaultBlocksCompactorFactory_PlannerStrategy(t *testing.T) {
+	for name, tc := range map[string]struct {
+		plannerStrategy     string
+		expectedPlanned     int
+		expectedNoCompacted int
+	}{
+		"time-based": {
+			plannerStrategy: PlannerStrategyTimeBased,
+			expectedPlanned: 2,
+		},
+		"size-bounded": {
+			plannerStrategy:     PlannerStrategySizeBounded,
+			expectedNoCompacted: 1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			bkt, _ := cortex_storage_testutil.PrepareFilesystemBucket(t)
+
+			cfg := prepareConfig()
+			cfg.PlannerStrategy = tc.plannerStrategy
+			cfg.PlannerMaxIndexSizeBytes = 100
+
+			_, plannerFactory, err := DefaultBlocksCompactorFactory(ctx, cfg, log.NewNopLogger(), nil)
+			require.NoError(t, err)
+
+			// Two overlapping blocks whose compacted block would have an index larger than the limit.
+			var metas []*metadata.Meta
+			for i, indexSize := range []int64{60, 50} {
+				meta := &metadata.Meta{BlockMeta: tsdb.BlockMeta{ULID: ulid.MustNew(uint64(i+1), nil), MinTime: 0, MaxTime: 2 * time.Hour.Milliseconds()}}
+				meta.Thanos.Files = []metadata.File{{RelPath: block.IndexFilename, SizeBytes: indexSize}}
+				metas = append(metas, meta)
+			}
+
+			noCompactMarked := prometheus.NewCounter(prometheus.CounterOpts{})
+			noCompactFilter := compact.NewGatherNoCompactionMarkFilter(log.NewNopLogger(), bkt, 1)
+			planner := plannerFactory(ctx, bkt, log.NewNopLogger(), cfg, noCompactFilter, noCompactMarked, nil, "user-1", nil, nil, nil)
+
+			planned, err := planner.Plan(ctx, metas, nil, nil)
+			require.NoError(t, err)
+			assert.Len(t, planned, tc.expectedPlanned)
+			assert.Equal(t, float64(tc.expectedNoCompacted), prom_testutil.ToFloat64(noCompactMarked))
+		})
+	}
+}
+
+type sample struct {
+	t int64
+	v float64
+}
+
+func (s sample) T() int64                      { return s.t }
+func (s sample) F() float64                    { return s.v }
+func (s sample) H() *histogram.Histogram       { return nil }
+func (s sample) FH() *histogram.FloatHistogram { return nil }
+func (s sample) Type() chunkenc.ValueType      { return chunkenc.ValFloat }
+
 func TestCompactor_SkipCompactionWhenCmkError(t *testing.T) {
 	t.Parallel()
 	userID := "user-1"
@@ -172,6 +349,7 @@ func TestCompactor_SkipCompactionWhenCmkError(t *testing.T) {
 	bucketClient.MockIter("", []string{userID}, nil)
 	bucketClient.MockIter("__markers__", []string{}, nil)
 	bucketClient.MockIter(userID+"/", []string{}, nil)
+	bucketClient.MockIter(userID+"/tombstones/", nil, nil)
 	bucketClient.MockIter(userID+"/markers/", nil, nil)
 	bucketClient.MockGet(userID+"/markers/cleaner-visit-marker.json", "", nil)
 	bucketClient.MockUpload(userID+"/markers/cleaner-visit-marker.json", nil)
@@ -352,6 +530,7 @@ func TestCompactor_ShouldIncrementCompactionErrorIfFailedToCompactASingleTenant(
 	bucketClient.MockIter("", []string{userID}, nil)
 	bucketClient.MockIter("__markers__", []string{}, nil)
 	bucketClient.MockIter(userID+"/", []string{userID + "/01DTVP434PA9VFXSW2JKB3392D/meta.json", userID + "/01FN6CDF3PNEWWRY5MPGJPE3EX/meta.json"}, nil)
+	bucketClient.MockIter(userID+"/tombstones/", nil, nil)
 	bucketClient.MockIter(userID+"/markers/", nil, nil)
 	bucketClient.MockGet(userID+"/markers/cleaner-visit-marker.json", "", nil)
 	bucketClient.MockUpload(userID+"/markers/cleaner-visit-marker.json", nil)
@@ -410,6 +589,7 @@ func TestCompactor_ShouldCompactAndRemoveUserFolder(t *testing.T) {
 	bucketClient.MockExists(cortex_tsdb.GetGlobalDeletionMarkPath("user-1"), false, nil)
 	bucketClient.MockExists(cortex_tsdb.GetLocalDeletionMarkPath("user-1"), false, nil)
 	bucketClient.MockIter("user-1/", []string{"user-1/01DTVP434PA9VFXSW2JKB3392D/meta.json", "user-1/01FN6CDF3PNEWWRY5MPGJPE3EX/meta.json"}, nil)
+	bucketClient.MockIter("user-1/tombstones/", nil, nil)
 	bucketClient.MockIter("user-1/markers/", nil, nil)
 	bucketClient.MockGet("user-1/markers/cleaner-visit-marker.json", "", nil)
 	bucketClient.MockUpload("user-1/markers/cleaner-visit-marker.json", nil)
@@ -425,6 +605,7 @@ func TestCompactor_ShouldCompactAndRemoveUserFolder(t *testing.T) {
 	bucketClient.MockGet("user-1/01FN6CDF3PNEWWRY5MPGJPE3EX/visit-mark.json", "", nil)
 	bucketClient.MockGet("user-1/bucket-index.json.gz", "", nil)
 	bucketClient.MockGet("user-1/bucket-index-sync-status.json", "", nil)
+	bucketClient.MockIter("user-1/tombstones/", nil, nil)
 	bucketClient.MockIter("user-1/markers/", nil, nil)
 	bucketClient.MockUpload("user-1/bucket-index.json.gz", nil)
 	bucketClient.MockUpload("user-1/bucket-index-sync-status.json", nil)
@@ -449,6 +630,51 @@ func TestCompactor_ShouldCompactAndRemoveUserFolder(t *testing.T) {
 	require.True(t, os.IsNotExist(err))
 }
 
+func TestCompactor_ShouldCompactTenantsConcurrently(t *testing.T) {
+	bucketClient, _ := cortex_storage_testutil.PrepareFilesystemBucket(t)
+	bucketClient = bucketindex.BucketWithGlobalMarkers(bucketClient)
+
+	for _, userID := range []string{"user-1", "user-2"} {
+		createTSDBBlock(t, bucketClient, userID, 10, 20, nil)
+		createTSDBBlock(t, bucketClient, userID, 20, 30, nil)
+	}
+
+	cfg := prepareConfig()
+	cfg.TenantsConcurrency = 2
+	c, _, tsdbPlanner, _, registry := prepare(t, cfg, bucketClient, nil)
+
+	// Each tenant waits for the other one to be in the planning, which only
+	// happens when they're compacted concurrently.
+	planning := sync.WaitGroup{}
+	planning.Add(2)
+	tsdbPlanner.On("Plan", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		planning.Done()
+		planning.Wait()
+	}).Return([]*metadata.Meta{}, nil).Twice()
+	tsdbPlanner.On("Plan", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]*metadata.Meta{}, nil)
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	defer services.StopAndAwaitTerminated(context.Background(), c) //nolint:errcheck
+
+	cortex_testutil.Poll(t, 5*time.Second, 1.0, func() interface{} {
+		return prom_testutil.ToFloat64(c.CompactionRunsCompleted)
+	})
+
+	assert.NoError(t, prom_testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_compactor_tenants_processing_queued Number of tenants waiting to be processed during the current compaction run. Reset to 0 when compactor is idle.
+		# TYPE cortex_compactor_tenants_processing_queued gauge
+		cortex_compactor_tenants_processing_queued 0
+
+		# HELP cortex_compactor_tenants_processing_in_progress Number of tenants being compacted during the current compaction run. Reset to 0 when compactor is idle.
+		# TYPE cortex_compactor_tenants_processing_in_progress gauge
+		cortex_compactor_tenants_processing_in_progress 0
+
+		# HELP cortex_compactor_runs_failed_total Total number of compaction runs failed.
+		# TYPE cortex_compactor_runs_failed_total counter
+		cortex_compactor_runs_failed_total 0
+	`), "cortex_compactor_tenants_processing_queued", "cortex_compactor_tenants_processing_in_progress", "cortex_compactor_runs_failed_total"))
+}
+
 func TestCompactor_ShouldIterateOverUsersAndRunCompaction(t *testing.T) {
 	t.Parallel()
 
@@ -462,10 +688,12 @@ func TestCompactor_ShouldIterateOverUsersAndRunCompaction(t *testing.T) {
 	bucketClient.MockExists(cortex_tsdb.GetLocalDeletionMarkPath("user-2"), false, nil)
 	bucketClient.MockIter("user-1/", []string{"user-1/01DTVP434PA9VFXSW2JKB3392D/meta.json", "user-1/01FN6CDF3PNEWWRY5MPGJPE3EX/meta.json"}, nil)
 	bucketClient.MockIter("user-2/", []string{"user-2/01DTW0ZCPDDNV4BV83Q2SV4QAZ/meta.json", "user-2/01FN3V83ABR9992RF8WRJZ76ZQ/meta.json"}, nil)
+	bucketClient.MockIter("user-1/tombstones/", nil, nil)
 	bucketClient.MockIter("user-1/markers/", nil, nil)
 	bucketClient.MockGet("user-1/markers/cleaner-visit-marker.json", "", nil)
 	bucketClient.MockUpload("user-1/markers/cleaner-visit-marker.json", nil)
 	bucketClient.MockDelete("user-1/markers/cleaner-visit-marker.json", nil)
+	bucketClient.MockIter("user-2/tombstones/", nil, nil)
 	bucketClient.MockIter("user-2/markers/", nil, nil)
 	bucketClient.MockGet("user-2/markers/cleaner-visit-marker.json", "", nil)
 	bucketClient.MockUpload("user-2/markers/cleaner-visit-marker.json", nil)
@@ -491,7 +719,9 @@ func TestCompactor_ShouldIterateOverUsersAndRunCompaction(t *testing.T) {
 	bucketClient.MockGet("user-2/bucket-index.json.gz", "", nil)
 	bucketClient.MockGet("user-1/bucket-index-sync-status.json", "", nil)
 	bucketClient.MockGet("user-2/bucket-index-sync-status.json", "", nil)
+	bucketClient.MockIter("user-1/tombstones/", nil, nil)
 	bucketClient.MockIter("user-1/markers/", nil, nil)
+	bucketClient.MockIter("user-2/tombstones/", nil, nil)
 	bucketClient.MockIter("user-2/markers/", nil, nil)
 	bucketClient.MockUpload("user-1/bucket-index.json.gz", nil)
 	bucketClient.MockUpload("user-2/bucket-index.json.gz", nil)
@@ -620,6 +850,7 @@ func TestCompactor_ShouldNotCompactBlocksMarkedForDeletion(t *testing.T) {
 		"user-1/01DTW0ZCPDDNV4BV83Q2SV4QAZ/deletion-mark.json",
 	}, nil)
 
+	bucketClient.MockIter("user-1/tombstones/", nil, nil)
 	bucketClient.MockIter("user-1/markers/", []string{
 		"user-1/markers/01DTVP434PA9VFXSW2JKB3392D-deletion-mark.json",
 		"user-1/markers/01DTW0ZCPDDNV4BV83Q2SV4QAZ-deletion-mark.json",
@@ -727,10 +958,12 @@ func TestCompactor_ShouldNotCompactBlocksMarkedForSkipCompact(t *testing.T) {
 	bucketClient.MockExists(cortex_tsdb.GetLocalDeletionMarkPath("user-2"), false, nil)
 	bucketClient.MockIter("user-1/", []string{"user-1/01DTVP434PA9VFXSW2JKB3392D/meta.json", "user-1/01FN6CDF3PNEWWRY5MPGJPE3EX/meta.json"}, nil)
 	bucketClient.MockIter("user-2/", []string{"user-2/01DTW0ZCPDDNV4BV83Q2SV4QAZ/meta.json", "user-2/01FN3V83ABR9992RF8WRJZ76ZQ/meta.json"}, nil)
+	bucketClient.MockIter("user-1/tombstones/", nil, nil)
 	bucketClient.MockIter("user-1/markers/", nil, nil)
 	bucketClient.MockGet("user-1/markers/cleaner-visit-marker.json", "", nil)
 	bucketClient.MockUpload("user-1/markers/cleaner-visit-marker.json", nil)
 	bucketClient.MockDelete("user-1/markers/cleaner-visit-marker.json", nil)
+	bucketClient.MockIter("user-2/tombstones/", nil, nil)
 	bucketClient.MockIter("user-2/markers/", nil, nil)
 	bucketClient.MockGet("user-2/markers/cleaner-visit-marker.json", "", nil)
 	bucketClient.MockUpload("user-2/markers/cleaner-visit-marker.json", nil)
@@ -762,7 +995,9 @@ func TestCompactor_ShouldNotCompactBlocksMarkedForSkipCompact(t *testing.T) {
 	bucketClient.MockGet("user-2/bucket-index.json.gz", "", nil)
 	bucketClient.MockGet("user-1/bucket-index-sync-status.json", "", nil)
 	bucketClient.MockGet("user-2/bucket-index-sync-status.json", "", nil)
+	bucketClient.MockIter("user-1/tombstones/", nil, nil)
 	bucketClient.MockIter("user-1/markers/", nil, nil)
+	bucketClient.MockIter("user-2/tombstones/", nil, nil)
 	bucketClient.MockIter("user-2/markers/", nil, nil)
 	bucketClient.MockUpload("user-1/bucket-index.json.gz", nil)
 	bucketClient.MockUpload("user-2/bucket-index.json.gz", nil)
@@ -975,10 +1210,12 @@ func TestCompactor_ShouldCompactAllUsersOnShardingEnabledButOnlyOneInstanceRunni
 	bucketClient.MockExists(cortex_tsdb.GetLocalDeletionMarkPath("user-2"), false, nil)
 	bucketClient.MockIter("user-1/", []string{"user-1/01DTVP434PA9VFXSW2JKB3392D/meta.json", "user-1/01FN6CDF3PNEWWRY5MPGJPE3EX/meta.json"}, nil)
 	bucketClient.MockIter("user-2/", []string{"user-2/01DTW0ZCPDDNV4BV83Q2SV4QAZ/meta.json", "user-2/01FN3V83ABR9992RF8WRJZ76ZQ/meta.json"}, nil)
+	bucketClient.MockIter("user-1/tombstones/", nil, nil)
 	bucketClient.MockIter("user-1/markers/", nil, nil)
 	bucketClient.MockGet("user-1/markers/cleaner-visit-marker.json", "", nil)
 	bucketClient.MockUpload("user-1/markers/cleaner-visit-marker.json", nil)
 	bucketClient.MockDelete("user-1/markers/cleaner-visit-marker.json", nil)
+	bucketClient.MockIter("user-2/tombstones/", nil, nil)
 	bucketClient.MockIter("user-2/markers/", nil, nil)
 	bucketClient.MockGet("user-2/markers/cleaner-visit-marker.json", "", nil)
 	bucketClient.MockUpload("user-2/markers/cleaner-visit-marker.json", nil)
@@ -1080,6 +1317,7 @@ func TestCompactor_ShouldCompactOnlyUsersOwnedByTheInstanceOnShardingEnabledAndM
 	bucketClient.MockIter("__markers__", []string{}, nil)
 	for _, userID := range userIDs {
 		bucketClient.MockIter(userID+"/", []string{userID + "/01DTVP434PA9VFXSW2JKB3392D"}, nil)
+		bucketClient.MockIter(userID+"/tombstones/", nil, nil)
 		bucketClient.MockIter(userID+"/markers/", nil, nil)
 		bucketClient.MockGet(userID+"/markers/cleaner-visit-marker.json", "", nil)
 		bucketClient.MockUpload(userID+"/markers/cleaner-visit-marker.json", nil)
@@ -1217,6 +1455,7 @@ func TestCompactor_ShouldCompactOnlyShardsOwnedByTheInstanceOnShardingEnabledWit
 		}
 
 		bucketClient.MockIter(userID+"/", blockFiles, nil)
+		bucketClient.MockIter(userID+"/tombstones/", nil, nil)
 		bucketClient.MockIter(userID+"/markers/", nil, nil)
 		bucketClient.MockGet(userID+"/markers/cleaner-visit-marker.json", "", nil)
 		bucketClient.MockUpload(userID+"/markers/cleaner-visit-marker.json", nil)
@@ -1563,7 +1802,7 @@ func prepare(t *testing.T, compactorCfg Config, bucketClient objstore.Instrument
 
 	blocksCompactorFactory := func(ctx context.Context, cfg Config, logger log.Logger, reg prometheus.Registerer) (compact.Compactor, PlannerFactory, error) {
 		return tsdbCompactor,
-			func(ctx context.Context, bkt objstore.InstrumentedBucket, _ log.Logger, _ Config, noCompactMarkFilter *compact.GatherNoCompactionMarkFilter, ringLifecycle *ring.Lifecycler, _ string, _ prometheus.Counter, _ prometheus.Counter, _ *compactorMetrics) compact.Planner {
+			func(ctx context.Context, bkt objstore.InstrumentedBucket, _ log.Logger, _ Config, noCompactMarkFilter *compact.GatherNoCompactionMarkFilter, _ prometheus.Counter, ringLifecycle *ring.Lifecycler, _ string, _ prometheus.Counter, _ prometheus.Counter, _ *compactorMetrics) compact.Planner {
 				tsdbPlanner.noCompactMarkFilters = append(tsdbPlanner.noCompactMarkFilters, noCompactMarkFilter)
 				return tsdbPlanner
 			},
@@ -1914,6 +2153,7 @@ func TestCompactor_ShouldNotFailCompactionIfAccessDeniedErrDuringMetaSync(t *tes
 	bucketClient.MockIter("__markers__", []string{}, nil)
 	bucketClient.MockIter("", []string{"user-1"}, nil)
 	bucketClient.MockIter("user-1/", []string{"user-1/01DTVP434PA9VFXSW2JKB3392D", "user-1/01DTW0ZCPDDNV4BV83Q2SV4QAZ", "user-1/01DTVP434PA9VFXSW2JKB3392D/meta.json", "user-1/01DTW0ZCPDDNV4BV83Q2SV4QAZ/meta.json"}, nil)
+	bucketClient.MockIter("user-1/tombstones/", nil, nil)
 	bucketClient.MockIter("user-1/markers/", nil, nil)
 	bucketClient.MockGet("user-1/markers/cleaner-visit-marker.json", "", nil)
 	bucketClient.MockUpload("user-1/markers/cleaner-visit-marker.json", nil)
@@ -1964,6 +2204,7 @@ func TestCompactor_ShouldNotFailCompactionIfAccessDeniedErrReturnedFromBucket(t
 	bucketClient.MockIter("__markers__", []string{}, nil)
 	bucketClient.MockIter("", []string{"user-1"}, nil)
 	bucketClient.MockIter("user-1/", []string{"user-1/01DTVP434PA9VFXSW2JKB3392D", "user-1/01DTW0ZCPDDNV4BV83Q2SV4QAZ", "user-1/01DTVP434PA9VFXSW2JKB3392D/meta.json", "user-1/01DTW0ZCPDDNV4BV83Q2SV4QAZ/meta.json"}, nil)
+	bucketClient.MockIter("user-1/tombstones/", nil, nil)
 	bucketClient.MockIter("user-1/markers/", nil, nil)
 	bucketClient.MockGet("user-1/markers/cleaner-visit-marker.json", "", nil)
 	bucketClient.MockUpload("user-1/markers/cleaner-visit-marker.json", nil)
@@ -2014,6 +2255,7 @@ func TestCompactor_FailedWithRetriableError(t *testing.T) {
 	bucketClient.MockIter("__markers__", []string{}, nil)
 	bucketClient.MockIter("", []string{"user-1"}, nil)
 	bucketClient.MockIter("user-1/", []string{"user-1/01DTVP434PA9VFXSW2JKB3392D", "user-1/01DTW0ZCPDDNV4BV83Q2SV4QAZ", "user-1/01DTVP434PA9VFXSW2JKB3392D/meta.json", "user-1/01DTW0ZCPDDNV4BV83Q2SV4QAZ/meta.json"}, nil)
+	bucketClient.MockIter("user-1/tombstones/", nil, nil)
 	bucketClient.MockIter("user-1/markers/", nil, nil)
 	bucketClient.MockGet("user-1/markers/cleaner-visit-marker.json", "", nil)
 	bucketClient.MockUpload("user-1/markers/cleaner-visit-marker.json", nil)
@@ -2067,6 +2309,7 @@ func TestCompactor_FailedWithHaltError(t *testing.T) {
 	bucketClient.MockIter("__markers__", []string{}, nil)
 	bucketClient.MockIter("", []string{"user-1"}, nil)
 	bucketClient.MockIter("user-1/", []string{"user-1/01DTVP434PA9VFXSW2JKB3392D", "user-1/01DTW0ZCPDDNV4BV83Q2SV4QAZ", "user-1/01DTVP434PA9VFXSW2JKB3392D/meta.json", "user-1/01DTW0ZCPDDNV4BV83Q2SV4QAZ/meta.json"}, nil)
+	bucketClient.MockIter("user-1/tombstones/", nil, nil)
 	bucketClient.MockIter("user-1/markers/", nil, nil)
 	bucketClient.MockGet("user-1/markers/cleaner-visit-marker.json", "", nil)
 	bucketClient.MockUpload("user-1/markers/cleaner-visit-marker.json", nil)
@@ -0,0 +1,75 @@
+package compactor
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/tsdb/encoding"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/thanos-io/thanos/pkg/compact"
+	"github.com/thanos-io/thanos/pkg/errutil"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+)
+
+// CorruptedBlockNoCompactReason is the reason of the no-compact marks of the blocks which
+// can't be compacted because their index is corrupted.
+const CorruptedBlockNoCompactReason metadata.NoCompactReason = "block-index-corrupted"
+
+// corruptedBlockErrorRegex matches the errors returned by the Thanos compactor when the index of a
+// block to compact can't be read or is not healthy. The Thanos compactor doesn't expose the ID of the
+// block in these errors, so it's taken from the path of the block in the error, once the error has
+// been checked to be caused by a corrupted index.
+var corruptedBlockErrorRegex = regexp.MustCompile(`(?:block with not healthy index found|gather index issues for block) \S*?([0-7][0-9A-HJKMNP-TV-Z]{25})\b`)
+
+// corruptedBlockID returns the ID of the block whose corrupted index failed the compaction, if any.
+func corruptedBlockID(err error) (ulid.ULID, bool) {
+	// The Thanos compactor returns the errors of the compaction groups in a multi-error.
+	groupErrs := []error{err}
+	var multiErr errutil.NonNilMultiError
+	if errors.As(err, &multiErr) {
+		groupErrs = multiErr
+	}
+
+	for _, groupErr := range groupErrs {
+		if !isIndexCorruptionError(groupErr) {
+			continue
+		}
+
+		matches := corruptedBlockErrorRegex.FindStringSubmatch(groupErr.Error())
+		if matches == nil {
+			continue
+		}
+		if id, parseErr := ulid.Parse(matches[1]); parseErr == nil {
+			return id, true
+		}
+	}
+	return ulid.ULID{}, false
+}
+
+// isIndexCorruptionError returns whether the error has been caused by a block index which is not
+// healthy, which halts the Thanos compactor, or which can't be read because it's corrupted.
+func isIndexCorruptionError(err error) bool {
+	return compact.IsHaltError(err) || errors.Is(err, encoding.ErrInvalidSize) || errors.Is(err, encoding.ErrInvalidChecksum)
+}
+
+// markCorruptedBlockForNoCompaction marks a corrupted block for no compaction, with the compaction
+// error as details, so that the compaction of the other blocks of the tenant can go on.
+func (c *Compactor) markCorruptedBlockForNoCompaction(ctx context.Context, userID string, blockID ulid.ULID, compactionErr error) error {
+	userBucket := bucket.NewUserBucketClient(userID, c.bucketClient, c.limits)
+	userLogger := util_log.WithUserID(userID, c.logger)
+
+	if err := block.MarkForNoCompact(ctx, userLogger, userBucket, blockID, CorruptedBlockNoCompactReason, compactionErr.Error(), c.BlocksMarkedForNoCompaction); err != nil {
+		level.Error(userLogger).Log("msg", "failed to mark corrupted block for no compaction", "block", blockID, "err", err)
+		return err
+	}
+
+	c.CorruptedBlocksMarkedForNoCompaction.Inc()
+	level.Warn(userLogger).Log("msg", "marked corrupted block for no compaction", "block", blockID, "err", compactionErr)
+	return nil
+}
@@ -0,0 +1,124 @@
+package compactor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	prom_testutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/encoding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/thanos-io/thanos/pkg/errutil"
+
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	cortex_storage_testutil "github.com/cortexproject/cortex/pkg/storage/tsdb/testutil"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	cortex_testutil "github.com/cortexproject/cortex/pkg/util/test"
+)
+
+func TestCorruptedBlockID(t *testing.T) {
+	blockID := ulid.MustNew(1, nil)
+	otherBlockID := ulid.MustNew(2, nil)
+
+	gatherIndexIssuesErr := func(id ulid.ULID, cause error) error {
+		return errors.Wrapf(errors.Wrap(cause, "open index file"), "gather index issues for block /data/compact/0@123/%s", id)
+	}
+
+	for name, tc := range map[string]struct {
+		err        error
+		expectedOK bool
+	}{
+		"index with an invalid size": {
+			err:        errors.Wrap(errutil.NonNilMultiError{errors.Wrap(gatherIndexIssuesErr(blockID, fmt.Errorf("index header: %w", encoding.ErrInvalidSize)), "group 0@123")}, "compaction"),
+			expectedOK: true,
+		},
+		"index with an invalid checksum": {
+			err:        gatherIndexIssuesErr(blockID, fmt.Errorf("read TOC: %w", encoding.ErrInvalidChecksum)),
+			expectedOK: true,
+		},
+		"index corrupted in one of the groups": {
+			err: errors.Wrap(errutil.NonNilMultiError{
+				errors.Wrap(gatherIndexIssuesErr(otherBlockID, os.ErrNotExist), "group 0@123"),
+				errors.Wrap(gatherIndexIssuesErr(blockID, encoding.ErrInvalidChecksum), "group 0@456"),
+			}, "compaction"),
+			expectedOK: true,
+		},
+		"index not readable because of an error other than a corruption": {
+			err: gatherIndexIssuesErr(blockID, os.ErrNotExist),
+		},
+		"other error": {
+			err: errors.Errorf("compaction: group 0@123: download block %s: %s", blockID, encoding.ErrInvalidChecksum),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual, ok := corruptedBlockID(tc.err)
+			require.Equal(t, tc.expectedOK, ok)
+			if ok {
+				assert.Equal(t, blockID, actual)
+			}
+		})
+	}
+}
+
+func TestCompactor_ShouldSkipCorruptedBlocks(t *testing.T) {
+	bucketClient, tmpDir := cortex_storage_testutil.PrepareFilesystemBucket(t)
+	bucketClient = bucketindex.BucketWithGlobalMarkers(bucketClient)
+
+	b1 := createTSDBBlock(t, bucketClient, "user-1", 10, 20, nil)
+	b2 := createTSDBBlock(t, bucketClient, "user-1", 20, 30, nil)
+
+	// Corrupt the index of the first block, truncating it.
+	dir := path.Join(tmpDir, "user-1", b1.String())
+	info, err := os.Stat(path.Join(dir, "index"))
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path.Join(dir, "index"), info.Size()/2))
+
+	cfg := prepareConfig()
+	cfg.SkipCorruptedBlocksEnabled = true
+	c, tsdbCompac, tsdbPlanner, logs, registry := prepare(t, cfg, bucketClient, nil)
+
+	tsdbCompac.On("CompactWithBlockPopulator", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]ulid.ULID{b1}, nil)
+	tsdbPlanner.On("Plan", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]*metadata.Meta{
+		{BlockMeta: tsdb.BlockMeta{ULID: b1, MinTime: 10, MaxTime: 20}},
+		{BlockMeta: tsdb.BlockMeta{ULID: b2, MinTime: 20, MaxTime: 30}},
+	}, nil).Once()
+	tsdbPlanner.On("Plan", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]*metadata.Meta{}, nil)
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	defer services.StopAndAwaitTerminated(context.Background(), c) //nolint:errcheck
+
+	// Wait until the corrupted block has been marked for no compaction.
+	cortex_testutil.Poll(t, 5*time.Second, true, func() interface{} {
+		_, err := os.Stat(path.Join(dir, metadata.NoCompactMarkFilename))
+		return err == nil
+	})
+
+	// The compaction of the tenant goes on without the corrupted block.
+	cortex_testutil.Poll(t, 5*time.Second, true, func() interface{} {
+		return strings.Contains(logs.String(), `msg="successfully compacted user blocks" user=user-1`)
+	})
+
+	content, err := os.ReadFile(path.Join(dir, metadata.NoCompactMarkFilename))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), string(CorruptedBlockNoCompactReason))
+
+	assert.NoError(t, prom_testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_compactor_blocks_marked_for_no_compaction_total Total number of blocks marked for no compact during a compaction run.
+		# TYPE cortex_compactor_blocks_marked_for_no_compaction_total counter
+		cortex_compactor_blocks_marked_for_no_compaction_total 1
+
+		# HELP cortex_compactor_corrupted_blocks_marked_for_no_compaction_total Total number of blocks with a corrupted index marked for no compact during a compaction run.
+		# TYPE cortex_compactor_corrupted_blocks_marked_for_no_compaction_total counter
+		cortex_compactor_corrupted_blocks_marked_for_no_compaction_total 1
+	`), "cortex_compactor_blocks_marked_for_no_compaction_total", "cortex_compactor_corrupted_blocks_marked_for_no_compaction_total"))
+}
@@ -1,38 +1,150 @@
 package compactor
 
 import (
-	"context"
+	"sync"
 
-	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/oklog/ulid"
-	"github.com/thanos-io/objstore"
+	"github.com/pkg/errors"
+	"github.com/thanos-io/thanos/pkg/block"
 	"github.com/thanos-io/thanos/pkg/block/metadata"
 	"github.com/thanos-io/thanos/pkg/compact"
 )
 
+// PartitionCompactionGrouper groups the blocks like the ShuffleShardingGrouper, but into
+// the groups of each partition of the series of the tenant: the blocks are first split
+// into partitions by the hash of the series labels, and the blocks of each partition are
+// then compacted independently, so that the blocks of the tenants too large to be compacted
+// into a single block are compacted in parallel.
 type PartitionCompactionGrouper struct {
-	ctx    context.Context
-	logger log.Logger
-	bkt    objstore.InstrumentedBucket
-}
+	*ShuffleShardingGrouper
 
-func NewPartitionCompactionGrouper(
-	ctx context.Context,
-	logger log.Logger,
-	bkt objstore.InstrumentedBucket,
-) *PartitionCompactionGrouper {
-	if logger == nil {
-		logger = log.NewNopLogger()
-	}
+	// outsidePartitionBlocks are the blocks of the groups holding the series of more than
+	// one partition, which must be compacted into each partition before being deleted.
+	outsidePartitionBlocksMtx sync.Mutex
+	outsidePartitionBlocks    map[ulid.ULID]struct{}
+}
 
+func NewPartitionCompactionGrouper(shuffleShardingGrouper *ShuffleShardingGrouper) *PartitionCompactionGrouper {
 	return &PartitionCompactionGrouper{
-		ctx:    ctx,
-		logger: logger,
-		bkt:    bkt,
+		ShuffleShardingGrouper: shuffleShardingGrouper,
+		outsidePartitionBlocks: map[ulid.ULID]struct{}{},
 	}
 }
 
-// Groups function modified from https://github.com/cortexproject/cortex/pull/2616
+// Groups returns the compaction groups of each partition of the tenant.
 func (g *PartitionCompactionGrouper) Groups(blocks map[ulid.ULID]*metadata.Meta) (res []*compact.Group, err error) {
-	panic("PartitionCompactionGrouper not implemented")
+	partitionCount := max(g.limits.CompactorPartitionCount(g.userID), 1)
+	outsidePartitionBlocks := map[ulid.ULID]struct{}{}
+
+	res, err = g.groups(blocks, func(mainBlocks []*metadata.Meta) ([]blocksGroup, error) {
+		partitions, err := g.partitionBlocks(mainBlocks, partitionCount)
+		if err != nil {
+			return nil, err
+		}
+
+		var groups []blocksGroup
+		for id, partitionBlocks := range partitions {
+			partitionInfo := &PartitionInfo{PartitionCount: partitionCount, PartitionID: id}
+			for _, b := range partitionBlocks {
+				if partitionCount > 1 && !partitionInfo.isPartitionOf(b) {
+					outsidePartitionBlocks[b.ULID] = struct{}{}
+				}
+			}
+			for _, group := range groupBlocksByCompactableRanges(partitionBlocks, g.compactorCfg.BlockRanges.ToMilliseconds()) {
+				group.partitionInfo = partitionInfo
+				groups = append(groups, group)
+			}
+		}
+		return groups, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g.outsidePartitionBlocksMtx.Lock()
+	g.outsidePartitionBlocks = outsidePartitionBlocks
+	g.outsidePartitionBlocksMtx.Unlock()
+
+	return res, nil
+}
+
+// CanDelete implements compact.BlockDeletableChecker. The blocks holding the series of
+// more than one partition are only deleted once they have been compacted into all the
+// partitions.
+func (g *PartitionCompactionGrouper) CanDelete(_ *compact.Group, blockID ulid.ULID) bool {
+	g.outsidePartitionBlocksMtx.Lock()
+	defer g.outsidePartitionBlocksMtx.Unlock()
+
+	_, ok := g.outsidePartitionBlocks[blockID]
+	return !ok
+}
+
+// partitionBlocks returns the blocks to compact into each partition. The blocks of another
+// partition count, or which have not been partitioned, are compacted into each partition
+// which has no block compacted from them yet, and are marked for deletion once compacted
+// into all the partitions.
+func (g *PartitionCompactionGrouper) partitionBlocks(blocks []*metadata.Meta, partitionCount int) ([][]*metadata.Meta, error) {
+	partitions := make([][]*metadata.Meta, partitionCount)
+	var outsidePartitionBlocks []*metadata.Meta
+	for _, b := range blocks {
+		if info := partitionInfoOf(b); info.PartitionCount == partitionCount {
+			partitions[info.PartitionID] = append(partitions[info.PartitionID], b)
+		} else {
+			outsidePartitionBlocks = append(outsidePartitionBlocks, b)
+		}
+	}
+
+	uncompactedBlocks := make([][]*metadata.Meta, partitionCount)
+	for _, b := range outsidePartitionBlocks {
+		compacted := true
+		for id := range partitions {
+			ok, err := g.isCompactedIntoPartition(b, partitions[id], PartitionInfo{PartitionCount: partitionCount, PartitionID: id})
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				uncompactedBlocks[id] = append(uncompactedBlocks[id], b)
+				compacted = false
+			}
+		}
+		if !compacted {
+			continue
+		}
+
+		level.Info(g.logger).Log("msg", "marking for deletion block compacted into all the partitions", "block", b.ULID.String(), "partition_count", partitionCount)
+		if err := block.MarkForDeletion(g.ctx, g.logger, g.bkt, b.ULID, "compacted into all the partitions", g.syncerMetrics.BlocksMarkedForDeletion); err != nil {
+			return nil, errors.Wrapf(err, "mark block %s for deletion", b.ULID)
+		}
+	}
+
+	for id := range partitions {
+		partitions[id] = append(partitions[id], uncompactedBlocks[id]...)
+	}
+	return partitions, nil
+}
+
+// isCompactedIntoPartition returns whether the block has been compacted into one of the
+// blocks of the partition, or has no series of the partition.
+func (g *PartitionCompactionGrouper) isCompactedIntoPartition(b *metadata.Meta, partitionBlocks []*metadata.Meta, partitionInfo PartitionInfo) (bool, error) {
+	for _, p := range partitionBlocks {
+		if containsSources(p, b) {
+			return true, nil
+		}
+	}
+	return hasEmptyPartitionMarker(g.ctx, g.bkt, b.ULID, partitionInfo)
+}
+
+// containsSources returns whether all the source blocks of b are sources of p.
+func containsSources(p, b *metadata.Meta) bool {
+	sources := make(map[ulid.ULID]struct{}, len(p.Compaction.Sources))
+	for _, s := range p.Compaction.Sources {
+		sources[s] = struct{}{}
+	}
+	for _, s := range b.Compaction.Sources {
+		if _, ok := sources[s]; !ok {
+			return false
+		}
+	}
+	return len(b.Compaction.Sources) > 0
 }
@@ -0,0 +1,215 @@
+package compactor
+
+import (
+	"context"
+	"path"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestPartitionCompactionGrouper_Groups(t *testing.T) {
+	block1 := ulid.MustNew(1, nil)
+	block2 := ulid.MustNew(2, nil)
+	partition0Block := ulid.MustNew(3, nil)
+	partition1Block := ulid.MustNew(4, nil)
+	partition0Block1h := ulid.MustNew(5, nil)
+	partition0Block2h := ulid.MustNew(6, nil)
+	partition1Block1h := ulid.MustNew(7, nil)
+
+	newMeta := func(id ulid.ULID, minT, maxT time.Duration, partitionInfo *PartitionInfo, sources ...ulid.ULID) *metadata.Meta {
+		if len(sources) == 0 {
+			sources = []ulid.ULID{id}
+		}
+		m := &metadata.Meta{
+			BlockMeta: tsdb.BlockMeta{ULID: id, MinTime: minT.Milliseconds(), MaxTime: maxT.Milliseconds(), Compaction: tsdb.BlockMetaCompaction{Sources: sources}},
+			Thanos:    metadata.Thanos{Labels: map[string]string{"external": "1"}},
+		}
+		if partitionInfo != nil {
+			m.Thanos.Extensions = &CortexMetaExtensions{PartitionInfo: partitionInfo}
+		}
+		return m
+	}
+	partition0 := &PartitionInfo{PartitionCount: 2, PartitionID: 0}
+	partition1 := &PartitionInfo{PartitionCount: 2, PartitionID: 1}
+
+	type expectedGroup struct {
+		ids           []ulid.ULID
+		partitionInfo *PartitionInfo
+	}
+
+	tests := map[string]struct {
+		partitionCount         int
+		blocks                 []*metadata.Meta
+		emptyPartitionMarkers  map[ulid.ULID]PartitionInfo
+		expected               []expectedGroup
+		expectedNonDeletable   []ulid.ULID
+		expectedMarkedDeletion []ulid.ULID
+	}{
+		"should compact the blocks into a single partition by default": {
+			partitionCount: 1,
+			blocks:         []*metadata.Meta{newMeta(block1, 0, 2*time.Hour, nil), newMeta(block2, 0, 2*time.Hour, nil)},
+			expected: []expectedGroup{
+				{ids: []ulid.ULID{block1, block2}, partitionInfo: &PartitionInfo{PartitionCount: 1}},
+			},
+		},
+		"should compact the unpartitioned blocks into each partition": {
+			partitionCount: 2,
+			blocks:         []*metadata.Meta{newMeta(block1, 0, 2*time.Hour, nil), newMeta(block2, 0, 2*time.Hour, nil)},
+			expected: []expectedGroup{
+				{ids: []ulid.ULID{block1, block2}, partitionInfo: partition0},
+				{ids: []ulid.ULID{block1, block2}, partitionInfo: partition1},
+			},
+			expectedNonDeletable: []ulid.ULID{block1, block2},
+		},
+		"should not compact the unpartitioned blocks again into the partitions they have been compacted into": {
+			partitionCount: 2,
+			blocks: []*metadata.Meta{
+				newMeta(block1, 0, 2*time.Hour, nil),
+				newMeta(block2, 0, 2*time.Hour, nil),
+				newMeta(partition0Block, 0, 2*time.Hour, partition0, block1, block2),
+			},
+			expected: []expectedGroup{
+				{ids: []ulid.ULID{block1, block2}, partitionInfo: partition1},
+			},
+			expectedNonDeletable: []ulid.ULID{block1, block2},
+		},
+		"should mark for deletion the unpartitioned blocks compacted into all the partitions": {
+			partitionCount: 2,
+			blocks: []*metadata.Meta{
+				newMeta(block1, 0, 2*time.Hour, nil),
+				newMeta(block2, 0, 2*time.Hour, nil),
+				newMeta(partition0Block, 0, 2*time.Hour, partition0, block1, block2),
+				newMeta(partition1Block, 0, 2*time.Hour, partition1, block1, block2),
+			},
+			expectedMarkedDeletion: []ulid.ULID{block1, block2},
+		},
+		"should mark for deletion the unpartitioned blocks with no series of the partitions they have not been compacted into": {
+			partitionCount: 2,
+			blocks: []*metadata.Meta{
+				newMeta(block1, 0, 2*time.Hour, nil),
+				newMeta(block2, 0, 2*time.Hour, nil),
+				newMeta(partition0Block, 0, 2*time.Hour, partition0, block1, block2),
+			},
+			emptyPartitionMarkers:  map[ulid.ULID]PartitionInfo{block1: *partition1, block2: *partition1},
+			expectedMarkedDeletion: []ulid.ULID{block1, block2},
+		},
+		"should compact the blocks of each partition independently": {
+			partitionCount: 2,
+			blocks: []*metadata.Meta{
+				newMeta(partition0Block1h, 0, time.Hour, partition0),
+				newMeta(partition0Block2h, time.Hour, 2*time.Hour, partition0),
+				newMeta(partition1Block1h, 0, time.Hour, partition1),
+			},
+			expected: []expectedGroup{
+				{ids: []ulid.ULID{partition0Block1h, partition0Block2h}, partitionInfo: partition0},
+			},
+		},
+		"should compact the blocks of another partition count into each partition": {
+			partitionCount: 3,
+			blocks: []*metadata.Meta{
+				newMeta(partition0Block, 0, 2*time.Hour, partition0, block1, block2),
+				newMeta(partition1Block, 0, 2*time.Hour, partition1, block1, block2),
+			},
+			expected: []expectedGroup{
+				{ids: []ulid.ULID{partition0Block, partition1Block}, partitionInfo: &PartitionInfo{PartitionCount: 3, PartitionID: 0}},
+				{ids: []ulid.ULID{partition0Block, partition1Block}, partitionInfo: &PartitionInfo{PartitionCount: 3, PartitionID: 1}},
+				{ids: []ulid.ULID{partition0Block, partition1Block}, partitionInfo: &PartitionInfo{PartitionCount: 3, PartitionID: 2}},
+			},
+			expectedNonDeletable: []ulid.ULID{partition0Block, partition1Block},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			limits := validation.Limits{CompactorPartitionCount: testData.partitionCount}
+			overrides, err := validation.NewOverrides(limits, nil)
+			require.NoError(t, err)
+
+			// Setup mocking of the ring so that the grouper will own all the shards.
+			rs := ring.ReplicationSet{
+				Instances: []ring.InstanceDesc{
+					{Addr: "test-addr"},
+				},
+			}
+			subring := &RingMock{}
+			subring.On("GetAllHealthy", mock.Anything).Return(rs, nil)
+			r := &RingMock{}
+			r.On("ShuffleShard", mock.Anything, mock.Anything).Return(subring, nil)
+
+			bkt := objstore.NewInMemBucket()
+			for blockID, partitionInfo := range testData.emptyPartitionMarkers {
+				require.NoError(t, writeEmptyPartitionMarker(ctx, bkt, blockID, partitionInfo))
+			}
+
+			registerer := prometheus.NewPedanticRegistry()
+			metrics := newCompactorMetrics(registerer)
+			blockVisitMarkerReadFailed := promauto.With(registerer).NewCounter(prometheus.CounterOpts{Name: "block_visit_marker_read_failed"})
+			blockVisitMarkerWriteFailed := promauto.With(registerer).NewCounter(prometheus.CounterOpts{Name: "block_visit_marker_write_failed"})
+
+			g := NewPartitionCompactionGrouper(NewShuffleShardingGrouper(
+				ctx,
+				nil,
+				objstore.WithNoopInstr(bkt),
+				false, // Do not accept malformed indexes
+				true,  // Enable vertical compaction
+				nil,
+				metadata.NoneFunc,
+				metrics.getSyncerMetrics("test-user"),
+				metrics,
+				Config{BlockRanges: []time.Duration{2 * time.Hour}},
+				r,
+				"test-addr",
+				"test-compactor",
+				overrides,
+				"test-user",
+				10,
+				3,
+				10,
+				5*time.Minute,
+				blockVisitMarkerReadFailed,
+				blockVisitMarkerWriteFailed,
+				func() map[ulid.ULID]*metadata.NoCompactMark { return nil },
+			))
+
+			blocks := map[ulid.ULID]*metadata.Meta{}
+			for _, b := range testData.blocks {
+				blocks[b.ULID] = b
+			}
+			actual, err := g.Groups(blocks)
+			require.NoError(t, err)
+			require.Len(t, actual, len(testData.expected))
+
+			for idx, expected := range testData.expected {
+				assert.Equal(t, expected.ids, actual[idx].IDs())
+				partitionInfo, err := partitionInfoOfExtensions(actual[idx].Extensions())
+				require.NoError(t, err)
+				assert.Equal(t, expected.partitionInfo, partitionInfo)
+			}
+
+			for _, b := range testData.blocks {
+				assert.Equal(t, !slices.Contains(testData.expectedNonDeletable, b.ULID), g.CanDelete(nil, b.ULID), b.ULID.String())
+
+				markedForDeletion, err := bkt.Exists(ctx, path.Join(b.ULID.String(), metadata.DeletionMarkFilename))
+				require.NoError(t, err)
+				assert.Equal(t, slices.Contains(testData.expectedMarkedDeletion, b.ULID), markedForDeletion, b.ULID.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package compactor
+
+import (
+	"context"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/index"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/compact"
+)
+
+// PartitionCompactionLifecycleCallback compacts the blocks of the groups of the
+// PartitionCompactionGrouper into the partition of the group only.
+type PartitionCompactionLifecycleCallback struct {
+	compact.DefaultCompactionLifecycleCallback
+
+	bkt objstore.Bucket
+}
+
+func NewPartitionCompactionLifecycleCallback(bkt objstore.Bucket) *PartitionCompactionLifecycleCallback {
+	return &PartitionCompactionLifecycleCallback{bkt: bkt}
+}
+
+func (c *PartitionCompactionLifecycleCallback) GetBlockPopulator(ctx context.Context, logger log.Logger, group *compact.Group) (tsdb.BlockPopulator, error) {
+	partitionInfo, err := partitionInfoOfExtensions(group.Extensions())
+	if err != nil {
+		return nil, errors.Wrap(err, "read partition of compaction group")
+	}
+	if partitionInfo == nil || partitionInfo.PartitionCount <= 1 {
+		return tsdb.DefaultBlockPopulator{}, nil
+	}
+	return &partitionBlockPopulator{
+		ctx:           ctx,
+		bkt:           c.bkt,
+		logger:        logger,
+		partitionInfo: *partitionInfo,
+	}, nil
+}
+
+// partitionBlockPopulator populates the block with the series of the partition only.
+type partitionBlockPopulator struct {
+	ctx           context.Context
+	bkt           objstore.Bucket
+	logger        log.Logger
+	partitionInfo PartitionInfo
+}
+
+func (p *partitionBlockPopulator) PopulateBlock(ctx context.Context, metrics *tsdb.CompactorMetrics, logger log.Logger, chunkPool chunkenc.Pool, mergeFunc storage.VerticalChunkSeriesMergeFunc, blocks []tsdb.BlockReader, meta *tsdb.BlockMeta, indexw tsdb.IndexWriter, chunkw tsdb.ChunkWriter, postingsFunc tsdb.IndexReaderPostingsFunc) error {
+	partitionPostingsFunc := func(ctx context.Context, reader tsdb.IndexReader) index.Postings {
+		return reader.ShardedPostings(postingsFunc(ctx, reader), uint64(p.partitionInfo.PartitionID), uint64(p.partitionInfo.PartitionCount))
+	}
+	if err := (tsdb.DefaultBlockPopulator{}).PopulateBlock(ctx, metrics, logger, chunkPool, mergeFunc, blocks, meta, indexw, chunkw, partitionPostingsFunc); err != nil {
+		return err
+	}
+	if meta.Stats.NumSamples > 0 {
+		return nil
+	}
+
+	// No block is written for an empty partition, so the source blocks are marked as having no
+	// series of the partition, not to be compacted again into the partition.
+	for _, b := range blocks {
+		blockID := b.Meta().ULID
+		level.Info(p.logger).Log("msg", "marking block as having no series of the partition", "block", blockID.String(), "partition_id", p.partitionInfo.PartitionID, "partition_count", p.partitionInfo.PartitionCount)
+		if err := writeEmptyPartitionMarker(p.ctx, p.bkt, blockID, p.partitionInfo); err != nil {
+			return errors.Wrapf(err, "mark block %s as having no series of the partition", blockID)
+		}
+	}
+	return nil
+}
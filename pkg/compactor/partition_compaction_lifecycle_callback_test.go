@@ -0,0 +1,105 @@
+package compactor
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/index"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestPartitionBlockPopulator(t *testing.T) {
+	const partitionCount = 3
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	dir := t.TempDir()
+
+	var series1, series2 []storage.Series
+	for i := 0; i < 20; i++ {
+		lbls := labels.FromStrings("series_id", strconv.Itoa(i))
+		series1 = append(series1, storage.NewListSeries(lbls, chunks.GenerateSamples(0, 10)))
+		series2 = append(series2, storage.NewListSeries(lbls, chunks.GenerateSamples(10, 10)))
+	}
+	block1, err := tsdb.CreateBlock(series1, dir, 0, logger)
+	require.NoError(t, err)
+	block2, err := tsdb.CreateBlock(series2, dir, 0, logger)
+	require.NoError(t, err)
+
+	compactor, err := tsdb.NewLeveledCompactor(ctx, nil, logger, []int64{1000}, nil, nil)
+	require.NoError(t, err)
+
+	bkt := objstore.NewInMemBucket()
+	compacted := map[string]struct{}{}
+	for id := 0; id < partitionCount; id++ {
+		partitionInfo := PartitionInfo{PartitionCount: partitionCount, PartitionID: id}
+		populator := &partitionBlockPopulator{ctx: ctx, bkt: bkt, logger: logger, partitionInfo: partitionInfo}
+
+		blockIDs, err := compactor.CompactWithBlockPopulator(dir, []string{block1, block2}, nil, populator)
+		require.NoError(t, err)
+		require.Len(t, blockIDs, 1)
+
+		// The block of the partition holds all the samples of the series of the partition only.
+		b, err := tsdb.OpenBlock(nil, filepath.Join(dir, blockIDs[0].String()), nil)
+		require.NoError(t, err)
+		assert.Equal(t, 20*b.Meta().Stats.NumSeries, b.Meta().Stats.NumSamples)
+
+		indexr, err := b.Index()
+		require.NoError(t, err)
+		k, v := index.AllPostingsKey()
+		postings, err := indexr.Postings(ctx, k, v)
+		require.NoError(t, err)
+		var builder labels.ScratchBuilder
+		for postings.Next() {
+			require.NoError(t, indexr.Series(postings.At(), &builder, nil))
+			lbls := builder.Labels()
+			assert.Equal(t, uint64(id), labels.StableHash(lbls)%partitionCount, lbls.String())
+			compacted[lbls.Get("series_id")] = struct{}{}
+		}
+		require.NoError(t, postings.Err())
+		require.NoError(t, indexr.Close())
+		require.NoError(t, b.Close())
+	}
+	assert.Len(t, compacted, 20)
+}
+
+func TestPartitionBlockPopulator_ShouldMarkTheBlocksWithNoSeriesOfThePartition(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	dir := t.TempDir()
+
+	lbls := labels.FromStrings("series_id", "0")
+	blockDir, err := tsdb.CreateBlock([]storage.Series{storage.NewListSeries(lbls, chunks.GenerateSamples(0, 10))}, dir, 0, logger)
+	require.NoError(t, err)
+	b, err := tsdb.OpenBlock(nil, blockDir, nil)
+	require.NoError(t, err)
+	blockID := b.Meta().ULID
+	require.NoError(t, b.Close())
+
+	compactor, err := tsdb.NewLeveledCompactor(ctx, nil, logger, []int64{1000}, nil, nil)
+	require.NoError(t, err)
+
+	bkt := objstore.NewInMemBucket()
+	for id := 0; id < 2; id++ {
+		partitionInfo := PartitionInfo{PartitionCount: 2, PartitionID: id}
+		populator := &partitionBlockPopulator{ctx: ctx, bkt: bkt, logger: logger, partitionInfo: partitionInfo}
+
+		blockIDs, err := compactor.CompactWithBlockPopulator(dir, []string{blockDir}, nil, populator)
+		require.NoError(t, err)
+
+		inPartition := labels.StableHash(lbls)%2 == uint64(id)
+		assert.Equal(t, inPartition, len(blockIDs) == 1)
+		marked, err := hasEmptyPartitionMarker(ctx, objstore.WithNoopInstr(bkt), blockID, partitionInfo)
+		require.NoError(t, err)
+		assert.Equal(t, !inPartition, marked)
+	}
+}
@@ -0,0 +1,117 @@
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+)
+
+// CortexMetaExtensions is the Cortex extension of the Thanos metadata of the blocks.
+type CortexMetaExtensions struct {
+	PartitionInfo *PartitionInfo `json:"partition_info,omitempty"`
+}
+
+// PartitionInfo is the partition of the series of a block compacted with the partitioning
+// compaction strategy: the block holds the series whose labels hash modulo PartitionCount
+// is PartitionID.
+type PartitionInfo struct {
+	PartitionCount int `json:"partition_count"`
+	PartitionID    int `json:"partition_id"`
+}
+
+// isPartitionOf returns whether the block holds the series of the partition only.
+func (p PartitionInfo) isPartitionOf(meta *metadata.Meta) bool {
+	return partitionInfoOf(meta) == p
+}
+
+// partitionInfoOf returns the partition of the block. The blocks which have not been compacted
+// with the partitioning compaction strategy are the single partition of their series.
+func partitionInfoOf(meta *metadata.Meta) PartitionInfo {
+	info, err := partitionInfoOfExtensions(meta.Thanos.Extensions)
+	if err != nil || info == nil {
+		return PartitionInfo{PartitionCount: 1}
+	}
+	return *info
+}
+
+// partitionInfoOfExtensions returns the partition of the Thanos metadata extensions, or nil if
+// the extensions have no partition.
+func partitionInfoOfExtensions(extensions any) (*PartitionInfo, error) {
+	ext, err := metadata.ConvertExtensions(extensions, &CortexMetaExtensions{})
+	if err != nil || ext == nil {
+		return nil, err
+	}
+	info := ext.(*CortexMetaExtensions).PartitionInfo
+	if info != nil && (info.PartitionCount < 1 || info.PartitionID < 0 || info.PartitionID >= info.PartitionCount) {
+		return nil, errors.Errorf("invalid partition %d of %d", info.PartitionID, info.PartitionCount)
+	}
+	return info, nil
+}
+
+// emptyPartitionMarker records that a block has no series in a partition, so that the block
+// is not compacted again for this partition.
+type emptyPartitionMarker struct {
+	// Version of the file.
+	Version int `json:"version"`
+}
+
+const emptyPartitionMarkerVersion1 = 1
+
+func emptyPartitionMarkerPath(blockID ulid.ULID, p PartitionInfo) string {
+	return path.Join(blockID.String(), fmt.Sprintf("partition-%d-of-%d-empty-mark.json", p.PartitionID, p.PartitionCount))
+}
+
+func hasEmptyPartitionMarker(ctx context.Context, bkt objstore.InstrumentedBucketReader, blockID ulid.ULID, p PartitionInfo) (bool, error) {
+	return bkt.ReaderWithExpectedErrs(bkt.IsObjNotFoundErr).Exists(ctx, emptyPartitionMarkerPath(blockID, p))
+}
+
+func writeEmptyPartitionMarker(ctx context.Context, bkt objstore.Bucket, blockID ulid.ULID, p PartitionInfo) error {
+	content, err := json.Marshal(emptyPartitionMarker{Version: emptyPartitionMarkerVersion1})
+	if err != nil {
+		return err
+	}
+	return bkt.Upload(ctx, emptyPartitionMarkerPath(blockID, p), bytes.NewReader(content))
+}
+
+// deduplicateFilter is the filter of the blocks with the same data used by the compactor syncer.
+type deduplicateFilter interface {
+	block.MetadataFilter
+	block.DeduplicateFilter
+}
+
+// partitionedDeduplicateFilter is a deduplicateFilter filtering out the duplicate blocks among
+// the blocks which have not been compacted with the partitioning compaction strategy only. The
+// blocks compacted into each partition have the same source blocks, so they would be filtered
+// out as duplicates of each other.
+type partitionedDeduplicateFilter struct {
+	*block.DefaultDeduplicateFilter
+}
+
+func newPartitionedDeduplicateFilter(concurrency int) *partitionedDeduplicateFilter {
+	return &partitionedDeduplicateFilter{DefaultDeduplicateFilter: block.NewDeduplicateFilter(concurrency)}
+}
+
+func (f *partitionedDeduplicateFilter) Filter(ctx context.Context, metas map[ulid.ULID]*metadata.Meta, synced block.GaugeVec, modified block.GaugeVec) error {
+	partitioned := map[ulid.ULID]*metadata.Meta{}
+	for id, meta := range metas {
+		if partitionInfoOf(meta).PartitionCount > 1 {
+			partitioned[id] = meta
+			delete(metas, id)
+		}
+	}
+
+	err := f.DefaultDeduplicateFilter.Filter(ctx, metas, synced, modified)
+
+	for id, meta := range partitioned {
+		metas[id] = meta
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+package compactor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+)
+
+func TestPartitionedDeduplicateFilter(t *testing.T) {
+	block1 := ulid.MustNew(1, nil)
+	block2 := ulid.MustNew(2, nil)
+	compactedBlock := ulid.MustNew(3, nil)
+	partition0Block := ulid.MustNew(4, nil)
+	partition1Block := ulid.MustNew(5, nil)
+
+	newMeta := func(id ulid.ULID, partitionInfo *PartitionInfo, sources ...ulid.ULID) *metadata.Meta {
+		m := &metadata.Meta{
+			BlockMeta: tsdb.BlockMeta{ULID: id, Compaction: tsdb.BlockMetaCompaction{Sources: sources}},
+			Thanos:    metadata.Thanos{Labels: map[string]string{"external": "1"}},
+		}
+		if partitionInfo != nil {
+			m.Thanos.Extensions = &CortexMetaExtensions{PartitionInfo: partitionInfo}
+		}
+		return m
+	}
+
+	metas := map[ulid.ULID]*metadata.Meta{
+		block1:          newMeta(block1, nil, block1),
+		block2:          newMeta(block2, nil, block2),
+		compactedBlock:  newMeta(compactedBlock, &PartitionInfo{PartitionCount: 1}, block1, block2),
+		partition0Block: newMeta(partition0Block, &PartitionInfo{PartitionCount: 2, PartitionID: 0}, block1, block2),
+		partition1Block: newMeta(partition1Block, &PartitionInfo{PartitionCount: 2, PartitionID: 1}, block1, block2),
+	}
+
+	synced := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "synced"}, []string{"state"})
+	modified := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "modified"}, []string{"modified"})
+
+	// The blocks not partitioned are deduplicated, while the partitions of the
+	// same source blocks are all kept.
+	f := newPartitionedDeduplicateFilter(1)
+	require.NoError(t, f.Filter(context.Background(), metas, synced, modified))
+	assert.ElementsMatch(t, []ulid.ULID{block1, block2}, f.DuplicateIDs())
+	assert.Len(t, metas, 3)
+	assert.Contains(t, metas, compactedBlock)
+	assert.Contains(t, metas, partition0Block)
+	assert.Contains(t, metas, partition1Block)
+}
@@ -0,0 +1,208 @@
+package compactor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/tombstones"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+const reasonValueSeriesDeletion = "series-deletion"
+
+// deleteSeries deletes from the blocks of the tenant the series of the pending deletion requests
+// whose cancel period has elapsed. Each block overlapping a request is rewritten without the
+// deleted series, and the original block is marked for deletion. A request is moved to the
+// processed state once all the blocks overlapping it have been rewritten, and no new block
+// overlapping it is expected to be compacted.
+func (c *BlocksCleaner) deleteSeries(ctx context.Context, idx *bucketindex.Index, userBucket objstore.InstrumentedBucket, userLogger log.Logger, userID string) error {
+	tombstones, err := bucketindex.ReadTombstones(ctx, c.bucketClient, userID, c.cfgProvider)
+	if err != nil {
+		return err
+	}
+
+	var ready bucketindex.Tombstones
+	for _, t := range tombstones {
+		if t.State == bucketindex.StatePending && time.Since(time.Unix(t.RequestCreatedAt, 0)) > c.cfg.DeleteRequestCancelPeriod {
+			ready = append(ready, t)
+		}
+	}
+	if len(ready) == 0 {
+		return nil
+	}
+
+	deletionMarks := idx.BlockDeletionMarks.GetULIDs()
+	markedForDeletion := make(map[ulid.ULID]struct{}, len(deletionMarks))
+	for _, id := range deletionMarks {
+		markedForDeletion[id] = struct{}{}
+	}
+
+	// The requests which have not been applied to all the blocks overlapping them.
+	unprocessed := map[string]struct{}{}
+
+	for _, b := range idx.Blocks {
+		if _, ok := markedForDeletion[b.ID]; ok {
+			continue
+		}
+
+		var overlapping bucketindex.Tombstones
+		for _, t := range ready {
+			// The block max time is exclusive.
+			if t.Overlaps(b.MinTime, b.MaxTime-1) {
+				overlapping = append(overlapping, t)
+			}
+		}
+		if len(overlapping) == 0 {
+			continue
+		}
+
+		meta, err := block.DownloadMeta(ctx, userLogger, userBucket, b.ID)
+		if err != nil {
+			return err
+		}
+
+		applied := appliedDeletionRequests(meta)
+
+		var toApply bucketindex.Tombstones
+		for _, t := range overlapping {
+			if _, ok := applied[t.RequestID]; !ok {
+				toApply = append(toApply, t)
+			}
+		}
+		if len(toApply) == 0 {
+			continue
+		}
+
+		if err := c.rewriteBlock(ctx, meta, toApply, userBucket, userLogger, userID); err != nil {
+			level.Warn(userLogger).Log("msg", "failed to delete series from block", "block", b.ID, "err", err)
+			for _, t := range toApply {
+				unprocessed[t.RequestID] = struct{}{}
+			}
+		}
+	}
+
+	// Blocks overlapping the requests may still be uploaded by the ingesters or compacted
+	// until the largest block range has elapsed since the end of the requests.
+	largestRange := c.cfg.BlockRanges[len(c.cfg.BlockRanges)-1]
+
+	for _, t := range ready {
+		if _, ok := unprocessed[t.RequestID]; ok {
+			continue
+		}
+		if time.Since(util.TimeFromMillis(t.EndTime)) <= largestRange {
+			continue
+		}
+		if _, err := bucketindex.UpdateTombstoneState(ctx, c.bucketClient, userID, c.cfgProvider, t, bucketindex.StateProcessed, time.Now().Unix()); err != nil {
+			return err
+		}
+		level.Info(userLogger).Log("msg", "series deletion request processed", "request_id", t.RequestID)
+	}
+	return nil
+}
+
+// rewriteBlock uploads a copy of the block without the series of the deletion requests, and
+// marks the block for deletion.
+func (c *BlocksCleaner) rewriteBlock(ctx context.Context, meta metadata.Meta, requests bucketindex.Tombstones, userBucket objstore.InstrumentedBucket, userLogger log.Logger, userID string) error {
+	dir := filepath.Join(c.cfg.DataDir, "series-deletion", userID, meta.ULID.String())
+	if err := os.RemoveAll(dir); err != nil {
+		return errors.Wrap(err, "clean up the working directory")
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			level.Warn(userLogger).Log("msg", "failed to remove the working directory", "dir", dir, "err", err)
+		}
+	}()
+
+	blockDir := filepath.Join(dir, meta.ULID.String())
+	if err := block.Download(ctx, userLogger, userBucket, meta.ULID, blockDir); err != nil {
+		return errors.Wrap(err, "download block")
+	}
+
+	b, err := tsdb.OpenBlock(userLogger, blockDir, nil)
+	if err != nil {
+		return errors.Wrap(err, "open block")
+	}
+	defer b.Close()
+
+	rewrite := metadata.Rewrite{Sources: meta.Compaction.Sources}
+	for _, t := range requests {
+		interval := tombstones.Interval{Mint: max(t.StartTime, meta.MinTime), Maxt: min(t.EndTime, meta.MaxTime-1)}
+		for _, matchers := range t.Matchers() {
+			if err := b.Delete(ctx, interval.Mint, interval.Maxt, matchers...); err != nil {
+				return errors.Wrap(err, "delete series")
+			}
+			rewrite.DeletionsApplied = append(rewrite.DeletionsApplied, metadata.DeletionRequest{
+				Matchers:  matchers,
+				Intervals: tombstones.Intervals{interval},
+				RequestID: t.RequestID,
+			})
+		}
+	}
+
+	compactor, err := tsdb.NewLeveledCompactor(ctx, nil, userLogger, c.cfg.BlockRanges.ToMilliseconds(), chunkenc.NewPool(), nil)
+	if err != nil {
+		return errors.Wrap(err, "create compactor")
+	}
+
+	ids, err := compactor.Compact(dir, []string{blockDir}, []*tsdb.Block{b})
+	if err != nil {
+		return errors.Wrap(err, "rewrite block")
+	}
+
+	// The block is left with no sample when all its series have been deleted.
+	if len(ids) > 0 {
+		newBlockDir := filepath.Join(dir, ids[0].String())
+		newMeta, err := metadata.InjectThanos(userLogger, newBlockDir, metadata.Thanos{
+			Labels:       meta.Thanos.Labels,
+			Downsample:   meta.Thanos.Downsample,
+			Source:       metadata.BucketRewriteSource,
+			SegmentFiles: block.GetSegmentFiles(newBlockDir),
+			Rewrites:     append(meta.Thanos.Rewrites, rewrite),
+			Extensions:   meta.Thanos.Extensions,
+		}, nil)
+		if err != nil {
+			return errors.Wrap(err, "write block meta")
+		}
+
+		// The Cortex blocks are not required to have external labels.
+		if err := block.UploadPromBlock(ctx, userLogger, userBucket, newBlockDir, metadata.NoneFunc); err != nil {
+			return errors.Wrap(err, "upload block")
+		}
+		level.Info(userLogger).Log("msg", "uploaded block without deleted series", "block", meta.ULID, "new_block", newMeta.ULID)
+	}
+
+	return block.MarkForDeletion(ctx, userLogger, userBucket, meta.ULID, fmt.Sprintf("series deleted by the requests %v", tombstoneRequestIDs(requests)), c.blocksMarkedForDeletion.WithLabelValues(userID, reasonValueSeriesDeletion))
+}
+
+// appliedDeletionRequests returns the IDs of the series deletion requests applied to the block.
+func appliedDeletionRequests(meta metadata.Meta) map[string]struct{} {
+	applied := map[string]struct{}{}
+	for _, rewrite := range meta.Thanos.Rewrites {
+		for _, deletion := range rewrite.DeletionsApplied {
+			applied[deletion.RequestID] = struct{}{}
+		}
+	}
+	return applied
+}
+
+func tombstoneRequestIDs(tombstones bucketindex.Tombstones) []string {
+	ids := make([]string, 0, len(tombstones))
+	for _, t := range tombstones {
+		ids = append(ids, t.RequestID)
+	}
+	return ids
+}
@@ -0,0 +1,106 @@
+package compactor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/block"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	cortex_testutil "github.com/cortexproject/cortex/pkg/storage/tsdb/testutil"
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+)
+
+func TestBlocksCleaner_ShouldDeleteSeriesOfDeletionRequests(t *testing.T) {
+	const userID = "user-1"
+
+	ctx := context.Background()
+	now := time.Now()
+	bkt, _ := cortex_testutil.PrepareFilesystemBucket(t)
+	bkt = bucketindex.BucketWithGlobalMarkers(bkt)
+
+	// Each block has the series_id="0" and series_id="1" series.
+	block1 := createTSDBBlock(t, bkt, userID, 10, 20, nil)
+	block2 := createTSDBBlock(t, bkt, userID, 20, 30, nil)
+	block3 := createTSDBBlock(t, bkt, userID, 30, 40, nil)
+
+	cfgProvider := newMockConfigProvider()
+	createTombstone := func(requestCreatedAt time.Time, startTime, endTime int64, selectors ...string) *bucketindex.Tombstone {
+		tombstone, err := bucketindex.NewTombstone(userID, requestCreatedAt.Unix(), requestCreatedAt.Unix(), startTime, endTime, selectors, bucketindex.StatePending)
+		require.NoError(t, err)
+		require.NoError(t, bucketindex.WriteTombstone(ctx, bkt, userID, cfgProvider, tombstone))
+		return tombstone
+	}
+
+	// Deletes the series_id="0" series from block1, and all the series from block2.
+	partial := createTombstone(now.Add(-2*time.Hour), 10, 29, `{series_id="0"}`)
+	full := createTombstone(now.Add(-2*time.Hour), 20, 29, `{series_id="1"}`)
+	// The request can still be cancelled.
+	cancellable := createTombstone(now, 30, 39, `{series_id="0"}`)
+
+	cfg := BlocksCleanerConfig{
+		DeletionDelay:             time.Hour,
+		CleanupInterval:           time.Minute,
+		CleanupConcurrency:        1,
+		DeleteRequestCancelPeriod: time.Hour,
+		BlockRanges:               []time.Duration{2 * time.Hour},
+		DataDir:                   t.TempDir(),
+	}
+
+	logger := log.NewNopLogger()
+	scanner := tsdb.NewUsersScanner(bkt, tsdb.AllUsers, logger)
+	blocksMarkedForDeletion := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: blocksMarkedForDeletionName,
+		Help: blocksMarkedForDeletionHelp,
+	}, append(commonLabels, reasonLabelName))
+
+	cleaner := NewBlocksCleaner(cfg, bkt, scanner, cfgProvider, logger, "test-cleaner", nil, time.Minute, 30*time.Second, blocksMarkedForDeletion)
+	userLogger := util_log.WithUserID(userID, cleaner.logger)
+	userBucket := bucket.NewUserBucketClient(userID, bkt, cfgProvider)
+
+	// The first run creates the bucket index, the series are deleted by the second one.
+	require.NoError(t, cleaner.cleanUser(ctx, userLogger, userBucket, userID, true))
+	require.NoError(t, cleaner.cleanUser(ctx, userLogger, userBucket, userID, false))
+
+	idx, err := bucketindex.ReadIndex(ctx, bkt, userID, cfgProvider, logger)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []ulid.ULID{block1, block2}, idx.BlockDeletionMarks.GetULIDs())
+
+	// block1 has been rewritten without the deleted series, while block2 had no series left.
+	var rewritten []ulid.ULID
+	for _, b := range idx.Blocks {
+		if b.ID != block1 && b.ID != block2 && b.ID != block3 {
+			rewritten = append(rewritten, b.ID)
+		}
+	}
+	require.Len(t, rewritten, 1)
+
+	meta, err := block.DownloadMeta(ctx, logger, userBucket, rewritten[0])
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), meta.MinTime)
+	assert.Equal(t, int64(20), meta.MaxTime)
+	assert.Equal(t, uint64(1), meta.Stats.NumSeries)
+	assert.Equal(t, map[string]struct{}{partial.RequestID: {}}, appliedDeletionRequests(meta))
+
+	// The requests whose cancel period has elapsed have been processed.
+	tombstones, err := bucketindex.ReadTombstones(ctx, bkt, userID, cfgProvider)
+	require.NoError(t, err)
+	states := map[string]bucketindex.DeleteRequestState{}
+	for _, tombstone := range tombstones {
+		states[tombstone.RequestID] = tombstone.State
+	}
+	assert.Equal(t, map[string]bucketindex.DeleteRequestState{
+		partial.RequestID:     bucketindex.StateProcessed,
+		full.RequestID:        bucketindex.StateProcessed,
+		cancellable.RequestID: bucketindex.StatePending,
+	}, states)
+	assert.Len(t, idx.Tombstones, 3)
+}
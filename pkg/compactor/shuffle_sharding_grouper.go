@@ -105,6 +105,14 @@ func NewShuffleShardingGrouper(
 
 // Groups function modified from https://github.com/cortexproject/cortex/pull/2616
 func (g *ShuffleShardingGrouper) Groups(blocks map[ulid.ULID]*metadata.Meta) (res []*compact.Group, err error) {
+	return g.groups(blocks, func(mainBlocks []*metadata.Meta) ([]blocksGroup, error) {
+		return groupBlocksByCompactableRanges(mainBlocks, g.compactorCfg.BlockRanges.ToMilliseconds()), nil
+	})
+}
+
+// groups returns the compaction groups of the blocks, split by splitFn into the groups
+// of blocks which can be compacted in parallel.
+func (g *ShuffleShardingGrouper) groups(blocks map[ulid.ULID]*metadata.Meta, splitFn func(mainBlocks []*metadata.Meta) ([]blocksGroup, error)) (res []*compact.Group, err error) {
 	noCompactMarked := g.noCompBlocksFunc()
 	// First of all we have to group blocks using the Thanos default
 	// grouping (based on downsample resolution + external labels).
@@ -140,7 +148,11 @@ func (g *ShuffleShardingGrouper) Groups(blocks map[ulid.ULID]*metadata.Meta) (re
 
 	var groups []blocksGroup
 	for _, mainBlocks := range mainGroups {
-		groups = append(groups, groupBlocksByCompactableRanges(mainBlocks, g.compactorCfg.BlockRanges.ToMilliseconds())...)
+		splitGroups, err := splitFn(mainBlocks)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, splitGroups...)
 	}
 
 	// Ensure groups are sorted by smallest range, oldest min time first. The rationale
@@ -239,6 +251,9 @@ mainLoop:
 		if err != nil {
 			return nil, errors.Wrap(err, "create compaction group")
 		}
+		if group.partitionInfo != nil {
+			thanosGroup.SetExtensions(&CortexMetaExtensions{PartitionInfo: group.partitionInfo})
+		}
 
 		for _, m := range group.blocks {
 			if err := thanosGroup.AppendMeta(m); err != nil {
@@ -301,6 +316,9 @@ func hashGroup(userID string, rangeStart int64, rangeEnd int64) uint32 {
 }
 
 func createGroupKey(groupHash uint32, group blocksGroup) string {
+	if group.partitionInfo != nil {
+		return fmt.Sprintf("%v%s-partition-%d-of-%d", groupHash, group.blocks[0].Thanos.GroupKey(), group.partitionInfo.PartitionID, group.partitionInfo.PartitionCount)
+	}
 	return fmt.Sprintf("%v%s", groupHash, group.blocks[0].Thanos.GroupKey())
 }
 
@@ -310,6 +328,10 @@ type blocksGroup struct {
 	rangeEnd   int64 // Excluded.
 	blocks     []*metadata.Meta
 	key        string
+
+	// partitionInfo is the partition of the series the blocks are compacted into,
+	// nil if the blocks are not partitioned.
+	partitionInfo *PartitionInfo
 }
 
 // overlaps returns whether the group range overlaps with the input group.
@@ -140,7 +140,7 @@ func TestShuffleShardingGrouper_Groups(t *testing.T) {
 				{block1hto2hExt1Ulid, block0hto1hExt1Ulid},
 				{block3hto4hExt1Ulid, block2hto3hExt1Ulid},
 			},
-			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy
+			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
         	          # TYPE cortex_compactor_remaining_planned_compactions gauge
         	          cortex_compactor_remaining_planned_compactions{user="test-user"} 3
 `,
@@ -150,7 +150,7 @@ func TestShuffleShardingGrouper_Groups(t *testing.T) {
 			ranges:      []time.Duration{2 * time.Hour, 4 * time.Hour},
 			blocks:      map[ulid.ULID]*metadata.Meta{block0hto1hExt1Ulid: blocks[block0hto1hExt1Ulid], block0hto1hExt2Ulid: blocks[block0hto1hExt2Ulid], block0to1hExt3Ulid: blocks[block0to1hExt3Ulid]},
 			expected:    [][]ulid.ULID{},
-			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy
+			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
         	          # TYPE cortex_compactor_remaining_planned_compactions gauge
         	          cortex_compactor_remaining_planned_compactions{user="test-user"} 0
 `,
@@ -164,7 +164,7 @@ func TestShuffleShardingGrouper_Groups(t *testing.T) {
 				{block3hto4hExt1Ulid, block2hto3hExt1Ulid},
 				{block4hto6hExt2Ulid, block6hto8hExt2Ulid},
 			},
-			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy
+			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
         	          # TYPE cortex_compactor_remaining_planned_compactions gauge
         	          cortex_compactor_remaining_planned_compactions{user="test-user"} 3
 `,
@@ -177,7 +177,7 @@ func TestShuffleShardingGrouper_Groups(t *testing.T) {
 				{block1hto2hExt1Ulid, block0hto1hExt1Ulid, block1hto2hExt1UlidCopy},
 				{block3hto4hExt1Ulid, block2hto3hExt1Ulid},
 			},
-			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy
+			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
         	          # TYPE cortex_compactor_remaining_planned_compactions gauge
         	          cortex_compactor_remaining_planned_compactions{user="test-user"} 2
 `,
@@ -189,7 +189,7 @@ func TestShuffleShardingGrouper_Groups(t *testing.T) {
 			expected: [][]ulid.ULID{
 				{block21hto40hExt1Ulid, block21hto40hExt1UlidCopy},
 			},
-			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy
+			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
         	          # TYPE cortex_compactor_remaining_planned_compactions gauge
         	          cortex_compactor_remaining_planned_compactions{user="test-user"} 1
 `,
@@ -201,7 +201,7 @@ func TestShuffleShardingGrouper_Groups(t *testing.T) {
 			expected: [][]ulid.ULID{
 				{block0hto45mExt1Ulid, block0hto1h30mExt1Ulid},
 			},
-			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy
+			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
         	          # TYPE cortex_compactor_remaining_planned_compactions gauge
         	          cortex_compactor_remaining_planned_compactions{user="test-user"} 1
 `,
@@ -211,7 +211,7 @@ func TestShuffleShardingGrouper_Groups(t *testing.T) {
 			ranges:      []time.Duration{2 * time.Hour},
 			blocks:      map[ulid.ULID]*metadata.Meta{blocklast1hExt1UlidCopy: blocks[blocklast1hExt1UlidCopy], blocklast1hExt1Ulid: blocks[blocklast1hExt1Ulid]},
 			expected:    [][]ulid.ULID{},
-			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy
+			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
         	          # TYPE cortex_compactor_remaining_planned_compactions gauge
         	          cortex_compactor_remaining_planned_compactions{user="test-user"} 0
 `,
@@ -231,7 +231,7 @@ func TestShuffleShardingGrouper_Groups(t *testing.T) {
 				{id: block1hto2hExt2Ulid, compactorID: otherCompactorID, isExpired: false},
 				{id: block0hto1hExt2Ulid, compactorID: otherCompactorID, isExpired: false},
 			},
-			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy
+			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
         	          # TYPE cortex_compactor_remaining_planned_compactions gauge
         	          cortex_compactor_remaining_planned_compactions{user="test-user"} 1
 `,
@@ -250,7 +250,7 @@ func TestShuffleShardingGrouper_Groups(t *testing.T) {
 			}{
 				{id: block1hto2hExt2Ulid, compactorID: otherCompactorID, isExpired: false},
 			},
-			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy
+			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
         	          # TYPE cortex_compactor_remaining_planned_compactions gauge
         	          cortex_compactor_remaining_planned_compactions{user="test-user"} 1
 `,
@@ -270,7 +270,7 @@ func TestShuffleShardingGrouper_Groups(t *testing.T) {
 				{id: block1hto2hExt2Ulid, compactorID: otherCompactorID, isExpired: true},
 				{id: block0hto1hExt2Ulid, compactorID: otherCompactorID, isExpired: true},
 			},
-			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy
+			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
         	          # TYPE cortex_compactor_remaining_planned_compactions gauge
         	          cortex_compactor_remaining_planned_compactions{user="test-user"} 1
 `,
@@ -289,7 +289,7 @@ func TestShuffleShardingGrouper_Groups(t *testing.T) {
 			}{
 				{id: block1hto2hExt2Ulid, compactorID: testCompactorID, isExpired: false},
 			},
-			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy
+			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
         	          # TYPE cortex_compactor_remaining_planned_compactions gauge
         	          cortex_compactor_remaining_planned_compactions{user="test-user"} 1
 `,
@@ -302,7 +302,7 @@ func TestShuffleShardingGrouper_Groups(t *testing.T) {
 				{block1hto2hExt2Ulid, block0hto1hExt2Ulid},
 				{block1hto2hExt1Ulid, block0hto1hExt1Ulid},
 			},
-			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy
+			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
         	          # TYPE cortex_compactor_remaining_planned_compactions gauge
         	          cortex_compactor_remaining_planned_compactions{user="test-user"} 2
 `,
@@ -315,7 +315,7 @@ func TestShuffleShardingGrouper_Groups(t *testing.T) {
 				{block1hto2hExt2Ulid, block0hto1hExt2Ulid},
 				{block1hto2hExt1Ulid, block0hto1hExt1Ulid},
 			},
-			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. Only available with shuffle-sharding strategy
+			metrics: `# HELP cortex_compactor_remaining_planned_compactions Total number of plans that remain to be compacted. With the default sharding strategy, it's estimated from the groups of blocks which can be compacted right away.
         	          # TYPE cortex_compactor_remaining_planned_compactions gauge
         	          cortex_compactor_remaining_planned_compactions{user="test-user"} 2
 `,
@@ -0,0 +1,110 @@
+package compactor
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/thanos-io/thanos/pkg/compact"
+)
+
+// sizeBoundedPlanner wraps a planner compacting together all the blocks of the group not marked
+// for no compaction, like the ShuffleShardingPlanner, to exclude from the compaction, and mark for
+// no compaction, the blocks which would make the index of the compacted block larger than the max
+// size. It's the equivalent of the large total index size filter of the Thanos planner.
+type sizeBoundedPlanner struct {
+	compact.Planner
+
+	bkt                         objstore.Bucket
+	logger                      log.Logger
+	noCompBlocksFunc            func() map[ulid.ULID]*metadata.NoCompactMark
+	maxIndexSizeBytes           int64
+	blocksMarkedForNoCompaction prometheus.Counter
+}
+
+func newSizeBoundedPlanner(planner compact.Planner, bkt objstore.Bucket, logger log.Logger, noCompBlocksFunc func() map[ulid.ULID]*metadata.NoCompactMark, maxIndexSizeBytes int64, blocksMarkedForNoCompaction prometheus.Counter) *sizeBoundedPlanner {
+	return &sizeBoundedPlanner{
+		Planner:                     planner,
+		bkt:                         bkt,
+		logger:                      logger,
+		noCompBlocksFunc:            noCompBlocksFunc,
+		maxIndexSizeBytes:           maxIndexSizeBytes,
+		blocksMarkedForNoCompaction: blocksMarkedForNoCompaction,
+	}
+}
+
+func (p *sizeBoundedPlanner) Plan(ctx context.Context, metasByMinTime []*metadata.Meta, errChan chan error, extensions any) ([]*metadata.Meta, error) {
+	noCompactMarked := p.noCompBlocksFunc()
+	metas := make([]*metadata.Meta, 0, len(metasByMinTime))
+	for _, m := range metasByMinTime {
+		if _, excluded := noCompactMarked[m.ULID]; !excluded {
+			metas = append(metas, m)
+		}
+	}
+
+PlanLoop:
+	for len(metas) > 1 {
+		var totalIndexBytes, maxIndexBytes int64
+		biggestIndex := 0
+		for i, m := range metas {
+			indexBytes, err := p.indexSize(ctx, m)
+			if err != nil {
+				return nil, err
+			}
+
+			if indexBytes > maxIndexBytes {
+				maxIndexBytes = indexBytes
+				biggestIndex = i
+			}
+			totalIndexBytes += indexBytes
+
+			// Leave 15% headroom for index compaction bloat, like the Thanos planner.
+			if totalIndexBytes >= int64(float64(p.maxIndexSizeBytes)*0.85) {
+				blockID := metas[biggestIndex].ULID
+				if err := block.MarkForNoCompact(
+					ctx,
+					p.logger,
+					p.bkt,
+					blockID,
+					metadata.IndexSizeExceedingNoCompactReason,
+					fmt.Sprintf("sizeBoundedPlanner: Total compacted block's index size could exceed: %v with this block", p.maxIndexSizeBytes),
+					p.blocksMarkedForNoCompaction,
+				); err != nil {
+					return nil, errors.Wrapf(err, "mark %v for no compaction", blockID.String())
+				}
+
+				metas = slices.Delete(metas, biggestIndex, biggestIndex+1)
+				continue PlanLoop
+			}
+		}
+		break
+	}
+
+	if len(metas) < 2 {
+		return nil, nil
+	}
+	return p.Planner.Plan(ctx, metas, errChan, extensions)
+}
+
+// indexSize returns the size of the index of the block, from its meta if available.
+func (p *sizeBoundedPlanner) indexSize(ctx context.Context, m *metadata.Meta) (int64, error) {
+	for _, f := range m.Thanos.Files {
+		if f.RelPath == block.IndexFilename && f.SizeBytes > 0 {
+			return f.SizeBytes, nil
+		}
+	}
+
+	attrs, err := p.bkt.Attributes(ctx, path.Join(m.ULID.String(), block.IndexFilename))
+	if err != nil {
+		return 0, errors.Wrapf(err, "get attributes of %s", path.Join(m.ULID.String(), block.IndexFilename))
+	}
+	return attrs.Size, nil
+}
@@ -0,0 +1,105 @@
+package compactor
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus"
+	prom_testutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+
+	cortex_storage_testutil "github.com/cortexproject/cortex/pkg/storage/tsdb/testutil"
+)
+
+type plannerMock struct {
+	planned []*metadata.Meta
+}
+
+func (p *plannerMock) Plan(_ context.Context, metasByMinTime []*metadata.Meta, _ chan error, _ any) ([]*metadata.Meta, error) {
+	p.planned = metasByMinTime
+	return metasByMinTime, nil
+}
+
+func TestSizeBoundedPlanner_Plan(t *testing.T) {
+	block1 := ulid.MustNew(1, nil)
+	block2 := ulid.MustNew(2, nil)
+	block3 := ulid.MustNew(3, nil)
+
+	tests := map[string]struct {
+		indexSizes          map[ulid.ULID]int64
+		noCompactMarked     []ulid.ULID
+		expectedPlanned     []ulid.ULID
+		expectedNoCompacted []ulid.ULID
+	}{
+		"should plan all the blocks when the index is smaller than the max size": {
+			indexSizes:      map[ulid.ULID]int64{block1: 30, block2: 20, block3: 10},
+			expectedPlanned: []ulid.ULID{block1, block2, block3},
+		},
+		"should mark for no compaction the block with the biggest index when exceeding the max size": {
+			indexSizes:          map[ulid.ULID]int64{block1: 30, block2: 60, block3: 20},
+			expectedPlanned:     []ulid.ULID{block1, block3},
+			expectedNoCompacted: []ulid.ULID{block2},
+		},
+		"should exclude the blocks already marked for no compaction": {
+			indexSizes:      map[ulid.ULID]int64{block1: 30, block2: 60, block3: 20},
+			noCompactMarked: []ulid.ULID{block2},
+			expectedPlanned: []ulid.ULID{block1, block3},
+		},
+		"should plan nothing when less than 2 blocks are left": {
+			indexSizes:          map[ulid.ULID]int64{block1: 90, block2: 10},
+			expectedNoCompacted: []ulid.ULID{block1},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ctx := context.Background()
+			bkt, _ := cortex_storage_testutil.PrepareFilesystemBucket(t)
+
+			var metas []*metadata.Meta
+			for _, id := range []ulid.ULID{block1, block2, block3} {
+				indexSize, ok := testData.indexSizes[id]
+				if !ok {
+					continue
+				}
+				meta := &metadata.Meta{BlockMeta: tsdb.BlockMeta{ULID: id}}
+				meta.Thanos.Files = []metadata.File{{RelPath: block.IndexFilename, SizeBytes: indexSize}}
+				metas = append(metas, meta)
+			}
+
+			noCompactMarked := map[ulid.ULID]*metadata.NoCompactMark{}
+			for _, id := range testData.noCompactMarked {
+				noCompactMarked[id] = &metadata.NoCompactMark{ID: id}
+			}
+
+			markedForNoCompaction := prometheus.NewCounter(prometheus.CounterOpts{})
+			inner := &plannerMock{}
+			planner := newSizeBoundedPlanner(inner, bkt, log.NewNopLogger(), func() map[ulid.ULID]*metadata.NoCompactMark {
+				return noCompactMarked
+			}, 100, markedForNoCompaction)
+
+			planned, err := planner.Plan(ctx, metas, nil, nil)
+			require.NoError(t, err)
+
+			var plannedIDs []ulid.ULID
+			for _, meta := range planned {
+				plannedIDs = append(plannedIDs, meta.ULID)
+			}
+			assert.Equal(t, testData.expectedPlanned, plannedIDs)
+			assert.Equal(t, float64(len(testData.expectedNoCompacted)), prom_testutil.ToFloat64(markedForNoCompaction))
+
+			for _, id := range testData.expectedNoCompacted {
+				exists, err := bkt.Exists(ctx, path.Join(id.String(), metadata.NoCompactMarkFilename))
+				require.NoError(t, err)
+				assert.True(t, exists)
+			}
+		})
+	}
+}
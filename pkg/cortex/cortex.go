@@ -38,6 +38,7 @@ import (
 	frontendv1 "github.com/cortexproject/cortex/pkg/frontend/v1"
 	"github.com/cortexproject/cortex/pkg/ingester"
 	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/purger"
 	"github.com/cortexproject/cortex/pkg/querier"
 	"github.com/cortexproject/cortex/pkg/querier/tenantfederation"
 	"github.com/cortexproject/cortex/pkg/querier/tripperware"
@@ -66,6 +67,8 @@ import (
 
 var (
 	errInvalidHTTPPrefix = errors.New("HTTP prefix should be empty or start with /")
+
+	errZoneAwarenessMinSuccessZonesWithoutWriteQuorum = errors.New("the ingesters zone-awareness min success zones requires the distributor zone-aware write quorum to be enabled")
 )
 
 // The design pattern for Cortex is a series of config objects, which are
@@ -112,6 +115,7 @@ type Config struct {
 	Compactor        compactor.Config                `yaml:"compactor"`
 	StoreGateway     storegateway.Config             `yaml:"store_gateway"`
 	TenantFederation tenantfederation.Config         `yaml:"tenant_federation"`
+	Purger           purger.Config                   `yaml:"purger"`
 
 	Ruler               ruler.Config                               `yaml:"ruler"`
 	RulerStorage        rulestore.Config                           `yaml:"ruler_storage"`
@@ -159,6 +163,7 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	c.Compactor.RegisterFlags(f)
 	c.StoreGateway.RegisterFlags(f)
 	c.TenantFederation.RegisterFlags(f)
+	c.Purger.RegisterFlags(f)
 
 	c.Ruler.RegisterFlags(f)
 	c.RulerStorage.RegisterFlags(f)
@@ -212,6 +217,9 @@ func (c *Config) Validate(log log.Logger) error {
 	if err := c.Worker.Validate(log); err != nil {
 		return errors.Wrap(err, "invalid frontend_worker config")
 	}
+	if err := c.Frontend.Validate(); err != nil {
+		return errors.Wrap(err, "invalid frontend config")
+	}
 	if err := c.QueryRange.Validate(c.Querier); err != nil {
 		return errors.Wrap(err, "invalid query_range config")
 	}
@@ -232,10 +240,20 @@ func (c *Config) Validate(log log.Logger) error {
 		return errors.Wrap(err, "invalid ingester config")
 	}
 
+	// The reads tolerate the failure of min success zones - 1 zones, which is safe only if the
+	// writes are guaranteed to succeed in the min success zones.
+	if c.Ingester.LifecyclerConfig.RingConfig.ZoneAwarenessMinSuccessZones > 0 && !c.Distributor.ZoneAwareWriteQuorum {
+		return errZoneAwarenessMinSuccessZonesWithoutWriteQuorum
+	}
+
 	if err := c.Tracing.Validate(); err != nil {
 		return errors.Wrap(err, "invalid tracing config")
 	}
 
+	if err := c.MemberlistKV.Validate(); err != nil {
+		return errors.Wrap(err, "invalid memberlist config")
+	}
+
 	return nil
 }
 
@@ -310,8 +328,10 @@ type Cortex struct {
 	Frontend                 *frontendv1.Frontend
 	RuntimeConfig            *runtimeconfig.Manager
 	QuerierQueryable         prom_storage.SampleAndChunkQueryable
+	MetadataQuerier          querier.MetadataQuerier
 	ExemplarQueryable        prom_storage.ExemplarQueryable
 	QuerierEngine            promql.QueryEngine
+	ActiveQueries            *querier.ActiveQueries
 	QueryFrontendTripperware tripperware.Tripperware
 
 	Ruler        *ruler.Ruler
@@ -326,6 +346,14 @@ type Cortex struct {
 	// Queryables that the querier should use to query the long
 	// term storage. It depends on the storage engine used.
 	StoreQueryables []querier.QueryableWithFilter
+
+	// Loader of the tombstones of the series deletion requests, used
+	// to filter out the deleted series from the queries.
+	TombstonesLoader querier.TombstonesLoader
+
+	// Syncer of the blocks known by the querier, used to immediately
+	// refresh the blocks of a tenant on request.
+	BlocksSyncer querier.BlocksSyncer
 }
 
 // New makes a new Cortex.
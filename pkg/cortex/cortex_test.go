@@ -165,6 +165,25 @@ func TestConfigValidation(t *testing.T) {
 			},
 			expectedError: errInvalidHTTPPrefix,
 		},
+		{
+			name: "should fail validation if the ingesters min success zones is set without the zone-aware write quorum",
+			getTestConfig: func() *Config {
+				configuration := newDefaultConfig()
+				configuration.Ingester.LifecyclerConfig.RingConfig.ZoneAwarenessMinSuccessZones = 2
+				return configuration
+			},
+			expectedError: errZoneAwarenessMinSuccessZonesWithoutWriteQuorum,
+		},
+		{
+			name: "should pass validation if the ingesters min success zones is set with the zone-aware write quorum",
+			getTestConfig: func() *Config {
+				configuration := newDefaultConfig()
+				configuration.Ingester.LifecyclerConfig.RingConfig.ZoneAwarenessMinSuccessZones = 2
+				configuration.Distributor.ZoneAwareWriteQuorum = true
+				return configuration
+			},
+			expectedError: nil,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			err := tc.getTestConfig().Validate(nil)
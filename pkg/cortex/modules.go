@@ -26,6 +26,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/alertmanager"
 	"github.com/cortexproject/cortex/pkg/alertmanager/alertstore"
 	"github.com/cortexproject/cortex/pkg/api"
+	"github.com/cortexproject/cortex/pkg/chunk/cache"
 	"github.com/cortexproject/cortex/pkg/compactor"
 	configAPI "github.com/cortexproject/cortex/pkg/configs/api"
 	"github.com/cortexproject/cortex/pkg/configs/db"
@@ -33,6 +34,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/flusher"
 	"github.com/cortexproject/cortex/pkg/frontend"
 	"github.com/cortexproject/cortex/pkg/frontend/transport"
+	"github.com/cortexproject/cortex/pkg/ha"
 	"github.com/cortexproject/cortex/pkg/ingester"
 	"github.com/cortexproject/cortex/pkg/purger"
 	"github.com/cortexproject/cortex/pkg/querier"
@@ -42,14 +44,17 @@ import (
 	"github.com/cortexproject/cortex/pkg/querier/tripperware/queryrange"
 	querier_worker "github.com/cortexproject/cortex/pkg/querier/worker"
 	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/cortexproject/cortex/pkg/ring/kv"
 	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
 	"github.com/cortexproject/cortex/pkg/ring/kv/memberlist"
 	"github.com/cortexproject/cortex/pkg/ruler"
+	"github.com/cortexproject/cortex/pkg/ruler/rulestore"
 	"github.com/cortexproject/cortex/pkg/scheduler"
 	"github.com/cortexproject/cortex/pkg/storage/bucket"
 	"github.com/cortexproject/cortex/pkg/storegateway"
 	"github.com/cortexproject/cortex/pkg/util/grpcclient"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
+	cortexmiddleware "github.com/cortexproject/cortex/pkg/util/middleware"
 	"github.com/cortexproject/cortex/pkg/util/modules"
 	"github.com/cortexproject/cortex/pkg/util/runtimeconfig"
 	"github.com/cortexproject/cortex/pkg/util/services"
@@ -138,7 +143,6 @@ func (t *Cortex) initServer() (services.Service, error) {
 }
 
 func (t *Cortex) initRing() (serv services.Service, err error) {
-	t.Cfg.Ingester.LifecyclerConfig.RingConfig.KVStore.Multi.ConfigProvider = multiClientRuntimeConfigChannel(t.RuntimeConfig)
 	t.Ring, err = ring.New(t.Cfg.Ingester.LifecyclerConfig.RingConfig, "ingester", ingester.RingKey, util_log.Logger, prometheus.WrapRegistererWithPrefix("cortex_", prometheus.DefaultRegisterer))
 	if err != nil {
 		return nil, err
@@ -150,12 +154,22 @@ func (t *Cortex) initRing() (serv services.Service, err error) {
 }
 
 func (t *Cortex) initRuntimeConfig() (services.Service, error) {
+	// The global step alignment is the default of the per-tenant one, so that the tenants can opt out.
+	if t.Cfg.QueryRange.AlignQueriesWithStep {
+		t.Cfg.LimitsConfig.AlignQueriesWithStep = true
+	}
+
 	if t.Cfg.RuntimeConfig.LoadPath == "" {
 		// no need to initialize module if load path is empty
 		return nil, nil
 	}
 	t.Cfg.RuntimeConfig.Loader = loadRuntimeConfig
 
+	// The runtime config file can be an object storage URL, configuring the storage backend.
+	if err := t.Cfg.RuntimeConfig.ResolveLoadPath(); err != nil {
+		return nil, err
+	}
+
 	// make sure to set default limits before we start loading configuration into memory
 	validation.SetDefaultLimitsForYAMLUnmarshalling(t.Cfg.LimitsConfig)
 
@@ -187,10 +201,29 @@ func (t *Cortex) initRuntimeConfig() (services.Service, error) {
 	}
 
 	t.RuntimeConfig = serv
+	t.setMultiKVConfigProviders()
 	t.API.RegisterRuntimeConfig(runtimeConfigHandler(t.RuntimeConfig, t.Cfg.LimitsConfig))
 	return serv, err
 }
 
+// setMultiKVConfigProviders allows to switch at runtime the primary store, and the mirroring, of the
+// multi KV of all the rings and of the HA tracker, to migrate them to another KV store without downtime.
+func (t *Cortex) setMultiKVConfigProviders() {
+	provider := multiClientRuntimeConfigChannel(t.RuntimeConfig)
+
+	for _, cfg := range []*kv.MultiConfig{
+		&t.Cfg.Ingester.LifecyclerConfig.RingConfig.KVStore.Multi,
+		&t.Cfg.Distributor.DistributorRing.KVStore.Multi,
+		&t.Cfg.Distributor.HATrackerConfig.KVStore.Multi,
+		&t.Cfg.StoreGateway.ShardingRing.KVStore.Multi,
+		&t.Cfg.Compactor.ShardingRing.KVStore.Multi,
+		&t.Cfg.Ruler.Ring.KVStore.Multi,
+		&t.Cfg.Alertmanager.ShardingRing.KVStore.Multi,
+	} {
+		cfg.ConfigProvider = provider
+	}
+}
+
 func (t *Cortex) initOverrides() (serv services.Service, err error) {
 	t.Overrides, err = validation.NewOverrides(t.Cfg.LimitsConfig, t.TenantLimits)
 	// overrides don't have operational state, nor do they need to do anything more in starting/stopping phase,
@@ -206,7 +239,7 @@ func (t *Cortex) initOverridesExporter() (services.Service, error) {
 		return nil, errors.New("overrides-exporter has been enabled, but no runtime configuration file was configured")
 	}
 
-	exporter := validation.NewOverridesExporter(t.TenantLimits)
+	exporter := validation.NewOverridesExporter(&t.Cfg.LimitsConfig, t.TenantLimits)
 	prometheus.MustRegister(exporter)
 
 	// the overrides exporter has no state and reads overrides for runtime configuration each time it
@@ -217,6 +250,8 @@ func (t *Cortex) initOverridesExporter() (services.Service, error) {
 func (t *Cortex) initDistributorService() (serv services.Service, err error) {
 	t.Cfg.Distributor.DistributorRing.ListenPort = t.Cfg.Server.GRPCListenPort
 	t.Cfg.Distributor.ShuffleShardingLookbackPeriod = t.Cfg.Querier.ShuffleShardingIngestersLookbackPeriod
+	t.Cfg.Distributor.IngestersZoneAwarenessEnabled = t.Cfg.Ingester.LifecyclerConfig.RingConfig.ZoneAwarenessEnabled
+	t.Cfg.Distributor.IngestersZoneAwarenessMinSuccessZones = t.Cfg.Ingester.LifecyclerConfig.RingConfig.ZoneAwarenessMinSuccessZones
 	t.Cfg.IngesterClient.GRPCClientConfig.SignWriteRequestsEnabled = t.Cfg.Distributor.SignWriteRequestsEnabled
 
 	// Check whether the distributor can join the distributors ring, which is
@@ -257,10 +292,18 @@ func (t *Cortex) initQueryable() (serv services.Service, err error) {
 	querierRegisterer := prometheus.WrapRegistererWith(prometheus.Labels{"engine": "querier"}, prometheus.DefaultRegisterer)
 
 	// Create a querier queryable and PromQL engine
-	t.QuerierQueryable, t.ExemplarQueryable, t.QuerierEngine = querier.New(t.Cfg.Querier, t.Overrides, t.Distributor, t.StoreQueryables, querierRegisterer, util_log.Logger)
+	t.ActiveQueries = querier.NewActiveQueries(createActiveQueryTracker(t.Cfg.Querier, util_log.Logger))
+	t.QuerierQueryable, t.ExemplarQueryable, t.QuerierEngine = querier.New(t.Cfg.Querier, t.Overrides, t.Distributor, t.StoreQueryables, t.ActiveQueries, querierRegisterer, util_log.Logger)
+	if t.TombstonesLoader != nil {
+		t.QuerierQueryable = querier.NewSampleAndChunkQueryable(querier.NewTombstonesQueryable(t.QuerierQueryable, t.TombstonesLoader))
+	}
+	t.MetadataQuerier = t.Distributor
 
 	// Register the default endpoints that are always enabled for the querier module
-	t.API.RegisterQueryable(t.QuerierQueryable, t.Distributor)
+	t.API.RegisterQueryable(t.QuerierQueryable, t.Distributor, t.ActiveQueries)
+	if t.BlocksSyncer != nil {
+		t.API.RegisterQuerierBlocksSync(t.BlocksSyncer)
+	}
 
 	return nil, nil
 }
@@ -272,7 +315,8 @@ func (t *Cortex) initTenantFederation() (serv services.Service, err error) {
 		// single tenant. This allows for a less impactful enabling of tenant
 		// federation.
 		byPassForSingleQuerier := true
-		t.QuerierQueryable = querier.NewSampleAndChunkQueryable(tenantfederation.NewQueryable(t.QuerierQueryable, byPassForSingleQuerier))
+		t.QuerierQueryable = querier.NewSampleAndChunkQueryable(tenantfederation.NewQueryable(t.QuerierQueryable, byPassForSingleQuerier, t.Overrides))
+		t.MetadataQuerier = tenantfederation.NewMetadataQuerier(t.MetadataQuerier, t.Overrides)
 	}
 	return nil, nil
 }
@@ -335,6 +379,7 @@ func (t *Cortex) initQuerier() (serv services.Service, err error) {
 		t.ExemplarQueryable,
 		t.QuerierEngine,
 		t.Distributor,
+		t.MetadataQuerier,
 		prometheus.DefaultRegisterer,
 		util_log.Logger,
 	)
@@ -392,6 +437,12 @@ func (t *Cortex) initStoreQueryables() (services.Service, error) {
 		return nil, fmt.Errorf("failed to initialize querier: %v", err)
 	} else {
 		t.StoreQueryables = append(t.StoreQueryables, querier.UseAlwaysQueryable(q))
+		if l, ok := q.(querier.TombstonesLoader); ok {
+			t.TombstonesLoader = l
+		}
+		if s, ok := q.(querier.BlocksSyncer); ok {
+			t.BlocksSyncer = s
+		}
 		if s, ok := q.(services.Service); ok {
 			servs = append(servs, s)
 		}
@@ -427,7 +478,6 @@ func (t *Cortex) tsdbIngesterConfig() {
 }
 
 func (t *Cortex) initIngesterService() (serv services.Service, err error) {
-	t.Cfg.Ingester.LifecyclerConfig.RingConfig.KVStore.Multi.ConfigProvider = multiClientRuntimeConfigChannel(t.RuntimeConfig)
 	t.Cfg.Ingester.LifecyclerConfig.ListenPort = t.Cfg.Server.GRPCListenPort
 	t.Cfg.Ingester.DistributorShardingStrategy = t.Cfg.Distributor.ShardingStrategy
 	t.Cfg.Ingester.DistributorShardByAllLabels = t.Cfg.Distributor.ShardByAllLabels
@@ -476,7 +526,7 @@ func (t *Cortex) initQueryFrontendTripperware() (serv services.Service, err erro
 	shardedPrometheusCodec := queryrange.NewPrometheusCodec(true, t.Cfg.Querier.ResponseCompression, t.Cfg.API.QuerierDefaultCodec)
 	instantQueryCodec := instantquery.NewInstantQueryCodec(t.Cfg.Querier.ResponseCompression, t.Cfg.API.QuerierDefaultCodec)
 
-	queryRangeMiddlewares, cache, err := queryrange.Middlewares(
+	queryRangeMiddlewares, resultsCache, err := queryrange.Middlewares(
 		t.Cfg.QueryRange,
 		util_log.Logger,
 		t.Overrides,
@@ -491,12 +541,12 @@ func (t *Cortex) initQueryFrontendTripperware() (serv services.Service, err erro
 		return nil, err
 	}
 
-	instantQueryMiddlewares, err := instantquery.Middlewares(util_log.Logger, t.Overrides, instantQueryCodec, queryAnalyzer, t.Cfg.Querier.LookbackDelta)
+	instantQueryMiddlewares, err := instantquery.Middlewares(util_log.Logger, t.Overrides, instantQueryCodec, queryAnalyzer, t.Cfg.Querier.LookbackDelta, resultsCache)
 	if err != nil {
 		return nil, err
 	}
 
-	t.QueryFrontendTripperware = tripperware.NewQueryTripperware(util_log.Logger,
+	queryTripperware := tripperware.NewQueryTripperware(util_log.Logger,
 		prometheus.DefaultRegisterer,
 		t.Cfg.QueryRange.ForwardHeaders,
 		queryRangeMiddlewares,
@@ -509,18 +559,36 @@ func (t *Cortex) initQueryFrontendTripperware() (serv services.Service, err erro
 		t.Cfg.Querier.MaxSubQuerySteps,
 		t.Cfg.Querier.LookbackDelta,
 	)
+	t.QueryFrontendTripperware = queryTripperware
+
+	var metadataCache cache.Cache
+	if t.Cfg.QueryRange.CacheMetadata {
+		var metadataCacheTripperware tripperware.Tripperware
+		metadataCacheTripperware, metadataCache, err = tripperware.NewMetadataCacheTripperware(t.Cfg.QueryRange.MetadataCacheConfig, t.Overrides, util_log.Logger, prometheus.DefaultRegisterer)
+		if err != nil {
+			return nil, err
+		}
+		// The labels and series requests are cached after they have been checked by the query tripperware.
+		t.QueryFrontendTripperware = func(next http.RoundTripper) http.RoundTripper {
+			return queryTripperware(metadataCacheTripperware(next))
+		}
+	}
 
 	return services.NewIdleService(nil, func(_ error) error {
-		if cache != nil {
-			cache.Stop()
-			cache = nil
+		if resultsCache != nil {
+			resultsCache.Stop()
+			resultsCache = nil
+		}
+		if metadataCache != nil {
+			metadataCache.Stop()
+			metadataCache = nil
 		}
 		return nil
 	}), nil
 }
 
 func (t *Cortex) initQueryFrontend() (serv services.Service, err error) {
-	retry := transport.NewRetry(t.Cfg.QueryRange.MaxRetries, prometheus.DefaultRegisterer)
+	retry := transport.NewRetry(t.Cfg.QueryRange.MaxRetries, t.Cfg.Frontend.Hedging, t.Overrides, prometheus.DefaultRegisterer)
 	roundTripper, frontendV1, frontendV2, err := frontend.InitFrontend(t.Cfg.Frontend, t.Overrides, t.Cfg.Server.GRPCListenPort, util_log.Logger, prometheus.DefaultRegisterer, retry)
 	if err != nil {
 		return nil, err
@@ -529,7 +597,11 @@ func (t *Cortex) initQueryFrontend() (serv services.Service, err error) {
 	// Wrap roundtripper into Tripperware.
 	roundTripper = t.QueryFrontendTripperware(roundTripper)
 
-	handler := transport.NewHandler(t.Cfg.Frontend.Handler, roundTripper, util_log.Logger, prometheus.DefaultRegisterer)
+	var handler http.Handler = transport.NewHandler(t.Cfg.Frontend.Handler, roundTripper, util_log.Logger, prometheus.DefaultRegisterer)
+	if t.Cfg.API.ResponseCompression {
+		// Compress the query API responses with the encoding negotiated by the client, gzip or snappy.
+		handler = cortexmiddleware.NewResponseCompression("cortex_query_frontend", prometheus.DefaultRegisterer).Wrap(handler)
+	}
 	t.API.RegisterQueryFrontendHandler(handler)
 
 	if frontendV1 != nil {
@@ -615,7 +687,10 @@ func (t *Cortex) initRuler() (serv services.Service, err error) {
 	} else {
 		rulerRegisterer := prometheus.WrapRegistererWith(prometheus.Labels{"engine": "ruler"}, prometheus.DefaultRegisterer)
 		// TODO: Consider wrapping logger to differentiate from querier module logger
-		queryable, _, engine := querier.New(t.Cfg.Querier, t.Overrides, t.Distributor, t.StoreQueryables, rulerRegisterer, util_log.Logger)
+		queryable, _, engine := querier.New(t.Cfg.Querier, t.Overrides, t.Distributor, t.StoreQueryables, createActiveQueryTracker(t.Cfg.Querier, util_log.Logger), rulerRegisterer, util_log.Logger)
+		if t.TombstonesLoader != nil {
+			queryable = querier.NewSampleAndChunkQueryable(querier.NewTombstonesQueryable(queryable, t.TombstonesLoader))
+		}
 
 		managerFactory := ruler.DefaultTenantManagerFactory(t.Cfg.Ruler, t.Distributor, queryable, engine, t.Overrides, metrics, prometheus.DefaultRegisterer)
 		manager, err = ruler.NewDefaultMultiTenantManager(t.Cfg.Ruler, managerFactory, metrics, prometheus.DefaultRegisterer, util_log.Logger)
@@ -625,12 +700,21 @@ func (t *Cortex) initRuler() (serv services.Service, err error) {
 		return nil, err
 	}
 
+	ruleStore := t.RulerStorage
+	if t.Cfg.Ruler.TenantDeletionGracePeriod > 0 {
+		bucketClient, err := bucket.NewClient(context.Background(), t.Cfg.BlocksStorage.Bucket, "ruler-tenant-deletion", util_log.Logger, prometheus.DefaultRegisterer)
+		if err != nil {
+			return nil, err
+		}
+		ruleStore = rulestore.NewTenantDeletionRuleStore(ruleStore, bucketClient, t.Cfg.Ruler.TenantDeletionGracePeriod, util_log.Logger)
+	}
+
 	t.Ruler, err = ruler.NewRuler(
 		t.Cfg.Ruler,
 		manager,
 		prometheus.DefaultRegisterer,
 		util_log.Logger,
-		t.RulerStorage,
+		ruleStore,
 		t.Overrides,
 	)
 	if err != nil {
@@ -671,6 +755,14 @@ func (t *Cortex) initAlertManager() (serv services.Service, err error) {
 		return
 	}
 
+	if t.Cfg.Alertmanager.TenantDeletionGracePeriod > 0 {
+		bucketClient, err := bucket.NewClient(context.Background(), t.Cfg.BlocksStorage.Bucket, "alertmanager-tenant-deletion", util_log.Logger, prometheus.DefaultRegisterer)
+		if err != nil {
+			return nil, err
+		}
+		store = alertstore.NewTenantDeletionAlertStore(store, bucketClient, t.Cfg.Alertmanager.TenantDeletionGracePeriod, util_log.Logger)
+	}
+
 	t.Alertmanager, err = alertmanager.NewMultitenantAlertmanager(&t.Cfg.Alertmanager, store, t.Overrides, util_log.Logger, prometheus.DefaultRegisterer)
 	if err != nil {
 		return
@@ -682,6 +774,7 @@ func (t *Cortex) initAlertManager() (serv services.Service, err error) {
 
 func (t *Cortex) initCompactor() (serv services.Service, err error) {
 	t.Cfg.Compactor.ShardingRing.ListenPort = t.Cfg.Server.GRPCListenPort
+	t.Cfg.Compactor.DeleteRequestCancelPeriod = t.Cfg.Purger.DeleteRequestCancelPeriod
 
 	t.Compactor, err = compactor.NewCompactor(t.Cfg.Compactor, t.Cfg.BlocksStorage, util_log.Logger, prometheus.DefaultRegisterer, t.Overrides)
 	if err != nil {
@@ -736,8 +829,15 @@ func (t *Cortex) initMemberlistKV() (services.Service, error) {
 }
 
 func (t *Cortex) initTenantDeletionAPI() (services.Service, error) {
-	// t.RulerStorage can be nil when running in single-binary mode, and rule storage is not configured.
-	tenantDeletionAPI, err := purger.NewTenantDeletionAPI(t.Cfg.BlocksStorage, t.Overrides, util_log.Logger, prometheus.DefaultRegisterer)
+	var deleters []purger.TenantDataDeleter
+	if t.Cfg.Purger.TenantDeletionCascadeEnabled {
+		var err error
+		if deleters, err = t.createTenantDataDeleters(); err != nil {
+			return nil, err
+		}
+	}
+
+	tenantDeletionAPI, err := purger.NewTenantDeletionAPI(t.Cfg.BlocksStorage, t.Overrides, util_log.Logger, prometheus.DefaultRegisterer, deleters...)
 	if err != nil {
 		return nil, err
 	}
@@ -746,6 +846,65 @@ func (t *Cortex) initTenantDeletionAPI() (services.Service, error) {
 	return nil, nil
 }
 
+// createTenantDataDeleters creates the deleters of the tenant data stored in the ruler and
+// Alertmanager storages, and in the HA tracker KV store. The storages which aren't configured,
+// or whose backend is read-only, are skipped.
+func (t *Cortex) createTenantDataDeleters() ([]purger.TenantDataDeleter, error) {
+	var deleters []purger.TenantDataDeleter
+
+	// The metrics of the storage clients are not registered, since they would clash with the ones
+	// of the ruler and alertmanager running in the same process.
+	if !t.Cfg.RulerStorage.IsDefaults() && isBucketBackend(t.Cfg.RulerStorage.Backend) {
+		ruleStore, err := ruler.NewRuleStore(context.Background(), t.Cfg.RulerStorage, t.Overrides, rules.FileLoader{}, util_log.Logger, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "create ruler storage")
+		}
+		deleters = append(deleters, purger.NewRuleGroupsDeleter(ruleStore))
+	}
+
+	if t.Cfg.AlertmanagerStorage.IsFullStateSupported() {
+		alertStore, err := alertstore.NewAlertStore(context.Background(), t.Cfg.AlertmanagerStorage, t.Overrides, util_log.Logger, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "create alertmanager storage")
+		}
+		deleters = append(deleters, purger.NewAlertmanagerDeleter(alertStore))
+	}
+
+	if t.Cfg.Distributor.HATrackerConfig.EnableHATracker {
+		client, err := kv.NewClient(
+			t.Cfg.Distributor.HATrackerConfig.KVStore,
+			ha.GetReplicaDescCodec(),
+			kv.RegistererWithKVName(prometheus.WrapRegistererWithPrefix("cortex_", prometheus.DefaultRegisterer), "purger-hatracker"),
+			util_log.Logger,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "create HA tracker KV client")
+		}
+		deleters = append(deleters, purger.NewHATrackerDeleter(client))
+	}
+
+	return deleters, nil
+}
+
+func isBucketBackend(backend string) bool {
+	for _, b := range bucket.SupportedBackends {
+		if backend == b {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Cortex) initBlocksPurger() (services.Service, error) {
+	blocksPurgerAPI, err := purger.NewBlocksPurgerAPI(t.Cfg.BlocksStorage, t.Overrides, t.Cfg.Purger, util_log.Logger, prometheus.DefaultRegisterer)
+	if err != nil {
+		return nil, err
+	}
+
+	t.API.RegisterBlocksPurger(blocksPurgerAPI)
+	return nil, nil
+}
+
 func (t *Cortex) initQueryScheduler() (services.Service, error) {
 	s, err := scheduler.NewScheduler(t.Cfg.QueryScheduler, t.Overrides, util_log.Logger, prometheus.DefaultRegisterer)
 	if err != nil {
@@ -786,7 +945,7 @@ func (t *Cortex) setupModuleManager() error {
 	mm.RegisterModule(Compactor, t.initCompactor)
 	mm.RegisterModule(StoreGateway, t.initStoreGateway)
 	mm.RegisterModule(TenantDeletion, t.initTenantDeletionAPI, modules.UserInvisibleModule)
-	mm.RegisterModule(Purger, nil)
+	mm.RegisterModule(Purger, t.initBlocksPurger)
 	mm.RegisterModule(QueryScheduler, t.initQueryScheduler)
 	mm.RegisterModule(TenantFederation, t.initTenantFederation, modules.UserInvisibleModule)
 	mm.RegisterModule(All, nil)
@@ -810,19 +969,19 @@ func (t *Cortex) setupModuleManager() error {
 		QueryFrontendTripperware: {API, Overrides},
 		QueryFrontend:            {QueryFrontendTripperware},
 		QueryScheduler:           {API, Overrides},
-		Ruler:                    {DistributorService, Overrides, StoreQueryable, RulerStorage},
+		Ruler:                    {DistributorService, Overrides, StoreQueryable, RulerStorage, MemberlistKV},
 		RulerStorage:             {Overrides},
 		Configs:                  {API},
 		AlertManager:             {API, MemberlistKV, Overrides},
 		Compactor:                {API, MemberlistKV, Overrides},
 		StoreGateway:             {API, Overrides, MemberlistKV},
 		TenantDeletion:           {API, Overrides},
-		Purger:                   {TenantDeletion},
+		Purger:                   {API, Overrides, TenantDeletion},
 		TenantFederation:         {Queryable},
 		All:                      {QueryFrontend, Querier, Ingester, Distributor, Purger, StoreGateway, Ruler, Compactor, AlertManager},
 	}
 	if t.Cfg.ExternalPusher != nil && t.Cfg.ExternalQueryable != nil {
-		deps[Ruler] = []string{Overrides, RulerStorage}
+		deps[Ruler] = []string{Overrides, RulerStorage, MemberlistKV}
 	}
 	for mod, targets := range deps {
 		if err := mm.AddDependency(mod, targets...); err != nil {
@@ -4,15 +4,22 @@ import (
 	"context"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/go-kit/log"
 	"github.com/gorilla/mux"
 	prom_storage "github.com/prometheus/prometheus/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
 	"github.com/weaveworks/common/server"
 
 	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/ring/kv"
+	"github.com/cortexproject/cortex/pkg/storage/bucket/filesystem"
+	"github.com/cortexproject/cortex/pkg/util/runtimeconfig"
+	"github.com/cortexproject/cortex/pkg/util/services"
 )
 
 func changeTargetConfig(c *Config) {
@@ -232,3 +239,48 @@ func Test_setupModuleManager(t *testing.T) {
 		}
 	}
 }
+
+func TestSetMultiKVConfigProviders(t *testing.T) {
+	runtimeConfigFile := filepath.Join(t.TempDir(), "runtime.yaml")
+	require.NoError(t, os.WriteFile(runtimeConfigFile, []byte(`
+multi_kv_config:
+  primary: etcd
+  mirror_enabled: true
+`), 0o644))
+
+	cfg := newDefaultConfig()
+	cfg.RuntimeConfig.LoadPath = runtimeConfigFile
+	cfg.RuntimeConfig.Loader = loadRuntimeConfig
+
+	manager, err := runtimeconfig.New(cfg.RuntimeConfig, nil, log.NewNopLogger(), func(_ context.Context) (objstore.Bucket, error) {
+		return filesystem.NewBucketClient(filesystem.Config{Directory: "/"})
+	})
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), manager))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), manager))
+	})
+
+	cortex := &Cortex{
+		Cfg:           *cfg,
+		RuntimeConfig: manager,
+	}
+	cortex.setMultiKVConfigProviders()
+
+	for name, multiCfg := range map[string]kv.MultiConfig{
+		"ingester":      cortex.Cfg.Ingester.LifecyclerConfig.RingConfig.KVStore.Multi,
+		"distributor":   cortex.Cfg.Distributor.DistributorRing.KVStore.Multi,
+		"ha-tracker":    cortex.Cfg.Distributor.HATrackerConfig.KVStore.Multi,
+		"store-gateway": cortex.Cfg.StoreGateway.ShardingRing.KVStore.Multi,
+		"compactor":     cortex.Cfg.Compactor.ShardingRing.KVStore.Multi,
+		"ruler":         cortex.Cfg.Ruler.Ring.KVStore.Multi,
+		"alertmanager":  cortex.Cfg.Alertmanager.ShardingRing.KVStore.Multi,
+	} {
+		require.NotNil(t, multiCfg.ConfigProvider, name)
+
+		runtimeCfg := <-multiCfg.ConfigProvider()
+		assert.Equal(t, "etcd", runtimeCfg.PrimaryStore, name)
+		require.NotNil(t, runtimeCfg.Mirroring, name)
+		assert.True(t, *runtimeCfg.Mirroring, name)
+	}
+}
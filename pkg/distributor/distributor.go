@@ -6,6 +6,7 @@ import (
 	"fmt"
 	io "io"
 	"net/http"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -26,6 +27,8 @@ import (
 	"github.com/weaveworks/common/instrument"
 	"github.com/weaveworks/common/user"
 	"go.uber.org/atomic"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/cortexproject/cortex/pkg/cortexpb"
 	"github.com/cortexproject/cortex/pkg/ha"
@@ -74,6 +77,11 @@ const (
 
 	sampleMetricTypeFloat     = "float"
 	sampleMetricTypeHistogram = "histogram"
+
+	// tsdbStatusIngesterLimitFactor is how many times the requested number of items is
+	// asked to each ingester by TSDBStatus, so that items outside the top of some
+	// ingesters are still counted.
+	tsdbStatusIngesterLimitFactor = 10
 )
 
 // Distributor is a storage.SampleAppender and a client.Querier which
@@ -144,6 +152,7 @@ type Config struct {
 	ShardByAllLabels         bool   `yaml:"shard_by_all_labels"`
 	ExtendWrites             bool   `yaml:"extend_writes"`
 	SignWriteRequestsEnabled bool   `yaml:"sign_write_requests"`
+	ZoneAwareWriteQuorum     bool   `yaml:"zone_aware_write_quorum"`
 
 	// Distributors ring
 	DistributorRing RingConfig `yaml:"ring"`
@@ -158,6 +167,12 @@ type Config struct {
 	// This config is dynamically injected because defined in the querier config.
 	ShuffleShardingLookbackPeriod time.Duration `yaml:"-"`
 
+	// This config is dynamically injected because defined in the ingester ring config.
+	IngestersZoneAwarenessEnabled bool `yaml:"-"`
+
+	// This config is dynamically injected because defined in the ingester ring config.
+	IngestersZoneAwarenessMinSuccessZones int `yaml:"-"`
+
 	// ZoneResultsQuorumMetadata enables zone results quorum when querying ingester replication set
 	// with metadata APIs (labels names and values for now). When zone awareness is enabled, only results
 	// from quorum number of zones will be included to reduce data merged and improve performance.
@@ -194,6 +209,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.SignWriteRequestsEnabled, "distributor.sign-write-requests", false, "EXPERIMENTAL: If enabled, sign the write request between distributors and ingesters.")
 	f.StringVar(&cfg.ShardingStrategy, "distributor.sharding-strategy", util.ShardingStrategyDefault, fmt.Sprintf("The sharding strategy to use. Supported values are: %s.", strings.Join(supportedShardingStrategies, ", ")))
 	f.BoolVar(&cfg.ExtendWrites, "distributor.extend-writes", true, "Try writing to an additional ingester in the presence of an ingester not in the ACTIVE state. It is useful to disable this along with -ingester.unregister-on-shutdown=false in order to not spread samples to extra ingesters during rolling restarts with consistent naming.")
+	f.BoolVar(&cfg.ZoneAwareWriteQuorum, "distributor.zone-aware-write-quorum", false, "Experimental, this flag may change in the future. If zone awareness and this both enabled, a write succeeds once it has been acknowledged by the ingesters of a majority of the zones it is replicated to, instead of a majority of the ingesters, so that a full zone outage doesn't fail writes.")
 	f.BoolVar(&cfg.ZoneResultsQuorumMetadata, "distributor.zone-results-quorum-metadata", false, "Experimental, this flag may change in the future. If zone awareness and this both enabled, when querying metadata APIs (labels names and values for now), only results from quorum number of zones will be included.")
 
 	f.Float64Var(&cfg.InstanceLimits.MaxIngestionRate, "distributor.instance-limits.max-ingestion-rate", 0, "Max ingestion rate (samples/sec) that this distributor will accept. This limit is per-distributor, not per-tenant. Additional push requests will be rejected. Current ingestion rate is computed as exponentially weighted moving average, updated every second. 0 = unlimited.")
@@ -806,7 +822,12 @@ func (d *Distributor) doBatch(ctx context.Context, req *cortexpb.WriteRequest, s
 		op = ring.Write
 	}
 
-	return ring.DoBatch(ctx, op, subRing, keys, func(ingester ring.InstanceDesc, indexes []int) error {
+	batchOpts := ring.DoBatchOptions{
+		ZoneQuorum:      d.cfg.ZoneAwareWriteQuorum && d.cfg.IngestersZoneAwarenessEnabled,
+		MinSuccessZones: d.cfg.IngestersZoneAwarenessMinSuccessZones,
+	}
+
+	return ring.DoBatchWithOptions(ctx, op, subRing, keys, func(ingester ring.InstanceDesc, indexes []int) error {
 		timeseries := make([]cortexpb.PreallocTimeseries, 0, len(indexes))
 		var metadata []*cortexpb.MetricMetadata
 
@@ -822,7 +843,7 @@ func (d *Distributor) doBatch(ctx context.Context, req *cortexpb.WriteRequest, s
 	}, func() {
 		cortexpb.ReuseSlice(req.Timeseries)
 		cancel()
-	})
+	}, batchOpts)
 }
 
 func (d *Distributor) prepareMetadataKeys(req *cortexpb.WriteRequest, limits *validation.Limits, userID string, firstPartialErr error) ([]uint32, []*cortexpb.MetricMetadata, error) {
@@ -1029,6 +1050,8 @@ func (d *Distributor) send(ctx context.Context, ingester ring.InstanceDesc, time
 	// See: https://github.com/grpc/grpc-go/issues/6355
 	if err == nil {
 		cortexpb.ReuseWriteRequest(req)
+	} else {
+		err = mapIngesterPushError(err)
 	}
 
 	if len(metadata) > 0 {
@@ -1047,6 +1070,15 @@ func (d *Distributor) send(ctx context.Context, ingester ring.InstanceDesc, time
 	return err
 }
 
+// mapIngesterPushError converts the codes.ResourceExhausted gRPC error, returned by ingesters
+// when one of their instance limits is reached, into a 429 so that clients back off and retry.
+func mapIngesterPushError(err error) error {
+	if s, ok := status.FromError(err); ok && s.Code() == codes.ResourceExhausted {
+		return httpgrpc.Errorf(http.StatusTooManyRequests, "%s", s.Message())
+	}
+	return err
+}
+
 func getErrorStatus(err error) string {
 	status := "5xx"
 	httpResp, ok := httpgrpc.HTTPResponseFromError(err)
@@ -1325,14 +1357,13 @@ func (d *Distributor) metricsForLabelMatchersCommon(ctx context.Context, from, t
 	return result, nil
 }
 
-// MetricsMetadata returns all metric metadata of a user.
-func (d *Distributor) MetricsMetadata(ctx context.Context) ([]scrape.MetricMetadata, error) {
+// MetricsMetadata returns the metric metadata of a user, up to the limits of the request.
+func (d *Distributor) MetricsMetadata(ctx context.Context, req *ingester_client.MetricsMetadataRequest) ([]scrape.MetricMetadata, error) {
 	replicationSet, err := d.GetIngestersForMetadata(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	req := &ingester_client.MetricsMetadataRequest{}
 	// TODO(gotjosh): We only need to look in all the ingesters if shardByAllLabels is enabled.
 	resps, err := d.ForReplicationSet(ctx, replicationSet, d.cfg.ZoneResultsQuorumMetadata, func(ctx context.Context, client ingester_client.IngesterClient) (interface{}, error) {
 		return client.MetricsMetadata(ctx, req)
@@ -1343,9 +1374,14 @@ func (d *Distributor) MetricsMetadata(ctx context.Context) ([]scrape.MetricMetad
 
 	result := []scrape.MetricMetadata{}
 	dedupTracker := map[cortexpb.MetricMetadata]struct{}{}
+	metadataPerMetric := map[string]int64{}
 	for _, resp := range resps {
 		r := resp.(*ingester_client.MetricsMetadataResponse)
 		for _, m := range r.Metadata {
+			if req.GetMetric() != "" && m.MetricFamilyName != req.GetMetric() {
+				continue
+			}
+
 			// Given we look across all ingesters - dedup the metadata.
 			_, ok := dedupTracker[*m]
 			if ok {
@@ -1353,6 +1389,11 @@ func (d *Distributor) MetricsMetadata(ctx context.Context) ([]scrape.MetricMetad
 			}
 			dedupTracker[*m] = struct{}{}
 
+			if limit := req.GetLimitPerMetric(); limit > 0 && metadataPerMetric[m.MetricFamilyName] >= limit {
+				continue
+			}
+			metadataPerMetric[m.MetricFamilyName]++
+
 			result = append(result, scrape.MetricMetadata{
 				Metric: m.MetricFamilyName,
 				Help:   m.Help,
@@ -1362,6 +1403,24 @@ func (d *Distributor) MetricsMetadata(ctx context.Context) ([]scrape.MetricMetad
 		}
 	}
 
+	// Each ingester returns the metadata of its first metrics sorted by name,
+	// so the first metrics of their union are the first ones of the tenant.
+	if limit := req.GetLimit(); limit > 0 && int64(len(metadataPerMetric)) > limit {
+		metrics := make([]string, 0, len(metadataPerMetric))
+		for metric := range metadataPerMetric {
+			metrics = append(metrics, metric)
+		}
+		slices.Sort(metrics)
+		for _, metric := range metrics[limit:] {
+			delete(metadataPerMetric, metric)
+		}
+
+		result = slices.DeleteFunc(result, func(m scrape.MetricMetadata) bool {
+			_, ok := metadataPerMetric[m.Metric]
+			return !ok
+		})
+	}
+
 	return result, nil
 }
 
@@ -1401,6 +1460,95 @@ func (d *Distributor) UserStats(ctx context.Context) (*ingester.UserStats, error
 	return totalStats, nil
 }
 
+// TSDBStatus returns the cardinality statistics of the TSDB heads of the current user,
+// aggregated across the ingesters. The series counts and the memory used by the label
+// values are summed and divided by the replication factor, while the numbers of label
+// values and label pairs, which are shared by the ingesters, are the highest reported.
+// Since each ingester only returns its own top items, the lists are approximated: the
+// ingesters are asked for more items than requested to reduce the error.
+func (d *Distributor) TSDBStatus(ctx context.Context, req *ingester_client.TSDBStatusRequest) (*ingester_client.TSDBStatusResponse, error) {
+	replicationSet, err := d.GetIngestersForMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure we get a successful response from all of them.
+	replicationSet.MaxErrors = 0
+
+	ingesterLimit := min(int64(req.Limit)*tsdbStatusIngesterLimitFactor, ingester_client.MaxTSDBStatusLimit)
+	ingesterReq := &ingester_client.TSDBStatusRequest{Limit: int32(ingesterLimit)}
+	resps, err := d.ForReplicationSet(ctx, replicationSet, false, func(ctx context.Context, client ingester_client.IngesterClient) (interface{}, error) {
+		return client.TSDBStatus(ctx, ingesterReq)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result                      = &ingester_client.TSDBStatusResponse{}
+		seriesCountByMetricName     = map[string]uint64{}
+		labelValueCountByLabelName  = map[string]uint64{}
+		memoryInBytesByLabelName    = map[string]uint64{}
+		seriesCountByLabelValuePair = map[string]uint64{}
+	)
+	for _, resp := range resps {
+		r := resp.(*ingester_client.TSDBStatusResponse)
+		if r.NumSeries == 0 {
+			continue
+		}
+
+		if result.NumSeries == 0 || r.MinTime < result.MinTime {
+			result.MinTime = r.MinTime
+		}
+		if result.NumSeries == 0 || r.MaxTime > result.MaxTime {
+			result.MaxTime = r.MaxTime
+		}
+		result.NumSeries += r.NumSeries
+		result.NumLabelPairs = max(result.NumLabelPairs, r.NumLabelPairs)
+
+		for _, s := range r.SeriesCountByMetricName {
+			seriesCountByMetricName[s.Name] += s.Value
+		}
+		for _, s := range r.LabelValueCountByLabelName {
+			labelValueCountByLabelName[s.Name] = max(labelValueCountByLabelName[s.Name], s.Value)
+		}
+		for _, s := range r.MemoryInBytesByLabelName {
+			memoryInBytesByLabelName[s.Name] += s.Value
+		}
+		for _, s := range r.SeriesCountByLabelValuePair {
+			seriesCountByLabelValuePair[s.Name] += s.Value
+		}
+	}
+
+	factor := uint64(d.ingestersRing.ReplicationFactor())
+	limit := int(req.Limit)
+	result.NumSeries /= factor
+	result.SeriesCountByMetricName = topTSDBStatistics(seriesCountByMetricName, factor, limit)
+	result.LabelValueCountByLabelName = topTSDBStatistics(labelValueCountByLabelName, 1, limit)
+	result.MemoryInBytesByLabelName = topTSDBStatistics(memoryInBytesByLabelName, factor, limit)
+	result.SeriesCountByLabelValuePair = topTSDBStatistics(seriesCountByLabelValuePair, factor, limit)
+
+	return result, nil
+}
+
+// topTSDBStatistics returns the limit statistics with the highest values, divided by factor.
+func topTSDBStatistics(values map[string]uint64, factor uint64, limit int) []*ingester_client.TSDBStatistic {
+	result := make([]*ingester_client.TSDBStatistic, 0, len(values))
+	for name, value := range values {
+		result = append(result, &ingester_client.TSDBStatistic{Name: name, Value: value / factor})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Value != result[j].Value {
+			return result[i].Value > result[j].Value
+		}
+		return result[i].Name < result[j].Name
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
 // AllUserStats returns statistics about all users.
 // Note it does not divide by the ReplicationFactor like UserStats()
 func (d *Distributor) AllUserStats(ctx context.Context) ([]ingester.UserIDStats, error) {
@@ -1485,6 +1633,21 @@ func (d *Distributor) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// RingDumpHandler responds with the full distributors ring descriptor as JSON.
+func (d *Distributor) RingDumpHandler(w http.ResponseWriter, req *http.Request) {
+	if d.distributorsRing == nil {
+		http.Error(w, "Distributor is not running with global limits enabled.", http.StatusNotFound)
+		return
+	}
+
+	if d.State() != services.Running {
+		http.Error(w, "Distributor is not running yet.", http.StatusServiceUnavailable)
+		return
+	}
+
+	d.distributorsRing.DumpHandler(w, req)
+}
+
 func findHALabels(replicaLabel, clusterLabel string, labels []cortexpb.LabelAdapter) (string, string) {
 	var cluster, replica string
 	var pair cortexpb.LabelAdapter
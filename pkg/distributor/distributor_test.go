@@ -7,6 +7,7 @@ import (
 	"math"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	"sort"
 	"strconv"
 	"strings"
@@ -22,7 +23,9 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/index"
 	"github.com/prometheus/prometheus/tsdb/tsdbutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -935,6 +938,21 @@ func TestDistributor_PushInstanceLimits(t *testing.T) {
 	}
 }
 
+func TestMapIngesterPushError(t *testing.T) {
+	err := mapIngesterPushError(status.Error(codes.ResourceExhausted, "cannot push: too many inflight push requests in ingester"))
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+	assert.Equal(t, "cannot push: too many inflight push requests in ingester", string(resp.Body))
+	assert.Equal(t, "4xx", getErrorStatus(err))
+
+	original := httpgrpc.Errorf(http.StatusBadRequest, "bad request")
+	assert.Equal(t, original, mapIngesterPushError(original))
+
+	original = status.Error(codes.Unavailable, "unavailable")
+	assert.Equal(t, original, mapIngesterPushError(original))
+}
+
 func TestDistributor_PushHAInstances(t *testing.T) {
 	t.Parallel()
 	ctx := user.InjectOrgID(context.Background(), "user")
@@ -1317,7 +1335,7 @@ func TestDistributor_QueryStream_ShouldReturnErrorIfMaxChunksPerQueryLimitIsReac
 			limits:           limits,
 		})
 
-		ctx = limiter.AddQueryLimiterToContext(ctx, limiter.NewQueryLimiter(0, 0, maxChunksLimit, 0))
+		ctx = limiter.AddQueryLimiterToContext(ctx, limiter.NewQueryLimiter(0, 0, maxChunksLimit, 0, 0, 0))
 
 		// Push a number of series below the max chunks limit. Each series has 1 sample,
 		// so expect 1 chunk per series when querying back.
@@ -1370,7 +1388,7 @@ func TestDistributor_QueryStream_ShouldReturnErrorIfMaxSeriesPerQueryLimitIsReac
 		ctx := user.InjectOrgID(context.Background(), "user")
 		limits := &validation.Limits{}
 		flagext.DefaultValues(limits)
-		ctx = limiter.AddQueryLimiterToContext(ctx, limiter.NewQueryLimiter(maxSeriesLimit, 0, 0, 0))
+		ctx = limiter.AddQueryLimiterToContext(ctx, limiter.NewQueryLimiter(maxSeriesLimit, 0, 0, 0, 0, 0))
 
 		// Prepare distributors.
 		ds, _, _, _ := prepare(t, prepConfig{
@@ -1462,7 +1480,7 @@ func TestDistributor_QueryStream_ShouldReturnErrorIfMaxChunkBytesPerQueryLimitIs
 		var maxBytesLimit = (seriesToAdd) * responseChunkSize
 
 		// Update the limiter with the calculated limits.
-		ctx = limiter.AddQueryLimiterToContext(ctx, limiter.NewQueryLimiter(0, maxBytesLimit, 0, 0))
+		ctx = limiter.AddQueryLimiterToContext(ctx, limiter.NewQueryLimiter(0, maxBytesLimit, 0, 0, 0, 0))
 
 		// Push a number of series below the max chunk bytes limit. Subtract one for the series added above.
 		if histogram {
@@ -1539,7 +1557,7 @@ func TestDistributor_QueryStream_ShouldReturnErrorIfMaxDataBytesPerQueryLimitIsR
 		var maxBytesLimit = (seriesToAdd) * dataSize * 2 // Multiplying by RF because the limit is applied before de-duping.
 
 		// Update the limiter with the calculated limits.
-		ctx = limiter.AddQueryLimiterToContext(ctx, limiter.NewQueryLimiter(0, 0, 0, maxBytesLimit))
+		ctx = limiter.AddQueryLimiterToContext(ctx, limiter.NewQueryLimiter(0, 0, 0, maxBytesLimit, 0, 0))
 
 		// Push a number of series below the max chunk bytes limit. Subtract one for the series added above.
 		if histogram {
@@ -2429,7 +2447,7 @@ func TestDistributor_MetricsForLabelMatchers(t *testing.T) {
 			},
 			expectedResult:    []model.Metric{},
 			expectedIngesters: numIngesters,
-			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 0),
+			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 0, 0, 0),
 			expectedErr:       nil,
 		},
 		"should filter metrics by single matcher": {
@@ -2441,7 +2459,7 @@ func TestDistributor_MetricsForLabelMatchers(t *testing.T) {
 				util.LabelsToMetric(fixtures[1].lbls),
 			},
 			expectedIngesters: numIngesters,
-			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 0),
+			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 0, 0, 0),
 			expectedErr:       nil,
 		},
 		"should filter metrics by multiple matchers": {
@@ -2453,7 +2471,7 @@ func TestDistributor_MetricsForLabelMatchers(t *testing.T) {
 				util.LabelsToMetric(fixtures[0].lbls),
 			},
 			expectedIngesters: numIngesters,
-			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 0),
+			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 0, 0, 0),
 			expectedErr:       nil,
 		},
 		"should return all matching metrics even if their FastFingerprint collide": {
@@ -2465,7 +2483,7 @@ func TestDistributor_MetricsForLabelMatchers(t *testing.T) {
 				util.LabelsToMetric(fixtures[4].lbls),
 			},
 			expectedIngesters: numIngesters,
-			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 0),
+			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 0, 0, 0),
 			expectedErr:       nil,
 		},
 		"should query only ingesters belonging to tenant's subring if shuffle sharding is enabled": {
@@ -2479,7 +2497,7 @@ func TestDistributor_MetricsForLabelMatchers(t *testing.T) {
 				util.LabelsToMetric(fixtures[1].lbls),
 			},
 			expectedIngesters: 3,
-			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 0),
+			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 0, 0, 0),
 			expectedErr:       nil,
 		},
 		"should query all ingesters if shuffle sharding is enabled but shard size is 0": {
@@ -2493,7 +2511,7 @@ func TestDistributor_MetricsForLabelMatchers(t *testing.T) {
 				util.LabelsToMetric(fixtures[1].lbls),
 			},
 			expectedIngesters: numIngesters,
-			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 0),
+			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 0, 0, 0),
 			expectedErr:       nil,
 		},
 		"should return err if series limit is exhausted": {
@@ -2504,7 +2522,7 @@ func TestDistributor_MetricsForLabelMatchers(t *testing.T) {
 			},
 			expectedResult:    nil,
 			expectedIngesters: numIngesters,
-			queryLimiter:      limiter.NewQueryLimiter(1, 0, 0, 0),
+			queryLimiter:      limiter.NewQueryLimiter(1, 0, 0, 0, 0, 0),
 			expectedErr:       validation.LimitError(fmt.Sprintf(limiter.ErrMaxSeriesHit, 1)),
 		},
 		"should return err if data bytes limit is exhausted": {
@@ -2515,7 +2533,7 @@ func TestDistributor_MetricsForLabelMatchers(t *testing.T) {
 			},
 			expectedResult:    nil,
 			expectedIngesters: numIngesters,
-			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 1),
+			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 1, 0, 0),
 			expectedErr:       validation.LimitError(fmt.Sprintf(limiter.ErrMaxDataBytesHit, 1)),
 		},
 		"should not exhaust series limit when only one series is fetched": {
@@ -2526,7 +2544,7 @@ func TestDistributor_MetricsForLabelMatchers(t *testing.T) {
 				util.LabelsToMetric(fixtures[2].lbls),
 			},
 			expectedIngesters: numIngesters,
-			queryLimiter:      limiter.NewQueryLimiter(1, 0, 0, 0),
+			queryLimiter:      limiter.NewQueryLimiter(1, 0, 0, 0, 0, 0),
 			expectedErr:       nil,
 		},
 	}
@@ -2631,7 +2649,7 @@ func BenchmarkDistributor_MetricsForLabelMatchers(b *testing.B) {
 			matchers: []*labels.Matcher{
 				mustNewMatcher(labels.MatchRegexp, model.MetricNameLabel, "foo.+"),
 			},
-			queryLimiter: limiter.NewQueryLimiter(100, 0, 0, 0),
+			queryLimiter: limiter.NewQueryLimiter(100, 0, 0, 0, 0, 0),
 			expectedErr:  nil,
 		},
 	}
@@ -2733,7 +2751,7 @@ func TestDistributor_MetricsMetadata(t *testing.T) {
 			require.NoError(t, err)
 
 			// Assert on metric metadata
-			metadata, err := ds[0].MetricsMetadata(ctx)
+			metadata, err := ds[0].MetricsMetadata(ctx, &client.MetricsMetadataRequest{})
 			require.NoError(t, err)
 			assert.Equal(t, 10, len(metadata))
 
@@ -2746,6 +2764,184 @@ func TestDistributor_MetricsMetadata(t *testing.T) {
 	}
 }
 
+func TestDistributor_MetricsMetadata_Limits(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		req             *client.MetricsMetadataRequest
+		expectedMetrics map[string]int
+	}{
+		"should return all the metadata without limits": {
+			req:             &client.MetricsMetadataRequest{},
+			expectedMetrics: map[string]int{"metric_0": 1, "metric_1": 2, "metric_2": 1, "metric_3": 1, "metric_4": 1},
+		},
+		"should return the metadata of the first metrics sorted by name": {
+			req:             &client.MetricsMetadataRequest{Limit: 2},
+			expectedMetrics: map[string]int{"metric_0": 1, "metric_1": 2},
+		},
+		"should limit the metadata per metric": {
+			req:             &client.MetricsMetadataRequest{LimitPerMetric: 1},
+			expectedMetrics: map[string]int{"metric_0": 1, "metric_1": 1, "metric_2": 1, "metric_3": 1, "metric_4": 1},
+		},
+		"should only return the metadata of the requested metric": {
+			req:             &client.MetricsMetadataRequest{Metric: "metric_1"},
+			expectedMetrics: map[string]int{"metric_1": 2},
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+			ds, _, _, _ := prepare(t, prepConfig{
+				numIngesters:     3,
+				happyIngesters:   3,
+				numDistributors:  1,
+				shardByAllLabels: true,
+			})
+
+			ctx := user.InjectOrgID(context.Background(), "test")
+			req := makeWriteRequest(0, 0, 5, 0)
+			req.Metadata = append(req.Metadata, &cortexpb.MetricMetadata{MetricFamilyName: "metric_1", Type: cortexpb.GAUGE, Help: "another help for metric_1"})
+			_, err := ds[0].Push(ctx, req)
+			require.NoError(t, err)
+
+			metadata, err := ds[0].MetricsMetadata(ctx, testData.req)
+			require.NoError(t, err)
+
+			metrics := map[string]int{}
+			for _, m := range metadata {
+				metrics[m.Metric]++
+			}
+			assert.Equal(t, testData.expectedMetrics, metrics)
+		})
+	}
+}
+
+func TestDistributor_TSDBStatus(t *testing.T) {
+	t.Parallel()
+
+	ds, ingesters, _, _ := prepare(t, prepConfig{
+		numIngesters:     3,
+		happyIngesters:   3,
+		numDistributors:  1,
+		shardByAllLabels: true,
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	_, err := ds[0].Push(ctx, mockWriteRequest([]labels.Labels{
+		labels.FromStrings(labels.MetricName, "foo", "job", "a"),
+		labels.FromStrings(labels.MetricName, "foo", "job", "b"),
+		labels.FromStrings(labels.MetricName, "foo", "job", "c"),
+		labels.FromStrings(labels.MetricName, "bar", "job", "a"),
+	}, 1, 1, false))
+	require.NoError(t, err)
+
+	// The series are replicated to all the 3 ingesters, but the push returns once
+	// the quorum is reached, so wait for the last one.
+	for _, ing := range ingesters {
+		test.Poll(t, time.Second, 4, func() interface{} {
+			return len(ing.series())
+		})
+	}
+
+	resp, err := ds[0].TSDBStatus(ctx, &client.TSDBStatusRequest{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), resp.NumSeries)
+	assert.Equal(t, int32(5), resp.NumLabelPairs)
+	assert.Equal(t, []*client.TSDBStatistic{{Name: "foo", Value: 3}, {Name: "bar", Value: 1}}, resp.SeriesCountByMetricName)
+	assert.Equal(t, []*client.TSDBStatistic{{Name: "job", Value: 3}, {Name: labels.MetricName, Value: 2}}, resp.LabelValueCountByLabelName)
+	assert.Equal(t, []*client.TSDBStatistic{{Name: labels.MetricName, Value: 12}, {Name: "job", Value: 4}}, resp.MemoryInBytesByLabelName)
+	assert.Equal(t, []*client.TSDBStatistic{{Name: "__name__=foo", Value: 3}, {Name: "job=a", Value: 2}}, resp.SeriesCountByLabelValuePair)
+
+	// The limit asked to the ingesters doesn't overflow.
+	resp, err = ds[0].TSDBStatus(ctx, &client.TSDBStatusRequest{Limit: math.MaxInt32})
+	require.NoError(t, err)
+	assert.Len(t, resp.SeriesCountByMetricName, 2)
+}
+
+func TestDistributor_TSDBStatus_ShouldCountItemsOutsideTheTopOfSomeIngesters(t *testing.T) {
+	t.Parallel()
+
+	ds, _, _, _ := prepare(t, prepConfig{
+		numIngesters:      6,
+		happyIngesters:    6,
+		numDistributors:   1,
+		shardByAllLabels:  true,
+		replicationFactor: 1,
+	})
+
+	// The team=shared label pair is on a series of each metric, spread across the
+	// ingesters: it's one of the top pairs of the tenant, but not of any ingester.
+	var series []labels.Labels
+	for m := 0; m < 10; m++ {
+		for s := 0; s < 10-m; s++ {
+			team := "team-" + strconv.Itoa(s)
+			if s == 0 {
+				team = "shared"
+			}
+			series = append(series, labels.FromStrings(labels.MetricName, fmt.Sprintf("metric_%d", m), "team", team))
+		}
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	_, err := ds[0].Push(ctx, mockWriteRequest(series, 1, 1, false))
+	require.NoError(t, err)
+
+	resp, err := ds[0].TSDBStatus(ctx, &client.TSDBStatusRequest{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(55), resp.NumSeries)
+	assert.Equal(t, []*client.TSDBStatistic{{Name: "__name__=metric_0", Value: 10}, {Name: "team=shared", Value: 10}}, resp.SeriesCountByLabelValuePair)
+}
+
+func TestDistributor_RingDumpHandler(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		ingestionRateStrategy string
+		stopped               bool
+		expectedStatusCode    int
+	}{
+		"should return 404 without the distributors ring": {
+			ingestionRateStrategy: validation.LocalIngestionRateStrategy,
+			expectedStatusCode:    http.StatusNotFound,
+		},
+		"should return the ring dump when running": {
+			ingestionRateStrategy: validation.GlobalIngestionRateStrategy,
+			expectedStatusCode:    http.StatusOK,
+		},
+		"should return 503 when not running": {
+			ingestionRateStrategy: validation.GlobalIngestionRateStrategy,
+			stopped:               true,
+			expectedStatusCode:    http.StatusServiceUnavailable,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			limits := &validation.Limits{}
+			flagext.DefaultValues(limits)
+			limits.IngestionRateStrategy = tc.ingestionRateStrategy
+
+			ds, _, _, _ := prepare(t, prepConfig{
+				numIngesters:     3,
+				happyIngesters:   3,
+				numDistributors:  1,
+				shardByAllLabels: true,
+				limits:           limits,
+			})
+			if tc.stopped {
+				require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ds[0]))
+			}
+
+			rec := httptest.NewRecorder()
+			ds[0].RingDumpHandler(rec, httptest.NewRequest(http.MethodGet, "/distributor/ring/json", nil))
+			assert.Equal(t, tc.expectedStatusCode, rec.Code)
+		})
+	}
+}
+
 func mustNewMatcher(t labels.MatchType, n, v string) *labels.Matcher {
 	m, err := labels.NewMatcher(t, n, v)
 	if err != nil {
@@ -3486,6 +3682,34 @@ func (i *mockIngester) MetricsMetadata(ctx context.Context, req *client.MetricsM
 	return resp, nil
 }
 
+func (i *mockIngester) TSDBStatus(ctx context.Context, req *client.TSDBStatusRequest, opts ...grpc.CallOption) (*client.TSDBStatusResponse, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	i.trackCall("TSDBStatus")
+
+	if !i.happy.Load() {
+		return nil, errFail
+	}
+
+	postings := index.NewMemPostings()
+	ref := storage.SeriesRef(0)
+	for _, ts := range i.timeseries {
+		ref++
+		postings.Add(ref, cortexpb.FromLabelAdaptersToLabels(ts.Labels))
+	}
+	stats := postings.Stats(labels.MetricName, int(req.Limit))
+
+	return &client.TSDBStatusResponse{
+		NumSeries:                   uint64(len(i.timeseries)),
+		NumLabelPairs:               int32(stats.NumLabelPairs),
+		SeriesCountByMetricName:     client.FromIndexStats(stats.CardinalityMetricsStats),
+		LabelValueCountByLabelName:  client.FromIndexStats(stats.CardinalityLabelStats),
+		MemoryInBytesByLabelName:    client.FromIndexStats(stats.LabelValueStats),
+		SeriesCountByLabelValuePair: client.FromIndexStats(stats.LabelValuePairsStats),
+	}, nil
+}
+
 func (i *mockIngester) trackCall(name string) {
 	if i.calls == nil {
 		i.calls = map[string]int{}
@@ -33,9 +33,11 @@ func (cfg *PoolConfig) RegisterFlags(f *flag.FlagSet) {
 
 func NewPool(cfg PoolConfig, ring ring.ReadRing, factory ring_client.PoolFactory, logger log.Logger) *ring_client.Pool {
 	poolCfg := ring_client.PoolConfig{
-		CheckInterval:      cfg.ClientCleanupPeriod,
-		HealthCheckEnabled: cfg.HealthCheckIngesters,
-		HealthCheckTimeout: cfg.RemoteTimeout,
+		CheckInterval:          cfg.ClientCleanupPeriod,
+		HealthCheckEnabled:     cfg.HealthCheckIngesters,
+		HealthCheckTimeout:     cfg.RemoteTimeout,
+		CheckIntervalJitter:    0.2,
+		HealthCheckConcurrency: 16,
 	}
 
 	return ring_client.NewPool("ingester", poolCfg, ring_client.NewRingServiceDiscovery(ring), factory, clients, logger)
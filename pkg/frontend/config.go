@@ -19,6 +19,7 @@ type CombinedFrontendConfig struct {
 	Handler    transport.HandlerConfig `yaml:",inline"`
 	FrontendV1 v1.Config               `yaml:",inline"`
 	FrontendV2 v2.Config               `yaml:",inline"`
+	Hedging    transport.HedgingConfig `yaml:",inline"`
 
 	DownstreamURL string `yaml:"downstream_url"`
 }
@@ -27,10 +28,15 @@ func (cfg *CombinedFrontendConfig) RegisterFlags(f *flag.FlagSet) {
 	cfg.Handler.RegisterFlags(f)
 	cfg.FrontendV1.RegisterFlags(f)
 	cfg.FrontendV2.RegisterFlags(f)
+	cfg.Hedging.RegisterFlags(f)
 
 	f.StringVar(&cfg.DownstreamURL, "frontend.downstream-url", "", "URL of downstream Prometheus.")
 }
 
+func (cfg *CombinedFrontendConfig) Validate() error {
+	return cfg.Hedging.Validate()
+}
+
 // InitFrontend initializes frontend (either V1 -- without scheduler, or V2 -- with scheduler) or no frontend at
 // all if downstream Prometheus URL is used instead.
 //
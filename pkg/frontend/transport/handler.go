@@ -73,12 +73,16 @@ type HandlerConfig struct {
 	LogQueriesLongerThan time.Duration `yaml:"log_queries_longer_than"`
 	MaxBodySize          int64         `yaml:"max_body_size"`
 	QueryStatsEnabled    bool          `yaml:"query_stats_enabled"`
+	AuditLogEnabled      bool          `yaml:"audit_log_enabled"`
+	AuditLogUserHeader   string        `yaml:"audit_log_user_header"`
 }
 
 func (cfg *HandlerConfig) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&cfg.LogQueriesLongerThan, "frontend.log-queries-longer-than", 0, "Log queries that are slower than the specified duration. Set to 0 to disable. Set to < 0 to enable on all queries.")
 	f.Int64Var(&cfg.MaxBodySize, "frontend.max-body-size", 10*1024*1024, "Max body size for downstream prometheus.")
 	f.BoolVar(&cfg.QueryStatsEnabled, "frontend.query-stats-enabled", false, "True to enable query statistics tracking. When enabled, a message with some statistics is logged for every query.")
+	f.BoolVar(&cfg.AuditLogEnabled, "frontend.audit-log-enabled", false, "True to log an audit message for every query served, with the tenant, the user, the query parameters, the status code and the response time.")
+	f.StringVar(&cfg.AuditLogUserHeader, "frontend.audit-log-user-header", "", "The request header with the user issuing the query, to include in the audit messages (eg. X-Grafana-User). If empty, the user is not logged.")
 }
 
 // Handler accepts queries and forwards them to RoundTripper. It can log slow queries,
@@ -231,7 +235,7 @@ func (f *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Check whether we should parse the query string.
 	shouldReportSlowQuery := f.cfg.LogQueriesLongerThan != 0 && queryResponseTime > f.cfg.LogQueriesLongerThan
-	if shouldReportSlowQuery || f.cfg.QueryStatsEnabled {
+	if shouldReportSlowQuery || f.cfg.QueryStatsEnabled || f.cfg.AuditLogEnabled {
 		queryString = f.parseRequestQueryString(r, buf)
 	}
 
@@ -239,7 +243,7 @@ func (f *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		f.reportSlowQuery(r, queryString, queryResponseTime)
 	}
 
-	if f.cfg.QueryStatsEnabled {
+	if f.cfg.QueryStatsEnabled || f.cfg.AuditLogEnabled {
 		// Try to parse error and get status code.
 		var statusCode int
 		if err != nil {
@@ -256,7 +260,12 @@ func (f *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		f.reportQueryStats(r, userID, queryString, queryResponseTime, stats, err, statusCode, resp)
+		if f.cfg.QueryStatsEnabled {
+			f.reportQueryStats(r, userID, queryString, queryResponseTime, stats, err, statusCode, resp)
+		}
+		if f.cfg.AuditLogEnabled {
+			f.reportQueryAudit(r, userID, queryString, queryResponseTime, err, statusCode)
+		}
 	}
 
 	hs := w.Header()
@@ -449,6 +458,36 @@ func (f *Handler) reportQueryStats(r *http.Request, userID string, queryString u
 	}
 }
 
+// reportQueryAudit logs the audit message of a query.
+func (f *Handler) reportQueryAudit(r *http.Request, userID string, queryString url.Values, queryResponseTime time.Duration, error error, statusCode int) {
+	logMessage := []interface{}{
+		"msg", "query audit",
+		"component", "query-frontend",
+		"tenant", userID,
+		"method", r.Method,
+		"path", r.URL.Path,
+		"status_code", statusCode,
+		"response_time", queryResponseTime,
+	}
+
+	if f.cfg.AuditLogUserHeader != "" {
+		if user := r.Header.Get(f.cfg.AuditLogUserHeader); len(user) > 0 {
+			logMessage = append(logMessage, "user", user)
+		}
+	}
+	if error != nil {
+		s, ok := status.FromError(error)
+		if !ok {
+			logMessage = append(logMessage, "error", error)
+		} else {
+			logMessage = append(logMessage, "error", s.Message())
+		}
+	}
+	logMessage = append(logMessage, formatQueryString(queryString)...)
+
+	level.Info(util_log.WithContext(r.Context(), f.log)).Log(logMessage...)
+}
+
 func (f *Handler) parseRequestQueryString(r *http.Request, bodyBuf bytes.Buffer) url.Values {
 	// Use previously buffered body.
 	r.Body = io.NopCloser(&bodyBuf)
@@ -199,6 +199,13 @@ func TestHandler_ServeHTTP(t *testing.T) {
 			roundTripperFunc:   roundTripper,
 			expectedStatusCode: http.StatusOK,
 		},
+		{
+			name:               "test handler with stats disabled and audit log enabled",
+			cfg:                HandlerConfig{QueryStatsEnabled: false, AuditLogEnabled: true},
+			expectedMetrics:    0,
+			roundTripperFunc:   roundTripper,
+			expectedStatusCode: http.StatusOK,
+		},
 		{
 			name:            "test handler with reasonResponseTooLarge",
 			cfg:             HandlerConfig{QueryStatsEnabled: true},
@@ -503,3 +510,52 @@ func TestReportQueryStatsFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestReportQueryAuditFormat(t *testing.T) {
+	outputBuf := bytes.NewBuffer(nil)
+	logger := log.NewSyncLogger(log.NewLogfmtLogger(outputBuf))
+	handler := NewHandler(HandlerConfig{AuditLogEnabled: true, AuditLogUserHeader: "X-Grafana-User"}, http.DefaultTransport, logger, nil)
+	userID := "fake"
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/prometheus/api/v1/query", nil)
+	responseTime := time.Second
+
+	type testCase struct {
+		queryString url.Values
+		header      http.Header
+		statusCode  int
+		responseErr error
+		expectedLog string
+	}
+
+	tests := map[string]testCase{
+		"should not include query and user if empty": {
+			statusCode:  http.StatusOK,
+			expectedLog: `level=info msg="query audit" component=query-frontend tenant=fake method=GET path=/prometheus/api/v1/query status_code=200 response_time=1s`,
+		},
+		"should include query string": {
+			queryString: url.Values(map[string][]string{"query": {"up"}}),
+			statusCode:  http.StatusOK,
+			expectedLog: `level=info msg="query audit" component=query-frontend tenant=fake method=GET path=/prometheus/api/v1/query status_code=200 response_time=1s param_query=up`,
+		},
+		"should include user": {
+			header:      http.Header{"X-Grafana-User": []string{"admin"}},
+			statusCode:  http.StatusOK,
+			expectedLog: `level=info msg="query audit" component=query-frontend tenant=fake method=GET path=/prometheus/api/v1/query status_code=200 response_time=1s user=admin`,
+		},
+		"should include response error": {
+			statusCode:  http.StatusInternalServerError,
+			responseErr: errors.New("foo_err"),
+			expectedLog: `level=info msg="query audit" component=query-frontend tenant=fake method=GET path=/prometheus/api/v1/query status_code=500 response_time=1s error=foo_err`,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			req.Header = testData.header
+			handler.reportQueryAudit(req, userID, testData.queryString, responseTime, testData.responseErr, testData.statusCode)
+			data, err := io.ReadAll(outputBuf)
+			require.NoError(t, err)
+			require.Equal(t, testData.expectedLog+"\n", string(data))
+		})
+	}
+}
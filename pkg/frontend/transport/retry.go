@@ -3,7 +3,11 @@ package transport
 import (
 	"context"
 	"errors"
+	"flag"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -12,31 +16,105 @@ import (
 	"github.com/weaveworks/common/httpgrpc"
 
 	"github.com/cortexproject/cortex/pkg/querier/tripperware"
+	"github.com/cortexproject/cortex/pkg/tenant"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
+const (
+	// retryBudgetMaxTokens is the maximum number of retries a tenant can save in its retry budget.
+	retryBudgetMaxTokens = 10
+
+	// hedgingLatencyWindow is the number of recent request latencies the hedging delay is computed from,
+	// and hedgingLatencyUpdateInterval how many latencies are observed between two computations.
+	hedgingLatencyWindow         = 1000
+	hedgingLatencyUpdateInterval = 100
+)
+
+var errInvalidHedgingPercentile = errors.New("the percentile to hedge requests at must be between 0 and 1")
+
+// HedgingConfig configures the hedging of the requests dispatched to the queriers.
+type HedgingConfig struct {
+	Percentile float64       `yaml:"hedge_requests_at_percentile"`
+	MinDelay   time.Duration `yaml:"hedge_requests_min_delay"`
+}
+
+func (cfg *HedgingConfig) RegisterFlags(f *flag.FlagSet) {
+	f.Float64Var(&cfg.Percentile, "frontend.hedge-requests-at-percentile", 0, "[Experimental] Dispatch a request again, to another querier, when it takes longer than this percentile of the latencies of the recent requests, and use the first response. This reduces the tail latency when a querier is slow, eg. during a GC pause, at the cost of running some requests twice. Must be between 0 and 1, eg. 0.95. 0 to disable.")
+	f.DurationVar(&cfg.MinDelay, "frontend.hedge-requests-min-delay", 100*time.Millisecond, "[Experimental] Minimum time to wait before dispatching a request again, when -frontend.hedge-requests-at-percentile is enabled.")
+}
+
+func (cfg *HedgingConfig) Validate() error {
+	if cfg.Percentile < 0 || cfg.Percentile >= 1 {
+		return errInvalidHedgingPercentile
+	}
+	return nil
+}
+
+// RetryLimits are the per-tenant limits of the retries.
+type RetryLimits interface {
+	// QueryRetryBudgetRatio returns the maximum ratio of retries to requests of the tenant.
+	QueryRetryBudgetRatio(userID string) float64
+}
+
 type Retry struct {
-	maxRetries   int
-	retriesCount prometheus.Histogram
+	maxRetries int
+	hedging    HedgingConfig
+	limits     RetryLimits
+
+	// Retry budget of each tenant. The tenants with a full budget are not tracked, so that the
+	// map only keeps the tenants which recently spent some of their budget.
+	budgetsMx sync.Mutex
+	budgets   map[string]float64
+
+	latencies *latencyTracker
+
+	retriesCount          prometheus.Histogram
+	retryBudgetExhausted  prometheus.Counter
+	hedgedRequestsCounter prometheus.Counter
 }
 
-func NewRetry(maxRetries int, reg prometheus.Registerer) *Retry {
-	return &Retry{
+// NewRetry makes a new Retry. The limits can be nil to not limit the retries per tenant.
+func NewRetry(maxRetries int, hedging HedgingConfig, limits RetryLimits, reg prometheus.Registerer) *Retry {
+	r := &Retry{
 		maxRetries: maxRetries,
+		hedging:    hedging,
+		limits:     limits,
+		budgets:    map[string]float64{},
 		retriesCount: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
 			Namespace: "cortex",
 			Name:      "query_frontend_retries",
 			Help:      "Number of times a request is retried.",
 			Buckets:   []float64{0, 1, 2, 3, 4, 5},
 		}),
+		retryBudgetExhausted: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: "cortex",
+			Name:      "query_frontend_retry_budget_exhausted_total",
+			Help:      "Number of failed requests not retried because the tenant's retry budget is exhausted.",
+		}),
+		hedgedRequestsCounter: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: "cortex",
+			Name:      "query_frontend_hedged_requests_total",
+			Help:      "Number of requests dispatched again because they took longer than the hedging delay.",
+		}),
+	}
+	if hedging.Percentile > 0 {
+		r.latencies = newLatencyTracker(hedging.Percentile)
 	}
+	return r
 }
 
-func (r *Retry) Do(ctx context.Context, f func() (*httpgrpc.HTTPResponse, error)) (*httpgrpc.HTTPResponse, error) {
+func (r *Retry) Do(ctx context.Context, f func(context.Context) (*httpgrpc.HTTPResponse, error)) (*httpgrpc.HTTPResponse, error) {
 	if r.maxRetries == 0 {
 		// Retries are disabled. Try only once.
-		return f()
+		return r.doHedged(ctx, f)
 	}
 
+	userID := ""
+	if tenantIDs, err := tenant.TenantIDs(ctx); err == nil {
+		userID = tenant.JoinTenantIDs(tenantIDs)
+	}
+	r.depositRetryBudget(userID)
+
 	tries := 0
 	defer func() { r.retriesCount.Observe(float64(tries)) }()
 
@@ -48,8 +126,12 @@ func (r *Retry) Do(ctx context.Context, f func() (*httpgrpc.HTTPResponse, error)
 		if ctx.Err() != nil {
 			return nil, ctx.Err()
 		}
+		if tries > 0 && !r.withdrawRetryBudget(userID) {
+			r.retryBudgetExhausted.Inc()
+			break
+		}
 
-		resp, err = f()
+		resp, err = r.doHedged(ctx, f)
 		if err != nil && !errors.Is(err, context.Canceled) {
 			continue // Retryable
 		} else if resp != nil && resp.Code/100 == 5 {
@@ -76,6 +158,164 @@ func (r *Retry) Do(ctx context.Context, f func() (*httpgrpc.HTTPResponse, error)
 	return resp, err
 }
 
+// depositRetryBudget adds the tenant's retry budget ratio to its budget, for a new request.
+func (r *Retry) depositRetryBudget(userID string) {
+	ratio := r.retryBudgetRatio(userID)
+	if ratio <= 0 {
+		return
+	}
+
+	r.budgetsMx.Lock()
+	defer r.budgetsMx.Unlock()
+
+	budget, ok := r.budgets[userID]
+	if !ok {
+		// The budget is already full.
+		return
+	}
+	if budget+ratio >= retryBudgetMaxTokens {
+		delete(r.budgets, userID)
+		return
+	}
+	r.budgets[userID] = budget + ratio
+}
+
+// withdrawRetryBudget spends a retry from the tenant's retry budget, and returns false if it's exhausted.
+func (r *Retry) withdrawRetryBudget(userID string) bool {
+	if r.retryBudgetRatio(userID) <= 0 {
+		return true
+	}
+
+	r.budgetsMx.Lock()
+	defer r.budgetsMx.Unlock()
+
+	budget, ok := r.budgets[userID]
+	if !ok {
+		budget = retryBudgetMaxTokens
+	}
+	if budget < 1 {
+		return false
+	}
+	r.budgets[userID] = budget - 1
+	return true
+}
+
+func (r *Retry) retryBudgetRatio(userID string) float64 {
+	if r.limits == nil {
+		return 0
+	}
+	tenantIDs, err := tenant.TenantIDsFromOrgID(userID)
+	if err != nil {
+		return 0
+	}
+	return validation.SmallestPositiveNonZeroFloat64PerTenant(tenantIDs, r.limits.QueryRetryBudgetRatio)
+}
+
+type hedgedResult struct {
+	resp    *httpgrpc.HTTPResponse
+	err     error
+	latency time.Duration
+}
+
+// doHedged calls f, and calls it again concurrently if it takes longer than the hedging delay,
+// returning the first successful result.
+func (r *Retry) doHedged(ctx context.Context, f func(context.Context) (*httpgrpc.HTTPResponse, error)) (*httpgrpc.HTTPResponse, error) {
+	if r.latencies == nil {
+		return f(ctx)
+	}
+
+	// Cancel the slowest call, once the first one has returned.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffer of 2 to ensure the slowest call can write its result after we return.
+	results := make(chan hedgedResult, 2)
+	call := func() {
+		start := time.Now()
+		resp, err := f(ctx)
+		results <- hedgedResult{resp: resp, err: err, latency: time.Since(start)}
+	}
+	go call()
+	pending := 1
+
+	delay, ok := r.latencies.percentileLatency()
+	if ok {
+		timer := time.NewTimer(max(delay, r.hedging.MinDelay))
+		defer timer.Stop()
+
+		select {
+		case res := <-results:
+			return r.observeHedgedResult(res)
+		case <-timer.C:
+			r.hedgedRequestsCounter.Inc()
+			go call()
+			pending++
+		}
+	}
+
+	var res hedgedResult
+	for ; pending > 0; pending-- {
+		res = <-results
+		if res.err == nil && res.resp != nil && res.resp.Code/100 != 5 {
+			break
+		}
+	}
+	return r.observeHedgedResult(res)
+}
+
+func (r *Retry) observeHedgedResult(res hedgedResult) (*httpgrpc.HTTPResponse, error) {
+	if res.err == nil {
+		r.latencies.observe(res.latency)
+	}
+	return res.resp, res.err
+}
+
+// latencyTracker tracks the latencies of the recent requests, to compute their percentile.
+type latencyTracker struct {
+	percentile float64
+
+	mtx       sync.Mutex
+	latencies []time.Duration
+	next      int
+	observed  int
+	value     time.Duration
+}
+
+func newLatencyTracker(percentile float64) *latencyTracker {
+	return &latencyTracker{
+		percentile: percentile,
+		latencies:  make([]time.Duration, 0, hedgingLatencyWindow),
+	}
+}
+
+func (t *latencyTracker) observe(latency time.Duration) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if len(t.latencies) < hedgingLatencyWindow {
+		t.latencies = append(t.latencies, latency)
+	} else {
+		t.latencies[t.next] = latency
+	}
+	t.next = (t.next + 1) % hedgingLatencyWindow
+
+	t.observed++
+	if t.observed%hedgingLatencyUpdateInterval == 0 {
+		sorted := append([]time.Duration(nil), t.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		t.value = sorted[int(t.percentile*float64(len(sorted)-1))]
+	}
+}
+
+// percentileLatency returns the percentile of the recent latencies, and false until enough latencies
+// have been observed to compute it.
+func (t *latencyTracker) percentileLatency() (time.Duration, bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	return t.value, t.observed >= hedgingLatencyUpdateInterval
+}
+
 func isBodyRetryable(body string) bool {
 	// If pool exhausted, retry at query frontend might make things worse.
 	// Rely on retries at querier level only.
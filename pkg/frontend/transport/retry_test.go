@@ -3,18 +3,20 @@ package transport
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/thanos-io/thanos/pkg/pool"
 	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
 	"go.uber.org/atomic"
 )
 
 func TestRetry(t *testing.T) {
 	tries := atomic.NewInt64(3)
-	r := NewRetry(3, nil)
+	r := NewRetry(3, HedgingConfig{}, nil, nil)
 	ctx := context.Background()
-	res, err := r.Do(ctx, func() (*httpgrpc.HTTPResponse, error) {
+	res, err := r.Do(ctx, func(context.Context) (*httpgrpc.HTTPResponse, error) {
 		try := tries.Dec()
 		if try > 1 {
 			return &httpgrpc.HTTPResponse{
@@ -33,9 +35,9 @@ func TestRetry(t *testing.T) {
 
 func TestNoRetryOnChunkPoolExhaustion(t *testing.T) {
 	tries := atomic.NewInt64(3)
-	r := NewRetry(3, nil)
+	r := NewRetry(3, HedgingConfig{}, nil, nil)
 	ctx := context.Background()
-	res, err := r.Do(ctx, func() (*httpgrpc.HTTPResponse, error) {
+	res, err := r.Do(ctx, func(context.Context) (*httpgrpc.HTTPResponse, error) {
 		try := tries.Dec()
 		if try > 1 {
 			return &httpgrpc.HTTPResponse{
@@ -52,3 +54,94 @@ func TestNoRetryOnChunkPoolExhaustion(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, int32(500), res.Code)
 }
+
+type retryLimits float64
+
+func (l retryLimits) QueryRetryBudgetRatio(string) float64 {
+	return float64(l)
+}
+
+func TestRetryBudget(t *testing.T) {
+	r := NewRetry(3, HedgingConfig{}, retryLimits(0.5), nil)
+	ctx := user.InjectOrgID(context.Background(), "user-1")
+	calls := atomic.NewInt64(0)
+	failing := func(context.Context) (*httpgrpc.HTTPResponse, error) {
+		calls.Inc()
+		return &httpgrpc.HTTPResponse{Code: 500}, nil
+	}
+
+	// The initial budget allows 10 retries, 2 per request.
+	for i := 0; i < 5; i++ {
+		res, err := r.Do(ctx, failing)
+		require.NoError(t, err)
+		require.Equal(t, int32(500), res.Code)
+	}
+	require.Equal(t, int64(15), calls.Load())
+
+	// The 2 retries left in the budget are spent by the first request, then each request
+	// adds half a retry to it: 3 + 2 + 1 + 2 calls.
+	calls.Store(0)
+	for i := 0; i < 4; i++ {
+		_, err := r.Do(ctx, failing)
+		require.NoError(t, err)
+	}
+	require.Equal(t, int64(8), calls.Load())
+
+	// The budget is per tenant.
+	calls.Store(0)
+	_, err := r.Do(user.InjectOrgID(context.Background(), "user-2"), failing)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), calls.Load())
+
+	// The tenants are not tracked anymore once their budget is full again.
+	require.Len(t, r.budgets, 2)
+	succeeding := func(context.Context) (*httpgrpc.HTTPResponse, error) {
+		return &httpgrpc.HTTPResponse{Code: 200}, nil
+	}
+	for i := 0; i < 2*retryBudgetMaxTokens; i++ {
+		_, err := r.Do(user.InjectOrgID(context.Background(), "user-2"), succeeding)
+		require.NoError(t, err)
+	}
+	require.Len(t, r.budgets, 1)
+	require.Contains(t, r.budgets, "user-1")
+}
+
+func TestRetryHedging(t *testing.T) {
+	r := NewRetry(0, HedgingConfig{Percentile: 0.9, MinDelay: 10 * time.Millisecond}, nil, nil)
+	ctx := context.Background()
+	fast := func(context.Context) (*httpgrpc.HTTPResponse, error) {
+		return &httpgrpc.HTTPResponse{Code: 200}, nil
+	}
+
+	// The requests are not hedged until enough latencies are observed.
+	for i := 0; i < hedgingLatencyUpdateInterval; i++ {
+		_, err := r.Do(ctx, fast)
+		require.NoError(t, err)
+	}
+	delay, ok := r.latencies.percentileLatency()
+	require.True(t, ok)
+	require.Less(t, delay, 10*time.Millisecond)
+
+	// The first call is stuck, until canceled once the hedged one has returned.
+	calls := atomic.NewInt64(0)
+	canceled := make(chan struct{})
+	res, err := r.Do(ctx, func(ctx context.Context) (*httpgrpc.HTTPResponse, error) {
+		if calls.Inc() == 1 {
+			<-ctx.Done()
+			close(canceled)
+			return nil, ctx.Err()
+		}
+		return &httpgrpc.HTTPResponse{Code: 200}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(200), res.Code)
+	require.Equal(t, int64(2), calls.Load())
+	<-canceled
+}
+
+func TestHedgingConfig_Validate(t *testing.T) {
+	require.NoError(t, (&HedgingConfig{}).Validate())
+	require.NoError(t, (&HedgingConfig{Percentile: 0.95}).Validate())
+	require.ErrorIs(t, (&HedgingConfig{Percentile: 1}).Validate(), errInvalidHedgingPercentile)
+	require.ErrorIs(t, (&HedgingConfig{Percentile: -0.5}).Validate(), errInvalidHedgingPercentile)
+}
@@ -95,6 +95,7 @@ type request struct {
 	enqueueTime time.Time
 	queueSpan   opentracing.Span
 	originalCtx context.Context
+	userID      string
 
 	request  *httpgrpc.HTTPRequest
 	err      chan error
@@ -197,7 +198,7 @@ func (f *Frontend) RoundTripGRPC(ctx context.Context, req *httpgrpc.HTTPRequest)
 		}
 	}
 
-	return f.retry.Do(ctx, func() (*httpgrpc.HTTPResponse, error) {
+	return f.retry.Do(ctx, func(ctx context.Context) (*httpgrpc.HTTPResponse, error) {
 		request := request{
 			request:     req,
 			originalCtx: ctx,
@@ -270,6 +271,7 @@ func (f *Frontend) Process(server frontendv1pb.Frontend_ProcessServer) error {
 		  it's possible that it's own queue would perpetually contain only expired requests.
 		*/
 		if req.originalCtx.Err() != nil {
+			f.requestQueue.RequestDone(req.userID)
 			lastUserIndex = lastUserIndex.ReuseLastUser()
 			continue
 		}
@@ -303,16 +305,19 @@ func (f *Frontend) Process(server frontendv1pb.Frontend_ProcessServer) error {
 		// downstream req.  Only way we can do that is to close the stream.
 		// The worker client is expecting this semantics.
 		case <-req.originalCtx.Done():
+			f.requestQueue.RequestDone(req.userID)
 			return req.originalCtx.Err()
 
 		// Is there was an error handling this request due to network IO,
 		// then error out this upstream request _and_ stream.
 		case err := <-errs:
+			f.requestQueue.RequestDone(req.userID)
 			req.err <- err
 			return err
 
 		// Happy path: merge the stats and propagate the response.
 		case resp := <-resps:
+			f.requestQueue.RequestDone(req.userID)
 			if stats.ShouldTrackHTTPGRPCResponse(resp.HttpResponse) {
 				stats := stats.FromContext(req.originalCtx)
 				stats.Merge(resp.Stats) // Safe if stats is nil.
@@ -368,6 +373,7 @@ func (f *Frontend) queueRequest(ctx context.Context, req *request) error {
 
 	joinedTenantID := tenant.JoinTenantIDs(tenantIDs)
 	f.activeUsers.UpdateUserTimestamp(joinedTenantID, now)
+	req.userID = joinedTenantID
 
 	err = f.requestQueue.EnqueueRequest(joinedTenantID, req, maxQueriers, nil)
 	if err == queue.ErrTooManyRequests {
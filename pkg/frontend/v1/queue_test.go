@@ -26,7 +26,7 @@ func setupFrontend(t *testing.T, maxOutstanding int, config Config) (*Frontend,
 	logger := log.NewNopLogger()
 
 	limits := MockLimits{Queriers: 3, MockLimits: queue.MockLimits{MaxOutstanding: maxOutstanding}}
-	frontend, err := New(config, limits, logger, nil, transport.NewRetry(0, nil))
+	frontend, err := New(config, limits, logger, nil, transport.NewRetry(0, transport.HedgingConfig{}, nil, nil))
 	require.NoError(t, err)
 
 	t.Cleanup(func() {
@@ -108,6 +108,15 @@ func TestHATrackerConfig_Validate(t *testing.T) {
 			}(),
 			expectedErr: nil,
 		},
+		"should pass with redis kv store": {
+			cfg: func() HATrackerConfig {
+				cfg := HATrackerConfig{}
+				flagext.DefaultValues(&cfg)
+				cfg.KVStore.Store = "redis"
+				return cfg
+			}(),
+			expectedErr: nil,
+		},
 		"should failed with invalid kv store": {
 			cfg: func() HATrackerConfig {
 				cfg := HATrackerConfig{}
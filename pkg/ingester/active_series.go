@@ -77,6 +77,16 @@ func (c *ActiveSeries) Active() int {
 	return total
 }
 
+// ActiveMatching returns, for each set of matchers, the number of active series matching all of them.
+// Series are expected to have been purged before calling it.
+func (c *ActiveSeries) ActiveMatching(matchers [][]*labels.Matcher) []int {
+	counts := make([]int, len(matchers))
+	for s := 0; s < numActiveSeriesStripes; s++ {
+		c.stripes[s].countMatching(matchers, counts)
+	}
+	return counts
+}
+
 func (s *activeSeriesStripe) updateSeriesTimestamp(now time.Time, series labels.Labels, fingerprint uint64, labelsCopy func(labels.Labels) labels.Labels) {
 	nowNanos := now.UnixNano()
 
@@ -211,6 +221,30 @@ func (s *activeSeriesStripe) purge(keepUntil time.Time) {
 	s.active = active
 }
 
+func (s *activeSeriesStripe) countMatching(matchers [][]*labels.Matcher, counts []int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, entries := range s.refs {
+		for _, entry := range entries {
+			for ix, ms := range matchers {
+				if matchesAll(entry.lbs, ms) {
+					counts[ix]++
+				}
+			}
+		}
+	}
+}
+
+func matchesAll(lbs labels.Labels, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbs.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *activeSeriesStripe) getActive() int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -90,6 +90,27 @@ func TestActiveSeries_PurgeOpt(t *testing.T) {
 	assert.Equal(t, 1, c.Active())
 }
 
+func TestActiveSeries_ActiveMatching(t *testing.T) {
+	c := NewActiveSeries()
+	for _, lbls := range []labels.Labels{
+		labels.FromStrings("__name__", "up", "job", "api", "team", "a"),
+		labels.FromStrings("__name__", "up", "job", "api", "team", "b"),
+		labels.FromStrings("__name__", "up", "job", "db", "team", "b"),
+		labels.FromStrings("__name__", "requests_total", "job", "api"),
+	} {
+		c.UpdateSeries(lbls, lbls.Hash(), time.Now(), copyFn)
+	}
+
+	matchers := [][]*labels.Matcher{
+		{labels.MustNewMatcher(labels.MatchEqual, "job", "api")},
+		{labels.MustNewMatcher(labels.MatchEqual, "__name__", "up"), labels.MustNewMatcher(labels.MatchEqual, "team", "b")},
+		{labels.MustNewMatcher(labels.MatchRegexp, "team", ".+")},
+		{labels.MustNewMatcher(labels.MatchEqual, "job", "unknown")},
+	}
+	assert.Equal(t, []int{3, 2, 3, 0}, c.ActiveMatching(matchers))
+	assert.Empty(t, c.ActiveMatching(nil))
+}
+
 var activeSeriesTestGoroutines = []int{50, 100, 500}
 
 func BenchmarkActiveSeriesTest_single_series(b *testing.B) {
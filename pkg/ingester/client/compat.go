@@ -7,6 +7,7 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/index"
 
 	"github.com/cortexproject/cortex/pkg/cortexpb"
 )
@@ -250,6 +251,19 @@ func FromLabelNamesRequest(req *LabelNamesRequest) (int64, int64, int, []*labels
 	return req.StartTimestampMs, req.EndTimestampMs, int(req.Limit), matchers, nil
 }
 
+// MaxTSDBStatusLimit is the max number of items of each list returned by the ingesters
+// for a TSDBStatusRequest, given the lists are allocated upfront for the limit.
+const MaxTSDBStatusLimit = 10000
+
+// FromIndexStats converts the TSDB postings statistics to their proto representation.
+func FromIndexStats(stats []index.Stat) []*TSDBStatistic {
+	result := make([]*TSDBStatistic, 0, len(stats))
+	for _, s := range stats {
+		result = append(result, &TSDBStatistic{Name: s.Name, Value: s.Count})
+	}
+	return result
+}
+
 func toLabelMatchers(matchers []*labels.Matcher) ([]*LabelMatcher, error) {
 	result := make([]*LabelMatcher, 0, len(matchers))
 	for _, matcher := range matchers {
@@ -1077,6 +1077,14 @@ func (m *MetricsForLabelMatchersStreamResponse) GetMetric() []*cortexpb.Metric {
 }
 
 type MetricsMetadataRequest struct {
+	// Maximum number of metrics to return metadata for. 0 means no limit.
+	Limit int64 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Maximum number of metadata to return per metric. 0 means no limit.
+	LimitPerMetric int64 `protobuf:"varint,2,opt,name=limit_per_metric,json=limitPerMetric,proto3" json:"limit_per_metric,omitempty"`
+	// Only return the metadata of this metric, if not empty.
+	Metric string `protobuf:"bytes,3,opt,name=metric,proto3" json:"metric,omitempty"`
+	// Return the page following the one which returned this token.
+	PaginationToken string `protobuf:"bytes,4,opt,name=pagination_token,json=paginationToken,proto3" json:"pagination_token,omitempty"`
 }
 
 func (m *MetricsMetadataRequest) Reset()      { *m = MetricsMetadataRequest{} }
@@ -1111,8 +1119,38 @@ func (m *MetricsMetadataRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_MetricsMetadataRequest proto.InternalMessageInfo
 
+func (m *MetricsMetadataRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *MetricsMetadataRequest) GetLimitPerMetric() int64 {
+	if m != nil {
+		return m.LimitPerMetric
+	}
+	return 0
+}
+
+func (m *MetricsMetadataRequest) GetMetric() string {
+	if m != nil {
+		return m.Metric
+	}
+	return ""
+}
+
+func (m *MetricsMetadataRequest) GetPaginationToken() string {
+	if m != nil {
+		return m.PaginationToken
+	}
+	return ""
+}
+
 type MetricsMetadataResponse struct {
 	Metadata []*cortexpb.MetricMetadata `protobuf:"bytes,1,rep,name=metadata,proto3" json:"metadata,omitempty"`
+	// Token to request the next page, empty if there are no more metrics.
+	NextPaginationToken string `protobuf:"bytes,2,opt,name=next_pagination_token,json=nextPaginationToken,proto3" json:"next_pagination_token,omitempty"`
 }
 
 func (m *MetricsMetadataResponse) Reset()      { *m = MetricsMetadataResponse{} }
@@ -1154,6 +1192,13 @@ func (m *MetricsMetadataResponse) GetMetadata() []*cortexpb.MetricMetadata {
 	return nil
 }
 
+func (m *MetricsMetadataResponse) GetNextPaginationToken() string {
+	if m != nil {
+		return m.NextPaginationToken
+	}
+	return ""
+}
+
 type TimeSeriesChunk struct {
 	FromIngesterId string                                                      `protobuf:"bytes,1,opt,name=from_ingester_id,json=fromIngesterId,proto3" json:"from_ingester_id,omitempty"`
 	UserId         string                                                      `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
@@ -1450,6 +1495,200 @@ func (m *TimeSeriesFile) GetData() []byte {
 	return nil
 }
 
+type TSDBStatusRequest struct {
+	// Maximum number of items to return in each statistic.
+	Limit int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (m *TSDBStatusRequest) Reset()      { *m = TSDBStatusRequest{} }
+func (*TSDBStatusRequest) ProtoMessage() {}
+func (*TSDBStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{27}
+}
+func (m *TSDBStatusRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TSDBStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TSDBStatusRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TSDBStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TSDBStatusRequest.Merge(m, src)
+}
+func (m *TSDBStatusRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *TSDBStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TSDBStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TSDBStatusRequest proto.InternalMessageInfo
+
+func (m *TSDBStatusRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type TSDBStatusResponse struct {
+	NumSeries                   uint64           `protobuf:"varint,1,opt,name=num_series,json=numSeries,proto3" json:"num_series,omitempty"`
+	MinTime                     int64            `protobuf:"varint,2,opt,name=min_time,json=minTime,proto3" json:"min_time,omitempty"`
+	MaxTime                     int64            `protobuf:"varint,3,opt,name=max_time,json=maxTime,proto3" json:"max_time,omitempty"`
+	NumLabelPairs               int32            `protobuf:"varint,4,opt,name=num_label_pairs,json=numLabelPairs,proto3" json:"num_label_pairs,omitempty"`
+	SeriesCountByMetricName     []*TSDBStatistic `protobuf:"bytes,5,rep,name=series_count_by_metric_name,json=seriesCountByMetricName,proto3" json:"series_count_by_metric_name,omitempty"`
+	LabelValueCountByLabelName  []*TSDBStatistic `protobuf:"bytes,6,rep,name=label_value_count_by_label_name,json=labelValueCountByLabelName,proto3" json:"label_value_count_by_label_name,omitempty"`
+	MemoryInBytesByLabelName    []*TSDBStatistic `protobuf:"bytes,7,rep,name=memory_in_bytes_by_label_name,json=memoryInBytesByLabelName,proto3" json:"memory_in_bytes_by_label_name,omitempty"`
+	SeriesCountByLabelValuePair []*TSDBStatistic `protobuf:"bytes,8,rep,name=series_count_by_label_value_pair,json=seriesCountByLabelValuePair,proto3" json:"series_count_by_label_value_pair,omitempty"`
+}
+
+func (m *TSDBStatusResponse) Reset()      { *m = TSDBStatusResponse{} }
+func (*TSDBStatusResponse) ProtoMessage() {}
+func (*TSDBStatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{28}
+}
+func (m *TSDBStatusResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TSDBStatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TSDBStatusResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TSDBStatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TSDBStatusResponse.Merge(m, src)
+}
+func (m *TSDBStatusResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *TSDBStatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TSDBStatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TSDBStatusResponse proto.InternalMessageInfo
+
+func (m *TSDBStatusResponse) GetNumSeries() uint64 {
+	if m != nil {
+		return m.NumSeries
+	}
+	return 0
+}
+
+func (m *TSDBStatusResponse) GetMinTime() int64 {
+	if m != nil {
+		return m.MinTime
+	}
+	return 0
+}
+
+func (m *TSDBStatusResponse) GetMaxTime() int64 {
+	if m != nil {
+		return m.MaxTime
+	}
+	return 0
+}
+
+func (m *TSDBStatusResponse) GetNumLabelPairs() int32 {
+	if m != nil {
+		return m.NumLabelPairs
+	}
+	return 0
+}
+
+func (m *TSDBStatusResponse) GetSeriesCountByMetricName() []*TSDBStatistic {
+	if m != nil {
+		return m.SeriesCountByMetricName
+	}
+	return nil
+}
+
+func (m *TSDBStatusResponse) GetLabelValueCountByLabelName() []*TSDBStatistic {
+	if m != nil {
+		return m.LabelValueCountByLabelName
+	}
+	return nil
+}
+
+func (m *TSDBStatusResponse) GetMemoryInBytesByLabelName() []*TSDBStatistic {
+	if m != nil {
+		return m.MemoryInBytesByLabelName
+	}
+	return nil
+}
+
+func (m *TSDBStatusResponse) GetSeriesCountByLabelValuePair() []*TSDBStatistic {
+	if m != nil {
+		return m.SeriesCountByLabelValuePair
+	}
+	return nil
+}
+
+type TSDBStatistic struct {
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value uint64 `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *TSDBStatistic) Reset()      { *m = TSDBStatistic{} }
+func (*TSDBStatistic) ProtoMessage() {}
+func (*TSDBStatistic) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{29}
+}
+func (m *TSDBStatistic) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TSDBStatistic) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TSDBStatistic.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TSDBStatistic) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TSDBStatistic.Merge(m, src)
+}
+func (m *TSDBStatistic) XXX_Size() int {
+	return m.Size()
+}
+func (m *TSDBStatistic) XXX_DiscardUnknown() {
+	xxx_messageInfo_TSDBStatistic.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TSDBStatistic proto.InternalMessageInfo
+
+func (m *TSDBStatistic) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *TSDBStatistic) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("cortex.MatchType", MatchType_name, MatchType_value)
 	proto.RegisterType((*ReadRequest)(nil), "cortex.ReadRequest")
@@ -1479,96 +1718,117 @@ func init() {
 	proto.RegisterType((*LabelMatchers)(nil), "cortex.LabelMatchers")
 	proto.RegisterType((*LabelMatcher)(nil), "cortex.LabelMatcher")
 	proto.RegisterType((*TimeSeriesFile)(nil), "cortex.TimeSeriesFile")
+	proto.RegisterType((*TSDBStatusRequest)(nil), "cortex.TSDBStatusRequest")
+	proto.RegisterType((*TSDBStatusResponse)(nil), "cortex.TSDBStatusResponse")
+	proto.RegisterType((*TSDBStatistic)(nil), "cortex.TSDBStatistic")
 }
 
 func init() { proto.RegisterFile("ingester.proto", fileDescriptor_60f6df4f3586b478) }
 
 var fileDescriptor_60f6df4f3586b478 = []byte{
-	// 1339 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x57, 0x4b, 0x6f, 0x14, 0xc7,
-	0x13, 0xdf, 0xf1, 0x3e, 0xec, 0xad, 0x7d, 0xb0, 0x6e, 0x1b, 0xbc, 0x0c, 0x7f, 0xc6, 0x30, 0x88,
-	0x7f, 0xac, 0x24, 0xd8, 0xe0, 0x24, 0x12, 0xe4, 0x85, 0x6c, 0x30, 0x60, 0xc0, 0x18, 0xc6, 0x86,
-	0x44, 0x51, 0xa2, 0xd1, 0x78, 0xb7, 0xb1, 0x27, 0xcc, 0x63, 0x99, 0xee, 0x41, 0x90, 0x53, 0xa2,
-	0x7c, 0x80, 0xe4, 0x98, 0x6b, 0x6e, 0xf9, 0x00, 0xf9, 0x10, 0x1c, 0x39, 0xe4, 0x80, 0x72, 0x40,
-	0x61, 0x91, 0xa2, 0x1c, 0xc9, 0x37, 0x88, 0xa6, 0x1f, 0xf3, 0xf2, 0xf8, 0x41, 0x04, 0xb9, 0xed,
-	0x54, 0xfd, 0xaa, 0xba, 0xea, 0xd7, 0x55, 0x5d, 0xb5, 0xd0, 0xb6, 0xbd, 0x4d, 0x4c, 0x28, 0x0e,
-	0x66, 0x07, 0x81, 0x4f, 0x7d, 0x54, 0xeb, 0xf9, 0x01, 0xc5, 0x0f, 0xd5, 0xc9, 0x4d, 0x7f, 0xd3,
-	0x67, 0xa2, 0xb9, 0xe8, 0x17, 0xd7, 0xaa, 0xe7, 0x36, 0x6d, 0xba, 0x15, 0x6e, 0xcc, 0xf6, 0x7c,
-	0x77, 0x8e, 0x03, 0x07, 0x81, 0xff, 0x35, 0xee, 0x51, 0xf1, 0x35, 0x37, 0xb8, 0xb7, 0x29, 0x15,
-	0x1b, 0xe2, 0x07, 0x37, 0xd5, 0x3f, 0x81, 0x86, 0x81, 0xad, 0xbe, 0x81, 0xef, 0x87, 0x98, 0x50,
-	0x34, 0x0b, 0xa3, 0xf7, 0x43, 0x1c, 0xd8, 0x98, 0x74, 0x95, 0x63, 0xe5, 0x99, 0xc6, 0xfc, 0xe4,
-	0xac, 0x80, 0xdf, 0x0a, 0x71, 0xf0, 0x48, 0xc0, 0x0c, 0x09, 0xd2, 0xcf, 0x43, 0x93, 0x9b, 0x93,
-	0x81, 0xef, 0x11, 0x8c, 0xe6, 0x60, 0x34, 0xc0, 0x24, 0x74, 0xa8, 0xb4, 0x3f, 0x98, 0xb3, 0xe7,
-	0x38, 0x43, 0xa2, 0xf4, 0x6b, 0xd0, 0xca, 0x68, 0xd0, 0x87, 0x00, 0xd4, 0x76, 0x31, 0x29, 0x0a,
-	0x62, 0xb0, 0x31, 0xbb, 0x6e, 0xbb, 0x78, 0x8d, 0xe9, 0x16, 0x2b, 0x8f, 0x9f, 0x4d, 0x97, 0x8c,
-	0x14, 0x5a, 0xff, 0x49, 0x81, 0x66, 0x3a, 0x4e, 0xf4, 0x2e, 0x20, 0x42, 0xad, 0x80, 0x9a, 0x0c,
-	0x44, 0x2d, 0x77, 0x60, 0xba, 0x91, 0x53, 0x65, 0xa6, 0x6c, 0x74, 0x98, 0x66, 0x5d, 0x2a, 0x56,
-	0x08, 0x9a, 0x81, 0x0e, 0xf6, 0xfa, 0x59, 0xec, 0x08, 0xc3, 0xb6, 0xb1, 0xd7, 0x4f, 0x23, 0x4f,
-	0xc3, 0x98, 0x6b, 0xd1, 0xde, 0x16, 0x0e, 0x48, 0xb7, 0x9c, 0xe5, 0xe9, 0xba, 0xb5, 0x81, 0x9d,
-	0x15, 0xae, 0x34, 0x62, 0x94, 0xfe, 0xb3, 0x02, 0x93, 0x4b, 0x0f, 0xb1, 0x3b, 0x70, 0xac, 0xe0,
-	0x3f, 0x09, 0xf1, 0xcc, 0xb6, 0x10, 0x0f, 0x16, 0x85, 0x48, 0x52, 0x31, 0x7e, 0x09, 0x13, 0x2c,
-	0xb4, 0x35, 0x1a, 0x60, 0xcb, 0x8d, 0x6f, 0xe4, 0x3c, 0x34, 0x7a, 0x5b, 0xa1, 0x77, 0x2f, 0x73,
-	0x25, 0x53, 0xd2, 0x59, 0x72, 0x21, 0x17, 0x22, 0x90, 0xb8, 0x95, 0xb4, 0xc5, 0xd5, 0xca, 0xd8,
-	0x48, 0xa7, 0xac, 0xaf, 0xc1, 0xc1, 0x1c, 0x01, 0xaf, 0xe1, 0xc6, 0x7f, 0x53, 0x00, 0xb1, 0x74,
-	0xee, 0x58, 0x4e, 0x88, 0x89, 0x24, 0xf5, 0x28, 0x80, 0x13, 0x49, 0x4d, 0xcf, 0x72, 0x31, 0x23,
-	0xb3, 0x6e, 0xd4, 0x99, 0xe4, 0x86, 0xe5, 0xe2, 0x1d, 0x38, 0x1f, 0x79, 0x05, 0xce, 0xcb, 0x7b,
-	0x72, 0x5e, 0x39, 0xa6, 0xec, 0x83, 0x73, 0x34, 0x09, 0x55, 0xc7, 0x76, 0x6d, 0xda, 0xad, 0x32,
-	0x8f, 0xfc, 0x43, 0x3f, 0x0b, 0x13, 0x99, 0xac, 0x04, 0x53, 0xc7, 0xa1, 0xc9, 0xd3, 0x7a, 0xc0,
-	0xe4, 0x8c, 0xab, 0xba, 0xd1, 0x70, 0x12, 0xa8, 0xfe, 0x29, 0x1c, 0x4e, 0x59, 0xe6, 0x6e, 0x72,
-	0x1f, 0xf6, 0xbf, 0x2a, 0x30, 0x7e, 0x5d, 0x12, 0x45, 0xde, 0x74, 0x91, 0xc6, 0xd9, 0x97, 0x53,
-	0xd9, 0xff, 0x0b, 0x1a, 0xf5, 0x0f, 0x44, 0x19, 0x88, 0xa8, 0x45, 0xbe, 0xd3, 0xd0, 0x48, 0xca,
-	0x40, 0xa6, 0x0b, 0x71, 0x1d, 0x10, 0xfd, 0x23, 0xe8, 0x26, 0x66, 0x39, 0xb2, 0xf6, 0x34, 0x46,
-	0xd0, 0xb9, 0x4d, 0x70, 0xb0, 0x46, 0x2d, 0x2a, 0x89, 0xd2, 0xbf, 0x1b, 0x81, 0xf1, 0x94, 0x50,
-	0xb8, 0x3a, 0x29, 0xdf, 0x73, 0xdb, 0xf7, 0xcc, 0xc0, 0xa2, 0xbc, 0x24, 0x15, 0xa3, 0x15, 0x4b,
-	0x0d, 0x8b, 0xe2, 0xa8, 0x6a, 0xbd, 0xd0, 0x35, 0x45, 0x23, 0x44, 0x8c, 0x55, 0x8c, 0xba, 0x17,
-	0xba, 0xbc, 0xfa, 0xa3, 0x4b, 0xb0, 0x06, 0xb6, 0x99, 0xf3, 0x54, 0x66, 0x9e, 0x3a, 0xd6, 0xc0,
-	0x5e, 0xce, 0x38, 0x9b, 0x85, 0x89, 0x20, 0x74, 0x70, 0x1e, 0x5e, 0x61, 0xf0, 0xf1, 0x48, 0x95,
-	0xc5, 0x9f, 0x80, 0x96, 0xd5, 0xa3, 0xf6, 0x03, 0x2c, 0xcf, 0xaf, 0xb2, 0xf3, 0x9b, 0x5c, 0x28,
-	0x42, 0x38, 0x01, 0x2d, 0xc7, 0xb7, 0xfa, 0xb8, 0x6f, 0x6e, 0x38, 0x7e, 0xef, 0x1e, 0xe9, 0xd6,
-	0x38, 0x88, 0x0b, 0x17, 0x99, 0x4c, 0xff, 0x0a, 0x26, 0x22, 0x0a, 0x96, 0x2f, 0x66, 0x49, 0x98,
-	0x82, 0xd1, 0x90, 0xe0, 0xc0, 0xb4, 0xfb, 0xa2, 0x21, 0x6b, 0xd1, 0xe7, 0x72, 0x1f, 0x9d, 0x82,
-	0x4a, 0xdf, 0xa2, 0x16, 0x4b, 0xb8, 0x31, 0x7f, 0x58, 0x5e, 0xf5, 0x36, 0x1a, 0x0d, 0x06, 0xd3,
-	0x2f, 0x03, 0x8a, 0x54, 0x24, 0xeb, 0xfd, 0x0c, 0x54, 0x49, 0x24, 0x10, 0xef, 0xc7, 0x91, 0xb4,
-	0x97, 0x5c, 0x24, 0x06, 0x47, 0xea, 0x8f, 0x15, 0xd0, 0x56, 0x30, 0x0d, 0xec, 0x1e, 0xb9, 0xe4,
-	0x07, 0xd9, 0xca, 0x7a, 0xc3, 0x75, 0x7f, 0x16, 0x9a, 0xb2, 0x74, 0x4d, 0x82, 0xe9, 0xee, 0x0f,
-	0x74, 0x43, 0x42, 0xd7, 0x30, 0x4d, 0x3a, 0xa6, 0x92, 0x7e, 0x2f, 0xae, 0xc1, 0xf4, 0x8e, 0x99,
-	0x08, 0x82, 0x66, 0xa0, 0xe6, 0x32, 0x88, 0x60, 0xa8, 0x93, 0xbc, 0xb0, 0xdc, 0xd4, 0x10, 0x7a,
-	0xfd, 0x16, 0x9c, 0xdc, 0xc1, 0x59, 0xae, 0x43, 0xf6, 0xef, 0xb2, 0x0b, 0x87, 0x84, 0xcb, 0x15,
-	0x4c, 0xad, 0xe8, 0x1a, 0x65, 0xc3, 0xac, 0xc2, 0xd4, 0x36, 0x8d, 0x70, 0xff, 0x3e, 0x8c, 0xb9,
-	0x42, 0x26, 0x0e, 0xe8, 0xe6, 0x0f, 0x88, 0x6d, 0x62, 0xa4, 0xfe, 0xb7, 0x02, 0x07, 0x72, 0x33,
-	0x29, 0xba, 0x98, 0xbb, 0x81, 0xef, 0x9a, 0x72, 0xa9, 0x4a, 0x6a, 0xb0, 0x1d, 0xc9, 0x97, 0x85,
-	0x78, 0xb9, 0x9f, 0x2e, 0xd2, 0x91, 0x4c, 0x91, 0x7a, 0x50, 0x63, 0xad, 0x2f, 0x87, 0xe9, 0x44,
-	0x12, 0x0a, 0xa3, 0xe8, 0xa6, 0x65, 0x07, 0x8b, 0x0b, 0xd1, 0x7c, 0xfa, 0xfd, 0xd9, 0xf4, 0x2b,
-	0xed, 0x63, 0xdc, 0x7e, 0xa1, 0x6f, 0x0d, 0x28, 0x0e, 0x0c, 0x71, 0x0a, 0x7a, 0x07, 0x6a, 0x7c,
-	0x84, 0x76, 0x2b, 0xec, 0xbc, 0x96, 0xac, 0x8d, 0xf4, 0x94, 0x15, 0x10, 0xfd, 0x07, 0x05, 0xaa,
-	0x3c, 0xd3, 0x37, 0x55, 0xb0, 0x2a, 0x8c, 0x61, 0xaf, 0xe7, 0xf7, 0x6d, 0x6f, 0x93, 0xbd, 0x38,
-	0x55, 0x23, 0xfe, 0x46, 0x48, 0xf4, 0x6f, 0x54, 0x91, 0x4d, 0xd1, 0xa4, 0x0b, 0xd0, 0xca, 0x54,
-	0x4e, 0x66, 0x63, 0x52, 0xf6, 0xb5, 0x31, 0x99, 0xd0, 0x4c, 0x6b, 0xd0, 0x49, 0xa8, 0xd0, 0x47,
-	0x03, 0xfe, 0x74, 0xb6, 0xe7, 0xc7, 0xa5, 0x35, 0x53, 0xaf, 0x3f, 0x1a, 0x60, 0x83, 0xa9, 0xa3,
-	0x68, 0xd8, 0xd0, 0xe7, 0xd7, 0xc7, 0x7e, 0x47, 0x4d, 0xc3, 0x26, 0x1e, 0x0b, 0xbd, 0x6e, 0xf0,
-	0x0f, 0xfd, 0x7b, 0x05, 0xda, 0x49, 0xa5, 0x5c, 0xb2, 0x1d, 0xfc, 0x3a, 0x0a, 0x45, 0x85, 0xb1,
-	0xbb, 0xb6, 0x83, 0x59, 0x0c, 0xfc, 0xb8, 0xf8, 0xbb, 0x88, 0xa9, 0xb7, 0xaf, 0x42, 0x3d, 0x4e,
-	0x01, 0xd5, 0xa1, 0xba, 0x74, 0xeb, 0xf6, 0xc2, 0xf5, 0x4e, 0x09, 0xb5, 0xa0, 0x7e, 0x63, 0x75,
-	0xdd, 0xe4, 0x9f, 0x0a, 0x3a, 0x00, 0x0d, 0x63, 0xe9, 0xf2, 0xd2, 0xe7, 0xe6, 0xca, 0xc2, 0xfa,
-	0x85, 0x2b, 0x9d, 0x11, 0x84, 0xa0, 0xcd, 0x05, 0x37, 0x56, 0x85, 0xac, 0x3c, 0xff, 0xe7, 0x28,
-	0x8c, 0xc9, 0x18, 0xd1, 0x39, 0xa8, 0xdc, 0x0c, 0xc9, 0x16, 0x3a, 0x94, 0x54, 0xea, 0x67, 0x81,
-	0x4d, 0xb1, 0xe8, 0x3c, 0x75, 0x6a, 0x9b, 0x9c, 0xf7, 0x9d, 0x5e, 0x42, 0x17, 0xa1, 0x91, 0x5a,
-	0x04, 0x51, 0xe1, 0x7f, 0x00, 0xf5, 0x48, 0x46, 0x9a, 0x7d, 0x1a, 0xf4, 0xd2, 0x69, 0x05, 0xad,
-	0x42, 0x9b, 0xa9, 0xe4, 0xd6, 0x47, 0xd0, 0xff, 0xa4, 0x49, 0xd1, 0x26, 0xac, 0x1e, 0xdd, 0x41,
-	0x1b, 0x87, 0x75, 0x05, 0x1a, 0xa9, 0xdd, 0x06, 0xa9, 0x99, 0x02, 0xca, 0x2c, 0x80, 0x49, 0x70,
-	0x05, 0x6b, 0x94, 0x5e, 0x42, 0x77, 0xc4, 0x92, 0x93, 0xde, 0x92, 0x76, 0xf5, 0x77, 0xbc, 0x40,
-	0x57, 0x90, 0xf2, 0x12, 0x40, 0xb2, 0x4f, 0xa0, 0xc3, 0x19, 0xa3, 0xf4, 0x42, 0xa5, 0xaa, 0x45,
-	0xaa, 0x38, 0xbc, 0x35, 0xe8, 0xe4, 0xd7, 0x92, 0xdd, 0x9c, 0x1d, 0xdb, 0xae, 0x2a, 0x88, 0x6d,
-	0x11, 0xea, 0xf1, 0x48, 0x45, 0xdd, 0x82, 0x29, 0xcb, 0x9d, 0xed, 0x3c, 0x7f, 0xf5, 0x12, 0xba,
-	0x04, 0xcd, 0x05, 0xc7, 0xd9, 0x8f, 0x1b, 0x35, 0xad, 0x21, 0x79, 0x3f, 0x4e, 0xfc, 0xea, 0xe7,
-	0x47, 0x0c, 0xfa, 0x7f, 0xdc, 0xd8, 0xbb, 0x8e, 0x66, 0xf5, 0xad, 0x3d, 0x71, 0xf1, 0x69, 0xdf,
-	0xc0, 0xd1, 0x5d, 0x07, 0xda, 0xbe, 0xcf, 0x3c, 0xb5, 0x07, 0xae, 0x80, 0xf5, 0x75, 0x38, 0x90,
-	0x9b, 0x6f, 0x48, 0xcb, 0x79, 0xc9, 0x8d, 0x44, 0x75, 0x7a, 0x47, 0xbd, 0xf4, 0xbb, 0xf8, 0xf1,
-	0x93, 0xe7, 0x5a, 0xe9, 0xe9, 0x73, 0xad, 0xf4, 0xf2, 0xb9, 0xa6, 0x7c, 0x3b, 0xd4, 0x94, 0x5f,
-	0x86, 0x9a, 0xf2, 0x78, 0xa8, 0x29, 0x4f, 0x86, 0x9a, 0xf2, 0xc7, 0x50, 0x53, 0xfe, 0x1a, 0x6a,
-	0xa5, 0x97, 0x43, 0x4d, 0xf9, 0xf1, 0x85, 0x56, 0x7a, 0xf2, 0x42, 0x2b, 0x3d, 0x7d, 0xa1, 0x95,
-	0xbe, 0xa8, 0xf5, 0x1c, 0x1b, 0x7b, 0x74, 0xa3, 0xc6, 0xfe, 0xfa, 0xbf, 0xf7, 0x4f, 0x00, 0x00,
-	0x00, 0xff, 0xff, 0x84, 0xf7, 0x8d, 0x61, 0x65, 0x10, 0x00, 0x00,
+	// 1624 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0x4b, 0x73, 0x13, 0xc7,
+	0x16, 0xd6, 0x58, 0x0f, 0x4b, 0x47, 0x92, 0x2d, 0xb7, 0x6d, 0x2c, 0x8f, 0xaf, 0x65, 0x33, 0x14,
+	0x5c, 0x73, 0x6f, 0xb0, 0xc1, 0x49, 0xaa, 0x20, 0x2f, 0xca, 0x02, 0x03, 0x06, 0x0c, 0x66, 0x24,
+	0x48, 0x8a, 0x22, 0x35, 0x35, 0x92, 0x1a, 0x7b, 0xe2, 0x79, 0x88, 0x99, 0x1e, 0xca, 0xce, 0x2a,
+	0xa9, 0xfc, 0x80, 0x64, 0x99, 0xaa, 0xac, 0xb2, 0xcb, 0x26, 0xbb, 0xfc, 0x08, 0x96, 0x2c, 0xb2,
+	0xa0, 0xb2, 0xa0, 0x82, 0xd9, 0x64, 0x49, 0x76, 0x59, 0xa6, 0xa6, 0xbb, 0xe7, 0xe9, 0xb1, 0x6c,
+	0x52, 0x90, 0x9d, 0xfa, 0x3c, 0xbe, 0x3e, 0xe7, 0xeb, 0xd3, 0xa7, 0xcf, 0x08, 0x46, 0x34, 0x73,
+	0x13, 0x3b, 0x04, 0xdb, 0x8b, 0x7d, 0xdb, 0x22, 0x16, 0x2a, 0x74, 0x2d, 0x9b, 0xe0, 0x1d, 0x71,
+	0x62, 0xd3, 0xda, 0xb4, 0xa8, 0x68, 0xc9, 0xfb, 0xc5, 0xb4, 0xe2, 0x85, 0x4d, 0x8d, 0x6c, 0xb9,
+	0x9d, 0xc5, 0xae, 0x65, 0x2c, 0x31, 0xc3, 0xbe, 0x6d, 0x7d, 0x81, 0xbb, 0x84, 0xaf, 0x96, 0xfa,
+	0xdb, 0x9b, 0xbe, 0xa2, 0xc3, 0x7f, 0x30, 0x57, 0xe9, 0x63, 0x28, 0xcb, 0x58, 0xed, 0xc9, 0xf8,
+	0x91, 0x8b, 0x1d, 0x82, 0x16, 0x61, 0xf8, 0x91, 0x8b, 0x6d, 0x0d, 0x3b, 0x75, 0x61, 0x3e, 0xbb,
+	0x50, 0x5e, 0x9e, 0x58, 0xe4, 0xe6, 0x77, 0x5c, 0x6c, 0xef, 0x72, 0x33, 0xd9, 0x37, 0x92, 0x2e,
+	0x42, 0x85, 0xb9, 0x3b, 0x7d, 0xcb, 0x74, 0x30, 0x5a, 0x82, 0x61, 0x1b, 0x3b, 0xae, 0x4e, 0x7c,
+	0xff, 0xc9, 0x84, 0x3f, 0xb3, 0x93, 0x7d, 0x2b, 0xe9, 0x06, 0x54, 0x63, 0x1a, 0xf4, 0x01, 0x00,
+	0xd1, 0x0c, 0xec, 0xa4, 0x05, 0xd1, 0xef, 0x2c, 0xb6, 0x35, 0x03, 0xb7, 0xa8, 0xae, 0x99, 0x7b,
+	0xf2, 0x7c, 0x2e, 0x23, 0x47, 0xac, 0xa5, 0xef, 0x05, 0xa8, 0x44, 0xe3, 0x44, 0xef, 0x00, 0x72,
+	0x88, 0x6a, 0x13, 0x85, 0x1a, 0x11, 0xd5, 0xe8, 0x2b, 0x86, 0x07, 0x2a, 0x2c, 0x64, 0xe5, 0x1a,
+	0xd5, 0xb4, 0x7d, 0xc5, 0xba, 0x83, 0x16, 0xa0, 0x86, 0xcd, 0x5e, 0xdc, 0x76, 0x88, 0xda, 0x8e,
+	0x60, 0xb3, 0x17, 0xb5, 0x3c, 0x0b, 0x45, 0x43, 0x25, 0xdd, 0x2d, 0x6c, 0x3b, 0xf5, 0x6c, 0x9c,
+	0xa7, 0x9b, 0x6a, 0x07, 0xeb, 0xeb, 0x4c, 0x29, 0x07, 0x56, 0xd2, 0x8f, 0x02, 0x4c, 0xac, 0xee,
+	0x60, 0xa3, 0xaf, 0xab, 0xf6, 0xbf, 0x12, 0xe2, 0xb9, 0x7d, 0x21, 0x4e, 0xa6, 0x85, 0xe8, 0x44,
+	0x62, 0x7c, 0x00, 0xe3, 0x34, 0xb4, 0x16, 0xb1, 0xb1, 0x6a, 0x04, 0x27, 0x72, 0x11, 0xca, 0xdd,
+	0x2d, 0xd7, 0xdc, 0x8e, 0x1d, 0xc9, 0x94, 0x0f, 0x16, 0x1e, 0xc8, 0x25, 0xcf, 0x88, 0x9f, 0x4a,
+	0xd4, 0xe3, 0x7a, 0xae, 0x38, 0x54, 0xcb, 0x4a, 0x2d, 0x98, 0x4c, 0x10, 0xf0, 0x06, 0x4e, 0xfc,
+	0x57, 0x01, 0x10, 0x4d, 0xe7, 0x9e, 0xaa, 0xbb, 0xd8, 0xf1, 0x49, 0x9d, 0x05, 0xd0, 0x3d, 0xa9,
+	0x62, 0xaa, 0x06, 0xa6, 0x64, 0x96, 0xe4, 0x12, 0x95, 0xdc, 0x52, 0x0d, 0x7c, 0x00, 0xe7, 0x43,
+	0xaf, 0xc1, 0x79, 0xf6, 0x50, 0xce, 0x73, 0xf3, 0xc2, 0x11, 0x38, 0x47, 0x13, 0x90, 0xd7, 0x35,
+	0x43, 0x23, 0xf5, 0x3c, 0x45, 0x64, 0x0b, 0xe9, 0x3c, 0x8c, 0xc7, 0xb2, 0xe2, 0x4c, 0x1d, 0x87,
+	0x0a, 0x4b, 0xeb, 0x31, 0x95, 0x53, 0xae, 0x4a, 0x72, 0x59, 0x0f, 0x4d, 0xa5, 0x4f, 0x60, 0x3a,
+	0xe2, 0x99, 0x38, 0xc9, 0x23, 0xf8, 0xff, 0x22, 0xc0, 0xd8, 0x4d, 0x9f, 0x28, 0xe7, 0x6d, 0x17,
+	0x69, 0x90, 0x7d, 0x36, 0x92, 0xfd, 0x3f, 0xa0, 0x51, 0x7a, 0x1f, 0x50, 0x34, 0x6a, 0x9e, 0xef,
+	0x1c, 0x94, 0xc3, 0x32, 0xf0, 0xd3, 0x85, 0xa0, 0x0e, 0x1c, 0xe9, 0x43, 0xa8, 0x87, 0x6e, 0x09,
+	0xb2, 0x0e, 0x75, 0x46, 0x50, 0xbb, 0xeb, 0x60, 0xbb, 0x45, 0x54, 0xe2, 0x13, 0x25, 0x7d, 0x3d,
+	0x04, 0x63, 0x11, 0x21, 0x87, 0x3a, 0xe9, 0xf7, 0x73, 0xcd, 0x32, 0x15, 0x5b, 0x25, 0xac, 0x24,
+	0x05, 0xb9, 0x1a, 0x48, 0x65, 0x95, 0x60, 0xaf, 0x6a, 0x4d, 0xd7, 0x50, 0xf8, 0x45, 0xf0, 0x18,
+	0xcb, 0xc9, 0x25, 0xd3, 0x35, 0x58, 0xf5, 0x7b, 0x87, 0xa0, 0xf6, 0x35, 0x25, 0x81, 0x94, 0xa5,
+	0x48, 0x35, 0xb5, 0xaf, 0xad, 0xc5, 0xc0, 0x16, 0x61, 0xdc, 0x76, 0x75, 0x9c, 0x34, 0xcf, 0x51,
+	0xf3, 0x31, 0x4f, 0x15, 0xb7, 0x3f, 0x01, 0x55, 0xb5, 0x4b, 0xb4, 0xc7, 0xd8, 0xdf, 0x3f, 0x4f,
+	0xf7, 0xaf, 0x30, 0x21, 0x0f, 0xe1, 0x04, 0x54, 0x75, 0x4b, 0xed, 0xe1, 0x9e, 0xd2, 0xd1, 0xad,
+	0xee, 0xb6, 0x53, 0x2f, 0x30, 0x23, 0x26, 0x6c, 0x52, 0x99, 0xf4, 0x39, 0x8c, 0x7b, 0x14, 0xac,
+	0x5d, 0x8e, 0x93, 0x30, 0x05, 0xc3, 0xae, 0x83, 0x6d, 0x45, 0xeb, 0xf1, 0x0b, 0x59, 0xf0, 0x96,
+	0x6b, 0x3d, 0x74, 0x06, 0x72, 0x3d, 0x95, 0xa8, 0x34, 0xe1, 0xf2, 0xf2, 0xb4, 0x7f, 0xd4, 0xfb,
+	0x68, 0x94, 0xa9, 0x99, 0x74, 0x15, 0x90, 0xa7, 0x72, 0xe2, 0xe8, 0xe7, 0x20, 0xef, 0x78, 0x02,
+	0xde, 0x3f, 0x66, 0xa2, 0x28, 0x89, 0x48, 0x64, 0x66, 0x29, 0x3d, 0x11, 0xa0, 0xb1, 0x8e, 0x89,
+	0xad, 0x75, 0x9d, 0x2b, 0x96, 0x1d, 0xaf, 0xac, 0xb7, 0x5c, 0xf7, 0xe7, 0xa1, 0xe2, 0x97, 0xae,
+	0xe2, 0x60, 0x32, 0xb8, 0x41, 0x97, 0x7d, 0xd3, 0x16, 0x26, 0xe1, 0x8d, 0xc9, 0x45, 0xfb, 0xc5,
+	0x0d, 0x98, 0x3b, 0x30, 0x13, 0x4e, 0xd0, 0x02, 0x14, 0x0c, 0x6a, 0xc2, 0x19, 0xaa, 0x85, 0x1d,
+	0x96, 0xb9, 0xca, 0x5c, 0x2f, 0xdd, 0x81, 0x93, 0x07, 0x80, 0x25, 0x6e, 0xc8, 0xd1, 0x21, 0x7f,
+	0x10, 0xe0, 0x18, 0xc7, 0x5c, 0xc7, 0x44, 0xf5, 0xce, 0xd1, 0xa7, 0x38, 0x48, 0x48, 0x88, 0xb6,
+	0x80, 0x05, 0xa8, 0xd1, 0x1f, 0x4a, 0x1f, 0xdb, 0x0a, 0xdf, 0x84, 0x53, 0x49, 0xe5, 0x1b, 0xd8,
+	0x66, 0x78, 0xe8, 0x58, 0x10, 0x44, 0x96, 0x55, 0x15, 0x5b, 0xa1, 0xd3, 0x50, 0xeb, 0xab, 0x9b,
+	0x9a, 0xa9, 0xd2, 0xda, 0x27, 0xd6, 0x36, 0x36, 0x29, 0x67, 0x25, 0x79, 0x34, 0x94, 0xb7, 0x3d,
+	0xb1, 0xf4, 0x8d, 0x00, 0x53, 0xfb, 0xa2, 0xe3, 0x39, 0xbe, 0x07, 0x45, 0x83, 0xcb, 0x78, 0x96,
+	0xf5, 0x64, 0x96, 0x81, 0x4f, 0x60, 0x89, 0x96, 0x61, 0xd2, 0xc4, 0x3b, 0x44, 0xd9, 0x17, 0xc1,
+	0x10, 0x8d, 0x60, 0xdc, 0x53, 0x6e, 0x24, 0xa2, 0xf8, 0x53, 0x80, 0xd1, 0xc4, 0x63, 0xea, 0xd1,
+	0xf0, 0xd0, 0xb6, 0x0c, 0xc5, 0x9f, 0x06, 0xc3, 0xcb, 0x33, 0xe2, 0xc9, 0xd7, 0xb8, 0x78, 0xad,
+	0x17, 0xbd, 0x5d, 0x43, 0xb1, 0xdb, 0x65, 0x42, 0x81, 0xf6, 0x2c, 0x7f, 0x0a, 0x18, 0x0f, 0xc3,
+	0xa7, 0x67, 0xbb, 0xa1, 0x6a, 0x76, 0x73, 0xc5, 0x7b, 0x58, 0x7f, 0x7b, 0x3e, 0xf7, 0x5a, 0x83,
+	0x24, 0xf3, 0x5f, 0xe9, 0xa9, 0x7d, 0x82, 0x6d, 0x99, 0xef, 0x82, 0xfe, 0x0f, 0x05, 0xf6, 0xf6,
+	0xd7, 0x73, 0x74, 0xbf, 0xaa, 0x5f, 0xd4, 0xd1, 0xf1, 0x80, 0x9b, 0x48, 0xdf, 0x0a, 0x90, 0x67,
+	0x99, 0xbe, 0xad, 0x9b, 0x26, 0x42, 0x11, 0x9b, 0x5d, 0xab, 0xa7, 0x99, 0x9b, 0xb4, 0x40, 0xf2,
+	0x72, 0xb0, 0x46, 0x88, 0x37, 0x1e, 0xaf, 0x2c, 0x2a, 0xbc, 0xbb, 0xac, 0x40, 0x35, 0x56, 0xf2,
+	0xb1, 0x51, 0x4f, 0x38, 0xd2, 0xa8, 0xa7, 0x40, 0x25, 0xaa, 0x41, 0x27, 0x21, 0x47, 0x76, 0xfb,
+	0xac, 0xe7, 0x8f, 0x2c, 0x8f, 0xf9, 0xde, 0x54, 0xdd, 0xde, 0xed, 0x63, 0x99, 0xaa, 0xbd, 0x68,
+	0xe8, 0xb4, 0xc2, 0x8e, 0x8f, 0xfe, 0xf6, 0x2e, 0x07, 0x7d, 0xaa, 0x79, 0x6d, 0xb3, 0x85, 0x57,
+	0xaf, 0x23, 0x61, 0xa5, 0x5c, 0xd1, 0x74, 0xfc, 0x26, 0x0a, 0x45, 0x84, 0xe2, 0x43, 0x4d, 0xc7,
+	0x34, 0x06, 0xb6, 0x5d, 0xb0, 0x4e, 0x65, 0xea, 0x34, 0x8c, 0xb5, 0x5b, 0x97, 0x9b, 0x5e, 0x6b,
+	0x75, 0x9d, 0xd4, 0xdb, 0x9c, 0xf7, 0xdb, 0xd3, 0x5f, 0x59, 0x40, 0x51, 0x5b, 0x7e, 0xb7, 0xe2,
+	0xef, 0x9d, 0x90, 0x7c, 0xef, 0xa6, 0xa1, 0x68, 0x68, 0x26, 0x3d, 0x64, 0x7e, 0xb8, 0xc3, 0x86,
+	0x66, 0x7a, 0x89, 0x53, 0x95, 0xba, 0xc3, 0x54, 0x59, 0xae, 0x52, 0x77, 0xa8, 0xea, 0x14, 0x8c,
+	0x7a, 0xa0, 0xec, 0xe9, 0xee, 0xab, 0x1a, 0x9f, 0x21, 0xf2, 0x72, 0xd5, 0x74, 0x8d, 0xa0, 0xe2,
+	0x1d, 0xd4, 0x82, 0x19, 0xb6, 0xb1, 0xd2, 0xb5, 0x5c, 0x93, 0x28, 0x9d, 0x5d, 0xde, 0x67, 0xd8,
+	0xcc, 0x98, 0x8f, 0x77, 0x64, 0x3f, 0x7a, 0xcd, 0x21, 0x5a, 0x57, 0x9e, 0x62, 0x9e, 0x97, 0x3c,
+	0xc7, 0xe6, 0x2e, 0xeb, 0x02, 0x74, 0xb0, 0xbc, 0x0f, 0x73, 0x91, 0x01, 0x2b, 0x44, 0x8e, 0x0c,
+	0xa3, 0x85, 0x41, 0xc0, 0x62, 0x38, 0x8a, 0x71, 0xf0, 0x60, 0x3c, 0x41, 0xf7, 0x60, 0xd6, 0xc0,
+	0x86, 0x65, 0xef, 0x2a, 0x9a, 0xa9, 0x74, 0x76, 0x09, 0x76, 0x12, 0xc8, 0xc3, 0x83, 0x90, 0xeb,
+	0xcc, 0x77, 0xcd, 0x6c, 0x7a, 0x9e, 0x51, 0xdc, 0x07, 0x30, 0x9f, 0x24, 0x22, 0x9a, 0x83, 0x47,
+	0x61, 0xbd, 0x38, 0x08, 0x7a, 0x26, 0xc6, 0x46, 0x38, 0x7d, 0x7a, 0x3c, 0x4b, 0x17, 0xa0, 0x1a,
+	0xb3, 0x0e, 0xca, 0x5c, 0x48, 0x2b, 0x73, 0x36, 0xf3, 0xb0, 0xc5, 0xff, 0xae, 0x43, 0x29, 0xb8,
+	0x23, 0xa8, 0x04, 0xf9, 0xd5, 0x3b, 0x77, 0x57, 0x6e, 0xd6, 0x32, 0xa8, 0x0a, 0xa5, 0x5b, 0xb7,
+	0xdb, 0x0a, 0x5b, 0x0a, 0x68, 0x14, 0xca, 0xf2, 0xea, 0xd5, 0xd5, 0xcf, 0x94, 0xf5, 0x95, 0xf6,
+	0xa5, 0x6b, 0xb5, 0x21, 0x84, 0x60, 0x84, 0x09, 0x6e, 0xdd, 0xe6, 0xb2, 0xec, 0xf2, 0xcf, 0x45,
+	0x28, 0xfa, 0x97, 0x00, 0x5d, 0x80, 0xdc, 0x86, 0xeb, 0x6c, 0xa1, 0x63, 0x61, 0x2b, 0xfc, 0xd4,
+	0xd6, 0x08, 0xe6, 0x45, 0x2c, 0x4e, 0xed, 0x93, 0xb3, 0x82, 0x95, 0x32, 0xe8, 0x32, 0x94, 0x23,
+	0x9f, 0x48, 0x28, 0xf5, 0xeb, 0x58, 0x9c, 0x89, 0x49, 0xe3, 0x8f, 0xa6, 0x94, 0x39, 0x2b, 0xa0,
+	0xdb, 0x30, 0x42, 0x55, 0xfe, 0xf7, 0x90, 0x83, 0xfe, 0xe3, 0xbb, 0xa4, 0x7d, 0x23, 0x8a, 0xb3,
+	0x07, 0x68, 0x83, 0xb0, 0xae, 0x41, 0x39, 0xe4, 0xdd, 0x41, 0x62, 0xac, 0x43, 0xc5, 0x3e, 0x8d,
+	0xc4, 0x99, 0x54, 0x5d, 0x80, 0x74, 0x0f, 0xc6, 0x22, 0x0a, 0x9e, 0xe6, 0x20, 0xbc, 0xe3, 0x29,
+	0xba, 0x94, 0x94, 0x57, 0x01, 0xc2, 0x49, 0x1b, 0x4d, 0xc7, 0x9c, 0xa2, 0x9f, 0x1a, 0xa2, 0x98,
+	0xa6, 0x0a, 0xc2, 0x6b, 0x41, 0x2d, 0x39, 0xb0, 0x0f, 0x02, 0x9b, 0xdf, 0xaf, 0x4a, 0x89, 0xad,
+	0x09, 0xa5, 0x60, 0xd8, 0x44, 0xf5, 0x94, 0xf9, 0x93, 0x81, 0x1d, 0x3c, 0x99, 0x4a, 0x19, 0x74,
+	0x05, 0x2a, 0x2b, 0xba, 0x7e, 0x14, 0x18, 0x31, 0xaa, 0x71, 0x92, 0x38, 0x3a, 0x4c, 0x1d, 0x30,
+	0x7c, 0xa1, 0x53, 0xc1, 0xcb, 0x31, 0x70, 0x68, 0x15, 0xff, 0x7b, 0xa8, 0x5d, 0xb0, 0xdb, 0x97,
+	0x30, 0x3b, 0x70, 0xd4, 0x3b, 0xf2, 0x9e, 0x67, 0x0e, 0xb1, 0x4b, 0x61, 0xbd, 0x0d, 0xa3, 0x89,
+	0xa1, 0x0b, 0x35, 0x12, 0x28, 0x89, 0x59, 0x51, 0x9c, 0x3b, 0x50, 0x1f, 0x64, 0xb4, 0x0a, 0x10,
+	0xbe, 0x34, 0x61, 0x69, 0xec, 0x7b, 0xa9, 0x44, 0x31, 0x4d, 0xe5, 0xc3, 0x34, 0x3f, 0x7a, 0xfa,
+	0xa2, 0x91, 0x79, 0xf6, 0xa2, 0x91, 0x79, 0xf5, 0xa2, 0x21, 0x7c, 0xb5, 0xd7, 0x10, 0x7e, 0xda,
+	0x6b, 0x08, 0x4f, 0xf6, 0x1a, 0xc2, 0xd3, 0xbd, 0x86, 0xf0, 0xfb, 0x5e, 0x43, 0xf8, 0x63, 0xaf,
+	0x91, 0x79, 0xb5, 0xd7, 0x10, 0xbe, 0x7b, 0xd9, 0xc8, 0x3c, 0x7d, 0xd9, 0xc8, 0x3c, 0x7b, 0xd9,
+	0xc8, 0xdc, 0x2f, 0x74, 0x75, 0x0d, 0x9b, 0xa4, 0x53, 0xa0, 0xff, 0xad, 0xbd, 0xfb, 0xf7, 0x00,
+	0x99, 0x01, 0xe1, 0x5a, 0xc6, 0x13, 0x00, 0x00,
 }
 
 func (x MatchType) String() string {
@@ -2209,6 +2469,18 @@ func (this *MetricsMetadataRequest) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
+	if this.Limit != that1.Limit {
+		return false
+	}
+	if this.LimitPerMetric != that1.LimitPerMetric {
+		return false
+	}
+	if this.Metric != that1.Metric {
+		return false
+	}
+	if this.PaginationToken != that1.PaginationToken {
+		return false
+	}
 	return true
 }
 func (this *MetricsMetadataResponse) Equal(that interface{}) bool {
@@ -2238,6 +2510,9 @@ func (this *MetricsMetadataResponse) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if this.NextPaginationToken != that1.NextPaginationToken {
+		return false
+	}
 	return true
 }
 func (this *TimeSeriesChunk) Equal(that interface{}) bool {
@@ -2408,6 +2683,122 @@ func (this *TimeSeriesFile) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *TSDBStatusRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*TSDBStatusRequest)
+	if !ok {
+		that2, ok := that.(TSDBStatusRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Limit != that1.Limit {
+		return false
+	}
+	return true
+}
+func (this *TSDBStatusResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*TSDBStatusResponse)
+	if !ok {
+		that2, ok := that.(TSDBStatusResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.NumSeries != that1.NumSeries {
+		return false
+	}
+	if this.MinTime != that1.MinTime {
+		return false
+	}
+	if this.MaxTime != that1.MaxTime {
+		return false
+	}
+	if this.NumLabelPairs != that1.NumLabelPairs {
+		return false
+	}
+	if len(this.SeriesCountByMetricName) != len(that1.SeriesCountByMetricName) {
+		return false
+	}
+	for i := range this.SeriesCountByMetricName {
+		if !this.SeriesCountByMetricName[i].Equal(that1.SeriesCountByMetricName[i]) {
+			return false
+		}
+	}
+	if len(this.LabelValueCountByLabelName) != len(that1.LabelValueCountByLabelName) {
+		return false
+	}
+	for i := range this.LabelValueCountByLabelName {
+		if !this.LabelValueCountByLabelName[i].Equal(that1.LabelValueCountByLabelName[i]) {
+			return false
+		}
+	}
+	if len(this.MemoryInBytesByLabelName) != len(that1.MemoryInBytesByLabelName) {
+		return false
+	}
+	for i := range this.MemoryInBytesByLabelName {
+		if !this.MemoryInBytesByLabelName[i].Equal(that1.MemoryInBytesByLabelName[i]) {
+			return false
+		}
+	}
+	if len(this.SeriesCountByLabelValuePair) != len(that1.SeriesCountByLabelValuePair) {
+		return false
+	}
+	for i := range this.SeriesCountByLabelValuePair {
+		if !this.SeriesCountByLabelValuePair[i].Equal(that1.SeriesCountByLabelValuePair[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *TSDBStatistic) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*TSDBStatistic)
+	if !ok {
+		that2, ok := that.(TSDBStatistic)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Name != that1.Name {
+		return false
+	}
+	if this.Value != that1.Value {
+		return false
+	}
+	return true
+}
 func (this *ReadRequest) GoString() string {
 	if this == nil {
 		return "nil"
@@ -2671,8 +3062,12 @@ func (this *MetricsMetadataRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 4)
+	s := make([]string, 0, 8)
 	s = append(s, "&client.MetricsMetadataRequest{")
+	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "LimitPerMetric: "+fmt.Sprintf("%#v", this.LimitPerMetric)+",\n")
+	s = append(s, "Metric: "+fmt.Sprintf("%#v", this.Metric)+",\n")
+	s = append(s, "PaginationToken: "+fmt.Sprintf("%#v", this.PaginationToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -2680,11 +3075,12 @@ func (this *MetricsMetadataResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 5)
+	s := make([]string, 0, 6)
 	s = append(s, "&client.MetricsMetadataResponse{")
 	if this.Metadata != nil {
 		s = append(s, "Metadata: "+fmt.Sprintf("%#v", this.Metadata)+",\n")
 	}
+	s = append(s, "NextPaginationToken: "+fmt.Sprintf("%#v", this.NextPaginationToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -2757,6 +3153,52 @@ func (this *TimeSeriesFile) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *TSDBStatusRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&client.TSDBStatusRequest{")
+	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *TSDBStatusResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 12)
+	s = append(s, "&client.TSDBStatusResponse{")
+	s = append(s, "NumSeries: "+fmt.Sprintf("%#v", this.NumSeries)+",\n")
+	s = append(s, "MinTime: "+fmt.Sprintf("%#v", this.MinTime)+",\n")
+	s = append(s, "MaxTime: "+fmt.Sprintf("%#v", this.MaxTime)+",\n")
+	s = append(s, "NumLabelPairs: "+fmt.Sprintf("%#v", this.NumLabelPairs)+",\n")
+	if this.SeriesCountByMetricName != nil {
+		s = append(s, "SeriesCountByMetricName: "+fmt.Sprintf("%#v", this.SeriesCountByMetricName)+",\n")
+	}
+	if this.LabelValueCountByLabelName != nil {
+		s = append(s, "LabelValueCountByLabelName: "+fmt.Sprintf("%#v", this.LabelValueCountByLabelName)+",\n")
+	}
+	if this.MemoryInBytesByLabelName != nil {
+		s = append(s, "MemoryInBytesByLabelName: "+fmt.Sprintf("%#v", this.MemoryInBytesByLabelName)+",\n")
+	}
+	if this.SeriesCountByLabelValuePair != nil {
+		s = append(s, "SeriesCountByLabelValuePair: "+fmt.Sprintf("%#v", this.SeriesCountByLabelValuePair)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *TSDBStatistic) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&client.TSDBStatistic{")
+	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
+	s = append(s, "Value: "+fmt.Sprintf("%#v", this.Value)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringIngester(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -2790,6 +3232,7 @@ type IngesterClient interface {
 	MetricsForLabelMatchers(ctx context.Context, in *MetricsForLabelMatchersRequest, opts ...grpc.CallOption) (*MetricsForLabelMatchersResponse, error)
 	MetricsForLabelMatchersStream(ctx context.Context, in *MetricsForLabelMatchersRequest, opts ...grpc.CallOption) (Ingester_MetricsForLabelMatchersStreamClient, error)
 	MetricsMetadata(ctx context.Context, in *MetricsMetadataRequest, opts ...grpc.CallOption) (*MetricsMetadataResponse, error)
+	TSDBStatus(ctx context.Context, in *TSDBStatusRequest, opts ...grpc.CallOption) (*TSDBStatusResponse, error)
 }
 
 type ingesterClient struct {
@@ -3000,6 +3443,15 @@ func (c *ingesterClient) MetricsMetadata(ctx context.Context, in *MetricsMetadat
 	return out, nil
 }
 
+func (c *ingesterClient) TSDBStatus(ctx context.Context, in *TSDBStatusRequest, opts ...grpc.CallOption) (*TSDBStatusResponse, error) {
+	out := new(TSDBStatusResponse)
+	err := c.cc.Invoke(ctx, "/cortex.Ingester/TSDBStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // IngesterServer is the server API for Ingester service.
 type IngesterServer interface {
 	Push(context.Context, *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error)
@@ -3014,6 +3466,7 @@ type IngesterServer interface {
 	MetricsForLabelMatchers(context.Context, *MetricsForLabelMatchersRequest) (*MetricsForLabelMatchersResponse, error)
 	MetricsForLabelMatchersStream(*MetricsForLabelMatchersRequest, Ingester_MetricsForLabelMatchersStreamServer) error
 	MetricsMetadata(context.Context, *MetricsMetadataRequest) (*MetricsMetadataResponse, error)
+	TSDBStatus(context.Context, *TSDBStatusRequest) (*TSDBStatusResponse, error)
 }
 
 // UnimplementedIngesterServer can be embedded to have forward compatible implementations.
@@ -3056,6 +3509,9 @@ func (*UnimplementedIngesterServer) MetricsForLabelMatchersStream(req *MetricsFo
 func (*UnimplementedIngesterServer) MetricsMetadata(ctx context.Context, req *MetricsMetadataRequest) (*MetricsMetadataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method MetricsMetadata not implemented")
 }
+func (*UnimplementedIngesterServer) TSDBStatus(ctx context.Context, req *TSDBStatusRequest) (*TSDBStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TSDBStatus not implemented")
+}
 
 func RegisterIngesterServer(s *grpc.Server, srv IngesterServer) {
 	s.RegisterService(&_Ingester_serviceDesc, srv)
@@ -3289,6 +3745,24 @@ func _Ingester_MetricsMetadata_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Ingester_TSDBStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TSDBStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngesterServer).TSDBStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cortex.Ingester/TSDBStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngesterServer).TSDBStatus(ctx, req.(*TSDBStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Ingester_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "cortex.Ingester",
 	HandlerType: (*IngesterServer)(nil),
@@ -3325,6 +3799,10 @@ var _Ingester_serviceDesc = grpc.ServiceDesc{
 			MethodName: "MetricsMetadata",
 			Handler:    _Ingester_MetricsMetadata_Handler,
 		},
+		{
+			MethodName: "TSDBStatus",
+			Handler:    _Ingester_TSDBStatus_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -4169,6 +4647,30 @@ func (m *MetricsMetadataRequest) MarshalToSizedBuffer(dAtA []byte) (int, error)
 	_ = i
 	var l int
 	_ = l
+	if len(m.PaginationToken) > 0 {
+		i -= len(m.PaginationToken)
+		copy(dAtA[i:], m.PaginationToken)
+		i = encodeVarintIngester(dAtA, i, uint64(len(m.PaginationToken)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Metric) > 0 {
+		i -= len(m.Metric)
+		copy(dAtA[i:], m.Metric)
+		i = encodeVarintIngester(dAtA, i, uint64(len(m.Metric)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.LimitPerMetric != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.LimitPerMetric))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Limit != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x8
+	}
 	return len(dAtA) - i, nil
 }
 
@@ -4192,6 +4694,13 @@ func (m *MetricsMetadataResponse) MarshalToSizedBuffer(dAtA []byte) (int, error)
 	_ = i
 	var l int
 	_ = l
+	if len(m.NextPaginationToken) > 0 {
+		i -= len(m.NextPaginationToken)
+		copy(dAtA[i:], m.NextPaginationToken)
+		i = encodeVarintIngester(dAtA, i, uint64(len(m.NextPaginationToken)))
+		i--
+		dAtA[i] = 0x12
+	}
 	if len(m.Metadata) > 0 {
 		for iNdEx := len(m.Metadata) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -4449,6 +4958,168 @@ func (m *TimeSeriesFile) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *TSDBStatusRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TSDBStatusRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TSDBStatusRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Limit != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TSDBStatusResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TSDBStatusResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TSDBStatusResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.SeriesCountByLabelValuePair) > 0 {
+		for iNdEx := len(m.SeriesCountByLabelValuePair) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.SeriesCountByLabelValuePair[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x42
+		}
+	}
+	if len(m.MemoryInBytesByLabelName) > 0 {
+		for iNdEx := len(m.MemoryInBytesByLabelName) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.MemoryInBytesByLabelName[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x3a
+		}
+	}
+	if len(m.LabelValueCountByLabelName) > 0 {
+		for iNdEx := len(m.LabelValueCountByLabelName) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.LabelValueCountByLabelName[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x32
+		}
+	}
+	if len(m.SeriesCountByMetricName) > 0 {
+		for iNdEx := len(m.SeriesCountByMetricName) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.SeriesCountByMetricName[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if m.NumLabelPairs != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.NumLabelPairs))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.MaxTime != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.MaxTime))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.MinTime != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.MinTime))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.NumSeries != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.NumSeries))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TSDBStatistic) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TSDBStatistic) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TSDBStatistic) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Value != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.Value))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintIngester(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintIngester(dAtA []byte, offset int, v uint64) int {
 	offset -= sovIngester(v)
 	base := offset
@@ -4813,6 +5484,20 @@ func (m *MetricsMetadataRequest) Size() (n int) {
 	}
 	var l int
 	_ = l
+	if m.Limit != 0 {
+		n += 1 + sovIngester(uint64(m.Limit))
+	}
+	if m.LimitPerMetric != 0 {
+		n += 1 + sovIngester(uint64(m.LimitPerMetric))
+	}
+	l = len(m.Metric)
+	if l > 0 {
+		n += 1 + l + sovIngester(uint64(l))
+	}
+	l = len(m.PaginationToken)
+	if l > 0 {
+		n += 1 + l + sovIngester(uint64(l))
+	}
 	return n
 }
 
@@ -4828,6 +5513,10 @@ func (m *MetricsMetadataResponse) Size() (n int) {
 			n += 1 + l + sovIngester(uint64(l))
 		}
 	}
+	l = len(m.NextPaginationToken)
+	if l > 0 {
+		n += 1 + l + sovIngester(uint64(l))
+	}
 	return n
 }
 
@@ -4942,42 +5631,115 @@ func (m *TimeSeriesFile) Size() (n int) {
 	return n
 }
 
-func sovIngester(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
-}
-func sozIngester(x uint64) (n int) {
-	return sovIngester(uint64((x << 1) ^ uint64((int64(x) >> 63))))
-}
-func (this *ReadRequest) String() string {
-	if this == nil {
-		return "nil"
+func (m *TSDBStatusRequest) Size() (n int) {
+	if m == nil {
+		return 0
 	}
-	repeatedStringForQueries := "[]*QueryRequest{"
-	for _, f := range this.Queries {
-		repeatedStringForQueries += strings.Replace(f.String(), "QueryRequest", "QueryRequest", 1) + ","
+	var l int
+	_ = l
+	if m.Limit != 0 {
+		n += 1 + sovIngester(uint64(m.Limit))
 	}
-	repeatedStringForQueries += "}"
-	s := strings.Join([]string{`&ReadRequest{`,
-		`Queries:` + repeatedStringForQueries + `,`,
-		`}`,
-	}, "")
-	return s
+	return n
 }
-func (this *ReadResponse) String() string {
-	if this == nil {
-		return "nil"
+
+func (m *TSDBStatusResponse) Size() (n int) {
+	if m == nil {
+		return 0
 	}
-	repeatedStringForResults := "[]*QueryResponse{"
-	for _, f := range this.Results {
-		repeatedStringForResults += strings.Replace(f.String(), "QueryResponse", "QueryResponse", 1) + ","
+	var l int
+	_ = l
+	if m.NumSeries != 0 {
+		n += 1 + sovIngester(uint64(m.NumSeries))
 	}
-	repeatedStringForResults += "}"
-	s := strings.Join([]string{`&ReadResponse{`,
-		`Results:` + repeatedStringForResults + `,`,
-		`}`,
-	}, "")
-	return s
-}
+	if m.MinTime != 0 {
+		n += 1 + sovIngester(uint64(m.MinTime))
+	}
+	if m.MaxTime != 0 {
+		n += 1 + sovIngester(uint64(m.MaxTime))
+	}
+	if m.NumLabelPairs != 0 {
+		n += 1 + sovIngester(uint64(m.NumLabelPairs))
+	}
+	if len(m.SeriesCountByMetricName) > 0 {
+		for _, e := range m.SeriesCountByMetricName {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	if len(m.LabelValueCountByLabelName) > 0 {
+		for _, e := range m.LabelValueCountByLabelName {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	if len(m.MemoryInBytesByLabelName) > 0 {
+		for _, e := range m.MemoryInBytesByLabelName {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	if len(m.SeriesCountByLabelValuePair) > 0 {
+		for _, e := range m.SeriesCountByLabelValuePair {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *TSDBStatistic) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovIngester(uint64(l))
+	}
+	if m.Value != 0 {
+		n += 1 + sovIngester(uint64(m.Value))
+	}
+	return n
+}
+
+func sovIngester(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozIngester(x uint64) (n int) {
+	return sovIngester(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *ReadRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForQueries := "[]*QueryRequest{"
+	for _, f := range this.Queries {
+		repeatedStringForQueries += strings.Replace(f.String(), "QueryRequest", "QueryRequest", 1) + ","
+	}
+	repeatedStringForQueries += "}"
+	s := strings.Join([]string{`&ReadRequest{`,
+		`Queries:` + repeatedStringForQueries + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ReadResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForResults := "[]*QueryResponse{"
+	for _, f := range this.Results {
+		repeatedStringForResults += strings.Replace(f.String(), "QueryResponse", "QueryResponse", 1) + ","
+	}
+	repeatedStringForResults += "}"
+	s := strings.Join([]string{`&ReadResponse{`,
+		`Results:` + repeatedStringForResults + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *QueryResponse) String() string {
 	if this == nil {
 		return "nil"
@@ -5227,6 +5989,10 @@ func (this *MetricsMetadataRequest) String() string {
 		return "nil"
 	}
 	s := strings.Join([]string{`&MetricsMetadataRequest{`,
+		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`LimitPerMetric:` + fmt.Sprintf("%v", this.LimitPerMetric) + `,`,
+		`Metric:` + fmt.Sprintf("%v", this.Metric) + `,`,
+		`PaginationToken:` + fmt.Sprintf("%v", this.PaginationToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -5242,6 +6008,7 @@ func (this *MetricsMetadataResponse) String() string {
 	repeatedStringForMetadata += "}"
 	s := strings.Join([]string{`&MetricsMetadataResponse{`,
 		`Metadata:` + repeatedStringForMetadata + `,`,
+		`NextPaginationToken:` + fmt.Sprintf("%v", this.NextPaginationToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -5317,6 +6084,64 @@ func (this *TimeSeriesFile) String() string {
 	}, "")
 	return s
 }
+func (this *TSDBStatusRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TSDBStatusRequest{`,
+		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TSDBStatusResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForSeriesCountByMetricName := "[]*TSDBStatistic{"
+	for _, f := range this.SeriesCountByMetricName {
+		repeatedStringForSeriesCountByMetricName += strings.Replace(f.String(), "TSDBStatistic", "TSDBStatistic", 1) + ","
+	}
+	repeatedStringForSeriesCountByMetricName += "}"
+	repeatedStringForLabelValueCountByLabelName := "[]*TSDBStatistic{"
+	for _, f := range this.LabelValueCountByLabelName {
+		repeatedStringForLabelValueCountByLabelName += strings.Replace(f.String(), "TSDBStatistic", "TSDBStatistic", 1) + ","
+	}
+	repeatedStringForLabelValueCountByLabelName += "}"
+	repeatedStringForMemoryInBytesByLabelName := "[]*TSDBStatistic{"
+	for _, f := range this.MemoryInBytesByLabelName {
+		repeatedStringForMemoryInBytesByLabelName += strings.Replace(f.String(), "TSDBStatistic", "TSDBStatistic", 1) + ","
+	}
+	repeatedStringForMemoryInBytesByLabelName += "}"
+	repeatedStringForSeriesCountByLabelValuePair := "[]*TSDBStatistic{"
+	for _, f := range this.SeriesCountByLabelValuePair {
+		repeatedStringForSeriesCountByLabelValuePair += strings.Replace(f.String(), "TSDBStatistic", "TSDBStatistic", 1) + ","
+	}
+	repeatedStringForSeriesCountByLabelValuePair += "}"
+	s := strings.Join([]string{`&TSDBStatusResponse{`,
+		`NumSeries:` + fmt.Sprintf("%v", this.NumSeries) + `,`,
+		`MinTime:` + fmt.Sprintf("%v", this.MinTime) + `,`,
+		`MaxTime:` + fmt.Sprintf("%v", this.MaxTime) + `,`,
+		`NumLabelPairs:` + fmt.Sprintf("%v", this.NumLabelPairs) + `,`,
+		`SeriesCountByMetricName:` + repeatedStringForSeriesCountByMetricName + `,`,
+		`LabelValueCountByLabelName:` + repeatedStringForLabelValueCountByLabelName + `,`,
+		`MemoryInBytesByLabelName:` + repeatedStringForMemoryInBytesByLabelName + `,`,
+		`SeriesCountByLabelValuePair:` + repeatedStringForSeriesCountByLabelValuePair + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TSDBStatistic) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TSDBStatistic{`,
+		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`Value:` + fmt.Sprintf("%v", this.Value) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringIngester(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -7425,6 +8250,108 @@ func (m *MetricsMetadataRequest) Unmarshal(dAtA []byte) error {
 			return fmt.Errorf("proto: MetricsMetadataRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LimitPerMetric", wireType)
+			}
+			m.LimitPerMetric = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LimitPerMetric |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metric", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Metric = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PaginationToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PaginationToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipIngester(dAtA[iNdEx:])
@@ -7512,6 +8439,38 @@ func (m *MetricsMetadataResponse) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NextPaginationToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NextPaginationToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipIngester(dAtA[iNdEx:])
@@ -8247,6 +9206,438 @@ func (m *TimeSeriesFile) Unmarshal(dAtA []byte) error {
 				m.Data = []byte{}
 			}
 			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TSDBStatusRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TSDBStatusRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TSDBStatusRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TSDBStatusResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TSDBStatusResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TSDBStatusResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NumSeries", wireType)
+			}
+			m.NumSeries = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NumSeries |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinTime", wireType)
+			}
+			m.MinTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MinTime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxTime", wireType)
+			}
+			m.MaxTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxTime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NumLabelPairs", wireType)
+			}
+			m.NumLabelPairs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NumLabelPairs |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SeriesCountByMetricName", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SeriesCountByMetricName = append(m.SeriesCountByMetricName, &TSDBStatistic{})
+			if err := m.SeriesCountByMetricName[len(m.SeriesCountByMetricName)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LabelValueCountByLabelName", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.LabelValueCountByLabelName = append(m.LabelValueCountByLabelName, &TSDBStatistic{})
+			if err := m.LabelValueCountByLabelName[len(m.LabelValueCountByLabelName)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MemoryInBytesByLabelName", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MemoryInBytesByLabelName = append(m.MemoryInBytesByLabelName, &TSDBStatistic{})
+			if err := m.MemoryInBytesByLabelName[len(m.MemoryInBytesByLabelName)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SeriesCountByLabelValuePair", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SeriesCountByLabelValuePair = append(m.SeriesCountByLabelValuePair, &TSDBStatistic{})
+			if err := m.SeriesCountByLabelValuePair[len(m.SeriesCountByLabelValuePair)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TSDBStatistic) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TSDBStatistic: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TSDBStatistic: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			m.Value = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Value |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipIngester(dAtA[iNdEx:])
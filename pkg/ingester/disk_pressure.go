@@ -0,0 +1,146 @@
+package ingester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gogo/status"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc/codes"
+
+	"github.com/cortexproject/cortex/pkg/ring"
+)
+
+// errDiskPressure is returned to push requests while the disk pressure circuit breaker is open.
+var errDiskPressure = status.Error(codes.Unavailable, "ingester is rejecting push requests because of disk pressure")
+
+// diskPressureBreaker rejects push requests when the free space of the ingester data directory
+// goes below a threshold or writing to the WAL failed, to avoid crashing with a corrupted WAL.
+type diskPressureBreaker struct {
+	dir          string
+	minFreeRatio float64
+	logger       log.Logger
+
+	// Returns the ratio of free space of the filesystem holding the directory. Replaced in tests.
+	freeRatioFn func(dir string) (float64, error)
+
+	open      atomic.Bool
+	walFailed atomic.Bool
+
+	// Whether the breaker switched the ingester to READONLY, so that it switches it back
+	// to ACTIVE once the pressure is gone. Only accessed by check().
+	setReadOnly bool
+
+	freeRatio prometheus.Gauge
+	pressure  prometheus.Gauge
+}
+
+func newDiskPressureBreaker(dir string, minFreePercent float64, logger log.Logger, registerer prometheus.Registerer) *diskPressureBreaker {
+	return &diskPressureBreaker{
+		dir:          dir,
+		minFreeRatio: minFreePercent / 100,
+		logger:       logger,
+		freeRatioFn:  diskFreeRatio,
+		freeRatio: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_ingester_data_dir_free_space_ratio",
+			Help: "Ratio of free space of the filesystem holding the ingester TSDB directory.",
+		}),
+		pressure: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_ingester_disk_pressure",
+			Help: "1 if the ingester is rejecting push requests because of disk pressure, 0 otherwise.",
+		}),
+	}
+}
+
+// isOpen returns whether push requests should be rejected.
+func (b *diskPressureBreaker) isOpen() bool {
+	return b.open.Load()
+}
+
+// recordCommitError opens the breaker if the error comes from writing to the WAL.
+func (b *diskPressureBreaker) recordCommitError(err error) {
+	if !isWALWriteError(err) {
+		return
+	}
+
+	b.walFailed.Store(true)
+	if b.open.CompareAndSwap(false, true) {
+		b.pressure.Set(1)
+		level.Warn(b.logger).Log("msg", "rejecting push requests because writing to the WAL failed", "err", err)
+	}
+}
+
+// check updates the state of the breaker based on the free disk space, and reflects it in the ring by
+// switching the ingester between ACTIVE and READONLY.
+func (b *diskPressureBreaker) check(ctx context.Context, lifecycler *ring.Lifecycler) {
+	ratio, err := b.freeRatioFn(b.dir)
+	if err != nil {
+		level.Warn(b.logger).Log("msg", "failed to check free disk space", "dir", b.dir, "err", err)
+		return
+	}
+	b.freeRatio.Set(ratio)
+
+	// A WAL failure since the previous check keeps the breaker open for at least a full interval.
+	if ratio < b.minFreeRatio || b.walFailed.Swap(false) {
+		if b.open.CompareAndSwap(false, true) {
+			level.Warn(b.logger).Log("msg", "rejecting push requests because of disk pressure", "free_ratio", ratio, "min_free_ratio", b.minFreeRatio)
+		}
+	} else if b.open.CompareAndSwap(true, false) {
+		level.Info(b.logger).Log("msg", "disk pressure is gone, accepting push requests again", "free_ratio", ratio)
+	}
+
+	if b.open.Load() {
+		b.pressure.Set(1)
+	} else {
+		b.pressure.Set(0)
+	}
+
+	if lifecycler == nil {
+		return
+	}
+
+	state := lifecycler.GetState()
+	switch {
+	case b.open.Load() && state == ring.ACTIVE:
+		if err := lifecycler.ChangeState(ctx, ring.READONLY); err != nil {
+			level.Warn(b.logger).Log("msg", "failed to switch the ingester to READONLY because of disk pressure", "err", err)
+			return
+		}
+		b.setReadOnly = true
+	case !b.open.Load() && b.setReadOnly:
+		if state == ring.READONLY {
+			if err := lifecycler.ChangeState(ctx, ring.ACTIVE); err != nil {
+				level.Warn(b.logger).Log("msg", "failed to switch the ingester back to ACTIVE after disk pressure", "err", err)
+				return
+			}
+		}
+		b.setReadOnly = false
+	}
+}
+
+// isWALWriteError returns whether the error returned by committing a TSDB appender comes from writing to the WAL.
+func isWALWriteError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, syscall.ENOSPC) || strings.Contains(err.Error(), "write to WAL")
+}
+
+// diskFreeRatio returns the ratio of space available to unprivileged users on the filesystem holding dir.
+func diskFreeRatio(dir string) (float64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+	if stat.Blocks == 0 {
+		return 0, fmt.Errorf("filesystem holding %s reports no blocks", dir)
+	}
+	return float64(stat.Bavail) / float64(stat.Blocks), nil
+}
@@ -0,0 +1,105 @@
+package ingester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/gogo/status"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc/codes"
+
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/test"
+)
+
+func TestIsWALWriteError(t *testing.T) {
+	assert.False(t, isWALWriteError(nil))
+	assert.False(t, isWALWriteError(errors.New("out of bounds")))
+	assert.True(t, isWALWriteError(fmt.Errorf("write to WAL: %w", errors.New("log samples: write /data/wal/00000001: input/output error"))))
+	assert.True(t, isWALWriteError(fmt.Errorf("log series: %w", syscall.ENOSPC)))
+}
+
+func TestDiskPressureBreaker_RecordCommitError(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	b := newDiskPressureBreaker(t.TempDir(), 10, log.NewNopLogger(), reg)
+	b.freeRatioFn = func(string) (float64, error) { return 0.5, nil }
+
+	b.recordCommitError(errors.New("out of bounds"))
+	assert.False(t, b.isOpen())
+
+	b.recordCommitError(fmt.Errorf("write to WAL: %w", syscall.ENOSPC))
+	assert.True(t, b.isOpen())
+
+	// The breaker stays open until the check after the WAL failure.
+	b.check(context.Background(), nil)
+	assert.True(t, b.isOpen())
+	b.check(context.Background(), nil)
+	assert.False(t, b.isOpen())
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_ingester_disk_pressure 1 if the ingester is rejecting push requests because of disk pressure, 0 otherwise.
+		# TYPE cortex_ingester_disk_pressure gauge
+		cortex_ingester_disk_pressure 0
+		# HELP cortex_ingester_data_dir_free_space_ratio Ratio of free space of the filesystem holding the ingester TSDB directory.
+		# TYPE cortex_ingester_data_dir_free_space_ratio gauge
+		cortex_ingester_data_dir_free_space_ratio 0.5
+	`), "cortex_ingester_disk_pressure", "cortex_ingester_data_dir_free_space_ratio"))
+}
+
+func TestIngester_DiskPressure(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.LifecyclerConfig.MinReadyDuration = 0
+	cfg.DiskPressureMinFreeSpacePercent = 10
+	cfg.DiskPressureCheckInterval = 10 * time.Millisecond
+
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	freeRatio := atomic.NewFloat64(0.5)
+	i.diskPressure.freeRatioFn = func(string) (float64, error) { return freeRatio.Load(), nil }
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	test.Poll(t, 1*time.Second, ring.ACTIVE, func() interface{} {
+		return i.lifecycler.GetState()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	push := func() error {
+		req, _ := mockWriteRequest(t, labels.Labels{{Name: labels.MetricName, Value: "test"}}, 0, util.TimeToMillis(time.Now()))
+		_, err := i.Push(ctx, req)
+		return err
+	}
+	require.NoError(t, push())
+
+	// Going below the threshold rejects pushes and switches the ingester to READONLY.
+	freeRatio.Store(0.05)
+	test.Poll(t, 1*time.Second, ring.READONLY, func() interface{} {
+		return i.lifecycler.GetState()
+	})
+	err = push()
+	require.Equal(t, errDiskPressure, err)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	// Recovering switches the ingester back to ACTIVE.
+	freeRatio.Store(0.5)
+	test.Poll(t, 1*time.Second, ring.ACTIVE, func() interface{} {
+		return i.lifecycler.GetState()
+	})
+	require.NoError(t, push())
+}
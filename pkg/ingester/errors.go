@@ -1,6 +1,7 @@
 package ingester
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -41,7 +42,12 @@ func (e *validationError) Error() string {
 	return fmt.Sprintf("%s for series %s", e.err.Error(), e.labels.String())
 }
 
-// wrapWithUser prepends the user to the error. It does not retain a reference to err.
+// wrapWithUser prepends the user to the error. It does not retain a reference to err,
+// but preserves the gRPC status of instance limit errors.
 func wrapWithUser(err error, userID string) error {
+	var limitErr *instanceLimitError
+	if errors.As(err, &limitErr) {
+		return newInstanceLimitError(fmt.Sprintf("user=%s: %s", userID, err))
+	}
 	return fmt.Errorf("user=%s: %s", userID, err)
 }
@@ -111,3 +111,24 @@ func AllUserStatsRender(w http.ResponseWriter, r *http.Request, stats []UserIDSt
 		ReplicationFactor: rf,
 	}, UserStatsTmpl, r)
 }
+
+// TenantUsage models the in-memory and on-disk usage of the TSDB of one tenant.
+type TenantUsage struct {
+	UserID          string  `json:"userID"`
+	MemorySeries    uint64  `json:"memorySeries"`
+	HeadChunksBytes int64   `json:"headChunksBytes"`
+	WALBytes        int64   `json:"walBytes"`
+	IngestionRate   float64 `json:"ingestionRate"`
+	PushesInFlight  int64   `json:"pushesInFlight"`
+	LastPush        string  `json:"lastPush,omitempty"`
+}
+
+// TenantsUsageRender returns the usage of all tenants in json format, sorted by number of in-memory series.
+func TenantsUsageRender(w http.ResponseWriter, usages []TenantUsage) {
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].MemorySeries > usages[j].MemorySeries ||
+			(usages[i].MemorySeries == usages[j].MemorySeries && usages[i].UserID < usages[j].UserID)
+	})
+
+	util.WriteJSONResponse(w, usages)
+}
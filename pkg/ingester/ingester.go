@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"html"
 	"io"
+	"io/fs"
 	"math"
 	"net/http"
 	"os"
@@ -28,10 +29,12 @@ import (
 	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/prometheus/prometheus/tsdb/chunkenc"
 	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/fileutil"
 	"github.com/prometheus/prometheus/tsdb/wlog"
 	"github.com/thanos-io/objstore"
 	"github.com/thanos-io/thanos/pkg/block/metadata"
@@ -52,6 +55,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
 	"github.com/cortexproject/cortex/pkg/tenant"
 	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/backoff"
 	"github.com/cortexproject/cortex/pkg/util/concurrency"
 	"github.com/cortexproject/cortex/pkg/util/extract"
 	logutil "github.com/cortexproject/cortex/pkg/util/log"
@@ -93,8 +97,12 @@ const (
 )
 
 var (
-	errExemplarRef      = errors.New("exemplars not ingested because series not already present")
-	errIngesterStopping = errors.New("ingester stopping")
+	errExemplarRef        = errors.New("exemplars not ingested because series not already present")
+	errIngesterStopping   = errors.New("ingester stopping")
+	errIngesterNotRunning = errors.New("ingester not running")
+
+	errInvalidDiskPressureMinFreeSpacePercent = errors.New("invalid disk pressure min free space percent, must be between 0 and 100")
+	errInvalidDiskPressureCheckInterval       = errors.New("invalid disk pressure check interval, must be greater than 0")
 )
 
 // Config for an Ingester.
@@ -142,6 +150,13 @@ type Config struct {
 	// When disabled, the result may contain samples outside the queried time range but Select() performances
 	// may be improved.
 	DisableChunkTrimming bool `yaml:"disable_chunk_trimming"`
+
+	// Disk pressure circuit breaker.
+	DiskPressureMinFreeSpacePercent float64       `yaml:"disk_pressure_min_free_space_percent"`
+	DiskPressureCheckInterval       time.Duration `yaml:"disk_pressure_check_interval"`
+
+	// Samples close to the oldest timestamp accepted by the TSDB head.
+	NearOutOfBoundsPeriod time.Duration `yaml:"near_out_of_bounds_period"`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet
@@ -170,6 +185,11 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.LabelsStringInterningEnabled, "ingester.labels-string-interning-enabled", false, "Experimental: Enable string interning for metrics labels.")
 
 	f.BoolVar(&cfg.DisableChunkTrimming, "ingester.disable-chunk-trimming", false, "Disable trimming of matching series chunks based on query Start and End time. When disabled, the result may contain samples outside the queried time range but select performances may be improved. Note that certain query results might change by changing this option.")
+
+	f.Float64Var(&cfg.DiskPressureMinFreeSpacePercent, "ingester.disk-pressure-min-free-space-percent", 0, "Experimental: Minimum percentage of free space on the filesystem holding the TSDB directory. Below this threshold, or after failing to write to the WAL, the ingester rejects push requests and switches to READONLY in the ring until the free space recovers. 0 to disable.")
+	f.DurationVar(&cfg.DiskPressureCheckInterval, "ingester.disk-pressure-check-interval", 10*time.Second, "How often to check the free space of the filesystem holding the TSDB directory when the disk pressure circuit breaker is enabled.")
+
+	f.DurationVar(&cfg.NearOutOfBoundsPeriod, "ingester.near-out-of-bounds-period", 10*time.Minute, "Samples ingested with a timestamp within this period from the oldest timestamp accepted by the TSDB head of the tenant are counted in cortex_ingester_near_out_of_bounds_samples_total, to monitor how close tenants run to being rejected as out of bounds. 0 to disable.")
 }
 
 func (cfg *Config) Validate() error {
@@ -181,6 +201,17 @@ func (cfg *Config) Validate() error {
 		logutil.WarnExperimentalUse("String interning for metrics labels Enabled")
 	}
 
+	if cfg.DiskPressureMinFreeSpacePercent < 0 || cfg.DiskPressureMinFreeSpacePercent >= 100 {
+		return errInvalidDiskPressureMinFreeSpacePercent
+	}
+
+	if cfg.DiskPressureMinFreeSpacePercent > 0 {
+		if cfg.DiskPressureCheckInterval <= 0 {
+			return errInvalidDiskPressureCheckInterval
+		}
+		logutil.WarnExperimentalUse("Ingester disk pressure circuit breaker")
+	}
+
 	return nil
 }
 
@@ -238,6 +269,12 @@ type Ingester struct {
 
 	inflightQueryRequests    atomic.Int64
 	maxInflightQueryRequests util_math.MaxTracker
+
+	// Rejects pushes on disk pressure. Nil when disabled.
+	diskPressure *diskPressureBreaker
+
+	// Error of the last flush triggered by the lifecycler on shutdown, if some blocks were not shipped.
+	lifecyclerFlushErr atomic.Error
 }
 
 // Shipper interface is used to have an easy way to mock it in tests.
@@ -287,13 +324,24 @@ type userTSDB struct {
 	instanceSeriesCount *atomic.Int64 // Shared across all userTSDB instances created by ingester.
 	instanceLimitsFn    func() *InstanceLimits
 
-	stateMtx       sync.RWMutex
-	state          tsdbState
-	pushesInFlight sync.WaitGroup // Increased with stateMtx read lock held, only if state == active or activeShipping.
+	stateMtx            sync.RWMutex
+	state               tsdbState
+	pushesInFlight      sync.WaitGroup // Increased with stateMtx read lock held, only if state == active or activeShipping.
+	pushesInFlightCount atomic.Int64   // Number of pushes tracked by pushesInFlight, for statistics.
 
 	// Used to detect idle TSDBs.
 	lastUpdate atomic.Int64
 
+	// Max time of the head after the WAL replay on startup, and until when the pushed samples not newer
+	// than it are ignored. Set before the TSDB is used.
+	walReplayMaxTime          int64
+	walReplayDuplicatesUntil  time.Time
+	walReplayDuplicatesLogged atomic.Bool
+
+	// Names of the active series custom trackers exported for this user, only accessed
+	// when updating the active series metrics.
+	activeSeriesCustomTrackers map[string]struct{}
+
 	// Thanos shipper used to ship blocks to the storage.
 	shipper                 Shipper
 	shipperMetadataFilePath string
@@ -305,6 +353,9 @@ type userTSDB struct {
 	// Unix timestamp of last deletion mark check.
 	lastDeletionMarkCheck atomic.Int64
 
+	// Unix timestamp of the last shipper synchronisation completed without errors.
+	lastSuccessfulShip atomic.Int64
+
 	// for statistics
 	ingestedAPISamples  *util_math.EwmaRate
 	ingestedRuleSamples *util_math.EwmaRate
@@ -319,6 +370,12 @@ type userTSDB struct {
 
 	blockRetentionPeriod int64
 
+	// Duration of the blocks cut from the head, in milliseconds.
+	blockDuration int64
+
+	// Delay, in milliseconds, of the regular head compaction once the head becomes compactable.
+	headCompactionDelay int64
+
 	postingCache cortex_tsdb.ExpandedPostingsCache
 }
 
@@ -563,6 +620,32 @@ func (u *userTSDB) getOldestUnshippedBlockTime() uint64 {
 	return oldestTs
 }
 
+// getUnshippedBlocksCount returns the number of TSDB blocks not shipped to the storage yet.
+func (u *userTSDB) getUnshippedBlocksCount() int {
+	shippedBlocks := u.getCachedShippedBlocks()
+	count := 0
+
+	for _, b := range u.Blocks() {
+		if _, ok := shippedBlocks[b.Meta().ULID]; !ok {
+			count++
+		}
+	}
+
+	return count
+}
+
+// headCompactionDelayElapsed returns whether the head has been compactable for longer than
+// the tenant head compaction delay.
+func (u *userTSDB) headCompactionDelayElapsed() bool {
+	if u.headCompactionDelay <= 0 {
+		return true
+	}
+
+	// The TSDB head becomes compactable once it spans 1.5 times the block duration.
+	h := u.Head()
+	return h.MaxTime()-h.MinTime() > u.blockDuration/2*3+u.headCompactionDelay
+}
+
 func (u *userTSDB) isIdle(now time.Time, idle time.Duration) bool {
 	lu := u.lastUpdate.Load()
 
@@ -621,6 +704,7 @@ type TSDBState struct {
 	compactionsTriggered   prometheus.Counter
 	compactionsFailed      prometheus.Counter
 	walReplayTime          prometheus.Histogram
+	walSkipped             prometheus.Counter
 	appenderAddDuration    prometheus.Histogram
 	appenderCommitDuration prometheus.Histogram
 	idleTsdbChecks         *prometheus.CounterVec
@@ -669,6 +753,10 @@ func newTSDBState(bucketClient objstore.Bucket, registerer prometheus.Registerer
 			Help:    "The total time it takes to open and replay a TSDB WAL.",
 			Buckets: prometheus.DefBuckets,
 		}),
+		walSkipped: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_ingester_tsdb_wal_skipped_total",
+			Help: "Total number of TSDB WALs skipped while opening a TSDB because they could not be replayed.",
+		}),
 		appenderAddDuration: promauto.With(registerer).NewHistogram(prometheus.HistogramOpts{
 			Name:    "cortex_ingester_tsdb_appender_add_duration_seconds",
 			Help:    "The total time it takes for a push request to add samples to the TSDB appender.",
@@ -750,6 +838,10 @@ func New(cfg Config, limits *validation.Overrides, registerer prometheus.Registe
 
 	i.TSDBState.shipperIngesterID = i.lifecycler.ID
 
+	if cfg.DiskPressureMinFreeSpacePercent > 0 {
+		i.diskPressure = newDiskPressureBreaker(cfg.BlocksStorageConfig.TSDB.Dir, cfg.DiskPressureMinFreeSpacePercent, logger, registerer)
+	}
+
 	// Apply positive jitter only to ensure that the minimum timeout is adhered to.
 	i.TSDBState.compactionIdleTimeout = util.DurationWithPositiveJitter(i.cfg.BlocksStorageConfig.TSDB.HeadCompactionIdleTimeout, compactionIdleTimeoutJitter)
 	level.Info(i.logger).Log("msg", "TSDB idle compaction timeout set", "timeout", i.TSDBState.compactionIdleTimeout)
@@ -920,6 +1012,15 @@ func (i *Ingester) updateLoop(ctx context.Context) error {
 	maxInflightRequestResetTicker := time.NewTicker(maxInflightRequestResetPeriod)
 	defer maxInflightRequestResetTicker.Stop()
 
+	var diskPressureTickerChan <-chan time.Time
+	if i.diskPressure != nil {
+		i.diskPressure.check(ctx, i.lifecycler)
+
+		t := time.NewTicker(i.cfg.DiskPressureCheckInterval)
+		diskPressureTickerChan = t.C
+		defer t.Stop()
+	}
+
 	for {
 		select {
 		case <-metadataPurgeTicker.C:
@@ -933,6 +1034,7 @@ func (i *Ingester) updateLoop(ctx context.Context) error {
 				db.ingestedRuleSamples.Tick()
 			}
 			i.stoppedMtx.RUnlock()
+			i.updateOldestAcceptedTimestamps()
 
 		case <-activeSeriesTickerChan:
 			i.updateActiveSeries(ctx)
@@ -940,6 +1042,8 @@ func (i *Ingester) updateLoop(ctx context.Context) error {
 			i.maxInflightQueryRequests.Tick()
 		case <-userTSDBConfigTicker.C:
 			i.updateUserTSDBConfigs()
+		case <-diskPressureTickerChan:
+			i.diskPressure.check(ctx, i.lifecycler)
 		case <-ctx.Done():
 			return nil
 		case err := <-i.subservicesWatcher.Chan():
@@ -987,6 +1091,41 @@ func (i *Ingester) getMaxExemplars(userID string) int64 {
 	return int64(maxExemplarsFromLimits)
 }
 
+// updateActiveSeriesCustomTrackers exports the number of active series matching each custom tracker
+// configured for the user, and removes the metrics of the trackers no longer configured.
+func (i *Ingester) updateActiveSeriesCustomTrackers(userID string, userDB *userTSDB) {
+	trackers := i.limits.ActiveSeriesCustomTrackers(userID)
+	if len(trackers) == 0 && len(userDB.activeSeriesCustomTrackers) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(trackers))
+	matchers := make([][]*labels.Matcher, 0, len(trackers))
+	for name, selector := range trackers {
+		m, err := parser.ParseMetricSelector(selector)
+		if err != nil {
+			level.Warn(i.logger).Log("msg", "invalid active series custom tracker", "user", userID, "name", name, "err", err)
+			continue
+		}
+		names = append(names, name)
+		matchers = append(matchers, m)
+	}
+
+	counts := userDB.activeSeries.ActiveMatching(matchers)
+	exported := make(map[string]struct{}, len(names))
+	for ix, name := range names {
+		i.metrics.activeSeriesCustomTrackers.WithLabelValues(userID, name).Set(float64(counts[ix]))
+		exported[name] = struct{}{}
+	}
+
+	for name := range userDB.activeSeriesCustomTrackers {
+		if _, ok := exported[name]; !ok {
+			i.metrics.activeSeriesCustomTrackers.DeleteLabelValues(userID, name)
+		}
+	}
+	userDB.activeSeriesCustomTrackers = exported
+}
+
 func (i *Ingester) updateActiveSeries(ctx context.Context) {
 	purgeTime := time.Now().Add(-i.cfg.ActiveSeriesMetricsIdleTimeout)
 
@@ -998,12 +1137,41 @@ func (i *Ingester) updateActiveSeries(ctx context.Context) {
 
 		userDB.activeSeries.Purge(purgeTime)
 		i.metrics.activeSeriesPerUser.WithLabelValues(userID).Set(float64(userDB.activeSeries.Active()))
+		i.updateActiveSeriesCustomTrackers(userID, userDB)
 		if err := userDB.labelSetCounter.UpdateMetric(ctx, userDB, i.metrics); err != nil {
 			level.Warn(i.logger).Log("msg", "failed to update per labelSet metrics", "user", userID, "err", err)
 		}
 	}
 }
 
+// updateOldestAcceptedTimestamps exports the oldest timestamp accepted by the TSDB head of each tenant.
+func (i *Ingester) updateOldestAcceptedTimestamps() {
+	for _, userID := range i.getTSDBUsers() {
+		userDB := i.getTSDB(userID)
+		if userDB == nil {
+			continue
+		}
+
+		if ts, ok := userDB.oldestAcceptedTimestamp(time.Duration(i.limits.OutOfOrderTimeWindow(userID)).Milliseconds()); ok {
+			i.metrics.oldestAcceptedTimestamp.WithLabelValues(userID).Set(float64(ts) / 1000)
+		}
+	}
+}
+
+// nearOutOfBoundsBefore returns the timestamp, in milliseconds, before which the samples ingested for the
+// tenant are close to be rejected as out of bounds. Returns math.MinInt64 if the tracking is disabled.
+func (i *Ingester) nearOutOfBoundsBefore(userID string, db *userTSDB) int64 {
+	if i.cfg.NearOutOfBoundsPeriod <= 0 {
+		return math.MinInt64
+	}
+
+	ts, ok := db.oldestAcceptedTimestamp(time.Duration(i.limits.OutOfOrderTimeWindow(userID)).Milliseconds())
+	if !ok {
+		return math.MinInt64
+	}
+	return ts + i.cfg.NearOutOfBoundsPeriod.Milliseconds()
+}
+
 func (i *Ingester) RenewTokenHandler(w http.ResponseWriter, r *http.Request) {
 	i.lifecycler.RenewTokens(0.1, r.Context())
 	w.WriteHeader(http.StatusNoContent)
@@ -1012,6 +1180,8 @@ func (i *Ingester) RenewTokenHandler(w http.ResponseWriter, r *http.Request) {
 // ShutdownHandler triggers the following set of operations in order:
 //   - Change the state of ring to stop accepting writes.
 //   - Flush all the chunks.
+//
+// It replies with an error if some blocks could not be shipped to the storage.
 func (i *Ingester) ShutdownHandler(w http.ResponseWriter, _ *http.Request) {
 	originalFlush := i.lifecycler.FlushOnShutdown()
 	// We want to flush the chunks if transfer fails irrespective of original flag.
@@ -1026,6 +1196,11 @@ func (i *Ingester) ShutdownHandler(w http.ResponseWriter, _ *http.Request) {
 	i.lifecycler.SetFlushOnShutdown(originalFlush)
 	i.lifecycler.SetUnregisterOnShutdown(originalUnregister)
 
+	if err := i.lifecyclerFlushErr.Load(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -1062,6 +1237,10 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 		return nil, err
 	}
 
+	if i.diskPressure != nil && i.diskPressure.isOpen() {
+		return nil, errDiskPressure
+	}
+
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Ingester.Push")
 	defer span.Finish()
 
@@ -1124,6 +1303,7 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 		succeededExemplarsCount     = 0
 		failedExemplarsCount        = 0
 		startAppend                 = time.Now()
+		walReplayedSamplesCount     = 0
 		sampleOutOfBoundsCount      = 0
 		sampleOutOfOrderCount       = 0
 		sampleTooOldCount           = 0
@@ -1132,6 +1312,8 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 		perLabelSetSeriesLimitCount = 0
 		perMetricSeriesLimitCount   = 0
 		nativeHistogramCount        = 0
+		nearOutOfBoundsCount        = 0
+		nearOutOfBoundsBefore       = i.nearOutOfBoundsBefore(userID, db)
 
 		updateFirstPartial = func(errFn func() error) {
 			if firstPartialErr == nil {
@@ -1139,12 +1321,15 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 			}
 		}
 
-		handleAppendFailure = func(err error, timestampMs int64, lbls []cortexpb.LabelAdapter, copiedLabels labels.Labels) (rollback bool) {
+		handleAppendFailure = func(err error, timestampMs int64, lbls []cortexpb.LabelAdapter, copiedLabels labels.Labels, isReplayed func() bool) (rollback bool) {
 			// Check if the error is a soft error we can proceed on. If so, we keep track
 			// of it, so that we can return it back to the distributor, which will return a
 			// 400 error to the client. The client (Prometheus) will not retry on 400, and
 			// we actually ingested all samples which haven't failed.
 			switch cause := errors.Cause(err); {
+			case (errors.Is(cause, storage.ErrOutOfBounds) || errors.Is(cause, storage.ErrOutOfOrderSample)) && isReplayed():
+				walReplayedSamplesCount++
+
 			case errors.Is(cause, storage.ErrOutOfBounds):
 				sampleOutOfBoundsCount++
 				updateFirstPartial(func() error { return wrappedTSDBIngestErr(err, model.Time(timestampMs), lbls) })
@@ -1213,6 +1398,9 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 		// To find out if any sample was added to this series, we keep old value.
 		oldSucceededSamplesCount := succeededSamplesCount
 
+		// Samples of this series already replayed from the WAL, looked up on the first rejected sample.
+		var replayed *walReplayedSamples
+
 		for _, s := range ts.Samples {
 			var err error
 
@@ -1220,6 +1408,9 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 			if ref != 0 {
 				if _, err = app.Append(ref, copiedLabels, s.TimestampMs, s.Value); err == nil {
 					succeededSamplesCount++
+					if s.TimestampMs < nearOutOfBoundsBefore {
+						nearOutOfBoundsCount++
+					}
 					continue
 				}
 
@@ -1230,13 +1421,25 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 				// Retain the reference in case there are multiple samples for the series.
 				if ref, err = app.Append(0, copiedLabels, s.TimestampMs, s.Value); err == nil {
 					succeededSamplesCount++
+					if s.TimestampMs < nearOutOfBoundsBefore {
+						nearOutOfBoundsCount++
+					}
 					continue
 				}
 			}
 
 			failedSamplesCount++
 
-			if rollback := handleAppendFailure(err, s.TimestampMs, ts.Labels, copiedLabels); !rollback {
+			isReplayed := func() bool {
+				if !db.mayBeWALReplayedSample(s.TimestampMs, startAppend) {
+					return false
+				}
+				if replayed == nil {
+					replayed = db.getWALReplayedSamples(ctx, copiedLabels, ts.Samples, ts.Histograms)
+				}
+				return replayed.isFloatReplayed(s.TimestampMs, s.Value)
+			}
+			if rollback := handleAppendFailure(err, s.TimestampMs, ts.Labels, copiedLabels, isReplayed); !rollback {
 				continue
 			}
 			// The error looks an issue on our side, so we should rollback
@@ -1264,6 +1467,9 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 				if ref != 0 {
 					if _, err = app.AppendHistogram(ref, copiedLabels, hp.TimestampMs, h, fh); err == nil {
 						succeededSamplesCount++
+						if hp.TimestampMs < nearOutOfBoundsBefore {
+							nearOutOfBoundsCount++
+						}
 						continue
 					}
 				} else {
@@ -1271,13 +1477,28 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 					copiedLabels = cortexpb.FromLabelAdaptersToLabelsWithCopy(ts.Labels)
 					if ref, err = app.AppendHistogram(0, copiedLabels, hp.TimestampMs, h, fh); err == nil {
 						succeededSamplesCount++
+						if hp.TimestampMs < nearOutOfBoundsBefore {
+							nearOutOfBoundsCount++
+						}
 						continue
 					}
 				}
 
 				failedSamplesCount++
 
-				if rollback := handleAppendFailure(err, hp.TimestampMs, ts.Labels, copiedLabels); !rollback {
+				isReplayed := func() bool {
+					if !db.mayBeWALReplayedSample(hp.TimestampMs, startAppend) {
+						return false
+					}
+					if replayed == nil {
+						replayed = db.getWALReplayedSamples(ctx, copiedLabels, ts.Samples, ts.Histograms)
+					}
+					if fh == nil {
+						fh = h.ToFloat(nil)
+					}
+					return replayed.isHistogramReplayed(hp.TimestampMs, fh)
+				}
+				if rollback := handleAppendFailure(err, hp.TimestampMs, ts.Labels, copiedLabels, isReplayed); !rollback {
 					continue
 				}
 				// The error looks an issue on our side, so we should rollback
@@ -1336,6 +1557,9 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 
 	startCommit := time.Now()
 	if err := app.Commit(); err != nil {
+		if i.diskPressure != nil {
+			i.diskPressure.recordCommitError(err)
+		}
 		return nil, wrapWithUser(err, userID)
 	}
 	i.TSDBState.appenderCommitDuration.Observe(time.Since(startCommit).Seconds())
@@ -1353,6 +1577,15 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 	i.metrics.ingestedExemplars.Add(float64(succeededExemplarsCount))
 	i.metrics.ingestedExemplarsFail.Add(float64(failedExemplarsCount))
 
+	if walReplayedSamplesCount > 0 {
+		i.metrics.walReplayIgnoredSamples.WithLabelValues(userID).Add(float64(walReplayedSamplesCount))
+		if db.walReplayDuplicatesLogged.CompareAndSwap(false, true) {
+			level.Info(logutil.WithContext(ctx, i.logger)).Log("msg", "ignoring pushed samples already replayed from the WAL", "user", userID, "until", db.walReplayDuplicatesUntil)
+		}
+	}
+	if nearOutOfBoundsCount > 0 {
+		i.metrics.nearOutOfBoundsSamples.WithLabelValues(userID).Add(float64(nearOutOfBoundsCount))
+	}
 	if sampleOutOfBoundsCount > 0 {
 		i.validateMetrics.DiscardedSamples.WithLabelValues(sampleOutOfBounds, userID).Add(float64(sampleOutOfBoundsCount))
 	}
@@ -1421,10 +1654,108 @@ func (u *userTSDB) acquireAppendLock() error {
 	}
 
 	u.pushesInFlight.Add(1)
+	u.pushesInFlightCount.Inc()
 	return nil
 }
 
+// oldestAcceptedTimestamp returns the oldest timestamp, in milliseconds, of the samples accepted by the head,
+// given the out-of-order time window of the tenant. Returns false if the head has no samples yet.
+func (u *userTSDB) oldestAcceptedTimestamp(oooTimeWindow int64) (int64, bool) {
+	minValid, ok := u.db.Head().AppendableMinValidTime()
+	if !ok {
+		return 0, false
+	}
+	if oooTimeWindow > 0 {
+		minValid = min(minValid, u.db.Head().MaxTime()-oooTimeWindow)
+	}
+	return minValid, true
+}
+
+// walReplayedSamples holds the samples of a series, in the time range of a push request, already
+// replayed from the WAL on startup.
+type walReplayedSamples struct {
+	floats     map[int64]float64
+	histograms map[int64]*histogram.FloatHistogram
+}
+
+// isFloatReplayed returns whether the head has a float sample with the same timestamp and value.
+func (r *walReplayedSamples) isFloatReplayed(timestampMs int64, value float64) bool {
+	v, ok := r.floats[timestampMs]
+	return ok && math.Float64bits(v) == math.Float64bits(value)
+}
+
+// isHistogramReplayed returns whether the head has a histogram sample with the same timestamp and value.
+func (r *walReplayedSamples) isHistogramReplayed(timestampMs int64, fh *histogram.FloatHistogram) bool {
+	v, ok := r.histograms[timestampMs]
+	return ok && v.Equals(fh)
+}
+
+// mayBeWALReplayedSample returns whether a sample rejected by the TSDB may be a duplicate of a sample
+// already replayed from the WAL on startup, as it happens when distributors retry pushes which succeeded
+// before the restart.
+func (u *userTSDB) mayBeWALReplayedSample(timestampMs int64, now time.Time) bool {
+	return now.Before(u.walReplayDuplicatesUntil) && timestampMs <= u.walReplayMaxTime
+}
+
+// getWALReplayedSamples returns the samples of the series in the time range of the pushed samples and
+// histograms. It's called once per series of a push request, and looks up both the head and the blocks,
+// given the head is compacted after the WAL replay.
+func (u *userTSDB) getWALReplayedSamples(ctx context.Context, lbls labels.Labels, samples []cortexpb.Sample, histograms []cortexpb.Histogram) *walReplayedSamples {
+	replayed := &walReplayedSamples{}
+
+	mint, maxt := int64(math.MaxInt64), int64(math.MinInt64)
+	for _, s := range samples {
+		mint, maxt = min(mint, s.TimestampMs), max(maxt, s.TimestampMs)
+	}
+	for _, h := range histograms {
+		mint, maxt = min(mint, h.TimestampMs), max(maxt, h.TimestampMs)
+	}
+	maxt = min(maxt, u.walReplayMaxTime)
+	if mint > maxt {
+		return replayed
+	}
+
+	q, err := u.db.Querier(mint, maxt)
+	if err != nil {
+		return replayed
+	}
+	defer q.Close()
+
+	matchers := make([]*labels.Matcher, 0, lbls.Len())
+	lbls.Range(func(l labels.Label) {
+		matchers = append(matchers, labels.MustNewMatcher(labels.MatchEqual, l.Name, l.Value))
+	})
+
+	ss := q.Select(ctx, false, nil, matchers...)
+	for ss.Next() {
+		series := ss.At()
+		if !labels.Equal(series.Labels(), lbls) {
+			continue
+		}
+
+		it := series.Iterator(nil)
+		for vt := it.Next(); vt != chunkenc.ValNone; vt = it.Next() {
+			switch vt {
+			case chunkenc.ValFloat:
+				if replayed.floats == nil {
+					replayed.floats = map[int64]float64{}
+				}
+				t, v := it.At()
+				replayed.floats[t] = v
+			case chunkenc.ValHistogram, chunkenc.ValFloatHistogram:
+				if replayed.histograms == nil {
+					replayed.histograms = map[int64]*histogram.FloatHistogram{}
+				}
+				t, fh := it.AtFloatHistogram(nil)
+				replayed.histograms[t] = fh
+			}
+		}
+	}
+	return replayed
+}
+
 func (u *userTSDB) releaseAppendLock() {
+	u.pushesInFlightCount.Dec()
 	u.pushesInFlight.Done()
 }
 
@@ -1788,8 +2119,9 @@ func (i *Ingester) metricsForLabelMatchersCommon(ctx context.Context, req *clien
 	return result, cleanup, nil
 }
 
-// MetricsMetadata returns all the metric metadata of a user.
-func (i *Ingester) MetricsMetadata(ctx context.Context, _ *client.MetricsMetadataRequest) (*client.MetricsMetadataResponse, error) {
+// MetricsMetadata returns the metric metadata of a user, optionally filtered by metric name, limited
+// and paginated according to the request.
+func (i *Ingester) MetricsMetadata(ctx context.Context, req *client.MetricsMetadataRequest) (*client.MetricsMetadataResponse, error) {
 	i.stoppedMtx.RLock()
 	if err := i.checkRunningOrStopping(); err != nil {
 		i.stoppedMtx.RUnlock()
@@ -1808,7 +2140,7 @@ func (i *Ingester) MetricsMetadata(ctx context.Context, _ *client.MetricsMetadat
 		return &client.MetricsMetadataResponse{}, nil
 	}
 
-	return &client.MetricsMetadataResponse{Metadata: userMetadata.toClientMetadata()}, nil
+	return userMetadata.toClientMetadata(req), nil
 }
 
 // CheckReady is the readiness handler used to indicate to k8s when the ingesters
@@ -1848,6 +2180,37 @@ func (i *Ingester) UserStats(ctx context.Context, req *client.UserStatsRequest)
 	}, nil
 }
 
+// TSDBStatus returns the cardinality statistics of the TSDB head of the current user.
+func (i *Ingester) TSDBStatus(ctx context.Context, req *client.TSDBStatusRequest) (*client.TSDBStatusResponse, error) {
+	if err := i.checkRunning(); err != nil {
+		return nil, err
+	}
+
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db := i.getTSDB(userID)
+	if db == nil {
+		return &client.TSDBStatusResponse{}, nil
+	}
+
+	limit := min(max(int(req.Limit), 1), client.MaxTSDBStatusLimit)
+	stats := db.Head().Stats(labels.MetricName, limit)
+
+	return &client.TSDBStatusResponse{
+		NumSeries:                   stats.NumSeries,
+		MinTime:                     stats.MinTime,
+		MaxTime:                     stats.MaxTime,
+		NumLabelPairs:               int32(stats.IndexPostingStats.NumLabelPairs),
+		SeriesCountByMetricName:     client.FromIndexStats(stats.IndexPostingStats.CardinalityMetricsStats),
+		LabelValueCountByLabelName:  client.FromIndexStats(stats.IndexPostingStats.CardinalityLabelStats),
+		MemoryInBytesByLabelName:    client.FromIndexStats(stats.IndexPostingStats.LabelValueStats),
+		SeriesCountByLabelValuePair: client.FromIndexStats(stats.IndexPostingStats.LabelValuePairsStats),
+	}, nil
+}
+
 func (i *Ingester) userStats() []UserIDStats {
 	i.stoppedMtx.RLock()
 	defer i.stoppedMtx.RUnlock()
@@ -1874,6 +2237,22 @@ func (i *Ingester) AllUserStatsHandler(w http.ResponseWriter, r *http.Request) {
 	AllUserStatsRender(w, r, stats, 0)
 }
 
+// TenantsUsageHandler returns the in-memory and on-disk usage of the TSDB of each tenant, to identify
+// the tenants mostly contributing to the resources utilization of this ingester.
+func (i *Ingester) TenantsUsageHandler(w http.ResponseWriter, r *http.Request) {
+	usages := make([]TenantUsage, 0)
+	for _, userID := range i.getTSDBUsers() {
+		db := i.getTSDB(userID)
+		if db == nil {
+			continue
+		}
+
+		usages = append(usages, createTenantUsage(db))
+	}
+
+	TenantsUsageRender(w, usages)
+}
+
 // AllUserStats returns ingestion statistics for all users known to this ingester.
 func (i *Ingester) AllUserStats(_ context.Context, _ *client.UserStatsRequest) (*client.UsersStatsResponse, error) {
 	if err := i.checkRunning(); err != nil {
@@ -1920,6 +2299,29 @@ func createUserStats(db *userTSDB, activeSeriesMetricsEnabled bool) UserStats {
 	}
 }
 
+func createTenantUsage(db *userTSDB) TenantUsage {
+	usage := TenantUsage{
+		UserID:         db.userID,
+		MemorySeries:   db.Head().NumSeries(),
+		IngestionRate:  db.ingestedAPISamples.Rate() + db.ingestedRuleSamples.Rate(),
+		PushesInFlight: db.pushesInFlightCount.Load(),
+	}
+
+	if lastUpdate := db.lastUpdate.Load(); lastUpdate > 0 {
+		usage.LastPush = time.Unix(lastUpdate, 0).UTC().Format(time.RFC3339)
+	}
+
+	// Sizes are best effort: the directories may be changed by a concurrent WAL truncation or head compaction.
+	if size, err := fileutil.DirSize(filepath.Join(db.db.Dir(), "wal")); err == nil {
+		usage.WALBytes = size
+	}
+	if size, err := fileutil.DirSize(filepath.Join(db.db.Dir(), "chunks_head")); err == nil {
+		usage.HeadChunksBytes = size
+	}
+
+	return usage
+}
+
 const queryStreamBatchMessageSize = 1 * 1024 * 1024
 
 // QueryStream implements service.IngesterServer
@@ -2165,6 +2567,169 @@ func (i *Ingester) getOrCreateTSDB(userID string, force bool) (*userTSDB, error)
 	return db, nil
 }
 
+// corruptedWALSuffix is the suffix of the WAL and WBL directories moved aside because they
+// could not be replayed.
+const corruptedWALSuffix = ".corrupted-"
+
+// isWALReplayError returns whether the error returned opening the TSDB stored in dir has been
+// caused by a corruption of its WAL (including checkpoints) or WBL.
+func isWALReplayError(dir string, err error) bool {
+	var cerr *wlog.CorruptionErr
+	if errors.As(err, &cerr) {
+		return true
+	}
+
+	// The TSDB fails to repair a corrupted checkpoint without wrapping the corruption error,
+	// so we check the last checkpoint explicitly.
+	return isWALCheckpointCorrupted(filepath.Join(dir, "wal"))
+}
+
+// isWALCheckpointCorrupted returns whether the records of the last checkpoint of the WAL
+// stored in dir can't be read because of a corruption.
+func isWALCheckpointCorrupted(dir string) bool {
+	checkpointDir, _, err := wlog.LastCheckpoint(dir)
+	if err != nil {
+		return false
+	}
+
+	sr, err := wlog.NewSegmentsReader(checkpointDir)
+	if err != nil {
+		return false
+	}
+	defer sr.Close()
+
+	r := wlog.NewReader(sr)
+	for r.Next() {
+	}
+
+	var cerr *wlog.CorruptionErr
+	return errors.As(r.Err(), &cerr)
+}
+
+// moveWALAside renames the WAL and WBL directories of the TSDB stored in dir, so that the TSDB
+// can be opened without them while keeping them on disk for investigation. Only the last WAL
+// moved aside is kept, so that they don't accumulate on disk. It returns the suffix of the
+// renamed directories.
+func moveWALAside(dir string) (string, error) {
+	movedAside, err := movedAsideWALs(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, path := range movedAside {
+		if err := os.RemoveAll(path); err != nil {
+			return "", err
+		}
+	}
+
+	suffix := fmt.Sprintf("%s%d", corruptedWALSuffix, time.Now().Unix())
+
+	for _, name := range []string{wlog.WblDirName, "wal"} {
+		src := filepath.Join(dir, name)
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			continue
+		}
+
+		if err := os.Rename(src, src+suffix); err != nil {
+			return "", err
+		}
+	}
+
+	return suffix, nil
+}
+
+// movedAsideWALs returns the paths of the WAL and WBL directories moved aside in the TSDB stored in dir.
+func movedAsideWALs(dir string) ([]string, error) {
+	var paths []string
+	for _, name := range []string{wlog.WblDirName, "wal"} {
+		matches, err := filepath.Glob(filepath.Join(dir, name+corruptedWALSuffix+"*"))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// movedAsideWALsSize returns the size, in bytes, of the WAL and WBL directories moved aside in the
+// TSDB stored in dir.
+func movedAsideWALsSize(dir string) (int64, error) {
+	paths, err := movedAsideWALs(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return size, nil
+}
+
+// getBlockDuration returns the duration, in milliseconds, of the TSDB blocks cut for the given user.
+// The per-tenant override is honored only if it's a divisor or a multiple of the configured block
+// range, so that blocks of all tenants stay aligned.
+func (i *Ingester) getBlockDuration(userID string) int64 {
+	defaultDuration := i.cfg.BlocksStorageConfig.TSDB.BlockRanges[0].Milliseconds()
+
+	override := time.Duration(i.limits.TSDBBlockDuration(userID)).Milliseconds()
+	if override <= 0 {
+		return defaultDuration
+	}
+
+	if defaultDuration%override != 0 && override%defaultDuration != 0 {
+		level.Warn(i.logger).Log("msg", "ignoring the per-tenant TSDB block duration because it's neither a divisor nor a multiple of the configured block range", "user", userID, "block_duration", time.Duration(override)*time.Millisecond, "block_range", time.Duration(defaultDuration)*time.Millisecond)
+		return defaultDuration
+	}
+
+	return override
+}
+
+// getHeadCompactionDelay returns the stable delay, in milliseconds, applied to the regular head
+// compaction of the given user, so that the tenants of an ingester, and the replicas of a tenant
+// across ingesters, don't all compact at the same time. The jitter is clamped to half of the
+// tenant's block duration, which may be lower than the smallest block range when overridden.
+func (i *Ingester) getHeadCompactionDelay(userID string, blockDuration int64) int64 {
+	jitter := min(i.cfg.BlocksStorageConfig.TSDB.HeadCompactionTenantJitter.Milliseconds(), blockDuration/2)
+	if jitter <= 0 {
+		return 0
+	}
+
+	h := client.HashNew32()
+	h = client.HashAdd32(h, i.cfg.LifecyclerConfig.ID)
+	h = client.HashAdd32(h, userID)
+	return int64(h) % jitter
+}
+
+// getRetentionPeriod returns the retention, in milliseconds, of the TSDB blocks for the given user.
+// The per-tenant override is clamped to the largest block range, so that blocks aren't deleted
+// while still being compacted, or right after being cut.
+func (i *Ingester) getRetentionPeriod(userID string, maxBlockDuration int64) int64 {
+	override := time.Duration(i.limits.TSDBRetentionPeriod(userID)).Milliseconds()
+	if override <= 0 {
+		return i.cfg.BlocksStorageConfig.TSDB.Retention.Milliseconds()
+	}
+
+	if override < maxBlockDuration {
+		level.Warn(i.logger).Log("msg", "clamping the per-tenant TSDB retention period to the largest block range", "user", userID, "retention_period", time.Duration(override)*time.Millisecond, "block_range", time.Duration(maxBlockDuration)*time.Millisecond)
+		return maxBlockDuration
+	}
+
+	return override
+}
+
 // createTSDB creates a TSDB for a given userID, and returns the created db.
 func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 	tsdbPromReg := prometheus.NewRegistry()
@@ -2172,6 +2737,14 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 	userLogger := logutil.WithUserID(userID, i.logger)
 
 	blockRanges := i.cfg.BlocksStorageConfig.TSDB.BlockRanges.ToMilliseconds()
+	blockDuration := i.getBlockDuration(userID)
+	maxBlockDuration := blockRanges[len(blockRanges)-1]
+	if blockDuration != blockRanges[0] {
+		// The blocks of a tenant with a per-tenant block duration are not compacted locally,
+		// given the shipper only uploads the blocks cut from the head.
+		maxBlockDuration = blockDuration
+	}
+	retentionPeriod := i.getRetentionPeriod(userID, maxBlockDuration)
 
 	var postingCache cortex_tsdb.ExpandedPostingsCache
 	if i.cfg.BlocksStorageConfig.TSDB.PostingsCache.Head.Enabled || i.cfg.BlocksStorageConfig.TSDB.PostingsCache.Blocks.Enabled {
@@ -2192,7 +2765,9 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 		interner:                     util.NewInterner(),
 		labelsStringInterningEnabled: i.cfg.LabelsStringInterningEnabled,
 
-		blockRetentionPeriod: i.cfg.BlocksStorageConfig.TSDB.Retention.Milliseconds(),
+		blockRetentionPeriod: retentionPeriod,
+		blockDuration:        blockDuration,
+		headCompactionDelay:  i.getHeadCompactionDelay(userID, blockDuration),
 		postingCache:         postingCache,
 	}
 
@@ -2209,10 +2784,10 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 	}
 
 	// Create a new user database
-	db, err := tsdb.Open(udir, userLogger, tsdbPromReg, &tsdb.Options{
-		RetentionDuration:              i.cfg.BlocksStorageConfig.TSDB.Retention.Milliseconds(),
-		MinBlockDuration:               blockRanges[0],
-		MaxBlockDuration:               blockRanges[len(blockRanges)-1],
+	tsdbOpts := &tsdb.Options{
+		RetentionDuration:              retentionPeriod,
+		MinBlockDuration:               blockDuration,
+		MaxBlockDuration:               maxBlockDuration,
 		NoLockfile:                     true,
 		StripeSize:                     i.cfg.BlocksStorageConfig.TSDB.StripeSize,
 		HeadChunksWriteBufferSize:      i.cfg.BlocksStorageConfig.TSDB.HeadChunksWriteBufferSize,
@@ -2221,9 +2796,10 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 		SeriesLifecycleCallback:        userDB,
 		BlocksToDelete:                 userDB.blocksToDelete,
 		EnableExemplarStorage:          enableExemplars,
-		IsolationDisabled:              true,
+		IsolationDisabled:              !i.cfg.BlocksStorageConfig.TSDB.IsolationEnabled,
 		MaxExemplars:                   maxExemplarsForUser,
 		HeadChunksWriteQueueSize:       i.cfg.BlocksStorageConfig.TSDB.HeadChunksWriteQueueSize,
+		SamplesPerChunk:                i.cfg.BlocksStorageConfig.TSDB.HeadChunksSamplesPerChunk,
 		EnableMemorySnapshotOnShutdown: i.cfg.BlocksStorageConfig.TSDB.MemorySnapshotOnShutdown,
 		OutOfOrderTimeWindow:           time.Duration(oooTimeWindow).Milliseconds(),
 		OutOfOrderCapMax:               i.cfg.BlocksStorageConfig.TSDB.OutOfOrderCapMax,
@@ -2235,12 +2811,41 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 			}
 			return tsdb.NewBlockChunkQuerier(b, mint, maxt)
 		},
-	}, nil)
+	}
+
+	db, err := tsdb.Open(udir, userLogger, tsdbPromReg, tsdbOpts, nil)
+	if err != nil && i.cfg.BlocksStorageConfig.TSDB.SkipCorruptedWAL && isWALReplayError(udir, err) {
+		// The WAL can't be replayed, so we move it aside and retry opening the TSDB without it.
+		// Samples in the skipped WAL which have not been compacted into a block yet are lost.
+		movedTo, moveErr := moveWALAside(udir)
+		if moveErr != nil {
+			return nil, errors.Wrapf(err, "failed to open TSDB: %s (moving the corrupted WAL aside failed: %s)", udir, moveErr)
+		}
+
+		level.Warn(userLogger).Log("msg", "failed to replay the TSDB WAL, skipping it", "err", err, "moved_to", movedTo)
+		i.TSDBState.walSkipped.Inc()
+
+		// The failed TSDB may have registered some metrics, so we use a fresh registry.
+		tsdbPromReg = prometheus.NewRegistry()
+		db, err = tsdb.Open(udir, userLogger, tsdbPromReg, tsdbOpts, nil)
+	}
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to open TSDB: %s", udir)
 	}
 	db.DisableCompactions() // we will compact on our own schedule
 
+	// Report the WALs moved aside on disk, including the ones left by a previous run.
+	if size, err := movedAsideWALsSize(udir); err != nil {
+		level.Warn(userLogger).Log("msg", "failed to get the size of the WALs moved aside", "err", err)
+	} else if size > 0 {
+		i.metrics.walMovedAsideBytes.WithLabelValues(userID).Set(float64(size))
+	}
+
+	if period := i.cfg.BlocksStorageConfig.TSDB.WALReplayDuplicatesPeriod; period > 0 && db.Head().NumSeries() > 0 {
+		userDB.walReplayMaxTime = db.Head().MaxTime()
+		userDB.walReplayDuplicatesUntil = time.Now().Add(period)
+	}
+
 	// Run compaction before using this TSDB. If there is data in head that needs to be put into blocks,
 	// this will actually create the blocks. If there is no data (empty TSDB), this is a no-op, although
 	// local blocks compaction may still take place if configured.
@@ -2333,6 +2938,7 @@ func (i *Ingester) closeAllTSDB() {
 
 			i.metrics.memUsers.Dec()
 			i.metrics.activeSeriesPerUser.DeleteLabelValues(userID)
+			i.metrics.activeSeriesCustomTrackers.DeletePartialMatch(prometheus.Labels{"user": userID})
 		}(userDB)
 	}
 
@@ -2367,7 +2973,12 @@ func (i *Ingester) openExistingTSDB(ctx context.Context) error {
 				i.stoppedMtx.Unlock()
 				i.metrics.memUsers.Inc()
 
-				i.TSDBState.walReplayTime.Observe(time.Since(startTime).Seconds())
+				replayDuration := time.Since(startTime)
+				replayedSeries := db.Head().NumSeries()
+				i.TSDBState.walReplayTime.Observe(replayDuration.Seconds())
+				i.metrics.walReplayDurationPerUser.WithLabelValues(userID).Set(replayDuration.Seconds())
+				i.metrics.walReplaySeriesPerUser.WithLabelValues(userID).Set(float64(replayedSeries))
+				level.Info(logutil.WithContext(ctx, i.logger)).Log("msg", "opened existing TSDB", "user", userID, "duration", replayDuration, "series", replayedSeries)
 			}
 
 			return nil
@@ -2564,6 +3175,10 @@ func (i *Ingester) shipBlocks(ctx context.Context, allowed *util.AllowedTenants)
 			level.Warn(logutil.WithContext(ctx, i.logger)).Log("msg", "shipper failed to synchronize TSDB blocks with the storage", "user", userID, "uploaded", uploaded, "err", err)
 		} else {
 			level.Debug(logutil.WithContext(ctx, i.logger)).Log("msg", "shipper successfully synchronized TSDB blocks with storage", "user", userID, "uploaded", uploaded)
+
+			now := time.Now()
+			userDB.lastSuccessfulShip.Store(now.Unix())
+			i.metrics.shipperLastSuccessfulUpload.WithLabelValues(userID).Set(float64(now.Unix()))
 		}
 
 		// The shipper meta file could be updated even if the Sync() returned an error,
@@ -2577,6 +3192,8 @@ func (i *Ingester) shipBlocks(ctx context.Context, allowed *util.AllowedTenants)
 			}
 		}
 
+		i.metrics.shipperPendingBlocks.WithLabelValues(userID).Set(float64(userDB.getUnshippedBlocksCount()))
+
 		return nil
 	})
 }
@@ -2642,18 +3259,25 @@ func (i *Ingester) compactBlocks(ctx context.Context, force bool, allowed *util.
 
 		var err error
 
+		idle := i.TSDBState.compactionIdleTimeout > 0 && userDB.isIdle(time.Now(), i.TSDBState.compactionIdleTimeout)
+
+		// Spread the regular head compactions of the tenants over time.
+		if !force && !idle && !userDB.headCompactionDelayElapsed() {
+			return nil
+		}
+
 		i.TSDBState.compactionsTriggered.Inc()
 
 		reason := ""
 		switch {
 		case force:
 			reason = "forced"
-			err = userDB.compactHead(ctx, i.cfg.BlocksStorageConfig.TSDB.BlockRanges[0].Milliseconds())
+			err = userDB.compactHead(ctx, userDB.blockDuration)
 
-		case i.TSDBState.compactionIdleTimeout > 0 && userDB.isIdle(time.Now(), i.TSDBState.compactionIdleTimeout):
+		case idle:
 			reason = "idle"
 			level.Info(logutil.WithContext(ctx, i.logger)).Log("msg", "TSDB is idle, forcing compaction", "user", userID)
-			err = userDB.compactHead(ctx, i.cfg.BlocksStorageConfig.TSDB.BlockRanges[0].Milliseconds())
+			err = userDB.compactHead(ctx, userDB.blockDuration)
 
 		default:
 			reason = "regular"
@@ -2889,17 +3513,59 @@ func (i *Ingester) lifecyclerFlush() {
 	i.compactBlocks(ctx, true, nil)
 	if i.cfg.BlocksStorageConfig.TSDB.IsBlocksShippingEnabled() {
 		i.shipBlocks(ctx, nil)
+
+		if err := i.retryShipBlocksOnShutdown(ctx); err != nil {
+			level.Error(i.logger).Log("msg", "failed to ship all TSDB blocks, the blocks not shipped are kept on the local disk", "err", err)
+			i.lifecyclerFlushErr.Store(err)
+			return
+		}
 	}
 
+	i.lifecyclerFlushErr.Store(nil)
 	level.Info(i.logger).Log("msg", "finished flushing and shipping TSDB blocks")
 }
 
+// retryShipBlocksOnShutdown retries shipping the blocks not shipped yet, until all of them have been shipped or
+// the flush on shutdown timeout expires. It returns an error listing the tenants with blocks not shipped.
+func (i *Ingester) retryShipBlocksOnShutdown(ctx context.Context) error {
+	err := i.checkTenantsShipped(nil)
+	if err == nil || i.cfg.BlocksStorageConfig.TSDB.FlushBlocksOnShutdownTimeout <= 0 {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, i.cfg.BlocksStorageConfig.TSDB.FlushBlocksOnShutdownTimeout)
+	defer cancel()
+
+	retries := backoff.New(ctx, backoff.Config{
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: 10 * time.Second,
+	})
+	for retries.Ongoing() {
+		level.Warn(i.logger).Log("msg", "retrying to ship TSDB blocks", "err", err)
+		retries.Wait()
+		if !retries.Ongoing() {
+			break
+		}
+
+		i.shipBlocks(ctx, nil)
+		if err = i.checkTenantsShipped(nil); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
 const (
 	tenantParam = "tenant"
 	waitParam   = "wait"
+	drainParam  = "drain"
 )
 
 // Blocks version of Flush handler. It force-compacts blocks, and triggers shipping.
+//
+// When called with wait=true, it returns once the selected tenants have been compacted and shipped,
+// and the returned status code reflects whether the flush completed.
 func (i *Ingester) flushHandler(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
@@ -2911,70 +3577,219 @@ func (i *Ingester) flushHandler(w http.ResponseWriter, r *http.Request) {
 	tenants := r.Form[tenantParam]
 
 	allowedUsers := util.NewAllowedTenants(tenants, nil)
-	run := func() {
-		ingCtx := i.BasicService.ServiceContext()
-		if ingCtx == nil || ingCtx.Err() != nil {
-			level.Info(logutil.WithContext(r.Context(), i.logger)).Log("msg", "flushing TSDB blocks: ingester not running, ignoring flush request")
+	logger := logutil.WithContext(r.Context(), i.logger)
+
+	if len(r.Form[waitParam]) > 0 && r.Form[waitParam][0] == "true" {
+		// Run synchronously and report the result of the flush.
+		if code, err := i.compactAndShipBlocks(r.Context(), logger, allowedUsers); err != nil {
+			http.Error(w, err.Error(), code)
 			return
 		}
+	} else {
+		go i.compactAndShipBlocks(context.Background(), logger, allowedUsers) //nolint:errcheck
+	}
 
-		compactionCallbackCh := make(chan struct{})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// compactAndShipBlocks force-compacts the TSDB head of the allowed tenants and ships their blocks, waiting
+// until both have completed. It returns an error, along with the matching HTTP status code, if the flush
+// didn't complete.
+func (i *Ingester) compactAndShipBlocks(ctx context.Context, logger log.Logger, allowedUsers *util.AllowedTenants) (int, error) {
+	ingCtx := i.BasicService.ServiceContext()
+	if ingCtx == nil || ingCtx.Err() != nil {
+		level.Info(logger).Log("msg", "flushing TSDB blocks: ingester not running, ignoring flush request")
+		return http.StatusServiceUnavailable, errIngesterNotRunning
+	}
+
+	compactionCallbackCh := make(chan struct{})
+
+	level.Info(logger).Log("msg", "flushing TSDB blocks: triggering compaction")
+	select {
+	case i.TSDBState.forceCompactTrigger <- requestWithUsersAndCallback{users: allowedUsers, callback: compactionCallbackCh}:
+		// Compacting now.
+	case <-ingCtx.Done():
+		level.Warn(logger).Log("msg", "failed to compact TSDB blocks, ingester not running anymore")
+		return http.StatusServiceUnavailable, errIngesterNotRunning
+	case <-ctx.Done():
+		return http.StatusServiceUnavailable, ctx.Err()
+	}
+
+	// Wait until notified about compaction being finished.
+	select {
+	case <-compactionCallbackCh:
+		level.Info(logger).Log("msg", "finished compacting TSDB blocks")
+	case <-ingCtx.Done():
+		level.Warn(logger).Log("msg", "failed to compact TSDB blocks, ingester not running anymore")
+		return http.StatusServiceUnavailable, errIngesterNotRunning
+	case <-ctx.Done():
+		return http.StatusServiceUnavailable, ctx.Err()
+	}
+
+	if i.cfg.BlocksStorageConfig.TSDB.IsBlocksShippingEnabled() {
+		shippingCallbackCh := make(chan struct{}) // must be new channel, as compactionCallbackCh is closed now.
+
+		level.Info(logger).Log("msg", "flushing TSDB blocks: triggering shipping")
 
-		level.Info(logutil.WithContext(r.Context(), i.logger)).Log("msg", "flushing TSDB blocks: triggering compaction")
 		select {
-		case i.TSDBState.forceCompactTrigger <- requestWithUsersAndCallback{users: allowedUsers, callback: compactionCallbackCh}:
-			// Compacting now.
+		case i.TSDBState.shipTrigger <- requestWithUsersAndCallback{users: allowedUsers, callback: shippingCallbackCh}:
+			// shipping now
 		case <-ingCtx.Done():
-			level.Warn(logutil.WithContext(r.Context(), i.logger)).Log("msg", "failed to compact TSDB blocks, ingester not running anymore")
-			return
+			level.Warn(logger).Log("msg", "failed to ship TSDB blocks, ingester not running anymore")
+			return http.StatusServiceUnavailable, errIngesterNotRunning
+		case <-ctx.Done():
+			return http.StatusServiceUnavailable, ctx.Err()
 		}
 
-		// Wait until notified about compaction being finished.
+		// Wait until shipping finished.
 		select {
-		case <-compactionCallbackCh:
-			level.Info(logutil.WithContext(r.Context(), i.logger)).Log("msg", "finished compacting TSDB blocks")
+		case <-shippingCallbackCh:
+			level.Info(logger).Log("msg", "shipping of TSDB blocks finished")
 		case <-ingCtx.Done():
-			level.Warn(logutil.WithContext(r.Context(), i.logger)).Log("msg", "failed to compact TSDB blocks, ingester not running anymore")
-			return
+			level.Warn(logger).Log("msg", "failed to ship TSDB blocks, ingester not running anymore")
+			return http.StatusServiceUnavailable, errIngesterNotRunning
+		case <-ctx.Done():
+			return http.StatusServiceUnavailable, ctx.Err()
 		}
 
-		if i.cfg.BlocksStorageConfig.TSDB.IsBlocksShippingEnabled() {
-			shippingCallbackCh := make(chan struct{}) // must be new channel, as compactionCallbackCh is closed now.
+		if err := i.checkTenantsShipped(allowedUsers); err != nil {
+			level.Warn(logger).Log("msg", "flushing TSDB blocks: not all blocks have been shipped", "err", err)
+			return http.StatusInternalServerError, err
+		}
+	}
 
-			level.Info(logutil.WithContext(r.Context(), i.logger)).Log("msg", "flushing TSDB blocks: triggering shipping")
+	level.Info(logger).Log("msg", "flushing TSDB blocks: finished")
+	return http.StatusNoContent, nil
+}
 
-			select {
-			case i.TSDBState.shipTrigger <- requestWithUsersAndCallback{users: allowedUsers, callback: shippingCallbackCh}:
-				// shipping now
-			case <-ingCtx.Done():
-				level.Warn(logutil.WithContext(r.Context(), i.logger)).Log("msg", "failed to ship TSDB blocks, ingester not running anymore")
-				return
-			}
+// checkTenantsShipped returns an error if any of the allowed tenants still has blocks not shipped to the storage.
+func (i *Ingester) checkTenantsShipped(allowed *util.AllowedTenants) error {
+	var pending []string
+	for _, userID := range i.getTSDBUsers() {
+		if !allowed.IsAllowed(userID) {
+			continue
+		}
 
-			// Wait until shipping finished.
-			select {
-			case <-shippingCallbackCh:
-				level.Info(logutil.WithContext(r.Context(), i.logger)).Log("msg", "shipping of TSDB blocks finished")
-			case <-ingCtx.Done():
-				level.Warn(logutil.WithContext(r.Context(), i.logger)).Log("msg", "failed to ship TSDB blocks, ingester not running anymore")
-				return
+		if db := i.getTSDB(userID); db != nil {
+			if count := db.getUnshippedBlocksCount(); count > 0 {
+				pending = append(pending, fmt.Sprintf("%s (%d blocks)", userID, count))
 			}
 		}
+	}
 
-		level.Info(logutil.WithContext(r.Context(), i.logger)).Log("msg", "flushing TSDB blocks: finished")
+	if len(pending) > 0 {
+		slices.Sort(pending)
+		return fmt.Errorf("blocks not shipped for tenants: %s", strings.Join(pending, ", "))
 	}
+	return nil
+}
 
-	if len(r.Form[waitParam]) > 0 && r.Form[waitParam][0] == "true" {
-		// Run synchronously. This simplifies and speeds up tests.
-		run()
-	} else {
-		go run()
+// shipperStatus is the shipper status of a tenant, as returned by the ShipHandler.
+type shipperStatus struct {
+	Tenant                        string `json:"tenant"`
+	PendingBlocks                 int    `json:"pending_blocks"`
+	LastSuccessfulUploadTimestamp int64  `json:"last_successful_upload_timestamp_seconds"`
+}
+
+// ShipHandler triggers an immediate shipping of the TSDB blocks of the requested tenants and waits
+// until it's done. It replies with the shipper status of each tenant, so that operators can verify
+// all blocks have been uploaded to the storage before decommissioning the ingester.
+func (i *Ingester) ShipHandler(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		level.Warn(logutil.WithContext(r.Context(), i.logger)).Log("msg", "failed to parse HTTP request in ship handler", "err", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	tenants := r.Form[tenantParam]
+	if len(tenants) == 0 {
+		http.Error(w, "at least one tenant is required", http.StatusBadRequest)
+		return
+	}
+
+	if !i.cfg.BlocksStorageConfig.TSDB.IsBlocksShippingEnabled() {
+		http.Error(w, "blocks shipping is disabled", http.StatusBadRequest)
+		return
+	}
+
+	ingCtx := i.BasicService.ServiceContext()
+	if ingCtx == nil || ingCtx.Err() != nil {
+		http.Error(w, "ingester not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	callback := make(chan struct{})
+	select {
+	case i.TSDBState.shipTrigger <- requestWithUsersAndCallback{users: util.NewAllowedTenants(tenants, nil), callback: callback}:
+		// Shipping now.
+	case <-ingCtx.Done():
+		http.Error(w, "ingester not running", http.StatusServiceUnavailable)
+		return
+	case <-r.Context().Done():
+		return
+	}
+
+	select {
+	case <-callback:
+	case <-ingCtx.Done():
+		http.Error(w, "ingester not running", http.StatusServiceUnavailable)
+		return
+	case <-r.Context().Done():
+		return
+	}
+
+	statuses := make([]shipperStatus, 0, len(tenants))
+	for _, userID := range tenants {
+		st := shipperStatus{Tenant: userID}
+		if db := i.getTSDB(userID); db != nil {
+			st.PendingBlocks = db.getUnshippedBlocksCount()
+			st.LastSuccessfulUploadTimestamp = db.lastSuccessfulShip.Load()
+		}
+		statuses = append(statuses, st)
+	}
+
+	util.WriteJSONResponse(w, statuses)
+}
+
+// modeStatus is the status of the ingester, as returned by the ModeHandler when no mode is requested.
+type modeStatus struct {
+	Mode          string `json:"mode"`
+	HeadSeries    uint64 `json:"head_series"`
+	PendingBlocks int    `json:"pending_blocks"`
+	// Drained is true once a READONLY ingester has no data left in the TSDB heads and
+	// all its blocks have been shipped to the storage.
+	Drained bool `json:"drained"`
+}
+
+func (i *Ingester) getModeStatus() modeStatus {
+	st := modeStatus{Mode: i.lifecycler.GetState().String()}
+
+	for _, userID := range i.getTSDBUsers() {
+		db := i.getTSDB(userID)
+		if db == nil {
+			continue
+		}
+
+		st.HeadSeries += db.Head().NumSeries()
+		if i.cfg.BlocksStorageConfig.TSDB.IsBlocksShippingEnabled() {
+			st.PendingBlocks += db.getUnshippedBlocksCount()
+		}
+	}
+
+	st.Drained = st.Mode == ring.READONLY.String() && st.HeadSeries == 0 && st.PendingBlocks == 0
+	return st
+}
+
+// ModeStatusHandler returns the current mode of the ingester and its drain status.
+func (i *Ingester) ModeStatusHandler(w http.ResponseWriter, _ *http.Request) {
+	util.WriteJSONResponse(w, i.getModeStatus())
 }
 
 // ModeHandler Change mode of ingester. It will also update set unregisterOnShutdown to true if READONLY mode
+//
+// When switching to READONLY mode with drain=true, the TSDB heads of all tenants are compacted and shipped
+// in the background.
 func (i *Ingester) ModeHandler(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
@@ -2988,6 +3803,17 @@ func (i *Ingester) ModeHandler(w http.ResponseWriter, r *http.Request) {
 
 	currentState := i.lifecycler.GetState()
 	reqMode := strings.ToUpper(r.Form.Get("mode"))
+	drain := r.Form.Get(drainParam) == "true"
+
+	if drain && reqMode != "READONLY" {
+		respMsg := "drain is only supported with READONLY mode"
+		level.Warn(logutil.WithContext(r.Context(), i.logger)).Log("msg", respMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		// We ignore errors here, because we cannot do anything about them.
+		_, _ = w.Write([]byte(respMsg))
+		return
+	}
+
 	switch reqMode {
 	case "READONLY":
 		if currentState != ring.READONLY {
@@ -3014,7 +3840,7 @@ func (i *Ingester) ModeHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	default:
-		respMsg := fmt.Sprintf("invalid mode input: %s", html.EscapeString(reqMode))
+		respMsg := fmt.Sprintf("invalid mode input: %q, valid modes are: ACTIVE, READONLY", html.EscapeString(reqMode))
 		level.Warn(logutil.WithContext(r.Context(), i.logger)).Log("msg", respMsg)
 		w.WriteHeader(http.StatusBadRequest)
 		// We ignore errors here, because we cannot do anything about them.
@@ -3022,6 +3848,11 @@ func (i *Ingester) ModeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if drain {
+		// The compaction and shipping outlive the request, so they must not be bound to its context.
+		go i.compactAndShipBlocks(context.Background(), logutil.WithContext(r.Context(), i.logger), nil) //nolint:errcheck
+	}
+
 	respMsg := fmt.Sprintf("Ingester mode %s", i.lifecycler.GetState())
 	level.Info(logutil.WithContext(r.Context(), i.logger)).Log("msg", respMsg)
 	w.WriteHeader(http.StatusOK)
@@ -12,6 +12,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -28,17 +29,21 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/chunks"
 	"github.com/prometheus/prometheus/tsdb/index"
 	"github.com/prometheus/prometheus/tsdb/tsdbutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
 	"github.com/thanos-io/thanos/pkg/runutil"
 	"github.com/thanos-io/thanos/pkg/shipper"
+	"github.com/thanos-io/thanos/pkg/store/storepb"
 	"github.com/weaveworks/common/httpgrpc"
 	"github.com/weaveworks/common/middleware"
 	"github.com/weaveworks/common/user"
@@ -52,6 +57,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/querier/batch"
 	"github.com/cortexproject/cortex/pkg/querier/series"
+	"github.com/cortexproject/cortex/pkg/querysharding"
 	"github.com/cortexproject/cortex/pkg/ring"
 	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
 	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
@@ -119,6 +125,60 @@ func seriesSetFromResponseStream(s *mockQueryStreamServer) (storage.SeriesSet, e
 	return set, nil
 }
 
+func TestIngester_ActiveSeriesCustomTrackers(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	userID := "1"
+	registry := prometheus.NewRegistry()
+
+	limits.ActiveSeriesCustomTrackers = map[string]string{
+		"team_a":  `{team="a"}`,
+		"api_up":  `up{job="api"}`,
+		"nothing": `{job="unknown"}`,
+	}
+	tenantLimits := newMockTenantLimits(map[string]*validation.Limits{userID: &limits})
+
+	ing, err := prepareIngesterWithBlocksStorageAndLimits(t, defaultIngesterTestConfig(t), limits, tenantLimits, t.TempDir(), registry, true)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+	// Wait until it's ACTIVE
+	test.Poll(t, time.Second, ring.ACTIVE, func() interface{} {
+		return ing.lifecycler.GetState()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	samples := []cortexpb.Sample{{Value: 1, TimestampMs: 10}}
+	for _, lbls := range []labels.Labels{
+		labels.FromStrings(labels.MetricName, "up", "job", "api", "team", "a"),
+		labels.FromStrings(labels.MetricName, "up", "job", "api", "team", "b"),
+		labels.FromStrings(labels.MetricName, "up", "job", "db", "team", "a"),
+	} {
+		_, err = ing.Push(ctx, cortexpb.ToWriteRequest([]labels.Labels{lbls}, samples, nil, nil, cortexpb.API))
+		require.NoError(t, err)
+	}
+
+	ing.updateActiveSeries(ctx)
+	require.NoError(t, testutil.GatherAndCompare(registry, bytes.NewBufferString(`
+				# HELP cortex_ingester_active_series_custom_tracker Number of currently active series matching a custom tracker configured for the user.
+				# TYPE cortex_ingester_active_series_custom_tracker gauge
+				cortex_ingester_active_series_custom_tracker{name="api_up",user="1"} 2
+				cortex_ingester_active_series_custom_tracker{name="nothing",user="1"} 0
+				cortex_ingester_active_series_custom_tracker{name="team_a",user="1"} 2
+	`), "cortex_ingester_active_series_custom_tracker"))
+
+	// Trackers removed from the overrides are no longer exported.
+	updated := limits
+	updated.ActiveSeriesCustomTrackers = map[string]string{"team_b": `{team="b"}`}
+	tenantLimits.setLimits(userID, &updated)
+
+	ing.updateActiveSeries(ctx)
+	require.NoError(t, testutil.GatherAndCompare(registry, bytes.NewBufferString(`
+				# HELP cortex_ingester_active_series_custom_tracker Number of currently active series matching a custom tracker configured for the user.
+				# TYPE cortex_ingester_active_series_custom_tracker gauge
+				cortex_ingester_active_series_custom_tracker{name="team_b",user="1"} 1
+	`), "cortex_ingester_active_series_custom_tracker"))
+}
+
 func TestIngesterPerLabelsetLimitExceeded(t *testing.T) {
 	limits := defaultLimitsTestConfig()
 	userID := "1"
@@ -1516,6 +1576,124 @@ func TestIngester_Push(t *testing.T) {
 	}
 }
 
+func TestIngester_MetricsMetadata(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	metadata := []*cortexpb.MetricMetadata{
+		{MetricFamilyName: "metric_a", Type: cortexpb.COUNTER, Help: "a"},
+		{MetricFamilyName: "metric_b", Type: cortexpb.COUNTER, Help: "b1"},
+		{MetricFamilyName: "metric_b", Type: cortexpb.COUNTER, Help: "b2"},
+		{MetricFamilyName: "metric_c", Type: cortexpb.GAUGE, Help: "c"},
+	}
+	_, err = i.Push(ctx, cortexpb.ToWriteRequest(nil, nil, metadata, nil, cortexpb.API))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		req               *client.MetricsMetadataRequest
+		expectedMetadata  []*cortexpb.MetricMetadata
+		expectedNextToken string
+	}{
+		"should return all metadata without limits": {
+			req:              &client.MetricsMetadataRequest{},
+			expectedMetadata: metadata,
+		},
+		"should return the metadata of the requested metric": {
+			req:              &client.MetricsMetadataRequest{Metric: "metric_b"},
+			expectedMetadata: metadata[1:3],
+		},
+		"should return no metadata for an unknown metric": {
+			req:              &client.MetricsMetadataRequest{Metric: "unknown"},
+			expectedMetadata: []*cortexpb.MetricMetadata{},
+		},
+		"should return the first page": {
+			req:               &client.MetricsMetadataRequest{Limit: 2},
+			expectedMetadata:  metadata[0:3],
+			expectedNextToken: "metric_b",
+		},
+		"should return the last page": {
+			req:              &client.MetricsMetadataRequest{Limit: 2, PaginationToken: "metric_b"},
+			expectedMetadata: metadata[3:],
+		},
+		"should return the page following a token not matching any metric": {
+			req:              &client.MetricsMetadataRequest{PaginationToken: "metric_a0"},
+			expectedMetadata: metadata[1:],
+		},
+		"should limit the metadata per metric": {
+			req: &client.MetricsMetadataRequest{Metric: "metric_b", LimitPerMetric: 1},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			res, err := i.MetricsMetadata(ctx, testData.req)
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedNextToken, res.NextPaginationToken)
+
+			if testData.req.LimitPerMetric > 0 {
+				// Which metadata is kept for a metric is not guaranteed.
+				require.Len(t, res.Metadata, 1)
+				assert.Contains(t, metadata[1:3], res.Metadata[0])
+				return
+			}
+
+			// Order is never guaranteed.
+			assert.ElementsMatch(t, testData.expectedMetadata, res.Metadata)
+		})
+	}
+}
+
+func TestIngester_TSDBStatus(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	// Wait until it's ACTIVE
+	test.Poll(t, 1*time.Second, ring.ACTIVE, func() interface{} {
+		return i.lifecycler.GetState()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+
+	// Without any series, the user has no TSDB.
+	res, err := i.TSDBStatus(ctx, &client.TSDBStatusRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, &client.TSDBStatusResponse{}, res)
+
+	series := []labels.Labels{
+		labels.FromStrings(labels.MetricName, "foo", "job", "a"),
+		labels.FromStrings(labels.MetricName, "foo", "job", "b"),
+		labels.FromStrings(labels.MetricName, "bar", "job", "c"),
+	}
+	samples := []cortexpb.Sample{{TimestampMs: 1000, Value: 1}, {TimestampMs: 2000, Value: 2}, {TimestampMs: 3000, Value: 3}}
+	_, err = i.Push(ctx, cortexpb.ToWriteRequest(series, samples, nil, nil, cortexpb.API))
+	require.NoError(t, err)
+
+	res, err = i.TSDBStatus(ctx, &client.TSDBStatusRequest{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, &client.TSDBStatusResponse{
+		NumSeries:                   3,
+		MinTime:                     1000,
+		MaxTime:                     3000,
+		NumLabelPairs:               5,
+		SeriesCountByMetricName:     []*client.TSDBStatistic{{Name: "foo", Value: 2}},
+		LabelValueCountByLabelName:  []*client.TSDBStatistic{{Name: "job", Value: 3}},
+		MemoryInBytesByLabelName:    []*client.TSDBStatistic{{Name: labels.MetricName, Value: 9}},
+		SeriesCountByLabelValuePair: []*client.TSDBStatistic{{Name: "__name__=foo", Value: 2}},
+	}, res)
+
+	// The limit is clamped, instead of allocating the lists for it.
+	res, err = i.TSDBStatus(ctx, &client.TSDBStatusRequest{Limit: math.MaxInt32})
+	require.NoError(t, err)
+	assert.Len(t, res.SeriesCountByMetricName, 2)
+}
+
 // Referred from https://github.com/prometheus/prometheus/blob/v2.52.1/model/histogram/histogram_test.go#L985.
 func TestIngester_PushNativeHistogramErrors(t *testing.T) {
 	metricLabelAdapters := []cortexpb.LabelAdapter{{Name: labels.MetricName, Value: "test"}}
@@ -1853,15 +2031,40 @@ func TestIngester_Push_DecreaseInactiveSeries(t *testing.T) {
 
 func BenchmarkIngesterPush(b *testing.B) {
 	limits := defaultLimitsTestConfig()
-	benchmarkIngesterPush(b, limits, false)
+
+	for _, isolationEnabled := range []bool{false, true} {
+		b.Run(fmt.Sprintf("isolation enabled: %t", isolationEnabled), func(b *testing.B) {
+			cfg := defaultIngesterTestConfig(b)
+			cfg.BlocksStorageConfig.TSDB.IsolationEnabled = isolationEnabled
+
+			benchmarkIngesterPush(b, cfg, limits, false)
+		})
+	}
+}
+
+// BenchmarkIngesterPush_HeadChunks compares the push performance with different TSDB head chunks
+// settings, to support the defaults and the guidance given in the production tips.
+func BenchmarkIngesterPush_HeadChunks(b *testing.B) {
+	limits := defaultLimitsTestConfig()
+
+	for _, samplesPerChunk := range []int{60, tsdb.DefaultSamplesPerChunk, 240} {
+		for _, writeQueueSize := range []int{chunks.DefaultWriteQueueSize, 1000} {
+			b.Run(fmt.Sprintf("samples per chunk: %d, write queue size: %d", samplesPerChunk, writeQueueSize), func(b *testing.B) {
+				cfg := defaultIngesterTestConfig(b)
+				cfg.BlocksStorageConfig.TSDB.HeadChunksSamplesPerChunk = samplesPerChunk
+				cfg.BlocksStorageConfig.TSDB.HeadChunksWriteQueueSize = writeQueueSize
+
+				benchmarkIngesterPush(b, cfg, limits, false)
+			})
+		}
+	}
 }
 
-func benchmarkIngesterPush(b *testing.B, limits validation.Limits, errorsExpected bool) {
+func benchmarkIngesterPush(b *testing.B, cfg Config, limits validation.Limits, errorsExpected bool) {
 	registry := prometheus.NewRegistry()
 	ctx := user.InjectOrgID(context.Background(), userID)
 
 	// Create a mocked ingester
-	cfg := defaultIngesterTestConfig(b)
 	cfg.LifecyclerConfig.JoinAfter = 0
 
 	ingester, err := prepareIngesterWithBlocksStorage(b, cfg, registry)
@@ -2244,6 +2447,7 @@ func Test_Ingester_LabelNames(t *testing.T) {
 
 	tests := map[string]struct {
 		limit    int
+		match    []*labels.Matcher
 		expected []string
 	}{
 		"should return all label names if no limit is set": {
@@ -2253,12 +2457,19 @@ func Test_Ingester_LabelNames(t *testing.T) {
 			limit:    2,
 			expected: expected[:2],
 		},
+		"should return label names of the series matching the matchers": {
+			match:    []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "test_2")},
+			expected: []string{"__name__"},
+		},
 	}
 
 	for testName, testData := range tests {
 		t.Run(testName, func(t *testing.T) {
+			req, err := client.ToLabelNamesRequest(0, 0, testData.limit, testData.match)
+			require.NoError(t, err)
+
 			// Get label names
-			res, err := i.LabelNames(ctx, &client.LabelNamesRequest{Limit: int64(testData.limit)})
+			res, err := i.LabelNames(ctx, req)
 			require.NoError(t, err)
 			assert.ElementsMatch(t, testData.expected, res.LabelNames)
 		})
@@ -2304,21 +2515,35 @@ func Test_Ingester_LabelValues(t *testing.T) {
 	}
 
 	tests := map[string]struct {
-		limit int64
-		match []*labels.Matcher
+		limit    int64
+		match    []*labels.Matcher
+		expected map[string][]string
 	}{
 		"should return all label values if no limit is set": {
-			limit: 0,
+			limit:    0,
+			expected: expected,
 		},
 		"should return limited label values if a limit is set": {
-			limit: 1,
+			limit:    1,
+			expected: expected,
+		},
+		"should return label values of the series matching the matchers": {
+			match: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "status", "500")},
+			expected: map[string][]string{
+				"__name__": {"test_1"},
+				"status":   {"500"},
+				"route":    {"get_user"},
+				"unknown":  {},
+			},
 		},
 	}
 
 	for testName, testData := range tests {
 		t.Run(testName, func(t *testing.T) {
-			for labelName, expectedValues := range expected {
-				req := &client.LabelValuesRequest{LabelName: labelName, Limit: testData.limit}
+			for labelName, expectedValues := range testData.expected {
+				req, err := client.ToLabelValuesRequest(model.LabelName(labelName), 0, 0, int(testData.limit), testData.match)
+				require.NoError(t, err)
+
 				res, err := i.LabelValues(ctx, req)
 				require.NoError(t, err)
 				if testData.limit > 0 && len(expectedValues) > int(testData.limit) {
@@ -3155,6 +3380,66 @@ func TestIngester_QueryStreamManySamplesChunks(t *testing.T) {
 	require.Equal(t, 100000+500000+samplesCount, totalSamples)
 }
 
+func TestIngester_QueryStreamSharded(t *testing.T) {
+	const (
+		numSeries = 50
+		numShards = 3
+	)
+
+	cfg := defaultIngesterTestConfig(t)
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	// Wait until it's ACTIVE.
+	test.Poll(t, 1*time.Second, ring.ACTIVE, func() interface{} {
+		return i.lifecycler.GetState()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	for s := 0; s < numSeries; s++ {
+		lbls := labels.FromStrings(labels.MetricName, "foo", "series", strconv.Itoa(s))
+		_, err := i.Push(ctx, writeRequestSingleSeries(lbls, []cortexpb.Sample{{Value: float64(s), TimestampMs: 1000}}))
+		require.NoError(t, err)
+	}
+
+	for _, shardBy := range []bool{false, true} {
+		t.Run(fmt.Sprintf("shard by labels: %t", shardBy), func(t *testing.T) {
+			seen := map[string]int{}
+
+			for shard := 0; shard < numShards; shard++ {
+				// Shard the query the same way the query-frontend does.
+				query, err := querysharding.InjectShardingInfo("foo", &storepb.ShardInfo{
+					TotalShards: numShards,
+					ShardIndex:  int64(shard),
+					By:          shardBy,
+					Labels:      []string{"series"},
+				})
+				require.NoError(t, err)
+				matchers, err := parser.ParseMetricSelector(query)
+				require.NoError(t, err)
+
+				req, err := client.ToQueryRequest(0, 2000, matchers)
+				require.NoError(t, err)
+
+				s := &mockQueryStreamServer{ctx: ctx}
+				require.NoError(t, i.QueryStream(req, s))
+
+				for _, series := range s.series {
+					seen[cortexpb.FromLabelAdaptersToLabels(series.Labels).String()]++
+				}
+			}
+
+			// Each series must be returned by exactly one shard.
+			require.Len(t, seen, numSeries)
+			for lbls, count := range seen {
+				require.Equal(t, 1, count, lbls)
+			}
+		})
+	}
+}
+
 func writeRequestSingleSeries(lbls labels.Labels, samples []cortexpb.Sample) *cortexpb.WriteRequest {
 	req := &cortexpb.WriteRequest{
 		Source: cortexpb.API,
@@ -3366,10 +3651,11 @@ func TestIngester_OpenExistingTSDBOnStartup(t *testing.T) {
 	t.Parallel()
 
 	tests := map[string]struct {
-		concurrency int
-		setup       func(*testing.T, string)
-		check       func(*testing.T, *Ingester)
-		expectedErr string
+		concurrency      int
+		skipCorruptedWAL bool
+		setup            func(*testing.T, string)
+		check            func(*testing.T, *Ingester)
+		expectedErr      string
 	}{
 		"should not load TSDB if the user directory is empty": {
 			concurrency: 10,
@@ -3472,6 +3758,63 @@ func TestIngester_OpenExistingTSDBOnStartup(t *testing.T) {
 			},
 			expectedErr: "unable to open TSDB for user user2",
 		},
+		"should fail if the WAL checkpoint is corrupted": {
+			concurrency: 10,
+			setup: func(t *testing.T, dir string) {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, "user0", "wal", "checkpoint.00000001"), 0700))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "user0", "wal", "checkpoint.00000001", "00000000"), []byte("corrupted"), 0700))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "user0", "wal", "00000002"), nil, 0700))
+			},
+			check: func(t *testing.T, i *Ingester) {
+				require.Equal(t, 0, len(i.TSDBState.dbs))
+			},
+			expectedErr: "unable to open TSDB for user user0",
+		},
+		"should skip the WAL if the WAL checkpoint is corrupted and skipping corrupted WALs is enabled": {
+			concurrency:      10,
+			skipCorruptedWAL: true,
+			setup: func(t *testing.T, dir string) {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, "user0", "wal", "checkpoint.00000001"), 0700))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "user0", "wal", "checkpoint.00000001", "00000000"), []byte("corrupted"), 0700))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "user0", "wal", "00000002"), nil, 0700))
+
+				// A WAL moved aside by a previous run.
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, "user0", "wal.corrupted-1"), 0700))
+
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, "user1", "dummy"), 0700))
+			},
+			check: func(t *testing.T, i *Ingester) {
+				require.Equal(t, 2, len(i.TSDBState.dbs))
+				require.NotNil(t, i.getTSDB("user0"))
+				require.NotNil(t, i.getTSDB("user1"))
+				require.Equal(t, float64(1), testutil.ToFloat64(i.TSDBState.walSkipped))
+
+				// Only the last corrupted WAL has been kept on disk.
+				matches, err := filepath.Glob(filepath.Join(i.cfg.BlocksStorageConfig.TSDB.Dir, "user0", "wal.corrupted-*"))
+				require.NoError(t, err)
+				require.Len(t, matches, 1)
+				require.DirExists(t, filepath.Join(matches[0], "checkpoint.00000001"))
+				require.Equal(t, float64(len("corrupted")), testutil.ToFloat64(i.metrics.walMovedAsideBytes.WithLabelValues("user0")))
+			},
+		},
+		"should fail if the error is not caused by the WAL even if skipping corrupted WALs is enabled": {
+			concurrency:      10,
+			skipCorruptedWAL: true,
+			setup: func(t *testing.T, dir string) {
+				// Create a fake TSDB on disk with an empty chunks head segment file (it's invalid unless
+				// it's the last one and opening TSDB should fail).
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, "user0", "wal", ""), 0700))
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, "user0", "chunks_head", ""), 0700))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "user0", "chunks_head", "00000001"), nil, 0700))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "user0", "chunks_head", "00000002"), nil, 0700))
+			},
+			check: func(t *testing.T, i *Ingester) {
+				require.Equal(t, 0, len(i.TSDBState.dbs))
+				require.Equal(t, float64(0), testutil.ToFloat64(i.TSDBState.walSkipped))
+				require.DirExists(t, filepath.Join(i.cfg.BlocksStorageConfig.TSDB.Dir, "user0", "wal"))
+			},
+			expectedErr: "unable to open TSDB for user user0",
+		},
 	}
 
 	for name, test := range tests {
@@ -3489,6 +3832,7 @@ func TestIngester_OpenExistingTSDBOnStartup(t *testing.T) {
 			ingesterCfg := defaultIngesterTestConfig(t)
 			ingesterCfg.BlocksStorageConfig.TSDB.Dir = tempDir
 			ingesterCfg.BlocksStorageConfig.TSDB.MaxTSDBOpeningConcurrencyOnStartup = testData.concurrency
+			ingesterCfg.BlocksStorageConfig.TSDB.SkipCorruptedWAL = testData.skipCorruptedWAL
 			ingesterCfg.BlocksStorageConfig.Bucket.Backend = "s3"
 			ingesterCfg.BlocksStorageConfig.Bucket.S3.Endpoint = "localhost"
 
@@ -3512,6 +3856,126 @@ func TestIngester_OpenExistingTSDBOnStartup(t *testing.T) {
 	}
 }
 
+func TestIngester_WALReplayDuplicates(t *testing.T) {
+	tests := map[string]struct {
+		period      time.Duration
+		expectedErr bool
+	}{
+		"should reject samples already replayed from the WAL if disabled": {
+			period:      0,
+			expectedErr: true,
+		},
+		"should ignore samples already replayed from the WAL if enabled": {
+			period:      time.Hour,
+			expectedErr: false,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			dataDir := t.TempDir()
+			ctx := user.InjectOrgID(context.Background(), userID)
+			lbls := labels.Labels{{Name: labels.MetricName, Value: "test"}}
+			otherLbls := labels.Labels{{Name: labels.MetricName, Value: "other"}}
+			now := time.Now()
+
+			cfg := defaultIngesterTestConfig(t)
+			cfg.BlocksStorageConfig.TSDB.WALReplayDuplicatesPeriod = testData.period
+
+			// Push some samples and stop the ingester, keeping them in the WAL only.
+			i, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, defaultLimitsTestConfig(), nil, dataDir, prometheus.NewRegistry(), true)
+			require.NoError(t, err)
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+
+			for _, ts := range []time.Time{now.Add(-4 * time.Hour), now.Add(-time.Minute), now} {
+				req, _ := mockWriteRequest(t, lbls, 1, util.TimeToMillis(ts))
+				_, err = i.Push(ctx, req)
+				require.NoError(t, err)
+			}
+			req, _ := mockWriteRequest(t, otherLbls, 1, util.TimeToMillis(now))
+			_, err = i.Push(ctx, req)
+			require.NoError(t, err)
+			require.NoError(t, services.StopAndAwaitTerminated(context.Background(), i))
+
+			// Restart the ingester, which replays the WAL.
+			reg := prometheus.NewPedanticRegistry()
+			i, err = prepareIngesterWithBlocksStorageAndLimits(t, cfg, defaultLimitsTestConfig(), nil, dataDir, reg, true)
+			require.NoError(t, err)
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+			defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+			// Push the first sample again, like a distributor retrying a push which succeeded before the restart.
+			req, _ = mockWriteRequest(t, lbls, 1, util.TimeToMillis(now.Add(-time.Minute)))
+			_, err = i.Push(ctx, req)
+			if testData.expectedErr {
+				require.Error(t, err)
+				assert.Equal(t, float64(0), testutil.ToFloat64(i.metrics.walReplayIgnoredSamples.WithLabelValues(userID)))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, float64(1), testutil.ToFloat64(i.metrics.walReplayIgnoredSamples.WithLabelValues(userID)))
+			}
+
+			// The oldest sample has been compacted into a block after the WAL replay.
+			require.Len(t, i.getTSDB(userID).db.Blocks(), 1)
+			req, _ = mockWriteRequest(t, lbls, 1, util.TimeToMillis(now.Add(-4*time.Hour)))
+			_, err = i.Push(ctx, req)
+			if testData.expectedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, float64(2), testutil.ToFloat64(i.metrics.walReplayIgnoredSamples.WithLabelValues(userID)))
+			}
+
+			// A sample with the same timestamp but a different value is not a duplicate.
+			req, _ = mockWriteRequest(t, lbls, 2, util.TimeToMillis(now.Add(-time.Minute)))
+			_, err = i.Push(ctx, req)
+			require.Error(t, err)
+
+			// A genuine out-of-order sample for another series is still rejected and counted as discarded.
+			discarded := testutil.ToFloat64(i.validateMetrics.DiscardedSamples.WithLabelValues(sampleOutOfOrder, userID))
+			req, _ = mockWriteRequest(t, otherLbls, 1, util.TimeToMillis(now.Add(-30*time.Second)))
+			_, err = i.Push(ctx, req)
+			require.Error(t, err)
+			assert.Equal(t, discarded+1, testutil.ToFloat64(i.validateMetrics.DiscardedSamples.WithLabelValues(sampleOutOfOrder, userID)))
+
+			// Newer samples are ingested as usual.
+			req, _ = mockWriteRequest(t, lbls, 1, util.TimeToMillis(now.Add(time.Second)))
+			_, err = i.Push(ctx, req)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestIngester_NearOutOfBoundsSamples(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), userID)
+	now := time.Now()
+
+	cfg := defaultIngesterTestConfig(t)
+	cfg.NearOutOfBoundsPeriod = 10 * time.Minute
+
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	push := func(name string, ts time.Time) error {
+		req, _ := mockWriteRequest(t, labels.Labels{{Name: labels.MetricName, Value: name}}, 1, util.TimeToMillis(ts))
+		_, err := i.Push(ctx, req)
+		return err
+	}
+
+	// The head accepts samples up to half of the block range (1h) older than its max time.
+	require.NoError(t, push("series_1", now))
+	require.NoError(t, push("series_2", now.Add(-30*time.Minute)))
+	require.NoError(t, push("series_3", now.Add(-55*time.Minute)))
+	require.Error(t, push("series_4", now.Add(-2*time.Hour)))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(i.metrics.nearOutOfBoundsSamples.WithLabelValues(userID)))
+
+	i.updateOldestAcceptedTimestamps()
+	assert.Equal(t, float64(util.TimeToMillis(now.Add(-time.Hour)))/1000, testutil.ToFloat64(i.metrics.oldestAcceptedTimestamp.WithLabelValues(userID)))
+}
+
 func TestIngester_shipBlocks(t *testing.T) {
 	testCases := map[string]struct {
 		ss                   bucketindex.Status
@@ -3619,6 +4083,169 @@ func TestIngester_dontShipBlocksWhenTenantDeletionMarkerIsPresent(t *testing.T)
 	require.Equal(t, tsdbTenantMarkedForDeletion, i.closeAndDeleteUserTSDBIfIdle(userID))
 }
 
+func TestIngester_PerTenantBlockDurationAndRetention(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	tenantLimits := newMockTenantLimits(map[string]*validation.Limits{
+		"short-blocks": func() *validation.Limits {
+			l := defaultLimitsTestConfig()
+			l.TSDBBlockDuration = model.Duration(30 * time.Minute)
+			l.TSDBRetentionPeriod = model.Duration(3 * time.Hour)
+			return &l
+		}(),
+		"short-retention": func() *validation.Limits {
+			l := defaultLimitsTestConfig()
+			l.TSDBRetentionPeriod = model.Duration(time.Hour)
+			return &l
+		}(),
+		"invalid-blocks": func() *validation.Limits {
+			l := defaultLimitsTestConfig()
+			l.TSDBBlockDuration = model.Duration(50 * time.Minute)
+			return &l
+		}(),
+		"long-blocks": func() *validation.Limits {
+			l := defaultLimitsTestConfig()
+			l.TSDBBlockDuration = model.Duration(4 * time.Hour)
+			return &l
+		}(),
+	})
+
+	cfg := defaultIngesterTestConfig(t)
+	cfg.BlocksStorageConfig.TSDB.BlockRanges = cortex_tsdb.DurationList{2 * time.Hour}
+	cfg.BlocksStorageConfig.TSDB.Retention = 6 * time.Hour
+
+	i, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, limits, tenantLimits, "", prometheus.NewRegistry(), true)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		expectedBlockDuration   time.Duration
+		expectedRetentionPeriod time.Duration
+	}{
+		"short-blocks":    {expectedBlockDuration: 30 * time.Minute, expectedRetentionPeriod: 3 * time.Hour},
+		"short-retention": {expectedBlockDuration: 2 * time.Hour, expectedRetentionPeriod: 2 * time.Hour},
+		"invalid-blocks":  {expectedBlockDuration: 2 * time.Hour, expectedRetentionPeriod: 6 * time.Hour},
+		"long-blocks":     {expectedBlockDuration: 4 * time.Hour, expectedRetentionPeriod: 6 * time.Hour},
+		"default":         {expectedBlockDuration: 2 * time.Hour, expectedRetentionPeriod: 6 * time.Hour},
+	}
+
+	for userID, testData := range tests {
+		t.Run(userID, func(t *testing.T) {
+			db, err := i.createTSDB(userID)
+			require.NoError(t, err)
+			defer db.Close() //nolint:errcheck
+
+			assert.Equal(t, testData.expectedBlockDuration.Milliseconds(), db.blockDuration)
+			assert.Equal(t, testData.expectedRetentionPeriod.Milliseconds(), db.blockRetentionPeriod)
+		})
+	}
+}
+
+func TestIngester_PerTenantBlockDurationShouldShipLevel1BlocksOnly(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	tenantLimits := newMockTenantLimits(map[string]*validation.Limits{
+		userID: func() *validation.Limits {
+			l := defaultLimitsTestConfig()
+			l.TSDBBlockDuration = model.Duration(30 * time.Minute)
+			return &l
+		}(),
+	})
+
+	cfg := defaultIngesterTestConfig(t)
+	cfg.LifecyclerConfig.JoinAfter = 0
+	cfg.BlocksStorageConfig.TSDB.BlockRanges = cortex_tsdb.DurationList{2 * time.Hour}
+
+	i, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, limits, tenantLimits, "", prometheus.NewRegistry(), true)
+	require.NoError(t, err)
+
+	bucket := objstore.NewInMemBucket()
+	i.TSDBState.bucket = bucket
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	test.Poll(t, 1*time.Second, ring.ACTIVE, func() interface{} {
+		return i.lifecycler.GetState()
+	})
+
+	// Push samples spanning several block ranges.
+	start := time.Now().Add(-5 * time.Hour).Truncate(2 * time.Hour)
+	for ts := start; ts.Before(start.Add(4 * time.Hour)); ts = ts.Add(10 * time.Minute) {
+		pushSingleSampleAtTime(t, i, ts.UnixMilli())
+	}
+
+	// Cut the blocks from the head, then run the regular TSDB compaction,
+	// which must not compact the blocks locally.
+	i.compactBlocks(context.Background(), true, nil)
+	db := i.getTSDB(userID)
+	require.NotNil(t, db)
+	require.NoError(t, db.Compact(context.Background()))
+	i.shipBlocks(context.Background(), nil)
+
+	blocks := db.Blocks()
+	require.Len(t, blocks, 8)
+	for _, b := range blocks {
+		assert.Equal(t, 1, b.Meta().Compaction.Level)
+		assert.LessOrEqual(t, b.Meta().MaxTime-b.Meta().MinTime, (30 * time.Minute).Milliseconds())
+
+		exists, err := bucket.Exists(context.Background(), path.Join(userID, b.Meta().ULID.String(), metadata.MetaFilename))
+		require.NoError(t, err)
+		assert.True(t, exists)
+	}
+}
+
+func TestIngester_ShipHandler(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.LifecyclerConfig.JoinAfter = 0
+	cfg.BlocksStorageConfig.TSDB.ShipConcurrency = 2
+
+	reg := prometheus.NewRegistry()
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, reg)
+	require.NoError(t, err)
+
+	// Use in-memory bucket.
+	bucket := objstore.NewInMemBucket()
+	i.TSDBState.bucket = bucket
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	// Wait until it's ACTIVE
+	test.Poll(t, 1*time.Second, ring.ACTIVE, func() interface{} {
+		return i.lifecycler.GetState()
+	})
+
+	t.Run("should fail if no tenant is specified", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		i.ShipHandler(rec, httptest.NewRequest("POST", "/ingester/ship", nil))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	pushSingleSampleWithMetadata(t, i)
+	i.compactBlocks(context.Background(), true, nil)
+
+	db := i.getTSDB(userID)
+	require.NotNil(t, db)
+	require.Equal(t, 1, db.getUnshippedBlocksCount())
+	require.Zero(t, len(bucket.Objects()))
+
+	rec := httptest.NewRecorder()
+	i.ShipHandler(rec, httptest.NewRequest("POST", "/ingester/ship?tenant="+userID+"&tenant=unknown", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var statuses []shipperStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &statuses))
+	require.Len(t, statuses, 2)
+	assert.Equal(t, userID, statuses[0].Tenant)
+	assert.Equal(t, 0, statuses[0].PendingBlocks)
+	assert.NotZero(t, statuses[0].LastSuccessfulUploadTimestamp)
+	assert.Equal(t, shipperStatus{Tenant: "unknown"}, statuses[1])
+	assert.NotZero(t, len(bucket.Objects()))
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_ingester_shipper_pending_blocks Number of TSDB blocks not shipped to the storage yet, per user.
+		# TYPE cortex_ingester_shipper_pending_blocks gauge
+		cortex_ingester_shipper_pending_blocks{user="1"} 0
+	`), "cortex_ingester_shipper_pending_blocks"))
+}
+
 func TestIngester_seriesCountIsCorrectAfterClosingTSDBForDeletedTenant(t *testing.T) {
 	cfg := defaultIngesterTestConfig(t)
 	cfg.LifecyclerConfig.JoinAfter = 0
@@ -3949,6 +4576,32 @@ func TestIngester_flushing(t *testing.T) {
 			},
 		},
 
+		"shutdownHandlerReportsBlocksNotShipped": {
+			setupIngester: func(cfg *Config) {
+				cfg.BlocksStorageConfig.TSDB.FlushBlocksOnShutdown = false
+				cfg.BlocksStorageConfig.TSDB.FlushBlocksOnShutdownTimeout = time.Second
+				cfg.BlocksStorageConfig.TSDB.KeepUserTSDBOpenOnShutdown = true
+			},
+
+			action: func(t *testing.T, i *Ingester, reg *prometheus.Registry) {
+				pushSingleSampleWithMetadata(t, i)
+
+				// Mock the shipper to fail uploading the blocks.
+				m := &shipperMock{}
+				m.On("Sync", mock.Anything).Return(0, errors.New("upload failed"))
+				i.getTSDB(userID).shipper = m
+
+				resp := httptest.NewRecorder()
+				i.ShutdownHandler(resp, httptest.NewRequest("POST", "/shutdown", nil))
+				require.Equal(t, http.StatusInternalServerError, resp.Code)
+				require.Contains(t, resp.Body.String(), fmt.Sprintf("blocks not shipped for tenants: %s (1 blocks)", userID))
+
+				// Shipping has been retried until the timeout expired.
+				verifyCompactedHead(t, i, true)
+				require.Greater(t, len(m.Calls), 1)
+			},
+		},
+
 		"flushHandler": {
 			setupIngester: func(cfg *Config) {
 				cfg.BlocksStorageConfig.TSDB.FlushBlocksOnShutdown = false
@@ -3965,7 +4618,9 @@ func TestIngester_flushing(t *testing.T) {
 				`), "cortex_ingester_shipper_uploads_total"))
 
 				// Using wait=true makes this a synchronous call.
-				i.FlushHandler(httptest.NewRecorder(), httptest.NewRequest("POST", "/flush?wait=true", nil))
+				resp := httptest.NewRecorder()
+				i.FlushHandler(resp, httptest.NewRequest("POST", "/flush?wait=true", nil))
+				require.Equal(t, http.StatusNoContent, resp.Code)
 
 				verifyCompactedHead(t, i, true)
 				require.NoError(t, testutil.GatherAndCompare(reg, bytes.NewBufferString(`
@@ -3976,6 +4631,38 @@ func TestIngester_flushing(t *testing.T) {
 			},
 		},
 
+		"flushHandlerWithWaitReportsBlocksNotShipped": {
+			setupIngester: func(cfg *Config) {
+				cfg.BlocksStorageConfig.TSDB.FlushBlocksOnShutdown = false
+			},
+
+			action: func(t *testing.T, i *Ingester, reg *prometheus.Registry) {
+				pushSingleSampleWithMetadata(t, i)
+
+				// Mock the shipper to fail uploading the blocks.
+				m := &shipperMock{}
+				m.On("Sync", mock.Anything).Return(0, errors.New("upload failed"))
+				i.getTSDB(userID).shipper = m
+
+				resp := httptest.NewRecorder()
+				i.FlushHandler(resp, httptest.NewRequest("POST", "/flush?wait=true&"+tenantParam+"="+userID, nil))
+				require.Equal(t, http.StatusInternalServerError, resp.Code)
+				require.Contains(t, resp.Body.String(), fmt.Sprintf("blocks not shipped for tenants: %s (1 blocks)", userID))
+
+				verifyCompactedHead(t, i, true)
+			},
+		},
+
+		"flushHandlerWithWaitWhenIngesterNotRunning": {
+			action: func(t *testing.T, i *Ingester, reg *prometheus.Registry) {
+				require.NoError(t, services.StopAndAwaitTerminated(context.Background(), i))
+
+				resp := httptest.NewRecorder()
+				i.FlushHandler(resp, httptest.NewRequest("POST", "/flush?wait=true", nil))
+				require.Equal(t, http.StatusServiceUnavailable, resp.Code)
+			},
+		},
+
 		"flushHandlerWithListOfTenants": {
 			setupIngester: func(cfg *Config) {
 				cfg.BlocksStorageConfig.TSDB.FlushBlocksOnShutdown = false
@@ -4361,6 +5048,63 @@ func Test_Ingester_AllUserStatsHandler(t *testing.T) {
 	assert.ElementsMatch(t, expect, resp)
 }
 
+func Test_Ingester_TenantsUsageHandler(t *testing.T) {
+	i, err := prepareIngesterWithBlocksStorage(t, defaultIngesterTestConfig(t), prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	// Wait until it's ACTIVE
+	test.Poll(t, 1*time.Second, ring.ACTIVE, func() interface{} {
+		return i.lifecycler.GetState()
+	})
+
+	now := time.Now()
+	for _, series := range []struct {
+		user string
+		lbls labels.Labels
+	}{
+		{"user-1", labels.Labels{{Name: labels.MetricName, Value: "test_1"}, {Name: "status", Value: "200"}}},
+		{"user-1", labels.Labels{{Name: labels.MetricName, Value: "test_1"}, {Name: "status", Value: "500"}}},
+		{"user-2", labels.Labels{{Name: labels.MetricName, Value: "test_1"}}},
+	} {
+		ctx := user.InjectOrgID(context.Background(), series.user)
+		req, _ := mockWriteRequest(t, series.lbls, 1, util.TimeToMillis(now))
+		_, err := i.Push(ctx, req)
+		require.NoError(t, err)
+	}
+
+	// force update statistics
+	for _, db := range i.TSDBState.dbs {
+		db.ingestedAPISamples.Tick()
+		db.ingestedRuleSamples.Tick()
+	}
+
+	response := httptest.NewRecorder()
+	i.TenantsUsageHandler(response, httptest.NewRequest("GET", "/ingester/tenants_usage", nil))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var resp []TenantUsage
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &resp))
+	require.Len(t, resp, 2)
+
+	// Tenants are sorted by number of in-memory series.
+	assert.Equal(t, "user-1", resp[0].UserID)
+	assert.Equal(t, uint64(2), resp[0].MemorySeries)
+	assert.Equal(t, 0.13333333333333333, resp[0].IngestionRate)
+	assert.Equal(t, "user-2", resp[1].UserID)
+	assert.Equal(t, uint64(1), resp[1].MemorySeries)
+
+	for _, usage := range resp {
+		assert.Greater(t, usage.WALBytes, int64(0))
+		assert.Equal(t, int64(0), usage.PushesInFlight)
+
+		lastPush, err := time.Parse(time.RFC3339, usage.LastPush)
+		require.NoError(t, err)
+		assert.WithinDuration(t, now, lastPush, time.Minute)
+	}
+}
+
 func TestIngesterCompactIdleBlock(t *testing.T) {
 	cfg := defaultIngesterTestConfig(t)
 	cfg.LifecyclerConfig.JoinAfter = 0
@@ -4440,6 +5184,76 @@ func TestIngesterCompactIdleBlock(t *testing.T) {
     `), memSeriesCreatedTotalName, memSeriesRemovedTotalName, "cortex_ingester_memory_users"))
 }
 
+func TestIngesterCompactionTenantJitter(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.LifecyclerConfig.JoinAfter = 0
+	cfg.BlocksStorageConfig.TSDB.BlockRanges = cortex_tsdb.DurationList{2 * time.Hour}
+	cfg.BlocksStorageConfig.TSDB.HeadCompactionInterval = 1 * time.Hour // Long enough to not be reached during the test.
+	cfg.BlocksStorageConfig.TSDB.HeadCompactionIdleTimeout = 0
+	cfg.BlocksStorageConfig.TSDB.HeadCompactionTenantJitter = 1 * time.Hour
+
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	t.Cleanup(func() {
+		_ = services.StopAndAwaitTerminated(context.Background(), i)
+	})
+
+	test.Poll(t, 1*time.Second, ring.ACTIVE, func() interface{} {
+		return i.lifecycler.GetState()
+	})
+
+	blockDuration := (2 * time.Hour).Milliseconds()
+	delay := i.getHeadCompactionDelay(userID, blockDuration)
+	require.Greater(t, delay, int64(0))
+	require.Less(t, delay, time.Hour.Milliseconds())
+	require.Equal(t, delay, i.getHeadCompactionDelay(userID, blockDuration), "the delay must be stable")
+
+	// Make the head compactable, but not for longer than the tenant delay.
+	compactableSpan := (3 * time.Hour).Milliseconds()
+	pushSingleSampleAtTime(t, i, 0)
+	pushSingleSampleAtTime(t, i, compactableSpan+1)
+
+	db := i.getTSDB(userID)
+	require.NotNil(t, db)
+	require.False(t, db.headCompactionDelayElapsed())
+
+	i.compactBlocks(context.Background(), false, nil)
+	require.Len(t, db.Blocks(), 0)
+
+	// Once the delay is elapsed, the head is compacted.
+	pushSingleSampleAtTime(t, i, compactableSpan+delay+1)
+	require.True(t, db.headCompactionDelayElapsed())
+
+	i.compactBlocks(context.Background(), false, nil)
+	require.Len(t, db.Blocks(), 1)
+}
+
+func TestIngester_getHeadCompactionDelay(t *testing.T) {
+	newIngester := func(instanceID string, jitter time.Duration) *Ingester {
+		cfg := defaultIngesterTestConfig(t)
+		cfg.LifecyclerConfig.ID = instanceID
+		cfg.BlocksStorageConfig.TSDB.HeadCompactionTenantJitter = jitter
+		return &Ingester{cfg: cfg}
+	}
+
+	blockDuration := (2 * time.Hour).Milliseconds()
+
+	// The replicas of a tenant don't compact at the same time.
+	assert.NotEqual(t,
+		newIngester("ingester-1", time.Hour).getHeadCompactionDelay(userID, blockDuration),
+		newIngester("ingester-2", time.Hour).getHeadCompactionDelay(userID, blockDuration))
+
+	// The jitter is clamped to half of the tenant's block duration.
+	for _, instanceID := range []string{"ingester-1", "ingester-2", "ingester-3"} {
+		for _, tenantID := range []string{"user-1", "user-2", "user-3"} {
+			assert.Less(t, newIngester(instanceID, time.Hour).getHeadCompactionDelay(tenantID, (30*time.Minute).Milliseconds()), (15 * time.Minute).Milliseconds())
+		}
+	}
+
+	assert.Equal(t, int64(0), newIngester("ingester-1", 0).getHeadCompactionDelay(userID, blockDuration))
+}
+
 func TestIngesterCompactAndCloseIdleTSDB(t *testing.T) {
 	cfg := defaultIngesterTestConfig(t)
 	cfg.LifecyclerConfig.JoinAfter = 0
@@ -5479,6 +6293,7 @@ func Test_Ingester_ModeHandler(t *testing.T) {
 		mode             string
 		expectedState    ring.InstanceState
 		expectedResponse int
+		expectedBody     string
 		expectedIsReady  bool
 	}{
 		"should change to READONLY mode": {
@@ -5520,6 +6335,25 @@ func Test_Ingester_ModeHandler(t *testing.T) {
 			requestUrl:       "/mode?mode=NotSupported",
 			expectedState:    ring.ACTIVE,
 			expectedResponse: http.StatusBadRequest,
+			expectedBody:     "valid modes are: ACTIVE, READONLY",
+			expectedIsReady:  true,
+		},
+		"should fail without mode": {
+			method:           "GET",
+			initialState:     ring.ACTIVE,
+			requestUrl:       "/mode",
+			expectedState:    ring.ACTIVE,
+			expectedResponse: http.StatusBadRequest,
+			expectedBody:     "valid modes are: ACTIVE, READONLY",
+			expectedIsReady:  true,
+		},
+		"should fail with an empty mode": {
+			method:           "POST",
+			initialState:     ring.READONLY,
+			requestUrl:       "/mode?mode=",
+			expectedState:    ring.READONLY,
+			expectedResponse: http.StatusBadRequest,
+			expectedBody:     "valid modes are: ACTIVE, READONLY",
 			expectedIsReady:  true,
 		},
 		"should maintain in readonly": {
@@ -5587,6 +6421,7 @@ func Test_Ingester_ModeHandler(t *testing.T) {
 			i.ModeHandler(response, request)
 
 			require.Equal(t, testData.expectedResponse, response.Code)
+			require.Contains(t, response.Body.String(), testData.expectedBody)
 			require.Equal(t, testData.expectedState, i.lifecycler.GetState())
 			if testData.expectedIsReady {
 				// Wait for instance to own tokens
@@ -5601,6 +6436,51 @@ func Test_Ingester_ModeHandler(t *testing.T) {
 	}
 }
 
+func Test_Ingester_ModeHandler_Drain(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.LifecyclerConfig.MinReadyDuration = 0
+	cfg.BlocksStorageConfig.TSDB.ShipInterval = 1 * time.Minute // Long enough to not be reached during the test.
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	// Wait until it's ACTIVE
+	test.Poll(t, 1*time.Second, ring.ACTIVE, func() interface{} {
+		return i.lifecycler.GetState()
+	})
+
+	pushSingleSampleWithMetadata(t, i)
+
+	getStatus := func() modeStatus {
+		response := httptest.NewRecorder()
+		i.ModeStatusHandler(response, httptest.NewRequest("GET", "/mode/status", nil))
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var st modeStatus
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &st))
+		return st
+	}
+
+	require.Equal(t, modeStatus{Mode: "ACTIVE", HeadSeries: 1}, getStatus())
+
+	// Draining is only supported when switching to READONLY.
+	response := httptest.NewRecorder()
+	i.ModeHandler(response, httptest.NewRequest("POST", "/mode?mode=ACTIVE&drain=true", nil))
+	require.Equal(t, http.StatusBadRequest, response.Code)
+
+	response = httptest.NewRecorder()
+	i.ModeHandler(response, httptest.NewRequest("POST", "/mode?mode=READONLY&drain=true", nil))
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Equal(t, ring.READONLY, i.lifecycler.GetState())
+
+	// The head is compacted and the block shipped in background.
+	test.Poll(t, 5*time.Second, modeStatus{Mode: "READONLY", Drained: true}, func() interface{} {
+		return getStatus()
+	})
+	verifyCompactedHead(t, i, true)
+}
+
 func TestIngester_UserTSDB_BlocksToDelete(t *testing.T) {
 	tempDir := t.TempDir()
 	db, err := tsdb.Open(tempDir, log.NewNopLogger(), prometheus.NewPedanticRegistry(), &tsdb.Options{}, nil)
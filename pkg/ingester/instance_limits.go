@@ -1,18 +1,42 @@
 package ingester
 
-import "github.com/pkg/errors"
+import (
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
 
 var (
 	// We don't include values in the message to avoid leaking Cortex cluster configuration to users.
-	errMaxSamplesPushRateLimitReached = errors.New("cannot push more samples: ingester's samples push rate limit reached")
-	errMaxUsersLimitReached           = errors.New("cannot create TSDB: ingesters's max tenants limit reached")
-	errMaxSeriesLimitReached          = errors.New("cannot add series: ingesters's max series limit reached")
-	errTooManyInflightPushRequests    = errors.New("cannot push: too many inflight push requests in ingester")
+	errMaxSamplesPushRateLimitReached = newInstanceLimitError("cannot push more samples: ingester's samples push rate limit reached")
+	errMaxUsersLimitReached           = newInstanceLimitError("cannot create TSDB: ingesters's max tenants limit reached")
+	errMaxSeriesLimitReached          = newInstanceLimitError("cannot add series: ingesters's max series limit reached")
+	errTooManyInflightPushRequests    = newInstanceLimitError("cannot push: too many inflight push requests in ingester")
 	errTooManyInflightQueryRequests   = errors.New("cannot push: too many inflight query requests in ingester")
 )
 
+// instanceLimitError is returned when a push is rejected because of an ingester instance limit.
+// It is sent over the wire with the codes.ResourceExhausted gRPC code, so that the distributor
+// can tell it apart from other failures and reply with 429 to the client.
+type instanceLimitError struct {
+	msg string
+}
+
+func newInstanceLimitError(msg string) error {
+	return &instanceLimitError{msg: msg}
+}
+
+func (e *instanceLimitError) Error() string {
+	return e.msg
+}
+
+// GRPCStatus implements the interface used by the gRPC status package to convert errors.
+func (e *instanceLimitError) GRPCStatus() *status.Status {
+	return status.New(codes.ResourceExhausted, e.msg)
+}
+
 // InstanceLimits describes limits used by ingester. Reaching any of these will result in Push method to return
-// (internal) error.
+// (internal) error. Limits can be overridden at runtime via the runtime config, without restarting the ingester.
 type InstanceLimits struct {
 	MaxIngestionRate         float64 `yaml:"max_ingestion_rate"`
 	MaxInMemoryTenants       int64   `yaml:"max_tenants"`
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"gopkg.in/yaml.v2"
 )
 
@@ -29,3 +31,22 @@ max_tenants: 50000
 	require.Equal(t, int64(40), l.MaxInflightPushRequests)  // default value
 	require.Equal(t, int64(50), l.MaxInflightQueryRequests) // default value
 }
+
+func TestInstanceLimitErrorsHaveResourceExhaustedCode(t *testing.T) {
+	for _, err := range []error{
+		errMaxSamplesPushRateLimitReached,
+		errMaxUsersLimitReached,
+		errMaxSeriesLimitReached,
+		errTooManyInflightPushRequests,
+		wrapWithUser(errMaxSeriesLimitReached, "user-1"),
+	} {
+		s, ok := status.FromError(err)
+		require.True(t, ok, err.Error())
+		require.Equal(t, codes.ResourceExhausted, s.Code(), err.Error())
+		require.Equal(t, err.Error(), s.Message())
+	}
+
+	s, ok := status.FromError(wrapWithUser(errMaxUsersLimitReached, "user-1"))
+	require.True(t, ok)
+	require.Equal(t, "user=user-1: "+errMaxUsersLimitReached.Error(), s.Message())
+}
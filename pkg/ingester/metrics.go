@@ -43,9 +43,24 @@ type ingesterMetrics struct {
 	memSeriesRemovedTotal   *prometheus.CounterVec
 	memMetadataRemovedTotal *prometheus.CounterVec
 
-	activeSeriesPerUser *prometheus.GaugeVec
-	limitsPerLabelSet   *prometheus.GaugeVec
-	usagePerLabelSet    *prometheus.GaugeVec
+	activeSeriesPerUser        *prometheus.GaugeVec
+	activeSeriesCustomTrackers *prometheus.GaugeVec
+	limitsPerLabelSet          *prometheus.GaugeVec
+	usagePerLabelSet           *prometheus.GaugeVec
+
+	// WAL replay diagnostics per user.
+	walReplayDurationPerUser *prometheus.GaugeVec
+	walReplaySeriesPerUser   *prometheus.GaugeVec
+	walReplayIgnoredSamples  *prometheus.CounterVec
+	walMovedAsideBytes       *prometheus.GaugeVec
+
+	// Shipper status per user.
+	shipperLastSuccessfulUpload *prometheus.GaugeVec
+	shipperPendingBlocks        *prometheus.GaugeVec
+
+	// Accepted head time range per user.
+	oldestAcceptedTimestamp *prometheus.GaugeVec
+	nearOutOfBoundsSamples  *prometheus.CounterVec
 
 	// Global limit metrics
 	maxUsersGauge           prometheus.GaugeFunc
@@ -233,11 +248,57 @@ func newIngesterMetrics(r prometheus.Registerer,
 			Help: "Current usage per user and labelset.",
 		}, []string{"user", "limit", "labelset"}),
 
+		walReplayDurationPerUser: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_ingester_tsdb_wal_replay_user_duration_seconds",
+			Help: "The time it took to open and replay the TSDB WAL of a user on startup.",
+		}, []string{"user"}),
+
+		walReplaySeriesPerUser: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_ingester_tsdb_wal_replay_user_series",
+			Help: "The number of in-memory series of a user after the TSDB WAL replay on startup.",
+		}, []string{"user"}),
+
+		walMovedAsideBytes: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_ingester_tsdb_wal_moved_aside_bytes",
+			Help: "Size in bytes of the TSDB WAL of a user moved aside on disk because it could not be replayed.",
+		}, []string{"user"}),
+
+		walReplayIgnoredSamples: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_ingester_tsdb_wal_replay_ignored_samples_total",
+			Help: "The total number of pushed samples ignored because already replayed from the TSDB WAL on startup.",
+		}, []string{"user"}),
+
+		shipperLastSuccessfulUpload: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_ingester_shipper_last_successful_upload_timestamp_seconds",
+			Help: "Unix timestamp of the last shipper synchronisation completed without errors, per user.",
+		}, []string{"user"}),
+
+		shipperPendingBlocks: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_ingester_shipper_pending_blocks",
+			Help: "Number of TSDB blocks not shipped to the storage yet, per user.",
+		}, []string{"user"}),
+
+		oldestAcceptedTimestamp: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_ingester_oldest_accepted_timestamp_seconds",
+			Help: "Unix timestamp of the oldest sample accepted by the TSDB head, per user. Older samples are rejected as out of bounds.",
+		}, []string{"user"}),
+
+		nearOutOfBoundsSamples: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_ingester_near_out_of_bounds_samples_total",
+			Help: "The total number of ingested samples whose timestamp is close to the oldest timestamp accepted by the TSDB head, per user.",
+		}, []string{"user"}),
+
 		// Not registered automatically, but only if activeSeriesEnabled is true.
 		activeSeriesPerUser: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "cortex_ingester_active_series",
 			Help: "Number of currently active series per user.",
 		}, []string{"user"}),
+
+		// Not registered automatically, but only if activeSeriesEnabled is true.
+		activeSeriesCustomTrackers: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_ingester_active_series_custom_tracker",
+			Help: "Number of currently active series matching a custom tracker configured for the user.",
+		}, []string{"user", "name"}),
 	}
 
 	if postingsCacheEnabled && r != nil {
@@ -246,6 +307,7 @@ func newIngesterMetrics(r prometheus.Registerer,
 
 	if activeSeriesEnabled && r != nil {
 		r.MustRegister(m.activeSeriesPerUser)
+		r.MustRegister(m.activeSeriesCustomTrackers)
 	}
 
 	if createMetricsConflictingWithTSDB {
@@ -267,6 +329,15 @@ func (m *ingesterMetrics) deletePerUserMetrics(userID string) {
 	m.memMetadataCreatedTotal.DeleteLabelValues(userID)
 	m.memMetadataRemovedTotal.DeleteLabelValues(userID)
 	m.activeSeriesPerUser.DeleteLabelValues(userID)
+	m.activeSeriesCustomTrackers.DeletePartialMatch(prometheus.Labels{"user": userID})
+	m.walReplayDurationPerUser.DeleteLabelValues(userID)
+	m.walReplaySeriesPerUser.DeleteLabelValues(userID)
+	m.walReplayIgnoredSamples.DeleteLabelValues(userID)
+	m.walMovedAsideBytes.DeleteLabelValues(userID)
+	m.shipperLastSuccessfulUpload.DeleteLabelValues(userID)
+	m.shipperPendingBlocks.DeleteLabelValues(userID)
+	m.oldestAcceptedTimestamp.DeleteLabelValues(userID)
+	m.nearOutOfBoundsSamples.DeleteLabelValues(userID)
 
 	if m.memSeriesCreatedTotal != nil {
 		m.memSeriesCreatedTotal.DeleteLabelValues(userID)
@@ -306,6 +377,7 @@ type tsdbMetrics struct {
 	tsdbChunksRemovedTotal             *prometheus.Desc
 	tsdbMmapChunkCorruptionTotal       *prometheus.Desc
 	tsdbChunkwriteQueueOperationsTotal *prometheus.Desc
+	tsdbHeadChunksStorageSize          *prometheus.Desc
 	tsdbSamplesAppended                *prometheus.Desc
 	// Although there is an existing sample-out-of-order discarded samples metric, some samples can still
 	// be dropped silently due to OOO at commit phase, and it doesn't increment the discarded samples metric.
@@ -442,6 +514,10 @@ func newTSDBMetrics(r prometheus.Registerer) *tsdbMetrics {
 			"cortex_ingester_tsdb_chunk_write_queue_operations_total",
 			"Number of currently tsdb chunk write queues.",
 			[]string{"user", "operation"}, nil),
+		tsdbHeadChunksStorageSize: prometheus.NewDesc(
+			"cortex_ingester_tsdb_head_chunks_storage_size_bytes",
+			"Size of the memory-mapped TSDB head chunks on disk.",
+			[]string{"user"}, nil),
 		tsdbDataTotalReplayDuration: prometheus.NewDesc(
 			"cortex_ingester_tsdb_data_replay_duration_seconds",
 			"Time taken to replay the tsdb data on disk.",
@@ -572,6 +648,7 @@ func (sm *tsdbMetrics) Describe(out chan<- *prometheus.Desc) {
 	out <- sm.tsdbChunksRemovedTotal
 	out <- sm.tsdbMmapChunkCorruptionTotal
 	out <- sm.tsdbChunkwriteQueueOperationsTotal
+	out <- sm.tsdbHeadChunksStorageSize
 	out <- sm.tsdbDataTotalReplayDuration
 	out <- sm.tsdbLoadedBlocks
 	out <- sm.tsdbSymbolTableSize
@@ -629,6 +706,7 @@ func (sm *tsdbMetrics) Collect(out chan<- prometheus.Metric) {
 	data.SendSumOfCountersPerUser(out, sm.tsdbChunksRemovedTotal, "prometheus_tsdb_head_chunks_removed_total")
 	data.SendSumOfCounters(out, sm.tsdbMmapChunkCorruptionTotal, "prometheus_tsdb_mmap_chunk_corruptions_total")
 	data.SendSumOfCountersPerUserWithLabels(out, sm.tsdbChunkwriteQueueOperationsTotal, "prometheus_tsdb_chunk_write_queue_operations_total", "operation")
+	data.SendSumOfGaugesPerUser(out, sm.tsdbHeadChunksStorageSize, "prometheus_tsdb_head_chunks_storage_size_bytes")
 	data.SendSumOfGaugesPerUser(out, sm.tsdbDataTotalReplayDuration, "prometheus_tsdb_data_replay_duration_seconds")
 	data.SendSumOfGauges(out, sm.tsdbLoadedBlocks, "prometheus_tsdb_blocks_loaded")
 	data.SendSumOfGaugesPerUser(out, sm.tsdbSymbolTableSize, "prometheus_tsdb_symbol_table_size_bytes")
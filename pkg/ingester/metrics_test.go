@@ -310,6 +310,12 @@ func TestTSDBMetrics(t *testing.T) {
 			cortex_ingester_tsdb_head_chunks_created_total{user="user2"} 1973101
 			cortex_ingester_tsdb_head_chunks_created_total{user="user3"} 22977
 
+			# HELP cortex_ingester_tsdb_head_chunks_storage_size_bytes Size of the memory-mapped TSDB head chunks on disk.
+			# TYPE cortex_ingester_tsdb_head_chunks_storage_size_bytes gauge
+			cortex_ingester_tsdb_head_chunks_storage_size_bytes{user="user1"} 25282560
+			cortex_ingester_tsdb_head_chunks_storage_size_bytes{user="user2"} 175691776
+			cortex_ingester_tsdb_head_chunks_storage_size_bytes{user="user3"} 2045952
+
 			# HELP cortex_ingester_tsdb_head_chunks_removed_total Total number of series removed in the TSDB head.
 			# TYPE cortex_ingester_tsdb_head_chunks_removed_total counter
 			cortex_ingester_tsdb_head_chunks_removed_total{user="user1"} 296280
@@ -563,6 +569,11 @@ func TestTSDBMetricsWithRemoval(t *testing.T) {
 			cortex_ingester_tsdb_head_chunks_created_total{user="user1"} 283935
 			cortex_ingester_tsdb_head_chunks_created_total{user="user2"} 1973101
 
+			# HELP cortex_ingester_tsdb_head_chunks_storage_size_bytes Size of the memory-mapped TSDB head chunks on disk.
+			# TYPE cortex_ingester_tsdb_head_chunks_storage_size_bytes gauge
+			cortex_ingester_tsdb_head_chunks_storage_size_bytes{user="user1"} 25282560
+			cortex_ingester_tsdb_head_chunks_storage_size_bytes{user="user2"} 175691776
+
 			# HELP cortex_ingester_tsdb_head_chunks_removed_total Total number of series removed in the TSDB head.
 			# TYPE cortex_ingester_tsdb_head_chunks_removed_total counter
 			cortex_ingester_tsdb_head_chunks_removed_total{user="user1"} 296280
@@ -798,6 +809,12 @@ func populateTSDBMetrics(base float64) *prometheus.Registry {
 	})
 	chunksRemoved.Add(24 * base)
 
+	chunksStorageSize := promauto.With(r).NewGauge(prometheus.GaugeOpts{
+		Name: "prometheus_tsdb_head_chunks_storage_size_bytes",
+		Help: "Size of the chunks_head directory.",
+	})
+	chunksStorageSize.Set(2048 * base)
+
 	walTruncateDuration := promauto.With(r).NewSummary(prometheus.SummaryOpts{
 		Name: "prometheus_tsdb_wal_truncate_duration_seconds",
 		Help: "Duration of WAL truncation.",
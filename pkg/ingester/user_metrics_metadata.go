@@ -1,12 +1,14 @@
 package ingester
 
 import (
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
@@ -84,16 +86,53 @@ func (mm *userMetricsMetadata) purge(deadline time.Time) {
 	mm.metrics.memMetadataRemovedTotal.WithLabelValues(mm.userID).Add(float64(deleted))
 }
 
-func (mm *userMetricsMetadata) toClientMetadata() []*cortexpb.MetricMetadata {
+func (mm *userMetricsMetadata) toClientMetadata(req *client.MetricsMetadataRequest) *client.MetricsMetadataResponse {
 	mm.mtx.RLock()
 	defer mm.mtx.RUnlock()
-	r := make([]*cortexpb.MetricMetadata, 0, len(mm.metricToMetadata))
-	for _, set := range mm.metricToMetadata {
-		for m := range set {
-			r = append(r, &m)
+
+	var metrics []string
+	switch {
+	case req.GetMetric() != "":
+		if _, ok := mm.metricToMetadata[req.GetMetric()]; ok {
+			metrics = []string{req.GetMetric()}
+		}
+	default:
+		metrics = make([]string, 0, len(mm.metricToMetadata))
+		for metric := range mm.metricToMetadata {
+			metrics = append(metrics, metric)
+		}
+
+		// Pages are made of metrics sorted by name, and the token is the name of the last metric of the previous page.
+		if req.GetLimit() > 0 || req.GetPaginationToken() != "" {
+			slices.Sort(metrics)
+			if token := req.GetPaginationToken(); token != "" {
+				idx, found := slices.BinarySearch(metrics, token)
+				if found {
+					idx++
+				}
+				metrics = metrics[idx:]
+			}
+		}
+	}
+
+	resp := &client.MetricsMetadataResponse{}
+	if limit := req.GetLimit(); limit > 0 && int64(len(metrics)) > limit {
+		metrics = metrics[:limit]
+		resp.NextPaginationToken = metrics[limit-1]
+	}
+
+	resp.Metadata = make([]*cortexpb.MetricMetadata, 0, len(metrics))
+	for _, metric := range metrics {
+		var n int64
+		for m := range mm.metricToMetadata[metric] {
+			if limit := req.GetLimitPerMetric(); limit > 0 && n >= limit {
+				break
+			}
+			resp.Metadata = append(resp.Metadata, &m)
+			n++
 		}
 	}
-	return r
+	return resp
 }
 
 type metricMetadataSet map[cortexpb.MetricMetadata]time.Time
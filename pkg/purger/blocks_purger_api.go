@@ -0,0 +1,205 @@
+package purger
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	"github.com/cortexproject/cortex/pkg/tenant"
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+// Config holds the config of the series deletion of the blocks storage.
+type Config struct {
+	DeleteRequestCancelPeriod    time.Duration `yaml:"delete_request_cancel_period"`
+	TenantDeletionCascadeEnabled bool          `yaml:"tenant_deletion_cascade_enabled"`
+}
+
+// RegisterFlags registers the flags of the series deletion config.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.DurationVar(&cfg.DeleteRequestCancelPeriod, "purger.delete-request-cancel-period", 24*time.Hour, "[Experimental] Period during which a series deletion request can be cancelled. Once the period has elapsed, the series are deleted from the blocks by the compactor.")
+	f.BoolVar(&cfg.TenantDeletionCascadeEnabled, "purger.tenant-deletion-cascade-enabled", false, "[Experimental] When enabled, the tenant deletion API deletes the rule groups, the Alertmanager configuration and state, and the HA tracker replicas of the tenant too, in addition to marking its blocks for deletion.")
+}
+
+// BlocksPurgerAPI is the API of the series deletion of the blocks storage. The deletion
+// requests are stored as tombstones in the bucket: the series are filtered out by the
+// queriers, and deleted from the blocks by the compactor once the cancel period has elapsed.
+type BlocksPurgerAPI struct {
+	bucketClient              objstore.Bucket
+	cfgProvider               bucket.TenantConfigProvider
+	deleteRequestCancelPeriod time.Duration
+	logger                    log.Logger
+}
+
+func NewBlocksPurgerAPI(storageCfg cortex_tsdb.BlocksStorageConfig, cfgProvider bucket.TenantConfigProvider, cfg Config, logger log.Logger, reg prometheus.Registerer) (*BlocksPurgerAPI, error) {
+	bucketClient, err := bucket.NewClient(context.Background(), storageCfg.Bucket, "series-deletion", logger, reg)
+	if err != nil {
+		return nil, errors.Wrap(err, "create bucket client")
+	}
+
+	return newBlocksPurgerAPI(bucketClient, cfgProvider, cfg, logger), nil
+}
+
+func newBlocksPurgerAPI(bkt objstore.Bucket, cfgProvider bucket.TenantConfigProvider, cfg Config, logger log.Logger) *BlocksPurgerAPI {
+	return &BlocksPurgerAPI{
+		bucketClient:              bkt,
+		cfgProvider:               cfgProvider,
+		deleteRequestCancelPeriod: cfg.DeleteRequestCancelPeriod,
+		logger:                    logger,
+	}
+}
+
+// AddDeleteRequestHandler creates a series deletion request, following the Prometheus delete series API.
+func (api *BlocksPurgerAPI) AddDeleteRequestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	selectors := r.Form["match[]"]
+	if len(selectors) == 0 {
+		http.Error(w, "selectors not set", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	startTime, err := util.ParseTimeParam(r, "start", 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	endTime, err := util.ParseTimeParam(r, "end", now.Unix())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if endTime > util.TimeToMillis(now) {
+		http.Error(w, "deletes in future not allowed", http.StatusBadRequest)
+		return
+	}
+	if startTime > endTime {
+		http.Error(w, "start time can't be greater than end time", http.StatusBadRequest)
+		return
+	}
+
+	tombstone, err := bucketindex.NewTombstone(userID, now.Unix(), now.Unix(), startTime, endTime, selectors, bucketindex.StatePending)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := api.addTombstone(ctx, userID, tombstone); err != nil {
+		level.Error(api.logger).Log("msg", "failed to create series deletion request", "user", userID, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	level.Info(api.logger).Log("msg", "series deletion request created", "user", userID, "request_id", tombstone.RequestID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *BlocksPurgerAPI) addTombstone(ctx context.Context, userID string, tombstone *bucketindex.Tombstone) error {
+	existing, err := bucketindex.ReadTombstone(ctx, api.bucketClient, userID, api.cfgProvider, tombstone.RequestID)
+	if err != nil && !errors.Is(err, bucketindex.ErrTombstoneNotFound) {
+		return err
+	}
+
+	// The same request has already been created.
+	if existing != nil && existing.State != bucketindex.StateCancelled {
+		return nil
+	}
+
+	if err := bucketindex.WriteTombstone(ctx, api.bucketClient, userID, api.cfgProvider, tombstone); err != nil {
+		return err
+	}
+
+	// The request has been cancelled before, so the tombstone of the cancelled request is
+	// deleted for the new one to be the latest.
+	if existing != nil {
+		userBkt := bucket.NewUserBucketClient(userID, api.bucketClient, api.cfgProvider)
+		return userBkt.Delete(ctx, bucketindex.TombstoneFilepath(existing.RequestID, existing.State))
+	}
+	return nil
+}
+
+// GetAllDeleteRequestsHandler returns all the series deletion requests of the tenant.
+func (api *BlocksPurgerAPI) GetAllDeleteRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	tombstones, err := bucketindex.ReadTombstones(ctx, api.bucketClient, userID, api.cfgProvider)
+	if err != nil {
+		level.Error(api.logger).Log("msg", "failed to read series deletion requests", "user", userID, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	util.WriteJSONResponse(w, tombstones)
+}
+
+// CancelDeleteRequestHandler cancels a series deletion request, which is only allowed while
+// the series have not been deleted from the blocks yet.
+func (api *BlocksPurgerAPI) CancelDeleteRequestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	requestID := r.FormValue("request_id")
+	if requestID == "" {
+		http.Error(w, "request_id not set", http.StatusBadRequest)
+		return
+	}
+
+	tombstone, err := bucketindex.ReadTombstone(ctx, api.bucketClient, userID, api.cfgProvider, requestID)
+	if errors.Is(err, bucketindex.ErrTombstoneNotFound) {
+		http.Error(w, "could not find delete request with given id", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		level.Error(api.logger).Log("msg", "failed to read series deletion request", "user", userID, "request_id", requestID, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if tombstone.State != bucketindex.StatePending {
+		http.Error(w, "deletion of request which is not in pending state is not allowed", http.StatusBadRequest)
+		return
+	}
+	if time.Since(time.Unix(tombstone.RequestCreatedAt, 0)) > api.deleteRequestCancelPeriod {
+		http.Error(w, "deletion of request past the deadline is not allowed", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := bucketindex.UpdateTombstoneState(ctx, api.bucketClient, userID, api.cfgProvider, tombstone, bucketindex.StateCancelled, time.Now().Unix()); err != nil {
+		level.Error(api.logger).Log("msg", "failed to cancel series deletion request", "user", userID, "request_id", requestID, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	level.Info(api.logger).Log("msg", "series deletion request cancelled", "user", userID, "request_id", requestID)
+	w.WriteHeader(http.StatusNoContent)
+}
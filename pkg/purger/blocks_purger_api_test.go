@@ -0,0 +1,123 @@
+package purger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+)
+
+func TestBlocksPurgerAPI(t *testing.T) {
+	const userID = "user-1"
+
+	bkt := objstore.NewInMemBucket()
+	api := newBlocksPurgerAPI(bkt, nil, Config{DeleteRequestCancelPeriod: time.Hour}, log.NewNopLogger())
+	ctx := user.InjectOrgID(context.Background(), userID)
+
+	newRequest := func(params url.Values) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(params.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req.WithContext(ctx)
+	}
+
+	getAll := func() bucketindex.Tombstones {
+		resp := httptest.NewRecorder()
+		api.GetAllDeleteRequestsHandler(resp, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var tombstones bucketindex.Tombstones
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &tombstones))
+		return tombstones
+	}
+
+	for name, tc := range map[string]struct {
+		params       url.Values
+		expectedCode int
+	}{
+		"no selector": {
+			params:       url.Values{"start": {"0"}, "end": {"10"}},
+			expectedCode: http.StatusBadRequest,
+		},
+		"invalid selector": {
+			params:       url.Values{"match[]": {`{a="1"`}},
+			expectedCode: http.StatusBadRequest,
+		},
+		"end in the future": {
+			params:       url.Values{"match[]": {`{a="1"}`}, "end": {"9999999999"}},
+			expectedCode: http.StatusBadRequest,
+		},
+		"start after end": {
+			params:       url.Values{"match[]": {`{a="1"}`}, "start": {"20"}, "end": {"10"}},
+			expectedCode: http.StatusBadRequest,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			api.AddDeleteRequestHandler(resp, newRequest(tc.params))
+			assert.Equal(t, tc.expectedCode, resp.Code)
+		})
+	}
+	require.Empty(t, getAll())
+
+	// Requests sent twice result in a single request.
+	params := url.Values{"match[]": {`{a="1"}`, `{b="2"}`}, "start": {"10"}, "end": {"20"}}
+	for i := 0; i < 2; i++ {
+		resp := httptest.NewRecorder()
+		api.AddDeleteRequestHandler(resp, newRequest(params))
+		require.Equal(t, http.StatusNoContent, resp.Code)
+	}
+
+	tombstones := getAll()
+	require.Len(t, tombstones, 1)
+	assert.Equal(t, userID, tombstones[0].UserID)
+	assert.Equal(t, int64(10000), tombstones[0].StartTime)
+	assert.Equal(t, int64(20000), tombstones[0].EndTime)
+	assert.Equal(t, []string{`{a="1"}`, `{b="2"}`}, tombstones[0].Selectors)
+	assert.Equal(t, bucketindex.StatePending, tombstones[0].State)
+	requestID := tombstones[0].RequestID
+
+	// Unknown request.
+	resp := httptest.NewRecorder()
+	api.CancelDeleteRequestHandler(resp, newRequest(url.Values{"request_id": {"unknown"}}))
+	require.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	api.CancelDeleteRequestHandler(resp, newRequest(url.Values{"request_id": {requestID}}))
+	require.Equal(t, http.StatusNoContent, resp.Code)
+
+	tombstones = getAll()
+	require.Len(t, tombstones, 1)
+	assert.Equal(t, bucketindex.StateCancelled, tombstones[0].State)
+
+	// A cancelled request can't be cancelled again.
+	resp = httptest.NewRecorder()
+	api.CancelDeleteRequestHandler(resp, newRequest(url.Values{"request_id": {requestID}}))
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// A cancelled request can be sent again.
+	resp = httptest.NewRecorder()
+	api.AddDeleteRequestHandler(resp, newRequest(params))
+	require.Equal(t, http.StatusNoContent, resp.Code)
+
+	tombstones = getAll()
+	require.Len(t, tombstones, 1)
+	assert.Equal(t, bucketindex.StatePending, tombstones[0].State)
+
+	// A request can't be cancelled once the cancel period has elapsed.
+	api.deleteRequestCancelPeriod = 0
+	resp = httptest.NewRecorder()
+	api.CancelDeleteRequestHandler(resp, newRequest(url.Values{"request_id": {requestID}}))
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+}
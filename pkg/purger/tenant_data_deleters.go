@@ -0,0 +1,147 @@
+package purger
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/timestamp"
+
+	"github.com/cortexproject/cortex/pkg/alertmanager/alertspb"
+	"github.com/cortexproject/cortex/pkg/alertmanager/alertstore"
+	"github.com/cortexproject/cortex/pkg/ha"
+	"github.com/cortexproject/cortex/pkg/ring/kv"
+	"github.com/cortexproject/cortex/pkg/ruler/rulestore"
+)
+
+// TenantDataDeleter deletes the data of a tenant stored outside of the blocks storage.
+type TenantDataDeleter interface {
+	// Name of the deleted data, reported by the tenant deletion status.
+	Name() string
+
+	// DeleteTenantData deletes the data of the tenant. Deleting data which doesn't exist is not an error.
+	DeleteTenantData(ctx context.Context, userID string) error
+
+	// IsTenantDataDeleted returns whether the tenant has no data left.
+	IsTenantDataDeleted(ctx context.Context, userID string) (bool, error)
+}
+
+type ruleGroupsDeleter struct {
+	store rulestore.RuleStore
+}
+
+// NewRuleGroupsDeleter returns a TenantDataDeleter deleting the rule groups of the tenant. The store
+// must be backed by a bucket, the other backends being read-only.
+func NewRuleGroupsDeleter(store rulestore.RuleStore) TenantDataDeleter {
+	return &ruleGroupsDeleter{store: store}
+}
+
+func (d *ruleGroupsDeleter) Name() string { return "rule_groups" }
+
+func (d *ruleGroupsDeleter) DeleteTenantData(ctx context.Context, userID string) error {
+	err := d.store.DeleteNamespace(ctx, userID, "")
+	if err != nil && !errors.Is(err, rulestore.ErrGroupNamespaceNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (d *ruleGroupsDeleter) IsTenantDataDeleted(ctx context.Context, userID string) (bool, error) {
+	groups, err := d.store.ListRuleGroupsForUserAndNamespace(ctx, userID, "")
+	if err != nil {
+		return false, err
+	}
+	return len(groups) == 0, nil
+}
+
+type alertmanagerDeleter struct {
+	store alertstore.AlertStore
+}
+
+// NewAlertmanagerDeleter returns a TenantDataDeleter deleting the Alertmanager configuration and
+// state of the tenant. The store must be backed by a bucket, the other backends being read-only.
+func NewAlertmanagerDeleter(store alertstore.AlertStore) TenantDataDeleter {
+	return &alertmanagerDeleter{store: store}
+}
+
+func (d *alertmanagerDeleter) Name() string { return "alertmanager" }
+
+func (d *alertmanagerDeleter) DeleteTenantData(ctx context.Context, userID string) error {
+	if err := d.store.DeleteAlertConfig(ctx, userID); err != nil {
+		return errors.Wrap(err, "delete alertmanager config")
+	}
+
+	if err := d.store.DeleteFullState(ctx, userID); err != nil {
+		return errors.Wrap(err, "delete alertmanager state")
+	}
+	return nil
+}
+
+func (d *alertmanagerDeleter) IsTenantDataDeleted(ctx context.Context, userID string) (bool, error) {
+	if _, err := d.store.GetAlertConfig(ctx, userID); !errors.Is(err, alertspb.ErrNotFound) {
+		return false, err
+	}
+
+	if _, err := d.store.GetFullState(ctx, userID); !errors.Is(err, alertspb.ErrNotFound) {
+		return false, err
+	}
+	return true, nil
+}
+
+type haTrackerDeleter struct {
+	client kv.Client
+}
+
+// NewHATrackerDeleter returns a TenantDataDeleter deleting the HA tracker elected replicas of all the
+// clusters of the tenant. The replicas are stored in the KV store under <tenant>/<cluster>.
+//
+// The replicas are marked as deleted, like the HA tracker cleanup does for the stale replicas, so that
+// the distributors watching the KV store drop them from memory. The HA tracker cleanup then deletes the
+// keys, given some KV stores don't send a watch notification for the deleted keys.
+func NewHATrackerDeleter(client kv.Client) TenantDataDeleter {
+	return &haTrackerDeleter{client: client}
+}
+
+func (d *haTrackerDeleter) Name() string { return "ha_tracker" }
+
+func (d *haTrackerDeleter) DeleteTenantData(ctx context.Context, userID string) error {
+	keys, err := d.client.List(ctx, userID+"/")
+	if err != nil {
+		return errors.Wrap(err, "list HA tracker replicas")
+	}
+
+	for _, key := range keys {
+		err := d.client.CAS(ctx, key, func(in interface{}) (out interface{}, retry bool, err error) {
+			desc, ok := in.(*ha.ReplicaDesc)
+			if !ok || desc == nil || desc.DeletedAt > 0 {
+				return nil, false, nil
+			}
+
+			desc.DeletedAt = timestamp.FromTime(time.Now())
+			return desc, true, nil
+		})
+		if err != nil {
+			return errors.Wrapf(err, "mark HA tracker replica %s for deletion", key)
+		}
+	}
+	return nil
+}
+
+func (d *haTrackerDeleter) IsTenantDataDeleted(ctx context.Context, userID string) (bool, error) {
+	keys, err := d.client.List(ctx, userID+"/")
+	if err != nil {
+		return false, err
+	}
+
+	for _, key := range keys {
+		val, err := d.client.Get(ctx, key)
+		if err != nil {
+			return false, err
+		}
+
+		if desc, ok := val.(*ha.ReplicaDesc); ok && desc != nil && desc.DeletedAt == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
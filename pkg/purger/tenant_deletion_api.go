@@ -23,22 +23,28 @@ type TenantDeletionAPI struct {
 	bucketClient objstore.InstrumentedBucket
 	logger       log.Logger
 	cfgProvider  bucket.TenantConfigProvider
+
+	// deleters delete the tenant data stored outside of the blocks storage.
+	deleters []TenantDataDeleter
 }
 
-func NewTenantDeletionAPI(storageCfg cortex_tsdb.BlocksStorageConfig, cfgProvider bucket.TenantConfigProvider, logger log.Logger, reg prometheus.Registerer) (*TenantDeletionAPI, error) {
+// NewTenantDeletionAPI makes a new TenantDeletionAPI. The blocks of the deleted tenants are deleted
+// by the compactor, while the tenant data stored elsewhere is deleted by the given deleters.
+func NewTenantDeletionAPI(storageCfg cortex_tsdb.BlocksStorageConfig, cfgProvider bucket.TenantConfigProvider, logger log.Logger, reg prometheus.Registerer, deleters ...TenantDataDeleter) (*TenantDeletionAPI, error) {
 	bucketClient, err := createBucketClient(storageCfg, logger, reg)
 	if err != nil {
 		return nil, err
 	}
 
-	return newTenantDeletionAPI(bucketClient, cfgProvider, logger), nil
+	return newTenantDeletionAPI(bucketClient, cfgProvider, logger, deleters...), nil
 }
 
-func newTenantDeletionAPI(bkt objstore.InstrumentedBucket, cfgProvider bucket.TenantConfigProvider, logger log.Logger) *TenantDeletionAPI {
+func newTenantDeletionAPI(bkt objstore.InstrumentedBucket, cfgProvider bucket.TenantConfigProvider, logger log.Logger, deleters ...TenantDataDeleter) *TenantDeletionAPI {
 	return &TenantDeletionAPI{
 		bucketClient: bkt,
 		cfgProvider:  cfgProvider,
 		logger:       logger,
+		deleters:     deleters,
 	}
 }
 
@@ -52,7 +58,7 @@ func (api *TenantDeletionAPI) DeleteTenant(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err = cortex_tsdb.WriteTenantDeletionMark(r.Context(), api.bucketClient, userID, cortex_tsdb.NewTenantDeletionMark(time.Now()))
+	err = cortex_tsdb.WriteTenantDeletionMark(ctx, api.bucketClient, userID, cortex_tsdb.NewTenantDeletionMark(time.Now()))
 	if err != nil {
 		level.Error(api.logger).Log("msg", "failed to write tenant deletion mark", "user", userID, "err", err)
 
@@ -62,12 +68,28 @@ func (api *TenantDeletionAPI) DeleteTenant(w http.ResponseWriter, r *http.Reques
 
 	level.Info(api.logger).Log("msg", "tenant deletion mark in blocks storage created", "user", userID)
 
+	// The deletion mark is written first, so that the ruler and alertmanager stop loading the tenant
+	// even if the deletion of its data fails. The request is idempotent and can be retried.
+	for _, deleter := range api.deleters {
+		if err := deleter.DeleteTenantData(ctx, userID); err != nil {
+			level.Error(api.logger).Log("msg", "failed to delete tenant data", "user", userID, "data", deleter.Name(), "err", err)
+
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		level.Info(api.logger).Log("msg", "tenant data deleted", "user", userID, "data", deleter.Name())
+	}
+
 	w.WriteHeader(http.StatusOK)
 }
 
 type DeleteTenantStatusResponse struct {
 	TenantID      string `json:"tenant_id"`
 	BlocksDeleted bool   `json:"blocks_deleted"`
+
+	// DataDeleted reports, by data name, whether the tenant data stored outside of the blocks storage is deleted.
+	DataDeleted map[string]bool `json:"data_deleted,omitempty"`
 }
 
 func (api *TenantDeletionAPI) DeleteTenantStatus(w http.ResponseWriter, r *http.Request) {
@@ -86,6 +108,19 @@ func (api *TenantDeletionAPI) DeleteTenantStatus(w http.ResponseWriter, r *http.
 		return
 	}
 
+	for _, deleter := range api.deleters {
+		deleted, err := deleter.IsTenantDataDeleted(ctx, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if result.DataDeleted == nil {
+			result.DataDeleted = make(map[string]bool, len(api.deleters))
+		}
+		result.DataDeleted[deleter.Name()] = deleted
+	}
+
 	util.WriteJSONResponse(w, result)
 }
 
@@ -3,16 +3,28 @@ package purger
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/stretchr/testify/require"
 	"github.com/thanos-io/objstore"
 	"github.com/weaveworks/common/user"
 
+	"github.com/cortexproject/cortex/pkg/alertmanager/alertspb"
+	alertbucketclient "github.com/cortexproject/cortex/pkg/alertmanager/alertstore/bucketclient"
+	"github.com/cortexproject/cortex/pkg/ha"
+	"github.com/cortexproject/cortex/pkg/ring/kv"
+	"github.com/cortexproject/cortex/pkg/ring/kv/consul"
+	"github.com/cortexproject/cortex/pkg/ruler/rulespb"
+	rulebucketclient "github.com/cortexproject/cortex/pkg/ruler/rulestore/bucketclient"
 	"github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/test"
 )
 
 func TestDeleteTenant(t *testing.T) {
@@ -40,6 +52,106 @@ func TestDeleteTenant(t *testing.T) {
 	}
 }
 
+func TestDeleteTenant_ShouldDeleteTheTenantDataWithTheDeleters(t *testing.T) {
+	const (
+		userID      = "user-1"
+		otherUserID = "user-2"
+	)
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+
+	ruleStore := rulebucketclient.NewBucketRuleStore(objstore.NewInMemBucket(), nil, logger)
+	alertStore := alertbucketclient.NewBucketAlertStore(objstore.NewInMemBucket(), nil, logger)
+	haClient, closer := consul.NewInMemoryClient(ha.GetReplicaDescCodec(), logger, nil)
+	t.Cleanup(func() { require.NoError(t, closer.Close()) })
+
+	for _, id := range []string{userID, otherUserID} {
+		require.NoError(t, ruleStore.SetRuleGroup(ctx, id, "namespace", &rulespb.RuleGroupDesc{Name: "group", Namespace: "namespace", User: id}))
+		require.NoError(t, alertStore.SetAlertConfig(ctx, alertspb.AlertConfigDesc{User: id, RawConfig: "config"}))
+		require.NoError(t, alertStore.SetFullState(ctx, id, alertspb.FullStateDesc{}))
+
+		for _, cluster := range []string{"cluster-1", "cluster-2"} {
+			require.NoError(t, haClient.CAS(ctx, id+"/"+cluster, func(interface{}) (interface{}, bool, error) {
+				return &ha.ReplicaDesc{Replica: "replica-1"}, true, nil
+			}))
+		}
+	}
+
+	// A distributor HA tracker watching the elected replicas.
+	tracker, err := ha.NewHATracker(ha.HATrackerConfig{
+		EnableHATracker: true,
+		KVStore:         kv.Config{Mock: haClient},
+		UpdateTimeout:   time.Second,
+		FailoverTimeout: 2 * time.Second,
+	}, haTrackerLimits{}, ha.HATrackerStatusConfig{}, nil, "test-ha-tracker", logger)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, tracker))
+	t.Cleanup(func() { require.NoError(t, services.StopAndAwaitTerminated(ctx, tracker)) })
+
+	electedClusters := func(id string) int {
+		count := 0
+		for key := range tracker.SnapshotElectedReplicas() {
+			if strings.HasPrefix(key, id+"/") {
+				count++
+			}
+		}
+		return count
+	}
+	test.Poll(t, time.Second, 2, func() interface{} { return electedClusters(userID) })
+
+	bkt := objstore.NewInMemBucket()
+	api := newTenantDeletionAPI(objstore.WithNoopInstr(bkt), nil, logger,
+		NewRuleGroupsDeleter(ruleStore),
+		NewAlertmanagerDeleter(alertStore),
+		NewHATrackerDeleter(haClient),
+	)
+
+	getStatus := func(id string) DeleteTenantStatusResponse {
+		resp := httptest.NewRecorder()
+		api.DeleteTenantStatus(resp, (&http.Request{}).WithContext(user.InjectOrgID(ctx, id)))
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var status DeleteTenantStatusResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &status))
+		return status
+	}
+
+	notDeleted := map[string]bool{"rule_groups": false, "alertmanager": false, "ha_tracker": false}
+	require.Equal(t, notDeleted, getStatus(userID).DataDeleted)
+
+	// The deletion is idempotent.
+	for i := 0; i < 2; i++ {
+		resp := httptest.NewRecorder()
+		api.DeleteTenant(resp, (&http.Request{}).WithContext(user.InjectOrgID(ctx, userID)))
+		require.Equal(t, http.StatusOK, resp.Code)
+	}
+
+	exists, err := tsdb.TenantDeletionMarkExists(ctx, bkt, userID)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	status := getStatus(userID)
+	require.Equal(t, userID, status.TenantID)
+	require.True(t, status.BlocksDeleted)
+	require.Equal(t, map[string]bool{"rule_groups": true, "alertmanager": true, "ha_tracker": true}, status.DataDeleted)
+
+	// The watching HA tracker drops the elected replicas of the tenant, while the keys are left to
+	// the HA tracker cleanup.
+	test.Poll(t, time.Second, 0, func() interface{} { return electedClusters(userID) })
+	keys, err := haClient.List(ctx, userID+"/")
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	// The data of the other tenants is left untouched.
+	require.Equal(t, notDeleted, getStatus(otherUserID).DataDeleted)
+	require.Equal(t, 2, electedClusters(otherUserID))
+}
+
+type haTrackerLimits struct{}
+
+func (haTrackerLimits) MaxHAReplicaGroups(string) int { return 100 }
+
 func TestDeleteTenantStatus(t *testing.T) {
 	const username = "user"
 
@@ -0,0 +1,138 @@
+package querier
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/promql"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+const (
+	// ActiveQueryStageQueued is the stage of a query waiting for a slot, when -querier.max-concurrent is reached.
+	ActiveQueryStageQueued = "queued"
+	// ActiveQueryStageExecuting is the stage of a query executed by the PromQL engine.
+	ActiveQueryStageExecuting = "executing"
+)
+
+// ActiveQuery is a query currently executed by the PromQL engine.
+type ActiveQuery struct {
+	TenantID  string    `json:"tenantID"`
+	Query     string    `json:"query"`
+	StartTime time.Time `json:"startTime"`
+	Stage     string    `json:"stage"`
+}
+
+type activeQuery struct {
+	ActiveQuery
+	trackerIndex int
+}
+
+// ActiveQueries is a promql.QueryTracker keeping the queries currently executed by the
+// PromQL engine in memory, to list them. It wraps the tracker persisting them to the
+// active query tracker file, if any, which logs them on the next startup after a crash.
+type ActiveQueries struct {
+	tracker promql.QueryTracker
+
+	mtx     sync.Mutex
+	nextID  int
+	queries map[int]*activeQuery
+}
+
+// NewActiveQueries makes a new ActiveQueries wrapping the tracker, which can be nil.
+func NewActiveQueries(tracker promql.QueryTracker) *ActiveQueries {
+	return &ActiveQueries{
+		tracker: tracker,
+		queries: map[int]*activeQuery{},
+	}
+}
+
+// GetMaxConcurrent implements promql.QueryTracker.
+func (a *ActiveQueries) GetMaxConcurrent() int {
+	if a.tracker == nil {
+		return -1
+	}
+	return a.tracker.GetMaxConcurrent()
+}
+
+// Insert implements promql.QueryTracker.
+func (a *ActiveQueries) Insert(ctx context.Context, query string) (int, error) {
+	tenantID, _ := user.ExtractOrgID(ctx)
+	q := &activeQuery{
+		ActiveQuery: ActiveQuery{
+			TenantID:  tenantID,
+			Query:     query,
+			StartTime: time.Now(),
+			Stage:     ActiveQueryStageQueued,
+		},
+	}
+
+	a.mtx.Lock()
+	id := a.nextID
+	a.nextID++
+	a.queries[id] = q
+	a.mtx.Unlock()
+
+	if a.tracker != nil {
+		// Blocks until the query can be executed.
+		trackerIndex, err := a.tracker.Insert(ctx, query)
+		if err != nil {
+			a.mtx.Lock()
+			delete(a.queries, id)
+			a.mtx.Unlock()
+			return 0, err
+		}
+		q.trackerIndex = trackerIndex
+	}
+
+	a.mtx.Lock()
+	q.Stage = ActiveQueryStageExecuting
+	a.mtx.Unlock()
+
+	return id, nil
+}
+
+// Delete implements promql.QueryTracker.
+func (a *ActiveQueries) Delete(insertIndex int) {
+	a.mtx.Lock()
+	q, ok := a.queries[insertIndex]
+	delete(a.queries, insertIndex)
+	a.mtx.Unlock()
+
+	if ok && a.tracker != nil {
+		a.tracker.Delete(q.trackerIndex)
+	}
+}
+
+// Close implements promql.QueryTracker.
+func (a *ActiveQueries) Close() error {
+	if a.tracker == nil {
+		return nil
+	}
+	return a.tracker.Close()
+}
+
+// List returns the queries currently executed, sorted by start time.
+func (a *ActiveQueries) List() []ActiveQuery {
+	a.mtx.Lock()
+	queries := make([]ActiveQuery, 0, len(a.queries))
+	for _, q := range a.queries {
+		queries = append(queries, q.ActiveQuery)
+	}
+	a.mtx.Unlock()
+
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].StartTime.Before(queries[j].StartTime)
+	})
+	return queries
+}
+
+// Handler returns the queries currently executed in json format, the oldest first.
+func (a *ActiveQueries) Handler(w http.ResponseWriter, _ *http.Request) {
+	util.WriteJSONResponse(w, a.List())
+}
@@ -0,0 +1,107 @@
+package querier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+)
+
+func TestActiveQueries(t *testing.T) {
+	t.Parallel()
+
+	tracker := &blockingQueryTracker{unblock: make(chan struct{})}
+	activeQueries := NewActiveQueries(tracker)
+	assert.Equal(t, 4, activeQueries.GetMaxConcurrent())
+
+	// The first query is blocked by the wrapped tracker.
+	inserted := make(chan int)
+	go func() {
+		id, err := activeQueries.Insert(user.InjectOrgID(context.Background(), "user-1"), "sum(up)")
+		assert.NoError(t, err)
+		inserted <- id
+	}()
+
+	require.Eventually(t, func() bool {
+		queries := activeQueries.List()
+		return len(queries) == 1 && queries[0].Stage == ActiveQueryStageQueued
+	}, time.Second, 10*time.Millisecond)
+
+	close(tracker.unblock)
+	firstID := <-inserted
+
+	secondID, err := activeQueries.Insert(user.InjectOrgID(context.Background(), "user-2"), "rate(http_requests_total[5m])")
+	require.NoError(t, err)
+
+	queries := activeQueries.List()
+	require.Len(t, queries, 2)
+	assert.Equal(t, "user-1", queries[0].TenantID)
+	assert.Equal(t, "sum(up)", queries[0].Query)
+	assert.Equal(t, ActiveQueryStageExecuting, queries[0].Stage)
+	assert.Equal(t, "user-2", queries[1].TenantID)
+	assert.Equal(t, "rate(http_requests_total[5m])", queries[1].Query)
+	assert.False(t, queries[1].StartTime.Before(queries[0].StartTime))
+
+	// The handler returns the same queries.
+	rec := httptest.NewRecorder()
+	activeQueries.Handler(rec, httptest.NewRequest("GET", "/querier/active_queries", nil))
+	var listed []ActiveQuery
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	require.Len(t, listed, 2)
+	assert.Equal(t, queries[0].Query, listed[0].Query)
+	assert.Equal(t, queries[1].Query, listed[1].Query)
+
+	activeQueries.Delete(firstID)
+	activeQueries.Delete(secondID)
+	assert.Empty(t, activeQueries.List())
+	assert.ElementsMatch(t, []int{0, 1}, tracker.deleted)
+}
+
+func TestActiveQueries_WithoutTracker(t *testing.T) {
+	t.Parallel()
+
+	activeQueries := NewActiveQueries(nil)
+	assert.Equal(t, -1, activeQueries.GetMaxConcurrent())
+
+	id, err := activeQueries.Insert(user.InjectOrgID(context.Background(), "user-1"), "up")
+	require.NoError(t, err)
+	require.Len(t, activeQueries.List(), 1)
+	assert.Equal(t, ActiveQueryStageExecuting, activeQueries.List()[0].Stage)
+
+	activeQueries.Delete(id)
+	assert.Empty(t, activeQueries.List())
+	assert.NoError(t, activeQueries.Close())
+}
+
+type blockingQueryTracker struct {
+	unblock chan struct{}
+	next    int
+	deleted []int
+}
+
+func (t *blockingQueryTracker) GetMaxConcurrent() int {
+	return 4
+}
+
+func (t *blockingQueryTracker) Insert(ctx context.Context, _ string) (int, error) {
+	select {
+	case <-t.unblock:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	t.next++
+	return t.next - 1, nil
+}
+
+func (t *blockingQueryTracker) Delete(insertIndex int) {
+	t.deleted = append(t.deleted, insertIndex)
+}
+
+func (t *blockingQueryTracker) Close() error {
+	return nil
+}
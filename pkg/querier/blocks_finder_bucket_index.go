@@ -118,3 +118,29 @@ func (f *BucketIndexBlocksFinder) GetBlocks(ctx context.Context, userID string,
 
 	return blocks, matchingDeletionMarks, nil
 }
+
+// SyncUserBlocks implements BlocksFinder. The blocks are found through the bucket index, so
+// new blocks are only visible once the bucket index has been updated by the compactor.
+func (f *BucketIndexBlocksFinder) SyncUserBlocks(ctx context.Context, userID string) error {
+	if f.State() != services.Running {
+		return errBucketIndexBlocksFinderNotRunning
+	}
+
+	return f.loader.RefreshIndex(ctx, userID)
+}
+
+// GetTombstones implements TombstonesLoader.
+func (f *BucketIndexBlocksFinder) GetTombstones(ctx context.Context, userID string) (bucketindex.Tombstones, error) {
+	if f.State() != services.Running {
+		return nil, errBucketIndexBlocksFinderNotRunning
+	}
+
+	idx, _, err := f.loader.GetIndex(ctx, userID)
+	if errors.Is(err, bucketindex.ErrIndexNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return idx.Tombstones, nil
+}
@@ -154,6 +154,31 @@ func (d *BucketScanBlocksFinder) GetBlocks(_ context.Context, userID string, min
 	return matchingMetas, matchingDeletionMarks, nil
 }
 
+// SyncUserBlocks implements BlocksFinder.
+func (d *BucketScanBlocksFinder) SyncUserBlocks(ctx context.Context, userID string) error {
+	if d.State() != services.Running {
+		return errBucketScanBlocksFinderNotRunning
+	}
+
+	metas, deletionMarks, err := d.scanUserBlocksWithRetries(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	lookup := map[ulid.ULID]*bucketindex.Block{}
+	for _, m := range metas {
+		lookup[m.ID] = m
+	}
+
+	d.userMx.Lock()
+	d.userMetas[userID] = metas
+	d.userMetasLookup[userID] = lookup
+	d.userDeletionMarks[userID] = deletionMarks
+	d.userMx.Unlock()
+
+	return nil
+}
+
 func (d *BucketScanBlocksFinder) starting(ctx context.Context) error {
 	// Before the service is in the running state it must have successfully
 	// complete the initial scan.
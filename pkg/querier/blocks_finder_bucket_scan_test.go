@@ -267,6 +267,39 @@ func TestBucketScanBlocksFinder_PeriodicScanFindsNewBlock(t *testing.T) {
 	assert.Empty(t, deletionMarks)
 }
 
+func TestBucketScanBlocksFinder_SyncUserBlocks(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s, bucket, _, _ := prepareBucketScanBlocksFinder(t, prepareBucketScanBlocksFinderConfig())
+
+	block1 := cortex_testutil.MockStorageBlock(t, bucket, "user-1", 10, 20)
+
+	require.NoError(t, services.StartAndAwaitRunning(ctx, s))
+
+	block2 := cortex_testutil.MockStorageBlock(t, bucket, "user-1", 20, 30)
+	block3 := cortex_testutil.MockStorageBlock(t, bucket, "user-2", 20, 30)
+
+	// Sync the blocks of a single user.
+	require.NoError(t, s.SyncUserBlocks(ctx, "user-1"))
+
+	blocks, _, err := s.GetBlocks(ctx, "user-1", 0, 30)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(blocks))
+	assert.Equal(t, block2.ULID, blocks[0].ID)
+	assert.Equal(t, block1.ULID, blocks[1].ID)
+
+	blocks, _, err = s.GetBlocks(ctx, "user-2", 0, 30)
+	require.NoError(t, err)
+	assert.Empty(t, blocks)
+
+	require.NoError(t, s.SyncUserBlocks(ctx, "user-2"))
+
+	blocks, _, err = s.GetBlocks(ctx, "user-2", 0, 30)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(blocks))
+	assert.Equal(t, block3.ULID, blocks[0].ID)
+}
+
 func TestBucketScanBlocksFinder_PeriodicScanFindsBlockMarkedForDeletion(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
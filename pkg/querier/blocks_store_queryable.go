@@ -17,6 +17,7 @@ import (
 	"github.com/go-kit/log/level"
 	"github.com/gogo/protobuf/types"
 	"github.com/oklog/ulid"
+	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -36,6 +37,7 @@ import (
 	grpc_metadata "google.golang.org/grpc/metadata"
 
 	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/querier/partialdata"
 	"github.com/cortexproject/cortex/pkg/querier/series"
 	"github.com/cortexproject/cortex/pkg/querier/stats"
 	"github.com/cortexproject/cortex/pkg/querysharding"
@@ -86,6 +88,9 @@ type BlocksFinder interface {
 	// GetBlocks returns known blocks for userID containing samples within the range minT
 	// and maxT (milliseconds, both included). Returned blocks are sorted by MaxTime descending.
 	GetBlocks(ctx context.Context, userID string, minT, maxT int64) (bucketindex.Blocks, map[ulid.ULID]*bucketindex.BlockDeletionMark, error)
+
+	// SyncUserBlocks immediately refreshes the known blocks of userID.
+	SyncUserBlocks(ctx context.Context, userID string) error
 }
 
 // BlocksStoreClient is the interface that should be implemented by any client used
@@ -104,6 +109,7 @@ type BlocksStoreLimits interface {
 
 	MaxChunksPerQueryFromStore(userID string) int
 	StoreGatewayTenantShardSize(userID string) float64
+	QueryPartialResults(userID string) bool
 }
 
 type blocksStoreQueryableMetrics struct {
@@ -241,7 +247,7 @@ func NewBlocksStoreQueryableFromConfig(querierCfg Config, gatewayCfg storegatewa
 			return nil, errors.Wrap(err, "failed to create store-gateway ring client")
 		}
 
-		stores, err = newBlocksStoreReplicationSet(storesRing, gatewayCfg.ShardingStrategy, randomLoadBalancing, limits, querierCfg.StoreGatewayClient, logger, reg, storesRingCfg.ZoneAwarenessEnabled, gatewayCfg.ShardingRing.ZoneStableShuffleSharding)
+		stores, err = newBlocksStoreReplicationSet(storesRing, gatewayCfg.ShardingStrategy, randomLoadBalancing, limits, querierCfg.StoreGatewayClient, logger, reg, storesRingCfg.ZoneAwarenessEnabled, gatewayCfg.ShardingRing.ZoneStableShuffleSharding, querierCfg.StoreGatewayPreferredZone)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create store set")
 		}
@@ -314,6 +320,24 @@ func (q *BlocksStoreQueryable) Querier(mint, maxt int64) (storage.Querier, error
 	}, nil
 }
 
+// GetTombstones implements TombstonesLoader. The tombstones are only loaded from the bucket
+// index, so no tombstone is returned when the bucket index is disabled.
+func (q *BlocksStoreQueryable) GetTombstones(ctx context.Context, userID string) (bucketindex.Tombstones, error) {
+	if loader, ok := q.finder.(TombstonesLoader); ok {
+		return loader.GetTombstones(ctx, userID)
+	}
+	return nil, nil
+}
+
+// SyncUserBlocks implements BlocksSyncer.
+func (q *BlocksStoreQueryable) SyncUserBlocks(ctx context.Context, userID string) error {
+	if s := q.State(); s != services.Running {
+		return errors.Errorf("BlocksStoreQueryable is not running: %v", s)
+	}
+
+	return q.finder.SyncUserBlocks(ctx, userID)
+}
+
 type blocksStoreQuerier struct {
 	minT, maxT  int64
 	finder      BlocksFinder
@@ -377,9 +401,11 @@ func (q *blocksStoreQuerier) LabelNames(ctx context.Context, hints *storage.Labe
 		return queriedBlocks, nil, retryableError
 	}
 
-	if err := q.queryWithConsistencyCheck(spanCtx, spanLog, minT, maxT, userID, queryFunc); err != nil {
+	partialWarnings, err := q.queryWithConsistencyCheck(spanCtx, spanLog, minT, maxT, userID, queryFunc)
+	if err != nil {
 		return nil, nil, err
 	}
+	resWarnings.Merge(partialWarnings)
 
 	return strutil.MergeSlices(int(limit), resNameSets...), resWarnings, nil
 }
@@ -420,9 +446,11 @@ func (q *blocksStoreQuerier) LabelValues(ctx context.Context, name string, hints
 		return queriedBlocks, nil, retryableError
 	}
 
-	if err := q.queryWithConsistencyCheck(spanCtx, spanLog, minT, maxT, userID, queryFunc); err != nil {
+	partialWarnings, err := q.queryWithConsistencyCheck(spanCtx, spanLog, minT, maxT, userID, queryFunc)
+	if err != nil {
 		return nil, nil, err
 	}
+	resWarnings.Merge(partialWarnings)
 
 	return strutil.MergeSlices(int(limit), resValueSets...), resWarnings, nil
 }
@@ -476,9 +504,11 @@ func (q *blocksStoreQuerier) selectSorted(ctx context.Context, sp *storage.Selec
 		return queriedBlocks, nil, retryableError
 	}
 
-	if err := q.queryWithConsistencyCheck(spanCtx, spanLog, minT, maxT, userID, queryFunc); err != nil {
+	partialWarnings, err := q.queryWithConsistencyCheck(spanCtx, spanLog, minT, maxT, userID, queryFunc)
+	if err != nil {
 		return storage.ErrSeriesSet(err)
 	}
+	resWarnings.Merge(partialWarnings)
 
 	if len(resSeriesSets) == 0 {
 		storage.EmptySeriesSet()
@@ -491,7 +521,7 @@ func (q *blocksStoreQuerier) selectSorted(ctx context.Context, sp *storage.Selec
 }
 
 func (q *blocksStoreQuerier) queryWithConsistencyCheck(ctx context.Context, logger log.Logger, minT, maxT int64, userID string,
-	queryFunc func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT int64) ([]ulid.ULID, error, error)) error {
+	queryFunc func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT int64) ([]ulid.ULID, error, error)) (annotations.Annotations, error) {
 	// If queryStoreAfter is enabled, we do manipulate the query maxt to query samples up until
 	// now - queryStoreAfter, because the most recent time range is covered by ingesters. This
 	// optimization is particularly important for the blocks storage because can be used to skip
@@ -508,20 +538,20 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(ctx context.Context, logg
 		if maxT < minT {
 			q.metrics.storesHit.Observe(0)
 			level.Debug(logger).Log("msg", "empty query time range after max time manipulation")
-			return nil
+			return nil, nil
 		}
 	}
 
 	// Find the list of blocks we need to query given the time range.
 	knownBlocks, knownDeletionMarks, err := q.finder.GetBlocks(ctx, userID, minT, maxT)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(knownBlocks) == 0 {
 		q.metrics.storesHit.Observe(0)
 		level.Debug(logger).Log("msg", "no blocks found")
-		return nil
+		return nil, nil
 	}
 
 	level.Debug(logger).Log("msg", "found blocks to query", "expected", knownBlocks.String())
@@ -551,7 +581,7 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(ctx context.Context, logg
 				break
 			}
 
-			return err
+			return nil, err
 		}
 		level.Debug(logger).Log("msg", "found store-gateway instances to query", "num instances", len(clients), "attempt", attempt)
 
@@ -559,7 +589,7 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(ctx context.Context, logg
 		// are only meant to cover missing blocks.
 		queriedBlocks, err, retryableError = queryFunc(clients, minT, maxT)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		level.Debug(logger).Log("msg", "received series from all store-gateways", "queried blocks", strings.Join(convertULIDsToString(queriedBlocks), " "))
 
@@ -580,7 +610,7 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(ctx context.Context, logg
 			q.metrics.storesHit.Observe(float64(len(touchedStores)))
 			q.metrics.refetches.Observe(float64(attempt - 1))
 
-			return nil
+			return nil, nil
 		}
 
 		level.Debug(logger).Log("msg", "consistency check failed", "attempt", attempt, "missing blocks", strings.Join(convertULIDsToString(missingBlocks), " "))
@@ -589,15 +619,39 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(ctx context.Context, logg
 		remainingBlocks = missingBlocks
 	}
 
+	// When the partial results are enabled, we return the results of the queried blocks
+	// with a warning listing the time ranges of the blocks which have not been queried.
+	if q.limits.QueryPartialResults(userID) {
+		level.Warn(util_log.WithContext(ctx, logger)).Log("msg", "returning partial results because some blocks were not queried", "missing blocks", strings.Join(convertULIDsToString(remainingBlocks), " "), "err", retryableError)
+		return annotations.New().Add(missingBlocksWarning(knownBlocks, remainingBlocks)), nil
+	}
+
 	// After we exhausted retries, if retryable error is not nil return the retryable error.
 	// It can be helpful to know whether we need to retry more or not.
 	if retryableError != nil {
-		return retryableError
+		return nil, retryableError
 	}
 
 	// We've not been able to query all expected blocks after all retries.
 	level.Warn(util_log.WithContext(ctx, logger)).Log("msg", "failed consistency check", "err", err)
-	return fmt.Errorf("consistency check failed because some blocks were not queried: %s", strings.Join(convertULIDsToString(remainingBlocks), " "))
+	return nil, fmt.Errorf("consistency check failed because some blocks were not queried: %s", strings.Join(convertULIDsToString(remainingBlocks), " "))
+}
+
+// missingBlocksWarning returns the warning of the partial results listing the
+// blocks which have not been queried, with the time range they cover.
+func missingBlocksWarning(knownBlocks bucketindex.Blocks, missingBlocks []ulid.ULID) error {
+	missing := make(map[ulid.ULID]struct{}, len(missingBlocks))
+	for _, id := range missingBlocks {
+		missing[id] = struct{}{}
+	}
+
+	ranges := make([]string, 0, len(missingBlocks))
+	for _, b := range knownBlocks {
+		if _, ok := missing[b.ID]; ok {
+			ranges = append(ranges, fmt.Sprintf("%s (%s to %s)", b.ID.String(), formatPartialResultsTime(b.MinTime), formatPartialResultsTime(b.MaxTime)))
+		}
+	}
+	return fmt.Errorf(partialdata.WarningPrefix+"some blocks have not been queried from the store-gateways: %s", strings.Join(ranges, ", "))
 }
 
 func (q *blocksStoreQuerier) fetchSeriesFromStores(
@@ -655,8 +709,15 @@ func (q *blocksStoreQuerier) fetchSeriesFromStores(
 				return errors.Wrapf(err, "failed to create series request")
 			}
 
+			// Each store-gateway request gets its own span, so that the stats of the blocks
+			// queried in it can be analysed for slow queries.
+			seriesSpan, seriesCtx := opentracing.StartSpanFromContext(gCtx, "blocksStoreQuerier.fetchSeriesFromStore")
+			defer seriesSpan.Finish()
+			seriesSpan.SetTag("instance", c.RemoteAddress())
+			seriesSpan.SetTag("requested_blocks", strings.Join(convertULIDsToString(blockIDs), " "))
+
 			begin := time.Now()
-			stream, err := c.Series(gCtx, req)
+			stream, err := c.Series(seriesCtx, req)
 			if err != nil {
 				if isRetryableError(err) {
 					level.Warn(spanLog).Log("err", errors.Wrapf(err, "failed to fetch series from %s due to retryable error", c.RemoteAddress()))
@@ -775,6 +836,12 @@ func (q *blocksStoreQuerier) fetchSeriesFromStores(
 			reqStats.AddStoreGatewayTouchedPostings(uint64(seriesQueryStats.PostingsTouched))
 			reqStats.AddStoreGatewayTouchedPostingBytes(uint64(seriesQueryStats.PostingsTouchedSizeSum))
 
+			seriesSpan.SetTag("queried_blocks", strings.Join(convertULIDsToString(myQueriedBlocks), " "))
+			seriesSpan.SetTag("fetched_series", numSeries)
+			seriesSpan.SetTag("fetched_chunks", chunksCount)
+			seriesSpan.SetTag("fetched_chunk_bytes", chunkBytes)
+			setSeriesQueryStatsSpanTags(seriesSpan, seriesQueryStats)
+
 			level.Debug(spanLog).Log("msg", "received series from store-gateway",
 				"instance", c.RemoteAddress(),
 				"requested blocks", strings.Join(convertULIDsToString(blockIDs), " "),
@@ -797,11 +864,13 @@ func (q *blocksStoreQuerier) fetchSeriesFromStores(
 					"postings_fetched", seriesQueryStats.PostingsFetched,
 					"postings_fetch_count", seriesQueryStats.PostingsFetchCount,
 					"postings_fetched_size_sum", seriesQueryStats.PostingsFetchedSizeSum,
+					"postings_cache_hit_ratio", postingsCacheHitRatio(seriesQueryStats),
 					"series_touched", seriesQueryStats.SeriesTouched,
 					"series_touched_size_sum", seriesQueryStats.SeriesTouchedSizeSum,
 					"series_fetched", seriesQueryStats.SeriesFetched,
 					"series_fetch_count", seriesQueryStats.SeriesFetchCount,
 					"series_fetched_size_sum", seriesQueryStats.SeriesFetchedSizeSum,
+					"series_cache_hit_ratio", seriesCacheHitRatio(seriesQueryStats),
 					"chunks_touched", seriesQueryStats.ChunksTouched,
 					"chunks_touched_size_sum", seriesQueryStats.ChunksTouchedSizeSum,
 					"chunks_fetched", seriesQueryStats.ChunksFetched,
@@ -1134,6 +1203,46 @@ func createLabelValuesRequest(minT, maxT, limit int64, label string, blockIDs []
 	return req, nil
 }
 
+// setSeriesQueryStatsSpanTags attaches the stats of the blocks queried by a store-gateway
+// Series request to the span of the request.
+func setSeriesQueryStatsSpanTags(span opentracing.Span, s *hintspb.QueryStats) {
+	span.SetTag("blocks_queried", s.BlocksQueried)
+	span.SetTag("postings_touched", s.PostingsTouched)
+	span.SetTag("postings_touched_size_sum", s.PostingsTouchedSizeSum)
+	span.SetTag("postings_fetched", s.PostingsFetched)
+	span.SetTag("postings_fetched_size_sum", s.PostingsFetchedSizeSum)
+	span.SetTag("postings_cache_hit_ratio", postingsCacheHitRatio(s))
+	span.SetTag("series_touched", s.SeriesTouched)
+	span.SetTag("series_fetched", s.SeriesFetched)
+	span.SetTag("series_fetched_size_sum", s.SeriesFetchedSizeSum)
+	span.SetTag("series_cache_hit_ratio", seriesCacheHitRatio(s))
+	span.SetTag("chunks_touched", s.ChunksTouched)
+	span.SetTag("chunks_fetched", s.ChunksFetched)
+	span.SetTag("chunks_fetched_size_sum", s.ChunksFetchedSizeSum)
+	span.SetTag("data_downloaded_size_sum", s.DataDownloadedSizeSum)
+	span.SetTag("get_all_duration", s.GetAllDuration.String())
+	span.SetTag("merge_duration", s.MergeDuration.String())
+}
+
+// postingsCacheHitRatio returns the ratio of the postings touched by the store-gateway which
+// have been found in the index cache, so didn't need to be fetched from the bucket.
+func postingsCacheHitRatio(s *hintspb.QueryStats) float64 {
+	return cacheHitRatio(s.PostingsTouched, s.PostingsToFetch)
+}
+
+// seriesCacheHitRatio returns the ratio of the series touched by the store-gateway which
+// have been found in the index cache, so didn't need to be fetched from the bucket.
+func seriesCacheHitRatio(s *hintspb.QueryStats) float64 {
+	return cacheHitRatio(s.SeriesTouched, s.SeriesFetched)
+}
+
+func cacheHitRatio(touched, fetched int64) float64 {
+	if touched <= 0 || fetched >= touched {
+		return 0
+	}
+	return float64(touched-fetched) / float64(touched)
+}
+
 func convertULIDsToString(ids []ulid.ULID) []string {
 	res := make([]string, len(ids))
 	for idx, id := range ids {
@@ -1199,7 +1308,7 @@ func isRetryableError(err error) bool {
 	case codes.Unavailable:
 		return true
 	case codes.ResourceExhausted:
-		return errors.Is(err, storegateway.ErrTooManyInflightRequests)
+		return errors.Is(err, storegateway.ErrTooManyInflightRequests) || errors.Is(err, storegateway.ErrQueueTimeout)
 	// Client side connection closing, this error happens during store gateway deployment.
 	// https://github.com/grpc/grpc-go/blob/03172006f5d168fc646d87928d85cb9c4a480291/clientconn.go#L67
 	case codes.Canceled:
@@ -12,6 +12,8 @@ import (
 	"github.com/go-kit/log"
 	"github.com/gogo/protobuf/types"
 	"github.com/oklog/ulid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
@@ -64,7 +66,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 		metricNameLabel     = labels.Label{Name: labels.MetricName, Value: metricName}
 		series1Label        = labels.Label{Name: "series", Value: "1"}
 		series2Label        = labels.Label{Name: "series", Value: "2"}
-		noOpQueryLimiter    = limiter.NewQueryLimiter(0, 0, 0, 0)
+		noOpQueryLimiter    = limiter.NewQueryLimiter(0, 0, 0, 0, 0, 0)
 		testHistogram1      = tsdbutil.GenerateTestHistogram(1)
 		testHistogram2      = tsdbutil.GenerateTestHistogram(2)
 		testHistogram3      = tsdbutil.GenerateTestHistogram(3)
@@ -1014,7 +1016,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 				},
 			},
 			limits:       &blocksStoreLimitsMock{},
-			queryLimiter: limiter.NewQueryLimiter(0, 0, 1, 0),
+			queryLimiter: limiter.NewQueryLimiter(0, 0, 1, 0, 0, 0),
 			expectedErr:  validation.LimitError(fmt.Sprintf(limiter.ErrMaxChunksPerQueryLimit, 1)),
 		},
 		"max chunks per query limit hit while fetching histogram chunks at first attempt - global limit": {
@@ -1034,7 +1036,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 				},
 			},
 			limits:       &blocksStoreLimitsMock{},
-			queryLimiter: limiter.NewQueryLimiter(0, 0, 1, 0),
+			queryLimiter: limiter.NewQueryLimiter(0, 0, 1, 0, 0, 0),
 			expectedErr:  validation.LimitError(fmt.Sprintf(limiter.ErrMaxChunksPerQueryLimit, 1)),
 		},
 		"max chunks per query limit hit while fetching float histogram chunks at first attempt - global limit": {
@@ -1054,7 +1056,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 				},
 			},
 			limits:       &blocksStoreLimitsMock{},
-			queryLimiter: limiter.NewQueryLimiter(0, 0, 1, 0),
+			queryLimiter: limiter.NewQueryLimiter(0, 0, 1, 0, 0, 0),
 			expectedErr:  validation.LimitError(fmt.Sprintf(limiter.ErrMaxChunksPerQueryLimit, 1)),
 		},
 		"max chunks per query limit hit while fetching chunks during subsequent attempts": {
@@ -1130,7 +1132,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 				},
 			},
 			limits:       &blocksStoreLimitsMock{},
-			queryLimiter: limiter.NewQueryLimiter(0, 0, 3, 0),
+			queryLimiter: limiter.NewQueryLimiter(0, 0, 3, 0, 0, 0),
 			expectedErr:  validation.LimitError(fmt.Sprintf(limiter.ErrMaxChunksPerQueryLimit, 3)),
 		},
 		"max series per query limit hit while fetching chunks": {
@@ -1148,7 +1150,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 				},
 			},
 			limits:       &blocksStoreLimitsMock{},
-			queryLimiter: limiter.NewQueryLimiter(1, 0, 0, 0),
+			queryLimiter: limiter.NewQueryLimiter(1, 0, 0, 0, 0, 0),
 			expectedErr:  validation.LimitError(fmt.Sprintf(limiter.ErrMaxSeriesHit, 1)),
 		},
 		"max series per query limit hit while fetching histogram chunks": {
@@ -1174,7 +1176,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 				},
 			},
 			limits:       &blocksStoreLimitsMock{},
-			queryLimiter: limiter.NewQueryLimiter(1, 0, 0, 0),
+			queryLimiter: limiter.NewQueryLimiter(1, 0, 0, 0, 0, 0),
 			expectedErr:  validation.LimitError(fmt.Sprintf(limiter.ErrMaxSeriesHit, 1)),
 		},
 		"max series per query limit hit while fetching float histogram chunks": {
@@ -1200,7 +1202,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 				},
 			},
 			limits:       &blocksStoreLimitsMock{},
-			queryLimiter: limiter.NewQueryLimiter(1, 0, 0, 0),
+			queryLimiter: limiter.NewQueryLimiter(1, 0, 0, 0, 0, 0),
 			expectedErr:  validation.LimitError(fmt.Sprintf(limiter.ErrMaxSeriesHit, 1)),
 		},
 		"max chunk bytes per query limit hit while fetching chunks": {
@@ -1217,7 +1219,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 				},
 			},
 			limits:       &blocksStoreLimitsMock{maxChunksPerQuery: 0},
-			queryLimiter: limiter.NewQueryLimiter(0, 8, 0, 0),
+			queryLimiter: limiter.NewQueryLimiter(0, 8, 0, 0, 0, 0),
 			expectedErr:  validation.LimitError(fmt.Sprintf(limiter.ErrMaxChunkBytesHit, 8)),
 		},
 		"max chunk bytes per query limit hit while fetching histogram chunks": {
@@ -1236,7 +1238,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 				},
 			},
 			limits:       &blocksStoreLimitsMock{maxChunksPerQuery: 0},
-			queryLimiter: limiter.NewQueryLimiter(0, 8, 0, 0),
+			queryLimiter: limiter.NewQueryLimiter(0, 8, 0, 0, 0, 0),
 			expectedErr:  validation.LimitError(fmt.Sprintf(limiter.ErrMaxChunkBytesHit, 8)),
 		},
 		"max chunk bytes per query limit hit while fetching float histogram chunks": {
@@ -1255,7 +1257,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 				},
 			},
 			limits:       &blocksStoreLimitsMock{maxChunksPerQuery: 0},
-			queryLimiter: limiter.NewQueryLimiter(0, 8, 0, 0),
+			queryLimiter: limiter.NewQueryLimiter(0, 8, 0, 0, 0, 0),
 			expectedErr:  validation.LimitError(fmt.Sprintf(limiter.ErrMaxChunkBytesHit, 8)),
 		},
 		"max data bytes per query limit hit while fetching chunks": {
@@ -1272,7 +1274,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 				},
 			},
 			limits:       &blocksStoreLimitsMock{maxChunksPerQuery: 0},
-			queryLimiter: limiter.NewQueryLimiter(0, 0, 0, 1),
+			queryLimiter: limiter.NewQueryLimiter(0, 0, 0, 1, 0, 0),
 			expectedErr:  validation.LimitError(fmt.Sprintf(limiter.ErrMaxDataBytesHit, 1)),
 		},
 		"max data bytes per query limit hit while fetching histogram chunks": {
@@ -1291,7 +1293,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 				},
 			},
 			limits:       &blocksStoreLimitsMock{maxChunksPerQuery: 0},
-			queryLimiter: limiter.NewQueryLimiter(0, 0, 0, 1),
+			queryLimiter: limiter.NewQueryLimiter(0, 0, 0, 1, 0, 0),
 			expectedErr:  validation.LimitError(fmt.Sprintf(limiter.ErrMaxDataBytesHit, 1)),
 		},
 		"max data bytes per query limit hit while fetching float histogram chunks": {
@@ -1310,7 +1312,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 				},
 			},
 			limits:       &blocksStoreLimitsMock{maxChunksPerQuery: 0},
-			queryLimiter: limiter.NewQueryLimiter(0, 0, 0, 1),
+			queryLimiter: limiter.NewQueryLimiter(0, 0, 0, 1, 0, 0),
 			expectedErr:  validation.LimitError(fmt.Sprintf(limiter.ErrMaxDataBytesHit, 1)),
 		},
 		"multiple store-gateways has the block, but one of them fails to return": {
@@ -1621,6 +1623,155 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 	}
 }
 
+func TestBlocksStoreQuerier_Select_PartialResults(t *testing.T) {
+	t.Parallel()
+
+	const (
+		minT = int64(10)
+		maxT = int64(20)
+	)
+
+	var (
+		block1          = ulid.MustNew(1, nil)
+		block2          = ulid.MustNew(2, nil)
+		metricNameLabel = labels.Label{Name: labels.MetricName, Value: "test_metric"}
+	)
+
+	ctx := user.InjectOrgID(context.Background(), "user-1")
+	ctx = limiter.AddQueryLimiterToContext(ctx, limiter.NewQueryLimiter(0, 0, 0, 0, 0, 0))
+	stores := &blocksStoreSetMock{mockedResponses: []interface{}{
+		map[BlocksStoreClient][]ulid.ULID{
+			&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedSeriesResponses: []*storepb.SeriesResponse{
+				mockSeriesResponse(labels.Labels{metricNameLabel}, []cortexpb.Sample{{Value: 1, TimestampMs: minT}}, nil, nil),
+				mockHintsResponse(block1),
+			}}: {block1},
+		},
+		errors.New("no store-gateway remaining after exclude"),
+	}}
+	finder := &blocksFinderMock{}
+	finder.On("GetBlocks", mock.Anything, "user-1", minT, maxT).Return(bucketindex.Blocks{
+		&bucketindex.Block{ID: block1, MinTime: 0, MaxTime: 7200000},
+		&bucketindex.Block{ID: block2, MinTime: 7200000, MaxTime: 14400000},
+	}, map[ulid.ULID]*bucketindex.BlockDeletionMark(nil), nil)
+
+	q := &blocksStoreQuerier{
+		minT:        minT,
+		maxT:        maxT,
+		finder:      finder,
+		stores:      stores,
+		consistency: NewBlocksConsistencyChecker(0, 0, log.NewNopLogger(), nil),
+		logger:      log.NewNopLogger(),
+		metrics:     newBlocksStoreQueryableMetrics(prometheus.NewPedanticRegistry()),
+		limits:      &blocksStoreLimitsMock{queryPartialResults: true},
+
+		storeGatewayConsistencyCheckMaxAttempts: 3,
+	}
+
+	set := q.Select(ctx, true, nil, labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "test_metric"))
+
+	// The series of the queried block are returned.
+	require.True(t, set.Next())
+	assert.Equal(t, labels.Labels{metricNameLabel}, set.At().Labels())
+	require.False(t, set.Next())
+	require.NoError(t, set.Err())
+
+	// The missing block is listed in the warnings.
+	assert.Equal(t, []error{
+		fmt.Errorf("partial results: some blocks have not been queried from the store-gateways: %s (%s to %s)", block2.String(), "1970-01-01T02:00:00Z", "1970-01-01T04:00:00Z"),
+	}, set.Warnings().AsErrors())
+}
+
+func TestBlocksStoreQuerier_Select_ShouldAttachStoreGatewayQueryStatsToSpans(t *testing.T) {
+	const (
+		minT = int64(10)
+		maxT = int64(20)
+	)
+
+	var (
+		block1          = ulid.MustNew(1, nil)
+		metricNameLabel = labels.Label{Name: labels.MetricName, Value: "test_metric"}
+	)
+
+	mockTracer := mocktracer.New()
+	opentracing.SetGlobalTracer(mockTracer)
+	defer opentracing.SetGlobalTracer(opentracing.NoopTracer{})
+
+	hints := &hintspb.SeriesResponseHints{QueryStats: &hintspb.QueryStats{
+		BlocksQueried:         1,
+		PostingsTouched:       4,
+		PostingsToFetch:       1,
+		PostingsFetched:       1,
+		SeriesTouched:         10,
+		SeriesFetched:         5,
+		ChunksTouched:         20,
+		ChunksFetched:         20,
+		DataDownloadedSizeSum: 1024,
+	}}
+	hints.AddQueriedBlock(block1)
+	hintsAny, err := types.MarshalAny(hints)
+	require.NoError(t, err)
+
+	ctx := user.InjectOrgID(context.Background(), "user-1")
+	ctx = limiter.AddQueryLimiterToContext(ctx, limiter.NewQueryLimiter(0, 0, 0, 0, 0, 0))
+	stores := &blocksStoreSetMock{mockedResponses: []interface{}{
+		map[BlocksStoreClient][]ulid.ULID{
+			&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedSeriesResponses: []*storepb.SeriesResponse{
+				mockSeriesResponse(labels.Labels{metricNameLabel}, []cortexpb.Sample{{Value: 1, TimestampMs: minT}}, nil, nil),
+				{Result: &storepb.SeriesResponse_Hints{Hints: hintsAny}},
+			}}: {block1},
+		},
+	}}
+	finder := &blocksFinderMock{}
+	finder.On("GetBlocks", mock.Anything, "user-1", minT, maxT).Return(bucketindex.Blocks{
+		&bucketindex.Block{ID: block1},
+	}, map[ulid.ULID]*bucketindex.BlockDeletionMark(nil), nil)
+
+	q := &blocksStoreQuerier{
+		minT:        minT,
+		maxT:        maxT,
+		finder:      finder,
+		stores:      stores,
+		consistency: NewBlocksConsistencyChecker(0, 0, log.NewNopLogger(), nil),
+		logger:      log.NewNopLogger(),
+		metrics:     newBlocksStoreQueryableMetrics(prometheus.NewPedanticRegistry()),
+		limits:      &blocksStoreLimitsMock{},
+
+		storeGatewayConsistencyCheckMaxAttempts: 3,
+	}
+
+	set := q.Select(ctx, true, nil, labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "test_metric"))
+	require.True(t, set.Next())
+	require.False(t, set.Next())
+	require.NoError(t, set.Err())
+
+	var seriesSpan *mocktracer.MockSpan
+	for _, span := range mockTracer.FinishedSpans() {
+		if span.OperationName == "blocksStoreQuerier.fetchSeriesFromStore" {
+			seriesSpan = span
+		}
+	}
+	require.NotNil(t, seriesSpan)
+
+	assert.Equal(t, "1.1.1.1", seriesSpan.Tag("instance"))
+	assert.Equal(t, block1.String(), seriesSpan.Tag("requested_blocks"))
+	assert.Equal(t, block1.String(), seriesSpan.Tag("queried_blocks"))
+	assert.Equal(t, 1, seriesSpan.Tag("fetched_series"))
+	assert.Equal(t, int64(4), seriesSpan.Tag("postings_touched"))
+	assert.Equal(t, 0.75, seriesSpan.Tag("postings_cache_hit_ratio"))
+	assert.Equal(t, int64(5), seriesSpan.Tag("series_fetched"))
+	assert.Equal(t, 0.5, seriesSpan.Tag("series_cache_hit_ratio"))
+	assert.Equal(t, int64(20), seriesSpan.Tag("chunks_fetched"))
+	assert.Equal(t, int64(1024), seriesSpan.Tag("data_downloaded_size_sum"))
+}
+
+func TestCacheHitRatio(t *testing.T) {
+	assert.Equal(t, 0.0, cacheHitRatio(0, 0))
+	assert.Equal(t, 0.0, cacheHitRatio(10, 10))
+	assert.Equal(t, 0.0, cacheHitRatio(10, 20))
+	assert.Equal(t, 0.25, cacheHitRatio(4, 3))
+	assert.Equal(t, 1.0, cacheHitRatio(4, 0))
+}
+
 func TestBlocksStoreQuerier_Labels(t *testing.T) {
 	t.Parallel()
 
@@ -2458,6 +2609,11 @@ func (m *blocksFinderMock) GetBlocks(ctx context.Context, userID string, minT, m
 	return args.Get(0).(bucketindex.Blocks), args.Get(1).(map[ulid.ULID]*bucketindex.BlockDeletionMark), args.Error(2)
 }
 
+func (m *blocksFinderMock) SyncUserBlocks(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
 type storeGatewayClientMock struct {
 	remoteAddr                string
 	mockedSeriesResponses     []*storepb.SeriesResponse
@@ -2520,6 +2676,7 @@ func (m *storeGatewaySeriesClientMock) Recv() (*storepb.SeriesResponse, error) {
 type blocksStoreLimitsMock struct {
 	maxChunksPerQuery           int
 	storeGatewayTenantShardSize float64
+	queryPartialResults         bool
 }
 
 func (m *blocksStoreLimitsMock) MaxChunksPerQueryFromStore(_ string) int {
@@ -2530,6 +2687,10 @@ func (m *blocksStoreLimitsMock) StoreGatewayTenantShardSize(_ string) float64 {
 	return m.storeGatewayTenantShardSize
 }
 
+func (m *blocksStoreLimitsMock) QueryPartialResults(_ string) bool {
+	return m.queryPartialResults
+}
+
 func (m *blocksStoreLimitsMock) S3SSEType(_ string) string {
 	return ""
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"sort"
 
 	"github.com/go-kit/log"
 	"github.com/oklog/ulid"
@@ -40,6 +41,9 @@ type blocksStoreReplicationSet struct {
 	zoneAwarenessEnabled      bool
 	zoneStableShuffleSharding bool
 
+	// Zone of the store-gateways to query first, if any.
+	preferredZone string
+
 	// Subservices manager.
 	subservices        *services.Manager
 	subservicesWatcher *services.FailureWatcher
@@ -55,6 +59,7 @@ func newBlocksStoreReplicationSet(
 	reg prometheus.Registerer,
 	zoneAwarenessEnabled bool,
 	zoneStableShuffleSharding bool,
+	preferredZone string,
 ) (*blocksStoreReplicationSet, error) {
 	s := &blocksStoreReplicationSet{
 		storesRing:        storesRing,
@@ -65,6 +70,7 @@ func newBlocksStoreReplicationSet(
 
 		zoneAwarenessEnabled:      zoneAwarenessEnabled,
 		zoneStableShuffleSharding: zoneStableShuffleSharding,
+		preferredZone:             preferredZone,
 	}
 
 	var err error
@@ -127,7 +133,7 @@ func (s *blocksStoreReplicationSet) GetClientsFor(userID string, blockIDs []ulid
 		}
 
 		// Pick a non excluded store-gateway instance.
-		instance := getNonExcludedInstance(set, exclude[blockID], s.balancingStrategy, s.zoneAwarenessEnabled, attemptedBlocksZones[blockID])
+		instance := getNonExcludedInstance(set, exclude[blockID], s.balancingStrategy, s.zoneAwarenessEnabled, attemptedBlocksZones[blockID], s.preferredZone)
 		// A valid instance should have a non-empty address.
 		if instance.Addr == "" {
 			return nil, fmt.Errorf("no store-gateway instance left after checking exclude for block %s", blockID.String())
@@ -157,7 +163,7 @@ func (s *blocksStoreReplicationSet) GetClientsFor(userID string, blockIDs []ulid
 	return clients, nil
 }
 
-func getNonExcludedInstance(set ring.ReplicationSet, exclude []string, balancingStrategy loadBalancingStrategy, zoneAwarenessEnabled bool, attemptedZones map[string]int) ring.InstanceDesc {
+func getNonExcludedInstance(set ring.ReplicationSet, exclude []string, balancingStrategy loadBalancingStrategy, zoneAwarenessEnabled bool, attemptedZones map[string]int, preferredZone string) ring.InstanceDesc {
 	if balancingStrategy == randomLoadBalancing {
 		// Randomize the list of instances to not always query the same one.
 		rand.Shuffle(len(set.Instances), func(i, j int) {
@@ -165,6 +171,19 @@ func getNonExcludedInstance(set ring.ReplicationSet, exclude []string, balancing
 		})
 	}
 
+	if preferredZone != "" {
+		// Move the instances of the preferred zone first, to query them before the ones of other zones.
+		sort.SliceStable(set.Instances, func(i, j int) bool {
+			return set.Instances[i].Zone == preferredZone && set.Instances[j].Zone != preferredZone
+		})
+	}
+
+	// Move the LOADING instances last, because they may have not loaded the block yet while
+	// the previous owner of the block is expected to still have it.
+	sort.SliceStable(set.Instances, func(i, j int) bool {
+		return set.Instances[i].State != ring.LOADING && set.Instances[j].State == ring.LOADING
+	})
+
 	minAttempt := math.MaxInt
 	numOfZone := set.GetNumOfZones()
 	// There are still unattempted zones so we know min is 0.
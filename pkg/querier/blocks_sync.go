@@ -0,0 +1,46 @@
+package querier
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+)
+
+const tenantParam = "tenant"
+
+// BlocksSyncer immediately refreshes the known blocks of a tenant.
+type BlocksSyncer interface {
+	SyncUserBlocks(ctx context.Context, userID string) error
+}
+
+// BlocksSyncHandler returns an HTTP handler triggering an immediate sync of the blocks of the
+// tenants given by the tenant parameter. It returns once the sync has been completed.
+func BlocksSyncHandler(syncer BlocksSyncer, logger log.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			level.Warn(util_log.WithContext(r.Context(), logger)).Log("msg", "failed to parse HTTP request in blocks sync handler", "err", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		tenants := r.Form[tenantParam]
+		if len(tenants) == 0 {
+			http.Error(w, "at least one tenant is required", http.StatusBadRequest)
+			return
+		}
+
+		for _, userID := range tenants {
+			if err := syncer.SyncUserBlocks(r.Context(), userID); err != nil {
+				level.Warn(util_log.WithUserID(userID, logger)).Log("msg", "failed to sync user blocks", "err", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -1,8 +1,14 @@
 package querier
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/prometheus/prometheus/scrape"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/util"
 )
 
@@ -23,11 +29,41 @@ type metadataResult struct {
 	Error  string                      `json:"error,omitempty"`
 }
 
+// MetadataQuerier returns the metric metadata of the tenant of the request.
+type MetadataQuerier interface {
+	MetricsMetadata(ctx context.Context, req *client.MetricsMetadataRequest) ([]scrape.MetricMetadata, error)
+}
+
 // MetadataHandler returns metric metadata held by Cortex for a given tenant.
-// It is kept and returned as a set.
-func MetadataHandler(d Distributor) http.Handler {
+// It is kept and returned as a set. Like Prometheus, it supports the limit,
+// limit_per_metric and metric parameters.
+func MetadataHandler(d MetadataQuerier) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		resp, err := d.MetricsMetadata(r.Context())
+		limit, err := parseMetadataLimit(r, "limit")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			util.WriteJSONResponse(w, metadataResult{Status: statusError, Error: err.Error()})
+			return
+		}
+		limitPerMetric, err := parseMetadataLimit(r, "limit_per_metric")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			util.WriteJSONResponse(w, metadataResult{Status: statusError, Error: err.Error()})
+			return
+		}
+		if limit == 0 {
+			// No metric is requested.
+			util.WriteJSONResponse(w, metadataResult{Status: statusSuccess, Data: map[string][]metricMetadata{}})
+			return
+		}
+
+		// A negative limit means no limit, like 0 in the request to the ingesters.
+		req := &client.MetricsMetadataRequest{
+			Limit:          max(limit, 0),
+			LimitPerMetric: max(limitPerMetric, 0),
+			Metric:         r.FormValue("metric"),
+		}
+		resp, err := d.MetricsMetadata(r.Context(), req)
 		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
 			util.WriteJSONResponse(w, metadataResult{Status: statusError, Error: err.Error()})
@@ -49,3 +85,16 @@ func MetadataHandler(d Distributor) http.Handler {
 		util.WriteJSONResponse(w, metadataResult{Status: statusSuccess, Data: metrics})
 	})
 }
+
+// parseMetadataLimit returns the value of the limit parameter, -1 if it's not set.
+func parseMetadataLimit(r *http.Request, param string) (int64, error) {
+	s := r.FormValue(param)
+	if s == "" {
+		return -1, nil
+	}
+	limit, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", param)
+	}
+	return limit, nil
+}
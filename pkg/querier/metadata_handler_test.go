@@ -10,13 +10,15 @@ import (
 	"github.com/prometheus/prometheus/scrape"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
 )
 
 func TestMetadataHandler_Success(t *testing.T) {
 	t.Parallel()
 
 	d := &MockDistributor{}
-	d.On("MetricsMetadata", mock.Anything).Return(
+	d.On("MetricsMetadata", mock.Anything, mock.Anything).Return(
 		[]scrape.MetricMetadata{
 			{Metric: "alertmanager_dispatcher_aggregation_groups", Help: "Number of active aggregation groups", Type: "gauge", Unit: ""},
 		},
@@ -56,7 +58,7 @@ func TestMetadataHandler_Error(t *testing.T) {
 	t.Parallel()
 
 	d := &MockDistributor{}
-	d.On("MetricsMetadata", mock.Anything).Return([]scrape.MetricMetadata{}, fmt.Errorf("no user id"))
+	d.On("MetricsMetadata", mock.Anything, mock.Anything).Return([]scrape.MetricMetadata{}, fmt.Errorf("no user id"))
 
 	handler := MetadataHandler(d)
 
@@ -79,3 +81,66 @@ func TestMetadataHandler_Error(t *testing.T) {
 
 	require.JSONEq(t, expectedJSON, string(responseBody))
 }
+
+func TestMetadataHandler_Limits(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		query          string
+		expectedReq    *client.MetricsMetadataRequest
+		expectedStatus int
+		expectedJSON   string
+	}{
+		"should pass the limits to the distributor": {
+			query:          "limit=10&limit_per_metric=2&metric=up",
+			expectedReq:    &client.MetricsMetadataRequest{Limit: 10, LimitPerMetric: 2, Metric: "up"},
+			expectedStatus: http.StatusOK,
+		},
+		"should not limit with negative limits": {
+			query:          "limit=-1&limit_per_metric=-1",
+			expectedReq:    &client.MetricsMetadataRequest{},
+			expectedStatus: http.StatusOK,
+		},
+		"should return no metadata with a zero limit": {
+			query:          "limit=0",
+			expectedStatus: http.StatusOK,
+			expectedJSON:   `{"status": "success"}`,
+		},
+		"should fail with an invalid limit": {
+			query:          "limit=foo",
+			expectedStatus: http.StatusBadRequest,
+			expectedJSON:   `{"status": "error", "error": "limit must be a number"}`,
+		},
+		"should fail with an invalid limit per metric": {
+			query:          "limit_per_metric=foo",
+			expectedStatus: http.StatusBadRequest,
+			expectedJSON:   `{"status": "error", "error": "limit_per_metric must be a number"}`,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			d := &MockDistributor{}
+			d.On("MetricsMetadata", mock.Anything, mock.Anything).Return([]scrape.MetricMetadata{}, nil)
+
+			request, err := http.NewRequest("GET", "/metadata?"+tc.query, nil)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			MetadataHandler(d).ServeHTTP(recorder, request)
+
+			require.Equal(t, tc.expectedStatus, recorder.Result().StatusCode)
+			if tc.expectedJSON != "" {
+				responseBody, err := io.ReadAll(recorder.Result().Body)
+				require.NoError(t, err)
+				require.JSONEq(t, tc.expectedJSON, string(responseBody))
+			}
+			if tc.expectedReq != nil {
+				d.AssertCalled(t, "MetricsMetadata", mock.Anything, tc.expectedReq)
+			} else {
+				d.AssertNotCalled(t, "MetricsMetadata", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package querier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/util/annotations"
+
+	"github.com/cortexproject/cortex/pkg/querier/partialdata"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// partialResultsSeriesSet returns the series received before the failure of the
+// underlying set, and a warning listing the time range which could not be queried
+// instead of the error, unless the error must fail the query.
+type partialResultsSeriesSet struct {
+	storage.SeriesSet
+	minT, maxT int64
+	warning    error
+}
+
+func newPartialResultsSeriesSet(set storage.SeriesSet, minT, maxT int64) storage.SeriesSet {
+	return &partialResultsSeriesSet{SeriesSet: set, minT: minT, maxT: maxT}
+}
+
+func (s *partialResultsSeriesSet) Next() bool {
+	if s.warning != nil {
+		return false
+	}
+	if s.SeriesSet.Next() {
+		return true
+	}
+	if err := s.SeriesSet.Err(); err != nil && isPartialResultsError(err) {
+		s.warning = fmt.Errorf(partialdata.WarningPrefix+"the time range %s to %s has not been fully queried: %w", formatPartialResultsTime(s.minT), formatPartialResultsTime(s.maxT), err)
+	}
+	return false
+}
+
+func (s *partialResultsSeriesSet) Err() error {
+	if s.warning != nil {
+		return nil
+	}
+	return s.SeriesSet.Err()
+}
+
+func (s *partialResultsSeriesSet) Warnings() annotations.Annotations {
+	warnings := s.SeriesSet.Warnings()
+	if s.warning == nil {
+		return warnings
+	}
+
+	var res annotations.Annotations
+	res.Merge(warnings)
+	return res.Add(s.warning)
+}
+
+// isPartialResultsError returns whether the query can return partial results on
+// the error. The limit errors and the canceled queries always fail the query.
+func isPartialResultsError(err error) bool {
+	switch errors.Cause(err).(type) {
+	case validation.LimitError, validation.AccessDeniedError, promql.ErrTooManySamples, promql.ErrQueryCanceled, promql.ErrQueryTimeout:
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// formatPartialResultsTime formats the timestamp in milliseconds of the partial results warnings.
+func formatPartialResultsTime(ms int64) string {
+	return util.TimeFromMillis(ms).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,58 @@
+package querier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestPartialResultsSeriesSet(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		err             error
+		expectedErr     error
+		expectedWarning string
+	}{
+		"should return no warning on success": {},
+		"should return a warning instead of a store error": {
+			err:             errors.New("failed to fetch series from 1.1.1.1"),
+			expectedWarning: "partial results: the time range 1970-01-01T00:00:00Z to 1970-01-01T01:00:00Z has not been fully queried: failed to fetch series from 1.1.1.1",
+		},
+		"should return a limit error": {
+			err:         validation.LimitError("the query hit the max number of chunks limit"),
+			expectedErr: validation.LimitError("the query hit the max number of chunks limit"),
+		},
+		"should return a canceled query error": {
+			err:         context.Canceled,
+			expectedErr: context.Canceled,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			set := storage.EmptySeriesSet()
+			if tc.err != nil {
+				set = storage.ErrSeriesSet(tc.err)
+			}
+			set = newPartialResultsSeriesSet(set, 0, 3600000)
+
+			require.False(t, set.Next())
+			assert.Equal(t, tc.expectedErr, set.Err())
+			if tc.expectedWarning == "" {
+				assert.Empty(t, set.Warnings())
+				return
+			}
+			warnings := set.Warnings().AsErrors()
+			require.Len(t, warnings, 1)
+			assert.EqualError(t, warnings[0], tc.expectedWarning)
+		})
+	}
+}
@@ -0,0 +1,5 @@
+package partialdata
+
+// WarningPrefix is the prefix of the warnings of the partial results,
+// whose responses must not be cached.
+const WarningPrefix = "partial results: "
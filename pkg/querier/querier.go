@@ -78,6 +78,7 @@ type Config struct {
 	StoreGatewayAddresses         string       `yaml:"store_gateway_addresses"`
 	StoreGatewayClient            ClientConfig `yaml:"store_gateway_client"`
 	StoreGatewayQueryStatsEnabled bool         `yaml:"store_gateway_query_stats"`
+	StoreGatewayPreferredZone     string       `yaml:"store_gateway_preferred_zone"`
 
 	// The maximum number of times we attempt fetching missing blocks from different Store Gateways.
 	StoreGatewayConsistencyCheckMaxAttempts int `yaml:"store_gateway_consistency_check_max_attempts"`
@@ -96,7 +97,7 @@ var (
 	errBadLookbackConfigs                             = errors.New("bad settings, query_store_after >= query_ingesters_within which can result in queries not being sent")
 	errShuffleShardingLookbackLessThanQueryStoreAfter = errors.New("the shuffle-sharding lookback period should be greater or equal than the configured 'query store after'")
 	errEmptyTimeRange                                 = errors.New("empty time range")
-	errUnsupportedResponseCompression                 = errors.New("unsupported response compression. Supported compression 'gzip' and '' (disable compression)")
+	errUnsupportedResponseCompression                 = errors.New("unsupported response compression. Supported compression 'gzip', 'snappy' and '' (disable compression)")
 	errInvalidConsistencyCheckAttempts                = errors.New("store gateway consistency check max attempts should be greater or equal than 1")
 )
 
@@ -119,13 +120,14 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.MaxSamples, "querier.max-samples", 50e6, "Maximum number of samples a single query can load into memory.")
 	f.DurationVar(&cfg.QueryIngestersWithin, "querier.query-ingesters-within", 0, "Maximum lookback beyond which queries are not sent to ingester. 0 means all queries are sent to ingester.")
 	f.BoolVar(&cfg.EnablePerStepStats, "querier.per-step-stats-enabled", false, "Enable returning samples stats per steps in query response.")
-	f.StringVar(&cfg.ResponseCompression, "querier.response-compression", "gzip", "Use compression for metrics query API or instant and range query APIs. Supports 'gzip' and '' (disable compression)")
+	f.StringVar(&cfg.ResponseCompression, "querier.response-compression", "gzip", "Use compression for metrics query API or instant and range query APIs. Supports 'gzip', 'snappy' and '' (disable compression)")
 	f.DurationVar(&cfg.MaxQueryIntoFuture, "querier.max-query-into-future", 10*time.Minute, "Maximum duration into the future you can query. 0 to disable.")
 	f.DurationVar(&cfg.DefaultEvaluationInterval, "querier.default-evaluation-interval", time.Minute, "The default evaluation interval or step size for subqueries.")
 	f.DurationVar(&cfg.QueryStoreAfter, "querier.query-store-after", 0, "The time after which a metric should be queried from storage and not just ingesters. 0 means all queries are sent to store. When running the blocks storage, if this option is enabled, the time range of the query sent to the store will be manipulated to ensure the query end is not more recent than 'now - query-store-after'.")
 	f.StringVar(&cfg.ActiveQueryTrackerDir, "querier.active-query-tracker-dir", "./active-query-tracker", "Active query tracker monitors active queries, and writes them to the file in given directory. If Cortex discovers any queries in this log during startup, it will log them to the log file. Setting to empty value disables active query tracker, which also disables -querier.max-concurrent option.")
 	f.StringVar(&cfg.StoreGatewayAddresses, "querier.store-gateway-addresses", "", "Comma separated list of store-gateway addresses in DNS Service Discovery format. This option should be set when using the blocks storage and the store-gateway sharding is disabled (when enabled, the store-gateway instances form a ring and addresses are picked from the ring).")
 	f.BoolVar(&cfg.StoreGatewayQueryStatsEnabled, "querier.store-gateway-query-stats-enabled", true, "If enabled, store gateway query stats will be logged using `info` log level.")
+	f.StringVar(&cfg.StoreGatewayPreferredZone, "querier.store-gateway-preferred-zone", "", "[Experimental] Availability zone of the store-gateways to query first, usually the zone of the querier, to cut the cross-zone traffic. The store-gateways of the other zones are queried when the blocks are not available in the preferred zone, or on retries. Only applies when the store-gateway sharding is enabled. Empty to disable.")
 	f.IntVar(&cfg.StoreGatewayConsistencyCheckMaxAttempts, "querier.store-gateway-consistency-check-max-attempts", maxFetchSeriesAttempts, "The maximum number of times we attempt fetching missing blocks from different store-gateways. If no more store-gateways are left (ie. due to lower replication factor) than we'll end the retries earlier")
 	f.DurationVar(&cfg.LookbackDelta, "querier.lookback-delta", 5*time.Minute, "Time since the last sample after which a time series is considered stale and ignored by expression evaluations.")
 	f.DurationVar(&cfg.ShuffleShardingIngestersLookbackPeriod, "querier.shuffle-sharding-ingesters-lookback-period", 0, "When distributor's sharding strategy is shuffle-sharding and this setting is > 0, queriers fetch in-memory series from the minimum set of required ingesters, selecting only ingesters which may have received series since 'now - lookback period'. The lookback period should be greater or equal than the configured 'query store after' and 'query ingesters within'. If this setting is 0, queriers always query all ingesters (ingesters shuffle sharding on read path is disabled).")
@@ -143,7 +145,7 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
-	if cfg.ResponseCompression != "" && cfg.ResponseCompression != "gzip" {
+	if cfg.ResponseCompression != "" && cfg.ResponseCompression != "gzip" && cfg.ResponseCompression != "snappy" {
 		return errUnsupportedResponseCompression
 	}
 
@@ -173,7 +175,7 @@ func getChunksIteratorFunction(_ Config) chunkIteratorFunc {
 }
 
 // New builds a queryable and promql engine.
-func New(cfg Config, limits *validation.Overrides, distributor Distributor, stores []QueryableWithFilter, reg prometheus.Registerer, logger log.Logger) (storage.SampleAndChunkQueryable, storage.ExemplarQueryable, promql.QueryEngine) {
+func New(cfg Config, limits *validation.Overrides, distributor Distributor, stores []QueryableWithFilter, tracker promql.QueryTracker, reg prometheus.Registerer, logger log.Logger) (storage.SampleAndChunkQueryable, storage.ExemplarQueryable, promql.QueryEngine) {
 	iteratorFunc := getChunksIteratorFunction(cfg)
 
 	distributorQueryable := newDistributorQueryable(distributor, cfg.IngesterMetadataStreaming, cfg.IngesterLabelNamesWithMatchers, iteratorFunc, cfg.QueryIngestersWithin)
@@ -208,7 +210,7 @@ func New(cfg Config, limits *validation.Overrides, distributor Distributor, stor
 	opts := promql.EngineOpts{
 		Logger:               logger,
 		Reg:                  reg,
-		ActiveQueryTracker:   createActiveQueryTracker(cfg, logger),
+		ActiveQueryTracker:   tracker,
 		MaxSamples:           cfg.MaxSamples,
 		Timeout:              cfg.Timeout,
 		LookbackDelta:        cfg.LookbackDelta,
@@ -244,16 +246,6 @@ func (q *sampleAndChunkQueryable) ChunkQuerier(mint, maxt int64) (storage.ChunkQ
 	return nil, errors.New("ChunkQuerier not implemented")
 }
 
-func createActiveQueryTracker(cfg Config, logger log.Logger) promql.QueryTracker {
-	dir := cfg.ActiveQueryTrackerDir
-
-	if dir != "" {
-		return promql.NewActiveQueryTracker(dir, cfg.MaxConcurrent, logger)
-	}
-
-	return nil
-}
-
 // QueryableWithFilter extends Queryable interface with `UseQueryable` filtering function.
 type QueryableWithFilter interface {
 	storage.Queryable
@@ -310,7 +302,7 @@ func (q querier) setupFromCtx(ctx context.Context) (context.Context, *querier_st
 	}
 
 	q.limiterHolder.limiterInitializer.Do(func() {
-		q.limiterHolder.limiter = limiter.NewQueryLimiter(q.limits.MaxFetchedSeriesPerQuery(userID), q.limits.MaxFetchedChunkBytesPerQuery(userID), q.limits.MaxChunksPerQuery(userID), q.limits.MaxFetchedDataBytesPerQuery(userID))
+		q.limiterHolder.limiter = limiter.NewQueryLimiter(q.limits.MaxFetchedSeriesPerQuery(userID), q.limits.MaxFetchedChunkBytesPerQuery(userID), q.limits.MaxChunksPerQuery(userID), q.limits.MaxFetchedDataBytesPerQuery(userID), q.limits.MaxSamplesPerQuery(userID), q.limits.MaxEstimatedMemoryPerQuery(userID))
 	})
 
 	ctx = limiter.AddQueryLimiterToContext(ctx, q.limiterHolder.limiter)
@@ -413,15 +405,32 @@ func (q querier) Select(ctx context.Context, sortSeries bool, sp *storage.Select
 		}
 	}
 
+	// The series API doesn't load any sample into the query engine.
+	samplesLimiter := q.limiterHolder.limiter
+	if getSeries {
+		samplesLimiter = nil
+	}
+
+	// When the partial results are enabled, the failures of the queriers are
+	// returned as warnings instead of failing the query.
+	partialResults := q.limits.QueryPartialResults(userID)
+	selectFn := func(querier storage.Querier, sortSeries bool) storage.SeriesSet {
+		set := querier.Select(ctx, sortSeries, sp, matchers...)
+		if partialResults {
+			return newPartialResultsSeriesSet(set, sp.Start, sp.End)
+		}
+		return set
+	}
+
 	if len(queriers) == 1 {
-		return queriers[0].Select(ctx, sortSeries, sp, matchers...)
+		return newSamplesLimitedSeriesSet(selectFn(queriers[0], sortSeries), samplesLimiter)
 	}
 
 	sets := make(chan storage.SeriesSet, len(queriers))
 	for _, querier := range queriers {
 		go func(querier storage.Querier) {
 			// We should always select sorted here as we will need to merge the series
-			sets <- querier.Select(ctx, true, sp, matchers...)
+			sets <- selectFn(querier, true)
 		}(querier)
 	}
 
@@ -435,7 +444,7 @@ func (q querier) Select(ctx context.Context, sortSeries bool, sp *storage.Select
 		}
 	}
 
-	return storage.NewMergeSeriesSet(result, storage.ChainedSeriesMerge)
+	return newSamplesLimitedSeriesSet(storage.NewMergeSeriesSet(result, storage.ChainedSeriesMerge), samplesLimiter)
 }
 
 // LabelValues implements storage.Querier.
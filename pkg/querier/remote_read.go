@@ -1,25 +1,39 @@
 package querier
 
 import (
+	"context"
 	"net/http"
+	"sync"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/prometheus/prometheus/prompb"
 	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/storage/remote"
 
 	"github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/util"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
 )
 
-// Queries are a set of matchers with time ranges - should not get into megabytes
-const maxRemoteReadQuerySize = 1024 * 1024
+const (
+	// Queries are a set of matchers with time ranges - should not get into megabytes
+	maxRemoteReadQuerySize = 1024 * 1024
 
-// RemoteReadHandler handles Prometheus remote read requests.
+	// Maximum size of the frames of the streamed remote read responses, like the Prometheus default.
+	// A series bigger than this is split over several frames.
+	remoteReadMaxBytesInFrame = 1024 * 1024
+)
+
+// RemoteReadHandler handles Prometheus remote read requests. The responses are
+// streamed series by series if the client accepts the STREAMED_XOR_CHUNKS
+// response type, otherwise the samples of all queries are sent in a single response.
 func RemoteReadHandler(q storage.Queryable, logger log.Logger) http.Handler {
+	marshalPool := &sync.Pool{}
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		var req client.ReadRequest
+		var req prompb.ReadRequest
 		logger := util_log.WithContext(r.Context(), logger)
 		if err := util.ParseProtoReader(ctx, r.Body, int(r.ContentLength), maxRemoteReadQuerySize, &req, util.RawSnappy); err != nil {
 			level.Error(logger).Log("msg", "failed to parse proto", "err", err.Error())
@@ -27,49 +41,115 @@ func RemoteReadHandler(q storage.Queryable, logger log.Logger) http.Handler {
 			return
 		}
 
-		// Fetch samples for all queries in parallel.
-		resp := client.ReadResponse{
-			Results: make([]*client.QueryResponse, len(req.Queries)),
+		respType, err := remote.NegotiateResponseType(req.AcceptedResponseTypes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
-		errors := make(chan error)
-		for i, qr := range req.Queries {
-			go func(i int, qr *client.QueryRequest) {
-				from, to, matchers, err := client.FromQueryRequest(qr)
-				if err != nil {
-					errors <- err
-					return
-				}
 
-				querier, err := q.Querier(int64(from), int64(to))
-				if err != nil {
-					errors <- err
-					return
-				}
+		switch respType {
+		case prompb.ReadRequest_STREAMED_XOR_CHUNKS:
+			remoteReadStreamedXORChunks(ctx, q, w, &req, marshalPool, logger)
+		default:
+			remoteReadSamples(ctx, q, w, &req, logger)
+		}
+	})
+}
 
-				params := &storage.SelectHints{
-					Start: int64(from),
-					End:   int64(to),
-				}
-				seriesSet := querier.Select(ctx, false, params, matchers...)
-				resp.Results[i], err = client.SeriesSetToQueryResponse(seriesSet)
+func remoteReadSamples(ctx context.Context, q storage.Queryable, w http.ResponseWriter, req *prompb.ReadRequest, logger log.Logger) {
+	// Fetch samples for all queries in parallel.
+	resp := client.ReadResponse{
+		Results: make([]*client.QueryResponse, len(req.Queries)),
+	}
+	errors := make(chan error)
+	for i, qr := range req.Queries {
+		go func(i int, qr *prompb.Query) {
+			matchers, err := remote.FromLabelMatchers(qr.Matchers)
+			if err != nil {
 				errors <- err
-			}(i, qr)
-		}
+				return
+			}
 
-		var lastErr error
-		for range req.Queries {
-			err := <-errors
+			querier, err := q.Querier(qr.StartTimestampMs, qr.EndTimestampMs)
 			if err != nil {
-				lastErr = err
+				errors <- err
+				return
+			}
+
+			params := &storage.SelectHints{
+				Start: qr.StartTimestampMs,
+				End:   qr.EndTimestampMs,
 			}
+			seriesSet := querier.Select(ctx, false, params, matchers...)
+			resp.Results[i], err = client.SeriesSetToQueryResponse(seriesSet)
+			errors <- err
+		}(i, qr)
+	}
+
+	var lastErr error
+	for range req.Queries {
+		err := <-errors
+		if err != nil {
+			lastErr = err
 		}
-		if lastErr != nil {
-			http.Error(w, lastErr.Error(), http.StatusBadRequest)
+	}
+	if lastErr != nil {
+		http.Error(w, lastErr.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Add("Content-Type", "application/x-protobuf")
+	if err := util.SerializeProtoResponse(w, &resp, util.RawSnappy); err != nil {
+		level.Error(logger).Log("msg", "error sending remote read response", "err", err)
+	}
+}
+
+// remoteReadStreamedXORChunks executes the queries one after the other, and streams
+// their series sorted by labels, as soon as they are read, in frames of XOR chunks.
+func remoteReadStreamedXORChunks(ctx context.Context, q storage.Queryable, w http.ResponseWriter, req *prompb.ReadRequest, marshalPool *sync.Pool, logger log.Logger) {
+	w.Header().Set("Content-Type", "application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse")
+
+	f, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "internal http.ResponseWriter does not implement http.Flusher interface", http.StatusInternalServerError)
+		return
+	}
+
+	for i, qr := range req.Queries {
+		if err := func() error {
+			matchers, err := remote.FromLabelMatchers(qr.Matchers)
+			if err != nil {
+				return err
+			}
+
+			querier, err := q.Querier(qr.StartTimestampMs, qr.EndTimestampMs)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := querier.Close(); err != nil {
+					level.Warn(logger).Log("msg", "error closing querier", "err", err)
+				}
+			}()
+
+			params := &storage.SelectHints{
+				Start: qr.StartTimestampMs,
+				End:   qr.EndTimestampMs,
+			}
+			// The streamed series must be sorted.
+			seriesSet := querier.Select(ctx, true, params, matchers...)
+			_, err = remote.StreamChunkedReadResponses(
+				remote.NewChunkedWriter(w, f),
+				int64(i),
+				storage.NewSeriesSetToChunkSet(seriesSet),
+				nil,
+				remoteReadMaxBytesInFrame,
+				marshalPool,
+			)
+			return err
+		}(); err != nil {
+			level.Error(logger).Log("msg", "error streaming remote read response", "err", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		w.Header().Add("Content-Type", "application/x-protobuf")
-		if err := util.SerializeProtoResponse(w, &resp, util.RawSnappy); err != nil {
-			level.Error(logger).Log("msg", "error sending remote read response", "err", err)
-		}
-	})
+	}
 }
@@ -3,6 +3,7 @@ package querier
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,8 +14,12 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/config"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
 	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/storage/remote"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
 	"github.com/prometheus/prometheus/util/annotations"
 	"github.com/stretchr/testify/require"
 
@@ -88,6 +93,91 @@ func TestRemoteReadHandler(t *testing.T) {
 	require.Equal(t, expected, response)
 }
 
+func TestRemoteReadHandler_StreamedXORChunks(t *testing.T) {
+	t.Parallel()
+	q := storage.QueryableFunc(func(mint, maxt int64) (storage.Querier, error) {
+		return mockQuerier{
+			matrix: model.Matrix{
+				{
+					Metric: model.Metric{"foo": "baz"},
+					Values: []model.SamplePair{
+						{Timestamp: 0, Value: 0},
+						{Timestamp: 1, Value: 1},
+					},
+				},
+				{
+					Metric: model.Metric{"foo": "bar"},
+					Values: []model.SamplePair{
+						{Timestamp: 2, Value: 2},
+						{Timestamp: 3, Value: 3},
+					},
+				},
+			},
+		}, nil
+	})
+	handler := RemoteReadHandler(q, log.NewNopLogger())
+
+	requestBody, err := proto.Marshal(&prompb.ReadRequest{
+		Queries: []*prompb.Query{
+			{StartTimestampMs: 0, EndTimestampMs: 10},
+		},
+		AcceptedResponseTypes: []prompb.ReadRequest_ResponseType{prompb.ReadRequest_STREAMED_XOR_CHUNKS},
+	})
+	require.NoError(t, err)
+	requestBody = snappy.Encode(nil, requestBody)
+	request, err := http.NewRequest("GET", "/query", bytes.NewReader(requestBody))
+	require.NoError(t, err)
+	request.Header.Set("X-Prometheus-Remote-Read-Version", "0.1.0")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, 200, recorder.Result().StatusCode)
+	require.Equal(t, []string{"application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse"}, recorder.Result().Header["Content-Type"])
+
+	// The series are streamed sorted by labels, one per frame.
+	reader := remote.NewChunkedReader(recorder.Result().Body, config.DefaultChunkedReadLimit, nil)
+	var series []string
+	var samples [][]model.SamplePair
+	for {
+		var resp prompb.ChunkedReadResponse
+		err := reader.NextProto(&resp)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		require.Equal(t, int64(0), resp.QueryIndex)
+		require.Len(t, resp.ChunkedSeries, 1)
+
+		series = append(series, labelsToString(resp.ChunkedSeries[0].Labels))
+		var values []model.SamplePair
+		for _, c := range resp.ChunkedSeries[0].Chunks {
+			chk, err := chunkenc.FromData(chunkenc.EncXOR, c.Data)
+			require.NoError(t, err)
+			it := chk.Iterator(nil)
+			for it.Next() == chunkenc.ValFloat {
+				ts, v := it.At()
+				values = append(values, model.SamplePair{Timestamp: model.Time(ts), Value: model.SampleValue(v)})
+			}
+		}
+		samples = append(samples, values)
+	}
+
+	require.Equal(t, []string{`{foo="bar"}`, `{foo="baz"}`}, series)
+	require.Equal(t, [][]model.SamplePair{
+		{{Timestamp: 2, Value: 2}, {Timestamp: 3, Value: 3}},
+		{{Timestamp: 0, Value: 0}, {Timestamp: 1, Value: 1}},
+	}, samples)
+}
+
+func labelsToString(lbls []prompb.Label) string {
+	b := labels.NewScratchBuilder(len(lbls))
+	for _, l := range lbls {
+		b.Add(l.Name, l.Value)
+	}
+	return b.Labels().String()
+}
+
 type mockQuerier struct {
 	matrix model.Matrix
 }
@@ -0,0 +1,161 @@
+package querier
+
+import (
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+
+	"github.com/cortexproject/cortex/pkg/util/limiter"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+const (
+	// samplesLimiterBatchSize is the number of samples an iterator loads before adding them to the limiter.
+	samplesLimiterBatchSize = 1024
+
+	// floatSampleBytes is the size of a promql.FPoint.
+	floatSampleBytes = 16
+)
+
+// samplesLimitedSeriesSet enforces the per-query limits of the samples loaded into the
+// query engine, while the engine iterates the series.
+type samplesLimitedSeriesSet struct {
+	storage.SeriesSet
+	limiter *limiter.QueryLimiter
+	err     error
+}
+
+// newSamplesLimitedSeriesSet returns the set limiting the samples loaded into the query engine
+// with the limiter, or the set as is if the limiter is nil or doesn't limit the samples.
+func newSamplesLimitedSeriesSet(set storage.SeriesSet, l *limiter.QueryLimiter) storage.SeriesSet {
+	if l == nil || !l.SamplesLimitsEnabled() {
+		return set
+	}
+	return &samplesLimitedSeriesSet{SeriesSet: set, limiter: l}
+}
+
+func (s *samplesLimitedSeriesSet) Next() bool {
+	if s.err != nil || !s.SeriesSet.Next() {
+		return false
+	}
+	if err := s.limiter.AddEstimatedMemoryBytes(labelsBytes(s.SeriesSet.At().Labels())); err != nil {
+		s.err = validation.LimitError(err.Error())
+		return false
+	}
+	return true
+}
+
+func (s *samplesLimitedSeriesSet) At() storage.Series {
+	return &samplesLimitedSeries{Series: s.SeriesSet.At(), limiter: s.limiter}
+}
+
+func (s *samplesLimitedSeriesSet) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.SeriesSet.Err()
+}
+
+type samplesLimitedSeries struct {
+	storage.Series
+	limiter *limiter.QueryLimiter
+}
+
+func (s *samplesLimitedSeries) Iterator(it chunkenc.Iterator) chunkenc.Iterator {
+	if li, ok := it.(*samplesLimitedIterator); ok {
+		// Add the samples left by the previous series, if it hasn't been iterated until the end.
+		err := li.flush()
+		li.Iterator = s.Series.Iterator(li.Iterator)
+		li.err = nil
+		if err != nil {
+			li.err = validation.LimitError(err.Error())
+		}
+		return li
+	}
+	return &samplesLimitedIterator{Iterator: s.Series.Iterator(it), limiter: s.limiter}
+}
+
+// samplesLimitedIterator adds the samples it loads to the limiter, by batches of samplesLimiterBatchSize.
+type samplesLimitedIterator struct {
+	chunkenc.Iterator
+	limiter *limiter.QueryLimiter
+
+	samples     int
+	memoryBytes int
+	err         error
+}
+
+func (it *samplesLimitedIterator) Next() chunkenc.ValueType {
+	if it.err != nil {
+		return chunkenc.ValNone
+	}
+	return it.track(it.Iterator.Next())
+}
+
+func (it *samplesLimitedIterator) Seek(t int64) chunkenc.ValueType {
+	if it.err != nil {
+		return chunkenc.ValNone
+	}
+	return it.track(it.Iterator.Seek(t))
+}
+
+func (it *samplesLimitedIterator) AtHistogram(h *histogram.Histogram) (int64, *histogram.Histogram) {
+	t, h := it.Iterator.AtHistogram(h)
+	if h != nil {
+		// The query engine loads it as a float histogram.
+		it.memoryBytes += (&histogram.FloatHistogram{PositiveSpans: h.PositiveSpans, NegativeSpans: h.NegativeSpans, CustomValues: h.CustomValues}).Size() +
+			(len(h.PositiveBuckets)+len(h.NegativeBuckets))*8
+	}
+	return t, h
+}
+
+func (it *samplesLimitedIterator) AtFloatHistogram(fh *histogram.FloatHistogram) (int64, *histogram.FloatHistogram) {
+	t, fh := it.Iterator.AtFloatHistogram(fh)
+	if fh != nil {
+		it.memoryBytes += fh.Size()
+	}
+	return t, fh
+}
+
+func (it *samplesLimitedIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.Iterator.Err()
+}
+
+func (it *samplesLimitedIterator) track(valueType chunkenc.ValueType) chunkenc.ValueType {
+	if valueType != chunkenc.ValNone {
+		it.samples++
+		// The size of the histograms is tracked when they're loaded.
+		it.memoryBytes += floatSampleBytes
+		if it.samples < samplesLimiterBatchSize {
+			return valueType
+		}
+	}
+
+	if err := it.flush(); err != nil {
+		it.err = validation.LimitError(err.Error())
+		return chunkenc.ValNone
+	}
+	return valueType
+}
+
+func (it *samplesLimitedIterator) flush() error {
+	samples, memoryBytes := it.samples, it.memoryBytes
+	it.samples, it.memoryBytes = 0, 0
+
+	if err := it.limiter.AddSamples(samples); err != nil {
+		return err
+	}
+	return it.limiter.AddEstimatedMemoryBytes(memoryBytes)
+}
+
+func labelsBytes(lbls labels.Labels) int {
+	size := 0
+	lbls.Range(func(l labels.Label) {
+		size += len(l.Name) + len(l.Value)
+	})
+	return size
+}
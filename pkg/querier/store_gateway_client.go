@@ -83,9 +83,11 @@ func newStoreGatewayClientPool(discovery client.PoolServiceDiscovery, clientConf
 		HealthCheckConfig: clientConfig.HealthCheckConfig,
 	}
 	poolCfg := client.PoolConfig{
-		CheckInterval:      time.Minute,
-		HealthCheckEnabled: true,
-		HealthCheckTimeout: 10 * time.Second,
+		CheckInterval:          time.Minute,
+		HealthCheckEnabled:     true,
+		HealthCheckTimeout:     10 * time.Second,
+		CheckIntervalJitter:    0.2,
+		HealthCheckConcurrency: 16,
 	}
 
 	clientsCount := promauto.With(reg).NewGauge(prometheus.GaugeOpts{
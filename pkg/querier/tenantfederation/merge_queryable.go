@@ -3,6 +3,7 @@ package tenantfederation
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -16,12 +17,15 @@ import (
 	"github.com/cortexproject/cortex/pkg/tenant"
 	"github.com/cortexproject/cortex/pkg/util/concurrency"
 	"github.com/cortexproject/cortex/pkg/util/spanlogger"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
 const (
 	defaultTenantLabel   = "__tenant_id__"
 	retainExistingPrefix = "original_"
 	maxConcurrency       = 16
+
+	errFederationNotAllowed = "tenant %s doesn't allow querying its data together with tenant %s"
 )
 
 // NewQueryable returns a queryable that iterates through all the tenant IDs
@@ -36,17 +40,30 @@ const (
 // If the label "__tenant_id__" is already existing, its value is overwritten
 // by the tenant ID and the previous value is exposed through a new label
 // prefixed with "original_". This behaviour is not implemented recursively.
-func NewQueryable(upstream storage.Queryable, byPassWithSingleQuerier bool) storage.Queryable {
-	return NewMergeQueryable(defaultTenantLabel, tenantQuerierCallback(upstream), byPassWithSingleQuerier)
+// If limits is not nil, queries federating tenants not allowed by their
+// FederationAllowedTenants are rejected.
+func NewQueryable(upstream storage.Queryable, byPassWithSingleQuerier bool, limits Limits) storage.Queryable {
+	return NewMergeQueryable(defaultTenantLabel, tenantQuerierCallback(upstream, limits), byPassWithSingleQuerier)
+}
+
+// Limits returns the per-tenant limits of the tenant federation.
+type Limits interface {
+	// FederationAllowedTenants returns the tenants whose data can be queried
+	// together with the data of the tenant. Empty to allow any tenant.
+	FederationAllowedTenants(userID string) []string
 }
 
-func tenantQuerierCallback(queryable storage.Queryable) MergeQuerierCallback {
+func tenantQuerierCallback(queryable storage.Queryable, limits Limits) MergeQuerierCallback {
 	return func(ctx context.Context, mint int64, maxt int64) ([]string, []storage.Querier, error) {
 		tenantIDs, err := tenant.TenantIDs(ctx)
 		if err != nil {
 			return nil, nil, err
 		}
 
+		if err := checkFederationAllowed(limits, tenantIDs); err != nil {
+			return nil, nil, err
+		}
+
 		var queriers = make([]storage.Querier, len(tenantIDs))
 		for pos := range tenantIDs {
 			q, err := queryable.Querier(
@@ -63,6 +80,27 @@ func tenantQuerierCallback(queryable storage.Queryable) MergeQuerierCallback {
 	}
 }
 
+// checkFederationAllowed returns an error if any of the tenants doesn't allow
+// its data to be queried together with the data of another tenant.
+func checkFederationAllowed(limits Limits, tenantIDs []string) error {
+	if limits == nil || len(tenantIDs) <= 1 {
+		return nil
+	}
+
+	for _, tenantID := range tenantIDs {
+		allowed := limits.FederationAllowedTenants(tenantID)
+		if len(allowed) == 0 {
+			continue
+		}
+		for _, otherID := range tenantIDs {
+			if otherID != tenantID && !slices.Contains(allowed, otherID) {
+				return validation.LimitError(fmt.Sprintf(errFederationNotAllowed, tenantID, otherID))
+			}
+		}
+	}
+	return nil
+}
+
 // MergeQuerierCallback returns the underlying queriers and their IDs relevant
 // for the query.
 type MergeQuerierCallback func(ctx context.Context, mint int64, maxt int64) (ids []string, queriers []storage.Querier, err error)
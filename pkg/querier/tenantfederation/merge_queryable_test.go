@@ -299,7 +299,7 @@ type mergeQueryableScenario struct {
 
 func (s *mergeQueryableScenario) init() (storage.Querier, error) {
 	// initialize with default tenant label
-	q := NewQueryable(&s.queryable, !s.doNotByPassSingleQuerier)
+	q := NewQueryable(&s.queryable, !s.doNotByPassSingleQuerier, nil)
 
 	// retrieve querier
 	return q.Querier(mint, maxt)
@@ -373,7 +373,7 @@ func TestMergeQueryable_Querier(t *testing.T) {
 	t.Run("querying without a tenant specified should error", func(t *testing.T) {
 		t.Parallel()
 		queryable := &mockTenantQueryableWithFilter{}
-		q := NewQueryable(queryable, false /* byPassWithSingleQuerier */)
+		q := NewQueryable(queryable, false /* byPassWithSingleQuerier */, nil)
 
 		querier, err := q.Querier(mint, maxt)
 		require.NoError(t, err)
@@ -383,6 +383,61 @@ func TestMergeQueryable_Querier(t *testing.T) {
 	})
 }
 
+func TestMergeQueryable_FederationAllowedTenants(t *testing.T) {
+	t.Parallel()
+	limits := mockFederationLimits{
+		"team-a": {"team-b"},
+		"team-c": {"team-a", "team-b"},
+	}
+
+	tests := map[string]struct {
+		tenants     string
+		expectedErr string
+	}{
+		"should allow a single tenant": {
+			tenants: "team-a",
+		},
+		"should allow tenants without allowed tenants": {
+			tenants: "team-b|team-d",
+		},
+		"should allow tenants allowed by each other": {
+			tenants: "team-a|team-b",
+		},
+		"should reject a tenant not allowed by another one": {
+			tenants:     "team-a|team-d",
+			expectedErr: "tenant team-a doesn't allow querying its data together with tenant team-d",
+		},
+		"should reject a tenant not allowed by another one, even if allowed by the others": {
+			tenants:     "team-b|team-c|team-d",
+			expectedErr: "tenant team-c doesn't allow querying its data together with tenant team-d",
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			q := NewQueryable(&mockTenantQueryableWithFilter{}, true, limits)
+			querier, err := q.Querier(mint, maxt)
+			require.NoError(t, err)
+
+			ctx := user.InjectOrgID(context.Background(), tc.tenants)
+			_, _, err = querier.LabelNames(ctx, nil)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+type mockFederationLimits map[string][]string
+
+func (m mockFederationLimits) FederationAllowedTenants(userID string) []string {
+	return m[userID]
+}
+
 var (
 	singleTenantScenario = mergeQueryableScenario{
 		name:    "single tenant",
@@ -1030,7 +1085,7 @@ func TestTracingMergeQueryable(t *testing.T) {
 	// set a multi tenant resolver
 	tenant.WithDefaultResolver(tenant.NewMultiResolver())
 	filter := mockTenantQueryableWithFilter{}
-	q := NewQueryable(&filter, false)
+	q := NewQueryable(&filter, false, nil)
 	// retrieve querier if set
 	querier, err := q.Querier(mint, maxt)
 	require.NoError(t, err)
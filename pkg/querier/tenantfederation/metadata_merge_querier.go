@@ -0,0 +1,92 @@
+package tenantfederation
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/prometheus/scrape"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/querier"
+	"github.com/cortexproject/cortex/pkg/tenant"
+	"github.com/cortexproject/cortex/pkg/util/concurrency"
+)
+
+// NewMetadataQuerier returns a MetadataQuerier merging the metric metadata of all
+// the tenant IDs that are part of the request. Requests with a single tenant are
+// passed to the upstream querier as is. If limits is not nil, requests federating
+// tenants not allowed by their FederationAllowedTenants are rejected.
+func NewMetadataQuerier(upstream querier.MetadataQuerier, limits Limits) querier.MetadataQuerier {
+	return &mergeMetadataQuerier{
+		upstream: upstream,
+		limits:   limits,
+	}
+}
+
+type mergeMetadataQuerier struct {
+	upstream querier.MetadataQuerier
+	limits   Limits
+}
+
+// MetricsMetadata returns the deduplicated metric metadata of all the tenants of the
+// request. The metadata doesn't have labels, so it can't be told apart by tenant.
+func (m *mergeMetadataQuerier) MetricsMetadata(ctx context.Context, req *client.MetricsMetadataRequest) ([]scrape.MetricMetadata, error) {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tenantIDs) == 1 {
+		return m.upstream.MetricsMetadata(ctx, req)
+	}
+
+	if err := checkFederationAllowed(m.limits, tenantIDs); err != nil {
+		return nil, err
+	}
+
+	var (
+		mtx     sync.Mutex
+		results = make(map[string][]scrape.MetricMetadata, len(tenantIDs))
+	)
+	err = concurrency.ForEachUser(ctx, tenantIDs, maxConcurrency, func(ctx context.Context, tenantID string) error {
+		res, err := m.upstream.MetricsMetadata(user.InjectOrgID(ctx, tenantID), req)
+		if err != nil {
+			return err
+		}
+
+		mtx.Lock()
+		results[tenantID] = res
+		mtx.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge the results in the order of the tenants, honoring the limits of the request
+	// on the merged metadata, given each tenant has been limited on its own.
+	var (
+		merged    []scrape.MetricMetadata
+		seen      = map[scrape.MetricMetadata]struct{}{}
+		perMetric = map[string]int64{}
+	)
+	for _, tenantID := range tenantIDs {
+		for _, md := range results[tenantID] {
+			if _, ok := seen[md]; ok {
+				continue
+			}
+			if _, ok := perMetric[md.Metric]; !ok && req.Limit > 0 && int64(len(perMetric)) >= req.Limit {
+				continue
+			}
+			if req.LimitPerMetric > 0 && perMetric[md.Metric] >= req.LimitPerMetric {
+				continue
+			}
+
+			seen[md] = struct{}{}
+			perMetric[md.Metric]++
+			merged = append(merged, md)
+		}
+	}
+	return merged, nil
+}
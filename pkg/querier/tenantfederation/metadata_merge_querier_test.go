@@ -0,0 +1,98 @@
+package tenantfederation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/scrape"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/tenant"
+)
+
+type mockMetadataQuerier map[string][]scrape.MetricMetadata
+
+func (m mockMetadataQuerier) MetricsMetadata(ctx context.Context, _ *client.MetricsMetadataRequest) ([]scrape.MetricMetadata, error) {
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m[userID], nil
+}
+
+func TestMergeMetadataQuerier_MetricsMetadata(t *testing.T) {
+	t.Parallel()
+	// set a multi tenant resolver
+	tenant.WithDefaultResolver(tenant.NewMultiResolver())
+
+	upstream := mockMetadataQuerier{
+		"team-a": {
+			{Metric: "up", Type: model.MetricTypeGauge, Help: "Up."},
+			{Metric: "requests_total", Type: model.MetricTypeCounter, Help: "Requests."},
+		},
+		"team-b": {
+			{Metric: "up", Type: model.MetricTypeGauge, Help: "Up."},
+			{Metric: "up", Type: model.MetricTypeGauge, Help: "Is up."},
+		},
+		"team-d": {
+			{Metric: "errors_total", Type: model.MetricTypeCounter, Help: "Errors."},
+		},
+	}
+	limits := mockFederationLimits{
+		"team-a": {"team-b"},
+	}
+
+	tests := map[string]struct {
+		tenants     string
+		req         *client.MetricsMetadataRequest
+		expected    []scrape.MetricMetadata
+		expectedErr string
+	}{
+		"should return the metadata of a single tenant": {
+			tenants:  "team-b",
+			req:      &client.MetricsMetadataRequest{},
+			expected: upstream["team-b"],
+		},
+		"should merge and deduplicate the metadata of the tenants": {
+			tenants: "team-a|team-b",
+			req:     &client.MetricsMetadataRequest{},
+			expected: []scrape.MetricMetadata{
+				{Metric: "up", Type: model.MetricTypeGauge, Help: "Up."},
+				{Metric: "requests_total", Type: model.MetricTypeCounter, Help: "Requests."},
+				{Metric: "up", Type: model.MetricTypeGauge, Help: "Is up."},
+			},
+		},
+		"should honor the limits of the request on the merged metadata": {
+			tenants: "team-a|team-b",
+			req:     &client.MetricsMetadataRequest{Limit: 1, LimitPerMetric: 1},
+			expected: []scrape.MetricMetadata{
+				{Metric: "up", Type: model.MetricTypeGauge, Help: "Up."},
+			},
+		},
+		"should reject a tenant not allowed by another one": {
+			tenants:     "team-a|team-d",
+			req:         &client.MetricsMetadataRequest{},
+			expectedErr: "tenant team-a doesn't allow querying its data together with tenant team-d",
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			q := NewMetadataQuerier(upstream, limits)
+
+			res, err := q.MetricsMetadata(user.InjectOrgID(context.Background(), tc.tenants), tc.req)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
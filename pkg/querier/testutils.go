@@ -58,11 +58,16 @@ func (m *MockDistributor) MetricsForLabelMatchersStream(ctx context.Context, fro
 	return args.Get(0).([]model.Metric), args.Error(1)
 }
 
-func (m *MockDistributor) MetricsMetadata(ctx context.Context) ([]scrape.MetricMetadata, error) {
-	args := m.Called(ctx)
+func (m *MockDistributor) MetricsMetadata(ctx context.Context, req *client.MetricsMetadataRequest) ([]scrape.MetricMetadata, error) {
+	args := m.Called(ctx, req)
 	return args.Get(0).([]scrape.MetricMetadata), args.Error(1)
 }
 
+func (m *MockDistributor) TSDBStatus(ctx context.Context, req *client.TSDBStatusRequest) (*client.TSDBStatusResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*client.TSDBStatusResponse), args.Error(1)
+}
+
 type MockLimitingDistributor struct {
 	MockDistributor
 	response *client.QueryStreamResponse
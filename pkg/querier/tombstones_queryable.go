@@ -0,0 +1,130 @@
+package querier
+
+import (
+	"context"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	"github.com/cortexproject/cortex/pkg/tenant"
+)
+
+// TombstonesLoader returns the tombstones of the series deletion requests of a tenant.
+type TombstonesLoader interface {
+	GetTombstones(ctx context.Context, userID string) (bucketindex.Tombstones, error)
+}
+
+// NewTombstonesQueryable returns a queryable filtering out the samples deleted by the series
+// deletion requests of the tenant, until they're deleted from the blocks by the compactor.
+func NewTombstonesQueryable(queryable storage.Queryable, loader TombstonesLoader) storage.Queryable {
+	return storage.QueryableFunc(func(mint, maxt int64) (storage.Querier, error) {
+		querier, err := queryable.Querier(mint, maxt)
+		if err != nil {
+			return nil, err
+		}
+		return &tombstonesQuerier{Querier: querier, loader: loader, mint: mint, maxt: maxt}, nil
+	})
+}
+
+type tombstonesQuerier struct {
+	storage.Querier
+	loader     TombstonesLoader
+	mint, maxt int64
+}
+
+func (q *tombstonesQuerier) Select(ctx context.Context, sortSeries bool, sp *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return storage.ErrSeriesSet(err)
+	}
+
+	tombstones, err := q.loader.GetTombstones(ctx, userID)
+	if err != nil {
+		return storage.ErrSeriesSet(err)
+	}
+
+	var overlapping bucketindex.Tombstones
+	for _, t := range tombstones {
+		if t.Overlaps(q.mint, q.maxt) {
+			overlapping = append(overlapping, t)
+		}
+	}
+
+	set := q.Querier.Select(ctx, sortSeries, sp, matchers...)
+	if len(overlapping) == 0 {
+		return set
+	}
+	return &tombstonesSeriesSet{SeriesSet: set, tombstones: overlapping}
+}
+
+// tombstonesSeriesSet filters out the samples of the series matching the tombstones.
+type tombstonesSeriesSet struct {
+	storage.SeriesSet
+	tombstones bucketindex.Tombstones
+}
+
+func (s *tombstonesSeriesSet) At() storage.Series {
+	series := s.SeriesSet.At()
+
+	var deleted []deletedInterval
+	for _, t := range s.tombstones {
+		if t.MatchesSeries(series.Labels()) {
+			deleted = append(deleted, deletedInterval{mint: t.StartTime, maxt: t.EndTime})
+		}
+	}
+	if len(deleted) == 0 {
+		return series
+	}
+	return &tombstonesSeries{Series: series, deleted: deleted}
+}
+
+// deletedInterval is the range of the deleted samples (milliseconds, both included).
+type deletedInterval struct {
+	mint, maxt int64
+}
+
+type tombstonesSeries struct {
+	storage.Series
+	deleted []deletedInterval
+}
+
+func (s *tombstonesSeries) Iterator(it chunkenc.Iterator) chunkenc.Iterator {
+	if ti, ok := it.(*tombstonesIterator); ok {
+		ti.Iterator = s.Series.Iterator(ti.Iterator)
+		ti.deleted = s.deleted
+		return ti
+	}
+	return &tombstonesIterator{Iterator: s.Series.Iterator(it), deleted: s.deleted}
+}
+
+// tombstonesIterator skips the samples within the deleted intervals.
+type tombstonesIterator struct {
+	chunkenc.Iterator
+	deleted []deletedInterval
+}
+
+func (it *tombstonesIterator) Next() chunkenc.ValueType {
+	return it.skipDeleted(it.Iterator.Next())
+}
+
+func (it *tombstonesIterator) Seek(t int64) chunkenc.ValueType {
+	return it.skipDeleted(it.Iterator.Seek(t))
+}
+
+func (it *tombstonesIterator) skipDeleted(valueType chunkenc.ValueType) chunkenc.ValueType {
+	for valueType != chunkenc.ValNone && it.isDeleted(it.Iterator.AtT()) {
+		valueType = it.Iterator.Next()
+	}
+	return valueType
+}
+
+func (it *tombstonesIterator) isDeleted(t int64) bool {
+	for _, d := range it.deleted {
+		if d.mint <= t && t <= d.maxt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package querier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+)
+
+type mockTombstonesLoader bucketindex.Tombstones
+
+func (m mockTombstonesLoader) GetTombstones(_ context.Context, _ string) (bucketindex.Tombstones, error) {
+	return bucketindex.Tombstones(m), nil
+}
+
+func TestTombstonesQueryable(t *testing.T) {
+	samples := []model.SamplePair{{Timestamp: 10, Value: 1}, {Timestamp: 20, Value: 2}, {Timestamp: 30, Value: 3}, {Timestamp: 40, Value: 4}, {Timestamp: 50, Value: 5}}
+	queryable := storage.QueryableFunc(func(mint, maxt int64) (storage.Querier, error) {
+		return mockQuerier{matrix: model.Matrix{
+			{Metric: model.Metric{"a": "1"}, Values: samples},
+			{Metric: model.Metric{"a": "2"}, Values: samples},
+		}}, nil
+	})
+
+	newTombstone := func(startTime, endTime int64, selectors ...string) *bucketindex.Tombstone {
+		tombstone, err := bucketindex.NewTombstone("user-1", 0, 0, startTime, endTime, selectors, bucketindex.StatePending)
+		require.NoError(t, err)
+		return tombstone
+	}
+
+	for name, tc := range map[string]struct {
+		tombstones bucketindex.Tombstones
+		seek       int64
+		expected   map[string][]int64
+	}{
+		"no tombstone": {
+			expected: map[string][]int64{
+				`{a="1"}`: {10, 20, 30, 40, 50},
+				`{a="2"}`: {10, 20, 30, 40, 50},
+			},
+		},
+		"tombstone not overlapping the query": {
+			tombstones: bucketindex.Tombstones{newTombstone(100, 200, `{a="1"}`)},
+			expected: map[string][]int64{
+				`{a="1"}`: {10, 20, 30, 40, 50},
+				`{a="2"}`: {10, 20, 30, 40, 50},
+			},
+		},
+		"tombstones deleting samples": {
+			tombstones: bucketindex.Tombstones{
+				newTombstone(20, 30, `{a="1"}`),
+				newTombstone(50, 50, `{a="1"}`, `{a="2"}`),
+			},
+			expected: map[string][]int64{
+				`{a="1"}`: {10, 40},
+				`{a="2"}`: {10, 20, 30, 40},
+			},
+		},
+		"seek to deleted samples": {
+			tombstones: bucketindex.Tombstones{newTombstone(0, 30, `{a="2"}`)},
+			seek:       20,
+			expected: map[string][]int64{
+				`{a="1"}`: {20, 30, 40, 50},
+				`{a="2"}`: {40, 50},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := user.InjectOrgID(context.Background(), "user-1")
+
+			q, err := NewTombstonesQueryable(queryable, mockTombstonesLoader(tc.tombstones)).Querier(0, 60)
+			require.NoError(t, err)
+
+			actual := map[string][]int64{}
+			set := q.Select(ctx, true, &storage.SelectHints{Start: 0, End: 60})
+			for set.Next() {
+				s := set.At()
+				it := s.Iterator(nil)
+
+				var ts []int64
+				valueType := it.Next()
+				if tc.seek > 0 {
+					valueType = it.Seek(tc.seek)
+				}
+				for ; valueType != chunkenc.ValNone; valueType = it.Next() {
+					ts = append(ts, it.AtT())
+				}
+				require.NoError(t, it.Err())
+				actual[s.Labels().String()] = ts
+			}
+			require.NoError(t, set.Err())
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package tripperware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/cortexproject/cortex/pkg/chunk/cache"
+	"github.com/cortexproject/cortex/pkg/querier/partialdata"
+	"github.com/cortexproject/cortex/pkg/tenant"
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+)
+
+// BucketCacheKeyFn returns the cache key of the request for the time buckets of the
+// given duration, and false if the request should not be cached.
+type BucketCacheKeyFn func(r *PrometheusRequest, bucket time.Duration) (string, bool)
+
+// InstantQueryBucketCacheKey is the BucketCacheKeyFn of the instant queries.
+func InstantQueryBucketCacheKey(r *PrometheusRequest, bucket time.Duration) (string, bool) {
+	return fmt.Sprintf("instant:%s:%s:%d", r.GetQuery(), r.GetStats(), r.Time/bucket.Milliseconds()), true
+}
+
+// UnalignedQueryBucketCacheKey is the BucketCacheKeyFn of the range queries whose start
+// and end are not aligned with their step. The aligned range queries are not cached.
+func UnalignedQueryBucketCacheKey(r *PrometheusRequest, bucket time.Duration) (string, bool) {
+	if r.GetStep() <= 0 || (r.GetStart()%r.GetStep() == 0 && r.GetEnd()%r.GetStep() == 0) {
+		return "", false
+	}
+	return fmt.Sprintf("unaligned:%s:%s:%d:%d:%d", r.GetQuery(), r.GetStats(), r.GetStep(), r.GetEnd()-r.GetStart(), r.GetStart()/bucket.Milliseconds()), true
+}
+
+// cachedBucketResponse is a successful query response stored in the cache.
+type cachedBucketResponse struct {
+	// Key is the unhashed cache key, to detect hash collisions.
+	Key       string `json:"key"`
+	ExpiresAt int64  `json:"expires_at"`
+	Response  []byte `json:"response"`
+}
+
+type bucketedResultsCache struct {
+	next   Handler
+	cache  cache.Cache
+	ttl    func(string) time.Duration
+	keyFn  BucketCacheKeyFn
+	logger log.Logger
+}
+
+// NewBucketedResultsCacheMiddleware returns a middleware caching the whole responses of the
+// queries for the per-tenant ttl, keyed by keyFn with time buckets of the ttl. Unlike the
+// results cache of the range queries, which merges the results of the requests over time,
+// the cached responses are returned as is to the identical requests of the same time bucket.
+// The responses of the requests are not cached for the tenants with a ttl of 0, and the ones
+// forwarded to the next handler are not cached again by the results cache.
+func NewBucketedResultsCacheMiddleware(c cache.Cache, ttl func(string) time.Duration, keyFn BucketCacheKeyFn, logger log.Logger) Middleware {
+	return MiddlewareFunc(func(next Handler) Handler {
+		return &bucketedResultsCache{
+			next:   next,
+			cache:  c,
+			ttl:    ttl,
+			keyFn:  keyFn,
+			logger: logger,
+		}
+	})
+}
+
+func (b *bucketedResultsCache) Do(ctx context.Context, r Request) (Response, error) {
+	promReq, ok := r.(*PrometheusRequest)
+	if !ok || promReq.GetCachingOptions().Disabled {
+		return b.next.Do(ctx, r)
+	}
+
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+	ttl := smallestDurationPerTenant(tenantIDs, b.ttl)
+	if ttl <= 0 {
+		return b.next.Do(ctx, r)
+	}
+	// The time buckets are at least as long as the timestamps resolution.
+	key, ok := b.keyFn(promReq, max(ttl, time.Millisecond))
+	if !ok {
+		return b.next.Do(ctx, r)
+	}
+	key = fmt.Sprintf("%s:%s", tenant.JoinTenantIDs(tenantIDs), key)
+
+	if resp, ok := b.get(ctx, key); ok {
+		return resp, nil
+	}
+
+	next := *promReq
+	next.CachingOptions.Disabled = true
+	resp, err := b.next.Do(ctx, &next)
+	if err != nil {
+		return nil, err
+	}
+	if promResp, ok := resp.(*PrometheusResponse); ok && promResp.Status == StatusSuccess && !hasNoStoreHeader(promResp) && !HasPartialResults(promResp) {
+		b.put(ctx, key, ttl, promResp)
+	}
+	return resp, nil
+}
+
+func (b *bucketedResultsCache) get(ctx context.Context, key string) (Response, bool) {
+	found, bufs, _ := b.cache.Fetch(ctx, []string{cache.HashKey(key)})
+	if len(found) != 1 {
+		return nil, false
+	}
+
+	var cached cachedBucketResponse
+	if err := json.Unmarshal(bufs[0], &cached); err != nil {
+		level.Error(util_log.WithContext(ctx, b.logger)).Log("msg", "error unmarshalling cached query response", "err", err)
+		return nil, false
+	}
+	if cached.Key != key || time.Now().UnixMilli() >= cached.ExpiresAt {
+		return nil, false
+	}
+
+	var resp PrometheusResponse
+	if err := resp.Unmarshal(cached.Response); err != nil {
+		level.Error(util_log.WithContext(ctx, b.logger)).Log("msg", "error unmarshalling cached query response", "err", err)
+		return nil, false
+	}
+	return &resp, true
+}
+
+func (b *bucketedResultsCache) put(ctx context.Context, key string, ttl time.Duration, resp *PrometheusResponse) {
+	// The headers are set again when the response is encoded.
+	withoutHeaders := *resp
+	withoutHeaders.Headers = nil
+	response, err := withoutHeaders.Marshal()
+	if err != nil {
+		level.Error(util_log.WithContext(ctx, b.logger)).Log("msg", "error marshalling query response", "err", err)
+		return
+	}
+
+	buf, err := json.Marshal(cachedBucketResponse{
+		Key:       key,
+		ExpiresAt: time.Now().Add(ttl).UnixMilli(),
+		Response:  response,
+	})
+	if err != nil {
+		level.Error(util_log.WithContext(ctx, b.logger)).Log("msg", "error marshalling query response", "err", err)
+		return
+	}
+	b.cache.Store(ctx, []string{cache.HashKey(key)}, [][]byte{buf})
+}
+
+func hasNoStoreHeader(resp *PrometheusResponse) bool {
+	for _, h := range resp.GetHeaders() {
+		if h != nil && strings.EqualFold(h.Name, cacheControlHeader) && headerValuesContain(h.Values, noStoreValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPartialResults returns whether the response holds the partial results of a query,
+// which must not be cached.
+func HasPartialResults(resp Response) bool {
+	promResp, ok := resp.(*PrometheusResponse)
+	if !ok {
+		return false
+	}
+	for _, w := range promResp.GetWarnings() {
+		if strings.HasPrefix(w, partialdata.WarningPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// smallestDurationPerTenant returns the smallest duration of the tenants, 0 if one of them is 0.
+func smallestDurationPerTenant(tenantIDs []string, f func(string) time.Duration) time.Duration {
+	var result time.Duration
+	for i, tenantID := range tenantIDs {
+		d := f(tenantID)
+		if i == 0 || d < result {
+			result = d
+		}
+	}
+	return result
+}
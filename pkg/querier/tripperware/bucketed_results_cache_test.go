@@ -0,0 +1,176 @@
+package tripperware
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/chunk/cache"
+	"github.com/cortexproject/cortex/pkg/querier/partialdata"
+)
+
+func TestBucketedResultsCache(t *testing.T) {
+	t.Parallel()
+
+	instant := func(query string, ts int64) *PrometheusRequest {
+		return &PrometheusRequest{Query: query, Time: ts}
+	}
+	rangeQuery := func(start, end int64) *PrometheusRequest {
+		return &PrometheusRequest{Query: "up", Start: start, End: end, Step: 30_000}
+	}
+
+	for name, tc := range map[string]struct {
+		requests      [2]*PrometheusRequest
+		keyFn         BucketCacheKeyFn
+		ttl           time.Duration
+		respHeaders   []*PrometheusResponseHeader
+		respWarning   string
+		respErr       error
+		expectedCalls int
+	}{
+		"instant queries of the same time bucket are cached": {
+			requests:      [2]*PrometheusRequest{instant("up", 10_000), instant("up", 50_000)},
+			keyFn:         InstantQueryBucketCacheKey,
+			ttl:           time.Minute,
+			expectedCalls: 1,
+		},
+		"instant queries of different time buckets are not cached": {
+			requests:      [2]*PrometheusRequest{instant("up", 50_000), instant("up", 70_000)},
+			keyFn:         InstantQueryBucketCacheKey,
+			ttl:           time.Minute,
+			expectedCalls: 2,
+		},
+		"different instant queries are not cached": {
+			requests:      [2]*PrometheusRequest{instant("up", 10_000), instant("down", 10_000)},
+			keyFn:         InstantQueryBucketCacheKey,
+			ttl:           time.Minute,
+			expectedCalls: 2,
+		},
+		"instant queries are not cached with a zero ttl": {
+			requests:      [2]*PrometheusRequest{instant("up", 10_000), instant("up", 10_000)},
+			keyFn:         InstantQueryBucketCacheKey,
+			expectedCalls: 2,
+		},
+		"instant queries with caching disabled are not cached": {
+			requests: [2]*PrometheusRequest{
+				{Query: "up", Time: 10_000, CachingOptions: CachingOptions{Disabled: true}},
+				{Query: "up", Time: 10_000, CachingOptions: CachingOptions{Disabled: true}},
+			},
+			keyFn:         InstantQueryBucketCacheKey,
+			ttl:           time.Minute,
+			expectedCalls: 2,
+		},
+		"failed queries are not cached": {
+			requests:      [2]*PrometheusRequest{instant("up", 10_000), instant("up", 10_000)},
+			keyFn:         InstantQueryBucketCacheKey,
+			ttl:           time.Minute,
+			respErr:       errors.New("failed"),
+			expectedCalls: 2,
+		},
+		"responses with the no-store header are not cached": {
+			requests:      [2]*PrometheusRequest{instant("up", 10_000), instant("up", 10_000)},
+			keyFn:         InstantQueryBucketCacheKey,
+			ttl:           time.Minute,
+			respHeaders:   []*PrometheusResponseHeader{{Name: cacheControlHeader, Values: []string{noStoreValue}}},
+			expectedCalls: 2,
+		},
+		"partial results are not cached": {
+			requests:      [2]*PrometheusRequest{instant("up", 10_000), instant("up", 10_000)},
+			keyFn:         InstantQueryBucketCacheKey,
+			ttl:           time.Minute,
+			respWarning:   partialdata.WarningPrefix + "some blocks have not been queried from the store-gateways",
+			expectedCalls: 2,
+		},
+		"unaligned range queries of the same time bucket and length are cached": {
+			requests:      [2]*PrometheusRequest{rangeQuery(1_000, 3_601_000), rangeQuery(2_000, 3_602_000)},
+			keyFn:         UnalignedQueryBucketCacheKey,
+			ttl:           time.Minute,
+			expectedCalls: 1,
+		},
+		"unaligned range queries of different lengths are not cached": {
+			requests:      [2]*PrometheusRequest{rangeQuery(1_000, 3_601_000), rangeQuery(1_000, 7_201_000)},
+			keyFn:         UnalignedQueryBucketCacheKey,
+			ttl:           time.Minute,
+			expectedCalls: 2,
+		},
+		"aligned range queries are not cached": {
+			requests:      [2]*PrometheusRequest{rangeQuery(0, 3_600_000), rangeQuery(0, 3_600_000)},
+			keyFn:         UnalignedQueryBucketCacheKey,
+			ttl:           time.Minute,
+			expectedCalls: 2,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			next := HandlerFunc(func(_ context.Context, r Request) (Response, error) {
+				calls++
+				// The forwarded requests of the cached queries must not be cached again.
+				if tc.ttl > 0 {
+					if _, ok := tc.keyFn(r.(*PrometheusRequest), tc.ttl); ok {
+						assert.True(t, r.(*PrometheusRequest).CachingOptions.Disabled)
+					}
+				}
+				if tc.respErr != nil {
+					return nil, tc.respErr
+				}
+				warnings := []string{strconv.Itoa(calls)}
+				if tc.respWarning != "" {
+					warnings = append(warnings, tc.respWarning)
+				}
+				return &PrometheusResponse{
+					Status:   StatusSuccess,
+					Warnings: warnings,
+					Headers:  tc.respHeaders,
+				}, nil
+			})
+
+			c := cache.NewMockCache()
+			handler := NewBucketedResultsCacheMiddleware(c, mockLimits{unalignedQueryTTL: tc.ttl}.UnalignedQueryCacheTTL, tc.keyFn, log.NewNopLogger()).Wrap(next)
+			ctx := user.InjectOrgID(context.Background(), "user-1")
+
+			var warnings []string
+			for _, r := range tc.requests {
+				resp, err := handler.Do(ctx, r)
+				if tc.respErr != nil {
+					require.Equal(t, tc.respErr, err)
+					continue
+				}
+				require.NoError(t, err)
+				warnings = append(warnings, resp.(*PrometheusResponse).Warnings...)
+			}
+
+			assert.Equal(t, tc.expectedCalls, calls)
+			if tc.respErr == nil && tc.expectedCalls == 1 {
+				assert.Equal(t, []string{"1", "1"}, warnings)
+			}
+		})
+	}
+}
+
+func TestBucketedResultsCache_ShouldNotShareResponsesAcrossTenants(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	next := HandlerFunc(func(context.Context, Request) (Response, error) {
+		calls++
+		return &PrometheusResponse{Status: StatusSuccess}, nil
+	})
+	handler := NewBucketedResultsCacheMiddleware(cache.NewMockCache(), mockLimits{instantQueryTTL: time.Minute}.InstantQueryCacheTTL, InstantQueryBucketCacheKey, log.NewNopLogger()).Wrap(next)
+
+	for _, tenantID := range []string{"user-1", "user-2", "user-1|user-2", "user-1"} {
+		_, err := handler.Do(user.InjectOrgID(context.Background(), tenantID), &PrometheusRequest{Query: "up", Time: 10_000})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 3, calls)
+}
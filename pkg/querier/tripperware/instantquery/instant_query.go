@@ -41,6 +41,8 @@ func NewInstantQueryCodec(compressionStr string, defaultCodecTypeStr string) ins
 	compression := tripperware.NonCompression // default
 	if compressionStr == string(tripperware.GzipCompression) {
 		compression = tripperware.GzipCompression
+	} else if compressionStr == string(tripperware.SnappyCompression) {
+		compression = tripperware.SnappyCompression
 	}
 
 	defaultCodecType := tripperware.JsonCodecType // default
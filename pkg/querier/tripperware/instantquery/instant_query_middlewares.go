@@ -6,6 +6,7 @@ import (
 	"github.com/go-kit/log"
 	"github.com/thanos-io/thanos/pkg/querysharding"
 
+	"github.com/cortexproject/cortex/pkg/chunk/cache"
 	"github.com/cortexproject/cortex/pkg/querier/tripperware"
 )
 
@@ -15,10 +16,15 @@ func Middlewares(
 	merger tripperware.Merger,
 	queryAnalyzer querysharding.Analyzer,
 	lookbackDelta time.Duration,
+	resultsCache cache.Cache,
 ) ([]tripperware.Middleware, error) {
-	m := []tripperware.Middleware{
-		NewLimitsMiddleware(limits, lookbackDelta),
-		tripperware.ShardByMiddleware(log, limits, merger, queryAnalyzer),
+	m := []tripperware.Middleware{NewLimitsMiddleware(limits, lookbackDelta)}
+	if resultsCache != nil {
+		m = append(m, tripperware.NewBucketedResultsCacheMiddleware(resultsCache, limits.InstantQueryCacheTTL, tripperware.InstantQueryBucketCacheKey, log))
 	}
+	m = append(m,
+		SplitByIntervalMiddleware(log, limits),
+		tripperware.ShardByMiddleware(log, limits, merger, queryAnalyzer),
+	)
 	return m, nil
 }
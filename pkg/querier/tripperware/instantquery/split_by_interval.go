@@ -0,0 +1,246 @@
+package instantquery
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	querier_stats "github.com/cortexproject/cortex/pkg/querier/stats"
+	"github.com/cortexproject/cortex/pkg/querier/tripperware"
+	"github.com/cortexproject/cortex/pkg/tenant"
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// combineOp is the operation combining the results of the partial queries of a split query.
+type combineOp int
+
+const (
+	combineSum combineOp = iota
+	combineMin
+	combineMax
+)
+
+var (
+	// Functions whose result over a range can be computed from their results over sub-ranges.
+	splittableFunctions = map[string]combineOp{
+		"sum_over_time":   combineSum,
+		"count_over_time": combineSum,
+		"min_over_time":   combineMin,
+		"max_over_time":   combineMax,
+	}
+
+	// Aggregations which can wrap a splittable function combined with the same operation.
+	splittableAggregations = map[parser.ItemType]combineOp{
+		parser.SUM: combineSum,
+		parser.MIN: combineMin,
+		parser.MAX: combineMax,
+	}
+)
+
+// SplitByIntervalMiddleware creates a new Middleware splitting instant queries with a range selector
+// longer than the split interval into partial queries over sub-ranges, executed in parallel and combined.
+func SplitByIntervalMiddleware(logger log.Logger, limits tripperware.Limits) tripperware.Middleware {
+	return tripperware.MiddlewareFunc(func(next tripperware.Handler) tripperware.Handler {
+		return splitByInterval{
+			next:   next,
+			limits: limits,
+			logger: logger,
+		}
+	})
+}
+
+type splitByInterval struct {
+	next   tripperware.Handler
+	limits tripperware.Limits
+	logger log.Logger
+}
+
+func (s splitByInterval) Do(ctx context.Context, r tripperware.Request) (tripperware.Response, error) {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+
+	interval := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, s.limits.SplitInstantQueriesByInterval)
+	if interval <= 0 {
+		return s.next.Do(ctx, r)
+	}
+
+	// Invalid queries are left to the querier, which returns the proper error.
+	expr, err := parser.ParseExpr(r.GetQuery())
+	if err != nil {
+		return s.next.Do(ctx, r)
+	}
+
+	queries, op, ok := splitInstantQuery(expr, interval)
+	if !ok {
+		return s.next.Do(ctx, r)
+	}
+
+	querier_stats.FromContext(ctx).AddExtraFields("split_instant_query.partial_queries", len(queries))
+
+	reqs := make([]tripperware.Request, 0, len(queries))
+	for _, q := range queries {
+		reqs = append(reqs, r.WithQuery(q))
+	}
+
+	reqResps, err := tripperware.DoRequests(ctx, s.next, reqs, s.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	resps := make([]*tripperware.PrometheusResponse, 0, len(reqResps))
+	for _, reqResp := range reqResps {
+		resp, ok := reqResp.Response.(*tripperware.PrometheusResponse)
+		if !ok || resp.Data.Result.GetVector() == nil || hasHistograms(resp) {
+			// Native histograms can't be combined, and other results are not expected.
+			level.Debug(util_log.WithContext(ctx, s.logger)).Log("msg", "can't combine the results of the split instant query, running it without splitting", "query", r.GetQuery())
+			return s.next.Do(ctx, r)
+		}
+		resps = append(resps, resp)
+	}
+
+	return combineResponses(ctx, r, op, resps)
+}
+
+// splitInstantQuery returns the partial queries, each one selecting a sub-range of at most the interval,
+// whose results combined with the returned operation are the result of the query. It returns false
+// if the query can't be split.
+func splitInstantQuery(root parser.Expr, interval time.Duration) ([]string, combineOp, bool) {
+	expr := unwrapParens(root)
+
+	aggrOp, aggregated := combineOp(0), false
+	if aggr, ok := expr.(*parser.AggregateExpr); ok {
+		if aggr.Param != nil {
+			return nil, 0, false
+		}
+		if aggrOp, aggregated = splittableAggregations[aggr.Op]; !aggregated {
+			return nil, 0, false
+		}
+		expr = unwrapParens(aggr.Expr)
+	}
+
+	call, ok := expr.(*parser.Call)
+	if !ok || len(call.Args) != 1 {
+		return nil, 0, false
+	}
+	op, ok := splittableFunctions[call.Func.Name]
+	if !ok || (aggregated && aggrOp != op) {
+		return nil, 0, false
+	}
+	matrix, ok := unwrapParens(call.Args[0]).(*parser.MatrixSelector)
+	if !ok {
+		return nil, 0, false
+	}
+	selector, ok := matrix.VectorSelector.(*parser.VectorSelector)
+	if !ok {
+		return nil, 0, false
+	}
+
+	splits := int(math.Ceil(float64(matrix.Range) / float64(interval)))
+	if splits <= 1 {
+		return nil, 0, false
+	}
+
+	// Range selectors include both ends, so all the sub-ranges except the oldest one exclude
+	// their oldest millisecond, to not select the samples on the boundaries twice.
+	origRange, origOffset := matrix.Range, selector.OriginalOffset
+	queries := make([]string, 0, splits)
+	for i := 0; i < splits; i++ {
+		matrix.Range = interval - time.Millisecond
+		if i == splits-1 {
+			matrix.Range = origRange - time.Duration(i)*interval
+		}
+		selector.OriginalOffset = origOffset + time.Duration(i)*interval
+		queries = append(queries, root.String())
+	}
+	matrix.Range, selector.OriginalOffset = origRange, origOffset
+
+	return queries, op, true
+}
+
+func unwrapParens(expr parser.Expr) parser.Expr {
+	for {
+		paren, ok := expr.(*parser.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = paren.Expr
+	}
+}
+
+func hasHistograms(resp *tripperware.PrometheusResponse) bool {
+	for _, s := range resp.Data.Result.GetVector().Samples {
+		if s.Histogram != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// combineResponses combines the samples with the same labels of the partial query responses.
+func combineResponses(ctx context.Context, r tripperware.Request, op combineOp, resps []*tripperware.PrometheusResponse) (tripperware.Response, error) {
+	combined := map[string]tripperware.Sample{}
+	buf := make([]byte, 0, 1024)
+	for _, resp := range resps {
+		for _, s := range resp.Data.Result.GetVector().Samples {
+			if s.Sample == nil {
+				continue
+			}
+			key := string(cortexpb.FromLabelAdaptersToLabels(s.Labels).Bytes(buf))
+			existing, ok := combined[key]
+			if !ok {
+				sample := *s.Sample
+				combined[key] = tripperware.Sample{Labels: s.Labels, Sample: &sample}
+				continue
+			}
+
+			switch op {
+			case combineSum:
+				existing.Sample.Value += s.Sample.Value
+			case combineMin:
+				existing.Sample.Value = math.Min(existing.Sample.Value, s.Sample.Value)
+			case combineMax:
+				existing.Sample.Value = math.Max(existing.Sample.Value, s.Sample.Value)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(combined))
+	for key := range combined {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	samples := make([]tripperware.Sample, 0, len(keys))
+	for _, key := range keys {
+		samples = append(samples, combined[key])
+	}
+
+	responses := make([]tripperware.Response, 0, len(resps))
+	for _, resp := range resps {
+		responses = append(responses, resp)
+	}
+
+	// Merge the warnings, infos and stats of the partial responses, then replace the merged samples.
+	merged, err := tripperware.MergeResponse(ctx, true, r, responses...)
+	if err != nil {
+		return nil, err
+	}
+	res := merged.(*tripperware.PrometheusResponse)
+	res.Data.Result = tripperware.PrometheusQueryResult{
+		Result: &tripperware.PrometheusQueryResult_Vector{
+			Vector: &tripperware.Vector{Samples: samples},
+		},
+	}
+	return res, nil
+}
@@ -0,0 +1,171 @@
+package instantquery
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/querier/tripperware"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestSplitInstantQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		query           string
+		expectedQueries []string
+		expectedOp      combineOp
+	}{
+		"should split a splittable function": {
+			query: `sum_over_time(up[3d])`,
+			expectedQueries: []string{
+				`sum_over_time(up[23h59m59s999ms])`,
+				`sum_over_time(up[23h59m59s999ms] offset 1d)`,
+				`sum_over_time(up[1d] offset 2d)`,
+			},
+			expectedOp: combineSum,
+		},
+		"should split a range not multiple of the interval": {
+			query: `max_over_time(up{job="test"}[36h] offset 1h)`,
+			expectedQueries: []string{
+				`max_over_time(up{job="test"}[23h59m59s999ms] offset 1h)`,
+				`max_over_time(up{job="test"}[12h] offset 1d1h)`,
+			},
+			expectedOp: combineMax,
+		},
+		"should split a splittable function wrapped by a compatible aggregation": {
+			query: `sum by (job) (count_over_time(up[2d]))`,
+			expectedQueries: []string{
+				`sum by (job) (count_over_time(up[23h59m59s999ms]))`,
+				`sum by (job) (count_over_time(up[1d] offset 1d))`,
+			},
+			expectedOp: combineSum,
+		},
+		"should not split a range not longer than the interval": {
+			query: `sum_over_time(up[1d])`,
+		},
+		"should not split a function which can't be combined": {
+			query: `avg_over_time(up[3d])`,
+		},
+		"should not split a splittable function wrapped by an incompatible aggregation": {
+			query: `max(sum_over_time(up[3d]))`,
+		},
+		"should not split a subquery": {
+			query: `sum_over_time(rate(up[5m])[3d:5m])`,
+		},
+		"should not split a binary expression": {
+			query: `sum_over_time(up[3d]) / 2`,
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := parser.ParseExpr(testData.query)
+			require.NoError(t, err)
+
+			queries, op, ok := splitInstantQuery(expr, 24*time.Hour)
+			assert.Equal(t, len(testData.expectedQueries) > 0, ok)
+			assert.Equal(t, testData.expectedQueries, queries)
+			assert.Equal(t, testData.expectedOp, op)
+
+			// The query is left unchanged.
+			assert.Equal(t, expr.String(), mustParse(t, testData.query).String())
+		})
+	}
+}
+
+func TestSplitByIntervalMiddleware(t *testing.T) {
+	t.Parallel()
+
+	vector := func(values map[string]float64) *tripperware.PrometheusResponse {
+		v := &tripperware.Vector{}
+		for job, value := range values {
+			v.Samples = append(v.Samples, tripperware.Sample{
+				Labels: []cortexpb.LabelAdapter{{Name: "job", Value: job}},
+				Sample: &cortexpb.Sample{Value: value, TimestampMs: 1000},
+			})
+		}
+		return &tripperware.PrometheusResponse{
+			Status: tripperware.StatusSuccess,
+			Data: tripperware.PrometheusData{
+				ResultType: model.ValVector.String(),
+				Result:     tripperware.PrometheusQueryResult{Result: &tripperware.PrometheusQueryResult_Vector{Vector: v}},
+			},
+		}
+	}
+
+	// Each partial query returns a different result.
+	partialResps := map[string]*tripperware.PrometheusResponse{
+		`max_over_time(up[23h59m59s999ms])`:           vector(map[string]float64{"a": 1, "b": 5}),
+		`max_over_time(up[23h59m59s999ms] offset 1d)`: vector(map[string]float64{"a": 3}),
+		`max_over_time(up[1d] offset 2d)`:             vector(map[string]float64{"a": 2, "c": 4}),
+	}
+
+	var (
+		mtx     sync.Mutex
+		queries []string
+	)
+	next := tripperware.HandlerFunc(func(_ context.Context, req tripperware.Request) (tripperware.Response, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		queries = append(queries, req.GetQuery())
+
+		if res, ok := partialResps[req.GetQuery()]; ok {
+			return res, nil
+		}
+		return vector(map[string]float64{"unsplit": 1}), nil
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	limits := &splitLimits{interval: 24 * time.Hour}
+	handler := SplitByIntervalMiddleware(log.NewNopLogger(), limits).Wrap(next)
+
+	res, err := handler.Do(ctx, &tripperware.PrometheusRequest{Query: `max_over_time(up[3d])`})
+	require.NoError(t, err)
+	assert.Len(t, queries, 3)
+	assert.Equal(t, model.ValVector.String(), res.(*tripperware.PrometheusResponse).Data.ResultType)
+	assert.Equal(t, []tripperware.Sample{
+		{Labels: []cortexpb.LabelAdapter{{Name: "job", Value: "a"}}, Sample: &cortexpb.Sample{Value: 3, TimestampMs: 1000}},
+		{Labels: []cortexpb.LabelAdapter{{Name: "job", Value: "b"}}, Sample: &cortexpb.Sample{Value: 5, TimestampMs: 1000}},
+		{Labels: []cortexpb.LabelAdapter{{Name: "job", Value: "c"}}, Sample: &cortexpb.Sample{Value: 4, TimestampMs: 1000}},
+	}, res.(*tripperware.PrometheusResponse).Data.Result.GetVector().Samples)
+
+	// A query which can't be split is forwarded as is.
+	queries = nil
+	res, err = handler.Do(ctx, &tripperware.PrometheusRequest{Query: `rate(up[3d])`})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`rate(up[3d])`}, queries)
+	assert.Equal(t, vector(map[string]float64{"unsplit": 1}), res)
+}
+
+func mustParse(t *testing.T, query string) parser.Expr {
+	expr, err := parser.ParseExpr(query)
+	require.NoError(t, err)
+	return expr
+}
+
+type splitLimits struct {
+	validation.Overrides
+	interval time.Duration
+}
+
+func (l splitLimits) SplitInstantQueriesByInterval(string) time.Duration {
+	return l.interval
+}
+
+func (splitLimits) MaxQueryParallelism(string) int {
+	return 14
+}
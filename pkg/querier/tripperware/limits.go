@@ -15,6 +15,15 @@ type Limits interface {
 	// MaxQueryLength returns the limit of the length (in time) of a query.
 	MaxQueryLength(string) time.Duration
 
+	// ClampMaxQueryLength returns whether the queries longer than the max query length are clamped instead of rejected.
+	ClampMaxQueryLength(string) bool
+
+	// MaxPointsPerSeries returns the limit of the number of points per series of the range queries.
+	MaxPointsPerSeries(string) int
+
+	// AdjustStepToMaxPoints returns whether the step of the range queries exceeding the max points per series is increased instead of rejected.
+	AdjustStepToMaxPoints(string) bool
+
 	// MaxQueryParallelism returns the limit to the number of split queries the
 	// frontend will process in parallel.
 	MaxQueryParallelism(string) int
@@ -23,9 +32,30 @@ type Limits interface {
 	// to prevent caching of very recent results.
 	MaxCacheFreshness(string) time.Duration
 
+	// ResultsCacheTTL returns how long cached results can be reused.
+	ResultsCacheTTL(string) time.Duration
+
+	// MetadataCacheTTL returns how long the responses of the labels and series APIs can be cached.
+	MetadataCacheTTL(string) time.Duration
+
+	// InstantQueryCacheTTL returns how long the results of the instant queries can be cached.
+	InstantQueryCacheTTL(string) time.Duration
+
+	// UnalignedQueryCacheTTL returns how long the results of the range queries not aligned with their step can be cached.
+	UnalignedQueryCacheTTL(string) time.Duration
+
+	// AlignQueriesWithStep returns whether the start and end of the queries are aligned with their step.
+	AlignQueriesWithStep(string) bool
+
 	// QueryVerticalShardSize returns the maximum number of queriers that can handle requests for this user.
 	QueryVerticalShardSize(userID string) int
 
+	// SplitInstantQueriesByInterval returns the interval to split instant queries with a long range selector by.
+	SplitInstantQueriesByInterval(userID string) time.Duration
+
+	// SplitQueriesByInterval returns the interval to split range queries by, overriding the default one when non-zero.
+	SplitQueriesByInterval(userID string) time.Duration
+
 	// QueryPriority returns the query priority config for the tenant, including different priorities and their attributes.
 	QueryPriority(userID string) validation.QueryPriority
 
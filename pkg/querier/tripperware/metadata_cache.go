@@ -0,0 +1,195 @@
+package tripperware
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cortexproject/cortex/pkg/chunk/cache"
+	"github.com/cortexproject/cortex/pkg/tenant"
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+)
+
+const (
+	cacheControlHeader = "Cache-Control"
+	// noCacheValue in the request cacheControlHeader fetches the response from the queriers
+	// and replaces the cached one.
+	noCacheValue = "no-cache"
+	// noStoreValue in the request or response cacheControlHeader bypasses the cache.
+	noStoreValue = "no-store"
+)
+
+// MetadataCacheConfig is the config for the cache of the labels and series API responses.
+type MetadataCacheConfig struct {
+	CacheConfig cache.Config `yaml:"cache"`
+}
+
+// RegisterFlags registers flags.
+func (cfg *MetadataCacheConfig) RegisterFlags(f *flag.FlagSet) {
+	cfg.CacheConfig.RegisterFlagsWithPrefix("frontend.metadata-cache.", "", f)
+}
+
+// Validate validates the config.
+func (cfg *MetadataCacheConfig) Validate() error {
+	return cfg.CacheConfig.Validate()
+}
+
+// cachedMetadataResponse is a successful response of the labels or series API stored in the cache.
+type cachedMetadataResponse struct {
+	// Key is the unhashed cache key, to detect hash collisions.
+	Key       string      `json:"key"`
+	ExpiresAt int64       `json:"expires_at"`
+	Header    http.Header `json:"header"`
+	Body      []byte      `json:"body"`
+}
+
+// Response headers stored with the cached responses.
+var cachedMetadataResponseHeaders = []string{"Content-Type", "Content-Encoding"}
+
+type metadataCache struct {
+	next   http.RoundTripper
+	cache  cache.Cache
+	limits Limits
+	logger log.Logger
+}
+
+// NewMetadataCacheTripperware returns a Tripperware caching the responses of the label names,
+// label values and series APIs for the per-tenant -frontend.metadata-cache-ttl. The requests
+// with the Cache-Control: no-cache header skip the cached response and refresh it, and the
+// ones with the Cache-Control: no-store header bypass the cache. All the other requests are
+// forwarded as is.
+func NewMetadataCacheTripperware(cfg MetadataCacheConfig, limits Limits, logger log.Logger, reg prometheus.Registerer) (Tripperware, cache.Cache, error) {
+	c, err := cache.New(cfg.CacheConfig, reg, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &metadataCache{
+			next:   next,
+			cache:  c,
+			limits: limits,
+			logger: logger,
+		}
+	}, c, nil
+}
+
+func (m *metadataCache) RoundTrip(r *http.Request) (*http.Response, error) {
+	switch getOperation(r) {
+	case "series", "labels", "label_values":
+	default:
+		return m.next.RoundTrip(r)
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		return m.next.RoundTrip(r)
+	}
+
+	cacheControl := r.Header.Values(cacheControlHeader)
+	if headerValuesContain(cacheControl, noStoreValue) {
+		return m.next.RoundTrip(r)
+	}
+
+	tenantIDs, err := tenant.TenantIDs(r.Context())
+	if err != nil {
+		return m.next.RoundTrip(r)
+	}
+	ttl := smallestDurationPerTenant(tenantIDs, m.limits.MetadataCacheTTL)
+	if ttl <= 0 {
+		return m.next.RoundTrip(r)
+	}
+
+	key := metadataCacheKey(tenant.JoinTenantIDs(tenantIDs), r)
+	if !headerValuesContain(cacheControl, noCacheValue) {
+		if resp, ok := m.get(r, key); ok {
+			return resp, nil
+		}
+	}
+
+	resp, err := m.next.RoundTrip(r)
+	if err != nil || resp.StatusCode != http.StatusOK || headerValuesContain(resp.Header.Values(cacheControlHeader), noStoreValue) {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	m.put(r, key, ttl, resp.Header, body)
+	return resp, nil
+}
+
+func (m *metadataCache) get(r *http.Request, key string) (*http.Response, bool) {
+	found, bufs, _ := m.cache.Fetch(r.Context(), []string{cache.HashKey(key)})
+	if len(found) != 1 {
+		return nil, false
+	}
+
+	var cached cachedMetadataResponse
+	if err := json.Unmarshal(bufs[0], &cached); err != nil {
+		level.Error(util_log.WithContext(r.Context(), m.logger)).Log("msg", "error unmarshalling cached metadata response", "err", err)
+		return nil, false
+	}
+	if cached.Key != key || time.Now().UnixMilli() >= cached.ExpiresAt {
+		return nil, false
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", http.StatusOK, http.StatusText(http.StatusOK)),
+		StatusCode:    http.StatusOK,
+		Header:        cached.Header,
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       r,
+	}, true
+}
+
+func (m *metadataCache) put(r *http.Request, key string, ttl time.Duration, header http.Header, body []byte) {
+	cached := cachedMetadataResponse{
+		Key:       key,
+		ExpiresAt: time.Now().Add(ttl).UnixMilli(),
+		Header:    http.Header{},
+		Body:      body,
+	}
+	for _, h := range cachedMetadataResponseHeaders {
+		if values := header.Values(h); len(values) > 0 {
+			cached.Header[h] = values
+		}
+	}
+
+	buf, err := json.Marshal(cached)
+	if err != nil {
+		level.Error(util_log.WithContext(r.Context(), m.logger)).Log("msg", "error marshalling metadata response", "err", err)
+		return
+	}
+	m.cache.Store(r.Context(), []string{cache.HashKey(key)}, [][]byte{buf})
+}
+
+// metadataCacheKey returns the cache key of the request, made of the tenant, the API path
+// and the parameters sorted by name, parsed from both the URL and the POST body.
+func metadataCacheKey(userID string, r *http.Request) string {
+	if r.Form == nil {
+		// The frontend handler has already parsed the request, except in tests.
+		_ = r.ParseForm()
+	}
+	return fmt.Sprintf("metadata:%s:%s:%s", userID, r.URL.Path, r.Form.Encode())
+}
+
+func headerValuesContain(values []string, value string) bool {
+	for _, v := range values {
+		if strings.Contains(v, value) {
+			return true
+		}
+	}
+	return false
+}
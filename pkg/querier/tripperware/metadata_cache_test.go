@@ -0,0 +1,158 @@
+package tripperware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/chunk/cache"
+)
+
+func TestMetadataCache(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		path          string
+		ttl           time.Duration
+		headers       map[string]string
+		statusCode    int
+		expectedCalls int
+	}{
+		"label names are cached": {
+			path:          "/api/v1/labels?match[]=up",
+			ttl:           time.Minute,
+			expectedCalls: 1,
+		},
+		"label values are cached": {
+			path:          "/api/v1/label/job/values",
+			ttl:           time.Minute,
+			expectedCalls: 1,
+		},
+		"series are cached": {
+			path:          "/api/v1/series?match[]=up&start=0&end=10",
+			ttl:           time.Minute,
+			expectedCalls: 1,
+		},
+		"queries are not cached": {
+			path:          "/api/v1/query?query=up",
+			ttl:           time.Minute,
+			expectedCalls: 2,
+		},
+		"responses are not cached with a zero ttl": {
+			path:          "/api/v1/labels",
+			expectedCalls: 2,
+		},
+		"failed responses are not cached": {
+			path:          "/api/v1/labels",
+			ttl:           time.Minute,
+			statusCode:    http.StatusInternalServerError,
+			expectedCalls: 2,
+		},
+		"no-cache requests refresh the cached responses": {
+			path:          "/api/v1/labels",
+			ttl:           time.Minute,
+			headers:       map[string]string{cacheControlHeader: noCacheValue},
+			expectedCalls: 2,
+		},
+		"no-store requests bypass the cache": {
+			path:          "/api/v1/labels",
+			ttl:           time.Minute,
+			headers:       map[string]string{cacheControlHeader: noStoreValue},
+			expectedCalls: 2,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			statusCode := tc.statusCode
+			if statusCode == 0 {
+				statusCode = http.StatusOK
+			}
+			calls := 0
+			next := RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+				calls++
+				body := []byte(`{"status":"success","data":["` + strconv.Itoa(calls) + `"]}`)
+				return &http.Response{
+					StatusCode: statusCode,
+					Header:     http.Header{"Content-Type": []string{"application/json"}, "X-Other": []string{"value"}},
+					Body:       io.NopCloser(bytes.NewReader(body)),
+				}, nil
+			})
+
+			tw, c, err := NewMetadataCacheTripperware(MetadataCacheConfig{CacheConfig: cache.Config{Cache: cache.NewMockCache()}}, mockLimits{metadataCacheTTL: tc.ttl}, log.NewNopLogger(), nil)
+			require.NoError(t, err)
+			defer c.Stop()
+			rt := tw(next)
+
+			var bodies []string
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+				for k, v := range tc.headers {
+					req.Header.Set(k, v)
+				}
+				req = req.WithContext(user.InjectOrgID(context.Background(), "user-1"))
+
+				resp, err := rt.RoundTrip(req)
+				require.NoError(t, err)
+				assert.Equal(t, statusCode, resp.StatusCode)
+				assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				bodies = append(bodies, string(body))
+			}
+
+			assert.Equal(t, tc.expectedCalls, calls)
+			if tc.expectedCalls == 1 {
+				assert.Equal(t, bodies[0], bodies[1])
+			} else {
+				assert.NotEqual(t, bodies[0], bodies[1])
+			}
+		})
+	}
+}
+
+func TestMetadataCache_ShouldNotShareResponsesAcrossTenantsAndParameters(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	next := RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader([]byte("{}")))}, nil
+	})
+
+	tw, c, err := NewMetadataCacheTripperware(MetadataCacheConfig{CacheConfig: cache.Config{Cache: cache.NewMockCache()}}, mockLimits{metadataCacheTTL: time.Minute}, log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	defer c.Stop()
+	rt := tw(next)
+
+	for _, r := range []struct {
+		tenant string
+		path   string
+	}{
+		{tenant: "user-1", path: "/api/v1/labels?match[]=up"},
+		{tenant: "user-2", path: "/api/v1/labels?match[]=up"},
+		{tenant: "user-1", path: "/api/v1/labels?match[]=down"},
+		{tenant: "user-1", path: "/api/v1/series?match[]=up"},
+		// Same parameters in a different order.
+		{tenant: "user-1", path: "/api/v1/labels?start=0&match[]=up"},
+		{tenant: "user-1", path: "/api/v1/labels?match[]=up&start=0"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, r.path, nil)
+		req = req.WithContext(user.InjectOrgID(context.Background(), r.tenant))
+		_, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 5, calls)
+}
@@ -0,0 +1,133 @@
+package tripperware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/cortexproject/cortex/pkg/util"
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// emptyMetadataResponseBody is the response of the labels and series APIs with no results.
+var emptyMetadataResponseBody = []byte(`{"status":"success","data":[]}`)
+
+// enforceMetadataQueryLimits enforces the max query lookback and length of the tenants on the
+// series, label names and label values requests, like the limits middleware does on the range
+// queries. The start time is clamped to the max query lookback, and to the max query length
+// before the end time if -frontend.clamp-max-query-length is enabled, otherwise the requests
+// longer than the max query length are rejected. It returns true if the request is fully
+// outside the max query lookback, so that it can be answered with an empty response.
+func enforceMetadataQueryLimits(r *http.Request, now time.Time, limits Limits, tenantIDs []string, logger log.Logger) (bool, error) {
+	maxQueryLookback := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, limits.MaxQueryLookback)
+	maxQueryLength := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, limits.MaxQueryLength)
+	if maxQueryLookback <= 0 && maxQueryLength <= 0 {
+		return false, nil
+	}
+
+	if err := parseFormKeepBody(r); err != nil {
+		return false, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+	// The requests without start or end time select the whole time range, up until now.
+	start, end := int64(0), now.UnixMilli()
+	if v := r.FormValue("start"); v != "" {
+		t, err := util.ParseTime(v)
+		if err != nil {
+			return false, err
+		}
+		start = t
+	}
+	if v := r.FormValue("end"); v != "" {
+		t, err := util.ParseTime(v)
+		if err != nil {
+			return false, err
+		}
+		end = t
+	}
+	origStart := start
+
+	// Clamp the time range based on the max query lookback.
+	if maxQueryLookback > 0 {
+		minStartTime := now.Add(-maxQueryLookback).UnixMilli()
+		if end < minStartTime {
+			return true, nil
+		}
+		if start < minStartTime {
+			start = minStartTime
+		}
+	}
+
+	// Enforce the max query length.
+	if maxQueryLength > 0 {
+		if queryLen := time.Duration(end-start) * time.Millisecond; queryLen > maxQueryLength {
+			if !validation.AllTrueBooleansPerTenant(tenantIDs, limits.ClampMaxQueryLength) {
+				return false, httpgrpc.Errorf(http.StatusBadRequest, validation.ErrQueryTooLong, queryLen, maxQueryLength)
+			}
+			start = end - maxQueryLength.Milliseconds()
+		}
+	}
+
+	if start != origStart {
+		level.Debug(util_log.WithContext(r.Context(), logger)).Log(
+			"msg", "the start time of the request has been manipulated because of the 'max query lookback' or 'max query length' setting",
+			"original", util.FormatTimeMillis(origStart),
+			"updated", util.FormatTimeMillis(start))
+
+		setFormValue(r, "start", EncodeTime(start))
+	}
+	return false, nil
+}
+
+// parseFormKeepBody parses the request parameters, keeping the POST body to forward it.
+func parseFormKeepBody(r *http.Request) error {
+	if r.Form != nil {
+		// The frontend handler has already parsed the request, except in tests.
+		return nil
+	}
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	err := r.ParseForm()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return err
+}
+
+// setFormValue replaces the value of the parameter in the POST body if it's set there,
+// otherwise in the URL, since the request is forwarded as is.
+func setFormValue(r *http.Request, name, value string) {
+	if _, ok := r.PostForm[name]; ok {
+		r.PostForm.Set(name, value)
+		body := r.PostForm.Encode()
+		r.Body = io.NopCloser(strings.NewReader(body))
+		r.ContentLength = int64(len(body))
+	} else {
+		query := r.URL.Query()
+		query.Set(name, value)
+		r.URL.RawQuery = query.Encode()
+	}
+	r.Form.Set(name, value)
+}
+
+func newEmptyMetadataResponse(r *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", http.StatusOK, http.StatusText(http.StatusOK)),
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(emptyMetadataResponseBody)),
+		ContentLength: int64(len(emptyMetadataResponseBody)),
+		Request:       r,
+	}
+}
@@ -0,0 +1,148 @@
+package tripperware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnforceMetadataQueryLimits(t *testing.T) {
+	t.Parallel()
+	const (
+		thirtyDays = 30 * 24 * time.Hour
+	)
+
+	now := time.Now()
+	unix := func(t time.Time) string {
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+
+	for name, tc := range map[string]struct {
+		limits        mockLimits
+		start, end    string
+		post          bool
+		expectedErr   string
+		expectedEmpty bool
+		expectedStart string
+	}{
+		"should not manipulate the request without limits": {
+			start:         "0",
+			end:           unix(now),
+			expectedStart: "0",
+		},
+		"should not manipulate a request on short time range": {
+			limits:        mockLimits{maxQueryLookback: thirtyDays, maxQueryLength: thirtyDays},
+			start:         unix(now.Add(-time.Hour)),
+			end:           unix(now),
+			expectedStart: unix(now.Add(-time.Hour)),
+		},
+		"should clamp the start time to the max query lookback": {
+			limits:        mockLimits{maxQueryLookback: thirtyDays},
+			start:         unix(now.Add(-2 * thirtyDays)),
+			end:           unix(now),
+			expectedStart: unix(now.Add(-thirtyDays)),
+		},
+		"should clamp the start time to the max query lookback without start time": {
+			limits:        mockLimits{maxQueryLookback: thirtyDays},
+			expectedStart: unix(now.Add(-thirtyDays)),
+		},
+		"should clamp the start time in the POST body": {
+			limits:        mockLimits{maxQueryLookback: thirtyDays},
+			start:         unix(now.Add(-2 * thirtyDays)),
+			end:           unix(now),
+			post:          true,
+			expectedStart: unix(now.Add(-thirtyDays)),
+		},
+		"should skip a request outside the max query lookback": {
+			limits:        mockLimits{maxQueryLookback: thirtyDays},
+			start:         unix(now.Add(-3 * thirtyDays)),
+			end:           unix(now.Add(-2 * thirtyDays)),
+			expectedEmpty: true,
+		},
+		"should fail on a request over the max query length": {
+			limits:      mockLimits{maxQueryLength: thirtyDays},
+			start:       unix(now.Add(-2 * thirtyDays)),
+			end:         unix(now),
+			expectedErr: "the query time range exceeds the limit",
+		},
+		"should fail on a request without start time with max query length": {
+			limits:      mockLimits{maxQueryLength: thirtyDays},
+			expectedErr: "the query time range exceeds the limit",
+		},
+		"should not fail on a request over the max query length within the max query lookback": {
+			limits:        mockLimits{maxQueryLookback: thirtyDays, maxQueryLength: thirtyDays},
+			start:         unix(now.Add(-2 * thirtyDays)),
+			end:           unix(now),
+			expectedStart: unix(now.Add(-thirtyDays)),
+		},
+		"should clamp the start time to the max query length if clamping is enabled": {
+			limits:        mockLimits{maxQueryLength: thirtyDays, clampMaxQueryLength: true},
+			start:         unix(now.Add(-4 * thirtyDays)),
+			end:           unix(now.Add(-2 * thirtyDays)),
+			expectedStart: unix(now.Add(-3 * thirtyDays)),
+		},
+		"should fail on an invalid start time": {
+			limits:      mockLimits{maxQueryLength: thirtyDays},
+			start:       "foo",
+			expectedErr: "cannot parse",
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			params := "match[]=up"
+			if tc.start != "" {
+				params += "&start=" + tc.start
+			}
+			if tc.end != "" {
+				params += "&end=" + tc.end
+			}
+			var r *http.Request
+			if tc.post {
+				r = httptest.NewRequest(http.MethodPost, "/api/v1/series", strings.NewReader(params))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				r = httptest.NewRequest(http.MethodGet, "/api/v1/series?"+params, nil)
+			}
+
+			empty, err := enforceMetadataQueryLimits(r, now, tc.limits, []string{"user-1"}, log.NewNopLogger())
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedEmpty, empty)
+			if tc.expectedEmpty {
+				return
+			}
+
+			// The forwarded request should have the expected parameters (1s delta).
+			var forwarded string
+			if tc.post {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				forwarded = string(body)
+			} else {
+				forwarded = r.URL.RawQuery
+			}
+			values, err := url.ParseQuery(forwarded)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"up"}, values["match[]"])
+			expectedStart, _ := strconv.ParseFloat(tc.expectedStart, 64)
+			start, err := strconv.ParseFloat(values.Get("start"), 64)
+			require.NoError(t, err)
+			assert.InDelta(t, expectedStart, start, 1)
+		})
+	}
+}
@@ -43,6 +43,7 @@ type Compression string
 
 const (
 	GzipCompression     Compression = "gzip"
+	SnappyCompression   Compression = "snappy"
 	NonCompression      Compression = ""
 	JsonCodecType       CodecType   = "json"
 	ProtobufCodecType   CodecType   = "protobuf"
@@ -92,6 +93,8 @@ type Request interface {
 	WithStartEnd(startTime int64, endTime int64) Request
 	// WithQuery clone the current request with a different query.
 	WithQuery(string) Request
+	// WithStep clone the current request with a different step.
+	WithStep(step int64) Request
 	proto.Message
 	// LogToSpan writes information about this request to an OpenTracing span
 	LogToSpan(opentracing.Span)
@@ -228,6 +231,13 @@ func (m *PrometheusRequest) WithQuery(query string) Request {
 	return &new
 }
 
+// WithStep clones the current `PrometheusRequest` with a new step.
+func (m *PrometheusRequest) WithStep(step int64) Request {
+	new := *m
+	new.Step = step
+	return &new
+}
+
 // WithStats clones the current `PrometheusRequest` with a new stats.
 func (m *PrometheusRequest) WithStats(stats string) Request {
 	new := *m
@@ -491,6 +501,9 @@ func BodyBufferFromHTTPGRPCResponse(res *httpgrpc.HTTPResponse, logger log.Logge
 		defer runutil.CloseWithLogOnErr(logger, gReader, "close gzip reader")
 
 		return io.ReadAll(gReader)
+	} else if strings.EqualFold(headers.Get("Content-Encoding"), "snappy") {
+		sReader := snappy.NewReader(bytes.NewBuffer(res.Body))
+		return io.ReadAll(sReader)
 	}
 
 	return res.Body, nil
@@ -752,8 +765,8 @@ func (s *PrometheusResponseStats) MarshalJSON() ([]byte, error) {
 }
 
 func SetRequestHeaders(h http.Header, defaultCodecType CodecType, compression Compression) {
-	if compression == GzipCompression {
-		h.Set("Accept-Encoding", string(GzipCompression))
+	if compression == GzipCompression || compression == SnappyCompression {
+		h.Set("Accept-Encoding", string(compression))
 	}
 	if defaultCodecType == ProtobufCodecType {
 		h.Set("Accept", ApplicationProtobuf+", "+ApplicationJson)
@@ -238,6 +238,8 @@ type Extent struct {
 	End      int64      `protobuf:"varint,2,opt,name=end,proto3" json:"end"`
 	TraceId  string     `protobuf:"bytes,4,opt,name=trace_id,json=traceId,proto3" json:"-"`
 	Response *types.Any `protobuf:"bytes,5,opt,name=response,proto3" json:"response"`
+	// Unix timestamp in milliseconds of the oldest query whose results are in the extent.
+	CachedAt int64 `protobuf:"varint,6,opt,name=cached_at,json=cachedAt,proto3" json:"cached_at"`
 }
 
 func (m *Extent) Reset()      { *m = Extent{} }
@@ -300,6 +302,13 @@ func (m *Extent) GetResponse() *types.Any {
 	return nil
 }
 
+func (m *Extent) GetCachedAt() int64 {
+	if m != nil {
+		return m.CachedAt
+	}
+	return 0
+}
+
 type SampleStream struct {
 	Labels     []github_com_cortexproject_cortex_pkg_cortexpb.LabelAdapter `protobuf:"bytes,1,rep,name=labels,proto3,customtype=github.com/cortexproject/cortex/pkg/cortexpb.LabelAdapter" json:"metric"`
 	Samples    []cortexpb.Sample                                           `protobuf:"bytes,2,rep,name=samples,proto3" json:"values"`
@@ -994,83 +1003,84 @@ func init() {
 func init() { proto.RegisterFile("query.proto", fileDescriptor_5c6ac9b241082464) }
 
 var fileDescriptor_5c6ac9b241082464 = []byte{
-	// 1208 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x56, 0x4b, 0x6f, 0x1b, 0x55,
-	0x14, 0xf6, 0xf8, 0x31, 0x76, 0x8e, 0xd3, 0xa4, 0xdc, 0xf4, 0xe1, 0x94, 0x32, 0x63, 0x46, 0x20,
-	0x05, 0x41, 0x1d, 0x91, 0x0a, 0x10, 0x20, 0x2a, 0x3a, 0x50, 0x48, 0x0b, 0xa5, 0xed, 0x4d, 0x55,
-	0x24, 0x36, 0xd5, 0xb5, 0x7d, 0xeb, 0x0c, 0xf1, 0x3c, 0x7a, 0xe7, 0x4e, 0x13, 0xb3, 0x62, 0xcd,
-	0x02, 0xb1, 0x46, 0x62, 0xc1, 0x8e, 0x05, 0x3f, 0x24, 0xcb, 0x2e, 0x2b, 0x24, 0x46, 0xd4, 0xd9,
-	0xa0, 0x59, 0xf5, 0x27, 0xa0, 0xfb, 0x18, 0x7b, 0x9c, 0x38, 0x89, 0xba, 0x62, 0xe3, 0xcc, 0x39,
-	0xe7, 0x3b, 0xcf, 0x7b, 0x1e, 0x81, 0xe6, 0xe3, 0x84, 0xb2, 0x51, 0x27, 0x62, 0x21, 0x0f, 0x51,
-	0x93, 0x33, 0x2f, 0x8a, 0x28, 0xdb, 0x25, 0x8c, 0x5e, 0x3a, 0x37, 0x08, 0x07, 0xa1, 0xe4, 0xaf,
-	0x8b, 0x2f, 0x05, 0xb9, 0x64, 0x0d, 0xc2, 0x70, 0x30, 0xa4, 0xeb, 0x92, 0xea, 0x26, 0x8f, 0xd6,
-	0xfb, 0x09, 0x23, 0xdc, 0x0b, 0x03, 0x2d, 0x5f, 0x3d, 0x2c, 0x27, 0x81, 0xb6, 0x7e, 0xe9, 0xc3,
-	0x81, 0xc7, 0xb7, 0x93, 0x6e, 0xa7, 0x17, 0xfa, 0xeb, 0xbd, 0x90, 0x71, 0xba, 0x17, 0xb1, 0xf0,
-	0x7b, 0xda, 0xe3, 0x9a, 0x5a, 0x8f, 0x76, 0x06, 0xb9, 0xa0, 0xab, 0x3f, 0x94, 0xaa, 0xf3, 0x53,
-	0x05, 0xd0, 0x5d, 0x16, 0xfa, 0x94, 0x6f, 0xd3, 0x24, 0xc6, 0x34, 0x8e, 0xc2, 0x20, 0xa6, 0xc8,
-	0x01, 0x73, 0x8b, 0x13, 0x9e, 0xc4, 0x2d, 0xa3, 0x6d, 0xac, 0x2d, 0xb8, 0x90, 0xa5, 0xb6, 0x19,
-	0x4b, 0x0e, 0xd6, 0x12, 0xf4, 0x25, 0x54, 0x3f, 0x27, 0x9c, 0xb4, 0xca, 0x6d, 0x63, 0xad, 0xb9,
-	0xf1, 0x6a, 0xa7, 0x90, 0x62, 0x67, 0x6a, 0x52, 0x40, 0xdc, 0x0b, 0xfb, 0xa9, 0x5d, 0xca, 0x52,
-	0x7b, 0xa9, 0x4f, 0x38, 0x79, 0x27, 0xf4, 0x3d, 0x4e, 0xfd, 0x88, 0x8f, 0xb0, 0x34, 0x80, 0xde,
-	0x83, 0x85, 0x1b, 0x8c, 0x85, 0xec, 0xfe, 0x28, 0xa2, 0xad, 0x8a, 0xf4, 0x77, 0x31, 0x4b, 0xed,
-	0x15, 0x9a, 0x33, 0x0b, 0x1a, 0x53, 0x24, 0x7a, 0x0b, 0x6a, 0x92, 0x68, 0x55, 0xa5, 0xca, 0x4a,
-	0x96, 0xda, 0xcb, 0x52, 0xa5, 0x00, 0x57, 0x08, 0xf4, 0x05, 0xd4, 0x37, 0x29, 0xe9, 0x53, 0x16,
-	0xb7, 0x6a, 0xed, 0xca, 0x5a, 0x73, 0xe3, 0xcd, 0x63, 0xa2, 0xcd, 0x0b, 0xa0, 0xd0, 0x6e, 0x2d,
-	0x4b, 0x6d, 0xe3, 0x0a, 0xce, 0x95, 0xd1, 0x06, 0x34, 0xbe, 0x25, 0x2c, 0xf0, 0x82, 0x41, 0xdc,
-	0x32, 0xdb, 0x95, 0xb5, 0x05, 0xf7, 0x42, 0x96, 0xda, 0x68, 0x57, 0xf3, 0x0a, 0x8e, 0x27, 0x38,
-	0x11, 0xe6, 0xcd, 0xe0, 0x51, 0x18, 0xb7, 0xea, 0x52, 0x41, 0x86, 0xe9, 0x09, 0x46, 0x31, 0x4c,
-	0x89, 0x70, 0xfe, 0x36, 0x60, 0x69, 0xb6, 0x72, 0xa8, 0x03, 0x80, 0x69, 0x9c, 0x0c, 0xb9, 0x2c,
-	0x8e, 0x7a, 0x8c, 0xa5, 0x2c, 0xb5, 0x81, 0x4d, 0xb8, 0xb8, 0x80, 0x40, 0xb7, 0xc0, 0x54, 0x94,
-	0x7e, 0x16, 0xe7, 0x98, 0x44, 0xef, 0x89, 0xe6, 0x54, 0x48, 0x77, 0x49, 0xbf, 0x8e, 0xa9, 0x6c,
-	0x62, 0x6d, 0x01, 0xdd, 0x81, 0x9a, 0x78, 0xf2, 0x58, 0xbe, 0x49, 0x73, 0xe3, 0x8d, 0x53, 0x6a,
-	0x26, 0xda, 0x22, 0x56, 0xf9, 0x49, 0xb5, 0x62, 0x7e, 0x92, 0xe1, 0xec, 0xc0, 0xd2, 0x67, 0xa4,
-	0xb7, 0x4d, 0xfb, 0x93, 0x3e, 0x5b, 0x85, 0xca, 0x0e, 0x1d, 0xe9, 0xbc, 0xea, 0x59, 0x6a, 0x0b,
-	0x12, 0x8b, 0x1f, 0x74, 0x0d, 0xea, 0x74, 0x8f, 0xd3, 0x80, 0xc7, 0xad, 0xb2, 0x7c, 0xb3, 0x95,
-	0x19, 0xff, 0x37, 0xa4, 0xcc, 0x5d, 0xd6, 0xb1, 0xe7, 0x58, 0x9c, 0x7f, 0x38, 0x7f, 0x1a, 0x60,
-	0x2a, 0x10, 0xb2, 0x65, 0x22, 0x8c, 0x4b, 0x3f, 0x15, 0x77, 0x21, 0x4b, 0x6d, 0xc5, 0xc0, 0xea,
-	0x8f, 0x08, 0x83, 0x06, 0x7d, 0x59, 0xb2, 0x8a, 0x0a, 0x83, 0x06, 0x7d, 0x2c, 0x7e, 0x50, 0x1b,
-	0x1a, 0x9c, 0x91, 0x1e, 0x7d, 0xe8, 0xf5, 0x75, 0xa3, 0xe5, 0x4d, 0x21, 0xd9, 0x37, 0xfb, 0xe8,
-	0x1a, 0x34, 0x98, 0xce, 0xa7, 0x55, 0x93, 0x95, 0x3a, 0xd7, 0x51, 0xb3, 0xda, 0xc9, 0x67, 0xb5,
-	0x73, 0x3d, 0x18, 0xb9, 0x8b, 0x59, 0x6a, 0x4f, 0x90, 0x78, 0xf2, 0x75, 0xab, 0xda, 0xa8, 0x9c,
-	0xad, 0x3a, 0xbf, 0x96, 0x61, 0x71, 0x8b, 0xf8, 0xd1, 0x90, 0x6e, 0x71, 0x46, 0x89, 0x8f, 0xf6,
-	0xc0, 0x1c, 0x92, 0x2e, 0x1d, 0x8a, 0x11, 0x54, 0xe9, 0xe7, 0x13, 0xdc, 0xf9, 0x5a, 0xf0, 0xef,
-	0x12, 0x8f, 0xb9, 0x5f, 0x89, 0xf4, 0xff, 0x4a, 0xed, 0x97, 0xda, 0x00, 0x4a, 0xff, 0x7a, 0x9f,
-	0x44, 0x9c, 0x32, 0xf1, 0xee, 0x3e, 0xe5, 0xcc, 0xeb, 0x61, 0xed, 0x0f, 0x7d, 0x04, 0xf5, 0x58,
-	0x46, 0x92, 0x57, 0xfe, 0xec, 0xd4, 0xb5, 0x0a, 0x71, 0xda, 0x32, 0x4f, 0xc8, 0x30, 0xa1, 0x31,
-	0xce, 0x15, 0xd0, 0x7d, 0x80, 0x6d, 0x2f, 0xe6, 0xe1, 0x80, 0x11, 0x5f, 0x34, 0x8e, 0x50, 0x6f,
-	0xcf, 0x3c, 0x9c, 0xb2, 0xb0, 0x99, 0x83, 0x64, 0x1a, 0x48, 0x9b, 0x2b, 0xe8, 0xe2, 0xc2, 0xb7,
-	0xf3, 0x03, 0xac, 0xcc, 0x51, 0x43, 0xaf, 0xc3, 0x22, 0xf7, 0x7c, 0x1a, 0x73, 0xe2, 0x47, 0x0f,
-	0x7d, 0xb5, 0xab, 0x2a, 0xb8, 0x39, 0xe1, 0xdd, 0x8e, 0xd1, 0xa7, 0xb0, 0x30, 0xb1, 0xa3, 0x47,
-	0xe2, 0xf2, 0x49, 0xe1, 0xb8, 0x55, 0x11, 0x0a, 0x9e, 0x2a, 0x39, 0x8f, 0x61, 0xf9, 0x10, 0x06,
-	0x9d, 0x83, 0x5a, 0x2f, 0x4c, 0x02, 0xd5, 0x4f, 0x06, 0x56, 0x04, 0x3a, 0x0b, 0x95, 0x38, 0x51,
-	0x4e, 0x0c, 0x2c, 0x3e, 0xd1, 0xfb, 0x50, 0xef, 0x26, 0xbd, 0x1d, 0xca, 0xf3, 0x4a, 0xcc, 0xba,
-	0x9e, 0x3a, 0x95, 0x20, 0x9c, 0x83, 0x9d, 0x18, 0x96, 0x0f, 0xc9, 0x90, 0x05, 0xd0, 0x0d, 0x93,
-	0xa0, 0x4f, 0x98, 0x47, 0x55, 0xa2, 0x35, 0x5c, 0xe0, 0x88, 0x90, 0x86, 0xe1, 0x2e, 0x65, 0xda,
-	0xbd, 0x22, 0x04, 0x37, 0x11, 0xee, 0xe4, 0x04, 0x1b, 0x58, 0x11, 0xd3, 0xf0, 0xab, 0x85, 0xf0,
-	0x1d, 0x1f, 0x2e, 0x1e, 0x33, 0xd3, 0x08, 0x4f, 0x1b, 0xc2, 0x90, 0x25, 0x7c, 0xfb, 0xb4, 0x55,
-	0xa0, 0xd0, 0x6a, 0x23, 0x34, 0xc5, 0x78, 0x6a, 0xfd, 0x49, 0xa3, 0x38, 0xfb, 0x65, 0xb0, 0x4e,
-	0x56, 0x44, 0x77, 0xe0, 0x3c, 0x0f, 0x39, 0x19, 0xca, 0x5d, 0x45, 0xba, 0xc3, 0x5c, 0xaa, 0xc7,
-	0x78, 0x35, 0x4b, 0xed, 0xf9, 0x00, 0x3c, 0x9f, 0x8d, 0x7e, 0x37, 0xe0, 0xf2, 0x5c, 0xc9, 0x5d,
-	0xca, 0xb6, 0x38, 0x8d, 0x74, 0xbb, 0x7f, 0x7c, 0x4a, 0x76, 0x87, 0xb5, 0x65, 0xb4, 0xda, 0x84,
-	0xdb, 0xce, 0x52, 0xfb, 0x44, 0x27, 0xf8, 0x44, 0x29, 0x7a, 0x17, 0x9a, 0x11, 0x25, 0x3b, 0x79,
-	0xaa, 0x15, 0x99, 0xea, 0x72, 0x96, 0xda, 0x45, 0x36, 0x2e, 0x12, 0x8e, 0x07, 0x2f, 0x19, 0xa4,
-	0xe8, 0x00, 0x39, 0xb8, 0x7a, 0x62, 0x14, 0x71, 0x64, 0x9c, 0xca, 0x47, 0xc6, 0xc9, 0xb9, 0x0f,
-	0xad, 0xe3, 0x8e, 0x25, 0x5a, 0x85, 0xea, 0x37, 0xc4, 0xcf, 0x8f, 0x94, 0xde, 0x92, 0x92, 0x85,
-	0x5e, 0x03, 0xf3, 0x81, 0x5c, 0x14, 0xb2, 0xc2, 0x13, 0xa1, 0x66, 0x3a, 0xbf, 0x19, 0x70, 0x7e,
-	0xee, 0x69, 0x42, 0x57, 0xc0, 0x7c, 0x42, 0x7b, 0x3c, 0x64, 0xba, 0xf1, 0x66, 0x6f, 0xc0, 0x03,
-	0x29, 0xda, 0x2c, 0x61, 0x0d, 0x42, 0x97, 0xa1, 0xc1, 0xc8, 0xae, 0x3b, 0xe2, 0x54, 0x45, 0xbf,
-	0xb8, 0x59, 0xc2, 0x13, 0x8e, 0x30, 0xe6, 0x13, 0xce, 0xbc, 0x3d, 0x7d, 0xd0, 0x66, 0x8d, 0xdd,
-	0x96, 0x22, 0x61, 0x4c, 0x81, 0xdc, 0x06, 0xe8, 0x83, 0xe8, 0x7c, 0x02, 0xa6, 0x72, 0x85, 0xae,
-	0x16, 0x27, 0xe1, 0xe8, 0x51, 0xd2, 0xdb, 0x51, 0xed, 0x90, 0x49, 0xab, 0xff, 0x5c, 0x06, 0x53,
-	0x49, 0xfe, 0xc7, 0xa5, 0xfe, 0x01, 0x98, 0x2a, 0x1e, 0xbd, 0x05, 0x8f, 0xee, 0xf4, 0x33, 0xfb,
-	0xa9, 0x6d, 0x88, 0xd3, 0x28, 0xbb, 0x01, 0x6b, 0x38, 0xba, 0x57, 0xdc, 0xa0, 0xaa, 0x70, 0xa7,
-	0x2f, 0xf4, 0x57, 0xb4, 0xad, 0xa9, 0x6a, 0x71, 0xa5, 0xde, 0x01, 0x53, 0x55, 0x1b, 0xdd, 0x80,
-	0x33, 0x71, 0xe1, 0xe8, 0xe5, 0x65, 0x59, 0x9d, 0xe3, 0x40, 0x21, 0x74, 0x6d, 0x67, 0xb5, 0xdc,
-	0xeb, 0x4f, 0x9f, 0x5b, 0xa5, 0x67, 0xcf, 0xad, 0xd2, 0x8b, 0xe7, 0x96, 0xf1, 0xe3, 0xd8, 0x32,
-	0xfe, 0x18, 0x5b, 0xc6, 0xfe, 0xd8, 0x32, 0x9e, 0x8e, 0x2d, 0xe3, 0x9f, 0xb1, 0x65, 0xfc, 0x3b,
-	0xb6, 0x4a, 0x2f, 0xc6, 0x96, 0xf1, 0xcb, 0x81, 0x55, 0x7a, 0x7a, 0x60, 0x95, 0x9e, 0x1d, 0x58,
-	0xa5, 0xef, 0x8a, 0xff, 0x94, 0x77, 0x4d, 0x79, 0xab, 0xaf, 0xfe, 0x17, 0x00, 0x00, 0xff, 0xff,
-	0xc9, 0x8e, 0x9e, 0x8e, 0xb7, 0x0b, 0x00, 0x00,
+	// 1229 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x56, 0xcd, 0x6f, 0x1b, 0x45,
+	0x14, 0xf7, 0x66, 0xed, 0x8d, 0xf3, 0x9c, 0x26, 0x65, 0xd2, 0x8f, 0x4d, 0x29, 0xbb, 0x66, 0x05,
+	0x52, 0xf8, 0xa8, 0x23, 0x52, 0x01, 0x02, 0x44, 0x45, 0x16, 0x0a, 0x69, 0xa1, 0xb4, 0x9d, 0x54,
+	0x45, 0xe2, 0x52, 0x8d, 0xed, 0xa9, 0xb3, 0xc4, 0xfb, 0xd1, 0xd9, 0xd9, 0x26, 0xe1, 0xc4, 0x99,
+	0x03, 0xe2, 0x8c, 0xc4, 0x81, 0x1b, 0x7f, 0x4a, 0x8e, 0x3d, 0x56, 0x48, 0xac, 0xa8, 0x7b, 0x41,
+	0x7b, 0xea, 0x9f, 0x80, 0xe6, 0x63, 0xed, 0x4d, 0xe2, 0x24, 0xea, 0x89, 0xcb, 0x7a, 0xde, 0x7b,
+	0xbf, 0xf7, 0x39, 0xef, 0xbd, 0x31, 0xb4, 0x1e, 0x65, 0x94, 0xed, 0x75, 0x12, 0x16, 0xf3, 0x18,
+	0xb5, 0x38, 0x0b, 0x92, 0x84, 0xb2, 0x1d, 0xc2, 0xe8, 0xa5, 0x73, 0x83, 0x78, 0x10, 0x4b, 0xfe,
+	0xaa, 0x38, 0x29, 0xc8, 0x25, 0x67, 0x10, 0xc7, 0x83, 0x21, 0x5d, 0x95, 0x54, 0x37, 0x7b, 0xb8,
+	0xda, 0xcf, 0x18, 0xe1, 0x41, 0x1c, 0x69, 0xf9, 0xf2, 0x61, 0x39, 0x89, 0xb4, 0xf5, 0x4b, 0x1f,
+	0x0d, 0x02, 0xbe, 0x95, 0x75, 0x3b, 0xbd, 0x38, 0x5c, 0xed, 0xc5, 0x8c, 0xd3, 0xdd, 0x84, 0xc5,
+	0x3f, 0xd0, 0x1e, 0xd7, 0xd4, 0x6a, 0xb2, 0x3d, 0x28, 0x05, 0x5d, 0x7d, 0x50, 0xaa, 0xde, 0xcf,
+	0x26, 0xa0, 0x3b, 0x2c, 0x0e, 0x29, 0xdf, 0xa2, 0x59, 0x8a, 0x69, 0x9a, 0xc4, 0x51, 0x4a, 0x91,
+	0x07, 0xd6, 0x26, 0x27, 0x3c, 0x4b, 0x6d, 0xa3, 0x6d, 0xac, 0xcc, 0xf9, 0x50, 0xe4, 0xae, 0x95,
+	0x4a, 0x0e, 0xd6, 0x12, 0xf4, 0x15, 0xd4, 0xbf, 0x20, 0x9c, 0xd8, 0x33, 0x6d, 0x63, 0xa5, 0xb5,
+	0xf6, 0x6a, 0xa7, 0x92, 0x62, 0x67, 0x62, 0x52, 0x40, 0xfc, 0x0b, 0xfb, 0xb9, 0x5b, 0x2b, 0x72,
+	0x77, 0xa1, 0x4f, 0x38, 0x79, 0x37, 0x0e, 0x03, 0x4e, 0xc3, 0x84, 0xef, 0x61, 0x69, 0x00, 0xbd,
+	0x0f, 0x73, 0xd7, 0x19, 0x8b, 0xd9, 0xbd, 0xbd, 0x84, 0xda, 0xa6, 0xf4, 0x77, 0xb1, 0xc8, 0xdd,
+	0x25, 0x5a, 0x32, 0x2b, 0x1a, 0x13, 0x24, 0x7a, 0x0b, 0x1a, 0x92, 0xb0, 0xeb, 0x52, 0x65, 0xa9,
+	0xc8, 0xdd, 0x45, 0xa9, 0x52, 0x81, 0x2b, 0x04, 0xfa, 0x12, 0x66, 0x37, 0x28, 0xe9, 0x53, 0x96,
+	0xda, 0x8d, 0xb6, 0xb9, 0xd2, 0x5a, 0x7b, 0xf3, 0x98, 0x68, 0xcb, 0x02, 0x28, 0xb4, 0xdf, 0x28,
+	0x72, 0xd7, 0xb8, 0x82, 0x4b, 0x65, 0xb4, 0x06, 0xcd, 0xef, 0x08, 0x8b, 0x82, 0x68, 0x90, 0xda,
+	0x56, 0xdb, 0x5c, 0x99, 0xf3, 0x2f, 0x14, 0xb9, 0x8b, 0x76, 0x34, 0xaf, 0xe2, 0x78, 0x8c, 0x13,
+	0x61, 0xde, 0x88, 0x1e, 0xc6, 0xa9, 0x3d, 0xdb, 0x36, 0xcb, 0x30, 0x03, 0xc1, 0xa8, 0x86, 0x29,
+	0x11, 0xde, 0xdf, 0x06, 0x2c, 0x1c, 0xac, 0x1c, 0xea, 0x00, 0x60, 0x9a, 0x66, 0x43, 0x2e, 0x8b,
+	0xa3, 0x2e, 0x63, 0xa1, 0xc8, 0x5d, 0x60, 0x63, 0x2e, 0xae, 0x20, 0xd0, 0x4d, 0xb0, 0x14, 0xa5,
+	0xaf, 0xc5, 0x3b, 0x26, 0xd1, 0xbb, 0xa2, 0x39, 0x15, 0xd2, 0x5f, 0xd0, 0xb7, 0x63, 0x29, 0x9b,
+	0x58, 0x5b, 0x40, 0xb7, 0xa1, 0x21, 0xae, 0x3c, 0x95, 0x77, 0xd2, 0x5a, 0x7b, 0xe3, 0x94, 0x9a,
+	0x89, 0xb6, 0x48, 0x55, 0x7e, 0x52, 0xad, 0x9a, 0x9f, 0x64, 0x78, 0xdb, 0xb0, 0xf0, 0x39, 0xe9,
+	0x6d, 0xd1, 0xfe, 0xb8, 0xcf, 0x96, 0xc1, 0xdc, 0xa6, 0x7b, 0x3a, 0xaf, 0xd9, 0x22, 0x77, 0x05,
+	0x89, 0xc5, 0x07, 0x5d, 0x83, 0x59, 0xba, 0xcb, 0x69, 0xc4, 0x53, 0x7b, 0x46, 0xde, 0xd9, 0xd2,
+	0x01, 0xff, 0xd7, 0xa5, 0xcc, 0x5f, 0xd4, 0xb1, 0x97, 0x58, 0x5c, 0x1e, 0xbc, 0xa7, 0x06, 0x58,
+	0x0a, 0x84, 0x5c, 0x99, 0x08, 0xe3, 0xd2, 0x8f, 0xe9, 0xcf, 0x15, 0xb9, 0xab, 0x18, 0x58, 0xfd,
+	0x88, 0x30, 0x68, 0xd4, 0x97, 0x25, 0x33, 0x55, 0x18, 0x34, 0xea, 0x63, 0xf1, 0x41, 0x6d, 0x68,
+	0x72, 0x46, 0x7a, 0xf4, 0x41, 0xd0, 0xd7, 0x8d, 0x56, 0x36, 0x85, 0x64, 0xdf, 0xe8, 0xa3, 0x6b,
+	0xd0, 0x64, 0x3a, 0x1f, 0xbb, 0x21, 0x2b, 0x75, 0xae, 0xa3, 0x66, 0xb5, 0x53, 0xce, 0x6a, 0x67,
+	0x3d, 0xda, 0xf3, 0xe7, 0x8b, 0xdc, 0x1d, 0x23, 0xf1, 0xf8, 0x84, 0xde, 0x86, 0xb9, 0x9e, 0xac,
+	0xca, 0x03, 0xc2, 0x6d, 0x4b, 0x86, 0x70, 0xa6, 0xc8, 0xdd, 0x09, 0x13, 0x37, 0xd5, 0x71, 0x9d,
+	0xdf, 0xac, 0x37, 0xcd, 0xb3, 0x75, 0xef, 0xb7, 0x19, 0x98, 0xdf, 0x24, 0x61, 0x32, 0xa4, 0x9b,
+	0x9c, 0x51, 0x12, 0xa2, 0x5d, 0xb0, 0x86, 0xa4, 0x4b, 0x87, 0x62, 0x5c, 0x55, 0xa9, 0xca, 0x69,
+	0xef, 0x7c, 0x23, 0xf8, 0x77, 0x48, 0xc0, 0xfc, 0xaf, 0x45, 0xa9, 0xfe, 0xca, 0xdd, 0x97, 0xda,
+	0x16, 0x4a, 0x7f, 0xbd, 0x4f, 0x12, 0x4e, 0x99, 0xe8, 0x91, 0x90, 0x72, 0x16, 0xf4, 0xb0, 0xf6,
+	0x87, 0x3e, 0x86, 0xd9, 0x54, 0x46, 0x52, 0xde, 0xd2, 0xd9, 0x89, 0x6b, 0x15, 0xe2, 0xa4, 0xbd,
+	0x1e, 0x93, 0x61, 0x46, 0x53, 0x5c, 0x2a, 0xa0, 0x7b, 0x00, 0x5b, 0x41, 0xca, 0xe3, 0x01, 0x23,
+	0xa1, 0x68, 0x32, 0xa1, 0xde, 0x3e, 0x70, 0xc9, 0xca, 0xc2, 0x46, 0x09, 0x92, 0x69, 0x20, 0x6d,
+	0xae, 0xa2, 0x8b, 0x2b, 0x67, 0xef, 0x47, 0x58, 0x9a, 0xa2, 0x86, 0x5e, 0x87, 0x79, 0x1e, 0x84,
+	0x34, 0xe5, 0x24, 0x4c, 0x1e, 0x84, 0x6a, 0xaf, 0x99, 0xb8, 0x35, 0xe6, 0xdd, 0x4a, 0xd1, 0x67,
+	0x30, 0x37, 0xb6, 0xa3, 0xc7, 0xe7, 0xf2, 0x49, 0xe1, 0xf8, 0x75, 0x11, 0x0a, 0x9e, 0x28, 0x79,
+	0x8f, 0x60, 0xf1, 0x10, 0x06, 0x9d, 0x83, 0x46, 0x2f, 0xce, 0x22, 0xd5, 0x7b, 0x06, 0x56, 0x04,
+	0x3a, 0x0b, 0x66, 0x9a, 0x29, 0x27, 0x06, 0x16, 0x47, 0xf4, 0x01, 0xcc, 0x76, 0xb3, 0xde, 0x36,
+	0xe5, 0x65, 0x25, 0x0e, 0xba, 0x9e, 0x38, 0x95, 0x20, 0x5c, 0x82, 0xbd, 0x14, 0x16, 0x0f, 0xc9,
+	0x90, 0x03, 0xd0, 0x8d, 0xb3, 0xa8, 0x4f, 0x58, 0x40, 0x55, 0xa2, 0x0d, 0x5c, 0xe1, 0x88, 0x90,
+	0x86, 0xf1, 0x0e, 0x65, 0xda, 0xbd, 0x22, 0x04, 0x37, 0x13, 0xee, 0xe4, 0xb4, 0x1b, 0x58, 0x11,
+	0x93, 0xf0, 0xeb, 0x95, 0xf0, 0xbd, 0x10, 0x2e, 0x1e, 0x33, 0xff, 0x08, 0x4f, 0x1a, 0xc2, 0x90,
+	0x25, 0x7c, 0xe7, 0xb4, 0xb5, 0xa1, 0xd0, 0x6a, 0x7b, 0xb4, 0xc4, 0x28, 0x6b, 0xfd, 0x71, 0xa3,
+	0x78, 0xfb, 0x33, 0xe0, 0x9c, 0xac, 0x88, 0x6e, 0xc3, 0x79, 0x1e, 0x73, 0x32, 0x94, 0x7b, 0x8d,
+	0x74, 0x87, 0x74, 0xb3, 0x12, 0x84, 0xe9, 0x2f, 0x17, 0xb9, 0x3b, 0x1d, 0x80, 0xa7, 0xb3, 0xd1,
+	0x1f, 0x06, 0x5c, 0x9e, 0x2a, 0xb9, 0x43, 0xd9, 0x26, 0xa7, 0x89, 0x6e, 0xf7, 0x4f, 0x4e, 0xc9,
+	0xee, 0xb0, 0xb6, 0x8c, 0x56, 0x9b, 0xf0, 0xdb, 0x45, 0xee, 0x9e, 0xe8, 0x04, 0x9f, 0x28, 0x45,
+	0xef, 0x41, 0x2b, 0xa1, 0x64, 0xbb, 0x4c, 0xd5, 0x94, 0xa9, 0x2e, 0x16, 0xb9, 0x5b, 0x65, 0xe3,
+	0x2a, 0xe1, 0x05, 0xf0, 0x92, 0x41, 0x8a, 0x0e, 0x90, 0x83, 0xab, 0x27, 0x46, 0x11, 0x47, 0xc6,
+	0x69, 0xe6, 0xc8, 0x38, 0x79, 0xf7, 0xc0, 0x3e, 0xee, 0x61, 0x45, 0xcb, 0x50, 0xff, 0x96, 0x84,
+	0xe5, 0x83, 0xa6, 0x37, 0xaa, 0x64, 0xa1, 0xd7, 0xc0, 0xba, 0x2f, 0x5c, 0xa8, 0x85, 0x32, 0x16,
+	0x6a, 0xa6, 0xf7, 0xbb, 0x01, 0xe7, 0xa7, 0x3e, 0x63, 0xe8, 0x0a, 0x58, 0x8f, 0x69, 0x8f, 0xc7,
+	0x4c, 0x37, 0xde, 0xc1, 0xf7, 0xe2, 0xbe, 0x14, 0x6d, 0xd4, 0xb0, 0x06, 0xa1, 0xcb, 0xd0, 0x64,
+	0x64, 0xc7, 0xdf, 0xe3, 0x54, 0x45, 0x3f, 0xbf, 0x51, 0xc3, 0x63, 0x8e, 0x30, 0x16, 0x12, 0xce,
+	0x82, 0x5d, 0xdb, 0x9c, 0x62, 0xec, 0x96, 0x14, 0x09, 0x63, 0x0a, 0xe4, 0x37, 0x41, 0x3f, 0x9e,
+	0xde, 0xa7, 0x60, 0x29, 0x57, 0xe8, 0x6a, 0x75, 0x12, 0x8e, 0x3e, 0x60, 0x7a, 0x3b, 0xaa, 0x1d,
+	0x32, 0x6e, 0xf5, 0x5f, 0x66, 0xc0, 0x52, 0x92, 0xff, 0x71, 0xa9, 0x7f, 0x08, 0x96, 0x8a, 0x47,
+	0x6f, 0xc1, 0xa3, 0x3b, 0xfd, 0xcc, 0x7e, 0xee, 0x1a, 0xe2, 0x19, 0x95, 0xdd, 0x80, 0x35, 0x1c,
+	0xdd, 0xad, 0x6e, 0x50, 0x55, 0xb8, 0xd3, 0x17, 0xfa, 0x2b, 0xda, 0xd6, 0x44, 0xb5, 0xba, 0x52,
+	0x6f, 0x83, 0xa5, 0xaa, 0x8d, 0xae, 0xc3, 0x99, 0xb4, 0xf2, 0xe8, 0x95, 0x65, 0x59, 0x9e, 0xe2,
+	0x40, 0x21, 0x74, 0x6d, 0x0f, 0x6a, 0xf9, 0xeb, 0x4f, 0x9e, 0x39, 0xb5, 0xa7, 0xcf, 0x9c, 0xda,
+	0x8b, 0x67, 0x8e, 0xf1, 0xd3, 0xc8, 0x31, 0xfe, 0x1c, 0x39, 0xc6, 0xfe, 0xc8, 0x31, 0x9e, 0x8c,
+	0x1c, 0xe3, 0x9f, 0x91, 0x63, 0xfc, 0x3b, 0x72, 0x6a, 0x2f, 0x46, 0x8e, 0xf1, 0xeb, 0x73, 0xa7,
+	0xf6, 0xe4, 0xb9, 0x53, 0x7b, 0xfa, 0xdc, 0xa9, 0x7d, 0x5f, 0xfd, 0x03, 0xdf, 0xb5, 0xe4, 0xbb,
+	0x7e, 0xf5, 0xbf, 0x01, 0x00, 0xde, 0x9f, 0xef, 0x31, 0xe3, 0x0b, 0x00, 0x00,
 }
 
 func (this *PrometheusResponse) Equal(that interface{}) bool {
@@ -1223,6 +1233,9 @@ func (this *Extent) Equal(that interface{}) bool {
 	if !this.Response.Equal(that1.Response) {
 		return false
 	}
+	if this.CachedAt != that1.CachedAt {
+		return false
+	}
 	return true
 }
 func (this *SampleStream) Equal(that interface{}) bool {
@@ -1731,7 +1744,7 @@ func (this *Extent) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 8)
+	s := make([]string, 0, 9)
 	s = append(s, "&tripperware.Extent{")
 	s = append(s, "Start: "+fmt.Sprintf("%#v", this.Start)+",\n")
 	s = append(s, "End: "+fmt.Sprintf("%#v", this.End)+",\n")
@@ -1739,6 +1752,7 @@ func (this *Extent) GoString() string {
 	if this.Response != nil {
 		s = append(s, "Response: "+fmt.Sprintf("%#v", this.Response)+",\n")
 	}
+	s = append(s, "CachedAt: "+fmt.Sprintf("%#v", this.CachedAt)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -2146,6 +2160,11 @@ func (m *Extent) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.CachedAt != 0 {
+		i = encodeVarintQuery(dAtA, i, uint64(m.CachedAt))
+		i--
+		dAtA[i] = 0x30
+	}
 	if m.Response != nil {
 		{
 			size, err := m.Response.MarshalToSizedBuffer(dAtA[:i])
@@ -2862,6 +2881,9 @@ func (m *Extent) Size() (n int) {
 		l = m.Response.Size()
 		n += 1 + l + sovQuery(uint64(l))
 	}
+	if m.CachedAt != 0 {
+		n += 1 + sovQuery(uint64(m.CachedAt))
+	}
 	return n
 }
 
@@ -3181,6 +3203,7 @@ func (this *Extent) String() string {
 		`End:` + fmt.Sprintf("%v", this.End) + `,`,
 		`TraceId:` + fmt.Sprintf("%v", this.TraceId) + `,`,
 		`Response:` + strings.Replace(fmt.Sprintf("%v", this.Response), "Any", "types.Any", 1) + `,`,
+		`CachedAt:` + fmt.Sprintf("%v", this.CachedAt) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -4075,6 +4098,25 @@ func (m *Extent) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CachedAt", wireType)
+			}
+			m.CachedAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CachedAt |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -100,6 +100,13 @@ func matchAttributeForExpressionQuery(attribute validation.QueryAttribute, op st
 		}
 	}
 
+	if attribute.Query != "" {
+		matched = true
+		if attribute.Query != query {
+			return false
+		}
+	}
+
 	if attribute.TimeWindow.Start != 0 || attribute.TimeWindow.End != 0 {
 		matched = true
 		if !isWithinTimeAttributes(attribute.TimeWindow, now, minTime, maxTime) {
@@ -328,6 +328,32 @@ func Test_matchAttributeForExpressionQueryShouldMatchRegex(t *testing.T) {
 
 }
 
+func Test_matchAttributeForExpressionQueryShouldMatchQuery(t *testing.T) {
+	tests := map[string]struct {
+		query  string
+		result bool
+	}{
+		"should hit if query is equal": {
+			query:  `sum(rate(http_requests_total{job="api"}[5m]))`,
+			result: true,
+		},
+		"should miss if query is different": {
+			query: `sum(rate(http_requests_total{job="api"}[1m]))`,
+		},
+		"should miss if query only contains the configured one": {
+			query: `count(sum(rate(http_requests_total{job="api"}[5m])))`,
+		},
+	}
+
+	queryAttribute := validation.QueryAttribute{Query: `sum(rate(http_requests_total{job="api"}[5m]))`}
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			result := matchAttributeForExpressionQuery(queryAttribute, "query", &http.Request{}, testData.query, time.Time{}, 0, 0)
+			assert.Equal(t, testData.result, result)
+		})
+	}
+}
+
 func Test_isWithinTimeAttributes(t *testing.T) {
 	now := time.Now()
 
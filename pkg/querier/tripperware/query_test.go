@@ -1,19 +1,67 @@
 package tripperware
 
 import (
+	"bytes"
+	"compress/gzip"
+	"io"
 	"math"
+	"net/http"
 	"strconv"
 	"testing"
 	"time"
 
+	"github.com/go-kit/log"
+	"github.com/golang/snappy"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
 
 	"github.com/cortexproject/cortex/pkg/cortexpb"
 	"github.com/cortexproject/cortex/pkg/util"
 )
 
+func TestBodyBuffer_ShouldDecompressTheResponse(t *testing.T) {
+	body := []byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`)
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	_, err := gw.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	var snappied bytes.Buffer
+	sw := snappy.NewBufferedWriter(&snappied)
+	_, err = sw.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, sw.Close())
+
+	for encoding, encoded := range map[string][]byte{
+		"":       body,
+		"gzip":   gzipped.Bytes(),
+		"snappy": snappied.Bytes(),
+	} {
+		t.Run(encoding, func(t *testing.T) {
+			header := http.Header{}
+			if encoding != "" {
+				header.Set("Content-Encoding", encoding)
+			}
+
+			decoded, err := BodyBuffer(&http.Response{Header: header, Body: io.NopCloser(bytes.NewReader(encoded))}, log.NewNopLogger())
+			require.NoError(t, err)
+			require.Equal(t, body, decoded)
+
+			httpResp := &httpgrpc.HTTPResponse{Body: encoded}
+			for k, v := range header {
+				httpResp.Headers = append(httpResp.Headers, &httpgrpc.Header{Key: k, Values: v})
+			}
+			decoded, err = BodyBufferFromHTTPGRPCResponse(httpResp, log.NewNopLogger())
+			require.NoError(t, err)
+			require.Equal(t, body, decoded)
+		})
+	}
+}
+
 // Same as https://github.com/prometheus/client_golang/blob/v1.19.1/api/prometheus/v1/api_test.go#L1577.
 func TestSampleHistogramPairJSONSerialization(t *testing.T) {
 	tests := []struct {
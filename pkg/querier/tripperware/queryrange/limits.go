@@ -79,7 +79,18 @@ func (l limitsMiddleware) Do(ctx context.Context, r tripperware.Request) (trippe
 	if maxQueryLength > 0 {
 		queryLen := timestamp.Time(r.GetEnd()).Sub(timestamp.Time(r.GetStart()))
 		if queryLen > maxQueryLength {
-			return nil, httpgrpc.Errorf(http.StatusBadRequest, validation.ErrQueryTooLong, queryLen, maxQueryLength)
+			if !validation.AllTrueBooleansPerTenant(tenantIDs, l.ClampMaxQueryLength) {
+				return nil, httpgrpc.Errorf(http.StatusBadRequest, validation.ErrQueryTooLong, queryLen, maxQueryLength)
+			}
+
+			// Replace the start time in the request.
+			minStartTime := r.GetEnd() - maxQueryLength.Milliseconds()
+			level.Debug(log).Log(
+				"msg", "the start time of the query has been manipulated because of the 'max query length' setting",
+				"original", util.FormatTimeMillis(r.GetStart()),
+				"updated", util.FormatTimeMillis(minStartTime))
+
+			r = r.WithStartEnd(minStartTime, r.GetEnd())
 		}
 
 		expr, err := parser.ParseExpr(r.GetQuery())
@@ -94,5 +105,36 @@ func (l limitsMiddleware) Do(ctx context.Context, r tripperware.Request) (trippe
 		}
 	}
 
+	// Enforce the max points per series.
+	if maxPoints := validation.SmallestPositiveIntPerTenant(tenantIDs, l.MaxPointsPerSeries); maxPoints > 0 && r.GetStep() > 0 {
+		points := (r.GetEnd()-r.GetStart())/r.GetStep() + 1
+		if points > int64(maxPoints) {
+			if !validation.AllTrueBooleansPerTenant(tenantIDs, l.AdjustStepToMaxPoints) {
+				return nil, httpgrpc.Errorf(http.StatusBadRequest, validation.ErrTooManyPointsPerSeries, points, maxPoints)
+			}
+
+			// Replace the step in the request with the smallest whole number
+			// of seconds fitting the time range in the max points.
+			step := stepForMaxPoints(r.GetEnd()-r.GetStart(), maxPoints)
+			level.Debug(log).Log(
+				"msg", "the step of the query has been manipulated because of the 'max points per series' setting",
+				"original", r.GetStep(),
+				"updated", step)
+
+			r = r.WithStep(step)
+		}
+	}
+
 	return l.next.Do(ctx, r)
 }
+
+// stepForMaxPoints returns the smallest step in milliseconds, rounded up to the second,
+// giving at most maxPoints points per series over the given time range in milliseconds.
+func stepForMaxPoints(rangeMs int64, maxPoints int) int64 {
+	if maxPoints == 1 {
+		// A step longer than the time range gives a single point.
+		return (rangeMs/1000 + 1) * 1000
+	}
+	step := (rangeMs + int64(maxPoints) - 2) / int64(maxPoints-1)
+	return (step + 999) / 1000 * 1000
+}
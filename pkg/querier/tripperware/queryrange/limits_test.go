@@ -122,11 +122,13 @@ func TestLimitsMiddleware_MaxQueryLength(t *testing.T) {
 	_, parserErr := parser.ParseExpr(wrongQuery)
 
 	tests := map[string]struct {
-		maxQueryLength time.Duration
-		query          string
-		reqStartTime   time.Time
-		reqEndTime     time.Time
-		expectedErr    string
+		maxQueryLength      time.Duration
+		clampMaxQueryLength bool
+		query               string
+		reqStartTime        time.Time
+		reqEndTime          time.Time
+		expectedErr         string
+		expectedStartTime   time.Time
 	}{
 		"should skip validation if max length is disabled": {
 			maxQueryLength: 0,
@@ -181,6 +183,21 @@ func TestLimitsMiddleware_MaxQueryLength(t *testing.T) {
 			reqEndTime:     now.Add(-2 * thirtyDays),
 			expectedErr:    "the query time range exceeds the limit",
 		},
+		"should clamp a query on large time range over the limit, ending in the past, if clamping is enabled": {
+			maxQueryLength:      thirtyDays,
+			clampMaxQueryLength: true,
+			reqStartTime:        now.Add(-4 * thirtyDays),
+			reqEndTime:          now.Add(-2 * thirtyDays),
+			expectedStartTime:   now.Add(-3 * thirtyDays),
+		},
+		"should fail on query with time window > max query length even if clamping is enabled": {
+			query:               "up[31d]",
+			maxQueryLength:      thirtyDays,
+			clampMaxQueryLength: true,
+			reqStartTime:        now.Add(-time.Hour),
+			reqEndTime:          now,
+			expectedErr:         "the query time range exceeds the limit",
+		},
 		"shouldn't exceed time range when having multiple selects with offset": {
 			query:          `rate(up[5m]) + rate(up[5m] offset 40d) + rate(up[5m] offset 80d)`,
 			maxQueryLength: thirtyDays,
@@ -202,7 +219,7 @@ func TestLimitsMiddleware_MaxQueryLength(t *testing.T) {
 				req.Query = "up"
 			}
 
-			limits := mockLimits{maxQueryLength: testData.maxQueryLength}
+			limits := mockLimits{maxQueryLength: testData.maxQueryLength, clampMaxQueryLength: testData.clampMaxQueryLength}
 			middleware := NewLimitsMiddleware(limits, 5*time.Minute)
 
 			innerRes := tripperware.NewEmptyPrometheusResponse(false)
@@ -223,19 +240,111 @@ func TestLimitsMiddleware_MaxQueryLength(t *testing.T) {
 				require.NoError(t, err)
 				assert.Same(t, innerRes, res)
 
-				// The time range of the request passed to the inner handler should have not been manipulated.
+				// The time range of the request passed to the inner handler should have not been manipulated,
+				// unless it has been clamped.
+				expectedStartTime := testData.reqStartTime
+				if !testData.expectedStartTime.IsZero() {
+					expectedStartTime = testData.expectedStartTime
+				}
 				require.Len(t, inner.Calls, 1)
-				assert.Equal(t, util.TimeToMillis(testData.reqStartTime), inner.Calls[0].Arguments.Get(1).(tripperware.Request).GetStart())
+				assert.Equal(t, util.TimeToMillis(expectedStartTime), inner.Calls[0].Arguments.Get(1).(tripperware.Request).GetStart())
 				assert.Equal(t, util.TimeToMillis(testData.reqEndTime), inner.Calls[0].Arguments.Get(1).(tripperware.Request).GetEnd())
 			}
 		})
 	}
 }
 
+func TestLimitsMiddleware_MaxPointsPerSeries(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		maxPointsPerSeries int
+		adjustStep         bool
+		step               time.Duration
+		expectedErr        string
+		expectedStep       time.Duration
+	}{
+		"should skip validation if the limit is disabled": {
+			step:         time.Second,
+			expectedStep: time.Second,
+		},
+		"should succeed on a query within the limit": {
+			maxPointsPerSeries: 11000,
+			step:               time.Minute,
+			expectedStep:       time.Minute,
+		},
+		"should succeed on a query exactly at the limit": {
+			maxPointsPerSeries: 25,
+			step:               time.Hour,
+			expectedStep:       time.Hour,
+		},
+		"should fail on a query over the limit": {
+			maxPointsPerSeries: 11000,
+			step:               time.Second,
+			expectedErr:        "the query resolution exceeds the limit of points per series, try increasing the step (points: 86401, limit: 11000)",
+		},
+		"should adjust the step of a query over the limit if enabled": {
+			maxPointsPerSeries: 11000,
+			adjustStep:         true,
+			step:               time.Second,
+			expectedStep:       8 * time.Second,
+		},
+		"should adjust the step of a query over a limit of a single point if enabled": {
+			maxPointsPerSeries: 1,
+			adjustStep:         true,
+			step:               time.Hour,
+			expectedStep:       24*time.Hour + time.Second,
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+			req := &tripperware.PrometheusRequest{
+				Query: "up",
+				Start: 0,
+				End:   (24 * time.Hour).Milliseconds(),
+				Step:  testData.step.Milliseconds(),
+			}
+
+			limits := mockLimits{maxPointsPerSeries: testData.maxPointsPerSeries, adjustStep: testData.adjustStep}
+			middleware := NewLimitsMiddleware(limits, 5*time.Minute)
+
+			innerRes := tripperware.NewEmptyPrometheusResponse(false)
+			inner := &mockHandler{}
+			inner.On("Do", mock.Anything, mock.Anything).Return(innerRes, nil)
+
+			ctx := user.InjectOrgID(context.Background(), "test")
+			res, err := middleware.Wrap(inner).Do(ctx, req)
+
+			if testData.expectedErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, httpgrpc.Errorf(http.StatusBadRequest, testData.expectedErr), err)
+				assert.Nil(t, res)
+				assert.Len(t, inner.Calls, 0)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Same(t, innerRes, res)
+			require.Len(t, inner.Calls, 1)
+			assert.Equal(t, testData.expectedStep.Milliseconds(), inner.Calls[0].Arguments.Get(1).(tripperware.Request).GetStep())
+		})
+	}
+}
+
 type mockLimits struct {
-	maxQueryLookback  time.Duration
-	maxQueryLength    time.Duration
-	maxCacheFreshness time.Duration
+	maxQueryLookback     time.Duration
+	maxQueryLength       time.Duration
+	clampMaxQueryLength  bool
+	maxPointsPerSeries   int
+	adjustStep           bool
+	maxCacheFreshness    time.Duration
+	resultsCacheTTL      time.Duration
+	alignQueriesWithStep bool
+	splitQueriesBy       time.Duration
+	unalignedQueryTTL    time.Duration
 }
 
 func (m mockLimits) MaxQueryLookback(string) time.Duration {
@@ -246,6 +355,18 @@ func (m mockLimits) MaxQueryLength(string) time.Duration {
 	return m.maxQueryLength
 }
 
+func (m mockLimits) ClampMaxQueryLength(string) bool {
+	return m.clampMaxQueryLength
+}
+
+func (m mockLimits) MaxPointsPerSeries(string) int {
+	return m.maxPointsPerSeries
+}
+
+func (m mockLimits) AdjustStepToMaxPoints(string) bool {
+	return m.adjustStep
+}
+
 func (mockLimits) MaxQueryParallelism(string) int {
 	return 14 // Flag default.
 }
@@ -254,10 +375,38 @@ func (m mockLimits) MaxCacheFreshness(string) time.Duration {
 	return m.maxCacheFreshness
 }
 
+func (m mockLimits) ResultsCacheTTL(string) time.Duration {
+	return m.resultsCacheTTL
+}
+
+func (m mockLimits) AlignQueriesWithStep(string) bool {
+	return m.alignQueriesWithStep
+}
+
+func (m mockLimits) MetadataCacheTTL(string) time.Duration {
+	return 0
+}
+
+func (m mockLimits) InstantQueryCacheTTL(string) time.Duration {
+	return 0
+}
+
+func (m mockLimits) UnalignedQueryCacheTTL(string) time.Duration {
+	return m.unalignedQueryTTL
+}
+
 func (m mockLimits) QueryVerticalShardSize(userID string) int {
 	return 0
 }
 
+func (m mockLimits) SplitInstantQueriesByInterval(userID string) time.Duration {
+	return 0
+}
+
+func (m mockLimits) SplitQueriesByInterval(userID string) time.Duration {
+	return m.splitQueriesBy
+}
+
 func (m mockLimits) QueryPriority(userID string) validation.QueryPriority {
 	return validation.QueryPriority{}
 }
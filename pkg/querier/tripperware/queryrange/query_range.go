@@ -66,6 +66,8 @@ func NewPrometheusCodec(sharded bool, compressionStr string, defaultCodecTypeStr
 	compression := tripperware.NonCompression // default
 	if compressionStr == string(tripperware.GzipCompression) {
 		compression = tripperware.GzipCompression
+	} else if compressionStr == string(tripperware.SnappyCompression) {
+		compression = tripperware.SnappyCompression
 	}
 
 	defaultCodecType := tripperware.JsonCodecType // default
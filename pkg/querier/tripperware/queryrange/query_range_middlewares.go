@@ -37,8 +37,10 @@ type Config struct {
 	SplitQueriesByInterval time.Duration `yaml:"split_queries_by_interval"`
 	AlignQueriesWithStep   bool          `yaml:"align_queries_with_step"`
 	ResultsCacheConfig     `yaml:"results_cache"`
-	CacheResults           bool `yaml:"cache_results"`
-	MaxRetries             int  `yaml:"max_retries"`
+	CacheResults           bool                            `yaml:"cache_results"`
+	MetadataCacheConfig    tripperware.MetadataCacheConfig `yaml:"metadata_cache"`
+	CacheMetadata          bool                            `yaml:"cache_metadata"`
+	MaxRetries             int                             `yaml:"max_retries"`
 	// List of headers which query_range middleware chain would forward to downstream querier.
 	ForwardHeaders flagext.StringSlice `yaml:"forward_headers_list"`
 
@@ -49,11 +51,13 @@ type Config struct {
 // RegisterFlags adds the flags required to config this to the given FlagSet.
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.MaxRetries, "querier.max-retries-per-request", 5, "Maximum number of retries for a single request; beyond this, the downstream error is returned.")
-	f.DurationVar(&cfg.SplitQueriesByInterval, "querier.split-queries-by-interval", 0, "Split queries by an interval and execute in parallel, 0 disables it. You should use a multiple of 24 hours (same as the storage bucketing scheme), to avoid queriers downloading and processing the same chunks. This also determines how cache keys are chosen when result caching is enabled")
-	f.BoolVar(&cfg.AlignQueriesWithStep, "querier.align-querier-with-step", false, "Mutate incoming queries to align their start and end with their step.")
+	f.DurationVar(&cfg.SplitQueriesByInterval, "querier.split-queries-by-interval", 0, "Split queries by an interval and execute in parallel, 0 disables it. You should use a multiple of 24 hours (same as the storage bucketing scheme), to avoid queriers downloading and processing the same chunks. This also determines how cache keys are chosen when result caching is enabled. It can be overridden per-tenant with -frontend.split-queries-by-interval.")
+	f.BoolVar(&cfg.AlignQueriesWithStep, "querier.align-querier-with-step", false, "Mutate incoming queries to align their start and end with their step. When enabled, it's the default of the per-tenant -frontend.align-queries-with-step, so that tenants can opt out with align_queries_with_step set to false.")
 	f.BoolVar(&cfg.CacheResults, "querier.cache-results", false, "Cache query results.")
 	f.Var(&cfg.ForwardHeaders, "frontend.forward-headers-list", "List of headers forwarded by the query Frontend to downstream querier.")
+	f.BoolVar(&cfg.CacheMetadata, "frontend.cache-metadata", false, "[Experimental] Cache the responses of the label names, label values and series APIs, for the per-tenant -frontend.metadata-cache-ttl.")
 	cfg.ResultsCacheConfig.RegisterFlags(f)
+	cfg.MetadataCacheConfig.RegisterFlags(f)
 }
 
 // Validate validates the config.
@@ -66,6 +70,11 @@ func (cfg *Config) Validate(qCfg querier.Config) error {
 			return errors.Wrap(err, "invalid ResultsCache config")
 		}
 	}
+	if cfg.CacheMetadata {
+		if err := cfg.MetadataCacheConfig.Validate(); err != nil {
+			return errors.Wrap(err, "invalid MetadataCache config")
+		}
+	}
 	return nil
 }
 
@@ -84,16 +93,10 @@ func Middlewares(
 	// Metric used to keep track of each middleware execution duration.
 	metrics := tripperware.NewInstrumentMiddlewareMetrics(registerer)
 
-	queryRangeMiddleware := []tripperware.Middleware{NewLimitsMiddleware(limits, lookbackDelta)}
-	if cfg.AlignQueriesWithStep {
-		queryRangeMiddleware = append(queryRangeMiddleware, tripperware.InstrumentMiddleware("step_align", metrics), StepAlignMiddleware)
-	}
-	if cfg.SplitQueriesByInterval != 0 {
-		staticIntervalFn := func(_ tripperware.Request) time.Duration { return cfg.SplitQueriesByInterval }
-		queryRangeMiddleware = append(queryRangeMiddleware, tripperware.InstrumentMiddleware("split_by_interval", metrics), SplitByIntervalMiddleware(staticIntervalFn, limits, prometheusCodec, registerer))
-	}
-
-	var c cache.Cache
+	var (
+		c                    cache.Cache
+		queryCacheMiddleware tripperware.Middleware
+	)
 	if cfg.CacheResults {
 		shouldCache := func(r tripperware.Request) bool {
 			if v, ok := r.(*tripperware.PrometheusRequest); ok {
@@ -101,11 +104,23 @@ func Middlewares(
 			}
 			return false
 		}
-		queryCacheMiddleware, cache, err := NewResultsCacheMiddleware(log, cfg.ResultsCacheConfig, constSplitter(cfg.SplitQueriesByInterval), limits, prometheusCodec, cacheExtractor, shouldCache, registerer)
+		var err error
+		queryCacheMiddleware, c, err = NewResultsCacheMiddleware(log, cfg.ResultsCacheConfig, tenantsSplitter{interval: cfg.SplitQueriesByInterval, limits: limits}, limits, prometheusCodec, cacheExtractor, shouldCache, registerer)
 		if err != nil {
 			return nil, nil, err
 		}
-		c = cache
+	}
+
+	queryRangeMiddleware := []tripperware.Middleware{NewLimitsMiddleware(limits, lookbackDelta)}
+	queryRangeMiddleware = append(queryRangeMiddleware, tripperware.InstrumentMiddleware("step_align", metrics), NewStepAlignMiddleware(limits))
+	if c != nil {
+		// The whole responses of the requests still not aligned are cached before being split.
+		queryRangeMiddleware = append(queryRangeMiddleware, tripperware.InstrumentMiddleware("unaligned_results_cache", metrics), tripperware.NewBucketedResultsCacheMiddleware(c, limits.UnalignedQueryCacheTTL, tripperware.UnalignedQueryBucketCacheKey, log))
+	}
+	if cfg.SplitQueriesByInterval != 0 {
+		queryRangeMiddleware = append(queryRangeMiddleware, tripperware.InstrumentMiddleware("split_by_interval", metrics), SplitByIntervalMiddleware(tenantsIntervalFn(cfg.SplitQueriesByInterval, limits), limits, prometheusCodec, registerer))
+	}
+	if queryCacheMiddleware != nil {
 		queryRangeMiddleware = append(queryRangeMiddleware, tripperware.InstrumentMiddleware("results_cache", metrics), queryCacheMiddleware)
 	}
 
@@ -151,6 +151,21 @@ func (t constSplitter) GenerateCacheKey(userID string, r tripperware.Request) st
 	return fmt.Sprintf("%s:%s:%d:%d", userID, r.GetQuery(), r.GetStep(), currentInterval)
 }
 
+// tenantsSplitter uses the split interval of the tenants, or the default one, when determining cache keys.
+type tenantsSplitter struct {
+	interval time.Duration
+	limits   tripperware.Limits
+}
+
+// GenerateCacheKey generates a cache key based on the userID, Request and the tenants split interval.
+func (t tenantsSplitter) GenerateCacheKey(userID string, r tripperware.Request) string {
+	interval := t.interval
+	if tenantIDs, err := tenant.TenantIDsFromOrgID(userID); err == nil {
+		interval = tenantsSplitInterval(tenantIDs, t.interval, t.limits)
+	}
+	return constSplitter(interval).GenerateCacheKey(userID, r)
+}
+
 // ShouldCacheFn checks whether the current request should go to cache
 // or not. If not, just send the request to next handler.
 type ShouldCacheFn func(r tripperware.Request) bool
@@ -244,6 +259,11 @@ func (s resultsCache) Do(ctx context.Context, r tripperware.Request) (tripperwar
 	}
 
 	cached, ok := s.get(ctx, key)
+	if ok {
+		resultsCacheTTL := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, s.limits.ResultsCacheTTL)
+		cached = filterExpiredExtents(cached, resultsCacheTTL)
+		ok = len(cached) > 0
+	}
 	if ok {
 		response, extents, err = s.handleHit(ctx, r, cached, maxCacheTime)
 	} else {
@@ -289,6 +309,11 @@ func (s resultsCache) shouldCacheResponse(ctx context.Context, req tripperware.R
 		}
 	}
 
+	if tripperware.HasPartialResults(r) {
+		level.Debug(util_log.WithContext(ctx, s.logger)).Log("msg", "response holds partial results, not caching the response")
+		return false
+	}
+
 	if !s.isAtModifierCachable(ctx, req, maxCacheTime) {
 		return false
 	}
@@ -497,6 +522,9 @@ func (s resultsCache) handleHit(ctx context.Context, r tripperware.Request, exte
 
 		accumulator.TraceId = jaegerTraceID(ctx)
 		accumulator.End = extents[i].End
+		if extents[i].CachedAt < accumulator.CachedAt {
+			accumulator.CachedAt = extents[i].CachedAt
+		}
 		currentRes, err := extentToResponse(extents[i])
 		if err != nil {
 			return nil, nil, err
@@ -532,6 +560,7 @@ func merge(extents []tripperware.Extent, acc *accumulator) ([]tripperware.Extent
 		End:      acc.Extent.End,
 		Response: any,
 		TraceId:  acc.Extent.TraceId,
+		CachedAt: acc.Extent.CachedAt,
 	}), nil
 }
 
@@ -556,6 +585,7 @@ func toExtent(ctx context.Context, req tripperware.Request, res tripperware.Resp
 		End:      req.GetEnd(),
 		Response: any,
 		TraceId:  jaegerTraceID(ctx),
+		CachedAt: int64(model.Now()),
 	}, nil
 }
 
@@ -702,6 +732,25 @@ func (s resultsCache) filterRecentExtents(req tripperware.Request, maxCacheFresh
 	return extents, nil
 }
 
+// filterExpiredExtents removes the extents holding results fetched more than the TTL ago.
+// Extents cached before their fetch time was tracked are considered expired. The TTL is
+// only enforced on read: expired extents are replaced in the cache once fetched again, while
+// the expiration of the cache entries is left to the cache backend.
+func filterExpiredExtents(extents []tripperware.Extent, ttl time.Duration) []tripperware.Extent {
+	if ttl <= 0 {
+		return extents
+	}
+
+	minCachedAt := int64(model.Now().Add(-ttl))
+	filtered := extents[:0]
+	for _, extent := range extents {
+		if extent.CachedAt >= minCachedAt {
+			filtered = append(filtered, extent)
+		}
+	}
+	return filtered
+}
+
 func (s resultsCache) get(ctx context.Context, key string) ([]tripperware.Extent, bool) {
 	found, bufs, _ := s.cache.Fetch(ctx, []string{cache.HashKey(key)})
 	if len(found) != 1 {
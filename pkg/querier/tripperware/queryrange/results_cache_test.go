@@ -1213,11 +1213,78 @@ func TestHandleHit(t *testing.T) {
 
 			expectedResponse := mkAPIResponse(tc.input.GetStart(), tc.input.GetEnd(), tc.input.GetStep())
 			require.Equal(t, expectedResponse, response, "response does not match the expectation")
+
+			// The extents holding newly fetched results are marked with the time they have been fetched.
+			for i := range updatedExtents {
+				require.True(t, updatedExtents[i].CachedAt == 0 || updatedExtents[i].CachedAt > int64(model.Now().Add(-time.Minute)))
+				updatedExtents[i].CachedAt = 0
+			}
 			require.Equal(t, tc.expectedUpdatedCachedEntry, updatedExtents, "updated cache entry does not match the expectation")
 		})
 	}
 }
 
+func TestHandleHit_ShouldKeepTheOldestCachedAtOfMergedExtents(t *testing.T) {
+	t.Parallel()
+	sut := resultsCache{
+		extractor:      PrometheusResponseExtractor{},
+		minCacheExtent: 10,
+		limits:         mockLimits{},
+		merger:         PrometheusCodec,
+		next: tripperware.HandlerFunc(func(_ context.Context, req tripperware.Request) (tripperware.Response, error) {
+			return mkAPIResponse(req.GetStart(), req.GetEnd(), req.GetStep()), nil
+		}),
+	}
+
+	oldest := int64(model.Now().Add(-2 * time.Hour))
+	cached := []tripperware.Extent{mkExtentWithStep(60, 160, 20)}
+	cached[0].CachedAt = oldest
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	_, updatedExtents, err := sut.handleHit(ctx, &tripperware.PrometheusRequest{Start: 100, End: 180, Step: 20}, cached, 0)
+	require.NoError(t, err)
+	require.Len(t, updatedExtents, 1)
+	require.Equal(t, oldest, updatedExtents[0].CachedAt)
+}
+
+func TestFilterExpiredExtents(t *testing.T) {
+	t.Parallel()
+	now := model.Now()
+	extent := func(start int64, cachedAt model.Time) tripperware.Extent {
+		e := mkExtent(start, start+10)
+		e.CachedAt = int64(cachedAt)
+		return e
+	}
+
+	for name, tc := range map[string]struct {
+		ttl      time.Duration
+		extents  []tripperware.Extent
+		expected []tripperware.Extent
+	}{
+		"should keep all the extents if the TTL is disabled": {
+			ttl:      0,
+			extents:  []tripperware.Extent{extent(0, 0), extent(20, now.Add(-48*time.Hour))},
+			expected: []tripperware.Extent{extent(0, 0), extent(20, now.Add(-48*time.Hour))},
+		},
+		"should remove the extents cached before the TTL": {
+			ttl:      time.Hour,
+			extents:  []tripperware.Extent{extent(0, now.Add(-2*time.Hour)), extent(20, now.Add(-time.Minute)), extent(40, now)},
+			expected: []tripperware.Extent{extent(20, now.Add(-time.Minute)), extent(40, now)},
+		},
+		"should remove the extents cached without the fetch time": {
+			ttl:      time.Hour,
+			extents:  []tripperware.Extent{extent(0, 0), extent(20, now)},
+			expected: []tripperware.Extent{extent(20, now)},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.expected, filterExpiredExtents(tc.extents, tc.ttl))
+		})
+	}
+}
+
 func TestResultsCache(t *testing.T) {
 	t.Parallel()
 	calls := 0
@@ -1438,6 +1505,14 @@ func TestConstSplitter_generateCacheKey(t *testing.T) {
 	}
 }
 
+func TestTenantsSplitter_generateCacheKey(t *testing.T) {
+	t.Parallel()
+	r := &tripperware.PrometheusRequest{Start: toMs(77 * time.Hour), Step: 10, Query: "foo{}"}
+
+	require.Equal(t, "fake:foo{}:10:3", tenantsSplitter{interval: day, limits: mockLimits{}}.GenerateCacheKey("fake", r))
+	require.Equal(t, "fake:foo{}:10:77", tenantsSplitter{interval: day, limits: mockLimits{splitQueriesBy: time.Hour}}.GenerateCacheKey("fake", r))
+}
+
 func TestResultsCacheShouldCacheFunc(t *testing.T) {
 	t.Parallel()
 	testcases := []struct {
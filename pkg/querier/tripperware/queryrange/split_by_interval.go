@@ -11,9 +11,30 @@ import (
 	"github.com/weaveworks/common/httpgrpc"
 
 	"github.com/cortexproject/cortex/pkg/querier/tripperware"
+	"github.com/cortexproject/cortex/pkg/tenant"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
-type IntervalFn func(r tripperware.Request) time.Duration
+type IntervalFn func(ctx context.Context, r tripperware.Request) time.Duration
+
+// tenantsIntervalFn returns the IntervalFn of the smallest split interval of the request tenants,
+// or the default interval if none of them overrides it.
+func tenantsIntervalFn(defaultInterval time.Duration, limits tripperware.Limits) IntervalFn {
+	return func(ctx context.Context, _ tripperware.Request) time.Duration {
+		tenantIDs, err := tenant.TenantIDs(ctx)
+		if err != nil {
+			return defaultInterval
+		}
+		return tenantsSplitInterval(tenantIDs, defaultInterval, limits)
+	}
+}
+
+func tenantsSplitInterval(tenantIDs []string, defaultInterval time.Duration, limits tripperware.Limits) time.Duration {
+	if interval := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, limits.SplitQueriesByInterval); interval > 0 {
+		return interval
+	}
+	return defaultInterval
+}
 
 // SplitByIntervalMiddleware creates a new Middleware that splits requests by a given interval.
 func SplitByIntervalMiddleware(interval IntervalFn, limits tripperware.Limits, merger tripperware.Merger, registerer prometheus.Registerer) tripperware.Middleware {
@@ -45,7 +66,7 @@ type splitByInterval struct {
 func (s splitByInterval) Do(ctx context.Context, r tripperware.Request) (tripperware.Response, error) {
 	// First we're going to build new requests, one for each day, taking care
 	// to line up the boundaries with step.
-	reqs, err := splitQuery(r, s.interval(r))
+	reqs, err := splitQuery(r, s.interval(ctx, r))
 	if err != nil {
 		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
 	}
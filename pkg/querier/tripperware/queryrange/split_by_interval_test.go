@@ -305,7 +305,7 @@ func TestSplitByDay(t *testing.T) {
 			u, err := url.Parse(s.URL)
 			require.NoError(t, err)
 
-			interval := func(_ tripperware.Request) time.Duration { return 24 * time.Hour }
+			interval := func(_ context.Context, _ tripperware.Request) time.Duration { return 24 * time.Hour }
 			roundtripper := tripperware.NewRoundTripper(singleHostRoundTripper{
 				host: u.Host,
 				next: http.DefaultTransport,
@@ -329,6 +329,18 @@ func TestSplitByDay(t *testing.T) {
 	}
 }
 
+func TestTenantsIntervalFn(t *testing.T) {
+	t.Parallel()
+	req := &tripperware.PrometheusRequest{}
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	require.Equal(t, day, tenantsIntervalFn(day, mockLimits{})(ctx, req))
+	require.Equal(t, 7*day, tenantsIntervalFn(day, mockLimits{splitQueriesBy: 7 * day})(ctx, req))
+	require.Equal(t, time.Hour, tenantsIntervalFn(day, mockLimits{splitQueriesBy: time.Hour})(user.InjectOrgID(context.Background(), "1|2"), req))
+	// Without tenant, the request is rejected by the limits middleware, before being split.
+	require.Equal(t, day, tenantsIntervalFn(day, mockLimits{splitQueriesBy: 7 * day})(context.Background(), req))
+}
+
 func Test_evaluateAtModifier(t *testing.T) {
 	t.Parallel()
 	const (
@@ -2,8 +2,12 @@ package queryrange
 
 import (
 	"context"
+	"net/http"
+
+	"github.com/weaveworks/common/httpgrpc"
 
 	"github.com/cortexproject/cortex/pkg/querier/tripperware"
+	"github.com/cortexproject/cortex/pkg/tenant"
 )
 
 // StepAlignMiddleware aligns the start and end of request to the step to
@@ -14,11 +18,36 @@ var StepAlignMiddleware = tripperware.MiddlewareFunc(func(next tripperware.Handl
 	}
 })
 
+// NewStepAlignMiddleware aligns the start and end of request to the step, when
+// enabled for all the tenants of the request by -frontend.align-queries-with-step.
+func NewStepAlignMiddleware(limits tripperware.Limits) tripperware.Middleware {
+	return tripperware.MiddlewareFunc(func(next tripperware.Handler) tripperware.Handler {
+		return stepAlign{
+			next:   next,
+			limits: limits,
+		}
+	})
+}
+
 type stepAlign struct {
 	next tripperware.Handler
+	// limits is nil when all the requests are aligned.
+	limits tripperware.Limits
 }
 
 func (s stepAlign) Do(ctx context.Context, r tripperware.Request) (tripperware.Response, error) {
+	if s.limits != nil {
+		tenantIDs, err := tenant.TenantIDs(ctx)
+		if err != nil {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+		}
+		for _, tenantID := range tenantIDs {
+			if !s.limits.AlignQueriesWithStep(tenantID) {
+				return s.next.Do(ctx, r)
+			}
+		}
+	}
+
 	start := (r.GetStart() / r.GetStep()) * r.GetStep()
 	end := (r.GetEnd() / r.GetStep()) * r.GetStep()
 	return s.next.Do(ctx, r.WithStartEnd(start, end))
@@ -6,8 +6,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
 
 	"github.com/cortexproject/cortex/pkg/querier/tripperware"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
 func TestStepAlign(t *testing.T) {
@@ -56,3 +59,77 @@ func TestStepAlign(t *testing.T) {
 		})
 	}
 }
+
+func TestStepAlign_PerTenant(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		alignQueriesWithStep bool
+		expected             *tripperware.PrometheusRequest
+	}{
+		"enabled for the tenant": {
+			alignQueriesWithStep: true,
+			expected:             &tripperware.PrometheusRequest{Start: 0, End: 100, Step: 10},
+		},
+		"disabled for the tenant": {
+			alignQueriesWithStep: false,
+			expected:             &tripperware.PrometheusRequest{Start: 2, End: 102, Step: 10},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var result *tripperware.PrometheusRequest
+			next := tripperware.HandlerFunc(func(_ context.Context, req tripperware.Request) (tripperware.Response, error) {
+				result = req.(*tripperware.PrometheusRequest)
+				return nil, nil
+			})
+			s := NewStepAlignMiddleware(mockLimits{alignQueriesWithStep: tc.alignQueriesWithStep}).Wrap(next)
+
+			_, err := s.Do(user.InjectOrgID(context.Background(), "user-1"), &tripperware.PrometheusRequest{Start: 2, End: 102, Step: 10})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestStepAlign_TenantOptingOutOfGlobalAlignment(t *testing.T) {
+	t.Parallel()
+
+	// The global -querier.align-querier-with-step is the default of the per-tenant limit.
+	defaults := validation.Limits{}
+	flagext.DefaultValues(&defaults)
+	defaults.AlignQueriesWithStep = true
+
+	optOut := defaults
+	optOut.AlignQueriesWithStep = false
+
+	overrides, err := validation.NewOverrides(defaults, tenantLimits{"opt-out": &optOut})
+	require.NoError(t, err)
+
+	for tenantID, expected := range map[string]*tripperware.PrometheusRequest{
+		"user-1":  {Start: 0, End: 100, Step: 10},
+		"opt-out": {Start: 2, End: 102, Step: 10},
+	} {
+		var result *tripperware.PrometheusRequest
+		next := tripperware.HandlerFunc(func(_ context.Context, req tripperware.Request) (tripperware.Response, error) {
+			result = req.(*tripperware.PrometheusRequest)
+			return nil, nil
+		})
+		s := NewStepAlignMiddleware(overrides).Wrap(next)
+
+		_, err := s.Do(user.InjectOrgID(context.Background(), tenantID), &tripperware.PrometheusRequest{Start: 2, End: 102, Step: 10})
+		require.NoError(t, err)
+		require.Equal(t, expected, result, tenantID)
+	}
+}
+
+type tenantLimits map[string]*validation.Limits
+
+func (l tenantLimits) ByUserID(userID string) *validation.Limits {
+	return l[userID]
+}
+
+func (l tenantLimits) AllByUserID() map[string]*validation.Limits {
+	return l
+}
@@ -169,6 +169,19 @@ func NewQueryTripperware(
 				} else if isQuery {
 					return instantQuery.RoundTrip(r)
 				}
+
+				switch getOperation(r) {
+				case "series", "labels", "label_values":
+					if limits == nil {
+						break
+					}
+					empty, err := enforceMetadataQueryLimits(r, now, limits, tenantIDs, log)
+					if err != nil {
+						return nil, err
+					} else if empty {
+						return newEmptyMetadataResponse(r), nil
+					}
+				}
 				return next.RoundTrip(r)
 			})
 		}
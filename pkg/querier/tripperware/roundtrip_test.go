@@ -29,7 +29,8 @@ const (
 	queryExemplar                 = "/api/v1/query_exemplars?query=test_exemplar_metric_total&start=2020-09-14T15:22:25.479Z&end=2020-09-14T15:23:25.479Z'"
 	querySubqueryStepSizeTooSmall = "/api/v1/query?query=up%5B30d%3A%5D"
 	queryExceedsMaxQueryLength    = "/api/v1/query?query=up%5B90d%5D"
-	seriesQuery                   = "/api/v1/series?match[]"
+	seriesQuery                   = "/api/v1/series?match[]=up&start=1536673680&end=1536716898"
+	seriesExceedsMaxQueryLength   = "/api/v1/series?match[]=up&start=0&end=1536716898"
 
 	responseBody        = `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"foo":"bar"},"values":[[1536673680,"137"],[1536673780,"137"]]}]}}`
 	instantResponseBody = `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"foo":"bar"},"values":[[1536673680,"137"],[1536673780,"137"]]}]}}`
@@ -188,6 +189,12 @@ func TestRoundTrip(t *testing.T) {
 			limits:           defaultOverrides,
 			maxSubQuerySteps: 11000,
 		},
+		{
+			path:             seriesExceedsMaxQueryLength,
+			expectedErr:      httpgrpc.Errorf(http.StatusBadRequest, validation.ErrQueryTooLong, 1536716898*time.Second, 60*24*time.Hour),
+			limits:           defaultOverrides,
+			maxSubQuerySteps: 11000,
+		},
 		{
 			// The query should go to instant query middlewares rather than forwarding to next.
 			path:             queryExceedsMaxQueryLength,
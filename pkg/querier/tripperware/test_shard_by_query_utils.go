@@ -473,12 +473,16 @@ http_requests_total`,
 }
 
 type mockLimits struct {
-	maxQueryLookback  time.Duration
-	maxQueryLength    time.Duration
-	maxCacheFreshness time.Duration
-	shardSize         int
-	queryPriority     validation.QueryPriority
-	queryRejection    validation.QueryRejection
+	maxQueryLookback    time.Duration
+	maxQueryLength      time.Duration
+	clampMaxQueryLength bool
+	maxCacheFreshness   time.Duration
+	shardSize           int
+	queryPriority       validation.QueryPriority
+	queryRejection      validation.QueryRejection
+	metadataCacheTTL    time.Duration
+	instantQueryTTL     time.Duration
+	unalignedQueryTTL   time.Duration
 }
 
 func (m mockLimits) MaxQueryLookback(string) time.Duration {
@@ -489,6 +493,18 @@ func (m mockLimits) MaxQueryLength(string) time.Duration {
 	return m.maxQueryLength
 }
 
+func (m mockLimits) ClampMaxQueryLength(string) bool {
+	return m.clampMaxQueryLength
+}
+
+func (mockLimits) MaxPointsPerSeries(string) int {
+	return 0
+}
+
+func (mockLimits) AdjustStepToMaxPoints(string) bool {
+	return false
+}
+
 func (mockLimits) MaxQueryParallelism(string) int {
 	return 14 // Flag default.
 }
@@ -497,10 +513,38 @@ func (m mockLimits) MaxCacheFreshness(string) time.Duration {
 	return m.maxCacheFreshness
 }
 
+func (m mockLimits) ResultsCacheTTL(string) time.Duration {
+	return 0
+}
+
+func (m mockLimits) AlignQueriesWithStep(string) bool {
+	return false
+}
+
+func (m mockLimits) MetadataCacheTTL(string) time.Duration {
+	return m.metadataCacheTTL
+}
+
+func (m mockLimits) InstantQueryCacheTTL(string) time.Duration {
+	return m.instantQueryTTL
+}
+
+func (m mockLimits) UnalignedQueryCacheTTL(string) time.Duration {
+	return m.unalignedQueryTTL
+}
+
 func (m mockLimits) QueryVerticalShardSize(userID string) int {
 	return m.shardSize
 }
 
+func (m mockLimits) SplitInstantQueriesByInterval(userID string) time.Duration {
+	return 0
+}
+
+func (m mockLimits) SplitQueriesByInterval(userID string) time.Duration {
+	return 0
+}
+
 func (m mockLimits) QueryPriority(userID string) validation.QueryPriority {
 	return m.queryPriority
 }
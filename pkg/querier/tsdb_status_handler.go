@@ -0,0 +1,70 @@
+package querier
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	v1 "github.com/prometheus/prometheus/web/api/v1"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+const (
+	defaultTSDBStatusLimit = 10
+	maxTSDBStatusLimit     = 1000
+)
+
+type tsdbStatusResult struct {
+	Status string         `json:"status"`
+	Data   *v1.TSDBStatus `json:"data,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// TSDBStatusHandler returns the cardinality statistics of the TSDB heads of the
+// ingesters for a given tenant, in the format of the Prometheus /api/v1/status/tsdb API.
+func TSDBStatusHandler(d Distributor) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultTSDBStatusLimit
+		if s := r.FormValue("limit"); s != "" {
+			var err error
+			if limit, err = strconv.Atoi(s); err != nil || limit < 1 || limit > maxTSDBStatusLimit {
+				w.WriteHeader(http.StatusBadRequest)
+				util.WriteJSONResponse(w, tsdbStatusResult{Status: statusError, Error: fmt.Sprintf("limit must be a positive number not greater than %d", maxTSDBStatusLimit)})
+				return
+			}
+		}
+
+		resp, err := d.TSDBStatus(r.Context(), &client.TSDBStatusRequest{Limit: int32(limit)})
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			util.WriteJSONResponse(w, tsdbStatusResult{Status: statusError, Error: err.Error()})
+			return
+		}
+
+		util.WriteJSONResponse(w, tsdbStatusResult{
+			Status: statusSuccess,
+			Data: &v1.TSDBStatus{
+				HeadStats: v1.HeadStats{
+					NumSeries:     resp.NumSeries,
+					NumLabelPairs: int(resp.NumLabelPairs),
+					MinTime:       resp.MinTime,
+					MaxTime:       resp.MaxTime,
+				},
+				SeriesCountByMetricName:     toTSDBStats(resp.SeriesCountByMetricName),
+				LabelValueCountByLabelName:  toTSDBStats(resp.LabelValueCountByLabelName),
+				MemoryInBytesByLabelName:    toTSDBStats(resp.MemoryInBytesByLabelName),
+				SeriesCountByLabelValuePair: toTSDBStats(resp.SeriesCountByLabelValuePair),
+			},
+		})
+	})
+}
+
+func toTSDBStats(stats []*client.TSDBStatistic) []v1.TSDBStat {
+	result := make([]v1.TSDBStat, 0, len(stats))
+	for _, s := range stats {
+		result = append(result, v1.TSDBStat{Name: s.Name, Value: s.Value})
+	}
+	return result
+}
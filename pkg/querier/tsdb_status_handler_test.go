@@ -0,0 +1,104 @@
+package querier
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+func TestTSDBStatusHandler(t *testing.T) {
+	t.Parallel()
+
+	resp := &client.TSDBStatusResponse{
+		NumSeries:                   3,
+		MinTime:                     1000,
+		MaxTime:                     3000,
+		NumLabelPairs:               5,
+		SeriesCountByMetricName:     []*client.TSDBStatistic{{Name: "foo", Value: 2}},
+		LabelValueCountByLabelName:  []*client.TSDBStatistic{{Name: "job", Value: 3}},
+		MemoryInBytesByLabelName:    []*client.TSDBStatistic{{Name: "__name__", Value: 9}},
+		SeriesCountByLabelValuePair: []*client.TSDBStatistic{{Name: "__name__=foo", Value: 2}},
+	}
+
+	for name, tc := range map[string]struct {
+		url            string
+		distributorErr error
+		expectedLimit  int32
+		expectedCode   int
+		expectedJSON   string
+	}{
+		"should return the statistics with the default limit": {
+			url:           "/api/v1/status/tsdb",
+			expectedLimit: 10,
+			expectedCode:  http.StatusOK,
+			expectedJSON: `{
+				"status": "success",
+				"data": {
+					"headStats": {"numSeries": 3, "numLabelPairs": 5, "chunkCount": 0, "minTime": 1000, "maxTime": 3000},
+					"seriesCountByMetricName": [{"name": "foo", "value": 2}],
+					"labelValueCountByLabelName": [{"name": "job", "value": 3}],
+					"memoryInBytesByLabelName": [{"name": "__name__", "value": 9}],
+					"seriesCountByLabelValuePair": [{"name": "__name__=foo", "value": 2}]
+				}
+			}`,
+		},
+		"should pass the limit to the distributor": {
+			url:           "/api/v1/status/tsdb?limit=1",
+			expectedLimit: 1,
+			expectedCode:  http.StatusOK,
+			expectedJSON: `{
+				"status": "success",
+				"data": {
+					"headStats": {"numSeries": 3, "numLabelPairs": 5, "chunkCount": 0, "minTime": 1000, "maxTime": 3000},
+					"seriesCountByMetricName": [{"name": "foo", "value": 2}],
+					"labelValueCountByLabelName": [{"name": "job", "value": 3}],
+					"memoryInBytesByLabelName": [{"name": "__name__", "value": 9}],
+					"seriesCountByLabelValuePair": [{"name": "__name__=foo", "value": 2}]
+				}
+			}`,
+		},
+		"should fail on an invalid limit": {
+			url:          "/api/v1/status/tsdb?limit=0",
+			expectedCode: http.StatusBadRequest,
+			expectedJSON: `{"status": "error", "error": "limit must be a positive number not greater than 1000"}`,
+		},
+		"should fail on a too large limit": {
+			url:          "/api/v1/status/tsdb?limit=2147483647",
+			expectedCode: http.StatusBadRequest,
+			expectedJSON: `{"status": "error", "error": "limit must be a positive number not greater than 1000"}`,
+		},
+		"should fail on a distributor error": {
+			url:            "/api/v1/status/tsdb",
+			distributorErr: fmt.Errorf("no user id"),
+			expectedLimit:  10,
+			expectedCode:   http.StatusBadRequest,
+			expectedJSON:   `{"status": "error", "error": "no user id"}`,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			d := &MockDistributor{}
+			d.On("TSDBStatus", mock.Anything, &client.TSDBStatusRequest{Limit: tc.expectedLimit}).Return(resp, tc.distributorErr)
+
+			request, err := http.NewRequest("GET", tc.url, nil)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			TSDBStatusHandler(d).ServeHTTP(recorder, request)
+
+			require.Equal(t, tc.expectedCode, recorder.Result().StatusCode)
+			responseBody, err := io.ReadAll(recorder.Result().Body)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expectedJSON, string(responseBody))
+		})
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	mathrand "math/rand"
 	"sort"
-	"strings"
 	"sync"
 	"time"
 
@@ -91,10 +90,7 @@ type BasicLifecycler struct {
 
 // NewBasicLifecycler makes a new BasicLifecycler.
 func NewBasicLifecycler(cfg BasicLifecyclerConfig, ringName, ringKey string, store kv.Client, delegate BasicLifecyclerDelegate, logger log.Logger, reg prometheus.Registerer) (*BasicLifecycler, error) {
-	tg := NewRandomTokenGenerator()
-	if strings.EqualFold(cfg.TokensGeneratorStrategy, minimizeSpreadTokenStrategy) {
-		tg = NewMinimizeSpreadTokenGenerator()
-	}
+	tg := newTokenGenerator(cfg.TokensGeneratorStrategy)
 
 	l := &BasicLifecycler{
 		cfg:            cfg,
@@ -33,6 +33,32 @@ type itemTracker struct {
 	remaining   atomic.Int32
 	err4xx      atomic.Error
 	err5xx      atomic.Error
+
+	// Zone-based quorum tracking. It's only set when the zone quorum is enabled
+	// and the item is replicated across at least 2 zones.
+	zones           map[string]*zoneTracker
+	minSuccessZones int
+	maxFailureZones int
+	succeededZones  atomic.Int32
+	failedZones     atomic.Int32
+}
+
+type zoneTracker struct {
+	remaining atomic.Int32
+	succeeded atomic.Bool
+}
+
+// DoBatchOptions configures the quorum DoBatch waits for.
+type DoBatchOptions struct {
+	// ZoneQuorum makes an item successful once it has been written to a majority of the
+	// zones it is replicated to, and failed once it can no longer reach such majority. A zone
+	// counts as successful when at least one of its instances succeeded. Items replicated
+	// to less than 2 zones fall back to the instance-based quorum.
+	ZoneQuorum bool
+
+	// MinSuccessZones overrides the majority of the zones required by ZoneQuorum. 0 means
+	// a majority of the zones.
+	MinSuccessZones int
 }
 
 func (i *itemTracker) recordError(err error) int32 {
@@ -67,6 +93,11 @@ func (i *itemTracker) getError() error {
 //
 // Not implemented as a method on Ring so we can test separately.
 func DoBatch(ctx context.Context, op Operation, r ReadRing, keys []uint32, callback func(InstanceDesc, []int) error, cleanup func()) error {
+	return DoBatchWithOptions(ctx, op, r, keys, callback, cleanup, DoBatchOptions{})
+}
+
+// DoBatchWithOptions is like DoBatch but allows to customize the quorum with DoBatchOptions.
+func DoBatchWithOptions(ctx context.Context, op Operation, r ReadRing, keys []uint32, callback func(InstanceDesc, []int) error, cleanup func(), opts DoBatchOptions) error {
 	if r.InstancesCount() <= 0 {
 		cleanup()
 		return fmt.Errorf("DoBatch: InstancesCount <= 0")
@@ -90,6 +121,9 @@ func DoBatch(ctx context.Context, op Operation, r ReadRing, keys []uint32, callb
 		itemTrackers[i].minSuccess = len(replicationSet.Instances) - replicationSet.MaxErrors
 		itemTrackers[i].maxFailures = replicationSet.MaxErrors
 		itemTrackers[i].remaining.Store(int32(len(replicationSet.Instances)))
+		if opts.ZoneQuorum {
+			itemTrackers[i].initZoneQuorum(replicationSet.Instances, opts.MinSuccessZones)
+		}
 
 		for _, desc := range replicationSet.Instances {
 			curr, found := instances[desc.Addr]
@@ -149,6 +183,11 @@ func (b *batchTracker) record(instance instance, err error) {
 	// avoiding race condition
 	sampleTrackers := instance.itemTrackers
 	for i := range sampleTrackers {
+		if sampleTrackers[i].zones != nil {
+			b.recordZone(instance, sampleTrackers[i], err)
+			continue
+		}
+
 		if err != nil {
 			// Track the number of errors by error family, and if it exceeds maxFailures
 			// shortcut the waiting rpc.
@@ -198,3 +237,58 @@ func (b *batchTracker) record(instance instance, err error) {
 		}
 	}
 }
+
+// initZoneQuorum enables the zone-based quorum for the item if its replicas span at least 2 zones.
+func (i *itemTracker) initZoneQuorum(instances []InstanceDesc, minSuccessZones int) {
+	zones := map[string]*zoneTracker{}
+	for _, desc := range instances {
+		zone, ok := zones[desc.Zone]
+		if !ok {
+			zone = &zoneTracker{}
+			zones[desc.Zone] = zone
+		}
+		zone.remaining.Inc()
+	}
+
+	if len(zones) < 2 {
+		return
+	}
+
+	i.zones = zones
+	i.minSuccessZones = MinSuccessZones(len(zones), minSuccessZones)
+	i.maxFailureZones = len(zones) - i.minSuccessZones
+}
+
+// recordZone records the result of a request for an item tracked with the zone-based quorum.
+// A zone is successful as soon as one of its instances succeeded, and failed once all of its
+// instances failed, so each zone is accounted exactly once.
+func (b *batchTracker) recordZone(instance instance, tracker *itemTracker, err error) {
+	zone := tracker.zones[instance.desc.Zone]
+
+	if err == nil {
+		// Mark the zone as succeeded before decrementing the remaining instances, so that
+		// a concurrent failure in the same zone never sees the zone as failed.
+		if zone.succeeded.CompareAndSwap(false, true) {
+			if tracker.succeededZones.Inc() == int32(tracker.minSuccessZones) {
+				if b.rpcsPending.Dec() == 0 {
+					b.done <- struct{}{}
+				}
+			}
+		}
+		zone.remaining.Dec()
+		return
+	}
+
+	tracker.recordError(err)
+	if zone.remaining.Dec() > 0 || zone.succeeded.Load() {
+		return
+	}
+
+	if tracker.failedZones.Inc() == int32(tracker.maxFailureZones+1) {
+		if b.rpcsFailed.Inc() == 1 {
+			b.err <- httpgrpcutil.WrapHTTPGrpcError(
+				tracker.getError(), "maxFailure (zone quorum) on a given error family, addr=%s state=%s zone=%s",
+				instance.desc.Addr, instance.desc.State, instance.desc.Zone)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/concurrency"
 	"github.com/cortexproject/cortex/pkg/util/services"
 )
 
@@ -35,9 +36,21 @@ type PoolServiceDiscovery func() ([]string, error)
 
 // PoolConfig is config for creating a Pool.
 type PoolConfig struct {
+	// CheckInterval is how frequently the stale clients are removed and the clients are
+	// health checked. 0 to disable.
 	CheckInterval      time.Duration
 	HealthCheckEnabled bool
 	HealthCheckTimeout time.Duration
+
+	// CheckIntervalJitter is the variance percentage applied to each check interval, so that
+	// the clients of all the replicas don't re-resolve the services and health check them at
+	// the same time. 0 to disable.
+	CheckIntervalJitter float64
+
+	// HealthCheckConcurrency is the maximum number of clients health checked concurrently,
+	// so that a few unresponsive services don't delay the eviction of the other unhealthy
+	// clients. Defaults to 1.
+	HealthCheckConcurrency int
 }
 
 // Pool holds a cache of grpc_health_v1 clients.
@@ -69,17 +82,44 @@ func NewPool(clientName string, cfg PoolConfig, discovery PoolServiceDiscovery,
 	}
 
 	p.Service = services.
-		NewTimerService(cfg.CheckInterval, nil, p.iteration, nil).
+		NewBasicService(nil, p.running, nil).
 		WithName(fmt.Sprintf("%s client pool", p.clientName))
 	return p
 }
 
-func (p *Pool) iteration(ctx context.Context) error {
+func (p *Pool) running(ctx context.Context) error {
+	if p.cfg.CheckInterval <= 0 {
+		<-ctx.Done()
+		return nil
+	}
+
+	t := time.NewTimer(p.nextCheckInterval())
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			p.iteration(ctx)
+			t.Reset(p.nextCheckInterval())
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (p *Pool) nextCheckInterval() time.Duration {
+	if p.cfg.CheckIntervalJitter <= 0 {
+		return p.cfg.CheckInterval
+	}
+	return util.DurationWithJitter(p.cfg.CheckInterval, p.cfg.CheckIntervalJitter)
+}
+
+func (p *Pool) iteration(ctx context.Context) {
 	p.removeStaleClients()
 	if p.cfg.HealthCheckEnabled {
-		p.cleanUnhealthy()
+		p.cleanUnhealthy(ctx)
 	}
-	return nil
 }
 
 func (p *Pool) fromCache(addr string) (PoolClient, bool) {
@@ -174,8 +214,15 @@ func (p *Pool) removeStaleClients() {
 }
 
 // cleanUnhealthy loops through all servers and deletes any that fails a healthcheck.
-func (p *Pool) cleanUnhealthy() {
-	for _, addr := range p.RegisteredAddresses() {
+func (p *Pool) cleanUnhealthy(ctx context.Context) {
+	maxConcurrency := p.cfg.HealthCheckConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = 1
+	}
+
+	// The errors are never returned, so that all the clients are checked.
+	_ = concurrency.ForEach(ctx, concurrency.CreateJobsFromStrings(p.RegisteredAddresses()), maxConcurrency, func(_ context.Context, job interface{}) error {
+		addr := job.(string)
 		client, ok := p.fromCache(addr)
 		// not ok means someone removed a client between the start of this loop and now
 		if ok {
@@ -185,7 +232,8 @@ func (p *Pool) cleanUnhealthy() {
 				p.RemoveClientFor(addr)
 			}
 		}
-	}
+		return nil
+	})
 }
 
 // healthCheck will check if the client is still healthy, returning an error if it is not
@@ -3,12 +3,14 @@ package client
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/gogo/status"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health/grpc_health_v1"
@@ -133,7 +135,7 @@ func TestCleanUnhealthy(t *testing.T) {
 		clients: clients,
 		logger:  log.NewNopLogger(),
 	}
-	pool.cleanUnhealthy()
+	pool.cleanUnhealthy(context.Background())
 	for _, addr := range badAddrs {
 		if _, ok := pool.clients[addr]; ok {
 			t.Errorf("Found bad client after clean: %s\n", addr)
@@ -145,3 +147,77 @@ func TestCleanUnhealthy(t *testing.T) {
 		}
 	}
 }
+
+// barrierClient is healthy only if the health checks of all the clients sharing the
+// barrier run concurrently.
+type barrierClient struct {
+	mockClient
+	started *sync.WaitGroup
+}
+
+func (b barrierClient) Check(ctx context.Context, in *grpc_health_v1.HealthCheckRequest, opts ...grpc.CallOption) (*grpc_health_v1.HealthCheckResponse, error) {
+	b.started.Done()
+
+	done := make(chan struct{})
+	go func() {
+		b.started.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestCleanUnhealthy_ShouldHealthCheckTheClientsConcurrently(t *testing.T) {
+	const numClients = 4
+
+	started := &sync.WaitGroup{}
+	started.Add(numClients)
+
+	clients := map[string]PoolClient{}
+	for i := 0; i < numClients; i++ {
+		clients[fmt.Sprintf("addr-%d", i)] = barrierClient{started: started}
+	}
+	pool := &Pool{
+		cfg: PoolConfig{
+			HealthCheckTimeout:     5 * time.Second,
+			HealthCheckConcurrency: numClients,
+		},
+		clients: clients,
+		logger:  log.NewNopLogger(),
+	}
+
+	pool.cleanUnhealthy(context.Background())
+	require.Equal(t, numClients, pool.Count())
+}
+
+func TestPool_NextCheckInterval(t *testing.T) {
+	pool := &Pool{cfg: PoolConfig{CheckInterval: time.Minute}}
+	require.Equal(t, time.Minute, pool.nextCheckInterval())
+
+	pool.cfg.CheckIntervalJitter = 0.2
+	for i := 0; i < 100; i++ {
+		interval := pool.nextCheckInterval()
+		require.GreaterOrEqual(t, interval, 48*time.Second)
+		require.LessOrEqual(t, interval, 72*time.Second)
+	}
+}
+
+func TestPool_ShouldNotCheckTheClientsWithZeroCheckInterval(t *testing.T) {
+	var discoveries atomic.Int32
+	discovery := func() ([]string, error) {
+		discoveries.Inc()
+		return nil, nil
+	}
+
+	pool := NewPool("test", PoolConfig{}, discovery, nil, nil, log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), pool))
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), pool))
+	require.Equal(t, int32(0), discoveries.Load())
+}
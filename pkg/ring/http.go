@@ -25,6 +25,32 @@ const pageContent = `
 		<h1>Ring Status</h1>
 		<p>Current time: {{ .Now }}</p>
         <p>Storage updated: {{ .StorageLastUpdated }}</p>
+		<p>
+			<a href="?format=json">JSON</a>
+		</p>
+		{{ if .Zones }}
+		<table border="1">
+			<thead>
+				<tr>
+					<th>Availability Zone</th>
+					<th>Instances</th>
+					<th>Tokens</th>
+					<th>Ownership</th>
+				</tr>
+			</thead>
+			<tbody>
+				{{ range .Zones }}
+				<tr>
+					<td>{{ .Zone }}</td>
+					<td>{{ .NumInstances }}</td>
+					<td>{{ .NumTokens }}</td>
+					<td>{{ .Ownership }}%</td>
+				</tr>
+				{{ end }}
+			</tbody>
+		</table>
+		<br>
+		{{ end }}
 		<form action="" method="POST">
 			<input type="hidden" name="csrf_token" value="$__CSRF_TOKEN_PLACEHOLDER__">
 			<table width="100%" border="1">
@@ -58,7 +84,10 @@ const pageContent = `
 						<td>{{ .NumTokens }}</td>
 						<td>{{ .Ownership }}%</td>
 						<td>{{ .DiffOwnership }}%</td>
-						<td><button name="forget" value="{{ .ID }}" type="submit">Forget</button></td>
+						<td>
+							<button name="forget" value="{{ .ID }}" type="submit">Forget</button>
+							<input type="button" value="Show Tokens" onclick="window.location.href = '?tokens={{ .ID }}'" />
+						</td>
 					</tr>
 					{{ end }}
 				</tbody>
@@ -72,6 +101,7 @@ const pageContent = `
 
 			{{ if .ShowTokens }}
 				{{ range $i, $ing := .Ingesters }}
+					{{ if .ShowTokens }}
 					<h2>Instance: {{ .ID }}</h2>
 					<p>
 						Tokens:<br />
@@ -79,6 +109,7 @@ const pageContent = `
 							{{ $token }}
 						{{ end }}
 					</p>
+					{{ end }}
 				{{ end }}
 			{{ end }}
 		</form>
@@ -115,12 +146,21 @@ type ingesterDesc struct {
 	Zone                string   `json:"zone"`
 	Tokens              []uint32 `json:"tokens"`
 	NumTokens           int      `json:"-"`
-	Ownership           float64  `json:"-"`
-	DiffOwnership       float64  `json:"-"`
+	Ownership           float64  `json:"ownership"`
+	DiffOwnership       float64  `json:"ownership_diff"`
+	ShowTokens          bool     `json:"-"`
+}
+
+type zoneDesc struct {
+	Zone         string  `json:"zone"`
+	NumInstances int     `json:"instances"`
+	NumTokens    int     `json:"tokens"`
+	Ownership    float64 `json:"ownership"`
 }
 
 type httpResponse struct {
 	Ingesters          []ingesterDesc `json:"shards"`
+	Zones              []zoneDesc     `json:"zones"`
 	Now                time.Time      `json:"now"`
 	StorageLastUpdated time.Time      `json:"storageLastUpdated"`
 	ShowTokens         bool           `json:"-"`
@@ -129,10 +169,21 @@ type httpResponse struct {
 func (r *Ring) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	if req.Method == http.MethodPost {
 		ingesterID := req.FormValue("forget")
-		if err := r.forget(req.Context(), ingesterID); err != nil {
+		err := r.forget(req.Context(), ingesterID)
+		if err != nil {
 			level.Error(r.logger).Log("msg", "error forgetting instance", "err", err)
 		}
 
+		// API clients get the outcome of the action, instead of being redirected to the page.
+		if isJSONRequest(req) {
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
 		// Implement PRG pattern to prevent double-POST and work with CSRF middleware.
 		// https://en.wikipedia.org/wiki/Post/Redirect/Get
 
@@ -154,10 +205,15 @@ func (r *Ring) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 	sort.Strings(ingesterIDs)
 
+	// The tokens of all the instances are shown with tokens=true, while the tokens
+	// of a single instance are shown with tokens=<instance ID>.
+	tokensParam := req.URL.Query().Get("tokens")
+
 	storageLastUpdate := r.KVClient.LastUpdateTime(r.key)
 	var ingesters []ingesterDesc
 	numTokens, ownedByAz := r.countTokensByAz()
 	_, owned := r.countTokens()
+	zones := map[string]*zoneDesc{}
 
 	for _, id := range ingesterIDs {
 		ing := r.ringDesc.Ingesters[id]
@@ -197,24 +253,110 @@ func (r *Ring) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			NumTokens:           len(ing.Tokens),
 			Ownership:           ownership,
 			DiffOwnership:       deltaOwnership,
+			ShowTokens:          tokensParam == "true" || tokensParam == id,
 		})
+
+		// The ownership of a zone is the percentage of the whole ring owned by its instances.
+		zone, ok := zones[ing.Zone]
+		if !ok {
+			zone = &zoneDesc{Zone: ing.Zone}
+			zones[ing.Zone] = zone
+		}
+		zone.NumInstances++
+		zone.NumTokens += len(ing.Tokens)
+		zone.Ownership += (float64(owned[id]) / float64(math.MaxUint32+1)) * 100
 	}
 
-	tokensParam := req.URL.Query().Get("tokens")
+	// The zones are only listed when the instances are spread across availability zones.
+	var zoneDescs []zoneDesc
+	if _, ok := zones[""]; !ok || len(zones) > 1 {
+		for _, zone := range zones {
+			zoneDescs = append(zoneDescs, *zone)
+		}
+		sort.Slice(zoneDescs, func(i, j int) bool { return zoneDescs[i].Zone < zoneDescs[j].Zone })
+	}
 
 	renderHTTPResponse(w, httpResponse{
 		Ingesters:          ingesters,
+		Zones:              zoneDescs,
 		Now:                time.Now(),
 		StorageLastUpdated: storageLastUpdate,
-		ShowTokens:         tokensParam == "true",
+		ShowTokens:         tokensParam != "" && tokensParam != "false",
 	}, pageTemplate, req)
 }
 
+type instanceDump struct {
+	ID                  string     `json:"id"`
+	Address             string     `json:"address"`
+	Zone                string     `json:"zone"`
+	State               string     `json:"state"`
+	Healthy             bool       `json:"healthy"`
+	HeartbeatTimestamp  time.Time  `json:"heartbeat_timestamp"`
+	RegisteredTimestamp *time.Time `json:"registered_timestamp,omitempty"`
+	Tokens              []uint32   `json:"tokens"`
+}
+
+type ringDump struct {
+	Key                  string         `json:"key"`
+	ReplicationFactor    int            `json:"replication_factor"`
+	ZoneAwarenessEnabled bool           `json:"zone_awareness_enabled"`
+	Zones                []string       `json:"zones"`
+	Instances            []instanceDump `json:"instances"`
+	Now                  time.Time      `json:"now"`
+	StorageLastUpdated   time.Time      `json:"storage_last_updated"`
+}
+
+// DumpHandler responds with the full ring descriptor as JSON: the state, address, zone, timestamps
+// and tokens of all the instances, sorted by ID. Unlike the ring status page, the instances state
+// is reported as stored in the ring, while their health is reported separately.
+func (r *Ring) DumpHandler(w http.ResponseWriter, _ *http.Request) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	storageLastUpdate := r.KVClient.LastUpdateTime(r.key)
+	instances := make([]instanceDump, 0, len(r.ringDesc.Ingesters))
+
+	for id, ing := range r.ringDesc.Ingesters {
+		var registeredTimestamp *time.Time
+		if ing.RegisteredTimestamp != 0 {
+			t := ing.GetRegisteredAt().UTC()
+			registeredTimestamp = &t
+		}
+
+		instances = append(instances, instanceDump{
+			ID:                  id,
+			Address:             ing.Addr,
+			Zone:                ing.Zone,
+			State:               ing.State.String(),
+			Healthy:             r.IsHealthy(&ing, Reporting, storageLastUpdate),
+			HeartbeatTimestamp:  time.Unix(ing.Timestamp, 0).UTC(),
+			RegisteredTimestamp: registeredTimestamp,
+			Tokens:              ing.Tokens,
+		})
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+
+	writeJSONResponse(w, ringDump{
+		Key:                  r.key,
+		ReplicationFactor:    r.cfg.ReplicationFactor,
+		ZoneAwarenessEnabled: r.cfg.ZoneAwarenessEnabled,
+		Zones:                r.ringZones,
+		Instances:            instances,
+		Now:                  time.Now().UTC(),
+		StorageLastUpdated:   storageLastUpdate,
+	})
+}
+
+// isJSONRequest returns whether the client asked for a JSON response, either through
+// the Accept header or the format=json query parameter.
+func isJSONRequest(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json") || r.URL.Query().Get("format") == "json"
+}
+
 // RenderHTTPResponse either responds with json or a rendered html page using the passed in template
-// by checking the Accepts header
+// by checking the Accepts header and the format query parameter
 func renderHTTPResponse(w http.ResponseWriter, v httpResponse, t *template.Template, r *http.Request) {
-	accept := r.Header.Get("Accept")
-	if strings.Contains(accept, "application/json") {
+	if isJSONRequest(r) {
 		writeJSONResponse(w, v)
 		return
 	}
@@ -226,7 +368,7 @@ func renderHTTPResponse(w http.ResponseWriter, v httpResponse, t *template.Templ
 }
 
 // WriteJSONResponse writes some JSON as a HTTP response.
-func writeJSONResponse(w http.ResponseWriter, v httpResponse) {
+func writeJSONResponse(w http.ResponseWriter, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 
 	data, err := json.Marshal(v)
@@ -0,0 +1,200 @@
+package ring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRingForHTTPTesting(client *MockClient) *Ring {
+	ringDesc := &Desc{Ingesters: generateRingInstances(3, 2, 32)}
+
+	return &Ring{
+		cfg: Config{
+			HeartbeatTimeout:     time.Hour,
+			ZoneAwarenessEnabled: true,
+			ReplicationFactor:    2,
+		},
+		key:                 "ring",
+		logger:              log.NewNopLogger(),
+		ringDesc:            ringDesc,
+		ringTokens:          ringDesc.GetTokens(),
+		ringTokensByZone:    ringDesc.getTokensByZone(),
+		ringInstanceByToken: ringDesc.getTokensInfo(),
+		ringZones:           getZones(ringDesc.getTokensByZone()),
+		strategy:            NewDefaultReplicationStrategy(),
+		KVClient:            client,
+	}
+}
+
+func TestRing_ServeHTTP_JSON(t *testing.T) {
+	r := newRingForHTTPTesting(&MockClient{})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/ring?format=json", nil),
+		func() *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/ring", nil)
+			req.Header.Set("Accept", "application/json")
+			return req
+		}(),
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var res struct {
+			Shards []struct {
+				ID        string  `json:"id"`
+				Ownership float64 `json:"ownership"`
+			} `json:"shards"`
+			Zones []zoneDesc `json:"zones"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		require.Len(t, res.Shards, 3)
+
+		require.Len(t, res.Zones, 2)
+		assert.Equal(t, "zone-0", res.Zones[0].Zone)
+		assert.Equal(t, 1, res.Zones[0].NumInstances)
+		assert.Equal(t, 32, res.Zones[0].NumTokens)
+		assert.Equal(t, "zone-1", res.Zones[1].Zone)
+		assert.Equal(t, 2, res.Zones[1].NumInstances)
+		assert.Equal(t, 64, res.Zones[1].NumTokens)
+
+		// The ownership of the zones should cover the whole ring.
+		assert.InDelta(t, 100, res.Zones[0].Ownership+res.Zones[1].Ownership, 0.001)
+	}
+}
+
+func TestRing_ServeHTTP_ShouldShowTheTokensOfTheRequestedInstances(t *testing.T) {
+	r := newRingForHTTPTesting(&MockClient{})
+
+	tests := map[string]struct {
+		query    string
+		expected []string
+	}{
+		"no tokens": {
+			query:    "",
+			expected: nil,
+		},
+		"tokens of all the instances": {
+			query:    "?tokens=true",
+			expected: []string{"instance-1", "instance-2", "instance-3"},
+		},
+		"tokens of a single instance": {
+			query:    "?tokens=instance-2",
+			expected: []string{"instance-2"},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ring"+testData.query, nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			for _, id := range []string{"instance-1", "instance-2", "instance-3"} {
+				shown := strings.Contains(rec.Body.String(), "<h2>Instance: "+id+"</h2>")
+				assert.Equal(t, contains(testData.expected, id), shown, id)
+			}
+		})
+	}
+}
+
+func TestRing_ServeHTTP_Forget(t *testing.T) {
+	var forgotten []string
+	client := &MockClient{
+		CASFunc: func(_ context.Context, _ string, f func(in interface{}) (out interface{}, retry bool, err error)) error {
+			out, _, err := f(&Desc{Ingesters: generateRingInstances(3, 2, 32)})
+			if err != nil {
+				return err
+			}
+			for _, id := range []string{"instance-1", "instance-2", "instance-3"} {
+				if _, ok := out.(*Desc).Ingesters[id]; !ok {
+					forgotten = append(forgotten, id)
+				}
+			}
+			return nil
+		},
+	}
+	r := newRingForHTTPTesting(client)
+
+	newForgetRequest := func(id string, json bool) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/ring", strings.NewReader(url.Values{"forget": {id}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if json {
+			req.Header.Set("Accept", "application/json")
+		}
+		return req
+	}
+
+	// Browsers should be redirected to the page.
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newForgetRequest("instance-1", false))
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, []string{"instance-1"}, forgotten)
+
+	// API clients should get the outcome of the action.
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newForgetRequest("instance-2", true))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, []string{"instance-1", "instance-2"}, forgotten)
+
+	client.CASFunc = func(context.Context, string, func(in interface{}) (out interface{}, retry bool, err error)) error {
+		return errors.New("CAS failed")
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newForgetRequest("instance-3", true))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestRing_DumpHandler(t *testing.T) {
+	r := newRingForHTTPTesting(&MockClient{})
+
+	rec := httptest.NewRecorder()
+	r.DumpHandler(rec, httptest.NewRequest(http.MethodGet, "/ring/json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var res ringDump
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, "ring", res.Key)
+	assert.Equal(t, 2, res.ReplicationFactor)
+	assert.True(t, res.ZoneAwarenessEnabled)
+	assert.Equal(t, []string{"zone-0", "zone-1"}, res.Zones)
+
+	require.Len(t, res.Instances, 3)
+	for i, instance := range res.Instances {
+		expected := r.ringDesc.Ingesters[instance.ID]
+
+		assert.Equal(t, fmt.Sprintf("instance-%d", i+1), instance.ID)
+		assert.Equal(t, expected.Addr, instance.Address)
+		assert.Equal(t, expected.Zone, instance.Zone)
+		assert.Equal(t, expected.State.String(), instance.State)
+		assert.Equal(t, expected.Tokens, instance.Tokens)
+		assert.Equal(t, expected.Timestamp, instance.HeartbeatTimestamp.Unix())
+		assert.True(t, instance.Healthy)
+		require.NotNil(t, instance.RegisteredTimestamp)
+		assert.Equal(t, expected.RegisteredTimestamp, instance.RegisteredTimestamp.Unix())
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
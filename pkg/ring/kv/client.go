@@ -15,6 +15,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/ring/kv/dynamodb"
 	"github.com/cortexproject/cortex/pkg/ring/kv/etcd"
 	"github.com/cortexproject/cortex/pkg/ring/kv/memberlist"
+	"github.com/cortexproject/cortex/pkg/ring/kv/redis"
 )
 
 const (
@@ -45,8 +46,11 @@ type StoreConfig struct {
 	DynamoDB dynamodb.Config `yaml:"dynamodb"`
 	Consul   consul.Config   `yaml:"consul"`
 	Etcd     etcd.Config     `yaml:"etcd"`
+	Redis    redis.Config    `yaml:"redis"`
 	Multi    MultiConfig     `yaml:"multi"`
 
+	SlowRequestLogThreshold time.Duration `yaml:"slow_request_log_threshold"`
+
 	// Function that returns memberlist.KV store to use. By using a function, we can delay
 	// initialization of memberlist.KV until it is actually required.
 	MemberlistKV func() (*memberlist.KV, error) `yaml:"-"`
@@ -75,13 +79,15 @@ func (cfg *Config) RegisterFlagsWithPrefix(flagsPrefix, defaultPrefix string, f
 	cfg.DynamoDB.RegisterFlags(f, flagsPrefix)
 	cfg.Consul.RegisterFlags(f, flagsPrefix)
 	cfg.Etcd.RegisterFlagsWithPrefix(f, flagsPrefix)
+	cfg.Redis.RegisterFlagsWithPrefix(f, flagsPrefix)
 	cfg.Multi.RegisterFlagsWithPrefix(f, flagsPrefix)
 
 	if flagsPrefix == "" {
 		flagsPrefix = "ring."
 	}
 	f.StringVar(&cfg.Prefix, flagsPrefix+"prefix", defaultPrefix, "The prefix for the keys in the store. Should end with a /.")
-	f.StringVar(&cfg.Store, flagsPrefix+"store", "consul", "Backend storage to use for the ring. Supported values are: consul, etcd, inmemory, memberlist, multi.")
+	f.StringVar(&cfg.Store, flagsPrefix+"store", "consul", "Backend storage to use for the ring. Supported values are: consul, etcd, inmemory, memberlist, multi, redis.")
+	f.DurationVar(&cfg.SlowRequestLogThreshold, flagsPrefix+"slow-request-log-threshold", 0, "Log the KV store requests slower than the specified duration, and the watches which didn't receive any update for longer than the specified duration. Set to 0 to disable.")
 }
 
 // Client is a high-level client for key-value stores (such as Etcd and
@@ -146,9 +152,10 @@ func createClient(backend string, prefix string, cfg StoreConfig, codec codec.Co
 
 	case "inmemory":
 		// If we use the in-memory store, make sure everyone gets the same instance
-		// within the same process.
+		// within the same process. The instance is configured like a Consul client,
+		// using the Consul config of the first client created.
 		inmemoryStoreInit.Do(func() {
-			inmemoryStore, _ = consul.NewInMemoryClient(codec, logger, reg)
+			inmemoryStore, _ = consul.NewInMemoryClientWithConfig(codec, cfg.Consul, logger, reg)
 		})
 		client = inmemoryStore
 
@@ -162,6 +169,9 @@ func createClient(backend string, prefix string, cfg StoreConfig, codec codec.Co
 			return nil, err
 		}
 
+	case "redis":
+		client, err = redis.New(cfg.Redis, codec, logger)
+
 	case "multi":
 		client, err = buildMultiClient(cfg, codec, reg, logger)
 
@@ -186,7 +196,7 @@ func createClient(backend string, prefix string, cfg StoreConfig, codec codec.Co
 		return client, nil
 	}
 
-	return newMetricsClient(backend, client, prometheus.WrapRegistererWith(role.Labels(), reg)), nil
+	return newMetricsClient(backend, client, cfg.SlowRequestLogThreshold, prometheus.WrapRegistererWith(role.Labels(), reg), logger), nil
 }
 
 func buildMultiClient(cfg StoreConfig, codec codec.Codec, reg prometheus.Registerer, logger log.Logger) (Client, error) {
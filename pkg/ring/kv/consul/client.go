@@ -40,12 +40,16 @@ var (
 
 // Config to create a ConsulClient
 type Config struct {
-	Host              string         `yaml:"host"`
-	ACLToken          flagext.Secret `yaml:"acl_token"`
-	HTTPClientTimeout time.Duration  `yaml:"http_client_timeout"`
-	ConsistentReads   bool           `yaml:"consistent_reads"`
-	WatchKeyRateLimit float64        `yaml:"watch_rate_limit"` // Zero disables rate limit
-	WatchKeyBurstSize int            `yaml:"watch_burst_size"` // Burst when doing rate-limit, defaults to 1
+	Host                string         `yaml:"host"`
+	ACLToken            flagext.Secret `yaml:"acl_token"`
+	Datacenter          string         `yaml:"datacenter"`
+	HTTPClientTimeout   time.Duration  `yaml:"http_client_timeout"`
+	MaxIdleConns        int            `yaml:"max_idle_conns"`
+	MaxIdleConnsPerHost int            `yaml:"max_idle_conns_per_host"`
+	IdleConnTimeout     time.Duration  `yaml:"idle_conn_timeout"`
+	ConsistentReads     bool           `yaml:"consistent_reads"`
+	WatchKeyRateLimit   float64        `yaml:"watch_rate_limit"` // Zero disables rate limit
+	WatchKeyBurstSize   int            `yaml:"watch_burst_size"` // Burst when doing rate-limit, defaults to 1
 
 	// Used in tests only.
 	MaxCasRetries int           `yaml:"-"`
@@ -74,20 +78,36 @@ type Client struct {
 func (cfg *Config) RegisterFlags(f *flag.FlagSet, prefix string) {
 	f.StringVar(&cfg.Host, prefix+"consul.hostname", "localhost:8500", "Hostname and port of Consul.")
 	f.Var(&cfg.ACLToken, prefix+"consul.acl-token", "ACL Token used to interact with Consul.")
+	f.StringVar(&cfg.Datacenter, prefix+"consul.datacenter", "", "Consul datacenter to interact with. If empty, the datacenter of the Consul agent is used.")
 	f.DurationVar(&cfg.HTTPClientTimeout, prefix+"consul.client-timeout", 2*longPollDuration, "HTTP timeout when talking to Consul")
+	f.IntVar(&cfg.MaxIdleConns, prefix+"consul.max-idle-conns", 100, "Maximum number of idle HTTP connections to Consul. 0 means no limit.")
+	f.IntVar(&cfg.MaxIdleConnsPerHost, prefix+"consul.max-idle-conns-per-host", 0, "Maximum number of idle HTTP connections to each Consul host. 0 means the number of CPUs plus one.")
+	f.DurationVar(&cfg.IdleConnTimeout, prefix+"consul.idle-conn-timeout", 90*time.Second, "How long an idle HTTP connection to Consul is kept open before being closed. 0 means no limit.")
 	f.BoolVar(&cfg.ConsistentReads, prefix+"consul.consistent-reads", false, "Enable consistent reads to Consul.")
 	f.Float64Var(&cfg.WatchKeyRateLimit, prefix+"consul.watch-rate-limit", 1, "Rate limit when watching key or prefix in Consul, in requests per second. 0 disables the rate limit.")
 	f.IntVar(&cfg.WatchKeyBurstSize, prefix+"consul.watch-burst-size", 1, "Burst size used in rate limit. Values less than 1 are treated as 1.")
 }
 
+// transport returns the HTTP transport used to talk to Consul.
+func (cfg *Config) transport() *http.Transport {
+	transport := cleanhttp.DefaultPooledTransport()
+	transport.MaxIdleConns = cfg.MaxIdleConns
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	return transport
+}
+
 // NewClient returns a new Client.
 func NewClient(cfg Config, codec codec.Codec, logger log.Logger, registerer prometheus.Registerer) (*Client, error) {
 	client, err := consul.NewClient(&consul.Config{
-		Address: cfg.Host,
-		Token:   cfg.ACLToken.Value,
-		Scheme:  "http",
+		Address:    cfg.Host,
+		Token:      cfg.ACLToken.Value,
+		Datacenter: cfg.Datacenter,
+		Scheme:     "http",
 		HttpClient: &http.Client{
-			Transport: cleanhttp.DefaultPooledTransport(),
+			Transport: cfg.transport(),
 			// See https://blog.cloudflare.com/the-complete-guide-to-golang-net-http-timeouts/
 			Timeout: cfg.HTTPClientTimeout,
 		},
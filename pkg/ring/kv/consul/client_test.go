@@ -2,8 +2,14 @@ package consul
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,6 +19,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
 )
 
 func writeValuesToKV(t *testing.T, client *Client, key string, start, end int, sleep time.Duration) <-chan struct{} {
@@ -179,6 +186,62 @@ func TestWatchKeyWithNoStartValue(t *testing.T) {
 	require.Equal(t, 2, reported)
 }
 
+func TestNewClient_ShouldSendTheACLTokenAndDatacenter(t *testing.T) {
+	var (
+		mtx      sync.Mutex
+		requests []*http.Request
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		requests = append(requests, r)
+		mtx.Unlock()
+
+		w.Header().Set("X-Consul-Index", "1")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(Config{
+		Host:              strings.TrimPrefix(srv.URL, "http://"),
+		ACLToken:          flagext.Secret{Value: "token"},
+		Datacenter:        "dc-1",
+		HTTPClientTimeout: time.Second,
+	}, codec.String{}, testLogger{}, prometheus.NewPedanticRegistry())
+	require.NoError(t, err)
+
+	value, err := c.Get(context.Background(), "test")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "dc-1", requests[0].URL.Query().Get("dc"))
+	assert.Equal(t, "token", requests[0].Header.Get("X-Consul-Token"))
+}
+
+func TestConfig_Transport(t *testing.T) {
+	cfg := Config{}
+	fs := flag.NewFlagSet("", flag.PanicOnError)
+	cfg.RegisterFlags(fs, "")
+	require.NoError(t, fs.Parse(nil))
+
+	transport := cfg.transport()
+	assert.Equal(t, 100, transport.MaxIdleConns)
+	assert.Equal(t, runtime.GOMAXPROCS(0)+1, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 90*time.Second, transport.IdleConnTimeout)
+
+	cfg.MaxIdleConns = 10
+	cfg.MaxIdleConnsPerHost = 5
+	cfg.IdleConnTimeout = time.Minute
+
+	transport = cfg.transport()
+	assert.Equal(t, 10, transport.MaxIdleConns)
+	assert.Equal(t, 5, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+}
+
 type testLogger struct {
 }
 
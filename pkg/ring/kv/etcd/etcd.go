@@ -27,11 +27,16 @@ type Config struct {
 	EnableTLS   bool                   `yaml:"tls_enabled"`
 	TLS         cortextls.ClientConfig `yaml:",inline"`
 
-	UserName            string `yaml:"username"`
-	Password            string `yaml:"password"`
-	PermitWithoutStream bool   `yaml:"ping-without-stream-allowed"`
+	UserName            string         `yaml:"username"`
+	Password            flagext.Secret `yaml:"password"`
+	PermitWithoutStream bool           `yaml:"ping-without-stream-allowed"`
 }
 
+var (
+	errPasswordWithoutUsername = errors.New("the etcd password is configured but the username is not")
+	errTLSNotEnabled           = errors.New("the etcd TLS certificates are configured but TLS is not enabled")
+)
+
 // Clientv3Facade is a subset of all Etcd client operations that are required
 // to implement an Etcd version of kv.Client
 type Clientv3Facade interface {
@@ -59,11 +64,22 @@ func (cfg *Config) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
 	f.IntVar(&cfg.MaxRetries, prefix+"etcd.max-retries", 10, "The maximum number of retries to do for failed ops.")
 	f.BoolVar(&cfg.EnableTLS, prefix+"etcd.tls-enabled", false, "Enable TLS.")
 	f.StringVar(&cfg.UserName, prefix+"etcd.username", "", "Etcd username.")
-	f.StringVar(&cfg.Password, prefix+"etcd.password", "", "Etcd password.")
+	f.Var(&cfg.Password, prefix+"etcd.password", "Etcd password.")
 	f.BoolVar(&cfg.PermitWithoutStream, prefix+"etcd.ping-without-stream-allowed", true, "Send Keepalive pings with no streams.")
 	cfg.TLS.RegisterFlagsWithPrefix(prefix+"etcd", f)
 }
 
+// Validate the config.
+func (cfg *Config) Validate() error {
+	if cfg.Password.Value != "" && cfg.UserName == "" {
+		return errPasswordWithoutUsername
+	}
+	if !cfg.EnableTLS && (cfg.TLS.CertPath != "" || cfg.TLS.KeyPath != "" || cfg.TLS.CAPath != "") {
+		return errTLSNotEnabled
+	}
+	return nil
+}
+
 // GetTLS sets the TLS config field with certs
 func (cfg *Config) GetTLS() (*tls.Config, error) {
 	if !cfg.EnableTLS {
@@ -81,6 +97,12 @@ func (cfg *Config) GetTLS() (*tls.Config, error) {
 
 // New makes a new Client.
 func New(cfg Config, codec codec.Codec, logger log.Logger) (*Client, error) {
+	// The invalid configs used to be silently accepted, so they're only logged
+	// for now, to not prevent existing deployments from starting.
+	if err := cfg.Validate(); err != nil {
+		level.Warn(logger).Log("msg", "invalid etcd configuration, this will be an error in a future release", "err", err)
+	}
+
 	tlsConfig, err := cfg.GetTLS()
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to initialise TLS configuration for etcd")
@@ -107,7 +129,7 @@ func New(cfg Config, codec codec.Codec, logger log.Logger) (*Client, error) {
 		PermitWithoutStream:  cfg.PermitWithoutStream,
 		TLS:                  tlsConfig,
 		Username:             cfg.UserName,
-		Password:             cfg.Password,
+		Password:             cfg.Password.Value,
 	})
 	if err != nil {
 		return nil, err
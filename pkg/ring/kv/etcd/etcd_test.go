@@ -0,0 +1,70 @@
+package etcd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-kit/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	cortextls "github.com/cortexproject/cortex/pkg/util/tls"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		cfg      Config
+		expected error
+	}{
+		"default config": {
+			cfg:      Config{},
+			expected: nil,
+		},
+		"username and password": {
+			cfg:      Config{UserName: "user", Password: flagext.Secret{Value: "pass"}},
+			expected: nil,
+		},
+		"password without username": {
+			cfg:      Config{Password: flagext.Secret{Value: "pass"}},
+			expected: errPasswordWithoutUsername,
+		},
+		"TLS enabled with certificates": {
+			cfg:      Config{EnableTLS: true, TLS: cortextls.ClientConfig{CertPath: "cert", KeyPath: "key", CAPath: "ca"}},
+			expected: nil,
+		},
+		"TLS certificates without TLS enabled": {
+			cfg:      Config{TLS: cortextls.ClientConfig{CAPath: "ca"}},
+			expected: errTLSNotEnabled,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expected, testData.cfg.Validate())
+		})
+	}
+}
+
+func TestNew_ShouldWarnAboutAnInvalidConfig(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := Config{Endpoints: []string{"localhost:2379"}, TLS: cortextls.ClientConfig{CAPath: "ca"}}
+
+	client, err := New(cfg, codec.NewProtoCodec("test", nil), log.NewLogfmtLogger(&buf))
+	require.NoError(t, err)
+	defer client.cli.Close() //nolint:errcheck
+
+	assert.Contains(t, buf.String(), errTLSNotEnabled.Error())
+}
+
+func TestConfig_ShouldNotExposeThePassword(t *testing.T) {
+	cfg := Config{UserName: "user", Password: flagext.Secret{Value: "pass"}}
+
+	out, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "pass\n")
+	assert.Contains(t, string(out), "password: '********'")
+}
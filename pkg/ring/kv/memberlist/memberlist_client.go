@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"math"
 	mathrand "math/rand"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -161,6 +162,10 @@ type KVConfig struct {
 	// How much space to use to keep received and sent messages in memory (for troubleshooting).
 	MessageHistoryBufferBytes int `yaml:"message_history_buffer_bytes"`
 
+	// Label of the memberlist cluster, used to reject messages from members of other clusters.
+	ClusterLabel                     string `yaml:"cluster_label"`
+	ClusterLabelVerificationDisabled bool   `yaml:"cluster_label_verification_disabled"`
+
 	TCPTransport TCPTransportConfig `yaml:",inline"`
 
 	// Where to put custom metrics. Metrics are not registered, if this is nil.
@@ -197,6 +202,8 @@ func (cfg *KVConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
 	f.BoolVar(&cfg.EnableCompression, prefix+"memberlist.compression-enabled", mlDefaults.EnableCompression, "Enable message compression. This can be used to reduce bandwidth usage at the cost of slightly more CPU utilization.")
 	f.StringVar(&cfg.AdvertiseAddr, prefix+"memberlist.advertise-addr", mlDefaults.AdvertiseAddr, "Gossip address to advertise to other members in the cluster. Used for NAT traversal.")
 	f.IntVar(&cfg.AdvertisePort, prefix+"memberlist.advertise-port", mlDefaults.AdvertisePort, "Gossip port to advertise to other members in the cluster. Used for NAT traversal.")
+	f.StringVar(&cfg.ClusterLabel, prefix+"memberlist.cluster-label", "", "The cluster label is an optional string to include in outbound packets and gossip streams. Other members in the memberlist cluster will discard any message whose label doesn't match the configured one, unless -memberlist.cluster-label-verification-disabled is set. It prevents members of different Cortex clusters, sharing the same network, from accidentally gossiping with each other.")
+	f.BoolVar(&cfg.ClusterLabelVerificationDisabled, prefix+"memberlist.cluster-label-verification-disabled", false, "When enabled, memberlist doesn't verify that inbound packets and gossip streams have the cluster label matching the configured one. Verification should be disabled while rolling out a change to the cluster label of a running memberlist cluster.")
 
 	cfg.TCPTransport.RegisterFlagsWithPrefix(f, prefix)
 }
@@ -205,6 +212,20 @@ func (cfg *KVConfig) RegisterFlags(f *flag.FlagSet) {
 	cfg.RegisterFlagsWithPrefix(f, "")
 }
 
+var (
+	errInvalidClusterLabel = fmt.Errorf("invalid memberlist cluster label, it must only contain letters, digits, '-', '_' and '.', and be at most %d characters long", memberlist.LabelMaxSize)
+
+	clusterLabelRegexp = regexp.MustCompile(`^[0-9A-Za-z_.-]*$`)
+)
+
+// Validate the config.
+func (cfg *KVConfig) Validate() error {
+	if len(cfg.ClusterLabel) > memberlist.LabelMaxSize || !clusterLabelRegexp.MatchString(cfg.ClusterLabel) {
+		return errInvalidClusterLabel
+	}
+	return cfg.TCPTransport.Validate()
+}
+
 func generateRandomSuffix(logger log.Logger) string {
 	suffix := make([]byte, 4)
 	_, err := rand.Read(suffix)
@@ -391,6 +412,9 @@ func (m *KV) buildMemberlistConfig() (*memberlist.Config, error) {
 	mlCfg.AdvertiseAddr = m.cfg.AdvertiseAddr
 	mlCfg.AdvertisePort = m.cfg.AdvertisePort
 
+	mlCfg.Label = m.cfg.ClusterLabel
+	mlCfg.SkipInboundLabelCheck = m.cfg.ClusterLabelVerificationDisabled
+
 	if m.cfg.NodeName != "" {
 		mlCfg.Name = m.cfg.NodeName
 	}
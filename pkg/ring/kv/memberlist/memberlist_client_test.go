@@ -11,11 +11,13 @@ import (
 	"net"
 	"reflect"
 	"sort"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-kit/log"
+	"github.com/hashicorp/memberlist"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1119,6 +1121,92 @@ func TestRejoin(t *testing.T) {
 	poll(t, 1*time.Minute, 2, membersFunc)
 }
 
+func TestClusterLabel(t *testing.T) {
+	tests := map[string]struct {
+		label1, label2       string
+		verificationDisabled bool
+		expectedJoin         bool
+	}{
+		"members with the same cluster label should join": {
+			label1:       "cluster-1",
+			label2:       "cluster-1",
+			expectedJoin: true,
+		},
+		"members with different cluster labels should not join": {
+			label1:       "cluster-1",
+			label2:       "cluster-2",
+			expectedJoin: false,
+		},
+		"a member without cluster label should not join a member with cluster label": {
+			label1:       "cluster-1",
+			label2:       "",
+			expectedJoin: false,
+		},
+		"members with different cluster labels should join if the verification is disabled": {
+			label1:               "cluster-1",
+			label2:               "",
+			verificationDisabled: true,
+			expectedJoin:         true,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ports, err := getFreePorts(2)
+			require.NoError(t, err)
+
+			var cfg1 KVConfig
+			flagext.DefaultValues(&cfg1)
+			cfg1.TCPTransport = TCPTransportConfig{
+				BindAddrs: []string{"localhost"},
+				BindPort:  ports[0],
+			}
+			cfg1.Codecs = []codec.Codec{dataCodec{}}
+			cfg1.ClusterLabel = testData.label1
+			cfg1.ClusterLabelVerificationDisabled = testData.verificationDisabled
+
+			cfg2 := cfg1
+			cfg2.TCPTransport.BindPort = ports[1]
+			cfg2.ClusterLabel = testData.label2
+
+			mkv1 := NewKV(cfg1, log.NewNopLogger(), &dnsProviderMock{}, prometheus.NewPedanticRegistry())
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), mkv1))
+			defer services.StopAndAwaitTerminated(context.Background(), mkv1) //nolint:errcheck
+
+			mkv2 := NewKV(cfg2, log.NewNopLogger(), &dnsProviderMock{}, prometheus.NewPedanticRegistry())
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), mkv2))
+			defer services.StopAndAwaitTerminated(context.Background(), mkv2) //nolint:errcheck
+
+			_, err = mkv2.JoinMembers([]string{fmt.Sprintf("localhost:%d", ports[0])})
+			if testData.expectedJoin {
+				require.NoError(t, err)
+				poll(t, 10*time.Second, 2, func() interface{} {
+					return mkv1.memberlist.NumMembers()
+				})
+			} else {
+				require.Error(t, err)
+				assert.Equal(t, 1, mkv1.memberlist.NumMembers())
+				assert.Equal(t, 1, mkv2.memberlist.NumMembers())
+			}
+		})
+	}
+}
+
+func TestKVConfig_Validate(t *testing.T) {
+	cfg := KVConfig{}
+	flagext.DefaultValues(&cfg)
+	require.NoError(t, cfg.Validate())
+
+	cfg.ClusterLabel = "cortex-prod_1.eu"
+	require.NoError(t, cfg.Validate())
+
+	cfg.ClusterLabel = "cortex prod"
+	require.Equal(t, errInvalidClusterLabel, cfg.Validate())
+
+	cfg.ClusterLabel = strings.Repeat("a", memberlist.LabelMaxSize+1)
+	require.Equal(t, errInvalidClusterLabel, cfg.Validate())
+}
+
 func TestMessageBuffer(t *testing.T) {
 	buf := []message(nil)
 	size := 0
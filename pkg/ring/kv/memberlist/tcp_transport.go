@@ -59,8 +59,17 @@ type TCPTransportConfig struct {
 
 	TLSEnabled bool                   `yaml:"tls_enabled"`
 	TLS        cortextls.ClientConfig `yaml:",inline"`
+
+	// Require the incoming connections to present a certificate signed by the configured CA.
+	TLSClientAuthEnabled bool `yaml:"tls_client_auth_enabled"`
 }
 
+var (
+	errTLSClientAuthWithoutTLS  = errors.New("memberlist TLS client authentication requires TLS to be enabled")
+	errTLSClientAuthWithoutCA   = errors.New("memberlist TLS client authentication requires the CA certificates file to be configured")
+	errTLSClientAuthWithoutCert = errors.New("memberlist TLS client authentication requires the certificate and key files to be configured")
+)
+
 func (cfg *TCPTransportConfig) RegisterFlags(f *flag.FlagSet) {
 	cfg.RegisterFlagsWithPrefix(f, "")
 }
@@ -76,6 +85,24 @@ func (cfg *TCPTransportConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix s
 
 	f.BoolVar(&cfg.TLSEnabled, prefix+"memberlist.tls-enabled", false, "Enable TLS on the memberlist transport layer.")
 	cfg.TLS.RegisterFlagsWithPrefix(prefix+"memberlist", f)
+	f.BoolVar(&cfg.TLSClientAuthEnabled, prefix+"memberlist.tls-client-auth-enabled", false, "Require the incoming connections on the memberlist transport layer to present a certificate signed by the CA certificates file, for mutual TLS authentication. The certificate and key files are presented by the outgoing connections, so all the members must enable it together.")
+}
+
+// Validate the config.
+func (cfg *TCPTransportConfig) Validate() error {
+	if !cfg.TLSClientAuthEnabled {
+		return nil
+	}
+	if !cfg.TLSEnabled {
+		return errTLSClientAuthWithoutTLS
+	}
+	if cfg.TLS.CAPath == "" {
+		return errTLSClientAuthWithoutCA
+	}
+	if cfg.TLS.CertPath == "" || cfg.TLS.KeyPath == "" {
+		return errTLSClientAuthWithoutCert
+	}
+	return nil
 }
 
 // TCPTransport is a memberlist.Transport implementation that uses TCP for both packet and stream
@@ -125,12 +152,24 @@ func NewTCPTransport(config TCPTransportConfig, logger log.Logger) (*TCPTranspor
 		connCh:   make(chan net.Conn),
 	}
 
-	var err error
+	var (
+		err               error
+		listenerTLSConfig *tls.Config
+	)
 	if config.TLSEnabled {
 		t.tlsConfig, err = config.TLS.GetTLSConfig()
 		if err != nil {
 			return nil, errors.Wrap(err, "unable to create TLS config")
 		}
+
+		listenerTLSConfig = t.tlsConfig
+		if config.TLSClientAuthEnabled {
+			// The certificates of the incoming connections are verified against the same CA
+			// as the certificates of the members we connect to.
+			listenerTLSConfig = t.tlsConfig.Clone()
+			listenerTLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+			listenerTLSConfig.ClientCAs = t.tlsConfig.RootCAs
+		}
 	}
 
 	t.registerMetrics(config.MetricsRegisterer)
@@ -151,7 +190,7 @@ func NewTCPTransport(config TCPTransportConfig, logger log.Logger) (*TCPTranspor
 
 		var tcpLn net.Listener
 		if config.TLSEnabled {
-			tcpLn, err = tls.Listen("tcp", tcpAddr.String(), t.tlsConfig)
+			tcpLn, err = tls.Listen("tcp", tcpAddr.String(), listenerTLSConfig)
 			if err != nil {
 				return nil, errors.Wrapf(err, "failed to start TLS TCP listener on %q port %d", addr, port)
 			}
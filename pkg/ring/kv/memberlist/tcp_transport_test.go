@@ -1,12 +1,19 @@
 package memberlist
 
 import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/cortexproject/cortex/integration/ca"
 	"github.com/cortexproject/cortex/pkg/util/concurrency"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
 )
@@ -59,3 +66,92 @@ func TestTCPTransport_WriteTo_ShouldNotLogAsWarningExpectedFailures(t *testing.T
 		})
 	}
 }
+
+func TestTCPTransportConfig_Validate(t *testing.T) {
+	cfg := TCPTransportConfig{}
+	flagext.DefaultValues(&cfg)
+	require.NoError(t, cfg.Validate())
+
+	cfg.TLSClientAuthEnabled = true
+	require.Equal(t, errTLSClientAuthWithoutTLS, cfg.Validate())
+
+	cfg.TLSEnabled = true
+	require.Equal(t, errTLSClientAuthWithoutCA, cfg.Validate())
+
+	cfg.TLS.CAPath = "ca.crt"
+	require.Equal(t, errTLSClientAuthWithoutCert, cfg.Validate())
+
+	cfg.TLS.CertPath = "member.crt"
+	cfg.TLS.KeyPath = "member.key"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestTCPTransport_TLSClientAuth(t *testing.T) {
+	dir := t.TempDir()
+
+	// Generate the certificates of the members, signed by the trusted CA, and the
+	// certificate of an intruder, signed by another CA.
+	trustedCA := ca.New("Memberlist Test")
+	require.NoError(t, trustedCA.WriteCACertificate(filepath.Join(dir, "ca.crt")))
+	untrustedCA := ca.New("Memberlist Test Intruder")
+
+	writeCertificate := func(issuer *ca.CA, name string) {
+		require.NoError(t, issuer.WriteCertificate(
+			&x509.Certificate{
+				Subject:     pkix.Name{CommonName: name},
+				DNSNames:    []string{"localhost"},
+				IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+			},
+			filepath.Join(dir, name+".crt"),
+			filepath.Join(dir, name+".key"),
+		))
+	}
+	writeCertificate(trustedCA, "member-1")
+	writeCertificate(trustedCA, "member-2")
+	writeCertificate(untrustedCA, "intruder")
+
+	newTransport := func(name string) *TCPTransport {
+		cfg := TCPTransportConfig{}
+		flagext.DefaultValues(&cfg)
+		cfg.BindAddrs = []string{"localhost"}
+		cfg.BindPort = 0
+		cfg.TLSEnabled = true
+		cfg.TLSClientAuthEnabled = true
+		cfg.TLS.CAPath = filepath.Join(dir, "ca.crt")
+		cfg.TLS.CertPath = filepath.Join(dir, name+".crt")
+		cfg.TLS.KeyPath = filepath.Join(dir, name+".key")
+		require.NoError(t, cfg.Validate())
+
+		transport, err := NewTCPTransport(cfg, log.NewNopLogger())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = transport.Shutdown() })
+		return transport
+	}
+
+	member1 := newTransport("member-1")
+	member2 := newTransport("member-2")
+	intruder := newTransport("intruder")
+	member1Addr := fmt.Sprintf("localhost:%d", member1.GetAutoBindPort())
+
+	// A member presenting a certificate signed by the trusted CA can gossip.
+	_, err := member2.WriteTo([]byte("from member-2"), member1Addr)
+	require.NoError(t, err)
+
+	select {
+	case packet := <-member1.PacketCh():
+		assert.Equal(t, "from member-2", string(packet.Buf))
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the packet of the trusted member has not been received")
+	}
+
+	// The connections of the intruder are rejected.
+	_, err = intruder.WriteTo([]byte("from intruder"), member1Addr)
+	require.NoError(t, err)
+
+	select {
+	case packet := <-member1.PacketCh():
+		require.Fail(t, "the packet of the intruder has been received", string(packet.Buf))
+	case <-time.After(time.Second):
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/weaveworks/common/httpgrpc"
@@ -42,27 +44,51 @@ func getCasErrorCode(err error) string {
 
 type metrics struct {
 	c               Client
+	backend         string
 	requestDuration *instrument.HistogramCollector
+	casRetries      prometheus.Counter
+	slowRequests    *prometheus.CounterVec
+
+	// slowRequestThreshold is the duration after which a request, or a watch which
+	// didn't receive any update, is logged as slow. Disabled if 0.
+	slowRequestThreshold time.Duration
+	logger               log.Logger
 }
 
-func newMetricsClient(backend string, c Client, reg prometheus.Registerer) Client {
+func newMetricsClient(backend string, c Client, slowRequestThreshold time.Duration, reg prometheus.Registerer, logger log.Logger) Client {
+	constLabels := prometheus.Labels{
+		"type": backend,
+	}
+
 	return &metrics{
-		c: c,
+		c:       c,
+		backend: backend,
 		requestDuration: instrument.NewHistogramCollector(
 			promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
-				Name:    "kv_request_duration_seconds",
-				Help:    "Time spent on kv store requests.",
-				Buckets: prometheus.DefBuckets,
-				ConstLabels: prometheus.Labels{
-					"type": backend,
-				},
+				Name:        "kv_request_duration_seconds",
+				Help:        "Time spent on kv store requests.",
+				Buckets:     prometheus.DefBuckets,
+				ConstLabels: constLabels,
 			}, []string{"operation", "status_code"}),
 		),
+		casRetries: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name:        "kv_cas_retries_total",
+			Help:        "Total number of CAS attempts retried because of a concurrent update of the key or a failed attempt.",
+			ConstLabels: constLabels,
+		}),
+		slowRequests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name:        "kv_slow_requests_total",
+			Help:        "Total number of kv store requests slower than the configured threshold, and of watches which didn't receive any update for longer than the configured threshold.",
+			ConstLabels: constLabels,
+		}, []string{"operation"}),
+		slowRequestThreshold: slowRequestThreshold,
+		logger:               logger,
 	}
 }
 
 func (m metrics) List(ctx context.Context, prefix string) ([]string, error) {
 	var result []string
+	defer m.logSlowRequest("List", prefix, time.Now())
 	err := instrument.CollectedRequest(ctx, "List", m.requestDuration, instrument.ErrorCode, func(ctx context.Context) error {
 		var err error
 		result, err = m.c.List(ctx, prefix)
@@ -73,6 +99,7 @@ func (m metrics) List(ctx context.Context, prefix string) ([]string, error) {
 
 func (m metrics) Get(ctx context.Context, key string) (interface{}, error) {
 	var result interface{}
+	defer m.logSlowRequest("GET", key, time.Now())
 	err := instrument.CollectedRequest(ctx, "GET", m.requestDuration, instrument.ErrorCode, func(ctx context.Context) error {
 		var err error
 		result, err = m.c.Get(ctx, key)
@@ -82,6 +109,7 @@ func (m metrics) Get(ctx context.Context, key string) (interface{}, error) {
 }
 
 func (m metrics) Delete(ctx context.Context, key string) error {
+	defer m.logSlowRequest("Delete", key, time.Now())
 	err := instrument.CollectedRequest(ctx, "Delete", m.requestDuration, instrument.ErrorCode, func(ctx context.Context) error {
 		return m.c.Delete(ctx, key)
 	})
@@ -89,21 +117,45 @@ func (m metrics) Delete(ctx context.Context, key string) error {
 }
 
 func (m metrics) CAS(ctx context.Context, key string, f func(in interface{}) (out interface{}, retry bool, err error)) error {
+	// Track how many times the callback is called, to expose the contention on the key.
+	attempts := 0
+	defer func(start time.Time) {
+		if attempts > 1 {
+			m.casRetries.Add(float64(attempts - 1))
+		}
+		m.logSlowRequest("CAS", key, start, "attempts", attempts)
+	}(time.Now())
+
 	return instrument.CollectedRequest(ctx, "CAS", m.requestDuration, getCasErrorCode, func(ctx context.Context) error {
-		return m.c.CAS(ctx, key, f)
+		return m.c.CAS(ctx, key, func(in interface{}) (out interface{}, retry bool, err error) {
+			attempts++
+			return f(in)
+		})
 	})
 }
 
 func (m metrics) WatchKey(ctx context.Context, key string, f func(interface{}) bool) {
+	updated, stop := m.trackStalledWatch(ctx, "WatchKey", key)
+	defer stop()
+
 	_ = instrument.CollectedRequest(ctx, "WatchKey", m.requestDuration, instrument.ErrorCode, func(ctx context.Context) error {
-		m.c.WatchKey(ctx, key, f)
+		m.c.WatchKey(ctx, key, func(value interface{}) bool {
+			updated()
+			return f(value)
+		})
 		return nil
 	})
 }
 
 func (m metrics) WatchPrefix(ctx context.Context, prefix string, f func(string, interface{}) bool) {
+	updated, stop := m.trackStalledWatch(ctx, "WatchPrefix", prefix)
+	defer stop()
+
 	_ = instrument.CollectedRequest(ctx, "WatchPrefix", m.requestDuration, instrument.ErrorCode, func(ctx context.Context) error {
-		m.c.WatchPrefix(ctx, prefix, f)
+		m.c.WatchPrefix(ctx, prefix, func(key string, value interface{}) bool {
+			updated()
+			return f(key, value)
+		})
 		return nil
 	})
 }
@@ -111,3 +163,68 @@ func (m metrics) WatchPrefix(ctx context.Context, prefix string, f func(string,
 func (m metrics) LastUpdateTime(key string) time.Time {
 	return m.c.LastUpdateTime(key)
 }
+
+// logSlowRequest logs the request started at the given time, if it's slower than the configured threshold.
+func (m metrics) logSlowRequest(operation, key string, start time.Time, keyvals ...interface{}) {
+	elapsed := time.Since(start)
+	if m.slowRequestThreshold <= 0 || elapsed <= m.slowRequestThreshold {
+		return
+	}
+
+	m.slowRequests.WithLabelValues(operation).Inc()
+	level.Warn(m.logger).Log(append([]interface{}{"msg", "slow kv store request", "type", m.backend, "operation", operation, "key", key, "duration", elapsed}, keyvals...)...)
+}
+
+// trackStalledWatch logs the watch once it didn't receive any update for longer than the configured
+// threshold, and again after each update followed by a stall. The returned updated function must be
+// called on each update, and stop once the watch is over.
+func (m metrics) trackStalledWatch(ctx context.Context, operation, key string) (updated func(), stop func()) {
+	if m.slowRequestThreshold <= 0 {
+		return func() {}, func() {}
+	}
+
+	updates := make(chan struct{}, 1)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+
+		lastUpdate := time.Now()
+		timer := time.NewTimer(m.slowRequestThreshold)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-updates:
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(m.slowRequestThreshold)
+				lastUpdate = time.Now()
+			case <-timer.C:
+				m.slowRequests.WithLabelValues(operation).Inc()
+				level.Warn(m.logger).Log("msg", "kv store watch didn't receive any update for longer than the threshold", "type", m.backend, "operation", operation, "key", key, "duration", time.Since(lastUpdate))
+			}
+		}
+	}()
+
+	updated = func() {
+		select {
+		case updates <- struct{}{}:
+		default:
+		}
+	}
+	stop = func() {
+		close(done)
+		<-exited
+	}
+	return updated, stop
+}
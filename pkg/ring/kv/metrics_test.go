@@ -0,0 +1,153 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowClient is a mockClient whose operations take the configured delay, and whose
+// CAS calls the callback the configured number of times.
+type slowClient struct {
+	mockClient
+	delay        time.Duration
+	casAttempts  int
+	watchUpdates int
+}
+
+func (c slowClient) Get(_ context.Context, _ string) (interface{}, error) {
+	time.Sleep(c.delay)
+	return nil, nil
+}
+
+func (c slowClient) CAS(_ context.Context, _ string, f func(in interface{}) (out interface{}, retry bool, err error)) error {
+	for i := 0; i < c.casAttempts; i++ {
+		if _, _, err := f(nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c slowClient) WatchKey(_ context.Context, _ string, f func(interface{}) bool) {
+	for i := 0; i < c.watchUpdates; i++ {
+		time.Sleep(c.delay)
+		if !f(nil) {
+			return
+		}
+	}
+}
+
+// stalledClient is a mockClient whose watches never receive any update.
+type stalledClient struct {
+	mockClient
+}
+
+func (c stalledClient) WatchPrefix(ctx context.Context, _ string, _ func(string, interface{}) bool) {
+	<-ctx.Done()
+}
+
+func TestMetricsClient_CASRetries(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	c := newMetricsClient("mock", slowClient{casAttempts: 3}, 0, reg, log.NewNopLogger())
+
+	calls := 0
+	require.NoError(t, c.CAS(context.Background(), "key", func(in interface{}) (out interface{}, retry bool, err error) {
+		calls++
+		return nil, true, nil
+	}))
+	require.NoError(t, c.CAS(context.Background(), "key", func(in interface{}) (out interface{}, retry bool, err error) {
+		calls++
+		return nil, true, nil
+	}))
+	assert.Equal(t, 6, calls)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP kv_cas_retries_total Total number of CAS attempts retried because of a concurrent update of the key or a failed attempt.
+		# TYPE kv_cas_retries_total counter
+		kv_cas_retries_total{type="mock"} 4
+	`), "kv_cas_retries_total"))
+}
+
+func TestMetricsClient_SlowRequestLog(t *testing.T) {
+	tests := map[string]struct {
+		threshold       time.Duration
+		expectedLogs    []string
+		expectedMetrics string
+	}{
+		"disabled": {
+			threshold: 0,
+			expectedMetrics: `
+				# HELP kv_slow_requests_total Total number of kv store requests slower than the configured threshold, and of watches which didn't receive any update for longer than the configured threshold.
+				# TYPE kv_slow_requests_total counter
+			`,
+		},
+		"requests faster than the threshold": {
+			threshold: time.Minute,
+			expectedMetrics: `
+				# HELP kv_slow_requests_total Total number of kv store requests slower than the configured threshold, and of watches which didn't receive any update for longer than the configured threshold.
+				# TYPE kv_slow_requests_total counter
+			`,
+		},
+		"requests slower than the threshold": {
+			threshold: 5 * time.Millisecond,
+			expectedLogs: []string{
+				`msg="slow kv store request" type=mock operation=GET key=key`,
+				`msg="kv store watch didn't receive any update for longer than the threshold" type=mock operation=WatchKey key=key`,
+			},
+			expectedMetrics: `
+				# HELP kv_slow_requests_total Total number of kv store requests slower than the configured threshold, and of watches which didn't receive any update for longer than the configured threshold.
+				# TYPE kv_slow_requests_total counter
+				kv_slow_requests_total{operation="GET",type="mock"} 1
+				kv_slow_requests_total{operation="WatchKey",type="mock"} 2
+			`,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			reg := prometheus.NewPedanticRegistry()
+			c := newMetricsClient("mock", slowClient{delay: 10 * time.Millisecond, watchUpdates: 2}, testData.threshold, reg, log.NewLogfmtLogger(buf))
+
+			_, err := c.Get(context.Background(), "key")
+			require.NoError(t, err)
+			c.WatchKey(context.Background(), "key", func(interface{}) bool { return true })
+
+			if len(testData.expectedLogs) == 0 {
+				assert.Empty(t, buf.String())
+			}
+			for _, expected := range testData.expectedLogs {
+				assert.Contains(t, buf.String(), expected)
+			}
+			assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(testData.expectedMetrics), "kv_slow_requests_total"))
+		})
+	}
+}
+
+func TestMetricsClient_StalledWatchLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	reg := prometheus.NewPedanticRegistry()
+	c := newMetricsClient("mock", stalledClient{}, 10*time.Millisecond, reg, log.NewLogfmtLogger(buf))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// The watch is logged while stalled, even if it never receives any update.
+	c.WatchPrefix(ctx, "prefix", func(string, interface{}) bool { return true })
+
+	assert.Contains(t, buf.String(), `msg="kv store watch didn't receive any update for longer than the threshold" type=mock operation=WatchPrefix key=prefix`)
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP kv_slow_requests_total Total number of kv store requests slower than the configured threshold, and of watches which didn't receive any update for longer than the configured threshold.
+		# TYPE kv_slow_requests_total counter
+		kv_slow_requests_total{operation="WatchPrefix",type="mock"} 1
+	`), "kv_slow_requests_total"))
+}
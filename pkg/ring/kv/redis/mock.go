@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"io"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-kit/log"
+	"github.com/go-redis/redis/v8"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+)
+
+// NewInMemoryClient creates a Redis Client implementation backed by an in-memory Redis server.
+func NewInMemoryClient(codec codec.Codec, logger log.Logger) (*Client, io.Closer, error) {
+	server, err := miniredis.Run()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Make sure to set default values for the config including number of retries,
+	// otherwise the client won't even attempt a CAS operation. The in-memory server
+	// is cheap to poll, so the watches poll it frequently.
+	cfg := Config{}
+	flagext.DefaultValues(&cfg)
+	cfg.Endpoint = server.Addr()
+	cfg.WatchPollInterval = 10 * time.Millisecond
+
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	client := newClient(cfg, codec, rdb, logger)
+
+	return client, closer{rdb: rdb, server: server}, nil
+}
+
+type closer struct {
+	rdb    *redis.Client
+	server *miniredis.Miniredis
+}
+
+func (c closer) Close() error {
+	err := c.rdb.Close()
+	c.server.Close()
+	return err
+}
@@ -0,0 +1,372 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/util/backoff"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+)
+
+const (
+	// scanCount is the number of keys requested to Redis for each SCAN iteration.
+	scanCount = 100
+)
+
+var (
+	errMissingEndpoint = errors.New("the Redis endpoint is required")
+	errInvalidInterval = errors.New("the Redis watch poll interval must be greater than 0")
+)
+
+// Config for a new redis.Client.
+type Config struct {
+	Endpoint           string         `yaml:"endpoint"`
+	MasterName         string         `yaml:"master_name"`
+	DB                 int            `yaml:"db"`
+	Password           flagext.Secret `yaml:"password"`
+	Timeout            time.Duration  `yaml:"timeout"`
+	MaxRetries         int            `yaml:"max_retries"`
+	WatchPollInterval  time.Duration  `yaml:"watch_poll_interval"`
+	EnableTLS          bool           `yaml:"tls_enabled"`
+	InsecureSkipVerify bool           `yaml:"tls_insecure_skip_verify"`
+}
+
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	cfg.RegisterFlagsWithPrefix(f, "")
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet.
+func (cfg *Config) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
+	f.StringVar(&cfg.Endpoint, prefix+"redis.endpoint", "", "Redis Server endpoint to use as KV store. A comma-separated list of endpoints for Redis Cluster or Redis Sentinel.")
+	f.StringVar(&cfg.MasterName, prefix+"redis.master-name", "", "Redis Sentinel master name. An empty string for Redis Server or Redis Cluster.")
+	f.IntVar(&cfg.DB, prefix+"redis.db", 0, "Database index.")
+	f.Var(&cfg.Password, prefix+"redis.password", "Password to use when connecting to Redis.")
+	f.DurationVar(&cfg.Timeout, prefix+"redis.timeout", 5*time.Second, "Maximum time to wait before giving up on Redis requests.")
+	f.IntVar(&cfg.MaxRetries, prefix+"redis.max-retries", 10, "The maximum number of retries to do for failed CAS operations.")
+	f.DurationVar(&cfg.WatchPollInterval, prefix+"redis.watch-poll-interval", time.Second, "How frequently the watched keys are polled for changes.")
+	f.BoolVar(&cfg.EnableTLS, prefix+"redis.tls-enabled", false, "Enable connecting to Redis with TLS.")
+	f.BoolVar(&cfg.InsecureSkipVerify, prefix+"redis.tls-insecure-skip-verify", false, "Skip validating server certificate.")
+}
+
+// Validate the config.
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errMissingEndpoint
+	}
+	if cfg.WatchPollInterval <= 0 {
+		return errInvalidInterval
+	}
+	return nil
+}
+
+// Client implements kv.Client for Redis. The CAS operations are implemented with optimistic
+// locking (WATCH/MULTI/EXEC), while the watches periodically poll the watched keys, so that
+// no keyspace notification has to be enabled on the Redis server.
+type Client struct {
+	cfg    Config
+	codec  codec.Codec
+	rdb    redis.UniversalClient
+	logger log.Logger
+}
+
+// New makes a new Client.
+func New(cfg Config, codec codec.Codec, logger log.Logger) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid Redis configuration")
+	}
+
+	opt := &redis.UniversalOptions{
+		Addrs:        strings.Split(cfg.Endpoint, ","),
+		MasterName:   cfg.MasterName,
+		Password:     cfg.Password.Value,
+		DB:           cfg.DB,
+		DialTimeout:  cfg.Timeout,
+		ReadTimeout:  cfg.Timeout,
+		WriteTimeout: cfg.Timeout,
+	}
+	if cfg.EnableTLS {
+		opt.TLSConfig = &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	}
+
+	return newClient(cfg, codec, redis.NewUniversalClient(opt), logger), nil
+}
+
+func newClient(cfg Config, codec codec.Codec, rdb redis.UniversalClient, logger log.Logger) *Client {
+	return &Client{
+		cfg:    cfg,
+		codec:  codec,
+		rdb:    rdb,
+		logger: logger,
+	}
+}
+
+// CAS implements kv.Client.
+func (c *Client) CAS(ctx context.Context, key string, f func(in interface{}) (out interface{}, retry bool, err error)) error {
+	var lastErr error
+
+	for i := 0; i < c.cfg.MaxRetries; i++ {
+		var done bool
+
+		err := c.rdb.Watch(ctx, func(tx *redis.Tx) error {
+			var intermediate interface{}
+
+			buf, err := tx.Get(ctx, key).Bytes()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return errors.Wrap(err, "get key")
+			}
+			if err == nil {
+				if intermediate, err = c.codec.Decode(buf); err != nil {
+					return errors.Wrap(err, "decode key")
+				}
+			}
+
+			var retry bool
+			intermediate, retry, err = f(intermediate)
+			if err != nil {
+				if !retry {
+					done = true
+				}
+				return err
+			}
+
+			// Callback returning nil means it doesn't want to CAS anymore.
+			if intermediate == nil {
+				done = true
+				return nil
+			}
+
+			if buf, err = c.codec.Encode(intermediate); err != nil {
+				return errors.Wrap(err, "encode key")
+			}
+
+			// The transaction fails if the key has been modified since it has been watched.
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, buf, 0)
+				return nil
+			})
+			if err == nil {
+				done = true
+			}
+			return err
+		}, key)
+
+		if done {
+			return err
+		}
+		if errors.Is(err, redis.TxFailedErr) {
+			level.Debug(c.logger).Log("msg", "failed to CAS, the key has been modified in Redis", "key", key)
+			continue
+		}
+		if err != nil {
+			level.Error(c.logger).Log("msg", "error CASing", "key", key, "err", err)
+			lastErr = err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	if lastErr != nil {
+		return lastErr
+	}
+	return fmt.Errorf("failed to CAS %s", key)
+}
+
+// WatchKey implements kv.Client.
+func (c *Client) WatchKey(ctx context.Context, key string, f func(interface{}) bool) {
+	level.Debug(c.logger).Log("msg", "Watching key", "key", key)
+	defer level.Debug(c.logger).Log("msg", "Finished watching key", "key", key)
+
+	var prev []byte
+
+	c.poll(ctx, key, func() (bool, error) {
+		buf, err := c.rdb.Get(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			// Deletions are not notified, like the other KV stores do.
+			prev = nil
+			return true, nil
+		}
+		if err != nil {
+			return true, err
+		}
+		if prev != nil && string(prev) == string(buf) {
+			return true, nil
+		}
+
+		out, err := c.codec.Decode(buf)
+		if err != nil {
+			level.Error(c.logger).Log("msg", "error decoding key", "key", key, "err", err)
+			return true, nil
+		}
+		prev = buf
+
+		return f(out), nil
+	})
+}
+
+// WatchPrefix implements kv.Client.
+func (c *Client) WatchPrefix(ctx context.Context, prefix string, f func(string, interface{}) bool) {
+	level.Debug(c.logger).Log("msg", "Watching prefix", "key", prefix)
+	defer level.Debug(c.logger).Log("msg", "Finished watching prefix", "key", prefix)
+
+	prev := map[string][]byte{}
+
+	c.poll(ctx, prefix, func() (bool, error) {
+		keys, err := c.List(ctx, prefix)
+		if err != nil {
+			return true, err
+		}
+
+		listed := make(map[string]struct{}, len(keys))
+		for _, key := range keys {
+			listed[key] = struct{}{}
+
+			buf, err := c.rdb.Get(ctx, key).Bytes()
+			if errors.Is(err, redis.Nil) {
+				// The key has been deleted after being listed.
+				continue
+			}
+			if err != nil {
+				return true, err
+			}
+			if old, ok := prev[key]; ok && string(old) == string(buf) {
+				continue
+			}
+
+			out, err := c.codec.Decode(buf)
+			if err != nil {
+				level.Error(c.logger).Log("msg", "error decoding key", "key", key, "err", err)
+				continue
+			}
+			prev[key] = buf
+
+			if !f(key, out) {
+				return false, nil
+			}
+		}
+
+		// Forget the deleted keys, so that they're notified again if they get recreated.
+		for key := range prev {
+			if _, ok := listed[key]; !ok {
+				delete(prev, key)
+			}
+		}
+
+		return true, nil
+	})
+}
+
+// poll calls fn every watch poll interval until the context is canceled or fn returns false.
+// Failures are retried with backoff.
+func (c *Client) poll(ctx context.Context, key string, fn func() (bool, error)) {
+	bo := backoff.New(ctx, backoff.Config{
+		MinBackoff: c.cfg.WatchPollInterval,
+		MaxBackoff: time.Minute,
+	})
+
+	for bo.Ongoing() {
+		cont, err := fn()
+		if err != nil {
+			level.Error(c.logger).Log("msg", "watch error", "key", key, "err", err)
+			bo.Wait()
+			continue
+		}
+		if !cont {
+			return
+		}
+
+		bo.Reset()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.cfg.WatchPollInterval):
+		}
+	}
+}
+
+// List implements kv.Client.
+func (c *Client) List(ctx context.Context, prefix string) ([]string, error) {
+	var (
+		mtx  sync.Mutex
+		keys []string
+	)
+
+	scan := func(ctx context.Context, rdb redis.UniversalClient) error {
+		iter := rdb.Scan(ctx, 0, escapePattern(prefix)+"*", scanCount).Iterator()
+		for iter.Next(ctx) {
+			mtx.Lock()
+			keys = append(keys, iter.Val())
+			mtx.Unlock()
+		}
+		return iter.Err()
+	}
+
+	var err error
+	if cluster, ok := c.rdb.(*redis.ClusterClient); ok {
+		// The keys are spread across the masters of the cluster, which have to be scanned one by one.
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+			return scan(ctx, master)
+		})
+	} else {
+		err = scan(ctx, c.rdb)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// SCAN may return the same key more than once.
+	sort.Strings(keys)
+	unique := keys[:0]
+	for i, key := range keys {
+		if i == 0 || key != keys[i-1] {
+			unique = append(unique, key)
+		}
+	}
+	return unique, nil
+}
+
+// Get implements kv.Client.
+func (c *Client) Get(ctx context.Context, key string) (interface{}, error) {
+	buf, err := c.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.codec.Decode(buf)
+}
+
+// Delete implements kv.Client.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	return c.rdb.Del(ctx, key).Err()
+}
+
+func (c *Client) LastUpdateTime(_ string) time.Time {
+	return time.Now().UTC()
+}
+
+// escapePattern escapes the special characters of the glob-style patterns supported by SCAN.
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
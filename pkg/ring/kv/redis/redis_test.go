@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		cfg      Config
+		expected error
+	}{
+		"valid config": {
+			cfg:      Config{Endpoint: "localhost:6379", WatchPollInterval: time.Second},
+			expected: nil,
+		},
+		"missing endpoint": {
+			cfg:      Config{WatchPollInterval: time.Second},
+			expected: errMissingEndpoint,
+		},
+		"invalid watch poll interval": {
+			cfg:      Config{Endpoint: "localhost:6379"},
+			expected: errInvalidInterval,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expected, testData.cfg.Validate())
+		})
+	}
+}
+
+func TestClient_ListShouldEscapeThePrefix(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer, err := NewInMemoryClient(codec.String{}, log.NewNopLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = closer.Close() })
+
+	for _, key := range []string{"ring[1]/a", "ring[1]/b", "ring1/a", "ring*/a"} {
+		require.NoError(t, client.CAS(ctx, key, func(interface{}) (interface{}, bool, error) {
+			return key, false, nil
+		}))
+	}
+
+	keys, err := client.List(ctx, "ring[1]/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ring[1]/a", "ring[1]/b"}, keys)
+
+	keys, err = client.List(ctx, "ring*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ring*/a"}, keys)
+}
+
+func TestClient_CASShouldNotOverwriteConcurrentUpdates(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer, err := NewInMemoryClient(codec.String{}, log.NewNopLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = closer.Close() })
+
+	attempts := 0
+	require.NoError(t, client.CAS(ctx, "key", func(in interface{}) (interface{}, bool, error) {
+		attempts++
+
+		// Update the key while the first CAS is in progress.
+		if attempts == 1 {
+			require.NoError(t, client.rdb.Set(ctx, "key", "concurrent", 0).Err())
+			return "first", true, nil
+		}
+
+		assert.Equal(t, "concurrent", in)
+		return "second", true, nil
+	}))
+
+	assert.Equal(t, 2, attempts)
+	value, err := client.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "second", value)
+}
@@ -8,7 +8,6 @@ import (
 	"os"
 	"slices"
 	"sort"
-	"strings"
 	"sync"
 	"time"
 
@@ -24,7 +23,7 @@ import (
 )
 
 var (
-	errInvalidTokensGeneratorStrategy = errors.New("invalid token generator strategy")
+	errInvalidAutoForgetUnhealthyPeriod = errors.New("the auto-forget unhealthy period must be greater than the ring heartbeat timeout")
 )
 
 // LifecyclerConfig is the config to build a Lifecycler.
@@ -32,18 +31,19 @@ type LifecyclerConfig struct {
 	RingConfig Config `yaml:"ring"`
 
 	// Config for the ingester lifecycle control
-	NumTokens                int           `yaml:"num_tokens"`
-	TokensGeneratorStrategy  string        `yaml:"tokens_generator_strategy"`
-	HeartbeatPeriod          time.Duration `yaml:"heartbeat_period"`
-	ObservePeriod            time.Duration `yaml:"observe_period"`
-	JoinAfter                time.Duration `yaml:"join_after"`
-	MinReadyDuration         time.Duration `yaml:"min_ready_duration"`
-	InfNames                 []string      `yaml:"interface_names"`
-	FinalSleep               time.Duration `yaml:"final_sleep"`
-	TokensFilePath           string        `yaml:"tokens_file_path"`
-	Zone                     string        `yaml:"availability_zone"`
-	UnregisterOnShutdown     bool          `yaml:"unregister_on_shutdown"`
-	ReadinessCheckRingHealth bool          `yaml:"readiness_check_ring_health"`
+	NumTokens                 int           `yaml:"num_tokens"`
+	TokensGeneratorStrategy   string        `yaml:"tokens_generator_strategy"`
+	HeartbeatPeriod           time.Duration `yaml:"heartbeat_period"`
+	ObservePeriod             time.Duration `yaml:"observe_period"`
+	JoinAfter                 time.Duration `yaml:"join_after"`
+	MinReadyDuration          time.Duration `yaml:"min_ready_duration"`
+	InfNames                  []string      `yaml:"interface_names"`
+	FinalSleep                time.Duration `yaml:"final_sleep"`
+	TokensFilePath            string        `yaml:"tokens_file_path"`
+	Zone                      string        `yaml:"availability_zone"`
+	UnregisterOnShutdown      bool          `yaml:"unregister_on_shutdown"`
+	ReadinessCheckRingHealth  bool          `yaml:"readiness_check_ring_health"`
+	AutoForgetUnhealthyPeriod time.Duration `yaml:"auto_forget_unhealthy_period"`
 
 	// For testing, you can override the address and ID of this ingester
 	Addr string `yaml:"address" doc:"hidden"`
@@ -70,7 +70,7 @@ func (cfg *LifecyclerConfig) RegisterFlagsWithPrefix(prefix string, f *flag.Flag
 	}
 
 	f.IntVar(&cfg.NumTokens, prefix+"num-tokens", 128, "Number of tokens for each ingester.")
-	f.StringVar(&cfg.TokensGeneratorStrategy, prefix+"tokens-generator-strategy", randomTokenStrategy, fmt.Sprintf("EXPERIMENTAL: Algorithm used to generate new ring tokens. Supported Values: %s", strings.Join(supportedTokenStrategy, ",")))
+	RegisterTokensGeneratorStrategyFlag(f, prefix+"tokens-generator-strategy", &cfg.TokensGeneratorStrategy)
 	f.DurationVar(&cfg.HeartbeatPeriod, prefix+"heartbeat-period", 5*time.Second, "Period at which to heartbeat to consul. 0 = disabled.")
 	f.DurationVar(&cfg.JoinAfter, prefix+"join-after", 0*time.Second, "Period to wait for a claim from another member; will join automatically after this.")
 	f.DurationVar(&cfg.ObservePeriod, prefix+"observe-period", 0*time.Second, "Observe tokens after generating to resolve collisions. Useful when using gossiping ring.")
@@ -91,11 +91,16 @@ func (cfg *LifecyclerConfig) RegisterFlagsWithPrefix(prefix string, f *flag.Flag
 	f.StringVar(&cfg.Zone, prefix+"availability-zone", "", "The availability zone where this instance is running.")
 	f.BoolVar(&cfg.UnregisterOnShutdown, prefix+"unregister-on-shutdown", true, "Unregister from the ring upon clean shutdown. It can be useful to disable for rolling restarts with consistent naming in conjunction with -distributor.extend-writes=false.")
 	f.BoolVar(&cfg.ReadinessCheckRingHealth, prefix+"readiness-check-ring-health", true, "When enabled the readiness probe succeeds only after all instances are ACTIVE and healthy in the ring, otherwise only the instance itself is checked. This option should be disabled if in your cluster multiple instances can be rolled out simultaneously, otherwise rolling updates may be slowed down.")
+	f.DurationVar(&cfg.AutoForgetUnhealthyPeriod, prefix+"auto-forget-unhealthy-period", 0, "Automatically remove from the ring the instances which have not heartbeated for this period, so that instances which crashed and never came back don't affect the ring quorum. Must be greater than the ring heartbeat timeout. 0 = disabled.")
 }
 
 func (cfg *LifecyclerConfig) Validate() error {
-	if cfg.TokensGeneratorStrategy != "" && !slices.Contains(supportedTokenStrategy, strings.ToLower(cfg.TokensGeneratorStrategy)) {
-		return errInvalidTokensGeneratorStrategy
+	if err := ValidateTokensGeneratorStrategy(cfg.TokensGeneratorStrategy); err != nil {
+		return err
+	}
+
+	if cfg.AutoForgetUnhealthyPeriod > 0 && cfg.AutoForgetUnhealthyPeriod <= cfg.RingConfig.HeartbeatTimeout {
+		return errInvalidAutoForgetUnhealthyPeriod
 	}
 
 	return nil
@@ -184,11 +189,7 @@ func NewLifecycler(
 		flushTransferer = NewNoopFlushTransferer()
 	}
 
-	tg := NewRandomTokenGenerator()
-
-	if strings.EqualFold(cfg.TokensGeneratorStrategy, minimizeSpreadTokenStrategy) {
-		tg = NewMinimizeSpreadTokenGenerator()
-	}
+	tg := newTokenGenerator(cfg.TokensGeneratorStrategy)
 
 	l := &Lifecycler{
 		cfg:                  cfg,
@@ -944,6 +945,10 @@ func (i *Lifecycler) updateConsul(ctx context.Context) error {
 			ringDesc.Ingesters[i.ID] = instanceDesc
 		}
 
+		if i.cfg.AutoForgetUnhealthyPeriod > 0 {
+			i.forgetUnhealthyInstances(ringDesc)
+		}
+
 		return ringDesc, true, nil
 	})
 
@@ -955,6 +960,21 @@ func (i *Lifecycler) updateConsul(ctx context.Context) error {
 	return err
 }
 
+// forgetUnhealthyInstances removes from the ring the instances whose last heartbeat is older than the auto-forget period.
+func (i *Lifecycler) forgetUnhealthyInstances(ringDesc *Desc) {
+	for id, instance := range ringDesc.Ingesters {
+		if id == i.ID {
+			continue
+		}
+
+		lastHeartbeat := time.Unix(instance.GetTimestamp(), 0)
+		if time.Since(lastHeartbeat) > i.cfg.AutoForgetUnhealthyPeriod {
+			level.Warn(i.logger).Log("msg", "auto-forgetting instance from the ring because it is unhealthy for a long time", "instance", id, "last_heartbeat", lastHeartbeat.String(), "forget_period", i.cfg.AutoForgetUnhealthyPeriod, "ring", i.RingName)
+			ringDesc.RemoveIngester(id)
+		}
+	}
+}
+
 // changeState updates consul with state transitions for us.  NB this must be
 // called from loop()!  Use ChangeState for calls from outside of loop().
 func (i *Lifecycler) changeState(ctx context.Context, state InstanceState) error {
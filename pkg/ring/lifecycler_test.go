@@ -380,6 +380,82 @@ func TestLifecycler_ShouldHandleInstanceAbruptlyRestarted(t *testing.T) {
 	})
 }
 
+func TestLifecycler_AutoForgetUnhealthyInstances(t *testing.T) {
+	tests := map[string]struct {
+		forgetPeriod      time.Duration
+		expectedInstances []string
+	}{
+		"should keep unhealthy instances if disabled": {
+			forgetPeriod:      0,
+			expectedInstances: []string{"ing1", "ing2", "ing3"},
+		},
+		"should forget instances unhealthy for longer than the forget period": {
+			forgetPeriod:      10 * time.Minute,
+			expectedInstances: []string{"ing1", "ing2"},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ctx := context.Background()
+			ringStore, closer := consul.NewInMemoryClient(GetCodec(), log.NewNopLogger(), nil)
+			t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+			var ringConfig Config
+			flagext.DefaultValues(&ringConfig)
+			ringConfig.KVStore.Mock = ringStore
+
+			// Add an instance unhealthy for less than the forget period and one unhealthy for longer.
+			require.NoError(t, ringStore.CAS(ctx, ringKey, func(in interface{}) (interface{}, bool, error) {
+				desc := NewDesc()
+				desc.AddIngester("ing2", "127.0.0.2", "zone1", []uint32{2}, ACTIVE, time.Now())
+				desc.AddIngester("ing3", "127.0.0.3", "zone1", []uint32{3}, ACTIVE, time.Now())
+
+				ing2 := desc.Ingesters["ing2"]
+				ing2.Timestamp = time.Now().Add(-5 * time.Minute).Unix()
+				desc.Ingesters["ing2"] = ing2
+
+				ing3 := desc.Ingesters["ing3"]
+				ing3.Timestamp = time.Now().Add(-time.Hour).Unix()
+				desc.Ingesters["ing3"] = ing3
+				return desc, true, nil
+			}))
+
+			cfg := testLifecyclerConfig(ringConfig, "ing1")
+			cfg.AutoForgetUnhealthyPeriod = testData.forgetPeriod
+			require.NoError(t, cfg.Validate())
+
+			l, err := NewLifecycler(cfg, &nopFlushTransferer{}, "ingester", ringKey, true, true, log.NewNopLogger(), nil)
+			require.NoError(t, err)
+			require.NoError(t, services.StartAndAwaitRunning(ctx, l))
+			defer services.StopAndAwaitTerminated(ctx, l) //nolint:errcheck
+
+			test.Poll(t, time.Second, testData.expectedInstances, func() interface{} {
+				d, err := ringStore.Get(ctx, ringKey)
+				require.NoError(t, err)
+
+				var ids []string
+				for id := range d.(*Desc).Ingesters {
+					ids = append(ids, id)
+				}
+				sort.Strings(ids)
+				return ids
+			})
+		})
+	}
+}
+
+func TestLifecyclerConfig_Validate_AutoForgetUnhealthyPeriod(t *testing.T) {
+	var cfg LifecyclerConfig
+	flagext.DefaultValues(&cfg)
+
+	cfg.AutoForgetUnhealthyPeriod = cfg.RingConfig.HeartbeatTimeout
+	assert.Equal(t, errInvalidAutoForgetUnhealthyPeriod, cfg.Validate())
+
+	cfg.AutoForgetUnhealthyPeriod = 2 * cfg.RingConfig.HeartbeatTimeout
+	assert.NoError(t, cfg.Validate())
+}
+
 type MockClient struct {
 	ListFunc        func(ctx context.Context, prefix string) ([]string, error)
 	GetFunc         func(ctx context.Context, key string) (interface{}, error)
@@ -66,6 +66,16 @@ func (s *defaultReplicationStrategy) Filter(instances []InstanceDesc, op Operati
 	return instances, len(instances) - minSuccess, nil
 }
 
+// MinSuccessZones returns the minimum number of zones, out of the zones the data is replicated to,
+// which must succeed for a zone-aware write to succeed. The configured minimum is capped to the
+// number of zones, and 0 means a majority of the zones.
+func MinSuccessZones(numZones, configured int) int {
+	if configured <= 0 {
+		return numZones/2 + 1
+	}
+	return min(configured, numZones)
+}
+
 type ignoreUnhealthyInstancesReplicationStrategy struct{}
 
 func NewIgnoreUnhealthyInstancesReplicationStrategy() ReplicationStrategy {
@@ -102,6 +102,27 @@ func TestRingReplicationStrategy(t *testing.T) {
 	}
 }
 
+func TestMinSuccessZones(t *testing.T) {
+	tests := map[string]struct {
+		numZones   int
+		configured int
+		expected   int
+	}{
+		"majority of 1 zone":  {numZones: 1, configured: 0, expected: 1},
+		"majority of 2 zones": {numZones: 2, configured: 0, expected: 2},
+		"majority of 3 zones": {numZones: 3, configured: 0, expected: 2},
+		"majority of 5 zones": {numZones: 5, configured: 0, expected: 3},
+		"configured minimum":  {numZones: 3, configured: 1, expected: 1},
+		"configured minimum greater than the number of zones": {numZones: 2, configured: 3, expected: 2},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expected, MinSuccessZones(testData.numZones, testData.configured))
+		})
+	}
+}
+
 func TestIgnoreUnhealthyInstancesReplicationStrategy(t *testing.T) {
 	for _, tc := range []struct {
 		name                         string
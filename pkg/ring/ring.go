@@ -142,12 +142,13 @@ var (
 
 // Config for a Ring
 type Config struct {
-	KVStore                kv.Config              `yaml:"kvstore"`
-	HeartbeatTimeout       time.Duration          `yaml:"heartbeat_timeout"`
-	ReplicationFactor      int                    `yaml:"replication_factor"`
-	ZoneAwarenessEnabled   bool                   `yaml:"zone_awareness_enabled"`
-	ExcludedZones          flagext.StringSliceCSV `yaml:"excluded_zones"`
-	DetailedMetricsEnabled bool                   `yaml:"detailed_metrics_enabled"`
+	KVStore                      kv.Config              `yaml:"kvstore"`
+	HeartbeatTimeout             time.Duration          `yaml:"heartbeat_timeout"`
+	ReplicationFactor            int                    `yaml:"replication_factor"`
+	ZoneAwarenessEnabled         bool                   `yaml:"zone_awareness_enabled"`
+	ZoneAwarenessMinSuccessZones int                    `yaml:"zone_awareness_min_success_zones"`
+	ExcludedZones                flagext.StringSliceCSV `yaml:"excluded_zones"`
+	DetailedMetricsEnabled       bool                   `yaml:"detailed_metrics_enabled"`
 
 	// Whether the shuffle-sharding subring cache is disabled. This option is set
 	// internally and never exposed to the user.
@@ -167,6 +168,7 @@ func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	f.BoolVar(&cfg.DetailedMetricsEnabled, prefix+"ring.detailed-metrics-enabled", true, "Set to true to enable ring detailed metrics. These metrics provide detailed information, such as token count and ownership per tenant. Disabling them can significantly decrease the number of metrics emitted by the distributors.")
 	f.IntVar(&cfg.ReplicationFactor, prefix+"distributor.replication-factor", 3, "The number of ingesters to write to and read from.")
 	f.BoolVar(&cfg.ZoneAwarenessEnabled, prefix+"distributor.zone-awareness-enabled", false, "True to enable the zone-awareness and replicate ingested samples across different availability zones.")
+	f.IntVar(&cfg.ZoneAwarenessMinSuccessZones, prefix+"distributor.zone-awareness-min-success-zones", 0, "[Experimental] Minimum number of zones a write must succeed in when zone-awareness and the zone-aware write quorum are enabled. Reads query enough zones to always include one of them. Requires -distributor.zone-aware-write-quorum to be enabled. 0 means a majority of the zones the data is replicated to.")
 	f.Var(&cfg.ExcludedZones, prefix+"distributor.excluded-zones", "Comma-separated list of zones to exclude from the ring. Instances in excluded zones will be filtered out from the ring.")
 }
 
@@ -199,6 +201,12 @@ type Ring struct {
 	// When did a set of instances change the last time (instance changing state or heartbeat is ignored for this timestamp).
 	lastTopologyChange time.Time
 
+	// Unix timestamp (with seconds precision) of when each instance has been seen switching from
+	// READONLY back to another state. The instances which replaced it in the shuffle shards for the
+	// writes are kept in the shards with lookback for the reads, because they hold the samples written
+	// meanwhile. This map is immutable and is replaced when changed.
+	readOnlyExitedAt map[string]int64
+
 	// List of zones for which there's at least 1 instance in the ring. This list is guaranteed
 	// to be sorted alphabetically.
 	ringZones []string
@@ -332,6 +340,8 @@ func (r *Ring) updateRingState(ringDesc *Desc) {
 		}
 	}
 
+	readOnlyExitedAt := r.updatedReadOnlyExitedAt(prevRing, ringDesc, time.Now())
+
 	rc := prevRing.RingCompare(ringDesc)
 	if rc == Equal || rc == EqualButStatesAndTimestamps {
 		// No need to update tokens or zones. Only states and timestamps
@@ -339,6 +349,15 @@ func (r *Ring) updateRingState(ringDesc *Desc) {
 		// when watching the ring for updates).
 		r.mtx.Lock()
 		r.ringDesc = ringDesc
+		r.readOnlyExitedAt = readOnlyExitedAt
+		// The READONLY instances are skipped when building the shuffle shards, so the cached
+		// subrings are invalidated when an instance switches from or to READONLY.
+		if hasReadOnlyInstancesChanged(prevRing, ringDesc) {
+			r.lastTopologyChange = time.Now()
+			if r.shuffledSubringCache != nil {
+				r.shuffledSubringCache = make(map[subringCacheKey]*Ring)
+			}
+		}
 		r.updateRingMetrics(rc)
 		r.mtx.Unlock()
 		return
@@ -359,6 +378,7 @@ func (r *Ring) updateRingState(ringDesc *Desc) {
 	r.ringInstanceByToken = ringInstanceByToken
 	r.ringInstanceIdByAddr = ringInstanceByAddr
 	r.ringZones = ringZones
+	r.readOnlyExitedAt = readOnlyExitedAt
 	r.lastTopologyChange = now
 	if r.shuffledSubringCache != nil {
 		// Invalidate all cached subrings.
@@ -367,6 +387,49 @@ func (r *Ring) updateRingState(ringDesc *Desc) {
 	r.updateRingMetrics(rc)
 }
 
+// updatedReadOnlyExitedAt returns the readOnlyExitedAt map updated with the instances which switched
+// from READONLY to another state between before and after.
+func (r *Ring) updatedReadOnlyExitedAt(before, after *Desc, now time.Time) map[string]int64 {
+	r.mtx.RLock()
+	prev := r.readOnlyExitedAt
+	r.mtx.RUnlock()
+
+	changed := false
+	for id := range prev {
+		if _, ok := after.GetIngesters()[id]; !ok {
+			changed = true
+		}
+	}
+	for id, a := range after.GetIngesters() {
+		if b, ok := before.GetIngesters()[id]; ok && b.State == READONLY && a.State != READONLY {
+			changed = true
+		}
+	}
+	if !changed {
+		return prev
+	}
+
+	result := make(map[string]int64, len(prev)+1)
+	for id, ts := range prev {
+		if _, ok := after.GetIngesters()[id]; ok {
+			result[id] = ts
+		}
+	}
+	for id, a := range after.GetIngesters() {
+		if b, ok := before.GetIngesters()[id]; ok && b.State == READONLY && a.State != READONLY {
+			result[id] = now.Unix()
+		}
+	}
+	return result
+}
+
+// hasReadOnlyInstancesChanged returns whether any instance switched from or to the READONLY state.
+func hasReadOnlyInstancesChanged(before, after *Desc) bool {
+	return HasInstanceDescsChanged(before.GetIngesters(), after.GetIngesters(), func(b, a InstanceDesc) bool {
+		return (b.State == READONLY) != (a.State == READONLY)
+	})
+}
+
 // Get returns n (or more) instances which form the replicas for the given key.
 // This implementation guarantees:
 // - Stability: given the same ring, two invocations returns the same set for same operation.
@@ -550,11 +613,12 @@ func (r *Ring) GetReplicationSetForOperation(op Operation) (ReplicationSet, erro
 	maxUnavailableZones := 0
 
 	if r.cfg.ZoneAwarenessEnabled {
-		// Given data is replicated to RF different zones, we can tolerate a number of
-		// RF/2 failing zones. However, we need to protect from the case the ring currently
-		// contains instances in a number of zones < RF.
+		// Given data is replicated to RF different zones and written to at least the min success
+		// zones (a majority by default), we can tolerate a number of min success zones - 1 failing
+		// zones. However, we need to protect from the case the ring currently contains instances
+		// in a number of zones < RF.
 		numReplicatedZones := min(len(r.ringZones), r.cfg.ReplicationFactor)
-		minSuccessZones := (numReplicatedZones / 2) + 1
+		minSuccessZones := MinSuccessZones(numReplicatedZones, r.cfg.ZoneAwarenessMinSuccessZones)
 		maxUnavailableZones = minSuccessZones - 1
 
 		if len(zoneFailures) > maxUnavailableZones {
@@ -665,7 +729,7 @@ func (r *Ring) updateRingMetrics(compareResult CompareResult) {
 	oldestTimestampByState := map[string]int64{}
 
 	// Initialized to zero so we emit zero-metrics (instead of not emitting anything)
-	for _, s := range []string{unhealthy, ACTIVE.String(), LEAVING.String(), PENDING.String(), JOINING.String(), READONLY.String()} {
+	for _, s := range []string{unhealthy, ACTIVE.String(), LEAVING.String(), PENDING.String(), JOINING.String(), READONLY.String(), LOADING.String()} {
 		numByState[s] = 0
 		oldestTimestampByState[s] = 0
 	}
@@ -852,7 +916,11 @@ func (r *Ring) shuffleShard(identifier string, size int, lookbackPeriod time.Dur
 
 				// If the lookback is enabled and this instance has been registered within the lookback period
 				// then we should include it in the subring but continuing selecting instances.
-				if lookbackPeriod > 0 && instance.RegisteredTimestamp >= lookbackUntil {
+				// READONLY instances are included in the subring too, so that they're still queried, but
+				// they don't receive writes, so we continue selecting instances to replace them. The same
+				// applies, for the lookback period, to the instances which have recently left READONLY,
+				// given their replacements received the writes meanwhile.
+				if (lookbackPeriod > 0 && (instance.RegisteredTimestamp >= lookbackUntil || r.readOnlyExitedAt[instanceID] >= lookbackUntil)) || instance.State == READONLY {
 					continue
 				}
 
@@ -999,7 +1067,7 @@ func NewOp(healthyStates []InstanceState, shouldExtendReplicaSet func(s Instance
 	}
 
 	if shouldExtendReplicaSet != nil {
-		for _, s := range []InstanceState{ACTIVE, LEAVING, PENDING, JOINING, LEFT, READONLY} {
+		for _, s := range []InstanceState{ACTIVE, LEAVING, PENDING, JOINING, LEFT, READONLY, LOADING} {
 			if shouldExtendReplicaSet(s) {
 				op |= (0x10000 << s)
 			}
@@ -3,6 +3,7 @@ package ring
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -170,6 +171,111 @@ func TestDoBatchZeroInstances(t *testing.T) {
 	require.Error(t, DoBatch(ctx, Write, &r, keys, callback, cleanup))
 }
 
+func TestBatchTracker_ZoneQuorum(t *testing.T) {
+	// The replication set of a write extended to an extra instance in zone-a.
+	replicas := []InstanceDesc{
+		{Addr: "a-1", Zone: "zone-a"},
+		{Addr: "a-2", Zone: "zone-a"},
+		{Addr: "b-1", Zone: "zone-b"},
+		{Addr: "c-1", Zone: "zone-c"},
+	}
+	errFailed := errors.New("failed")
+
+	tests := map[string]struct {
+		zoneQuorum      bool
+		minSuccessZones int
+		failed          map[string]bool
+		expectedErr     bool
+	}{
+		"instance quorum, all zones succeed": {
+			expectedErr: false,
+		},
+		"instance quorum, a full zone outage fails the write": {
+			failed:      map[string]bool{"a-1": true, "a-2": true},
+			expectedErr: true,
+		},
+		"zone quorum, all zones succeed": {
+			zoneQuorum:  true,
+			expectedErr: false,
+		},
+		"zone quorum, a full zone outage doesn't fail the write": {
+			zoneQuorum:  true,
+			failed:      map[string]bool{"a-1": true, "a-2": true},
+			expectedErr: false,
+		},
+		"zone quorum, a single failed instance in a zone doesn't fail the zone": {
+			zoneQuorum:  true,
+			failed:      map[string]bool{"a-1": true, "b-1": true},
+			expectedErr: false,
+		},
+		"zone quorum, two failed zones fail the write": {
+			zoneQuorum:  true,
+			failed:      map[string]bool{"b-1": true, "c-1": true},
+			expectedErr: true,
+		},
+		"zone quorum with 1 min success zone, two failed zones don't fail the write": {
+			zoneQuorum:      true,
+			minSuccessZones: 1,
+			failed:          map[string]bool{"b-1": true, "c-1": true},
+			expectedErr:     false,
+		},
+		"zone quorum with 3 min success zones, a full zone outage fails the write": {
+			zoneQuorum:      true,
+			minSuccessZones: 3,
+			failed:          map[string]bool{"a-1": true, "a-2": true},
+			expectedErr:     true,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			item := &itemTracker{
+				minSuccess:  3,
+				maxFailures: 1,
+			}
+			item.remaining.Store(int32(len(replicas)))
+			if testData.zoneQuorum {
+				item.initZoneQuorum(replicas, testData.minSuccessZones)
+				require.NotNil(t, item.zones)
+			}
+
+			tracker := batchTracker{
+				done: make(chan struct{}, 1),
+				err:  make(chan error, 1),
+			}
+			tracker.rpcsPending.Store(1)
+
+			for _, desc := range replicas {
+				var err error
+				if testData.failed[desc.Addr] {
+					err = errFailed
+				}
+				tracker.record(instance{desc: desc, itemTrackers: []*itemTracker{item}}, err)
+			}
+
+			select {
+			case err := <-tracker.err:
+				assert.True(t, testData.expectedErr, "unexpected error: %v", err)
+			case <-tracker.done:
+				assert.False(t, testData.expectedErr)
+			default:
+				t.Fatal("the batch tracker didn't reach a decision")
+			}
+		})
+	}
+}
+
+func TestItemTracker_ZoneQuorumRequiresMultipleZones(t *testing.T) {
+	item := &itemTracker{}
+	item.initZoneQuorum([]InstanceDesc{{Addr: "1", Zone: "zone-a"}, {Addr: "2", Zone: "zone-a"}, {Addr: "3", Zone: "zone-a"}}, 0)
+	assert.Nil(t, item.zones)
+
+	item.initZoneQuorum([]InstanceDesc{{Addr: "1", Zone: "zone-a"}, {Addr: "2", Zone: "zone-b"}, {Addr: "3", Zone: "zone-c"}}, 0)
+	assert.Len(t, item.zones, 3)
+	assert.Equal(t, 2, item.minSuccessZones)
+	assert.Equal(t, 1, item.maxFailureZones)
+}
+
 func TestAddIngester(t *testing.T) {
 	r := NewDesc()
 
@@ -1218,6 +1324,7 @@ func TestRing_GetReplicationSetForOperation_WithZoneAwarenessEnabled(t *testing.
 		unhealthyInstances          []string
 		expectedAddresses           []string
 		replicationFactor           int
+		minSuccessZones             int
 		expectedError               error
 		expectedMaxErrors           int
 		expectedMaxUnavailableZones int
@@ -1480,6 +1587,40 @@ func TestRing_GetReplicationSetForOperation_WithZoneAwarenessEnabled(t *testing.
 			replicationFactor:  5,
 			expectedError:      ErrTooManyUnhealthyInstances,
 		},
+		"RF=3, 3 zones, one instance per zone, writes succeeding in 1 zone": {
+			ringInstances: map[string]InstanceDesc{
+				"instance-1": {Addr: "127.0.0.1", Zone: "zone-a", Tokens: g.GenerateTokens(NewDesc(), "instance-1", "zone-a", 128, true)},
+				"instance-2": {Addr: "127.0.0.2", Zone: "zone-b", Tokens: g.GenerateTokens(NewDesc(), "instance-2", "zone-b", 128, true)},
+				"instance-3": {Addr: "127.0.0.3", Zone: "zone-c", Tokens: g.GenerateTokens(NewDesc(), "instance-3", "zone-c", 128, true)},
+			},
+			expectedAddresses:           []string{"127.0.0.1", "127.0.0.2", "127.0.0.3"},
+			replicationFactor:           3,
+			minSuccessZones:             1,
+			expectedMaxUnavailableZones: 0,
+		},
+		"RF=3, 3 zones, one instance per zone, one instance unhealthy, writes succeeding in 1 zone": {
+			ringInstances: map[string]InstanceDesc{
+				"instance-1": {Addr: "127.0.0.1", Zone: "zone-a", Tokens: g.GenerateTokens(NewDesc(), "instance-1", "zone-a", 128, true)},
+				"instance-2": {Addr: "127.0.0.2", Zone: "zone-b", Tokens: g.GenerateTokens(NewDesc(), "instance-2", "zone-b", 128, true)},
+				"instance-3": {Addr: "127.0.0.3", Zone: "zone-c", Tokens: g.GenerateTokens(NewDesc(), "instance-3", "zone-c", 128, true)},
+			},
+			unhealthyInstances: []string{"instance-1"},
+			replicationFactor:  3,
+			minSuccessZones:    1,
+			expectedError:      ErrTooManyUnhealthyInstances,
+		},
+		"RF=3, 3 zones, one instance per zone, two instances unhealthy, writes succeeding in 3 zones": {
+			ringInstances: map[string]InstanceDesc{
+				"instance-1": {Addr: "127.0.0.1", Zone: "zone-a", Tokens: g.GenerateTokens(NewDesc(), "instance-1", "zone-a", 128, true)},
+				"instance-2": {Addr: "127.0.0.2", Zone: "zone-b", Tokens: g.GenerateTokens(NewDesc(), "instance-2", "zone-b", 128, true)},
+				"instance-3": {Addr: "127.0.0.3", Zone: "zone-c", Tokens: g.GenerateTokens(NewDesc(), "instance-3", "zone-c", 128, true)},
+			},
+			expectedAddresses:           []string{"127.0.0.3"},
+			unhealthyInstances:          []string{"instance-1", "instance-2"},
+			replicationFactor:           3,
+			minSuccessZones:             3,
+			expectedMaxUnavailableZones: 0,
+		},
 	}
 
 	for testName, testData := range tests {
@@ -1503,9 +1644,10 @@ func TestRing_GetReplicationSetForOperation_WithZoneAwarenessEnabled(t *testing.
 
 			ring := Ring{
 				cfg: Config{
-					HeartbeatTimeout:     time.Minute,
-					ZoneAwarenessEnabled: true,
-					ReplicationFactor:    testData.replicationFactor,
+					HeartbeatTimeout:             time.Minute,
+					ZoneAwarenessEnabled:         true,
+					ZoneAwarenessMinSuccessZones: testData.minSuccessZones,
+					ReplicationFactor:            testData.replicationFactor,
 				},
 				ringDesc:            ringDesc,
 				ringTokens:          ringDesc.GetTokens(),
@@ -2506,6 +2648,66 @@ func TestRing_ShuffleShardWithLookback(t *testing.T) {
 	}
 }
 
+func TestRing_ShuffleShard_ShouldReplaceReadOnlyInstances(t *testing.T) {
+	const userID = "user-1"
+
+	newRingDesc := func(readOnly ...string) *Desc {
+		desc := &Desc{Ingesters: map[string]InstanceDesc{}}
+		for i := 0; i < 3; i++ {
+			id := fmt.Sprintf("instance-%d", i+1)
+			desc.Ingesters[id] = generateRingInstanceWithInfo(id, "zone-a", []uint32{userToken(userID, "zone-a", 0) + uint32(i) + 1}, time.Now().Add(-time.Hour))
+		}
+		for _, id := range readOnly {
+			instance := desc.Ingesters[id]
+			instance.State = READONLY
+			desc.Ingesters[id] = instance
+		}
+		return desc
+	}
+
+	cfg := Config{
+		HeartbeatTimeout:  time.Hour,
+		ReplicationFactor: 1,
+	}
+	ring, err := NewWithStoreClientAndStrategy(cfg, testRingName, testRingKey, &MockClient{}, NewDefaultReplicationStrategy(), prometheus.NewRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+	ring.updateRingState(newRingDesc())
+
+	// The shard is made of the instance owning the token following the user token.
+	rs, err := ring.ShuffleShard(userID, 1).GetAllHealthy(Read)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-1"}, rs.GetAddresses())
+
+	// Once READONLY, the instance is kept in the shard for the reads, and the next instance in the
+	// ring is added to the shard to receive the writes.
+	ring.updateRingState(newRingDesc("instance-1"))
+
+	subring := ring.ShuffleShard(userID, 1)
+	rs, err = subring.GetAllHealthy(Read)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"instance-1", "instance-2"}, rs.GetAddresses())
+
+	rs, err = subring.Get(userToken(userID, "zone-a", 0), WriteNoExtend, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-2"}, rs.GetAddresses())
+
+	// Switching the instance back to ACTIVE restores the original shard.
+	ring.updateRingState(newRingDesc())
+
+	rs, err = ring.ShuffleShard(userID, 1).GetAllHealthy(Read)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-1"}, rs.GetAddresses())
+
+	// The instance which received the writes meanwhile is kept in the read shard for the lookback period.
+	rs, err = ring.ShuffleShardWithLookback(userID, 1, 30*time.Minute, time.Now()).GetAllHealthy(Read)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"instance-1", "instance-2"}, rs.GetAddresses())
+
+	rs, err = ring.ShuffleShardWithLookback(userID, 1, 30*time.Minute, time.Now().Add(time.Hour)).GetAllHealthy(Read)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-1"}, rs.GetAddresses())
+}
+
 func TestRing_ShuffleShardWithLookback_CorrectnessWithFuzzy(t *testing.T) {
 	// The goal of this test is NOT to ensure that the minimum required number of instances
 	// are returned at any given time, BUT at least all required instances are returned.
@@ -3085,6 +3287,7 @@ func TestUpdateMetrics(t *testing.T) {
 		ring_members{name="test",state="ACTIVE"} 2
 		ring_members{name="test",state="JOINING"} 0
 		ring_members{name="test",state="LEAVING"} 0
+		ring_members{name="test",state="LOADING"} 0
 		ring_members{name="test",state="PENDING"} 0
 		ring_members{name="test",state="READONLY"} 0
 		ring_members{name="test",state="Unhealthy"} 0
@@ -3093,6 +3296,7 @@ func TestUpdateMetrics(t *testing.T) {
 		ring_oldest_member_timestamp{name="test",state="ACTIVE"} 11
 		ring_oldest_member_timestamp{name="test",state="JOINING"} 0
 		ring_oldest_member_timestamp{name="test",state="LEAVING"} 0
+		ring_oldest_member_timestamp{name="test",state="LOADING"} 0
 		ring_oldest_member_timestamp{name="test",state="PENDING"} 0
 		ring_oldest_member_timestamp{name="test",state="READONLY"} 0
 		ring_oldest_member_timestamp{name="test",state="Unhealthy"} 0
@@ -3113,6 +3317,7 @@ func TestUpdateMetrics(t *testing.T) {
 		ring_members{name="test",state="ACTIVE"} 2
 		ring_members{name="test",state="JOINING"} 0
 		ring_members{name="test",state="LEAVING"} 0
+		ring_members{name="test",state="LOADING"} 0
 		ring_members{name="test",state="PENDING"} 0
 		ring_members{name="test",state="READONLY"} 0
 		ring_members{name="test",state="Unhealthy"} 0
@@ -3121,6 +3326,7 @@ func TestUpdateMetrics(t *testing.T) {
 		ring_oldest_member_timestamp{name="test",state="ACTIVE"} 11
 		ring_oldest_member_timestamp{name="test",state="JOINING"} 0
 		ring_oldest_member_timestamp{name="test",state="LEAVING"} 0
+		ring_oldest_member_timestamp{name="test",state="LOADING"} 0
 		ring_oldest_member_timestamp{name="test",state="PENDING"} 0
 		ring_oldest_member_timestamp{name="test",state="READONLY"} 0
 		ring_oldest_member_timestamp{name="test",state="Unhealthy"} 0
@@ -3193,6 +3399,7 @@ func TestUpdateMetricsWithRemoval(t *testing.T) {
 		ring_members{name="test",state="ACTIVE"} 2
 		ring_members{name="test",state="JOINING"} 0
 		ring_members{name="test",state="LEAVING"} 0
+		ring_members{name="test",state="LOADING"} 0
 		ring_members{name="test",state="PENDING"} 0
 		ring_members{name="test",state="READONLY"} 0
 		ring_members{name="test",state="Unhealthy"} 0
@@ -3201,6 +3408,7 @@ func TestUpdateMetricsWithRemoval(t *testing.T) {
 		ring_oldest_member_timestamp{name="test",state="ACTIVE"} 11
 		ring_oldest_member_timestamp{name="test",state="JOINING"} 0
 		ring_oldest_member_timestamp{name="test",state="LEAVING"} 0
+		ring_oldest_member_timestamp{name="test",state="LOADING"} 0
 		ring_oldest_member_timestamp{name="test",state="PENDING"} 0
 		ring_oldest_member_timestamp{name="test",state="READONLY"} 0
 		ring_oldest_member_timestamp{name="test",state="Unhealthy"} 0
@@ -3230,6 +3438,7 @@ func TestUpdateMetricsWithRemoval(t *testing.T) {
 		ring_members{name="test",state="ACTIVE"} 1
 		ring_members{name="test",state="JOINING"} 0
 		ring_members{name="test",state="LEAVING"} 0
+		ring_members{name="test",state="LOADING"} 0
 		ring_members{name="test",state="PENDING"} 0
 		ring_members{name="test",state="READONLY"} 0
 		ring_members{name="test",state="Unhealthy"} 0
@@ -3238,6 +3447,7 @@ func TestUpdateMetricsWithRemoval(t *testing.T) {
 		ring_oldest_member_timestamp{name="test",state="ACTIVE"} 22
 		ring_oldest_member_timestamp{name="test",state="JOINING"} 0
 		ring_oldest_member_timestamp{name="test",state="LEAVING"} 0
+		ring_oldest_member_timestamp{name="test",state="LOADING"} 0
 		ring_oldest_member_timestamp{name="test",state="PENDING"} 0
 		ring_oldest_member_timestamp{name="test",state="READONLY"} 0
 		ring_oldest_member_timestamp{name="test",state="Unhealthy"} 0
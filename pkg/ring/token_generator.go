@@ -2,8 +2,12 @@ package ring
 
 import (
 	"container/heap"
+	"errors"
+	"flag"
+	"fmt"
 	"math"
 	"math/rand"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -18,8 +22,32 @@ const (
 
 var (
 	supportedTokenStrategy = []string{strings.ToLower(randomTokenStrategy), strings.ToLower(minimizeSpreadTokenStrategy)}
+
+	ErrInvalidTokensGeneratorStrategy = errors.New("invalid token generator strategy")
 )
 
+// RegisterTokensGeneratorStrategyFlag registers the flag to select the algorithm used to generate
+// the tokens of the instances joining a ring.
+func RegisterTokensGeneratorStrategyFlag(f *flag.FlagSet, name string, strategy *string) {
+	f.StringVar(strategy, name, randomTokenStrategy, fmt.Sprintf("EXPERIMENTAL: Algorithm used to generate new ring tokens. Supported Values: %s", strings.Join(supportedTokenStrategy, ",")))
+}
+
+// ValidateTokensGeneratorStrategy returns an error if the tokens generator strategy is not supported.
+func ValidateTokensGeneratorStrategy(strategy string) error {
+	if strategy != "" && !slices.Contains(supportedTokenStrategy, strings.ToLower(strategy)) {
+		return ErrInvalidTokensGeneratorStrategy
+	}
+	return nil
+}
+
+// newTokenGenerator returns the TokenGenerator for the tokens generator strategy, defaulting to random.
+func newTokenGenerator(strategy string) TokenGenerator {
+	if strings.EqualFold(strategy, minimizeSpreadTokenStrategy) {
+		return NewMinimizeSpreadTokenGenerator()
+	}
+	return NewRandomTokenGenerator()
+}
+
 type TokenGenerator interface {
 	// GenerateTokens make numTokens unique random tokens, none of which clash
 	// with takenTokens. Generated tokens are sorted.
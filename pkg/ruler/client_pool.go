@@ -37,9 +37,11 @@ func (p *rulerClientsPool) GetClientFor(addr string) (RulerClient, error) {
 func newRulerClientPool(clientCfg grpcclient.Config, logger log.Logger, reg prometheus.Registerer) ClientsPool {
 	// We prefer sane defaults instead of exposing further config options.
 	poolCfg := client.PoolConfig{
-		CheckInterval:      time.Minute,
-		HealthCheckEnabled: true,
-		HealthCheckTimeout: 10 * time.Second,
+		CheckInterval:          time.Minute,
+		HealthCheckEnabled:     true,
+		HealthCheckTimeout:     10 * time.Second,
+		CheckIntervalJitter:    0.2,
+		HealthCheckConcurrency: 16,
 	}
 
 	clientsCount := promauto.With(reg).NewGauge(prometheus.GaugeOpts{
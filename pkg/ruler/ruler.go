@@ -151,6 +151,8 @@ type Config struct {
 	EnabledTenants  flagext.StringSliceCSV `yaml:"enabled_tenants"`
 	DisabledTenants flagext.StringSliceCSV `yaml:"disabled_tenants"`
 
+	TenantDeletionGracePeriod time.Duration `yaml:"tenant_deletion_grace_period"`
+
 	RingCheckPeriod time.Duration `yaml:"-"`
 
 	// Field will be populated during runtime.
@@ -174,6 +176,12 @@ func (cfg *Config) Validate(limits validation.Limits, log log.Logger) error {
 		return errInvalidTenantShardSize
 	}
 
+	if cfg.EnableSharding {
+		if err := ring.ValidateTokensGeneratorStrategy(cfg.Ring.TokensGeneratorStrategy); err != nil {
+			return err
+		}
+	}
+
 	if err := cfg.ClientTLSConfig.Validate(log); err != nil {
 		return errors.Wrap(err, "invalid ruler gRPC client config")
 	}
@@ -234,6 +242,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.Var(&cfg.EnabledTenants, "ruler.enabled-tenants", "Comma separated list of tenants whose rules this ruler can evaluate. If specified, only these tenants will be handled by ruler, otherwise this ruler can process rules from all tenants. Subject to sharding.")
 	f.Var(&cfg.DisabledTenants, "ruler.disabled-tenants", "Comma separated list of tenants whose rules this ruler cannot evaluate. If specified, a ruler that would normally pick the specified tenant(s) for processing will ignore them instead. Subject to sharding.")
 
+	f.DurationVar(&cfg.TenantDeletionGracePeriod, "ruler.tenant-deletion-grace-period", 0, "[Experimental] If greater than 0, the rules of the tenants marked for deletion in the blocks storage are no longer evaluated, and their rule groups are deleted once this period has elapsed since the tenant has been marked for deletion. 0 to disable.")
+
 	f.BoolVar(&cfg.EnableQueryStats, "ruler.query-stats-enabled", false, "Report query statistics for ruler queries to complete as a per user metric and as an info level log message.")
 	f.BoolVar(&cfg.DisableRuleGroupLabel, "ruler.disable-rule-group-label", false, "Disable the rule_group label on exported metrics")
 
@@ -650,6 +660,21 @@ func (r *Ruler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// RingDumpHandler responds with the full ruler ring descriptor as JSON.
+func (r *Ruler) RingDumpHandler(w http.ResponseWriter, req *http.Request) {
+	if !r.cfg.EnableSharding {
+		http.Error(w, "Ruler running with shards disabled.", http.StatusNotFound)
+		return
+	}
+
+	if r.State() != services.Running {
+		http.Error(w, "Ruler is not running yet.", http.StatusServiceUnavailable)
+		return
+	}
+
+	r.ring.DumpHandler(w, req)
+}
+
 func (r *Ruler) run(ctx context.Context) error {
 	level.Info(r.logger).Log("msg", "ruler up and running")
 
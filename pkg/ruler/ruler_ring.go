@@ -53,6 +53,8 @@ type RingConfig struct {
 	InstanceZone           string   `yaml:"instance_availability_zone" doc:"hidden"`
 	NumTokens              int      `yaml:"num_tokens"`
 
+	TokensGeneratorStrategy string `yaml:"tokens_generator_strategy"`
+
 	FinalSleep                      time.Duration `yaml:"final_sleep"`
 	KeepInstanceInTheRingOnShutdown bool          `yaml:"keep_instance_in_the_ring_on_shutdown"`
 	// Injected internally
@@ -86,6 +88,7 @@ func (cfg *RingConfig) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&cfg.InstanceID, "ruler.ring.instance-id", hostname, "Instance ID to register in the ring.")
 	f.StringVar(&cfg.InstanceZone, "ruler.ring.instance-availability-zone", "", "The availability zone where this instance is running. Required if zone-awareness is enabled.")
 	f.IntVar(&cfg.NumTokens, "ruler.ring.num-tokens", 128, "Number of tokens for each ruler.")
+	ring.RegisterTokensGeneratorStrategyFlag(f, "ruler.ring.tokens-generator-strategy", &cfg.TokensGeneratorStrategy)
 	f.BoolVar(&cfg.KeepInstanceInTheRingOnShutdown, "ruler.ring.keep-instance-in-the-ring-on-shutdown", false, "Keep instance in the ring on shut down.")
 }
 
@@ -106,6 +109,7 @@ func (cfg *RingConfig) ToLifecyclerConfig(logger log.Logger) (ring.BasicLifecycl
 		HeartbeatPeriod:                 cfg.HeartbeatPeriod,
 		TokensObservePeriod:             0,
 		NumTokens:                       cfg.NumTokens,
+		TokensGeneratorStrategy:         cfg.TokensGeneratorStrategy,
 		FinalSleep:                      cfg.FinalSleep,
 		KeepInstanceInTheRingOnShutdown: cfg.KeepInstanceInTheRingOnShutdown,
 	}, nil
@@ -193,7 +193,7 @@ func testQueryableFunc(querierTestConfig *querier.TestConfig, reg prometheus.Reg
 		querierTestConfig.Cfg.ActiveQueryTrackerDir = ""
 
 		overrides, _ := validation.NewOverrides(querier.DefaultLimitsConfig(), nil)
-		q, _, _ := querier.New(querierTestConfig.Cfg, overrides, querierTestConfig.Distributor, querierTestConfig.Stores, reg, logger)
+		q, _, _ := querier.New(querierTestConfig.Cfg, overrides, querierTestConfig.Distributor, querierTestConfig.Stores, nil, reg, logger)
 		return func(mint, maxt int64) (storage.Querier, error) {
 			return q.Querier(mint, maxt)
 		}
@@ -2982,3 +2982,22 @@ func TestRuler_QueryOffset(t *testing.T) {
 	gotOffset = rg.GetGroup().QueryOffset
 	require.Equal(t, time.Minute*2, *gotOffset)
 }
+
+func TestRuler_RingDumpHandler(t *testing.T) {
+	cfg := defaultRulerConfig(t)
+	cfg.EnableSharding = true
+
+	r, _ := buildRuler(t, cfg, nil, newMockRuleStore(nil, nil), nil)
+
+	// The ring isn't dumped until the ruler is running.
+	rec := httptest.NewRecorder()
+	r.RingDumpHandler(rec, httptest.NewRequest(http.MethodGet, "/ruler/ring/json", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), r))
+	defer services.StopAndAwaitTerminated(context.Background(), r) //nolint:errcheck
+
+	rec = httptest.NewRecorder()
+	r.RingDumpHandler(rec, httptest.NewRequest(http.MethodGet, "/ruler/ring/json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,98 @@
+package rulestore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/ruler/rulespb"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+)
+
+// TenantDeletionRuleStore is a RuleStore hiding the rule groups of the tenants marked for
+// deletion in the blocks storage, so that their rules are no longer evaluated. The rule
+// groups of a tenant are deleted once the grace period has elapsed since the tenant has been
+// marked for deletion.
+type TenantDeletionRuleStore struct {
+	RuleStore
+
+	bucketClient objstore.InstrumentedBucket
+	gracePeriod  time.Duration
+	logger       log.Logger
+}
+
+func NewTenantDeletionRuleStore(store RuleStore, bucketClient objstore.InstrumentedBucket, gracePeriod time.Duration, logger log.Logger) *TenantDeletionRuleStore {
+	return &TenantDeletionRuleStore{
+		RuleStore:    store,
+		bucketClient: bucketClient,
+		gracePeriod:  gracePeriod,
+		logger:       logger,
+	}
+}
+
+// ListAllUsers implements RuleStore.
+func (s *TenantDeletionRuleStore) ListAllUsers(ctx context.Context) ([]string, error) {
+	users, err := s.RuleStore.ListAllUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.deletedUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]string, 0, len(users))
+	for _, userID := range users {
+		if _, ok := deleted[userID]; !ok {
+			filtered = append(filtered, userID)
+		}
+	}
+	return filtered, nil
+}
+
+// ListAllRuleGroups implements RuleStore.
+func (s *TenantDeletionRuleStore) ListAllRuleGroups(ctx context.Context) (map[string]rulespb.RuleGroupList, error) {
+	groups, err := s.RuleStore.ListAllRuleGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.deletedUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for userID := range deleted {
+		delete(groups, userID)
+	}
+	return groups, nil
+}
+
+// deletedUsers returns the tenants marked for deletion, and deletes the rule groups of the
+// tenants marked for deletion for longer than the grace period.
+func (s *TenantDeletionRuleStore) deletedUsers(ctx context.Context) (map[string]struct{}, error) {
+	marks, err := cortex_tsdb.ReadTenantDeletionMarks(ctx, s.bucketClient)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make(map[string]struct{}, len(marks))
+	for userID, mark := range marks {
+		deleted[userID] = struct{}{}
+
+		if time.Since(time.Unix(mark.DeletionTime, 0)) < s.gracePeriod {
+			continue
+		}
+		if err := s.RuleStore.DeleteNamespace(ctx, userID, ""); err == nil {
+			level.Info(s.logger).Log("msg", "deleted rule groups of tenant marked for deletion", "user", userID)
+		} else if !errors.Is(err, ErrGroupNamespaceNotFound) {
+			level.Warn(s.logger).Log("msg", "failed to delete rule groups of tenant marked for deletion", "user", userID, "err", err)
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,44 @@
+package rulestore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/ruler/rulespb"
+	"github.com/cortexproject/cortex/pkg/ruler/rulestore"
+	"github.com/cortexproject/cortex/pkg/ruler/rulestore/bucketclient"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+)
+
+func TestTenantDeletionRuleStore(t *testing.T) {
+	ctx := context.Background()
+	blocksBucket := objstore.WithNoopInstr(objstore.NewInMemBucket())
+	store := rulestore.NewTenantDeletionRuleStore(bucketclient.NewBucketRuleStore(objstore.NewInMemBucket(), nil, log.NewNopLogger()), blocksBucket, time.Hour, log.NewNopLogger())
+
+	for _, userID := range []string{"user-1", "user-2", "user-3"} {
+		require.NoError(t, store.SetRuleGroup(ctx, userID, "namespace", &rulespb.RuleGroupDesc{User: userID, Namespace: "namespace", Name: "group"}))
+	}
+	require.NoError(t, cortex_tsdb.WriteTenantDeletionMark(ctx, blocksBucket, "user-2", cortex_tsdb.NewTenantDeletionMark(time.Now())))
+	require.NoError(t, cortex_tsdb.WriteTenantDeletionMark(ctx, blocksBucket, "user-3", cortex_tsdb.NewTenantDeletionMark(time.Now().Add(-2*time.Hour))))
+
+	users, err := store.ListAllUsers(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user-1"}, users)
+
+	groups, err := store.ListAllRuleGroups(ctx)
+	require.NoError(t, err)
+	assert.Len(t, groups, 1)
+	assert.Contains(t, groups, "user-1")
+
+	// The rule groups of the tenant marked for deletion are only deleted after the grace period.
+	_, err = store.GetRuleGroup(ctx, "user-2", "namespace", "group")
+	require.NoError(t, err)
+	_, err = store.GetRuleGroup(ctx, "user-3", "namespace", "group")
+	require.ErrorIs(t, err, rulestore.ErrGroupNotFound)
+}
@@ -31,6 +31,9 @@ func NewRuleStore(ctx context.Context, cfg rulestore.Config, cfgProvider bucket.
 		return local.NewLocalRulesClient(cfg.Local, loader)
 	}
 
+	// The rules of each tenant are stored under rules/<tenant>/.
+	cfg.Config.TenantLayoutDepth = 1
+
 	bucketClient, err := bucket.NewClient(ctx, cfg.Config, "ruler-storage", logger, reg)
 	if err != nil {
 		return nil, err
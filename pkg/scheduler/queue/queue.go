@@ -29,10 +29,19 @@ var (
 // of RequestQueue.GetNextRequestForQuerier method.
 type UserIndex struct {
 	last int
+
+	// User of the last returned queue, and the number of times in a row it has been returned,
+	// to return it as many times in a row as the user's queue weight.
+	user   string
+	served int
 }
 
 // Modify index to start iteration on the same user, for which last queue was returned.
 func (ui UserIndex) ReuseLastUser() UserIndex {
+	if ui.last >= 0 && ui.user != "" {
+		// Don't count the last returned queue towards the user's queue weight.
+		return UserIndex{last: ui.last, user: ui.user, served: ui.served - 1}
+	}
 	if ui.last >= 0 {
 		return UserIndex{last: ui.last - 1}
 	}
@@ -64,6 +73,7 @@ type RequestQueue struct {
 
 	totalRequests     *prometheus.CounterVec // Per user and priority.
 	discardedRequests *prometheus.CounterVec // Per user and priority.
+	inflightRequests  *prometheus.GaugeVec   // Per user.
 }
 
 func NewRequestQueue(forgetDelay time.Duration, queueLength *prometheus.GaugeVec, discardedRequests *prometheus.CounterVec, limits Limits, registerer prometheus.Registerer) *RequestQueue {
@@ -75,6 +85,10 @@ func NewRequestQueue(forgetDelay time.Duration, queueLength *prometheus.GaugeVec
 			Help: "Total number of query requests going to the request queue.",
 		}, []string{"user", "priority"}),
 		discardedRequests: discardedRequests,
+		inflightRequests: promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_request_queue_inflight_requests",
+			Help: "Number of query requests dequeued by the queriers and not done yet.",
+		}, []string{"user"}),
 	}
 
 	q.cond = sync.NewCond(&q.mtx)
@@ -147,8 +161,8 @@ FindQueue:
 	}
 
 	for {
-		queue, userID, idx := q.queues.getNextQueueForQuerier(last.last, querierID)
-		last.last = idx
+		queue, userID, idx := q.queues.getNextWeightedQueueForQuerier(last, querierID)
+		last = idx
 		if queue == nil {
 			break
 		}
@@ -167,6 +181,9 @@ FindQueue:
 				q.queues.deleteQueue(userID)
 			}
 
+			q.queues.inflight[userID]++
+			q.inflightRequests.WithLabelValues(userID).Inc()
+
 			// Tell close() we've processed a request.
 			q.cond.Broadcast()
 
@@ -180,6 +197,28 @@ FindQueue:
 	goto FindQueue
 }
 
+// RequestDone must be called once a request of the user returned by GetNextRequestForQuerier
+// has been handled or discarded, to count it out of the max concurrent requests of the user.
+func (q *RequestQueue) RequestDone(userID string) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if q.queues.inflight[userID] <= 0 {
+		return
+	}
+
+	q.queues.inflight[userID]--
+	if q.queues.inflight[userID] == 0 {
+		delete(q.queues.inflight, userID)
+		q.inflightRequests.DeleteLabelValues(userID)
+	} else {
+		q.inflightRequests.WithLabelValues(userID).Dec()
+	}
+
+	// Notify the queriers waiting for the requests of the user.
+	q.cond.Broadcast()
+}
+
 func (q *RequestQueue) getPriorityForQuerier(userID string, querierID string) (int64, bool) {
 	if priority, ok := q.queues.userQueues[userID].reservedQueriers[querierID]; ok {
 		return priority, true
@@ -393,6 +393,51 @@ func TestExitingRequestsShouldPersistEvenIfTheConfigHasChanged(t *testing.T) {
 	assert.Equal(t, 2, queue.queues.userQueues["userID"].queue.length())
 }
 
+func TestQueriersShouldHandleRequestsOfTheUsersProportionallyToTheirWeight(t *testing.T) {
+	queue := NewRequestQueue(0,
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user", "priority", "type"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user", "priority"}),
+		weightedMockLimits{MockLimits: MockLimits{MaxOutstanding: 10}, weights: map[string]int{"user-a": 3}},
+		nil,
+	)
+	ctx := context.Background()
+	queue.RegisterQuerierConnection("querier-1")
+
+	for i := 0; i < 8; i++ {
+		assert.NoError(t, queue.EnqueueRequest("user-a", MockRequest{id: "user-a"}, 0, nil))
+		assert.NoError(t, queue.EnqueueRequest("user-b", MockRequest{id: "user-b"}, 0, nil))
+	}
+
+	var order []string
+	last := FirstUser()
+	for i := 0; i < 10; i++ {
+		req, idx, err := queue.GetNextRequestForQuerier(ctx, last, "querier-1")
+		require.NoError(t, err)
+		order = append(order, req.(MockRequest).id)
+		last = idx
+
+		// Reusing the last user after an expired request doesn't count the expired request.
+		if i == 4 {
+			last = last.ReuseLastUser()
+		}
+	}
+
+	assert.Equal(t, []string{
+		"user-a", "user-a", "user-a", "user-b",
+		"user-a", "user-a", "user-a", "user-a", "user-b",
+		"user-a",
+	}, order)
+}
+
+type weightedMockLimits struct {
+	MockLimits
+	weights map[string]int
+}
+
+func (l weightedMockLimits) QueryQueueWeight(user string) int {
+	return l.weights[user]
+}
+
 type MockRequest struct {
 	id       string
 	priority int64
@@ -401,3 +446,46 @@ type MockRequest struct {
 func (r MockRequest) Priority() int64 {
 	return r.priority
 }
+
+func TestQueriersShouldNotHandleMoreConcurrentRequestsOfTheUserThanTheLimit(t *testing.T) {
+	queue := NewRequestQueue(0,
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user", "priority", "type"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user", "priority"}),
+		MockLimits{MaxOutstanding: 10, MaxConcurrent: 2},
+		nil,
+	)
+	ctx := context.Background()
+	queue.RegisterQuerierConnection("querier-1")
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, queue.EnqueueRequest("user-a", MockRequest{id: "user-a"}, 0, nil))
+	}
+	assert.NoError(t, queue.EnqueueRequest("user-b", MockRequest{id: "user-b"}, 0, nil))
+
+	var order []string
+	last := FirstUser()
+	for i := 0; i < 3; i++ {
+		req, idx, err := queue.GetNextRequestForQuerier(ctx, last, "querier-1")
+		require.NoError(t, err)
+		order = append(order, req.(MockRequest).id)
+		last = idx
+	}
+
+	// The third request of user-a is held back while two of its requests are in flight.
+	assert.Equal(t, []string{"user-a", "user-b", "user-a"}, order)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	go func() {
+		<-timeoutCtx.Done()
+		queue.QuerierDisconnecting()
+	}()
+	_, _, err := queue.GetNextRequestForQuerier(timeoutCtx, last, "querier-1")
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	// Once a request of user-a is done, its next request can be handled.
+	queue.RequestDone("user-a")
+	req, _, err := queue.GetNextRequestForQuerier(ctx, last, "querier-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user-a", req.(MockRequest).id)
+}
@@ -18,6 +18,14 @@ type Limits interface {
 	// of outstanding requests per tenant per request queue.
 	MaxOutstandingPerTenant(user string) int
 
+	// MaxConcurrentPerTenant returns the limit to the maximum number of requests
+	// per tenant per request queue handled by the queriers at the same time.
+	MaxConcurrentPerTenant(user string) int
+
+	// QueryQueueWeight returns the number of requests of the tenant the queriers
+	// handle in a row, before moving to the next tenant.
+	QueryQueueWeight(user string) int
+
 	// QueryPriority returns query priority config for the tenant, including priority level,
 	// their attributes, and how many reserved queriers each priority has.
 	QueryPriority(user string) validation.QueryPriority
@@ -55,6 +63,9 @@ type queues struct {
 	// Sorted list of querier names, used when creating per-user shard.
 	sortedQueriers []string
 
+	// Number of requests per user dequeued by the queriers and not done yet.
+	inflight map[string]int
+
 	limits Limits
 
 	queueLength *prometheus.GaugeVec // Per user, type and priority.
@@ -76,6 +87,9 @@ type userQueue struct {
 	priorityList    []int64
 	priorityEnabled bool
 
+	// Number of requests the queriers handle in a row from this queue, before moving to the next user.
+	weight int
+
 	// Seed for shuffle sharding of queriers. This seed is based on userID only and is therefore consistent
 	// between different frontends.
 	seed int64
@@ -91,6 +105,7 @@ func newUserQueues(forgetDelay time.Duration, limits Limits, queueLength *promet
 		forgetDelay:    forgetDelay,
 		queriers:       map[string]*querier{},
 		sortedQueriers: nil,
+		inflight:       map[string]int{},
 		limits:         limits,
 		queueLength:    queueLength,
 	}
@@ -178,6 +193,12 @@ func (q *queues) getOrAddQueue(userID string, maxQueriers int) userRequestQueue
 		uq.priorityEnabled = priorityEnabled
 	}
 
+	uq.weight = q.limits.QueryQueueWeight(userID)
+
+	if pq, ok := uq.queue.(*PriorityRequestQueue); ok {
+		pq.setStarvationTimeout(time.Duration(q.limits.QueryPriority(userID).StarvationTimeout))
+	}
+
 	if uq.maxQueriers != maxQueriers {
 		uq.maxQueriers = maxQueriers
 		uq.queriers = shuffleQueriersForUser(uq.seed, maxQueriers, q.sortedQueriers, nil)
@@ -207,7 +228,7 @@ func (q *queues) getOrAddQueue(userID string, maxQueriers int) userRequestQueue
 
 func (q *queues) createUserRequestQueue(userID string) userRequestQueue {
 	if q.limits.QueryPriority(userID).Enabled {
-		return NewPriorityRequestQueue(util.NewPriorityQueue(nil), userID, q.queueLength)
+		return NewPriorityRequestQueue(util.NewPriorityQueue(nil), userID, q.queueLength, time.Duration(q.limits.QueryPriority(userID).StarvationTimeout))
 	}
 
 	queueSize := q.limits.MaxOutstandingPerTenant(userID)
@@ -247,11 +268,62 @@ func (q *queues) getNextQueueForQuerier(lastUserIndex int, querierID string) (us
 			}
 		}
 
+		if q.reachedMaxConcurrent(u) {
+			// The queriers are already handling as many requests of the user as allowed.
+			continue
+		}
+
 		return uq.queue, u, uid
 	}
 	return nil, "", uid
 }
 
+// Returns the queue of the last user again if the querier has handled less requests of the user in a row than
+// its weight, otherwise finds the next queue for the querier like getNextQueueForQuerier.
+func (q *queues) getNextWeightedQueueForQuerier(last UserIndex, querierID string) (userRequestQueue, string, UserIndex) {
+	if queue := q.getLastUserQueue(last, querierID); queue != nil {
+		return queue, last.user, UserIndex{last: last.last, user: last.user, served: last.served + 1}
+	}
+
+	queue, userID, idx := q.getNextQueueForQuerier(last.last, querierID)
+	return queue, userID, UserIndex{last: idx, user: userID, served: 1}
+}
+
+func (q *queues) getLastUserQueue(last UserIndex, querierID string) userRequestQueue {
+	if last.user == "" || last.last < 0 {
+		return nil
+	}
+
+	q.queuesMx.RLock()
+	defer q.queuesMx.RUnlock()
+
+	// The user queue may have been deleted, and its spot reused by another user, in the meantime.
+	if last.last >= len(q.users) || q.users[last.last] != last.user {
+		return nil
+	}
+
+	uq := q.userQueues[last.user]
+	if last.served >= uq.weight {
+		return nil
+	}
+	if uq.queriers != nil {
+		if _, ok := uq.queriers[querierID]; !ok {
+			return nil
+		}
+	}
+	if q.reachedMaxConcurrent(last.user) {
+		return nil
+	}
+	return uq.queue
+}
+
+// reachedMaxConcurrent returns whether the queriers are handling the max number
+// of concurrent requests of the user.
+func (q *queues) reachedMaxConcurrent(userID string) bool {
+	maxConcurrent := q.limits.MaxConcurrentPerTenant(userID)
+	return maxConcurrent > 0 && q.inflight[userID] >= maxConcurrent
+}
+
 func (q *queues) addQuerierConnection(querierID string) {
 	info := q.queriers[querierID]
 	if info != nil {
@@ -421,8 +493,10 @@ func hasPriorityListChanged(old, new []int64) bool {
 // MockLimits implements the Limits interface. Used in tests only.
 type MockLimits struct {
 	MaxOutstanding        int
+	MaxConcurrent         int
 	MaxQueriersPerUserVal float64
 	QueryPriorityVal      validation.QueryPriority
+	QueryQueueWeightVal   int
 }
 
 func (l MockLimits) MaxQueriersPerUser(_ string) float64 {
@@ -433,6 +507,14 @@ func (l MockLimits) MaxOutstandingPerTenant(_ string) int {
 	return l.MaxOutstanding
 }
 
+func (l MockLimits) MaxConcurrentPerTenant(_ string) int {
+	return l.MaxConcurrent
+}
+
+func (l MockLimits) QueryQueueWeight(_ string) int {
+	return l.QueryQueueWeightVal
+}
+
 func (l MockLimits) QueryPriority(_ string) validation.QueryPriority {
 	return l.QueryPriorityVal
 }
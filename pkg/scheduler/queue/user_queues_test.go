@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -440,6 +441,11 @@ func TestGetOrAddQueueShouldUpdateProperties(t *testing.T) {
 	assert.Equal(t, 0, len(q.userQueues["userID"].reservedQueriers))
 	assert.ElementsMatch(t, []int64{}, q.userQueues["userID"].priorityList)
 
+	limits.QueryPriorityVal.StarvationTimeout = model.Duration(time.Minute)
+	q.limits = limits
+	queue = q.getOrAddQueue("userID", 3)
+	assert.Equal(t, time.Minute, queue.(*PriorityRequestQueue).starvationTimeout)
+
 	limits.QueryPriorityVal.Enabled = false
 	q.limits = limits
 	queue = q.getOrAddQueue("userID", 3)
@@ -2,6 +2,7 @@ package queue
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -47,28 +48,80 @@ type PriorityRequestQueue struct {
 	queue       *util.PriorityQueue
 	userID      string
 	queueLength *prometheus.GaugeVec
+
+	// Requests waiting for longer than starvationTimeout are dequeued first, regardless of their priority.
+	// The requests are also kept in enqueue order to find them, when it's enabled.
+	starvationTimeout time.Duration
+	fifo              []*priorityRequest
+}
+
+// priorityRequest is a request in the priority queue, with the time it was enqueued.
+type priorityRequest struct {
+	Request
+	enqueueTime time.Time
 }
 
-func NewPriorityRequestQueue(queue *util.PriorityQueue, userID string, queueLength *prometheus.GaugeVec) *PriorityRequestQueue {
-	return &PriorityRequestQueue{queue: queue, userID: userID, queueLength: queueLength}
+func NewPriorityRequestQueue(queue *util.PriorityQueue, userID string, queueLength *prometheus.GaugeVec, starvationTimeout time.Duration) *PriorityRequestQueue {
+	return &PriorityRequestQueue{queue: queue, userID: userID, queueLength: queueLength, starvationTimeout: starvationTimeout}
 }
 
 func (f *PriorityRequestQueue) enqueueRequest(r Request) {
-	f.queue.Enqueue(r)
+	pr := &priorityRequest{Request: r, enqueueTime: time.Now()}
+	f.queue.Enqueue(pr)
+	if f.starvationTimeout > 0 {
+		f.fifo = append(f.fifo, pr)
+	}
 	if f.queueLength != nil {
 		f.queueLength.WithLabelValues(f.userID, strconv.FormatInt(r.Priority(), 10), "priority").Inc()
 	}
 }
 
 func (f *PriorityRequestQueue) dequeueRequest(minPriority int64, checkMinPriority bool) Request {
-	if checkMinPriority && f.queue.Peek().Priority() < minPriority {
-		return nil
+	pr := f.dequeueStarvedRequest(minPriority, checkMinPriority)
+	if pr == nil {
+		if checkMinPriority && f.queue.Peek().Priority() < minPriority {
+			return nil
+		}
+		pr = f.queue.Dequeue().(*priorityRequest)
+		f.removeFromFIFO(pr)
 	}
-	r := f.queue.Dequeue()
 	if f.queueLength != nil {
-		f.queueLength.WithLabelValues(f.userID, strconv.FormatInt(r.Priority(), 10), "priority").Dec()
+		f.queueLength.WithLabelValues(f.userID, strconv.FormatInt(pr.Priority(), 10), "priority").Dec()
+	}
+	return pr.Request
+}
+
+// dequeueStarvedRequest dequeues the oldest request if it has been waiting for longer than the starvation timeout,
+// unless it has a lower priority than the one required.
+func (f *PriorityRequestQueue) dequeueStarvedRequest(minPriority int64, checkMinPriority bool) *priorityRequest {
+	if f.starvationTimeout <= 0 || len(f.fifo) == 0 {
+		return nil
+	}
+	pr := f.fifo[0]
+	if time.Since(pr.enqueueTime) < f.starvationTimeout || (checkMinPriority && pr.Priority() < minPriority) {
+		return nil
+	}
+	f.fifo = f.fifo[1:]
+	f.queue.Remove(pr)
+	return pr
+}
+
+// setStarvationTimeout updates the starvation timeout. The requests already in the queue when it's
+// enabled are only dequeued by priority.
+func (f *PriorityRequestQueue) setStarvationTimeout(starvationTimeout time.Duration) {
+	f.starvationTimeout = starvationTimeout
+	if starvationTimeout <= 0 {
+		f.fifo = nil
+	}
+}
+
+func (f *PriorityRequestQueue) removeFromFIFO(pr *priorityRequest) {
+	for i := range f.fifo {
+		if f.fifo[i] == pr {
+			f.fifo = append(f.fifo[:i], f.fifo[i+1:]...)
+			return
+		}
 	}
-	return r
 }
 
 func (f *PriorityRequestQueue) length() int {
@@ -3,6 +3,7 @@ package queue
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -64,7 +65,7 @@ func TestPriorityRequestQueue(t *testing.T) {
 		Help: "Number of queries in the queue.",
 	}, []string{"user", "priority", "type"})
 
-	queue := NewPriorityRequestQueue(util.NewPriorityQueue(nil), "userID", queueLength)
+	queue := NewPriorityRequestQueue(util.NewPriorityQueue(nil), "userID", queueLength, 0)
 	request1 := MockRequest{
 		id:       "request 1",
 		priority: 1,
@@ -144,3 +145,41 @@ func TestPriorityRequestQueue(t *testing.T) {
 		cortex_query_scheduler_queue_length{priority="3",type="priority",user="userID"} 0
 	`), "cortex_query_scheduler_queue_length"))
 }
+
+func TestPriorityRequestQueue_StarvationTimeout(t *testing.T) {
+	queue := NewPriorityRequestQueue(util.NewPriorityQueue(nil), "userID", nil, 100*time.Millisecond)
+	request1 := MockRequest{
+		id:       "request 1",
+		priority: 1,
+	}
+	request2 := MockRequest{
+		id:       "request 2",
+		priority: 2,
+	}
+	request3 := MockRequest{
+		id:       "request 3",
+		priority: 3,
+	}
+
+	queue.enqueueRequest(request1)
+	time.Sleep(200 * time.Millisecond)
+	queue.enqueueRequest(request2)
+	queue.enqueueRequest(request3)
+
+	// The starved request is not dequeued by the queriers reserved to higher priorities.
+	assert.Equal(t, request3, queue.dequeueRequest(2, true))
+	assert.Equal(t, request1, queue.dequeueRequest(0, false))
+	assert.Equal(t, 1, queue.length())
+
+	queue.enqueueRequest(request1)
+	assert.Equal(t, request2, queue.dequeueRequest(0, false))
+	assert.Equal(t, request1, queue.dequeueRequest(0, false))
+	assert.Equal(t, 0, queue.length())
+
+	queue.setStarvationTimeout(0)
+	queue.enqueueRequest(request1)
+	queue.enqueueRequest(request2)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, request2, queue.dequeueRequest(0, false))
+	assert.Equal(t, request1, queue.dequeueRequest(0, false))
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/go-kit/log"
@@ -43,6 +44,11 @@ var (
 	ErrUnsupportedStorageBackend = errors.New("unsupported storage backend")
 
 	ErrCustomerManagedKeyAccessDenied = errors.New("access denied: customer key")
+
+	ErrInvalidStoragePrefix    = errors.New("invalid storage prefix, it must only contain letters, digits, '-' and '_', with '/' as path segments separator")
+	ErrUnsupportedTenantLayout = errors.New("unsupported tenant layout")
+
+	storagePrefixRegexp = regexp.MustCompile(`^[\da-zA-Z_-]+(/[\da-zA-Z_-]+)*$`)
 )
 
 // Config holds configuration for accessing long-term storage.
@@ -55,6 +61,13 @@ type Config struct {
 	Swift      swift.Config      `yaml:"swift"`
 	Filesystem filesystem.Config `yaml:"filesystem"`
 
+	StoragePrefix string `yaml:"storage_prefix"`
+	TenantLayout  string `yaml:"tenant_layout"`
+
+	// Not used internally, meant to allow callers storing the tenants under a prefix (e.g. rules/<tenant>/...)
+	// to set the number of path segments before the tenant ID in the object keys.
+	TenantLayoutDepth int `yaml:"-"`
+
 	// Not used internally, meant to allow callers to wrap Buckets
 	// created using this config
 	Middlewares []func(objstore.InstrumentedBucket) (objstore.InstrumentedBucket, error) `yaml:"-"`
@@ -86,6 +99,8 @@ func (cfg *Config) RegisterFlagsWithPrefixAndBackend(prefix string, f *flag.Flag
 	cfg.Filesystem.RegisterFlagsWithPrefix(prefix, f)
 
 	f.StringVar(&cfg.Backend, prefix+"backend", defaultBackend, fmt.Sprintf("Backend storage to use. Supported backends are: %s.", strings.Join(cfg.supportedBackends(), ", ")))
+	f.StringVar(&cfg.StoragePrefix, prefix+"storage-prefix", "", "Prefix of the keys of all the objects stored in the bucket, to share the bucket with other clusters or applications. It can only contain letters, digits, '-' and '_', with '/' as path segments separator.")
+	f.StringVar(&cfg.TenantLayout, prefix+"tenant-layout", TenantLayoutFlat, fmt.Sprintf("[Experimental] Layout of the objects of the tenants in the bucket. Supported values are: %s. The flat layout stores the objects of a tenant under <tenant>/, while the hashed layout stores them under <hash>/<tenant>/, where the hash is one of 256 prefixes computed from the tenant ID, to spread the tenants across the partitions of the object storage (e.g. to avoid S3 hot partitions).", strings.Join(supportedTenantLayouts, ", ")))
 }
 
 func (cfg *Config) Validate() error {
@@ -99,6 +114,14 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.StoragePrefix != "" && !storagePrefixRegexp.MatchString(cfg.StoragePrefix) {
+		return ErrInvalidStoragePrefix
+	}
+
+	if cfg.TenantLayout != "" && !util.StringsContain(supportedTenantLayouts, cfg.TenantLayout) {
+		return ErrUnsupportedTenantLayout
+	}
+
 	return nil
 }
 
@@ -124,6 +147,13 @@ func NewClient(ctx context.Context, cfg Config, name string, logger log.Logger,
 		return nil, err
 	}
 
+	if cfg.StoragePrefix != "" {
+		client = NewPrefixedBucketClient(client, cfg.StoragePrefix)
+	}
+	if cfg.TenantLayout == TenantLayoutHashed {
+		client = NewHashedTenantBucketClient(client, cfg.TenantLayoutDepth)
+	}
+
 	iClient := opentracing.WrapWithTraces(bucketWithMetrics(client, name, reg))
 
 	// Wrap the client with any provided middleware
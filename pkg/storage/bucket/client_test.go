@@ -5,6 +5,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 
@@ -100,6 +103,86 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestNewClient_ShouldApplyStoragePrefixAndTenantLayout(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	cfg := Config{}
+	flagext.DefaultValues(&cfg)
+	cfg.Backend = Filesystem
+	cfg.Filesystem.Directory = dir
+	cfg.StoragePrefix = "cluster-1"
+	cfg.TenantLayout = TenantLayoutHashed
+	require.NoError(t, cfg.Validate())
+
+	bucketClient, err := NewClient(ctx, cfg, "test", util_log.Logger, nil)
+	require.NoError(t, err)
+	require.NoError(t, bucketClient.Upload(ctx, "user-1/block-1/meta.json", strings.NewReader("{}")))
+
+	_, err = os.Stat(filepath.Join(dir, "cluster-1", TenantHashPrefix("user-1"), "user-1", "block-1", "meta.json"))
+	require.NoError(t, err)
+
+	var users []string
+	require.NoError(t, bucketClient.Iter(ctx, "", func(name string) error {
+		users = append(users, name)
+		return nil
+	}))
+	assert.Equal(t, []string{"user-1/"}, users)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		setup       func(cfg *Config)
+		expectedErr error
+	}{
+		"should pass with the default config": {
+			setup:       func(cfg *Config) {},
+			expectedErr: nil,
+		},
+		"should pass with a storage prefix made of multiple segments": {
+			setup: func(cfg *Config) {
+				cfg.StoragePrefix = "cortex/cluster_1"
+			},
+			expectedErr: nil,
+		},
+		"should fail with a storage prefix ending with the separator": {
+			setup: func(cfg *Config) {
+				cfg.StoragePrefix = "cortex/"
+			},
+			expectedErr: ErrInvalidStoragePrefix,
+		},
+		"should fail with a storage prefix containing dots": {
+			setup: func(cfg *Config) {
+				cfg.StoragePrefix = "../cortex"
+			},
+			expectedErr: ErrInvalidStoragePrefix,
+		},
+		"should pass with the hashed tenant layout": {
+			setup: func(cfg *Config) {
+				cfg.TenantLayout = TenantLayoutHashed
+			},
+			expectedErr: nil,
+		},
+		"should fail with an unsupported tenant layout": {
+			setup: func(cfg *Config) {
+				cfg.TenantLayout = "unknown"
+			},
+			expectedErr: ErrUnsupportedTenantLayout,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			cfg := Config{}
+			flagext.DefaultValues(&cfg)
+			cfg.Backend = Filesystem
+			testData.setup(&cfg)
+
+			assert.Equal(t, testData.expectedErr, cfg.Validate())
+		})
+	}
+}
+
 func Test_Thanos_Metrics(t *testing.T) {
 	reg := prometheus.NewPedanticRegistry()
 	ctx := context.Background()
@@ -0,0 +1,206 @@
+package bucket
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/thanos-io/objstore"
+)
+
+const (
+	// TenantLayoutFlat stores the objects of a tenant under <tenant>/.
+	TenantLayoutFlat = "flat"
+
+	// TenantLayoutHashed stores the objects of a tenant under <hash>/<tenant>/, where the
+	// hash is one of 256 prefixes computed from the tenant ID, to spread the tenants across
+	// the partitions of the object storage.
+	TenantLayoutHashed = "hashed"
+)
+
+var supportedTenantLayouts = []string{TenantLayoutFlat, TenantLayoutHashed}
+
+// TenantHashPrefix returns the prefix of the objects of the tenant in the hashed layout.
+func TenantHashPrefix(userID string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(userID))
+	return fmt.Sprintf("%02x", h.Sum32()&0xff)
+}
+
+// IsTenantHashPrefix returns whether the path segment is a tenant hash prefix of the hashed layout.
+func IsTenantHashPrefix(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// HashedTenantBucketClient is a bucket client which stores the objects of each tenant under
+// the tenant hash prefix, while exposing the object keys of the flat layout to its callers.
+type HashedTenantBucketClient struct {
+	bucket objstore.Bucket
+
+	// depth is the number of path segments before the tenant ID in the object keys.
+	depth int
+}
+
+// NewHashedTenantBucketClient returns a new HashedTenantBucketClient. The depth is the number of
+// path segments before the tenant ID in the object keys, e.g. 1 for keys like rules/<tenant>/...
+func NewHashedTenantBucketClient(bucket objstore.Bucket, depth int) *HashedTenantBucketClient {
+	return &HashedTenantBucketClient{
+		bucket: bucket,
+		depth:  depth,
+	}
+}
+
+// fullName returns the key of the object in the hashed layout. The objects which are not stored
+// within a tenant directory (e.g. the alertmanager configs stored as alerts/<tenant>) are left
+// untouched.
+func (b *HashedTenantBucketClient) fullName(name string) string {
+	parts := strings.Split(name, objstore.DirDelim)
+	if len(parts) < b.depth+2 || parts[b.depth] == "" {
+		return name
+	}
+
+	full := make([]string, 0, len(parts)+1)
+	full = append(full, parts[:b.depth]...)
+	full = append(full, TenantHashPrefix(parts[b.depth]))
+	full = append(full, parts[b.depth:]...)
+	return strings.Join(full, objstore.DirDelim)
+}
+
+// flatName returns the key of the object in the flat layout, removing the tenant hash prefix.
+func (b *HashedTenantBucketClient) flatName(name string) string {
+	parts := strings.Split(name, objstore.DirDelim)
+	if len(parts) < b.depth+2 || !IsTenantHashPrefix(parts[b.depth]) {
+		return name
+	}
+
+	flat := make([]string, 0, len(parts)-1)
+	flat = append(flat, parts[:b.depth]...)
+	flat = append(flat, parts[b.depth+1:]...)
+	return strings.Join(flat, objstore.DirDelim)
+}
+
+// Close implements io.Closer
+func (b *HashedTenantBucketClient) Close() error {
+	return b.bucket.Close()
+}
+
+// Upload the contents of the reader as an object into the bucket.
+func (b *HashedTenantBucketClient) Upload(ctx context.Context, name string, r io.Reader) error {
+	return b.bucket.Upload(ctx, b.fullName(name), r)
+}
+
+// Delete removes the object with the given name.
+func (b *HashedTenantBucketClient) Delete(ctx context.Context, name string) error {
+	return b.bucket.Delete(ctx, b.fullName(name))
+}
+
+// Name returns the bucket name for the provider.
+func (b *HashedTenantBucketClient) Name() string { return b.bucket.Name() }
+
+// Iter calls f for each entry in the given directory. The tenant hash prefixes are removed from
+// the entries, and iterating the directory of the tenants iterates the tenants of all the prefixes.
+func (b *HashedTenantBucketClient) Iter(ctx context.Context, dir string, f func(string) error, options ...objstore.IterOption) error {
+	parent := strings.Trim(dir, objstore.DirDelim)
+	levels := 0
+	if parent != "" {
+		levels = strings.Count(parent, objstore.DirDelim) + 1
+		parent += objstore.DirDelim
+	}
+
+	// Iterating within a tenant directory.
+	if levels > b.depth {
+		return b.bucket.Iter(ctx, b.fullName(parent), func(name string) error {
+			return f(b.flatName(name))
+		}, options...)
+	}
+
+	// Iterating a parent directory of the tenants directory, non recursively.
+	if levels < b.depth && !objstore.ApplyIterOptions(options...).Recursive {
+		return b.bucket.Iter(ctx, dir, f, options...)
+	}
+
+	// Iterating the tenants directory or recursively iterating a parent directory: the entries are
+	// collected from all the tenant hash prefixes, and sorted like the object storage does.
+	var entries []string
+	err := b.bucket.Iter(ctx, dir, func(name string) error {
+		if levels == b.depth && strings.HasSuffix(name, objstore.DirDelim) && IsTenantHashPrefix(strings.TrimSuffix(strings.TrimPrefix(name, parent), objstore.DirDelim)) {
+			return b.bucket.Iter(ctx, name, func(tenant string) error {
+				entries = append(entries, b.flatName(tenant))
+				return nil
+			}, options...)
+		}
+
+		entries = append(entries, b.flatName(name))
+		return nil
+	}, options...)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(entries)
+	for _, entry := range entries {
+		if err := f(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns a reader for the given object name.
+func (b *HashedTenantBucketClient) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	return b.bucket.Get(ctx, b.fullName(name))
+}
+
+// GetRange returns a new range reader for the given object name and range.
+func (b *HashedTenantBucketClient) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	return b.bucket.GetRange(ctx, b.fullName(name), off, length)
+}
+
+// Exists checks if the given object exists in the bucket.
+func (b *HashedTenantBucketClient) Exists(ctx context.Context, name string) (bool, error) {
+	return b.bucket.Exists(ctx, b.fullName(name))
+}
+
+// IsObjNotFoundErr returns true if error means that object is not found. Relevant to Get operations.
+func (b *HashedTenantBucketClient) IsObjNotFoundErr(err error) bool {
+	return b.bucket.IsObjNotFoundErr(err)
+}
+
+// IsAccessDeniedErr returns true if access to object is denied.
+func (b *HashedTenantBucketClient) IsAccessDeniedErr(err error) bool {
+	return b.bucket.IsAccessDeniedErr(err)
+}
+
+// Attributes returns attributes of the specified object.
+func (b *HashedTenantBucketClient) Attributes(ctx context.Context, name string) (objstore.ObjectAttributes, error) {
+	return b.bucket.Attributes(ctx, b.fullName(name))
+}
+
+// ReaderWithExpectedErrs allows to specify a filter that marks certain errors as expected, so it will not increment
+// thanos_objstore_bucket_operation_failures_total metric.
+func (b *HashedTenantBucketClient) ReaderWithExpectedErrs(fn objstore.IsOpFailureExpectedFunc) objstore.BucketReader {
+	return b.WithExpectedErrs(fn)
+}
+
+// WithExpectedErrs allows to specify a filter that marks certain errors as expected, so it will not increment
+// thanos_objstore_bucket_operation_failures_total metric.
+func (b *HashedTenantBucketClient) WithExpectedErrs(fn objstore.IsOpFailureExpectedFunc) objstore.Bucket {
+	if ib, ok := b.bucket.(objstore.InstrumentedBucket); ok {
+		return &HashedTenantBucketClient{
+			bucket: ib.WithExpectedErrs(fn),
+			depth:  b.depth,
+		}
+	}
+	return b
+}
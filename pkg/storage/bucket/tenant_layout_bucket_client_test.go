@@ -0,0 +1,115 @@
+package bucket
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestHashedTenantBucketClient(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		depth   int
+		objects []string
+		// Expected entries by iterated directory.
+		expectedIter map[string][]string
+		// Expected entries by recursively iterated directory.
+		expectedRecursiveIter map[string][]string
+	}{
+		"tenants at the root of the bucket": {
+			depth:   0,
+			objects: []string{"user-1/block-1/meta.json", "user-2/block-1/meta.json", "user-2/bucket-index.json.gz"},
+			expectedIter: map[string][]string{
+				"":                {"user-1/", "user-2/"},
+				"user-2":          {"user-2/block-1/", "user-2/bucket-index.json.gz"},
+				"user-2/block-1/": {"user-2/block-1/meta.json"},
+			},
+			expectedRecursiveIter: map[string][]string{
+				"":       {"user-1/block-1/meta.json", "user-2/block-1/meta.json", "user-2/bucket-index.json.gz"},
+				"user-1": {"user-1/block-1/meta.json"},
+			},
+		},
+		"tenants within a prefix": {
+			depth:   1,
+			objects: []string{"rules/user-1/namespace/group", "rules/user-2/namespace/group", "alerts/user-1"},
+			expectedIter: map[string][]string{
+				"":       {"alerts/", "rules/"},
+				"rules/": {"rules/user-1/", "rules/user-2/"},
+				"alerts": {"alerts/user-1"},
+			},
+			expectedRecursiveIter: map[string][]string{
+				"":             {"alerts/user-1", "rules/user-1/namespace/group", "rules/user-2/namespace/group"},
+				"rules":        {"rules/user-1/namespace/group", "rules/user-2/namespace/group"},
+				"rules/user-2": {"rules/user-2/namespace/group"},
+			},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			raw := objstore.NewInMemBucket()
+			bkt := NewHashedTenantBucketClient(raw, testData.depth)
+
+			for _, name := range testData.objects {
+				require.NoError(t, bkt.Upload(ctx, name, strings.NewReader(name)))
+
+				// The object should be stored under the tenant hash prefix, if it's within a tenant directory.
+				parts := strings.Split(name, objstore.DirDelim)
+				expectedKey := name
+				if len(parts) > testData.depth+1 {
+					expectedKey = strings.Join(append(append(append([]string{}, parts[:testData.depth]...), TenantHashPrefix(parts[testData.depth])), parts[testData.depth:]...), objstore.DirDelim)
+				}
+				exists, err := raw.Exists(ctx, expectedKey)
+				require.NoError(t, err)
+				assert.True(t, exists, expectedKey)
+
+				// The object should be readable with its flat layout key.
+				r, err := bkt.Get(ctx, name)
+				require.NoError(t, err)
+				content, err := io.ReadAll(r)
+				require.NoError(t, err)
+				assert.Equal(t, name, string(content))
+			}
+
+			for dir, expected := range testData.expectedIter {
+				var actual []string
+				require.NoError(t, bkt.Iter(ctx, dir, func(name string) error {
+					actual = append(actual, name)
+					return nil
+				}))
+				assert.ElementsMatch(t, expected, actual, dir)
+			}
+
+			for dir, expected := range testData.expectedRecursiveIter {
+				var actual []string
+				require.NoError(t, bkt.Iter(ctx, dir, func(name string) error {
+					actual = append(actual, name)
+					return nil
+				}, objstore.WithRecursiveIter))
+				assert.ElementsMatch(t, expected, actual, dir)
+			}
+
+			// Deleting the objects through the client should leave the bucket empty.
+			for _, name := range testData.objects {
+				require.NoError(t, bkt.Delete(ctx, name))
+			}
+			assert.Empty(t, raw.Objects())
+		})
+	}
+}
+
+func TestTenantHashPrefix(t *testing.T) {
+	prefix := TenantHashPrefix("user-1")
+	assert.Equal(t, prefix, TenantHashPrefix("user-1"))
+	assert.True(t, IsTenantHashPrefix(prefix))
+
+	assert.False(t, IsTenantHashPrefix("user-1"))
+	assert.False(t, IsTenantHashPrefix("0g"))
+	assert.False(t, IsTenantHashPrefix("0A"))
+}
@@ -149,6 +149,37 @@ func (l *Loader) GetIndex(ctx context.Context, userID string) (*Index, Status, e
 	return idx, ss, nil
 }
 
+// RefreshIndex reloads the bucket index of the given user from the bucket, if it's cached in-memory.
+// Indexes not cached are loaded from the bucket when requested anyway.
+func (l *Loader) RefreshIndex(ctx context.Context, userID string) error {
+	l.indexesMx.RLock()
+	_, ok := l.indexes[userID]
+	l.indexesMx.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	l.loadAttempts.Inc()
+	startTime := time.Now()
+	ss, err := ReadSyncStatus(ctx, l.bkt, userID, l.logger)
+	if err != nil {
+		level.Warn(l.logger).Log("msg", "unable to read bucket index status", "user", userID, "err", err)
+	}
+
+	idx, err := ReadIndex(ctx, l.bkt, userID, l.cfgProvider, l.logger)
+	if err != nil && !errors.Is(err, ErrIndexNotFound) && !errors.Is(err, bucket.ErrCustomerManagedKeyAccessDenied) {
+		if !errors.Is(err, context.Canceled) {
+			l.loadFailures.Inc()
+		}
+		return err
+	}
+
+	l.loadDuration.Observe(time.Since(startTime).Seconds())
+	l.cacheIndex(userID, idx, ss, err)
+	level.Info(l.logger).Log("msg", "refreshed bucket index", "user", userID, "duration", time.Since(startTime))
+	return nil
+}
+
 func (l *Loader) cacheIndex(userID string, idx *Index, ss Status, err error) {
 	if errors.Is(err, context.Canceled) {
 		level.Info(l.logger).Log("msg", "skipping cache bucket index", "err", err)
@@ -0,0 +1,289 @@
+package bucketindex
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+)
+
+const (
+	// TombstonesPathname is the location, relative to the tenant's bucket location, of the
+	// tombstones of the series deletion requests.
+	TombstonesPathname = "tombstones"
+
+	tombstoneFileExtension = ".json"
+)
+
+// DeleteRequestState is the state of a series deletion request, stored as the extension of
+// the tombstone filename.
+type DeleteRequestState string
+
+const (
+	// StatePending is the state of the requests whose series have not been deleted from the
+	// blocks yet. The series are filtered out at query time.
+	StatePending DeleteRequestState = "pending"
+	// StateProcessed is the state of the requests whose series have been deleted from the blocks.
+	StateProcessed DeleteRequestState = "processed"
+	// StateCancelled is the state of the requests which have been cancelled.
+	StateCancelled DeleteRequestState = "deleted"
+)
+
+var (
+	ErrTombstoneNotFound = errors.New("tombstone not found")
+
+	// The order of the states, used to pick the latest state of a request whose old tombstone
+	// has not been deleted.
+	statesOrder = map[DeleteRequestState]int{
+		StatePending:   0,
+		StateProcessed: 1,
+		StateCancelled: 1,
+	}
+)
+
+// Tombstone is a series deletion request of a tenant: the samples within StartTime and
+// EndTime (milliseconds, both included) of the series matching any of the selectors are deleted.
+type Tombstone struct {
+	RequestID        string             `json:"request_id"`
+	UserID           string             `json:"user_id"`
+	StartTime        int64              `json:"start_time"`
+	EndTime          int64              `json:"end_time"`
+	RequestCreatedAt int64              `json:"request_created_at"`
+	StateCreatedAt   int64              `json:"state_created_at"`
+	Selectors        []string           `json:"selectors"`
+	State            DeleteRequestState `json:"state"`
+
+	matchers [][]*labels.Matcher
+}
+
+// NewTombstone returns the tombstone of a series deletion request. The request ID is a hash
+// of the request parameters, so that a request sent twice results in a single tombstone.
+func NewTombstone(userID string, requestCreatedAt, stateCreatedAt, startTime, endTime int64, selectors []string, state DeleteRequestState) (*Tombstone, error) {
+	t := &Tombstone{
+		RequestID:        deleteRequestID(startTime, endTime, selectors),
+		UserID:           userID,
+		StartTime:        startTime,
+		EndTime:          endTime,
+		RequestCreatedAt: requestCreatedAt,
+		StateCreatedAt:   stateCreatedAt,
+		Selectors:        selectors,
+		State:            state,
+	}
+	if err := t.parseMatchers(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func deleteRequestID(startTime, endTime int64, selectors []string) string {
+	sorted := append([]string(nil), selectors...)
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%d,%d,%s", startTime, endTime, strings.Join(sorted, "&"))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (t *Tombstone) parseMatchers() error {
+	t.matchers = make([][]*labels.Matcher, 0, len(t.Selectors))
+	for _, selector := range t.Selectors {
+		matchers, err := parser.ParseMetricSelector(selector)
+		if err != nil {
+			return errors.Wrapf(err, "parse selector %s", selector)
+		}
+		t.matchers = append(t.matchers, matchers)
+	}
+	return nil
+}
+
+// Matchers returns the label matchers of each selector of the tombstone.
+func (t *Tombstone) Matchers() [][]*labels.Matcher {
+	return t.matchers
+}
+
+// MatchesSeries returns whether the series matches any of the selectors of the tombstone.
+func (t *Tombstone) MatchesSeries(lset labels.Labels) bool {
+	for _, matchers := range t.matchers {
+		if matchesAll(matchers, lset) {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlaps returns whether the tombstone deletes samples within the range minT and maxT
+// (milliseconds, both included).
+func (t *Tombstone) Overlaps(minT, maxT int64) bool {
+	return t.StartTime <= maxT && minT <= t.EndTime
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Tombstone) UnmarshalJSON(data []byte) error {
+	type plain Tombstone
+	if err := json.Unmarshal(data, (*plain)(t)); err != nil {
+		return err
+	}
+	return t.parseMatchers()
+}
+
+func matchesAll(matchers []*labels.Matcher, lset labels.Labels) bool {
+	for _, m := range matchers {
+		if !m.Matches(lset.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Tombstones is the list of the tombstones of a tenant.
+type Tombstones []*Tombstone
+
+// Active returns the tombstones of the requests which have not been cancelled.
+func (s Tombstones) Active() Tombstones {
+	var out Tombstones
+	for _, t := range s {
+		if t.State != StateCancelled {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// TombstoneFilepath returns the path, relative to the tenant's bucket location, of the tombstone
+// of a request in the given state.
+func TombstoneFilepath(requestID string, state DeleteRequestState) string {
+	return path.Join(TombstonesPathname, requestID+tombstoneFileExtension+"."+string(state))
+}
+
+// parseTombstoneFilename returns the request ID and the state of a tombstone filename.
+func parseTombstoneFilename(name string) (string, DeleteRequestState, bool) {
+	parts := strings.SplitN(path.Base(name), tombstoneFileExtension+".", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	state := DeleteRequestState(parts[1])
+	if _, ok := statesOrder[state]; !ok {
+		return "", "", false
+	}
+	return parts[0], state, true
+}
+
+// WriteTombstone uploads the tombstone to the tenant's tombstones location in the bucket.
+func WriteTombstone(ctx context.Context, bkt objstore.Bucket, userID string, cfgProvider bucket.TenantConfigProvider, t *Tombstone) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return errors.Wrap(err, "serialize tombstone")
+	}
+
+	userBkt := bucket.NewUserBucketClient(userID, bkt, cfgProvider)
+	return errors.Wrap(userBkt.Upload(ctx, TombstoneFilepath(t.RequestID, t.State), bytes.NewReader(data)), "upload tombstone")
+}
+
+// ReadTombstones returns the tombstones of all the series deletion requests of the tenant.
+// When a request has more than one tombstone, because the tombstone of its previous state
+// has not been deleted, the tombstone of its latest state is returned.
+func ReadTombstones(ctx context.Context, bkt objstore.Bucket, userID string, cfgProvider bucket.TenantConfigProvider) (Tombstones, error) {
+	return readTombstones(ctx, bucket.NewUserBucketClient(userID, bkt, cfgProvider))
+}
+
+func readTombstones(ctx context.Context, userBkt objstore.InstrumentedBucket) (Tombstones, error) {
+	states := map[string]DeleteRequestState{}
+	err := userBkt.Iter(ctx, TombstonesPathname+"/", func(name string) error {
+		requestID, state, ok := parseTombstoneFilename(name)
+		if !ok {
+			return nil
+		}
+		if prev, ok := states[requestID]; !ok || statesOrder[state] > statesOrder[prev] {
+			states[requestID] = state
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list tombstones")
+	}
+
+	out := make(Tombstones, 0, len(states))
+	for requestID, state := range states {
+		t, err := readTombstone(ctx, userBkt, TombstoneFilepath(requestID, state))
+		if errors.Is(err, ErrTombstoneNotFound) {
+			// The tombstone has been moved to another state between the listing and now.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].RequestCreatedAt < out[j].RequestCreatedAt
+	})
+	return out, nil
+}
+
+// ReadTombstone returns the tombstone of the request in its latest state.
+func ReadTombstone(ctx context.Context, bkt objstore.Bucket, userID string, cfgProvider bucket.TenantConfigProvider, requestID string) (*Tombstone, error) {
+	userBkt := bucket.NewUserBucketClient(userID, bkt, cfgProvider)
+
+	for _, state := range []DeleteRequestState{StateProcessed, StateCancelled, StatePending} {
+		t, err := readTombstone(ctx, userBkt, TombstoneFilepath(requestID, state))
+		if errors.Is(err, ErrTombstoneNotFound) {
+			continue
+		}
+		return t, err
+	}
+	return nil, ErrTombstoneNotFound
+}
+
+func readTombstone(ctx context.Context, userBkt objstore.InstrumentedBucket, name string) (*Tombstone, error) {
+	r, err := userBkt.WithExpectedErrs(userBkt.IsObjNotFoundErr).Get(ctx, name)
+	if userBkt.IsObjNotFoundErr(err) {
+		return nil, ErrTombstoneNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "read tombstone %s", name)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read tombstone %s", name)
+	}
+
+	t := &Tombstone{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, errors.Wrapf(err, "decode tombstone %s", name)
+	}
+	return t, nil
+}
+
+// UpdateTombstoneState moves the tombstone to the new state: the tombstone of the new state
+// is written before the tombstone of the previous state is deleted.
+func UpdateTombstoneState(ctx context.Context, bkt objstore.Bucket, userID string, cfgProvider bucket.TenantConfigProvider, t *Tombstone, state DeleteRequestState, stateCreatedAt int64) (*Tombstone, error) {
+	updated, err := NewTombstone(t.UserID, t.RequestCreatedAt, stateCreatedAt, t.StartTime, t.EndTime, t.Selectors, state)
+	if err != nil {
+		return nil, err
+	}
+	if err := WriteTombstone(ctx, bkt, userID, cfgProvider, updated); err != nil {
+		return nil, err
+	}
+
+	userBkt := bucket.NewUserBucketClient(userID, bkt, cfgProvider)
+	if err := userBkt.Delete(ctx, TombstoneFilepath(t.RequestID, t.State)); err != nil && !userBkt.IsObjNotFoundErr(err) {
+		return nil, errors.Wrap(err, "delete tombstone of the previous state")
+	}
+	return updated, nil
+}
@@ -0,0 +1,93 @@
+package bucketindex
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cortex_testutil "github.com/cortexproject/cortex/pkg/storage/tsdb/testutil"
+)
+
+func TestNewTombstone(t *testing.T) {
+	t1, err := NewTombstone("user-1", 10, 10, 0, 100, []string{`{a="1"}`, `{b=~"2|3"}`}, StatePending)
+	require.NoError(t, err)
+
+	// The request ID doesn't depend on the order of the selectors.
+	t2, err := NewTombstone("user-1", 20, 20, 0, 100, []string{`{b=~"2|3"}`, `{a="1"}`}, StatePending)
+	require.NoError(t, err)
+	assert.Equal(t, t1.RequestID, t2.RequestID)
+
+	t3, err := NewTombstone("user-1", 10, 10, 0, 101, []string{`{a="1"}`, `{b=~"2|3"}`}, StatePending)
+	require.NoError(t, err)
+	assert.NotEqual(t, t1.RequestID, t3.RequestID)
+
+	assert.True(t, t1.MatchesSeries(labels.FromStrings("a", "1", "c", "4")))
+	assert.True(t, t1.MatchesSeries(labels.FromStrings("b", "3")))
+	assert.False(t, t1.MatchesSeries(labels.FromStrings("a", "2", "b", "4")))
+
+	assert.True(t, t1.Overlaps(100, 200))
+	assert.True(t, t1.Overlaps(-10, 0))
+	assert.False(t, t1.Overlaps(101, 200))
+
+	_, err = NewTombstone("user-1", 10, 10, 0, 100, []string{`{a="1"`}, StatePending)
+	assert.Error(t, err)
+}
+
+func TestParseTombstoneFilename(t *testing.T) {
+	requestID, state, ok := parseTombstoneFilename(TombstoneFilepath("abc", StatePending))
+	assert.True(t, ok)
+	assert.Equal(t, "abc", requestID)
+	assert.Equal(t, StatePending, state)
+
+	_, _, ok = parseTombstoneFilename("tombstones/abc.json")
+	assert.False(t, ok)
+
+	_, _, ok = parseTombstoneFilename("tombstones/abc.json.unknown")
+	assert.False(t, ok)
+}
+
+func TestTombstones_ReadWriteUpdate(t *testing.T) {
+	const userID = "user-1"
+
+	ctx := context.Background()
+	bkt, _ := cortex_testutil.PrepareFilesystemBucket(t)
+
+	t1, err := NewTombstone(userID, 10, 10, 0, 100, []string{`{a="1"}`}, StatePending)
+	require.NoError(t, err)
+	t2, err := NewTombstone(userID, 20, 20, 0, 200, []string{`{a="2"}`}, StatePending)
+	require.NoError(t, err)
+	require.NoError(t, WriteTombstone(ctx, bkt, userID, nil, t2))
+	require.NoError(t, WriteTombstone(ctx, bkt, userID, nil, t1))
+
+	_, err = ReadTombstone(ctx, bkt, userID, nil, "unknown")
+	assert.ErrorIs(t, err, ErrTombstoneNotFound)
+
+	actual, err := ReadTombstone(ctx, bkt, userID, nil, t1.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, t1, actual)
+
+	updated, err := UpdateTombstoneState(ctx, bkt, userID, nil, t2, StateCancelled, 30)
+	require.NoError(t, err)
+	assert.Equal(t, StateCancelled, updated.State)
+	assert.Equal(t, int64(30), updated.StateCreatedAt)
+
+	// The tombstone of the previous state has been deleted.
+	exists, err := bkt.Exists(ctx, userID+"/"+TombstoneFilepath(t2.RequestID, StatePending))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// The tombstones are sorted by request creation time.
+	tombstones, err := ReadTombstones(ctx, bkt, userID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Tombstones{t1, updated}, tombstones)
+	assert.Equal(t, Tombstones{t1}, tombstones.Active())
+
+	// The tombstone of the latest state is returned when the previous one has not been deleted.
+	require.NoError(t, WriteTombstone(ctx, bkt, userID, nil, t2))
+	tombstones, err = ReadTombstones(ctx, bkt, userID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Tombstones{t1, updated}, tombstones)
+}
@@ -66,14 +66,29 @@ func (w *Updater) UpdateIndex(ctx context.Context, old *Index) (*Index, map[ulid
 		return nil, nil, 0, err
 	}
 
+	tombstones, err := w.updateTombstones(ctx)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
 	return &Index{
 		Version:            IndexVersion1,
 		Blocks:             blocks,
 		BlockDeletionMarks: blockDeletionMarks,
+		Tombstones:         tombstones,
 		UpdatedAt:          time.Now().Unix(),
 	}, partials, totalBlocksBlocksMarkedForNoCompaction, nil
 }
 
+func (w *Updater) updateTombstones(ctx context.Context) (Tombstones, error) {
+	// Tombstones are not immutable, since they move from a state to another, so they're all read again.
+	tombstones, err := readTombstones(ctx, w.bkt)
+	if err != nil {
+		return nil, err
+	}
+	return tombstones.Active(), nil
+}
+
 func (w *Updater) updateBlocks(ctx context.Context, old []*Block, deletedBlocks map[ulid.ULID]struct{}) (blocks []*Block, partials map[ulid.ULID]error, _ error) {
 	discovered := map[ulid.ULID]struct{}{}
 	partials = map[ulid.ULID]error{}
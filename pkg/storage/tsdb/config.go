@@ -46,15 +46,20 @@ var (
 	errInvalidOpeningConcurrency     = errors.New("invalid TSDB opening concurrency")
 	errInvalidCompactionInterval     = errors.New("invalid TSDB compaction interval")
 	errInvalidCompactionConcurrency  = errors.New("invalid TSDB compaction concurrency")
+	errInvalidCompactionTenantJitter = errors.New("invalid TSDB compaction tenant jitter: must be greater than or equal to 0 and not greater than half of the smallest block range")
 	errInvalidWALSegmentSizeBytes    = errors.New("invalid TSDB WAL segment size bytes")
 	errInvalidStripeSize             = errors.New("invalid TSDB stripe size")
 	errInvalidOutOfOrderCapMax       = errors.New("invalid TSDB OOO chunks capacity (in samples)")
+	errInvalidSamplesPerChunk        = errors.New("invalid TSDB head chunks samples per chunk")
+	errInvalidWriteQueueSize         = errors.New("invalid TSDB head chunks write queue size")
 	errEmptyBlockranges              = errors.New("empty block ranges for TSDB")
 	errUnSupportedWALCompressionType = errors.New("unsupported WAL compression type, valid types are (zstd, snappy and '')")
 
 	ErrInvalidBucketIndexBlockDiscoveryStrategy = errors.New("bucket index block discovery strategy can only be enabled when bucket index is enabled")
 	ErrBlockDiscoveryStrategy                   = errors.New("invalid block discovery strategy")
 	ErrInvalidTokenBucketBytesLimiterMode       = errors.New("invalid token bucket bytes limiter mode")
+	ErrIndexHeaderPrefetchWithoutLazyLoading    = errors.New("the index-header prefetching requires the index-header lazy loading to be enabled")
+	ErrInvalidIndexHeaderPrefetchMaxBlocks      = errors.New("invalid index-header prefetch max blocks, the value must be greater than 0")
 )
 
 // BlocksStorageConfig holds the config information for the blocks storage.
@@ -127,23 +132,31 @@ func (cfg *BlocksStorageConfig) Validate() error {
 //
 //nolint:revive
 type TSDBConfig struct {
-	Dir                       string        `yaml:"dir"`
-	BlockRanges               DurationList  `yaml:"block_ranges_period"`
-	Retention                 time.Duration `yaml:"retention_period"`
-	ShipInterval              time.Duration `yaml:"ship_interval"`
-	ShipConcurrency           int           `yaml:"ship_concurrency"`
-	HeadCompactionInterval    time.Duration `yaml:"head_compaction_interval"`
-	HeadCompactionConcurrency int           `yaml:"head_compaction_concurrency"`
-	HeadCompactionIdleTimeout time.Duration `yaml:"head_compaction_idle_timeout"`
-	HeadChunksWriteBufferSize int           `yaml:"head_chunks_write_buffer_size_bytes"`
-	StripeSize                int           `yaml:"stripe_size"`
-	WALCompressionEnabled     bool          `yaml:"wal_compression_enabled"`
-	WALCompressionType        string        `yaml:"wal_compression_type"`
-	WALSegmentSizeBytes       int           `yaml:"wal_segment_size_bytes"`
-	FlushBlocksOnShutdown     bool          `yaml:"flush_blocks_on_shutdown"`
-	CloseIdleTSDBTimeout      time.Duration `yaml:"close_idle_tsdb_timeout"`
+	Dir                          string        `yaml:"dir"`
+	BlockRanges                  DurationList  `yaml:"block_ranges_period"`
+	Retention                    time.Duration `yaml:"retention_period"`
+	ShipInterval                 time.Duration `yaml:"ship_interval"`
+	ShipConcurrency              int           `yaml:"ship_concurrency"`
+	HeadCompactionInterval       time.Duration `yaml:"head_compaction_interval"`
+	HeadCompactionConcurrency    int           `yaml:"head_compaction_concurrency"`
+	HeadCompactionIdleTimeout    time.Duration `yaml:"head_compaction_idle_timeout"`
+	HeadCompactionTenantJitter   time.Duration `yaml:"head_compaction_tenant_jitter"`
+	SkipCorruptedWAL             bool          `yaml:"skip_corrupted_wal"`
+	WALReplayDuplicatesPeriod    time.Duration `yaml:"wal_replay_duplicates_period"`
+	HeadChunksWriteBufferSize    int           `yaml:"head_chunks_write_buffer_size_bytes"`
+	StripeSize                   int           `yaml:"stripe_size"`
+	WALCompressionEnabled        bool          `yaml:"wal_compression_enabled"`
+	WALCompressionType           string        `yaml:"wal_compression_type"`
+	WALSegmentSizeBytes          int           `yaml:"wal_segment_size_bytes"`
+	FlushBlocksOnShutdown        bool          `yaml:"flush_blocks_on_shutdown"`
+	FlushBlocksOnShutdownTimeout time.Duration `yaml:"flush_blocks_on_shutdown_timeout"`
+	CloseIdleTSDBTimeout         time.Duration `yaml:"close_idle_tsdb_timeout"`
 	// The size of the in-memory queue used before flushing chunks to the disk.
 	HeadChunksWriteQueueSize int `yaml:"head_chunks_write_queue_size"`
+	// The target number of samples of a head chunk before it's cut and memory-mapped.
+	HeadChunksSamplesPerChunk int `yaml:"head_chunks_samples_per_chunk"`
+	// Enables the TSDB isolation, which Cortex doesn't need given queries are not consistent across ingesters anyway.
+	IsolationEnabled bool `yaml:"isolation_enabled"`
 
 	// MaxTSDBOpeningConcurrencyOnStartup limits the number of concurrently opening TSDB's during startup.
 	MaxTSDBOpeningConcurrencyOnStartup int `yaml:"max_tsdb_opening_concurrency_on_startup"`
@@ -186,14 +199,20 @@ func (cfg *TSDBConfig) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&cfg.HeadCompactionInterval, "blocks-storage.tsdb.head-compaction-interval", 1*time.Minute, "How frequently does Cortex try to compact TSDB head. Block is only created if data covers smallest block range. Must be greater than 0 and max 30 minutes. Note that up to 50% jitter is added to the value for the first compaction to avoid ingesters compacting concurrently.")
 	f.IntVar(&cfg.HeadCompactionConcurrency, "blocks-storage.tsdb.head-compaction-concurrency", 5, "Maximum number of tenants concurrently compacting TSDB head into a new block")
 	f.DurationVar(&cfg.HeadCompactionIdleTimeout, "blocks-storage.tsdb.head-compaction-idle-timeout", 1*time.Hour, "If TSDB head is idle for this duration, it is compacted. Note that up to 25% jitter is added to the value to avoid ingesters compacting concurrently. 0 means disabled.")
+	f.BoolVar(&cfg.SkipCorruptedWAL, "blocks-storage.tsdb.skip-corrupted-wal", false, "If the WAL (or a checkpoint) of a tenant can't be replayed even after the automatic repair, move it aside and open the TSDB without it, instead of failing the ingester startup. Samples not yet compacted into a block are lost. Only the last skipped WAL of each tenant is kept on disk. Skipped WALs are tracked by the cortex_ingester_tsdb_wal_skipped_total metric, and the size of the ones kept on disk by cortex_ingester_tsdb_wal_moved_aside_bytes.")
+	f.DurationVar(&cfg.WALReplayDuplicatesPeriod, "blocks-storage.tsdb.wal-replay-duplicates-period", 0, "Experimental: for how long after the WAL replay on startup the samples pushed again by the distributors, which are identical to a sample of the same series already replayed from the WAL (same timestamp and value), are silently ignored instead of being rejected as out of order or out of bounds. Ignored samples are tracked by the cortex_ingester_tsdb_wal_replay_ignored_samples_total metric. 0 means disabled.")
+	f.DurationVar(&cfg.HeadCompactionTenantJitter, "blocks-storage.tsdb.head-compaction-tenant-jitter", 0, "Maximum delay applied to the compaction of a tenant's TSDB head once it becomes compactable. Each tenant gets a stable delay between 0 and this value, based on the ingester ID and the tenant, so that the tenants of an ingester and the replicas of a tenant don't compact at the same time. Must not be greater than half of the smallest block range, and is clamped to half of the per-tenant block duration when overridden. 0 means disabled.")
 	f.IntVar(&cfg.HeadChunksWriteBufferSize, "blocks-storage.tsdb.head-chunks-write-buffer-size-bytes", chunks.DefaultWriteBufferSize, "The write buffer size used by the head chunks mapper. Lower values reduce memory utilisation on clusters with a large number of tenants at the cost of increased disk I/O operations.")
 	f.IntVar(&cfg.StripeSize, "blocks-storage.tsdb.stripe-size", 16384, "The number of shards of series to use in TSDB (must be a power of 2). Reducing this will decrease memory footprint, but can negatively impact performance.")
 	f.BoolVar(&cfg.WALCompressionEnabled, "blocks-storage.tsdb.wal-compression-enabled", false, "Deprecated (use blocks-storage.tsdb.wal-compression-type instead): True to enable TSDB WAL compression.")
 	f.StringVar(&cfg.WALCompressionType, "blocks-storage.tsdb.wal-compression-type", "", "TSDB WAL type. Supported values are: 'snappy', 'zstd' and '' (disable compression)")
 	f.IntVar(&cfg.WALSegmentSizeBytes, "blocks-storage.tsdb.wal-segment-size-bytes", wlog.DefaultSegmentSize, "TSDB WAL segments files max size (bytes).")
 	f.BoolVar(&cfg.FlushBlocksOnShutdown, "blocks-storage.tsdb.flush-blocks-on-shutdown", false, "True to flush blocks to storage on shutdown. If false, incomplete blocks will be reused after restart.")
+	f.DurationVar(&cfg.FlushBlocksOnShutdownTimeout, "blocks-storage.tsdb.flush-blocks-on-shutdown-timeout", 0, "How long to keep retrying to ship the blocks which failed to be uploaded to the storage when flushing on shutdown (either enabled by -blocks-storage.tsdb.flush-blocks-on-shutdown or triggered by the /shutdown endpoint). Blocks which are still not shipped are kept on the local disk. 0 to ship only once.")
 	f.DurationVar(&cfg.CloseIdleTSDBTimeout, "blocks-storage.tsdb.close-idle-tsdb-timeout", 0, "If TSDB has not received any data for this duration, and all blocks from TSDB have been shipped, TSDB is closed and deleted from local disk. If set to positive value, this value should be equal or higher than -querier.query-ingesters-within flag to make sure that TSDB is not closed prematurely, which could cause partial query results. 0 or negative value disables closing of idle TSDB.")
-	f.IntVar(&cfg.HeadChunksWriteQueueSize, "blocks-storage.tsdb.head-chunks-write-queue-size", chunks.DefaultWriteQueueSize, "The size of the in-memory queue used before flushing chunks to the disk.")
+	f.IntVar(&cfg.HeadChunksWriteQueueSize, "blocks-storage.tsdb.head-chunks-write-queue-size", chunks.DefaultWriteQueueSize, "The size of the in-memory queue used before flushing chunks to the disk. 0 to disable the queue and write chunks synchronously.")
+	f.IntVar(&cfg.HeadChunksSamplesPerChunk, "blocks-storage.tsdb.head-chunks-samples-per-chunk", tsdb.DefaultSamplesPerChunk, "The target number of samples of a TSDB head chunk before it's cut, written to the disk and memory-mapped. Higher values reduce the disk I/O operations at the cost of a higher memory utilisation.")
+	f.BoolVar(&cfg.IsolationEnabled, "blocks-storage.tsdb.isolation-enabled", false, "True to enable TSDB isolation, which guarantees that queries don't see partially committed appends. Cortex doesn't need it, since a query already reads from multiple ingesters which are not consistent with each other, and keeping it disabled reduces the CPU utilisation of the ingestion.")
 	f.IntVar(&cfg.MaxExemplars, "blocks-storage.tsdb.max-exemplars", 0, "Deprecated, use maxExemplars in limits instead. If the MaxExemplars value in limits is set to zero, cortex will fallback on this value. This setting enables support for exemplars in TSDB and sets the maximum number that will be stored. 0 or less means disabled.")
 	f.BoolVar(&cfg.MemorySnapshotOnShutdown, "blocks-storage.tsdb.memory-snapshot-on-shutdown", false, "True to enable snapshotting of in-memory TSDB data on disk when shutting down.")
 	f.Int64Var(&cfg.OutOfOrderCapMax, "blocks-storage.tsdb.out-of-order-cap-max", tsdb.DefaultOutOfOrderCapMax, "[EXPERIMENTAL] Configures the maximum number of samples per chunk that can be out-of-order.")
@@ -220,6 +239,10 @@ func (cfg *TSDBConfig) Validate() error {
 		return errInvalidCompactionConcurrency
 	}
 
+	if cfg.HeadCompactionTenantJitter < 0 || (len(cfg.BlockRanges) > 0 && cfg.HeadCompactionTenantJitter > cfg.BlockRanges[0]/2) {
+		return errInvalidCompactionTenantJitter
+	}
+
 	if cfg.HeadChunksWriteBufferSize < chunks.MinWriteBufferSize || cfg.HeadChunksWriteBufferSize > chunks.MaxWriteBufferSize || cfg.HeadChunksWriteBufferSize%1024 != 0 {
 		return errors.Errorf("head chunks write buffer size must be a multiple of 1024 between %d and %d", chunks.MinWriteBufferSize, chunks.MaxWriteBufferSize)
 	}
@@ -240,6 +263,14 @@ func (cfg *TSDBConfig) Validate() error {
 		return errInvalidOutOfOrderCapMax
 	}
 
+	if cfg.HeadChunksWriteQueueSize < 0 {
+		return errInvalidWriteQueueSize
+	}
+
+	if cfg.HeadChunksSamplesPerChunk <= 0 {
+		return errInvalidSamplesPerChunk
+	}
+
 	switch cfg.WALCompressionType {
 	case "snappy", "zstd", "":
 		// valid
@@ -263,21 +294,22 @@ func (cfg *TSDBConfig) IsBlocksShippingEnabled() bool {
 
 // BucketStoreConfig holds the config information for Bucket Stores used by the querier and store-gateway.
 type BucketStoreConfig struct {
-	SyncDir                  string              `yaml:"sync_dir"`
-	SyncInterval             time.Duration       `yaml:"sync_interval"`
-	MaxConcurrent            int                 `yaml:"max_concurrent"`
-	MaxInflightRequests      int                 `yaml:"max_inflight_requests"`
-	TenantSyncConcurrency    int                 `yaml:"tenant_sync_concurrency"`
-	BlockSyncConcurrency     int                 `yaml:"block_sync_concurrency"`
-	MetaSyncConcurrency      int                 `yaml:"meta_sync_concurrency"`
-	ConsistencyDelay         time.Duration       `yaml:"consistency_delay"`
-	IndexCache               IndexCacheConfig    `yaml:"index_cache"`
-	ChunksCache              ChunksCacheConfig   `yaml:"chunks_cache"`
-	MetadataCache            MetadataCacheConfig `yaml:"metadata_cache"`
-	IgnoreDeletionMarksDelay time.Duration       `yaml:"ignore_deletion_mark_delay"`
-	IgnoreBlocksWithin       time.Duration       `yaml:"ignore_blocks_within"`
-	BucketIndex              BucketIndexConfig   `yaml:"bucket_index"`
-	BlockDiscoveryStrategy   string              `yaml:"block_discovery_strategy"`
+	SyncDir                   string              `yaml:"sync_dir"`
+	SyncInterval              time.Duration       `yaml:"sync_interval"`
+	MaxConcurrent             int                 `yaml:"max_concurrent"`
+	MaxConcurrentQueueTimeout time.Duration       `yaml:"max_concurrent_queue_timeout"`
+	MaxInflightRequests       int                 `yaml:"max_inflight_requests"`
+	TenantSyncConcurrency     int                 `yaml:"tenant_sync_concurrency"`
+	BlockSyncConcurrency      int                 `yaml:"block_sync_concurrency"`
+	MetaSyncConcurrency       int                 `yaml:"meta_sync_concurrency"`
+	ConsistencyDelay          time.Duration       `yaml:"consistency_delay"`
+	IndexCache                IndexCacheConfig    `yaml:"index_cache"`
+	ChunksCache               ChunksCacheConfig   `yaml:"chunks_cache"`
+	MetadataCache             MetadataCacheConfig `yaml:"metadata_cache"`
+	IgnoreDeletionMarksDelay  time.Duration       `yaml:"ignore_deletion_mark_delay"`
+	IgnoreBlocksWithin        time.Duration       `yaml:"ignore_blocks_within"`
+	BucketIndex               BucketIndexConfig   `yaml:"bucket_index"`
+	BlockDiscoveryStrategy    string              `yaml:"block_discovery_strategy"`
 
 	// Chunk pool.
 	MaxChunkPoolBytes           uint64 `yaml:"max_chunk_pool_bytes"`
@@ -288,6 +320,10 @@ type BucketStoreConfig struct {
 	IndexHeaderLazyLoadingEnabled     bool          `yaml:"index_header_lazy_loading_enabled"`
 	IndexHeaderLazyLoadingIdleTimeout time.Duration `yaml:"index_header_lazy_loading_idle_timeout"`
 
+	// Controls whether the index-headers of the most recently queried blocks are loaded at startup.
+	IndexHeaderPrefetchEnabled   bool `yaml:"index_header_prefetch_enabled"`
+	IndexHeaderPrefetchMaxBlocks int  `yaml:"index_header_prefetch_max_blocks"`
+
 	// Controls whether lazy expanded posting optimization is enabled or not.
 	LazyExpandedPostingsEnabled bool `yaml:"lazy_expanded_postings_enabled"`
 
@@ -340,6 +376,7 @@ func (cfg *BucketStoreConfig) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.ChunkPoolMinBucketSizeBytes, "blocks-storage.bucket-store.chunk-pool-min-bucket-size-bytes", ChunkPoolDefaultMinBucketSize, "Size - in bytes - of the smallest chunks pool bucket.")
 	f.IntVar(&cfg.ChunkPoolMaxBucketSizeBytes, "blocks-storage.bucket-store.chunk-pool-max-bucket-size-bytes", ChunkPoolDefaultMaxBucketSize, "Size - in bytes - of the largest chunks pool bucket.")
 	f.IntVar(&cfg.MaxConcurrent, "blocks-storage.bucket-store.max-concurrent", 100, "Max number of concurrent queries to execute against the long-term storage. The limit is shared across all tenants.")
+	f.DurationVar(&cfg.MaxConcurrentQueueTimeout, "blocks-storage.bucket-store.max-concurrent-queue-timeout", 0, "Max time a query waits for its turn when -blocks-storage.bucket-store.max-concurrent or the per-tenant -store-gateway.max-concurrent-series-requests-per-tenant is reached. Queries timing out are rejected, and retried by the querier on other store-gateways. 0 to wait until the query is canceled.")
 	f.IntVar(&cfg.MaxInflightRequests, "blocks-storage.bucket-store.max-inflight-requests", 0, "Max number of inflight queries to execute against the long-term storage. The limit is shared across all tenants. 0 to disable.")
 	f.IntVar(&cfg.TenantSyncConcurrency, "blocks-storage.bucket-store.tenant-sync-concurrency", 10, "Maximum number of concurrent tenants syncing blocks.")
 	f.IntVar(&cfg.BlockSyncConcurrency, "blocks-storage.bucket-store.block-sync-concurrency", 20, "Maximum number of concurrent blocks syncing per tenant.")
@@ -352,6 +389,8 @@ func (cfg *BucketStoreConfig) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.PostingOffsetsInMemSampling, "blocks-storage.bucket-store.posting-offsets-in-mem-sampling", store.DefaultPostingOffsetInMemorySampling, "Controls what is the ratio of postings offsets that the store will hold in memory.")
 	f.BoolVar(&cfg.IndexHeaderLazyLoadingEnabled, "blocks-storage.bucket-store.index-header-lazy-loading-enabled", false, "If enabled, store-gateway will lazily memory-map an index-header only once required by a query.")
 	f.DurationVar(&cfg.IndexHeaderLazyLoadingIdleTimeout, "blocks-storage.bucket-store.index-header-lazy-loading-idle-timeout", 20*time.Minute, "If index-header lazy loading is enabled and this setting is > 0, the store-gateway will release memory-mapped index-headers after 'idle timeout' inactivity.")
+	f.BoolVar(&cfg.IndexHeaderPrefetchEnabled, "blocks-storage.bucket-store.index-header-prefetch-enabled", false, "[Experimental] If enabled, the store-gateway keeps the list of the most recently queried blocks in the sync dir, and loads their index-headers at startup, once the blocks have been synchronized, to reduce the latency of the first queries after a restart. Requires the index-header lazy loading to be enabled.")
+	f.IntVar(&cfg.IndexHeaderPrefetchMaxBlocks, "blocks-storage.bucket-store.index-header-prefetch-max-blocks", 1000, "[Experimental] Max number of most recently queried blocks whose index-headers are loaded at startup, when the index-header prefetching is enabled.")
 	f.Uint64Var(&cfg.PartitionerMaxGapBytes, "blocks-storage.bucket-store.partitioner-max-gap-bytes", store.PartitionerMaxGapSize, "Max size - in bytes - of a gap for which the partitioner aggregates together two bucket GET object requests.")
 	f.Uint64Var(&cfg.EstimatedMaxSeriesSizeBytes, "blocks-storage.bucket-store.estimated-max-series-size-bytes", store.EstimatedMaxSeriesSize, "Estimated max series size in bytes. Setting a large value might result in over fetching data while a small value might result in data refetch. Default value is 64KB.")
 	f.Uint64Var(&cfg.EstimatedMaxChunkSizeBytes, "blocks-storage.bucket-store.estimated-max-chunk-size-bytes", store.EstimatedMaxChunkSize, "Estimated max chunk size in bytes. Setting a large value might result in over fetching data while a small value might result in data refetch. Default value is 16KiB.")
@@ -390,6 +429,12 @@ func (cfg *BucketStoreConfig) Validate() error {
 	if !util.StringsContain(supportedTokenBucketBytesLimiterModes, cfg.TokenBucketBytesLimiter.Mode) {
 		return ErrInvalidTokenBucketBytesLimiterMode
 	}
+	if cfg.IndexHeaderPrefetchEnabled && !cfg.IndexHeaderLazyLoadingEnabled {
+		return ErrIndexHeaderPrefetchWithoutLazyLoading
+	}
+	if cfg.IndexHeaderPrefetchEnabled && cfg.IndexHeaderPrefetchMaxBlocks <= 0 {
+		return ErrInvalidIndexHeaderPrefetchMaxBlocks
+	}
 	return nil
 }
 
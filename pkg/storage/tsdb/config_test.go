@@ -73,6 +73,26 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			expectedErr: errInvalidCompactionConcurrency,
 		},
+		"should fail on negative compaction tenant jitter": {
+			setup: func(cfg *BlocksStorageConfig) {
+				cfg.TSDB.HeadCompactionTenantJitter = -time.Minute
+			},
+			expectedErr: errInvalidCompactionTenantJitter,
+		},
+		"should fail on compaction tenant jitter greater than half of the block range": {
+			setup: func(cfg *BlocksStorageConfig) {
+				cfg.TSDB.BlockRanges = DurationList{2 * time.Hour}
+				cfg.TSDB.HeadCompactionTenantJitter = 90 * time.Minute
+			},
+			expectedErr: errInvalidCompactionTenantJitter,
+		},
+		"should pass on valid compaction tenant jitter": {
+			setup: func(cfg *BlocksStorageConfig) {
+				cfg.TSDB.BlockRanges = DurationList{2 * time.Hour}
+				cfg.TSDB.HeadCompactionTenantJitter = 30 * time.Minute
+			},
+			expectedErr: nil,
+		},
 		"should pass on valid compaction concurrency": {
 			setup: func(cfg *BlocksStorageConfig) {
 				cfg.TSDB.HeadCompactionConcurrency = 10
@@ -121,6 +141,24 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			expectedErr: errInvalidOutOfOrderCapMax,
 		},
+		"should fail on negative head chunks write queue size": {
+			setup: func(cfg *BlocksStorageConfig) {
+				cfg.TSDB.HeadChunksWriteQueueSize = -1
+			},
+			expectedErr: errInvalidWriteQueueSize,
+		},
+		"should pass on disabled head chunks write queue": {
+			setup: func(cfg *BlocksStorageConfig) {
+				cfg.TSDB.HeadChunksWriteQueueSize = 0
+			},
+			expectedErr: nil,
+		},
+		"should fail on invalid head chunks samples per chunk": {
+			setup: func(cfg *BlocksStorageConfig) {
+				cfg.TSDB.HeadChunksSamplesPerChunk = 0
+			},
+			expectedErr: errInvalidSamplesPerChunk,
+		},
 		"should pass on valid wal compression type (snappy)": {
 			setup: func(cfg *BlocksStorageConfig) {
 				cfg.TSDB.WALCompressionType = "snappy"
@@ -145,6 +183,27 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			expectedErr: errUnSupportedWALCompressionType,
 		},
+		"should fail on index-header prefetching enabled without lazy loading": {
+			setup: func(cfg *BlocksStorageConfig) {
+				cfg.BucketStore.IndexHeaderPrefetchEnabled = true
+			},
+			expectedErr: ErrIndexHeaderPrefetchWithoutLazyLoading,
+		},
+		"should fail on invalid index-header prefetch max blocks": {
+			setup: func(cfg *BlocksStorageConfig) {
+				cfg.BucketStore.IndexHeaderLazyLoadingEnabled = true
+				cfg.BucketStore.IndexHeaderPrefetchEnabled = true
+				cfg.BucketStore.IndexHeaderPrefetchMaxBlocks = 0
+			},
+			expectedErr: ErrInvalidIndexHeaderPrefetchMaxBlocks,
+		},
+		"should pass on index-header prefetching enabled with lazy loading": {
+			setup: func(cfg *BlocksStorageConfig) {
+				cfg.BucketStore.IndexHeaderLazyLoadingEnabled = true
+				cfg.BucketStore.IndexHeaderPrefetchEnabled = true
+			},
+			expectedErr: nil,
+		},
 	}
 
 	for testName, testData := range tests {
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/go-kit/log/level"
@@ -61,6 +62,31 @@ func ReadTenantDeletionMark(ctx context.Context, bkt objstore.InstrumentedBucket
 	return read(ctx, bkt.WithExpectedErrs(bkt.IsObjNotFoundErr), markerFile)
 }
 
+// Returns the tenant deletion marks found in the global markers location, by tenant.
+func ReadTenantDeletionMarks(ctx context.Context, bkt objstore.InstrumentedBucket) (map[string]*TenantDeletionMark, error) {
+	var userIDs []string
+	err := bkt.Iter(ctx, util.GlobalMarkersDir, func(entry string) error {
+		// entry will be of the form __markers__/<user>/
+		userIDs = append(userIDs, path.Base(strings.TrimSuffix(entry, objstore.DirDelim)))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	marks := make(map[string]*TenantDeletionMark, len(userIDs))
+	for _, userID := range userIDs {
+		mark, err := ReadTenantDeletionMark(ctx, bkt, userID)
+		if err != nil {
+			return nil, err
+		}
+		if mark != nil {
+			marks[userID] = mark
+		}
+	}
+	return marks, nil
+}
+
 // Deletes the tenant deletion mark for given user if it exists.
 func DeleteTenantDeletionMark(ctx context.Context, bkt objstore.Bucket, userID string) error {
 	if err := bkt.Delete(ctx, GetGlobalDeletionMarkPath(userID)); err != nil {
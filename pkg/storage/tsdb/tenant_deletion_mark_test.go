@@ -68,3 +68,19 @@ func TestTenantDeletionMarkExists(t *testing.T) {
 		})
 	}
 }
+
+func TestReadTenantDeletionMarks(t *testing.T) {
+	ctx := context.Background()
+	bkt := objstore.WithNoopInstr(objstore.NewInMemBucket())
+
+	require.NoError(t, bkt.Upload(ctx, "user-1/01EQK4QKFHVSZYVJ908Y7HH9E0/meta.json", bytes.NewReader([]byte("data"))))
+	require.NoError(t, WriteTenantDeletionMark(ctx, bkt, "user-2", &TenantDeletionMark{DeletionTime: 10}))
+	require.NoError(t, WriteTenantDeletionMark(ctx, bkt, "user-3", &TenantDeletionMark{DeletionTime: 20, FinishedTime: 30}))
+
+	marks, err := ReadTenantDeletionMarks(ctx, bkt)
+	require.NoError(t, err)
+	require.Equal(t, map[string]*TenantDeletionMark{
+		"user-2": {DeletionTime: 10},
+		"user-3": {DeletionTime: 20, FinishedTime: 30},
+	}, marks)
+}
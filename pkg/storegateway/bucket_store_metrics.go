@@ -54,6 +54,7 @@ type BucketStoreMetrics struct {
 	indexHeaderLazyUnloadCount       *prometheus.Desc
 	indexHeaderLazyUnloadFailedCount *prometheus.Desc
 	indexHeaderLazyLoadDuration      *prometheus.Desc
+	indexHeaderLazyLoaded            *prometheus.Desc
 }
 
 func NewBucketStoreMetrics() *BucketStoreMetrics {
@@ -204,6 +205,10 @@ func NewBucketStoreMetrics() *BucketStoreMetrics {
 			"cortex_bucket_store_indexheader_lazy_load_duration_seconds",
 			"Duration of the index-header lazy loading in seconds.",
 			nil, nil),
+		indexHeaderLazyLoaded: prometheus.NewDesc(
+			"cortex_bucket_store_indexheader_lazy_loaded",
+			"Number of index-headers lazily loaded in memory.",
+			nil, nil),
 
 		lazyExpandedPostingsCount: prometheus.NewDesc(
 			"cortex_bucket_store_lazy_expanded_postings_total",
@@ -267,6 +272,7 @@ func (m *BucketStoreMetrics) Describe(out chan<- *prometheus.Desc) {
 	out <- m.indexHeaderLazyUnloadCount
 	out <- m.indexHeaderLazyUnloadFailedCount
 	out <- m.indexHeaderLazyLoadDuration
+	out <- m.indexHeaderLazyLoaded
 
 	out <- m.lazyExpandedPostingsCount
 	out <- m.lazyExpandedPostingSizeBytes
@@ -318,6 +324,11 @@ func (m *BucketStoreMetrics) Collect(out chan<- prometheus.Metric) {
 	data.SendSumOfCounters(out, m.indexHeaderLazyUnloadFailedCount, "thanos_bucket_store_indexheader_lazy_unload_failed_total")
 	data.SendSumOfHistograms(out, m.indexHeaderLazyLoadDuration, "thanos_bucket_store_indexheader_lazy_load_duration_seconds")
 
+	// The index-headers loaded in memory are the ones successfully loaded and not unloaded yet.
+	loaded := data.GetSumOfCounters("thanos_bucket_store_indexheader_lazy_load_total") - data.GetSumOfCounters("thanos_bucket_store_indexheader_lazy_load_failed_total")
+	unloaded := data.GetSumOfCounters("thanos_bucket_store_indexheader_lazy_unload_total") - data.GetSumOfCounters("thanos_bucket_store_indexheader_lazy_unload_failed_total")
+	out <- prometheus.MustNewConstMetric(m.indexHeaderLazyLoaded, prometheus.GaugeValue, loaded-unloaded)
+
 	data.SendSumOfCounters(out, m.lazyExpandedPostingsCount, "thanos_bucket_store_lazy_expanded_postings_total")
 	data.SendSumOfCounters(out, m.lazyExpandedPostingSizeBytes, "thanos_bucket_store_lazy_expanded_posting_size_bytes_total")
 	data.SendSumOfCounters(out, m.lazyExpandedPostingSeriesOverfetchedSizeBytes, "thanos_bucket_store_lazy_expanded_posting_series_overfetched_size_bytes_total")
@@ -532,6 +532,10 @@ func TestBucketStoreMetrics(t *testing.T) {
 			# TYPE cortex_bucket_store_indexheader_lazy_load_failed_total counter
 			cortex_bucket_store_indexheader_lazy_load_failed_total 1.373659e+06
 
+			# HELP cortex_bucket_store_indexheader_lazy_loaded Number of index-headers lazily loaded in memory.
+			# TYPE cortex_bucket_store_indexheader_lazy_loaded gauge
+			cortex_bucket_store_indexheader_lazy_loaded 0
+
 			# HELP cortex_bucket_store_indexheader_lazy_load_total Total number of index-header lazy load operations.
 			# TYPE cortex_bucket_store_indexheader_lazy_load_total counter
 			cortex_bucket_store_indexheader_lazy_load_total 1.35114e+06
@@ -25,6 +25,7 @@ import (
 	"github.com/thanos-io/thanos/pkg/pool"
 	"github.com/thanos-io/thanos/pkg/store"
 	storecache "github.com/thanos-io/thanos/pkg/store/cache"
+	"github.com/thanos-io/thanos/pkg/store/hintspb"
 	"github.com/thanos-io/thanos/pkg/store/storepb"
 	"github.com/weaveworks/common/httpgrpc"
 	"github.com/weaveworks/common/logging"
@@ -65,14 +66,32 @@ type BucketStores struct {
 	// Gate used to limit query concurrency across all tenants.
 	queryGate gate.Gate
 
-	// Keeps a bucket store for each tenant.
-	storesMu sync.RWMutex
-	stores   map[string]*store.BucketStore
+	// Gates used to limit query concurrency of each tenant.
+	tenantGates *tenantGates
+
+	// Keeps a bucket store, and its sharding filter, for each tenant.
+	storesMu        sync.RWMutex
+	stores          map[string]*store.BucketStore
+	shardingFilters map[string]*shardingMetadataFilterAdapter
+
+	// Keeps the tenants discovered and the ones included in the shard by the last sync of all tenants.
+	lastUsersMu       sync.Mutex
+	lastUserIDs       []string
+	lastIncludedUsers map[string]struct{}
 
 	// Keeps the last sync error for the  bucket store for each tenant.
 	storesErrorsMu sync.RWMutex
 	storesErrors   map[string]error
 
+	// Keeps the last time the bucket store of each tenant has been successfully synced.
+	lastSyncsMu sync.Mutex
+	lastSyncs   map[string]time.Time
+
+	// Serializes the syncs of the bucket store of each tenant, given the periodic and the
+	// on-demand syncs may run concurrently and the Thanos BucketStore doesn't support it.
+	syncLocksMu sync.Mutex
+	syncLocks   map[string]*sync.Mutex
+
 	instanceTokenBucket *util.TokenBucket
 
 	userTokenBucketsMu sync.RWMutex
@@ -82,7 +101,12 @@ type BucketStores struct {
 	inflightRequestCnt int
 	inflightRequestMu  sync.RWMutex
 
+	// Keeps the most recently queried blocks, whose index-headers are prefetched at startup.
+	// Nil if the prefetching is disabled.
+	queriedBlocks *queriedBlocksTracker
+
 	// Metrics.
+	queueTimeouts     *prometheus.CounterVec
 	syncTimes         prometheus.Histogram
 	syncLastSuccess   prometheus.Gauge
 	tenantsDiscovered prometheus.Gauge
@@ -114,13 +138,25 @@ func NewBucketStores(cfg tsdb.BlocksStorageConfig, shardingStrategy ShardingStra
 		bucket:             cachingBucket,
 		shardingStrategy:   shardingStrategy,
 		stores:             map[string]*store.BucketStore{},
+		shardingFilters:    map[string]*shardingMetadataFilterAdapter{},
 		storesErrors:       map[string]error{},
+		lastSyncs:          map[string]time.Time{},
+		syncLocks:          map[string]*sync.Mutex{},
 		logLevel:           logLevel,
 		bucketStoreMetrics: NewBucketStoreMetrics(),
 		metaFetcherMetrics: NewMetadataFetcherMetrics(),
 		queryGate:          queryGate,
-		partitioner:        newGapBasedPartitioner(cfg.BucketStore.PartitionerMaxGapBytes, reg),
-		userTokenBuckets:   make(map[string]*util.TokenBucket),
+		tenantGates: newTenantGates(limits, promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Name:    "cortex_bucket_stores_tenant_gate_duration_seconds",
+			Help:    "How many seconds it took for queries to wait at the tenant gate.",
+			Buckets: []float64{0.01, 0.1, 0.3, 0.6, 1, 3, 6, 9, 20, 30, 60, 90, 120, 240, 360, 720},
+		})),
+		queueTimeouts: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_bucket_stores_gate_queue_timeouts_total",
+			Help: "Total number of queries rejected because they timed out waiting at the gate.",
+		}, []string{"gate"}),
+		partitioner:      newGapBasedPartitioner(cfg.BucketStore.PartitionerMaxGapBytes, reg),
+		userTokenBuckets: make(map[string]*util.TokenBucket),
 		syncTimes: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
 			Name:    "cortex_bucket_stores_blocks_sync_seconds",
 			Help:    "The total time it takes to perform a sync stores",
@@ -157,6 +193,10 @@ func NewBucketStores(cfg tsdb.BlocksStorageConfig, shardingStrategy ShardingStra
 		}))
 	}
 
+	if cfg.BucketStore.IndexHeaderPrefetchEnabled {
+		u.queriedBlocks = newQueriedBlocksTracker(cfg.BucketStore.SyncDir, cfg.BucketStore.IndexHeaderPrefetchMaxBlocks)
+	}
+
 	if reg != nil {
 		reg.MustRegister(u.bucketStoreMetrics, u.metaFetcherMetrics)
 	}
@@ -176,11 +216,17 @@ func (u *BucketStores) InitialSync(ctx context.Context) error {
 	}
 
 	level.Info(u.logger).Log("msg", "successfully synchronized TSDB blocks for all users")
+
+	u.prefetchIndexHeaders(ctx)
 	return nil
 }
 
 // SyncBlocks synchronizes the stores state with the Bucket store for every user.
 func (u *BucketStores) SyncBlocks(ctx context.Context) error {
+	// The most recently queried blocks are persisted periodically, so that they're
+	// prefetched after a restart even if the store-gateway hasn't been gracefully stopped.
+	defer u.persistQueriedBlocks()
+
 	return u.syncUsersBlocksWithRetries(ctx, func(ctx context.Context, s *store.BucketStore) error {
 		return s.SyncBlocks(ctx)
 	})
@@ -244,6 +290,11 @@ func (u *BucketStores) syncUsersBlocks(ctx context.Context, f func(context.Conte
 	u.tenantsDiscovered.Set(float64(len(userIDs)))
 	u.tenantsSynced.Set(float64(len(includeUserIDs)))
 
+	u.lastUsersMu.Lock()
+	u.lastUserIDs = userIDs
+	u.lastIncludedUsers = includeUserIDs
+	u.lastUsersMu.Unlock()
+
 	// Create a pool of workers which will synchronize blocks. The pool size
 	// is limited in order to avoid to concurrently sync a lot of tenants in
 	// a large cluster.
@@ -253,20 +304,10 @@ func (u *BucketStores) syncUsersBlocks(ctx context.Context, f func(context.Conte
 			defer wg.Done()
 
 			for job := range jobs {
-				if err := f(ctx, job.store); err != nil {
-					if errors.Is(err, bucket.ErrCustomerManagedKeyAccessDenied) {
-						u.storesErrorsMu.Lock()
-						u.storesErrors[job.userID] = httpgrpc.Errorf(int(codes.PermissionDenied), "store error: %s", err)
-						u.storesErrorsMu.Unlock()
-					} else {
-						errsMx.Lock()
-						errs.Add(errors.Wrapf(err, "failed to synchronize TSDB blocks for user %s", job.userID))
-						errsMx.Unlock()
-					}
-				} else {
-					u.storesErrorsMu.Lock()
-					delete(u.storesErrors, job.userID)
-					u.storesErrorsMu.Unlock()
+				if err := u.syncUserStore(ctx, job.userID, job.store, f); err != nil {
+					errsMx.Lock()
+					errs.Add(err)
+					errsMx.Unlock()
 				}
 			}
 		}()
@@ -309,6 +350,116 @@ func (u *BucketStores) syncUsersBlocks(ctx context.Context, f func(context.Conte
 	return errs.Err()
 }
 
+// OwnedBlocksChanged returns whether the tenants and blocks owned by the store-gateway, among the
+// ones known from the last sync, have changed since then, for example because of a ring change.
+func (u *BucketStores) OwnedBlocksChanged(ctx context.Context) (bool, error) {
+	u.lastUsersMu.Lock()
+	userIDs, lastIncludedUsers := u.lastUserIDs, u.lastIncludedUsers
+	u.lastUsersMu.Unlock()
+
+	includedUsers := u.shardingStrategy.FilterUsers(ctx, userIDs)
+	if len(includedUsers) != len(lastIncludedUsers) {
+		return true, nil
+	}
+
+	for _, userID := range includedUsers {
+		if _, ok := lastIncludedUsers[userID]; !ok {
+			return true, nil
+		}
+
+		u.storesMu.RLock()
+		filter := u.shardingFilters[userID]
+		u.storesMu.RUnlock()
+		if filter == nil {
+			continue
+		}
+
+		changed, err := filter.ownedBlocksChanged(ctx)
+		if err != nil || changed {
+			return changed, err
+		}
+	}
+	return false, nil
+}
+
+// syncUserStore synchronizes the bucket store of a user, keeping track of the store errors
+// and of the last time the user has been synced.
+func (u *BucketStores) syncUserStore(ctx context.Context, userID string, store *store.BucketStore, f func(context.Context, *store.BucketStore) error) error {
+	syncLock := u.getUserSyncLock(userID)
+	syncLock.Lock()
+	defer syncLock.Unlock()
+
+	if err := f(ctx, store); err != nil {
+		if errors.Is(err, bucket.ErrCustomerManagedKeyAccessDenied) {
+			u.storesErrorsMu.Lock()
+			u.storesErrors[userID] = httpgrpc.Errorf(int(codes.PermissionDenied), "store error: %s", err)
+			u.storesErrorsMu.Unlock()
+			return nil
+		}
+		return errors.Wrapf(err, "failed to synchronize TSDB blocks for user %s", userID)
+	}
+
+	u.storesErrorsMu.Lock()
+	delete(u.storesErrors, userID)
+	u.storesErrorsMu.Unlock()
+
+	u.lastSyncsMu.Lock()
+	u.lastSyncs[userID] = time.Now()
+	u.lastSyncsMu.Unlock()
+	return nil
+}
+
+func (u *BucketStores) getUserSyncLock(userID string) *sync.Mutex {
+	u.syncLocksMu.Lock()
+	defer u.syncLocksMu.Unlock()
+
+	l, ok := u.syncLocks[userID]
+	if !ok {
+		l = &sync.Mutex{}
+		u.syncLocks[userID] = l
+	}
+	return l
+}
+
+// SyncUserBlocks synchronizes the blocks of a single user, if the user belongs to the
+// store-gateway shard or its blocks are still loaded.
+func (u *BucketStores) SyncUserBlocks(ctx context.Context, userID string) error {
+	if u.getStore(userID) == nil && !util.StringsContain(u.shardingStrategy.FilterUsers(ctx, []string{userID}), userID) {
+		return nil
+	}
+
+	bs, err := u.getOrCreateStore(userID)
+	if err != nil {
+		return err
+	}
+
+	return u.syncUserStore(ctx, userID, bs, func(ctx context.Context, s *store.BucketStore) error {
+		return s.SyncBlocks(ctx)
+	})
+}
+
+// SyncUsersBlocksDue synchronizes the blocks of the users whose per-tenant sync interval
+// has elapsed since their last sync.
+func (u *BucketStores) SyncUsersBlocksDue(ctx context.Context) error {
+	var due []string
+
+	u.lastSyncsMu.Lock()
+	for userID, lastSync := range u.lastSyncs {
+		if interval := u.limits.StoreGatewaySyncInterval(userID); interval > 0 && time.Since(lastSync) >= interval {
+			due = append(due, userID)
+		}
+	}
+	u.lastSyncsMu.Unlock()
+
+	errs := tsdb_errors.NewMulti()
+	for _, userID := range due {
+		if err := u.SyncUserBlocks(ctx, userID); err != nil {
+			errs.Add(err)
+		}
+	}
+	return errs.Err()
+}
+
 // Series makes a series request to the underlying user bucket store.
 func (u *BucketStores) Series(req *storepb.SeriesRequest, srv storepb.Store_SeriesServer) error {
 	spanLog, spanCtx := spanlogger.New(srv.Context(), "BucketStores.Series")
@@ -344,6 +495,14 @@ func (u *BucketStores) Series(req *storepb.SeriesRequest, srv storepb.Store_Seri
 		defer u.decrementInflightRequestCnt()
 	}
 
+	done, err := u.waitForTurn(spanCtx, userID)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	u.trackQueriedBlocks(userID, req.Hints, &hintspb.SeriesRequestHints{})
+
 	err = store.Series(req, spanSeriesServer{
 		Store_SeriesServer: srv,
 		ctx:                spanCtx,
@@ -395,6 +554,8 @@ func (u *BucketStores) LabelNames(ctx context.Context, req *storepb.LabelNamesRe
 		return &storepb.LabelNamesResponse{}, nil
 	}
 
+	u.trackQueriedBlocks(userID, req.Hints, &hintspb.LabelNamesRequestHints{})
+
 	resp, err := store.LabelNames(ctx, req)
 
 	return resp, err
@@ -425,6 +586,8 @@ func (u *BucketStores) LabelValues(ctx context.Context, req *storepb.LabelValues
 		return &storepb.LabelValuesResponse{}, nil
 	}
 
+	u.trackQueriedBlocks(userID, req.Hints, &hintspb.LabelValuesRequestHints{})
+
 	return store.LabelValues(ctx, req)
 }
 
@@ -485,6 +648,7 @@ func (u *BucketStores) closeEmptyBucketStore(userID string) error {
 	}
 
 	delete(u.stores, userID)
+	delete(u.shardingFilters, userID)
 	unlockInDefer = false
 	u.storesMu.Unlock()
 
@@ -494,9 +658,22 @@ func (u *BucketStores) closeEmptyBucketStore(userID string) error {
 		u.userTokenBucketsMu.Unlock()
 	}
 
+	u.tenantGates.remove(userID)
+
+	u.lastSyncsMu.Lock()
+	delete(u.lastSyncs, userID)
+	u.lastSyncsMu.Unlock()
+
+	u.syncLocksMu.Lock()
+	delete(u.syncLocks, userID)
+	u.syncLocksMu.Unlock()
+
+	// The bucket store is closed before removing its metrics registry, given the removal keeps
+	// the last values of the counters and the index-headers unloaded on close must be accounted.
+	err := bs.Close()
 	u.metaFetcherMetrics.RemoveUserRegistry(userID)
 	u.bucketStoreMetrics.RemoveUserRegistry(userID)
-	return bs.Close()
+	return err
 }
 
 func isEmptyBucketStore(bs *store.BucketStore) bool {
@@ -532,7 +709,8 @@ func (u *BucketStores) getOrCreateStore(userID string) (*store.BucketStore, erro
 	fetcherReg := prometheus.NewRegistry()
 
 	// The sharding strategy filter MUST be before the ones we create here (order matters).
-	filters := append([]block.MetadataFilter{NewShardingMetadataFilterAdapter(userID, u.shardingStrategy)}, []block.MetadataFilter{
+	shardingFilter := newShardingMetadataFilterAdapter(userID, u.shardingStrategy)
+	filters := append([]block.MetadataFilter{shardingFilter}, []block.MetadataFilter{
 		block.NewConsistencyDelayMetaFilter(userLogger, u.cfg.BucketStore.ConsistencyDelay, fetcherReg),
 		// Use our own custom implementation.
 		NewIgnoreDeletionMarkFilter(userLogger, userBkt, u.cfg.BucketStore.IgnoreDeletionMarksDelay, u.cfg.BucketStore.MetaSyncConcurrency),
@@ -607,7 +785,6 @@ func (u *BucketStores) getOrCreateStore(userID string) (*store.BucketStore, erro
 		}),
 		store.WithRegistry(bucketStoreReg),
 		store.WithIndexCache(u.indexCache),
-		store.WithQueryGate(u.queryGate),
 		store.WithChunkPool(u.chunksPool),
 		store.WithSeriesBatchSize(u.cfg.BucketStore.SeriesBatchSize),
 		store.WithBlockEstimatedMaxChunkFunc(func(m thanos_metadata.Meta) uint64 {
@@ -658,6 +835,7 @@ func (u *BucketStores) getOrCreateStore(userID string) (*store.BucketStore, erro
 	}
 
 	u.stores[userID] = bs
+	u.shardingFilters[userID] = shardingFilter
 	u.metaFetcherMetrics.AddUserRegistry(userID, fetcherReg)
 	u.bucketStoreMetrics.AddUserRegistry(userID, bucketStoreReg)
 
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/oklog/ulid"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/prometheus/prometheus/util/annotations"
@@ -44,6 +46,7 @@ import (
 	cortex_testutil "github.com/cortexproject/cortex/pkg/storage/tsdb/testutil"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
 func TestBucketStores_CustomerKeyError(t *testing.T) {
@@ -390,6 +393,102 @@ func TestBucketStores_SyncBlocks(t *testing.T) {
 	assert.Greater(t, testutil.ToFloat64(stores.syncLastSuccess), float64(0))
 }
 
+func TestBucketStores_SyncUserBlocks(t *testing.T) {
+	t.Parallel()
+	const metricName = "series_1"
+
+	ctx := context.Background()
+	cfg := prepareStorageConfig(t)
+	storageDir := t.TempDir()
+	generateStorageBlock(t, storageDir, "user-1", metricName, 10, 100, 15)
+	generateStorageBlock(t, storageDir, "user-2", metricName, 10, 100, 15)
+
+	bucket, err := filesystem.NewBucketClient(filesystem.Config{Directory: storageDir})
+	require.NoError(t, err)
+
+	limits := defaultLimitsConfig()
+	limits.StoreGatewaySyncInterval = model.Duration(time.Nanosecond)
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	sharding := &userShardingStrategy{users: []string{"user-1"}}
+	stores, err := NewBucketStores(cfg, sharding, objstore.WithNoopInstr(bucket), overrides, mockLoggingLevel(), log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, stores.InitialSync(ctx))
+
+	// Generate another block and sync the blocks of the user only.
+	generateStorageBlock(t, storageDir, "user-1", metricName, 100, 200, 15)
+	require.NoError(t, stores.SyncUserBlocks(ctx, "user-1"))
+
+	seriesSet, _, err := querySeries(stores, "user-1", metricName, 150, 180)
+	require.NoError(t, err)
+	assert.Len(t, seriesSet, 1)
+
+	// The users not belonging to the shard are not synced.
+	require.NoError(t, stores.SyncUserBlocks(ctx, "user-2"))
+	assert.Nil(t, stores.getStore("user-2"))
+
+	// The users whose sync interval has elapsed are synced.
+	generateStorageBlock(t, storageDir, "user-1", metricName, 200, 300, 15)
+	require.NoError(t, stores.SyncUsersBlocksDue(ctx))
+
+	seriesSet, _, err = querySeries(stores, "user-1", metricName, 250, 280)
+	require.NoError(t, err)
+	assert.Len(t, seriesSet, 1)
+}
+
+func TestBucketStores_SyncUserBlocks_ConcurrentWithPeriodicSync(t *testing.T) {
+	t.Parallel()
+	const (
+		userID     = "user-1"
+		metricName = "series_1"
+	)
+
+	ctx := context.Background()
+	cfg := prepareStorageConfig(t)
+	storageDir := t.TempDir()
+	generateStorageBlock(t, storageDir, userID, metricName, 10, 100, 15)
+
+	bucket, err := filesystem.NewBucketClient(filesystem.Config{Directory: storageDir})
+	require.NoError(t, err)
+
+	reg := prometheus.NewPedanticRegistry()
+	stores, err := NewBucketStores(cfg, NewNoShardingStrategy(log.NewNopLogger(), nil), objstore.WithNoopInstr(bucket), defaultLimitsOverrides(t), mockLoggingLevel(), log.NewNopLogger(), reg)
+	require.NoError(t, err)
+	require.NoError(t, stores.InitialSync(ctx))
+
+	// Sync on-demand while the periodic sync runs, like the sync HTTP handler does.
+	generateStorageBlock(t, storageDir, userID, metricName, 100, 200, 15)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, stores.SyncBlocks(ctx))
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, stores.SyncUserBlocks(ctx, userID))
+		}()
+	}
+	wg.Wait()
+
+	// Each block has been loaded once.
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+			# HELP cortex_bucket_store_blocks_loaded Number of currently loaded blocks.
+			# TYPE cortex_bucket_store_blocks_loaded gauge
+			cortex_bucket_store_blocks_loaded{user="user-1"} 2
+
+			# HELP cortex_bucket_store_block_loads_total Total number of remote block loading attempts.
+			# TYPE cortex_bucket_store_block_loads_total counter
+			cortex_bucket_store_block_loads_total 2
+	`),
+		"cortex_bucket_store_blocks_loaded",
+		"cortex_bucket_store_block_loads_total",
+	))
+}
+
 func TestBucketStores_syncUsersBlocks(t *testing.T) {
 	t.Parallel()
 	allUsers := []string{"user-1", "user-2", "user-3"}
@@ -559,6 +658,59 @@ func TestBucketStores_Series_ShouldNotCheckMaxInflightRequestsIfTheLimitIsDisabl
 	assert.Equal(t, 1, len(series))
 }
 
+func TestBucketStores_Series_ShouldReturnErrorOnQueueTimeout(t *testing.T) {
+	cfg := prepareStorageConfig(t)
+	cfg.BucketStore.MaxConcurrent = 2
+	cfg.BucketStore.MaxConcurrentQueueTimeout = 100 * time.Millisecond
+	reg := prometheus.NewPedanticRegistry()
+	storageDir := t.TempDir()
+	generateStorageBlock(t, storageDir, "user-1", "series_1", 0, 100, 15)
+	generateStorageBlock(t, storageDir, "user-2", "series_1", 0, 100, 15)
+	bucket, err := filesystem.NewBucketClient(filesystem.Config{Directory: storageDir})
+	require.NoError(t, err)
+
+	limits := defaultLimitsConfig()
+	limits.MaxConcurrentSeriesRequestsPerTenant = 1
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	stores, err := NewBucketStores(cfg, NewNoShardingStrategy(log.NewNopLogger(), nil), objstore.WithNoopInstr(bucket), overrides, mockLoggingLevel(), log.NewNopLogger(), reg)
+	require.NoError(t, err)
+	require.NoError(t, stores.InitialSync(context.Background()))
+
+	// Keep busy the only slot of user-1.
+	done, err := stores.waitForTurn(context.Background(), "user-1")
+	require.NoError(t, err)
+
+	_, _, err = querySeries(stores, "user-1", "series_1", 0, 100)
+	assert.ErrorIs(t, err, ErrQueueTimeout)
+
+	// The queries of the other tenants are not affected.
+	series, _, err := querySeries(stores, "user-2", "series_1", 0, 100)
+	require.NoError(t, err)
+	assert.Len(t, series, 1)
+
+	// Keep busy the last slot shared across all tenants.
+	require.NoError(t, stores.queryGate.Start(context.Background()))
+
+	_, _, err = querySeries(stores, "user-2", "series_1", 0, 100)
+	assert.ErrorIs(t, err, ErrQueueTimeout)
+
+	done()
+	stores.queryGate.Done()
+
+	series, _, err = querySeries(stores, "user-1", "series_1", 0, 100)
+	require.NoError(t, err)
+	assert.Len(t, series, 1)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_bucket_stores_gate_queue_timeouts_total Total number of queries rejected because they timed out waiting at the gate.
+		# TYPE cortex_bucket_stores_gate_queue_timeouts_total counter
+		cortex_bucket_stores_gate_queue_timeouts_total{gate="instance"} 1
+		cortex_bucket_stores_gate_queue_timeouts_total{gate="tenant"} 1
+	`), "cortex_bucket_stores_gate_queue_timeouts_total"))
+}
+
 func prepareStorageConfig(t *testing.T) cortex_tsdb.BlocksStorageConfig {
 	cfg := cortex_tsdb.BlocksStorageConfig{}
 	flagext.DefaultValues(&cfg)
@@ -665,6 +817,52 @@ func setUserIDToGRPCContext(ctx context.Context, userID string) context.Context
 	return metadata.NewIncomingContext(ctx, metadata.Pairs(cortex_tsdb.TenantIDExternalLabel, userID))
 }
 
+func TestBucketStores_closeEmptyBucketStore_ShouldUpdateIndexHeaderLazyLoadedGauge(t *testing.T) {
+	const (
+		userID     = "user-1"
+		metricName = "series_1"
+	)
+
+	ctx := context.Background()
+	cfg := prepareStorageConfig(t)
+	cfg.BucketStore.IndexHeaderLazyLoadingEnabled = true
+	cfg.BucketStore.IndexHeaderLazyLoadingIdleTimeout = time.Hour
+
+	storageDir := t.TempDir()
+	generateStorageBlock(t, storageDir, userID, metricName, 10, 100, 15)
+
+	bucket, err := filesystem.NewBucketClient(filesystem.Config{Directory: storageDir})
+	require.NoError(t, err)
+
+	sharding := userShardingStrategy{users: []string{userID}}
+
+	reg := prometheus.NewPedanticRegistry()
+	stores, err := NewBucketStores(cfg, &sharding, objstore.WithNoopInstr(bucket), defaultLimitsOverrides(t), mockLoggingLevel(), log.NewNopLogger(), reg)
+	require.NoError(t, err)
+	require.NoError(t, stores.InitialSync(ctx))
+
+	// Query the block to lazily load its index-header.
+	_, _, err = querySeries(stores, userID, metricName, 20, 40)
+	require.NoError(t, err)
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_bucket_store_indexheader_lazy_loaded Number of index-headers lazily loaded in memory.
+		# TYPE cortex_bucket_store_indexheader_lazy_loaded gauge
+		cortex_bucket_store_indexheader_lazy_loaded 1
+	`), "cortex_bucket_store_indexheader_lazy_loaded"))
+
+	// Remove the user from the shard, so that its store is emptied and closed.
+	sharding.users = nil
+	require.NoError(t, stores.SyncBlocks(ctx))
+	require.Nil(t, stores.getStore(userID))
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_bucket_store_indexheader_lazy_loaded Number of index-headers lazily loaded in memory.
+		# TYPE cortex_bucket_store_indexheader_lazy_loaded gauge
+		cortex_bucket_store_indexheader_lazy_loaded 0
+	`), "cortex_bucket_store_indexheader_lazy_loaded"))
+}
+
 func TestBucketStores_deleteLocalFilesForExcludedTenants(t *testing.T) {
 	const (
 		user1 = "user-1"
@@ -0,0 +1,110 @@
+package storegateway
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	promgate "github.com/prometheus/prometheus/util/gate"
+	"github.com/thanos-io/thanos/pkg/gate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+var ErrQueueTimeout = status.Error(codes.ResourceExhausted, "timed out waiting for the turn to execute the query in store gateway")
+
+// tenantGates keeps a gate for each tenant, limiting the number of Series requests of the tenant
+// executed concurrently.
+type tenantGates struct {
+	limits   *validation.Overrides
+	duration prometheus.Histogram
+
+	mu    sync.Mutex
+	gates map[string]*tenantGate
+}
+
+type tenantGate struct {
+	maxConcurrent int
+	gate          gate.Gate
+}
+
+func newTenantGates(limits *validation.Overrides, duration prometheus.Histogram) *tenantGates {
+	return &tenantGates{
+		limits:   limits,
+		duration: duration,
+		gates:    map[string]*tenantGate{},
+	}
+}
+
+// get returns the gate of the tenant. The gate is replaced when the limit of the tenant changes,
+// the requests already started releasing the gate they got.
+func (g *tenantGates) get(userID string) gate.Gate {
+	maxConcurrent := g.limits.MaxConcurrentSeriesRequestsPerTenant(userID)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if maxConcurrent <= 0 {
+		delete(g.gates, userID)
+		return gate.NewNoop()
+	}
+
+	if tg, ok := g.gates[userID]; ok && tg.maxConcurrent == maxConcurrent {
+		return tg.gate
+	}
+
+	tg := &tenantGate{
+		maxConcurrent: maxConcurrent,
+		gate:          gate.InstrumentGateDuration(g.duration, promgate.New(maxConcurrent)),
+	}
+	g.gates[userID] = tg
+	return tg.gate
+}
+
+func (g *tenantGates) remove(userID string) {
+	g.mu.Lock()
+	delete(g.gates, userID)
+	g.mu.Unlock()
+}
+
+// waitForTurn waits for the turn of the request to be executed, first at the tenant gate and then
+// at the gate shared across all tenants. The returned function must be called once the request
+// has been executed.
+func (u *BucketStores) waitForTurn(ctx context.Context, userID string) (func(), error) {
+	waitCtx := ctx
+	if timeout := u.cfg.BucketStore.MaxConcurrentQueueTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	userGate := u.tenantGates.get(userID)
+	if err := u.startGate(ctx, waitCtx, userGate, "tenant"); err != nil {
+		return nil, err
+	}
+
+	if err := u.startGate(ctx, waitCtx, u.queryGate, "instance"); err != nil {
+		userGate.Done()
+		return nil, err
+	}
+
+	return func() {
+		u.queryGate.Done()
+		userGate.Done()
+	}, nil
+}
+
+func (u *BucketStores) startGate(ctx, waitCtx context.Context, g gate.Gate, name string) error {
+	if err := g.Start(waitCtx); err != nil {
+		// The wait timed out if the request has not been canceled in the meanwhile.
+		if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			u.queueTimeouts.WithLabelValues(name).Inc()
+			return ErrQueueTimeout
+		}
+		return errors.Wrapf(err, "failed to wait for turn")
+	}
+	return nil
+}
@@ -33,6 +33,10 @@ const (
 	syncReasonPeriodic   = "periodic"
 	syncReasonRingChange = "ring-change"
 
+	// tenantSyncCheckInterval is how frequently the tenants with a custom sync interval are checked
+	// to find the ones due to be synced.
+	tenantSyncCheckInterval = time.Minute
+
 	// sharedOptionWithQuerier is a message appended to all config options that should be also
 	// set on the querier in order to work correct.
 	sharedOptionWithQuerier = " This option needs be set both on the store-gateway and querier when running in microservices mode."
@@ -84,6 +88,10 @@ func (cfg *Config) Validate(limits validation.Limits) error {
 		if cfg.ShardingStrategy == util.ShardingStrategyShuffle && limits.StoreGatewayTenantShardSize <= 0 {
 			return errInvalidTenantShardSize
 		}
+
+		if err := ring.ValidateTokensGeneratorStrategy(cfg.ShardingRing.TokensGeneratorStrategy); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -322,6 +330,10 @@ func (g *StoreGateway) running(ctx context.Context) error {
 	syncTicker := time.NewTicker(util.DurationWithJitter(g.storageCfg.BucketStore.SyncInterval, 0.2))
 	defer syncTicker.Stop()
 
+	// Check every minute for the tenants with a custom sync interval which are due to be synced.
+	tenantSyncTicker := time.NewTicker(tenantSyncCheckInterval)
+	defer tenantSyncTicker.Stop()
+
 	if g.gatewayCfg.ShardingEnabled {
 		lastInstanceDescs, _ = g.ring.GetInstanceDescsForOperation(BlocksOwnerSync) // nolint:errcheck
 		ringTicker := time.NewTicker(util.DurationWithJitter(g.gatewayCfg.ShardingRing.RingCheckPeriod, 0.2))
@@ -333,6 +345,10 @@ func (g *StoreGateway) running(ctx context.Context) error {
 		select {
 		case <-syncTicker.C:
 			g.syncStores(ctx, syncReasonPeriodic)
+		case <-tenantSyncTicker.C:
+			if err := g.stores.SyncUsersBlocksDue(ctx); err != nil {
+				level.Warn(g.logger).Log("msg", "failed to synchronize TSDB blocks of the tenants with a custom sync interval", "err", err)
+			}
 		case <-ringTickerChan:
 			// We ignore the error because in case of error it will return an empty
 			// replication set which we use to compare with the previous state.
@@ -343,7 +359,7 @@ func (g *StoreGateway) running(ctx context.Context) error {
 				return ring.HasTokensChanged(b, a) || ring.HasZoneChanged(b, a)
 			}) {
 				lastInstanceDescs = currInstanceDescs
-				g.syncStores(ctx, syncReasonRingChange)
+				g.syncStoresOnRingChange(ctx)
 			}
 		case <-ctx.Done():
 			return nil
@@ -354,6 +370,8 @@ func (g *StoreGateway) running(ctx context.Context) error {
 }
 
 func (g *StoreGateway) stopping(_ error) error {
+	g.stores.persistQueriedBlocks()
+
 	if g.subservices != nil {
 		return services.StopManagerAndAwaitStopped(context.Background(), g.subservices)
 	}
@@ -371,6 +389,45 @@ func (g *StoreGateway) syncStores(ctx context.Context, reason string) {
 	}
 }
 
+// syncStoresOnRingChange synchronizes the blocks after a change of the ring. If the LOADING
+// state is enabled and the blocks owned by the store-gateway have changed, the store-gateway
+// is LOADING in the ring while synchronizing, so that the previous owners of the blocks it now
+// owns keep them loaded until it's ACTIVE again.
+func (g *StoreGateway) syncStoresOnRingChange(ctx context.Context) {
+	if state := g.ringLifecycler.GetState(); !g.gatewayCfg.ShardingRing.LoadingStateEnabled || (state != ring.ACTIVE && state != ring.LOADING) {
+		g.syncStores(ctx, syncReasonRingChange)
+		return
+	}
+
+	// A store-gateway still LOADING from a previous ring change always switches back to ACTIVE.
+	if g.ringLifecycler.GetState() == ring.ACTIVE {
+		if changed, err := g.stores.OwnedBlocksChanged(ctx); err != nil {
+			level.Warn(g.logger).Log("msg", "failed to check whether the blocks owned by the store-gateway have changed", "err", err)
+		} else if !changed {
+			g.syncStores(ctx, syncReasonRingChange)
+			return
+		}
+	}
+
+	if err := g.ringLifecycler.ChangeState(ctx, ring.LOADING); err != nil {
+		level.Warn(g.logger).Log("msg", "failed to switch store-gateway to LOADING in the ring", "err", err)
+	} else {
+		// Wait until the ring client detected this instance in the LOADING state, so that the
+		// state has been propagated to the other store-gateways before the blocks are synchronized.
+		ctxWithTimeout, cancel := context.WithTimeout(ctx, g.gatewayCfg.ShardingRing.WaitInstanceStateTimeout)
+		if err := ring.WaitInstanceState(ctxWithTimeout, g.ring, g.ringLifecycler.GetInstanceID(), ring.LOADING); err != nil {
+			level.Warn(g.logger).Log("msg", "store-gateway failed to become LOADING in the ring", "err", err)
+		}
+		cancel()
+	}
+
+	g.syncStores(ctx, syncReasonRingChange)
+
+	if err := g.ringLifecycler.ChangeState(ctx, ring.ACTIVE); err != nil {
+		level.Warn(g.logger).Log("msg", "failed to switch store-gateway back to ACTIVE in the ring", "err", err)
+	}
+}
+
 func (g *StoreGateway) Series(req *storepb.SeriesRequest, srv storegatewaypb.StoreGateway_SeriesServer) error {
 	return g.stores.Series(req, srv)
 }
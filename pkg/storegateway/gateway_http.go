@@ -10,6 +10,8 @@ import (
 	"github.com/cortexproject/cortex/pkg/util/services"
 )
 
+const tenantParam = "tenant"
+
 var (
 	statusPageTemplate = template.Must(template.New("main").Parse(`
 	<!DOCTYPE html>
@@ -51,3 +53,49 @@ func (c *StoreGateway) RingHandler(w http.ResponseWriter, req *http.Request) {
 
 	c.ring.ServeHTTP(w, req)
 }
+
+// RingDumpHandler responds with the full store-gateway ring descriptor as JSON.
+func (c *StoreGateway) RingDumpHandler(w http.ResponseWriter, req *http.Request) {
+	if !c.gatewayCfg.ShardingEnabled {
+		http.Error(w, "Store gateway has no ring because sharding is disabled.", http.StatusNotFound)
+		return
+	}
+
+	if c.State() != services.Running {
+		http.Error(w, "Store gateway is not running yet.", http.StatusServiceUnavailable)
+		return
+	}
+
+	c.ring.DumpHandler(w, req)
+}
+
+// SyncHandler triggers an immediate sync of the blocks of the tenants given by the tenant
+// parameter, and returns once the sync has been completed.
+func (c *StoreGateway) SyncHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		level.Warn(util_log.WithContext(r.Context(), c.logger)).Log("msg", "failed to parse HTTP request in sync handler", "err", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	tenants := r.Form[tenantParam]
+	if len(tenants) == 0 {
+		http.Error(w, "at least one tenant is required", http.StatusBadRequest)
+		return
+	}
+
+	if c.State() != services.Running {
+		http.Error(w, "store gateway is not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	for _, userID := range tenants {
+		if err := c.stores.SyncUserBlocks(r.Context(), userID); err != nil {
+			level.Warn(util_log.WithUserID(userID, c.logger)).Log("msg", "failed to sync user blocks", "err", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -34,7 +34,7 @@ const (
 var (
 	// BlocksOwnerSync is the operation used to check the authoritative owners of a block
 	// (replicas included).
-	BlocksOwnerSync = ring.NewOp([]ring.InstanceState{ring.JOINING, ring.ACTIVE, ring.LEAVING}, func(s ring.InstanceState) bool {
+	BlocksOwnerSync = ring.NewOp([]ring.InstanceState{ring.JOINING, ring.ACTIVE, ring.LEAVING, ring.LOADING}, func(s ring.InstanceState) bool {
 		// Extend the replication set only when an instance is LEAVING so that
 		// their blocks will be loaded sooner on the next authoritative owner(s).
 		return s == ring.LEAVING
@@ -42,15 +42,16 @@ var (
 
 	// BlocksOwnerRead is the operation used to check the authoritative owners of a block
 	// (replicas included) that are available for queries (a store-gateway is available for
-	// queries only when ACTIVE).
+	// queries only when ACTIVE). A LOADING store-gateway may have not loaded the block yet.
 	BlocksOwnerRead = ring.NewOp([]ring.InstanceState{ring.ACTIVE}, nil)
 
 	// BlocksRead is the operation run by the querier to query blocks via the store-gateway.
-	BlocksRead = ring.NewOp([]ring.InstanceState{ring.ACTIVE}, func(s ring.InstanceState) bool {
+	BlocksRead = ring.NewOp([]ring.InstanceState{ring.ACTIVE, ring.LOADING}, func(s ring.InstanceState) bool {
 		// Blocks can only be queried from ACTIVE instances. However, if the block belongs to
 		// a non-active instance, then we should extend the replication set and try to query it
 		// from the next ACTIVE instance in the ring (which is expected to have it because a
 		// store-gateway keeps their previously owned blocks until new owners are ACTIVE).
+		// LOADING instances are queried too, because they may have already loaded the block.
 		return s != ring.ACTIVE
 	})
 )
@@ -65,9 +66,11 @@ type RingConfig struct {
 	HeartbeatTimeout                time.Duration `yaml:"heartbeat_timeout"`
 	ReplicationFactor               int           `yaml:"replication_factor"`
 	TokensFilePath                  string        `yaml:"tokens_file_path"`
+	TokensGeneratorStrategy         string        `yaml:"tokens_generator_strategy"`
 	ZoneAwarenessEnabled            bool          `yaml:"zone_awareness_enabled"`
 	KeepInstanceInTheRingOnShutdown bool          `yaml:"keep_instance_in_the_ring_on_shutdown"`
 	ZoneStableShuffleSharding       bool          `yaml:"zone_stable_shuffle_sharding" doc:"hidden"`
+	LoadingStateEnabled             bool          `yaml:"loading_state_enabled"`
 
 	// Wait ring stability.
 	WaitStabilityMinDuration time.Duration `yaml:"wait_stability_min_duration"`
@@ -104,9 +107,11 @@ func (cfg *RingConfig) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&cfg.HeartbeatTimeout, ringFlagsPrefix+"heartbeat-timeout", time.Minute, "The heartbeat timeout after which store gateways are considered unhealthy within the ring. 0 = never (timeout disabled)."+sharedOptionWithQuerier)
 	f.IntVar(&cfg.ReplicationFactor, ringFlagsPrefix+"replication-factor", 3, "The replication factor to use when sharding blocks."+sharedOptionWithQuerier)
 	f.StringVar(&cfg.TokensFilePath, ringFlagsPrefix+"tokens-file-path", "", "File path where tokens are stored. If empty, tokens are not stored at shutdown and restored at startup.")
+	ring.RegisterTokensGeneratorStrategyFlag(f, ringFlagsPrefix+"tokens-generator-strategy", &cfg.TokensGeneratorStrategy)
 	f.BoolVar(&cfg.ZoneAwarenessEnabled, ringFlagsPrefix+"zone-awareness-enabled", false, "True to enable zone-awareness and replicate blocks across different availability zones.")
 	f.BoolVar(&cfg.KeepInstanceInTheRingOnShutdown, ringFlagsPrefix+"keep-instance-in-the-ring-on-shutdown", false, "True to keep the store gateway instance in the ring when it shuts down. The instance will then be auto-forgotten from the ring after 10*heartbeat_timeout.")
 	f.BoolVar(&cfg.ZoneStableShuffleSharding, ringFlagsPrefix+"zone-stable-shuffle-sharding", true, "If true, use zone stable shuffle sharding algorithm. Otherwise, use the default shuffle sharding algorithm.")
+	f.BoolVar(&cfg.LoadingStateEnabled, ringFlagsPrefix+"loading-state-enabled", false, "[Experimental] True to switch the store gateway to the LOADING state in the ring while it synchronizes its blocks after a change of the ring which changed the blocks it owns. Blocks no longer owned by a store gateway are kept loaded, and queried, until at least one of their new owners is ACTIVE. Enable it only once all store gateways and queriers support the LOADING state.")
 
 	// Wait stability flags.
 	f.DurationVar(&cfg.WaitStabilityMinDuration, ringFlagsPrefix+"wait-stability-min-duration", time.Minute, "Minimum time to wait for ring stability at startup. 0 to disable.")
@@ -157,6 +162,7 @@ func (cfg *RingConfig) ToLifecyclerConfig(logger log.Logger) (ring.BasicLifecycl
 		HeartbeatPeriod:                 cfg.HeartbeatPeriod,
 		TokensObservePeriod:             0,
 		NumTokens:                       RingNumTokens,
+		TokensGeneratorStrategy:         cfg.TokensGeneratorStrategy,
 		KeepInstanceInTheRingOnShutdown: cfg.KeepInstanceInTheRingOnShutdown,
 		FinalSleep:                      cfg.FinalSleep,
 	}, nil
@@ -78,6 +78,20 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			expected: nil,
 		},
+		"should fail if the tokens generator strategy is invalid": {
+			setup: func(cfg *Config, limits *validation.Limits) {
+				cfg.ShardingEnabled = true
+				cfg.ShardingRing.TokensGeneratorStrategy = "xxx"
+			},
+			expected: ring.ErrInvalidTokensGeneratorStrategy,
+		},
+		"should pass if the tokens generator strategy is minimize-spread": {
+			setup: func(cfg *Config, limits *validation.Limits) {
+				cfg.ShardingEnabled = true
+				cfg.ShardingRing.TokensGeneratorStrategy = "minimize-spread"
+			},
+			expected: nil,
+		},
 	}
 
 	for testName, testData := range tests {
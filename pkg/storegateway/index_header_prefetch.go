@@ -0,0 +1,226 @@
+package storegateway
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log/level"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/store/hintspb"
+	"github.com/thanos-io/thanos/pkg/store/storepb"
+
+	"github.com/cortexproject/cortex/pkg/util/concurrency"
+)
+
+// queriedBlocksFilename is the name of the file, in the sync dir, where the most recently
+// queried blocks are persisted.
+const queriedBlocksFilename = "queried-blocks.json"
+
+type queriedBlock struct {
+	UserID  string    `json:"user_id"`
+	BlockID ulid.ULID `json:"block_id"`
+}
+
+// queriedBlocksTracker keeps the LRU list of the most recently queried blocks, persisted to
+// disk so that their index-headers can be prefetched when the store-gateway restarts.
+type queriedBlocksTracker struct {
+	path      string
+	maxBlocks int
+
+	mtx   sync.Mutex
+	lru   *list.List
+	items map[queriedBlock]*list.Element
+}
+
+func newQueriedBlocksTracker(dir string, maxBlocks int) *queriedBlocksTracker {
+	return &queriedBlocksTracker{
+		path:      filepath.Join(dir, queriedBlocksFilename),
+		maxBlocks: maxBlocks,
+		lru:       list.New(),
+		items:     map[queriedBlock]*list.Element{},
+	}
+}
+
+// track moves the given blocks of the tenant to the front of the list, evicting the least
+// recently queried blocks if the list is full.
+func (t *queriedBlocksTracker) track(userID string, blockIDs []ulid.ULID) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for _, id := range blockIDs {
+		b := queriedBlock{UserID: userID, BlockID: id}
+		if e, ok := t.items[b]; ok {
+			t.lru.MoveToFront(e)
+			continue
+		}
+
+		t.items[b] = t.lru.PushFront(b)
+		if t.lru.Len() > t.maxBlocks {
+			oldest := t.lru.Back()
+			t.lru.Remove(oldest)
+			delete(t.items, oldest.Value.(queriedBlock))
+		}
+	}
+}
+
+// blocks returns the tracked blocks, the most recently queried first.
+func (t *queriedBlocksTracker) blocks() []queriedBlock {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	res := make([]queriedBlock, 0, t.lru.Len())
+	for e := t.lru.Front(); e != nil; e = e.Next() {
+		res = append(res, e.Value.(queriedBlock))
+	}
+	return res
+}
+
+// persist writes the tracked blocks to disk. The file is written atomically, so that a
+// store-gateway crashing meanwhile doesn't leave a corrupted file behind.
+func (t *queriedBlocksTracker) persist() error {
+	data, err := json.Marshal(t.blocks())
+	if err != nil {
+		return err
+	}
+
+	tmpPath := t.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, t.path)
+}
+
+// load reads the tracked blocks persisted to disk, if any, and tracks them again.
+func (t *queriedBlocksTracker) load() error {
+	data, err := os.ReadFile(t.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var persisted []queriedBlock
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return errors.Wrapf(err, "parse %s", t.path)
+	}
+
+	// Track them from the least recently queried, to preserve the order.
+	for i := len(persisted) - 1; i >= 0; i-- {
+		t.track(persisted[i].UserID, []ulid.ULID{persisted[i].BlockID})
+	}
+	return nil
+}
+
+// blockIDsFromMatchers returns the IDs of the blocks selected by the block matchers of a
+// request hints, which the querier sets to select the blocks to query.
+func blockIDsFromMatchers(matchers []storepb.LabelMatcher) []ulid.ULID {
+	var ids []ulid.ULID
+	for _, m := range matchers {
+		if m.Name != block.BlockIDLabel || (m.Type != storepb.LabelMatcher_RE && m.Type != storepb.LabelMatcher_EQ) {
+			continue
+		}
+
+		for _, value := range strings.Split(m.Value, "|") {
+			if id, err := ulid.Parse(value); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// trackQueriedBlocks tracks the blocks queried by a request of the tenant, selected by the
+// request hints, if the prefetching of the index-headers is enabled.
+func (u *BucketStores) trackQueriedBlocks(userID string, hints *types.Any, reqHints proto.Message) {
+	if u.queriedBlocks == nil || hints == nil {
+		return
+	}
+
+	if err := types.UnmarshalAny(hints, reqHints); err != nil {
+		return
+	}
+
+	var matchers []storepb.LabelMatcher
+	switch h := reqHints.(type) {
+	case *hintspb.SeriesRequestHints:
+		matchers = h.BlockMatchers
+	case *hintspb.LabelNamesRequestHints:
+		matchers = h.BlockMatchers
+	case *hintspb.LabelValuesRequestHints:
+		matchers = h.BlockMatchers
+	}
+	u.queriedBlocks.track(userID, blockIDsFromMatchers(matchers))
+}
+
+// persistQueriedBlocks persists to disk the most recently queried blocks, if the prefetching
+// of the index-headers is enabled.
+func (u *BucketStores) persistQueriedBlocks() {
+	if u.queriedBlocks == nil {
+		return
+	}
+
+	if err := u.queriedBlocks.persist(); err != nil {
+		level.Warn(u.logger).Log("msg", "failed to persist the most recently queried blocks", "err", err)
+	}
+}
+
+// prefetchIndexHeaders loads the index-headers of the most recently queried blocks persisted
+// before the restart of the store-gateway, among the blocks loaded by the initial sync.
+func (u *BucketStores) prefetchIndexHeaders(ctx context.Context) {
+	if u.queriedBlocks == nil {
+		return
+	}
+
+	if err := u.queriedBlocks.load(); err != nil {
+		level.Warn(u.logger).Log("msg", "failed to load the most recently queried blocks", "err", err)
+		return
+	}
+
+	blocksByUser := map[string][]string{}
+	for _, b := range u.queriedBlocks.blocks() {
+		blocksByUser[b.UserID] = append(blocksByUser[b.UserID], b.BlockID.String())
+	}
+
+	userIDs := make([]string, 0, len(blocksByUser))
+	for userID := range blocksByUser {
+		userIDs = append(userIDs, userID)
+	}
+
+	level.Info(u.logger).Log("msg", "prefetching the index-headers of the most recently queried blocks", "users", len(userIDs))
+
+	_ = concurrency.ForEachUser(ctx, userIDs, u.cfg.BucketStore.TenantSyncConcurrency, func(ctx context.Context, userID string) error {
+		s := u.getStore(userID)
+		if s == nil {
+			return nil
+		}
+
+		hints, err := types.MarshalAny(&hintspb.LabelNamesRequestHints{
+			BlockMatchers: []storepb.LabelMatcher{
+				{Type: storepb.LabelMatcher_RE, Name: block.BlockIDLabel, Value: strings.Join(blocksByUser[userID], "|")},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		// Reading the label names of the blocks loads their index-headers, without
+		// reading the index from the bucket.
+		if _, err := s.LabelNames(ctx, &storepb.LabelNamesRequest{Start: math.MinInt64, End: math.MaxInt64, Hints: hints}); err != nil {
+			level.Warn(u.logger).Log("msg", "failed to prefetch the index-headers of the most recently queried blocks", "user", userID, "err", err)
+		}
+		return nil
+	})
+
+	level.Info(u.logger).Log("msg", "prefetched the index-headers of the most recently queried blocks")
+}
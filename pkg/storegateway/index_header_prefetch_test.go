@@ -0,0 +1,193 @@
+package storegateway
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/gogo/protobuf/types"
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/store/hintspb"
+	"github.com/thanos-io/thanos/pkg/store/storepb"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket/filesystem"
+)
+
+func TestQueriedBlocksTracker_ShouldEvictTheLeastRecentlyQueriedBlocks(t *testing.T) {
+	var (
+		block1 = ulid.MustNew(1, nil)
+		block2 = ulid.MustNew(2, nil)
+		block3 = ulid.MustNew(3, nil)
+	)
+
+	tracker := newQueriedBlocksTracker(t.TempDir(), 2)
+	tracker.track("user-1", []ulid.ULID{block1, block2})
+	tracker.track("user-1", []ulid.ULID{block1})
+	tracker.track("user-2", []ulid.ULID{block3})
+
+	assert.Equal(t, []queriedBlock{
+		{UserID: "user-2", BlockID: block3},
+		{UserID: "user-1", BlockID: block1},
+	}, tracker.blocks())
+}
+
+func TestQueriedBlocksTracker_PersistAndLoad(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		block1 = ulid.MustNew(1, nil)
+		block2 = ulid.MustNew(2, nil)
+		block3 = ulid.MustNew(3, nil)
+	)
+
+	// Loading a non existing file should be a no-op.
+	loaded := newQueriedBlocksTracker(dir, 10)
+	require.NoError(t, loaded.load())
+	assert.Empty(t, loaded.blocks())
+
+	tracker := newQueriedBlocksTracker(dir, 10)
+	tracker.track("user-1", []ulid.ULID{block1, block2})
+	tracker.track("user-2", []ulid.ULID{block3})
+	require.NoError(t, tracker.persist())
+
+	loaded = newQueriedBlocksTracker(dir, 10)
+	require.NoError(t, loaded.load())
+	assert.Equal(t, tracker.blocks(), loaded.blocks())
+
+	// Loading the file with a lower max blocks should keep the most recently queried ones.
+	loaded = newQueriedBlocksTracker(dir, 1)
+	require.NoError(t, loaded.load())
+	assert.Equal(t, []queriedBlock{{UserID: "user-2", BlockID: block3}}, loaded.blocks())
+
+	// A corrupted file should fail to load.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, queriedBlocksFilename), []byte("{"), 0o644))
+	require.Error(t, newQueriedBlocksTracker(dir, 10).load())
+}
+
+func TestBlockIDsFromMatchers(t *testing.T) {
+	var (
+		block1 = ulid.MustNew(1, nil)
+		block2 = ulid.MustNew(2, nil)
+	)
+
+	tests := map[string]struct {
+		matchers []storepb.LabelMatcher
+		expected []ulid.ULID
+	}{
+		"no matchers": {
+			expected: nil,
+		},
+		"equal matcher": {
+			matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: block.BlockIDLabel, Value: block1.String()}},
+			expected: []ulid.ULID{block1},
+		},
+		"regex matcher": {
+			matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_RE, Name: block.BlockIDLabel, Value: block1.String() + "|" + block2.String()}},
+			expected: []ulid.ULID{block1, block2},
+		},
+		"should skip negative matchers, other labels and invalid IDs": {
+			matchers: []storepb.LabelMatcher{
+				{Type: storepb.LabelMatcher_NEQ, Name: block.BlockIDLabel, Value: block1.String()},
+				{Type: storepb.LabelMatcher_EQ, Name: "other", Value: block1.String()},
+				{Type: storepb.LabelMatcher_RE, Name: block.BlockIDLabel, Value: "invalid|" + block2.String()},
+			},
+			expected: []ulid.ULID{block2},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expected, blockIDsFromMatchers(testData.matchers))
+		})
+	}
+}
+
+func TestBucketStores_InitialSyncShouldPrefetchTheIndexHeadersOfTheMostRecentlyQueriedBlocks(t *testing.T) {
+	ctx := context.Background()
+	cfg := prepareStorageConfig(t)
+	cfg.BucketStore.IndexHeaderLazyLoadingEnabled = true
+	cfg.BucketStore.IndexHeaderPrefetchEnabled = true
+
+	storageDir := t.TempDir()
+	generateStorageBlock(t, storageDir, "user-1", "series_1", 10, 100, 15)
+	generateStorageBlock(t, storageDir, "user-1", "series_1", 100, 200, 15)
+	generateStorageBlock(t, storageDir, "user-2", "series_2", 10, 100, 15)
+
+	// Persist one of the blocks of user-1 as queried before the restart, along with a block
+	// which doesn't exist anymore.
+	entries, err := os.ReadDir(filepath.Join(storageDir, "user-1"))
+	require.NoError(t, err)
+	queriedBlockID, err := ulid.Parse(entries[0].Name())
+	require.NoError(t, err)
+
+	tracker := newQueriedBlocksTracker(cfg.BucketStore.SyncDir, cfg.BucketStore.IndexHeaderPrefetchMaxBlocks)
+	tracker.track("user-1", []ulid.ULID{queriedBlockID, ulid.MustNew(1, nil)})
+	require.NoError(t, tracker.persist())
+
+	bucket, err := filesystem.NewBucketClient(filesystem.Config{Directory: storageDir})
+	require.NoError(t, err)
+
+	reg := prometheus.NewPedanticRegistry()
+	stores, err := NewBucketStores(cfg, NewNoShardingStrategy(log.NewNopLogger(), nil), objstore.WithNoopInstr(bucket), defaultLimitsOverrides(t), mockLoggingLevel(), log.NewNopLogger(), reg)
+	require.NoError(t, err)
+	require.NoError(t, stores.InitialSync(ctx))
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+			# HELP cortex_bucket_store_indexheader_lazy_load_total Total number of index-header lazy load operations.
+			# TYPE cortex_bucket_store_indexheader_lazy_load_total counter
+			cortex_bucket_store_indexheader_lazy_load_total 1
+	`), "cortex_bucket_store_indexheader_lazy_load_total"))
+}
+
+func TestBucketStores_ShouldPersistTheMostRecentlyQueriedBlocks(t *testing.T) {
+	ctx := context.Background()
+	cfg := prepareStorageConfig(t)
+	cfg.BucketStore.IndexHeaderLazyLoadingEnabled = true
+	cfg.BucketStore.IndexHeaderPrefetchEnabled = true
+
+	storageDir := t.TempDir()
+	generateStorageBlock(t, storageDir, "user-1", "series_1", 10, 100, 15)
+
+	bucket, err := filesystem.NewBucketClient(filesystem.Config{Directory: storageDir})
+	require.NoError(t, err)
+
+	stores, err := NewBucketStores(cfg, NewNoShardingStrategy(log.NewNopLogger(), nil), objstore.WithNoopInstr(bucket), defaultLimitsOverrides(t), mockLoggingLevel(), log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, stores.InitialSync(ctx))
+
+	entries, err := os.ReadDir(filepath.Join(storageDir, "user-1"))
+	require.NoError(t, err)
+	blockID, err := ulid.Parse(entries[0].Name())
+	require.NoError(t, err)
+
+	// Query the block selecting it through the block matchers, like the querier does.
+	hints, err := types.MarshalAny(&hintspb.SeriesRequestHints{
+		BlockMatchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_RE, Name: block.BlockIDLabel, Value: blockID.String()}},
+	})
+	require.NoError(t, err)
+
+	srv := newBucketStoreSeriesServer(setUserIDToGRPCContext(ctx, "user-1"))
+	require.NoError(t, stores.Series(&storepb.SeriesRequest{
+		MinTime:  10,
+		MaxTime:  100,
+		Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: labels.MetricName, Value: "series_1"}},
+		Hints:    hints,
+	}, srv))
+	require.Len(t, srv.SeriesSet, 1)
+
+	// The queried blocks are persisted at every sync.
+	require.NoError(t, stores.SyncBlocks(ctx))
+
+	loaded := newQueriedBlocksTracker(cfg.BucketStore.SyncDir, cfg.BucketStore.IndexHeaderPrefetchMaxBlocks)
+	require.NoError(t, loaded.load())
+	assert.Equal(t, []queriedBlock{{UserID: "user-1", BlockID: blockID}}, loaded.blocks())
+}
@@ -34,6 +34,22 @@ func (c *limiter) ReserveWithType(num uint64, _ store.StoreDataType) error {
 	return nil
 }
 
+// postingsLimiter is a bytes limiter reserving the bytes of the postings only.
+type postingsLimiter struct {
+	limiter *store.Limiter
+}
+
+func (c *postingsLimiter) ReserveWithType(num uint64, dataType store.StoreDataType) error {
+	if dataType != store.PostingsFetched && dataType != store.PostingsTouched {
+		return nil
+	}
+
+	if err := c.limiter.Reserve(num); err != nil {
+		return httpgrpc.Errorf(http.StatusUnprocessableEntity, "exceeded postings bytes limit: %s", err.Error())
+	}
+	return nil
+}
+
 type compositeBytesLimiter struct {
 	limiters []store.BytesLimiter
 }
@@ -134,6 +150,7 @@ func newBytesLimiterFactory(limits *validation.Overrides, userID string, userTok
 		// Since limit overrides could be live reloaded, we have to get the current user's limit
 		// each time a new limiter is instantiated.
 		limiters = append(limiters, &limiter{limiter: store.NewLimiter(uint64(limits.MaxDownloadedBytesPerRequest(userID)), failedCounter)})
+		limiters = append(limiters, &postingsLimiter{limiter: store.NewLimiter(uint64(limits.MaxPostingsBytesPerRequest(userID)), failedCounter)})
 
 		if tokenBucketBytesLimiterCfg.Mode != string(tsdb.TokenBucketBytesLimiterDisabled) {
 			requestTokenBucket := util.NewTokenBucket(tokenBucketBytesLimiterCfg.RequestTokenBucketSize, nil)
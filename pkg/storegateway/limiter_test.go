@@ -22,6 +22,17 @@ func TestLimiter(t *testing.T) {
 	assert.Error(t, l.ReserveWithType(1, store.PostingsFetched))
 }
 
+func TestPostingsLimiter(t *testing.T) {
+	l := &postingsLimiter{
+		limiter: store.NewLimiter(2, prometheus.NewCounter(prometheus.CounterOpts{})),
+	}
+
+	assert.NoError(t, l.ReserveWithType(1, store.PostingsFetched))
+	assert.NoError(t, l.ReserveWithType(5, store.ChunksFetched))
+	assert.NoError(t, l.ReserveWithType(1, store.PostingsTouched))
+	assert.ErrorContains(t, l.ReserveWithType(1, store.PostingsTouched), "(422)")
+}
+
 func TestCompositeLimiter(t *testing.T) {
 	l := &compositeBytesLimiter{
 		limiters: []store.BytesLimiter{
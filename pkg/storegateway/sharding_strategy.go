@@ -2,13 +2,16 @@ package storegateway
 
 import (
 	"context"
+	"sync"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/thanos-io/objstore"
 	"github.com/thanos-io/thanos/pkg/block"
 	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/thanos-io/thanos/pkg/extprom"
 
 	"github.com/cortexproject/cortex/pkg/ring"
 	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
@@ -222,11 +225,17 @@ type shardingMetadataFilterAdapter struct {
 	userID   string
 	strategy ShardingStrategy
 
-	// Keep track of the last blocks returned by the Filter() function.
+	// Keep track of the blocks passed to and returned by the last call of the Filter() function.
+	mtx        sync.Mutex
+	allBlocks  map[ulid.ULID]*metadata.Meta
 	lastBlocks map[ulid.ULID]struct{}
 }
 
 func NewShardingMetadataFilterAdapter(userID string, strategy ShardingStrategy) block.MetadataFilter {
+	return newShardingMetadataFilterAdapter(userID, strategy)
+}
+
+func newShardingMetadataFilterAdapter(userID string, strategy ShardingStrategy) *shardingMetadataFilterAdapter {
 	return &shardingMetadataFilterAdapter{
 		userID:     userID,
 		strategy:   strategy,
@@ -235,13 +244,21 @@ func NewShardingMetadataFilterAdapter(userID string, strategy ShardingStrategy)
 }
 
 // Filter implements block.MetadataFilter.
-// This function is NOT safe for use by multiple goroutines concurrently.
 func (a *shardingMetadataFilterAdapter) Filter(ctx context.Context, metas map[ulid.ULID]*metadata.Meta, synced block.GaugeVec, _ block.GaugeVec) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	allBlocks := make(map[ulid.ULID]*metadata.Meta, len(metas))
+	for blockID, meta := range metas {
+		allBlocks[blockID] = meta
+	}
+
 	if err := a.strategy.FilterBlocks(ctx, a.userID, metas, a.lastBlocks, synced); err != nil {
 		return err
 	}
 
 	// Keep track of the last filtered blocks.
+	a.allBlocks = allBlocks
 	a.lastBlocks = make(map[ulid.ULID]struct{}, len(metas))
 	for blockID := range metas {
 		a.lastBlocks[blockID] = struct{}{}
@@ -250,6 +267,34 @@ func (a *shardingMetadataFilterAdapter) Filter(ctx context.Context, metas map[ul
 	return nil
 }
 
+// ownedBlocksChanged returns whether the blocks owned by the store-gateway, among the ones known
+// from the last call of the Filter() function, are different from the ones returned by that call,
+// for example because the ring has changed since then.
+func (a *shardingMetadataFilterAdapter) ownedBlocksChanged(ctx context.Context) (bool, error) {
+	a.mtx.Lock()
+	metas := make(map[ulid.ULID]*metadata.Meta, len(a.allBlocks))
+	for blockID, meta := range a.allBlocks {
+		metas[blockID] = meta
+	}
+	lastBlocks := a.lastBlocks
+	a.mtx.Unlock()
+
+	synced := extprom.NewTxGaugeVec(nil, prometheus.GaugeOpts{}, []string{"state"})
+	if err := a.strategy.FilterBlocks(ctx, a.userID, metas, lastBlocks, synced); err != nil {
+		return false, err
+	}
+
+	if len(metas) != len(lastBlocks) {
+		return true, nil
+	}
+	for blockID := range metas {
+		if _, ok := lastBlocks[blockID]; !ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 type shardingBucketReaderAdapter struct {
 	objstore.InstrumentedBucketReader
 
@@ -236,6 +236,19 @@ func TestDefaultShardingStrategy(t *testing.T) {
 				"127.0.0.3": {block4},
 			},
 		},
+		"LOADING instance in the ring should keep its shard blocks and they should not be replicated to another instance": {
+			replicationFactor: 1,
+			setupRing: func(r *ring.Desc) {
+				r.AddIngester("instance-1", "127.0.0.1", "", []uint32{block1Hash + 1, block3Hash + 1}, ring.ACTIVE, registeredAt)
+				r.AddIngester("instance-2", "127.0.0.2", "", []uint32{block2Hash + 1}, ring.ACTIVE, registeredAt)
+				r.AddIngester("instance-3", "127.0.0.3", "", []uint32{block4Hash + 1}, ring.LOADING, registeredAt)
+			},
+			expectedBlocks: map[string][]ulid.ULID{
+				"127.0.0.1": {block1, block3},
+				"127.0.0.2": {block2},
+				"127.0.0.3": {block4},
+			},
+		},
 	}
 
 	for testName, testData := range tests {
@@ -300,6 +313,118 @@ func TestDefaultShardingStrategy(t *testing.T) {
 	}
 }
 
+func TestDefaultShardingStrategy_ShouldKeepLoadedBlocksUntilNewOwnerIsActive(t *testing.T) {
+	t.Parallel()
+
+	block1 := ulid.MustNew(1, nil) // hash: 283204220
+	block2 := ulid.MustNew(2, nil) // hash: 444110359
+	block1Hash := cortex_tsdb.HashBlockID(block1)
+	block2Hash := cortex_tsdb.HashBlockID(block2)
+
+	ctx := context.Background()
+	store, closer := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	// The block1 has been previously owned by instance-2, and it's now owned by instance-1.
+	setOwnerState := func(state ring.InstanceState) {
+		require.NoError(t, store.CAS(ctx, "test", func(in interface{}) (interface{}, bool, error) {
+			d := ring.NewDesc()
+			d.AddIngester("instance-1", "127.0.0.1", "", []uint32{block1Hash + 1}, state, time.Now())
+			d.AddIngester("instance-2", "127.0.0.2", "", []uint32{block1Hash + 2, block2Hash + 1}, ring.ACTIVE, time.Now())
+			return d, true, nil
+		}))
+	}
+	setOwnerState(ring.LOADING)
+
+	r, err := ring.NewWithStoreClientAndStrategy(ring.Config{ReplicationFactor: 1, HeartbeatTimeout: time.Minute}, "test", "test", store, ring.NewIgnoreUnhealthyInstancesReplicationStrategy(), nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, r))
+	defer services.StopAndAwaitTerminated(ctx, r) //nolint:errcheck
+	require.NoError(t, ring.WaitInstanceState(ctx, r, "instance-1", ring.LOADING))
+
+	filterBlocks := func() []ulid.ULID {
+		filter := NewDefaultShardingStrategy(r, "127.0.0.2", log.NewNopLogger(), nil)
+		synced := extprom.NewTxGaugeVec(nil, prometheus.GaugeOpts{}, []string{"state"})
+		metas := map[ulid.ULID]*metadata.Meta{block1: {}, block2: {}}
+		require.NoError(t, filter.FilterBlocks(ctx, "user-1", metas, map[ulid.ULID]struct{}{block1: {}}, synced))
+
+		var actualBlocks []ulid.ULID
+		for id := range metas {
+			actualBlocks = append(actualBlocks, id)
+		}
+		return actualBlocks
+	}
+
+	// The previous owner keeps the block loaded while the new owner is LOADING.
+	assert.ElementsMatch(t, []ulid.ULID{block1, block2}, filterBlocks())
+
+	// The previous owner unloads the block once the new owner is ACTIVE.
+	setOwnerState(ring.ACTIVE)
+	require.NoError(t, ring.WaitInstanceState(ctx, r, "instance-1", ring.ACTIVE))
+	assert.ElementsMatch(t, []ulid.ULID{block2}, filterBlocks())
+}
+
+func TestShardingMetadataFilterAdapter_OwnedBlocksChanged(t *testing.T) {
+	t.Parallel()
+
+	block1 := ulid.MustNew(1, nil) // hash: 283204220
+	block2 := ulid.MustNew(2, nil) // hash: 444110359
+	block1Hash := cortex_tsdb.HashBlockID(block1)
+	block2Hash := cortex_tsdb.HashBlockID(block2)
+
+	ctx := context.Background()
+	store, closer := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	updateRing := func(f func(d *ring.Desc)) {
+		require.NoError(t, store.CAS(ctx, "test", func(in interface{}) (interface{}, bool, error) {
+			d := ring.NewDesc()
+			d.AddIngester("instance-1", "127.0.0.1", "", []uint32{block1Hash + 1}, ring.ACTIVE, time.Now())
+			d.AddIngester("instance-2", "127.0.0.2", "", []uint32{block2Hash + 1}, ring.ACTIVE, time.Now())
+			f(d)
+			return d, true, nil
+		}))
+	}
+	updateRing(func(*ring.Desc) {})
+
+	r, err := ring.NewWithStoreClientAndStrategy(ring.Config{ReplicationFactor: 1, HeartbeatTimeout: time.Minute}, "test", "test", store, ring.NewIgnoreUnhealthyInstancesReplicationStrategy(), nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, r))
+	defer services.StopAndAwaitTerminated(ctx, r) //nolint:errcheck
+	require.NoError(t, ring.WaitInstanceState(ctx, r, "instance-2", ring.ACTIVE))
+
+	adapter := newShardingMetadataFilterAdapter("user-1", NewDefaultShardingStrategy(r, "127.0.0.1", log.NewNopLogger(), nil))
+	metas := map[ulid.ULID]*metadata.Meta{block1: {}, block2: {}}
+	require.NoError(t, adapter.Filter(ctx, metas, extprom.NewTxGaugeVec(nil, prometheus.GaugeOpts{}, []string{"state"}), nil))
+	require.Len(t, metas, 1)
+	require.Contains(t, metas, block1)
+
+	changed, err := adapter.ownedBlocksChanged(ctx)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// A new instance which doesn't take any block of the store-gateway.
+	updateRing(func(d *ring.Desc) {
+		d.AddIngester("instance-3", "127.0.0.3", "", []uint32{block1Hash + 2}, ring.ACTIVE, time.Now())
+	})
+	require.NoError(t, ring.WaitInstanceState(ctx, r, "instance-3", ring.ACTIVE))
+
+	changed, err = adapter.ownedBlocksChanged(ctx)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// The store-gateway now owns the block of a leaving instance.
+	updateRing(func(d *ring.Desc) {
+		d.AddIngester("instance-2", "127.0.0.2", "", []uint32{block2Hash + 1}, ring.LEAVING, time.Now())
+		d.AddIngester("instance-1", "127.0.0.1", "", []uint32{block1Hash + 1, block2Hash + 2}, ring.ACTIVE, time.Now())
+	})
+	require.NoError(t, ring.WaitInstanceState(ctx, r, "instance-2", ring.LEAVING))
+
+	changed, err = adapter.ownedBlocksChanged(ctx)
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
+
 func TestShuffleShardingStrategy(t *testing.T) {
 	t.Parallel()
 	// The following block IDs have been picked to have increasing hash values
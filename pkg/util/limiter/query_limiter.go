@@ -20,6 +20,8 @@ var (
 	ErrMaxChunkBytesHit       = "the query hit the aggregated chunks size limit (limit: %d bytes)"
 	ErrMaxDataBytesHit        = "the query hit the aggregated data size limit (limit: %d bytes)"
 	ErrMaxChunksPerQueryLimit = "the query hit the max number of chunks limit (limit: %d chunks)"
+	ErrMaxSamplesHit          = "the query hit the max number of samples loaded into the query engine limit (limit: %d samples)"
+	ErrMaxEstimatedMemoryHit  = "the query hit the max estimated memory limit (limit: %d bytes)"
 )
 
 type QueryLimiter struct {
@@ -29,24 +31,30 @@ type QueryLimiter struct {
 	chunkBytesCount atomic.Int64
 	dataBytesCount  atomic.Int64
 	chunkCount      atomic.Int64
-
-	maxSeriesPerQuery     int
-	maxChunkBytesPerQuery int
-	maxDataBytesPerQuery  int
-	maxChunksPerQuery     int
+	samplesCount    atomic.Int64
+	memoryBytes     atomic.Int64
+
+	maxSeriesPerQuery               int
+	maxChunkBytesPerQuery           int
+	maxDataBytesPerQuery            int
+	maxChunksPerQuery               int
+	maxSamplesPerQuery              int
+	maxEstimatedMemoryBytesPerQuery int
 }
 
 // NewQueryLimiter makes a new per-query limiter. Each query limiter
 // is configured using the `maxSeriesPerQuery` limit.
-func NewQueryLimiter(maxSeriesPerQuery, maxChunkBytesPerQuery, maxChunksPerQuery, maxDataBytesPerQuery int) *QueryLimiter {
+func NewQueryLimiter(maxSeriesPerQuery, maxChunkBytesPerQuery, maxChunksPerQuery, maxDataBytesPerQuery, maxSamplesPerQuery, maxEstimatedMemoryBytesPerQuery int) *QueryLimiter {
 	return &QueryLimiter{
 		uniqueSeriesMx: sync.Mutex{},
 		uniqueSeries:   map[model.Fingerprint]struct{}{},
 
-		maxSeriesPerQuery:     maxSeriesPerQuery,
-		maxChunkBytesPerQuery: maxChunkBytesPerQuery,
-		maxChunksPerQuery:     maxChunksPerQuery,
-		maxDataBytesPerQuery:  maxDataBytesPerQuery,
+		maxSeriesPerQuery:               maxSeriesPerQuery,
+		maxChunkBytesPerQuery:           maxChunkBytesPerQuery,
+		maxChunksPerQuery:               maxChunksPerQuery,
+		maxDataBytesPerQuery:            maxDataBytesPerQuery,
+		maxSamplesPerQuery:              maxSamplesPerQuery,
+		maxEstimatedMemoryBytesPerQuery: maxEstimatedMemoryBytesPerQuery,
 	}
 }
 
@@ -60,7 +68,7 @@ func QueryLimiterFromContextWithFallback(ctx context.Context) *QueryLimiter {
 	ql, ok := ctx.Value(ctxKey).(*QueryLimiter)
 	if !ok {
 		// If there's no limiter return a new unlimited limiter as a fallback
-		ql = NewQueryLimiter(0, 0, 0, 0)
+		ql = NewQueryLimiter(0, 0, 0, 0, 0, 0)
 	}
 	return ql
 }
@@ -128,3 +136,32 @@ func (ql *QueryLimiter) AddChunks(count int) error {
 	}
 	return nil
 }
+
+// SamplesLimitsEnabled returns whether the samples loaded into the query engine are limited,
+// either by their number or by their estimated memory.
+func (ql *QueryLimiter) SamplesLimitsEnabled() bool {
+	return ql.maxSamplesPerQuery > 0 || ql.maxEstimatedMemoryBytesPerQuery > 0
+}
+
+// AddSamples adds the number of samples loaded into the query engine and returns an error if the limit is reached.
+func (ql *QueryLimiter) AddSamples(count int) error {
+	if ql.maxSamplesPerQuery == 0 {
+		return nil
+	}
+	if ql.samplesCount.Add(int64(count)) > int64(ql.maxSamplesPerQuery) {
+		return fmt.Errorf(ErrMaxSamplesHit, ql.maxSamplesPerQuery)
+	}
+	return nil
+}
+
+// AddEstimatedMemoryBytes adds the estimated memory in bytes of the series and samples loaded into
+// the query engine and returns an error if the limit is reached.
+func (ql *QueryLimiter) AddEstimatedMemoryBytes(bytes int) error {
+	if ql.maxEstimatedMemoryBytesPerQuery == 0 {
+		return nil
+	}
+	if ql.memoryBytes.Add(int64(bytes)) > int64(ql.maxEstimatedMemoryBytesPerQuery) {
+		return fmt.Errorf(ErrMaxEstimatedMemoryHit, ql.maxEstimatedMemoryBytesPerQuery)
+	}
+	return nil
+}
@@ -26,7 +26,7 @@ func TestQueryLimiter_AddSeries_ShouldReturnNoErrorOnLimitNotExceeded(t *testing
 			labels.MetricName: metricName + "_2",
 			"series2":         "1",
 		})
-		limiter = NewQueryLimiter(100, 0, 0, 0)
+		limiter = NewQueryLimiter(100, 0, 0, 0, 0, 0)
 	)
 	err := limiter.AddSeries(cortexpb.FromLabelsToLabelAdapters(series1))
 	assert.NoError(t, err)
@@ -76,7 +76,7 @@ func TestQueryLimiter_AddSeriers_ShouldReturnErrorOnLimitExceeded(t *testing.T)
 			labels.MetricName: metricName + "_2",
 			"series2":         "1",
 		})
-		limiter = NewQueryLimiter(1, 0, 0, 0)
+		limiter = NewQueryLimiter(1, 0, 0, 0, 0, 0)
 	)
 	err := limiter.AddSeries(series1)
 	require.NoError(t, err)
@@ -93,7 +93,7 @@ func TestQueryLimiter_AddSeriesBatch_ShouldReturnErrorOnLimitExceeded(t *testing
 		metricName = "test_metric"
 	)
 
-	limiter := NewQueryLimiter(10, 0, 0, 0)
+	limiter := NewQueryLimiter(10, 0, 0, 0, 0, 0)
 	series := make([][]cortexpb.LabelAdapter, 0, 10)
 
 	for i := 0; i < 10; i++ {
@@ -120,7 +120,7 @@ func TestQueryLimiter_AddSeriesBatch_ShouldReturnErrorOnLimitExceeded(t *testing
 }
 
 func TestQueryLimiter_AddChunkBytes(t *testing.T) {
-	var limiter = NewQueryLimiter(0, 100, 0, 0)
+	var limiter = NewQueryLimiter(0, 100, 0, 0, 0, 0)
 
 	err := limiter.AddChunkBytes(100)
 	require.NoError(t, err)
@@ -129,7 +129,7 @@ func TestQueryLimiter_AddChunkBytes(t *testing.T) {
 }
 
 func TestQueryLimiter_AddDataBytes(t *testing.T) {
-	var limiter = NewQueryLimiter(0, 0, 0, 100)
+	var limiter = NewQueryLimiter(0, 0, 0, 100, 0, 0)
 
 	err := limiter.AddDataBytes(100)
 	require.NoError(t, err)
@@ -137,6 +137,28 @@ func TestQueryLimiter_AddDataBytes(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestQueryLimiter_AddSamples(t *testing.T) {
+	var limiter = NewQueryLimiter(0, 0, 0, 0, 100, 0)
+	require.True(t, limiter.SamplesLimitsEnabled())
+
+	err := limiter.AddSamples(100)
+	require.NoError(t, err)
+	err = limiter.AddSamples(1)
+	require.EqualError(t, err, fmt.Sprintf(ErrMaxSamplesHit, 100))
+}
+
+func TestQueryLimiter_AddEstimatedMemoryBytes(t *testing.T) {
+	var limiter = NewQueryLimiter(0, 0, 0, 0, 0, 100)
+	require.True(t, limiter.SamplesLimitsEnabled())
+
+	err := limiter.AddEstimatedMemoryBytes(100)
+	require.NoError(t, err)
+	err = limiter.AddEstimatedMemoryBytes(1)
+	require.EqualError(t, err, fmt.Sprintf(ErrMaxEstimatedMemoryHit, 100))
+
+	require.False(t, NewQueryLimiter(0, 0, 0, 0, 0, 0).SamplesLimitsEnabled())
+}
+
 func BenchmarkQueryLimiter_AddSeries(b *testing.B) {
 	AddSeriesConcurrentBench(b, 1)
 }
@@ -151,7 +173,7 @@ func AddSeriesConcurrentBench(b *testing.B, batchSize int) {
 		metricName = "test_metric"
 	)
 
-	limiter := NewQueryLimiter(b.N+1, 0, 0, 0)
+	limiter := NewQueryLimiter(b.N+1, 0, 0, 0, 0, 0)
 
 	// Concurrent goroutines trying to add duplicated series
 	const numWorkers = 100
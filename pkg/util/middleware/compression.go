@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	acceptEncodingHeader  = "Accept-Encoding"
+	contentEncodingHeader = "Content-Encoding"
+	contentLengthHeader   = "Content-Length"
+	contentTypeHeader     = "Content-Type"
+
+	// GzipEncoding is the gzip content encoding.
+	GzipEncoding = "gzip"
+	// SnappyEncoding is the snappy content encoding, using the snappy framing format.
+	SnappyEncoding = "snappy"
+)
+
+// Content types of the query API responses, which are compressed.
+var compressibleContentTypes = []string{"application/json", "application/x-protobuf"}
+
+// ResponseCompression is a HTTP middleware compressing the query API responses with the
+// encoding negotiated with the Accept-Encoding request header, gzip or snappy. Responses
+// with another content type or already encoded are sent as is.
+type ResponseCompression struct {
+	uncompressedBytes *prometheus.CounterVec
+	compressedBytes   *prometheus.CounterVec
+}
+
+// NewResponseCompression makes a new ResponseCompression, tracking the size of the
+// responses before and after compression in metrics prefixed by metricsPrefix.
+func NewResponseCompression(metricsPrefix string, reg prometheus.Registerer) *ResponseCompression {
+	return &ResponseCompression{
+		uncompressedBytes: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: metricsPrefix + "_response_uncompressed_bytes_total",
+			Help: "Total size (in bytes) of the compressed responses, before compression.",
+		}, []string{"encoding"}),
+		compressedBytes: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: metricsPrefix + "_response_compressed_bytes_total",
+			Help: "Total size (in bytes) of the compressed responses, after compression.",
+		}, []string{"encoding"}),
+	}
+}
+
+// Wrap implements middleware.Interface.
+func (c *ResponseCompression) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := NegotiateEncoding(r.Header.Values(acceptEncodingHeader))
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// The response is compressed here, so the next handlers must not compress it.
+		r = r.Clone(r.Context())
+		r.Header.Del(acceptEncodingHeader)
+
+		cw := &compressionResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r)
+		if !cw.compress {
+			return
+		}
+
+		compressed, err := compress(encoding, cw.buf.Bytes())
+		if err != nil {
+			// Fall back to the uncompressed response.
+			w.Header().Del(contentLengthHeader)
+			w.WriteHeader(cw.statusCode)
+			_, _ = w.Write(cw.buf.Bytes())
+			return
+		}
+		c.uncompressedBytes.WithLabelValues(encoding).Add(float64(cw.buf.Len()))
+		c.compressedBytes.WithLabelValues(encoding).Add(float64(len(compressed)))
+
+		w.Header().Set(contentEncodingHeader, encoding)
+		w.Header().Add("Vary", acceptEncodingHeader)
+		w.Header().Set(contentLengthHeader, strconv.Itoa(len(compressed)))
+		w.WriteHeader(cw.statusCode)
+		_, _ = w.Write(compressed)
+	})
+}
+
+// NegotiateEncoding returns the supported encoding with the highest quality value in the
+// Accept-Encoding header values, the first one listed on ties, or "" if there is none.
+func NegotiateEncoding(acceptEncoding []string) string {
+	var (
+		encoding string
+		quality  float64
+	)
+	for _, value := range acceptEncoding {
+		for _, part := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != GzipEncoding && name != SnappyEncoding {
+				continue
+			}
+
+			q := 1.0
+			if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				parsed, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					continue
+				}
+				q = parsed
+			}
+			if q > quality {
+				encoding, quality = name, q
+			}
+		}
+	}
+	return encoding
+}
+
+func compress(encoding string, data []byte) ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+	)
+	switch encoding {
+	case GzipEncoding:
+		w = gzip.NewWriter(&buf)
+	case SnappyEncoding:
+		w = snappy.NewBufferedWriter(&buf)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// compressionResponseWriter buffers the response to compress it, if its content type is
+// compressible and it isn't already encoded. Otherwise it's written as is.
+type compressionResponseWriter struct {
+	http.ResponseWriter
+
+	wroteHeader bool
+	compress    bool
+	statusCode  int
+	buf         bytes.Buffer
+}
+
+func (w *compressionResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.statusCode = statusCode
+
+	h := w.ResponseWriter.Header()
+	w.compress = h.Get(contentEncodingHeader) == "" && isCompressible(h.Get(contentTypeHeader))
+	if !w.compress {
+		w.ResponseWriter.WriteHeader(statusCode)
+	}
+}
+
+func (w *compressionResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.compress {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+// Flush implements http.Flusher, for the streamed responses which aren't compressed.
+func (w *compressionResponseWriter) Flush() {
+	if w.compress {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func isCompressible(contentType string) bool {
+	for _, t := range compressibleContentTypes {
+		if strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
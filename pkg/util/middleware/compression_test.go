@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	for _, tc := range []struct {
+		acceptEncoding []string
+		expected       string
+	}{
+		{acceptEncoding: nil, expected: ""},
+		{acceptEncoding: []string{"identity"}, expected: ""},
+		{acceptEncoding: []string{"gzip"}, expected: GzipEncoding},
+		{acceptEncoding: []string{"snappy"}, expected: SnappyEncoding},
+		{acceptEncoding: []string{"deflate, GZIP"}, expected: GzipEncoding},
+		{acceptEncoding: []string{"snappy, gzip"}, expected: SnappyEncoding},
+		{acceptEncoding: []string{"gzip", "snappy"}, expected: GzipEncoding},
+		{acceptEncoding: []string{"gzip;q=0.5, snappy;q=0.8"}, expected: SnappyEncoding},
+		{acceptEncoding: []string{"gzip;q=0, snappy;q=0"}, expected: ""},
+		{acceptEncoding: []string{"gzip;q=invalid, snappy;q=0.1"}, expected: SnappyEncoding},
+	} {
+		assert.Equal(t, tc.expected, NegotiateEncoding(tc.acceptEncoding), "Accept-Encoding: %v", tc.acceptEncoding)
+	}
+}
+
+func TestResponseCompression(t *testing.T) {
+	body := strings.Repeat(`{"metric":{"__name__":"up"},"values":[[1,"1"]]}`, 100)
+
+	for name, tc := range map[string]struct {
+		acceptEncoding          string
+		contentType             string
+		contentEncoding         string
+		expectedContentEncoding string
+	}{
+		"gzip": {
+			acceptEncoding:          "gzip",
+			contentType:             "application/json",
+			expectedContentEncoding: GzipEncoding,
+		},
+		"snappy": {
+			acceptEncoding:          "snappy",
+			contentType:             "application/x-protobuf",
+			expectedContentEncoding: SnappyEncoding,
+		},
+		"no accepted encoding": {
+			contentType: "application/json",
+		},
+		"not compressible content type": {
+			acceptEncoding: "gzip",
+			contentType:    "text/plain",
+		},
+		"already encoded": {
+			acceptEncoding:          "gzip",
+			contentType:             "application/json",
+			contentEncoding:         "identity",
+			expectedContentEncoding: "identity",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			reg := prometheus.NewPedanticRegistry()
+			c := NewResponseCompression("cortex_test", reg)
+
+			handler := c.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The next handlers must not compress the response again.
+				if tc.expectedContentEncoding != "" && tc.contentEncoding == "" {
+					assert.Empty(t, r.Header.Get(acceptEncodingHeader))
+				}
+				w.Header().Set(contentTypeHeader, tc.contentType)
+				if tc.contentEncoding != "" {
+					w.Header().Set(contentEncodingHeader, tc.contentEncoding)
+				}
+				w.WriteHeader(http.StatusAccepted)
+				_, _ = w.Write([]byte(body))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/query_range", nil)
+			if tc.acceptEncoding != "" {
+				req.Header.Set(acceptEncodingHeader, tc.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+			assert.Equal(t, tc.contentType, rec.Header().Get(contentTypeHeader))
+			assert.Equal(t, tc.expectedContentEncoding, rec.Header().Get(contentEncodingHeader))
+
+			compressedSize := rec.Body.Len()
+			var reader io.Reader = rec.Body
+			switch tc.expectedContentEncoding {
+			case GzipEncoding:
+				gzipReader, err := gzip.NewReader(rec.Body)
+				require.NoError(t, err)
+				reader = gzipReader
+			case SnappyEncoding:
+				reader = snappy.NewReader(rec.Body)
+			}
+			decompressed, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, body, string(decompressed))
+
+			if tc.expectedContentEncoding == GzipEncoding || tc.expectedContentEncoding == SnappyEncoding {
+				assert.Less(t, compressedSize, len(body))
+				assert.Equal(t, float64(len(body)), testutil.ToFloat64(c.uncompressedBytes.WithLabelValues(tc.expectedContentEncoding)))
+				assert.Equal(t, float64(compressedSize), testutil.ToFloat64(c.compressedBytes.WithLabelValues(tc.expectedContentEncoding)))
+			} else {
+				assert.Equal(t, 0, testutil.CollectAndCount(c.uncompressedBytes))
+			}
+		})
+	}
+}
+
+func TestResponseCompression_ShouldFlushResponsesNotCompressed(t *testing.T) {
+	c := NewResponseCompression("cortex_test", prometheus.NewPedanticRegistry())
+
+	handler := c.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(contentTypeHeader, "application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse")
+		_, _ = w.Write([]byte("frame"))
+		w.(http.Flusher).Flush()
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/read", bytes.NewReader(nil))
+	req.Header.Set(acceptEncodingHeader, "snappy")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.True(t, rec.Flushed)
+	assert.Empty(t, rec.Header().Get(contentEncodingHeader))
+	assert.Equal(t, "frame", rec.Body.String())
+}
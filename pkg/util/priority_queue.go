@@ -124,3 +124,20 @@ func (pq *PriorityQueue) Peek() PriorityOp {
 	op := pq.queue[0]
 	return op
 }
+
+// Remove removes the op from the queue, and returns whether it was in the queue.
+func (pq *PriorityQueue) Remove(op PriorityOp) bool {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	for i := range pq.queue {
+		if pq.queue[i] == op {
+			heap.Remove(&pq.queue, i)
+			if pq.lengthGauge != nil {
+				pq.lengthGauge.Dec()
+			}
+			return true
+		}
+	}
+	return false
+}
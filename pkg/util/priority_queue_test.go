@@ -90,3 +90,19 @@ func TestPriorityQueueClose(t *testing.T) {
 	assert.Panics(t, func() { queue.Enqueue(simpleItem(2)) })
 	assert.Nil(t, queue.Dequeue())
 }
+
+func TestPriorityQueueRemove(t *testing.T) {
+	queue := NewPriorityQueue(nil)
+	queue.Enqueue(simpleItem(1))
+	queue.Enqueue(simpleItem(2))
+	queue.Enqueue(simpleItem(3))
+
+	assert.True(t, queue.Remove(simpleItem(2)), "Expected to remove simpleItem(2)")
+	assert.False(t, queue.Remove(simpleItem(4)), "Expected not to remove simpleItem(4)")
+	assert.Equal(t, 2, queue.Length(), "Expected length = 2")
+	assert.Equal(t, simpleItem(3), queue.Dequeue().(simpleItem), "Expected to dequeue simpleItem(3)")
+	assert.Equal(t, simpleItem(1), queue.Dequeue().(simpleItem), "Expected to dequeue simpleItem(1)")
+
+	queue.Close()
+	assert.Nil(t, queue.Dequeue(), "Expect nil dequeue")
+}
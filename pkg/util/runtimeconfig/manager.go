@@ -7,6 +7,8 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -40,12 +42,56 @@ type Config struct {
 
 // RegisterFlags registers flags.
 func (mc *Config) RegisterFlags(f *flag.FlagSet) {
-	f.StringVar(&mc.LoadPath, "runtime-config.file", "", "File with the configuration that can be updated in runtime.")
+	f.StringVar(&mc.LoadPath, "runtime-config.file", "", "File with the configuration that can be updated in runtime. It can also be an object storage URL like s3://<bucket>/<object>, gcs://<bucket>/<object>, azure://<container>/<object> or swift://<container>/<object>, in which case the storage backend and the bucket name are taken from the URL, while the rest of the storage backend configuration is taken from the -runtime-config.* flags.")
 	f.DurationVar(&mc.ReloadPeriod, "runtime-config.reload-period", 10*time.Second, "How often to check runtime config file.")
 
 	mc.StorageConfig.RegisterFlagsWithPrefixAndBackend("runtime-config.", f, bucket.Filesystem)
 }
 
+// urlSchemeBackends maps the schemes of the runtime config file URLs to the storage backends.
+var urlSchemeBackends = map[string]string{
+	"s3":    bucket.S3,
+	"gcs":   bucket.GCS,
+	"azure": bucket.Azure,
+	"swift": bucket.Swift,
+}
+
+// ResolveLoadPath configures the storage backend and its bucket name when the load path is an object
+// storage URL like s3://<bucket>/<object>, and replaces the load path with the object name. The load
+// paths which are not object storage URLs are left untouched.
+func (mc *Config) ResolveLoadPath() error {
+	u, err := url.Parse(mc.LoadPath)
+	if err != nil {
+		// Not an URL, e.g. a Windows path.
+		return nil
+	}
+
+	backend, ok := urlSchemeBackends[u.Scheme]
+	if !ok {
+		return nil
+	}
+
+	object := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || object == "" {
+		return fmt.Errorf("invalid runtime config file URL %q: the bucket and the object name are required", mc.LoadPath)
+	}
+
+	switch backend {
+	case bucket.S3:
+		mc.StorageConfig.S3.BucketName = u.Host
+	case bucket.GCS:
+		mc.StorageConfig.GCS.BucketName = u.Host
+	case bucket.Azure:
+		mc.StorageConfig.Azure.ContainerName = u.Host
+	case bucket.Swift:
+		mc.StorageConfig.Swift.ContainerName = u.Host
+	}
+
+	mc.StorageConfig.Backend = backend
+	mc.LoadPath = object
+	return nil
+}
+
 // Manager periodically reloads the configuration from a file, and keeps this
 // configuration available for clients.
 type Manager struct {
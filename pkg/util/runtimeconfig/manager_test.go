@@ -361,3 +361,82 @@ func createMockBucketClient(configs ...[]byte) *bucket.ClientMock {
 	}
 	return &bucketClient
 }
+
+func TestConfig_ResolveLoadPath(t *testing.T) {
+	tests := map[string]struct {
+		loadPath           string
+		expectedBackend    string
+		expectedLoadPath   string
+		bucketName         func(cfg bucket.Config) string
+		expectedBucketName string
+		expectedErrString  string
+	}{
+		"relative file path": {
+			loadPath:         "runtime.yaml",
+			expectedBackend:  bucket.Filesystem,
+			expectedLoadPath: "runtime.yaml",
+		},
+		"absolute file path": {
+			loadPath:         "/etc/cortex/runtime.yaml",
+			expectedBackend:  bucket.Filesystem,
+			expectedLoadPath: "/etc/cortex/runtime.yaml",
+		},
+		"S3 URL": {
+			loadPath:           "s3://my-bucket/cortex/runtime.yaml",
+			expectedBackend:    bucket.S3,
+			expectedLoadPath:   "cortex/runtime.yaml",
+			bucketName:         func(cfg bucket.Config) string { return cfg.S3.BucketName },
+			expectedBucketName: "my-bucket",
+		},
+		"GCS URL": {
+			loadPath:           "gcs://my-bucket/runtime.yaml",
+			expectedBackend:    bucket.GCS,
+			expectedLoadPath:   "runtime.yaml",
+			bucketName:         func(cfg bucket.Config) string { return cfg.GCS.BucketName },
+			expectedBucketName: "my-bucket",
+		},
+		"Azure URL": {
+			loadPath:           "azure://my-container/runtime.yaml",
+			expectedBackend:    bucket.Azure,
+			expectedLoadPath:   "runtime.yaml",
+			bucketName:         func(cfg bucket.Config) string { return cfg.Azure.ContainerName },
+			expectedBucketName: "my-container",
+		},
+		"Swift URL": {
+			loadPath:           "swift://my-container/runtime.yaml",
+			expectedBackend:    bucket.Swift,
+			expectedLoadPath:   "runtime.yaml",
+			bucketName:         func(cfg bucket.Config) string { return cfg.Swift.ContainerName },
+			expectedBucketName: "my-container",
+		},
+		"URL without object": {
+			loadPath:          "s3://my-bucket/",
+			expectedErrString: "the bucket and the object name are required",
+		},
+		"URL without bucket": {
+			loadPath:          "gcs:///runtime.yaml",
+			expectedErrString: "the bucket and the object name are required",
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			cfg := Config{LoadPath: testData.loadPath}
+			cfg.StorageConfig.Backend = bucket.Filesystem
+
+			err := cfg.ResolveLoadPath()
+			if testData.expectedErrString != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), testData.expectedErrString)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedBackend, cfg.StorageConfig.Backend)
+			assert.Equal(t, testData.expectedLoadPath, cfg.LoadPath)
+			if testData.bucketName != nil {
+				assert.Equal(t, testData.expectedBucketName, testData.bucketName(cfg.StorageConfig))
+			}
+		})
+	}
+}
@@ -1,42 +1,104 @@
 package validation
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// exportedLimit is a limit exposed by the OverridesExporter.
+type exportedLimit struct {
+	name  string
+	value func(l *Limits) float64
+}
+
+// exportedLimits are the limits exposed by the OverridesExporter. The durations are exported in seconds.
+var exportedLimits = []exportedLimit{
+	// Ingestion limits.
+	{"ingestion_rate", func(l *Limits) float64 { return l.IngestionRate }},
+	{"ingestion_burst_size", func(l *Limits) float64 { return float64(l.IngestionBurstSize) }},
+	{"ingestion_tenant_shard_size", func(l *Limits) float64 { return float64(l.IngestionTenantShardSize) }},
+	{"max_local_series_per_user", func(l *Limits) float64 { return float64(l.MaxLocalSeriesPerUser) }},
+	{"max_local_series_per_metric", func(l *Limits) float64 { return float64(l.MaxLocalSeriesPerMetric) }},
+	{"max_global_series_per_user", func(l *Limits) float64 { return float64(l.MaxGlobalSeriesPerUser) }},
+	{"max_global_series_per_metric", func(l *Limits) float64 { return float64(l.MaxGlobalSeriesPerMetric) }},
+	{"max_global_metadata_per_user", func(l *Limits) float64 { return float64(l.MaxGlobalMetricsWithMetadataPerUser) }},
+	{"max_global_metadata_per_metric", func(l *Limits) float64 { return float64(l.MaxGlobalMetadataPerMetric) }},
+	{"max_exemplars", func(l *Limits) float64 { return float64(l.MaxExemplars) }},
+
+	// Query limits.
+	{"max_fetched_chunks_per_query", func(l *Limits) float64 { return float64(l.MaxChunksPerQuery) }},
+	{"max_fetched_series_per_query", func(l *Limits) float64 { return float64(l.MaxFetchedSeriesPerQuery) }},
+	{"max_fetched_chunk_bytes_per_query", func(l *Limits) float64 { return float64(l.MaxFetchedChunkBytesPerQuery) }},
+	{"max_fetched_data_bytes_per_query", func(l *Limits) float64 { return float64(l.MaxFetchedDataBytesPerQuery) }},
+	{"max_samples_per_query", func(l *Limits) float64 { return float64(l.MaxSamplesPerQuery) }},
+	{"max_query_lookback", func(l *Limits) float64 { return time.Duration(l.MaxQueryLookback).Seconds() }},
+	{"max_query_length", func(l *Limits) float64 { return time.Duration(l.MaxQueryLength).Seconds() }},
+	{"max_query_parallelism", func(l *Limits) float64 { return float64(l.MaxQueryParallelism) }},
+	{"max_queriers_per_tenant", func(l *Limits) float64 { return l.MaxQueriersPerTenant }},
+	{"max_outstanding_requests_per_tenant", func(l *Limits) float64 { return float64(l.MaxOutstandingPerTenant) }},
+
+	// Ruler limits.
+	{"ruler_tenant_shard_size", func(l *Limits) float64 { return float64(l.RulerTenantShardSize) }},
+	{"ruler_max_rules_per_rule_group", func(l *Limits) float64 { return float64(l.RulerMaxRulesPerRuleGroup) }},
+	{"ruler_max_rule_groups_per_tenant", func(l *Limits) float64 { return float64(l.RulerMaxRuleGroupsPerTenant) }},
+
+	// Alertmanager limits.
+	{"alertmanager_notification_rate_limit", func(l *Limits) float64 { return l.NotificationRateLimit }},
+	{"alertmanager_max_config_size_bytes", func(l *Limits) float64 { return float64(l.AlertmanagerMaxConfigSizeBytes) }},
+	{"alertmanager_max_templates_count", func(l *Limits) float64 { return float64(l.AlertmanagerMaxTemplatesCount) }},
+	{"alertmanager_max_template_size_bytes", func(l *Limits) float64 { return float64(l.AlertmanagerMaxTemplateSizeBytes) }},
+	{"alertmanager_max_dispatcher_aggregation_groups", func(l *Limits) float64 { return float64(l.AlertmanagerMaxDispatcherAggregationGroups) }},
+	{"alertmanager_max_alerts_count", func(l *Limits) float64 { return float64(l.AlertmanagerMaxAlertsCount) }},
+	{"alertmanager_max_alerts_size_bytes", func(l *Limits) float64 { return float64(l.AlertmanagerMaxAlertsSizeBytes) }},
+}
+
 // OverridesExporter exposes per-tenant resource limit overrides as Prometheus metrics
 type OverridesExporter struct {
-	tenantLimits TenantLimits
-	description  *prometheus.Desc
+	defaultLimits       *Limits
+	tenantLimits        TenantLimits
+	description         *prometheus.Desc
+	defaultsDescription *prometheus.Desc
 }
 
 // NewOverridesExporter creates an OverridesExporter that reads updates to per-tenant
-// limits using the provided function.
-func NewOverridesExporter(tenantLimits TenantLimits) *OverridesExporter {
+// limits using the provided function. The default limits, applied to the tenants without
+// overrides, are exported too.
+func NewOverridesExporter(defaultLimits *Limits, tenantLimits TenantLimits) *OverridesExporter {
 	return &OverridesExporter{
-		tenantLimits: tenantLimits,
+		defaultLimits: defaultLimits,
+		tenantLimits:  tenantLimits,
 		description: prometheus.NewDesc(
 			"cortex_overrides",
 			"Resource limit overrides applied to tenants",
 			[]string{"limit_name", "user"},
 			nil,
 		),
+		defaultsDescription: prometheus.NewDesc(
+			"cortex_overrides_defaults",
+			"Resource limit defaults for tenants without overrides",
+			[]string{"limit_name"},
+			nil,
+		),
 	}
 }
 
 func (oe *OverridesExporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- oe.description
+	ch <- oe.defaultsDescription
 }
 
 func (oe *OverridesExporter) Collect(ch chan<- prometheus.Metric) {
+	if oe.defaultLimits != nil {
+		for _, limit := range exportedLimits {
+			ch <- prometheus.MustNewConstMetric(oe.defaultsDescription, prometheus.GaugeValue, limit.value(oe.defaultLimits), limit.name)
+		}
+	}
+
 	allLimits := oe.tenantLimits.AllByUserID()
 	for tenant, limits := range allLimits {
-		ch <- prometheus.MustNewConstMetric(oe.description, prometheus.GaugeValue, limits.IngestionRate, "ingestion_rate", tenant)
-		ch <- prometheus.MustNewConstMetric(oe.description, prometheus.GaugeValue, float64(limits.IngestionBurstSize), "ingestion_burst_size", tenant)
-
-		ch <- prometheus.MustNewConstMetric(oe.description, prometheus.GaugeValue, float64(limits.MaxLocalSeriesPerUser), "max_local_series_per_user", tenant)
-		ch <- prometheus.MustNewConstMetric(oe.description, prometheus.GaugeValue, float64(limits.MaxLocalSeriesPerMetric), "max_local_series_per_metric", tenant)
-		ch <- prometheus.MustNewConstMetric(oe.description, prometheus.GaugeValue, float64(limits.MaxGlobalSeriesPerUser), "max_global_series_per_user", tenant)
-		ch <- prometheus.MustNewConstMetric(oe.description, prometheus.GaugeValue, float64(limits.MaxGlobalSeriesPerMetric), "max_global_series_per_metric", tenant)
+		for _, limit := range exportedLimits {
+			ch <- prometheus.MustNewConstMetric(oe.description, prometheus.GaugeValue, limit.value(limits), limit.name, tenant)
+		}
 	}
 }
@@ -2,13 +2,17 @@ package validation
 
 import (
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestOverridesExporter_noConfig(t *testing.T) {
-	exporter := NewOverridesExporter(newMockTenantLimits(nil))
+	exporter := NewOverridesExporter(nil, newMockTenantLimits(nil))
 
 	// With no updated override configurations, there should be no override metrics
 	count := testutil.CollectAndCount(exporter, "cortex_overrides")
@@ -22,9 +26,58 @@ func TestOverridesExporter_withConfig(t *testing.T) {
 		},
 	}
 
-	exporter := NewOverridesExporter(newMockTenantLimits(tenantLimits))
+	exporter := NewOverridesExporter(nil, newMockTenantLimits(tenantLimits))
 
 	// There should be at least a few metrics generated by receiving an override configuration map
 	count := testutil.CollectAndCount(exporter, "cortex_overrides")
 	assert.Greater(t, count, 0)
 }
+
+func TestOverridesExporter_ShouldExportTheQueryRulerAndAlertmanagerLimits(t *testing.T) {
+	defaults := &Limits{
+		IngestionRate:               1000,
+		MaxQueryLength:              model.Duration(24 * time.Hour),
+		RulerMaxRuleGroupsPerTenant: 10,
+	}
+	tenantLimits := map[string]*Limits{
+		"tenant-a": {
+			IngestionRate:              2000,
+			MaxFetchedSeriesPerQuery:   100000,
+			MaxQueryLength:             model.Duration(7 * 24 * time.Hour),
+			RulerMaxRulesPerRuleGroup:  20,
+			AlertmanagerMaxAlertsCount: 500,
+		},
+	}
+
+	exporter := NewOverridesExporter(defaults, newMockTenantLimits(tenantLimits))
+
+	// The limits which aren't overridden are exported too, to compare the tenant usage with its effective limits.
+	assert.Equal(t, len(exportedLimits), testutil.CollectAndCount(exporter, "cortex_overrides"))
+	assert.Equal(t, len(exportedLimits), testutil.CollectAndCount(exporter, "cortex_overrides_defaults"))
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(exporter)
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			key := family.GetName()
+			for _, label := range metric.GetLabel() {
+				key += "," + label.GetValue()
+			}
+			values[key] = metric.GetGauge().GetValue()
+		}
+	}
+
+	assert.Equal(t, 2000.0, values["cortex_overrides,ingestion_rate,tenant-a"])
+	assert.Equal(t, 100000.0, values["cortex_overrides,max_fetched_series_per_query,tenant-a"])
+	assert.Equal(t, (7 * 24 * time.Hour).Seconds(), values["cortex_overrides,max_query_length,tenant-a"])
+	assert.Equal(t, 20.0, values["cortex_overrides,ruler_max_rules_per_rule_group,tenant-a"])
+	assert.Equal(t, 500.0, values["cortex_overrides,alertmanager_max_alerts_count,tenant-a"])
+
+	assert.Equal(t, 1000.0, values["cortex_overrides_defaults,ingestion_rate"])
+	assert.Equal(t, (24 * time.Hour).Seconds(), values["cortex_overrides_defaults,max_query_length"])
+	assert.Equal(t, 10.0, values["cortex_overrides_defaults,ruler_max_rule_groups_per_tenant"])
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"flag"
+	"fmt"
 	"math"
 	"regexp"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/prometheus/prometheus/promql/parser"
 	"github.com/segmentio/fasthash/fnv1a"
 	"golang.org/x/time/rate"
 
@@ -26,6 +28,7 @@ var errMaxGlobalSeriesPerUserValidation = errors.New("The ingester.max-global-se
 var errDuplicateQueryPriorities = errors.New("duplicate entry of priorities found. Make sure they are all unique, including the default priority")
 var errCompilingQueryPriorityRegex = errors.New("error compiling query priority regex")
 var errDuplicatePerLabelSetLimit = errors.New("duplicate per labelSet limits found. Make sure they are all unique")
+var errInvalidActiveSeriesCustomTracker = errors.New("invalid active series custom tracker")
 
 // Supported values for enum limits
 const (
@@ -56,9 +59,10 @@ type DisabledRuleGroup struct {
 type DisabledRuleGroups []DisabledRuleGroup
 
 type QueryPriority struct {
-	Enabled         bool          `yaml:"enabled" json:"enabled"`
-	DefaultPriority int64         `yaml:"default_priority" json:"default_priority"`
-	Priorities      []PriorityDef `yaml:"priorities" json:"priorities" doc:"nocli|description=List of priority definitions."`
+	Enabled           bool           `yaml:"enabled" json:"enabled"`
+	DefaultPriority   int64          `yaml:"default_priority" json:"default_priority"`
+	Priorities        []PriorityDef  `yaml:"priorities" json:"priorities" doc:"nocli|description=List of priority definitions."`
+	StarvationTimeout model.Duration `yaml:"starvation_timeout" json:"starvation_timeout"`
 }
 
 type PriorityDef struct {
@@ -75,6 +79,7 @@ type QueryRejection struct {
 type QueryAttribute struct {
 	ApiType                string         `yaml:"api_type" json:"api_type" doc:"nocli|description=API type for the query. Should be one of the query, query_range, series, labels, label_values. If not set, it won't be checked."`
 	Regex                  string         `yaml:"regex" json:"regex" doc:"nocli|description=Regex that the query string (or at least one of the matchers in metadata query) should match. If not set, it won't be checked."`
+	Query                  string         `yaml:"query" json:"query" doc:"nocli|description=Query string that the query should be equal to. Use it to match a single known query, without escaping it into a regex. If not set, it won't be checked. This property won't be applied to metadata queries."`
 	TimeWindow             TimeWindow     `yaml:"time_window" json:"time_window" doc:"nocli|description=Overall data select time window (including range selectors, modifiers and lookback delta) that the query should be within. If not set, it won't be checked."`
 	TimeRangeLimit         TimeRangeLimit `yaml:"time_range_limit" json:"time_range_limit" doc:"nocli|description=Query time range should be within this limit to match. Depending on where it was used, in most of the use-cases, either min or max value will be used. If not set, it won't be checked."`
 	QueryStepLimit         QueryStepLimit `yaml:"query_step_limit" json:"query_step_limit" doc:"nocli|description=If query step provided should be within this limit to match. If not set, it won't be checked. This property only applied to range queries and ignored for other types of queries."`
@@ -146,6 +151,8 @@ type Limits struct {
 	MaxGlobalSeriesPerUser   int                 `yaml:"max_global_series_per_user" json:"max_global_series_per_user"`
 	MaxGlobalSeriesPerMetric int                 `yaml:"max_global_series_per_metric" json:"max_global_series_per_metric"`
 	LimitsPerLabelSet        []LimitsPerLabelSet `yaml:"limits_per_label_set" json:"limits_per_label_set" doc:"nocli|description=[Experimental] Enable limits per LabelSet. Supported limits per labelSet: [max_series]"`
+	// Active series
+	ActiveSeriesCustomTrackers map[string]string `yaml:"active_series_custom_trackers" json:"active_series_custom_trackers" doc:"nocli|description=[Experimental] Additional custom trackers for active series, where each key is the name of the tracker and the value is a series selector (eg. '{job=\"api\"}'). The number of active series matching each selector is exported by the ingesters as the cortex_ingester_active_series_custom_tracker metric. Requires -ingester.active-series-metrics-enabled.|default={}"`
 
 	// Metadata
 	MaxLocalMetricsWithMetadataPerUser  int `yaml:"max_metadata_per_user" json:"max_metadata_per_user"`
@@ -154,6 +161,9 @@ type Limits struct {
 	MaxGlobalMetadataPerMetric          int `yaml:"max_global_metadata_per_metric" json:"max_global_metadata_per_metric"`
 	// Out-of-order
 	OutOfOrderTimeWindow model.Duration `yaml:"out_of_order_time_window" json:"out_of_order_time_window"`
+	// TSDB
+	TSDBBlockDuration   model.Duration `yaml:"tsdb_block_duration" json:"tsdb_block_duration"`
+	TSDBRetentionPeriod model.Duration `yaml:"tsdb_retention_period" json:"tsdb_retention_period"`
 	// Exemplars
 	MaxExemplars int `yaml:"max_exemplars" json:"max_exemplars"`
 
@@ -162,15 +172,32 @@ type Limits struct {
 	MaxFetchedSeriesPerQuery     int            `yaml:"max_fetched_series_per_query" json:"max_fetched_series_per_query"`
 	MaxFetchedChunkBytesPerQuery int            `yaml:"max_fetched_chunk_bytes_per_query" json:"max_fetched_chunk_bytes_per_query"`
 	MaxFetchedDataBytesPerQuery  int            `yaml:"max_fetched_data_bytes_per_query" json:"max_fetched_data_bytes_per_query"`
+	MaxSamplesPerQuery           int            `yaml:"max_samples_per_query" json:"max_samples_per_query"`
+	MaxEstimatedMemoryPerQuery   int            `yaml:"max_estimated_memory_bytes_per_query" json:"max_estimated_memory_bytes_per_query"`
+	QueryPartialResults          bool           `yaml:"query_partial_results" json:"query_partial_results"`
 	MaxQueryLookback             model.Duration `yaml:"max_query_lookback" json:"max_query_lookback"`
 	MaxQueryLength               model.Duration `yaml:"max_query_length" json:"max_query_length"`
+	ClampMaxQueryLength          bool           `yaml:"clamp_max_query_length" json:"clamp_max_query_length"`
+	MaxPointsPerSeries           int            `yaml:"max_points_per_series" json:"max_points_per_series"`
+	AdjustStepToMaxPoints        bool           `yaml:"adjust_step_to_max_points_per_series" json:"adjust_step_to_max_points_per_series"`
 	MaxQueryParallelism          int            `yaml:"max_query_parallelism" json:"max_query_parallelism"`
+	FederationAllowedTenants     []string       `yaml:"federation_allowed_tenants" json:"federation_allowed_tenants"`
 	MaxCacheFreshness            model.Duration `yaml:"max_cache_freshness" json:"max_cache_freshness"`
+	ResultsCacheTTL              model.Duration `yaml:"results_cache_ttl" json:"results_cache_ttl"`
+	MetadataCacheTTL             model.Duration `yaml:"metadata_cache_ttl" json:"metadata_cache_ttl"`
+	InstantQueryCacheTTL         model.Duration `yaml:"instant_query_cache_ttl" json:"instant_query_cache_ttl"`
+	UnalignedQueryCacheTTL       model.Duration `yaml:"unaligned_query_cache_ttl" json:"unaligned_query_cache_ttl"`
+	AlignQueriesWithStep         bool           `yaml:"align_queries_with_step" json:"align_queries_with_step"`
 	MaxQueriersPerTenant         float64        `yaml:"max_queriers_per_tenant" json:"max_queriers_per_tenant"`
 	QueryVerticalShardSize       int            `yaml:"query_vertical_shard_size" json:"query_vertical_shard_size" doc:"hidden"`
+	SplitInstantQueriesInterval  model.Duration `yaml:"split_instant_queries_by_interval" json:"split_instant_queries_by_interval"`
+	SplitQueriesByInterval       model.Duration `yaml:"split_queries_by_interval" json:"split_queries_by_interval"`
+	QueryRetryBudgetRatio        float64        `yaml:"query_retry_budget_ratio" json:"query_retry_budget_ratio"`
 
 	// Query Frontend / Scheduler enforced limits.
 	MaxOutstandingPerTenant     int           `yaml:"max_outstanding_requests_per_tenant" json:"max_outstanding_requests_per_tenant"`
+	MaxConcurrentPerTenant      int           `yaml:"max_concurrent_requests_per_tenant" json:"max_concurrent_requests_per_tenant"`
+	QueryQueueWeight            int           `yaml:"query_queue_weight" json:"query_queue_weight"`
 	QueryPriority               QueryPriority `yaml:"query_priority" json:"query_priority" doc:"nocli|description=Configuration for query priority."`
 	queryAttributeRegexHash     uint64
 	queryAttributeCompiledRegex map[string]*regexp.Regexp
@@ -184,12 +211,17 @@ type Limits struct {
 	RulerQueryOffset            model.Duration `yaml:"ruler_query_offset" json:"ruler_query_offset"`
 
 	// Store-gateway.
-	StoreGatewayTenantShardSize  float64 `yaml:"store_gateway_tenant_shard_size" json:"store_gateway_tenant_shard_size"`
-	MaxDownloadedBytesPerRequest int     `yaml:"max_downloaded_bytes_per_request" json:"max_downloaded_bytes_per_request"`
+	StoreGatewayTenantShardSize          float64        `yaml:"store_gateway_tenant_shard_size" json:"store_gateway_tenant_shard_size"`
+	MaxDownloadedBytesPerRequest         int            `yaml:"max_downloaded_bytes_per_request" json:"max_downloaded_bytes_per_request"`
+	MaxPostingsBytesPerRequest           int            `yaml:"max_postings_bytes_per_request" json:"max_postings_bytes_per_request"`
+	MaxConcurrentSeriesRequestsPerTenant int            `yaml:"max_concurrent_series_requests_per_tenant" json:"max_concurrent_series_requests_per_tenant"`
+	StoreGatewaySyncInterval             model.Duration `yaml:"store_gateway_sync_interval" json:"store_gateway_sync_interval"`
 
 	// Compactor.
 	CompactorBlocksRetentionPeriod model.Duration `yaml:"compactor_blocks_retention_period" json:"compactor_blocks_retention_period"`
 	CompactorTenantShardSize       int            `yaml:"compactor_tenant_shard_size" json:"compactor_tenant_shard_size"`
+	CompactorPartitionCount        int            `yaml:"compactor_partition_count" json:"compactor_partition_count"`
+	CompactorBlockUploadEnabled    bool           `yaml:"compactor_block_upload_enabled" json:"compactor_block_upload_enabled"`
 
 	// This config doesn't have a CLI flag registered here because they're registered in
 	// their own original config struct.
@@ -248,6 +280,8 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.MaxGlobalSeriesPerMetric, "ingester.max-global-series-per-metric", 0, "The maximum number of active series per metric name, across the cluster before replication. 0 to disable.")
 	f.IntVar(&l.MaxExemplars, "ingester.max-exemplars", 0, "Enables support for exemplars in TSDB and sets the maximum number that will be stored. less than zero means disabled. If the value is set to zero, cortex will fallback to blocks-storage.tsdb.max-exemplars value.")
 	f.Var(&l.OutOfOrderTimeWindow, "ingester.out-of-order-time-window", "[Experimental] Configures the allowed time window for ingestion of out-of-order samples. Disabled (0s) by default.")
+	f.Var(&l.TSDBBlockDuration, "ingester.tsdb-block-duration", "[Experimental] Per-tenant duration of the TSDB blocks cut by the ingester. It must be a divisor or a multiple of the first -blocks-storage.tsdb.block-ranges-period, otherwise it's ignored. When set, the blocks of the tenant are not compacted by the ingester. Applied when the tenant TSDB is opened. 0 to use -blocks-storage.tsdb.block-ranges-period.")
+	f.Var(&l.TSDBRetentionPeriod, "ingester.tsdb-retention-period", "[Experimental] Per-tenant retention of the TSDB blocks in the ingester. Values lower than the largest block range are clamped to it. Applied when the tenant TSDB is opened. 0 to use -blocks-storage.tsdb.retention-period.")
 
 	f.IntVar(&l.MaxLocalMetricsWithMetadataPerUser, "ingester.max-metadata-per-user", 8000, "The maximum number of active metrics with metadata per user, per ingester. 0 to disable.")
 	f.IntVar(&l.MaxLocalMetadataPerMetric, "ingester.max-metadata-per-metric", 10, "The maximum number of metadata per metric, per ingester. 0 to disable.")
@@ -257,18 +291,37 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.MaxFetchedSeriesPerQuery, "querier.max-fetched-series-per-query", 0, "The maximum number of unique series for which a query can fetch samples from each ingesters and blocks storage. This limit is enforced in the querier, ruler and store-gateway. 0 to disable")
 	f.IntVar(&l.MaxFetchedChunkBytesPerQuery, "querier.max-fetched-chunk-bytes-per-query", 0, "Deprecated (use max-fetched-data-bytes-per-query instead): The maximum size of all chunks in bytes that a query can fetch from each ingester and storage. This limit is enforced in the querier, ruler and store-gateway. 0 to disable.")
 	f.IntVar(&l.MaxFetchedDataBytesPerQuery, "querier.max-fetched-data-bytes-per-query", 0, "The maximum combined size of all data that a query can fetch from each ingester and storage. This limit is enforced in the querier and ruler for `query`, `query_range` and `series` APIs. 0 to disable.")
+	f.IntVar(&l.MaxSamplesPerQuery, "querier.max-samples-per-query", 0, "The maximum number of samples a single query can load from the storage into the query engine. The query is aborted as soon as it reaches the limit. Unlike -querier.max-samples, which limits the samples held in memory at the same time, this limits all the samples loaded over the query execution. This limit is enforced in the querier and ruler. 0 to disable.")
+	f.IntVar(&l.MaxEstimatedMemoryPerQuery, "querier.max-estimated-memory-bytes-per-query", 0, "The maximum estimated memory in bytes of the series and samples a single query can load from the storage into the query engine. The query is aborted as soon as it reaches the limit. This limit is enforced in the querier and ruler. 0 to disable.")
+	f.BoolVar(&l.QueryPartialResults, "querier.partial-results", false, "[Experimental] Return the partial results of the queries of the tenant when some ingesters or store-gateways fail, with warnings listing the time ranges and blocks which could not be queried, instead of failing the queries. The queries exceeding a limit still fail, and the partial results are not cached by the query-frontend.")
 	f.Var(&l.MaxQueryLength, "store.max-query-length", "Limit the query time range (end - start time of range query parameter and max - min of data fetched time range). This limit is enforced in the query-frontend and ruler (on the received query). 0 to disable.")
+	f.BoolVar(&l.ClampMaxQueryLength, "frontend.clamp-max-query-length", false, "Clamp the start time of the range queries and of the series, label names and label values requests of the tenant longer than -store.max-query-length to the max query length before their end time, instead of rejecting them. Only applies in the query-frontend.")
+	f.IntVar(&l.MaxPointsPerSeries, "frontend.max-points-per-series", 0, "Limit the number of points per series of the range queries, that is (end - start) / step + 1. The range queries exceeding the limit are rejected by the query-frontend. 0 to disable.")
+	f.BoolVar(&l.AdjustStepToMaxPoints, "frontend.adjust-step-to-max-points-per-series", false, "Increase the step of the range queries of the tenant exceeding -frontend.max-points-per-series to the smallest whole number of seconds within the limit, instead of rejecting them. Only applies in the query-frontend.")
 	f.Var(&l.MaxQueryLookback, "querier.max-query-lookback", "Limit how long back data (series and metadata) can be queried, up until <lookback> duration ago. This limit is enforced in the query-frontend, querier and ruler. If the requested time range is outside the allowed range, the request will not fail but will be manipulated to only query data within the allowed time range. 0 to disable.")
 	f.IntVar(&l.MaxQueryParallelism, "querier.max-query-parallelism", 14, "Maximum number of split queries will be scheduled in parallel by the frontend.")
+	f.Var((*flagext.StringSliceCSV)(&l.FederationAllowedTenants), "querier.federation-allowed-tenants", "Comma separated list of the tenants whose data can be queried together with the data of this tenant, when the tenant federation is enabled. Federated queries involving the tenant and a tenant not in the list are rejected. Empty to allow any tenant.")
 	_ = l.MaxCacheFreshness.Set("1m")
 	f.Var(&l.MaxCacheFreshness, "frontend.max-cache-freshness", "Most recent allowed cacheable result per-tenant, to prevent caching very recent results that might still be in flux.")
+	f.Var(&l.ResultsCacheTTL, "frontend.results-cache-ttl", "How long the query-frontend reuses cached results per-tenant, since the query which has fetched them. Older cached results are fetched again from the queriers. The TTL is only enforced when reading the cache: it doesn't change when the entries expire from the cache, which is still controlled by the cache backend configuration. 0 to reuse cached results until they expire from the cache.")
+	_ = l.MetadataCacheTTL.Set("1m")
+	f.Var(&l.MetadataCacheTTL, "frontend.metadata-cache-ttl", "How long the query-frontend caches the responses of the label names, label values and series APIs per-tenant, when -frontend.cache-metadata is enabled. 0 to not cache them.")
+	f.Var(&l.InstantQueryCacheTTL, "frontend.instant-query-cache-ttl", "[Experimental] How long the query-frontend caches the results of the instant queries per-tenant, when -querier.cache-results is enabled. The results are keyed by query and time bucket of this duration, so an instant query can return the result of the same query evaluated up to this duration earlier. 0 to not cache them.")
+	f.Var(&l.UnalignedQueryCacheTTL, "frontend.unaligned-query-cache-ttl", "[Experimental] How long the query-frontend caches the results of the range queries whose start and end are not aligned with their step per-tenant, when -querier.cache-results is enabled. The results are keyed by query, step, length and time bucket of this duration, instead of being merged into the results cache of the aligned queries. 0 to cache them like the aligned queries.")
+	f.BoolVar(&l.AlignQueriesWithStep, "frontend.align-queries-with-step", false, "Mutate incoming queries of the tenant to align their start and end with their step, to improve the cacheability of the query results. Defaults to true when -querier.align-querier-with-step is enabled.")
 	f.Float64Var(&l.MaxQueriersPerTenant, "frontend.max-queriers-per-tenant", 0, "Maximum number of queriers that can handle requests for a single tenant. If set to 0 or value higher than number of available queriers, *all* queriers will handle requests for the tenant. If the value is < 1, it will be treated as a percentage and the gets a percentage of the total queriers. Each frontend (or query-scheduler, if used) will select the same set of queriers for the same tenant (given that all queriers are connected to all frontends / query-schedulers). This option only works with queriers connecting to the query-frontend / query-scheduler, not when using downstream URL.")
 	f.IntVar(&l.QueryVerticalShardSize, "frontend.query-vertical-shard-size", 0, "[Experimental] Number of shards to use when distributing shardable PromQL queries.")
+	f.Var(&l.SplitInstantQueriesInterval, "frontend.split-instant-queries-by-interval", "[Experimental] Split instant queries selecting a range longer than this interval, like sum_over_time(metric[30d]), into queries over sub-ranges of this interval, executed in parallel and combined. Supported for sum_over_time, count_over_time, min_over_time and max_over_time, optionally wrapped by the sum, min or max aggregation respectively. 0 to disable.")
+	f.Var(&l.SplitQueriesByInterval, "frontend.split-queries-by-interval", "Split range queries of the tenant by this interval, instead of -querier.split-queries-by-interval. Longer intervals suit tenants with long retention and low resolution data, shorter ones high resolution data. It also determines the cache keys of the tenant's results. Only applies when -querier.split-queries-by-interval is enabled. 0 to use -querier.split-queries-by-interval.")
+	f.Float64Var(&l.QueryRetryBudgetRatio, "frontend.query-retry-budget-ratio", 0, "[Experimental] Maximum ratio of retries to requests the query-frontend dispatches to the queriers for the tenant, eg. 0.1 for 1 retry every 10 requests, to not overload the queriers with retries when many requests fail. Each request adds this ratio to the tenant's retry budget, up to 10 retries, and each retry spends one. Only applies when -querier.max-retries-per-request is greater than 1. 0 to not limit the retries.")
 	f.BoolVar(&l.QueryPriority.Enabled, "frontend.query-priority.enabled", false, "Whether queries are assigned with priorities.")
 	f.Int64Var(&l.QueryPriority.DefaultPriority, "frontend.query-priority.default-priority", 0, "Priority assigned to all queries by default. Must be a unique value. Use this as a baseline to make certain queries higher/lower priority.")
+	f.Var(&l.QueryPriority.StarvationTimeout, "frontend.query-priority.starvation-timeout", "Maximum time a query can wait in the queue while higher priority queries are dequeued ahead of it. Queries waiting for longer are dequeued first, regardless of their priority, but still not by the queriers reserved to higher priorities. 0 to disable.")
 	f.BoolVar(&l.QueryRejection.Enabled, "frontend.query-rejection.enabled", false, "Whether query rejection is enabled.")
 
 	f.IntVar(&l.MaxOutstandingPerTenant, "frontend.max-outstanding-requests-per-tenant", 100, "Maximum number of outstanding requests per tenant per request queue (either query frontend or query scheduler); requests beyond this error with HTTP 429.")
+	f.IntVar(&l.MaxConcurrentPerTenant, "frontend.max-concurrent-requests-per-tenant", 0, "Maximum number of requests per tenant per request queue (either query frontend or query scheduler) handled by the queriers at the same time; requests beyond this wait in the queue, up to -frontend.max-outstanding-requests-per-tenant. 0 to disable.")
+	f.IntVar(&l.QueryQueueWeight, "frontend.query-queue-weight", 1, "[Experimental] Weight of the tenant in the request queue (either query frontend or query scheduler). The queriers take turns between the tenants with queued requests, and handle up to this many requests of the tenant in a row, so that under contention the tenant gets a share of the querier capacity proportional to its weight. Values lower than 1 are treated as 1.")
 
 	f.Var(&l.RulerEvaluationDelay, "ruler.evaluation-delay-duration", "Deprecated(use ruler.query-offset instead) and will be removed in v1.19.0: Duration to delay the evaluation of rules to ensure the underlying metrics have been pushed to Cortex.")
 	f.IntVar(&l.RulerTenantShardSize, "ruler.tenant-shard-size", 0, "The default tenant's shard size when the shuffle-sharding strategy is used by ruler. When this setting is specified in the per-tenant overrides, a value of 0 disables shuffle sharding for the tenant.")
@@ -277,11 +330,16 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.Var(&l.RulerQueryOffset, "ruler.query-offset", "Duration to offset all rule evaluation queries per-tenant.")
 
 	f.Var(&l.CompactorBlocksRetentionPeriod, "compactor.blocks-retention-period", "Delete blocks containing samples older than the specified retention period. 0 to disable.")
+	f.BoolVar(&l.CompactorBlockUploadEnabled, "compactor.block-upload-enabled", false, "[Experimental] Enable the block upload API of the compactor for the tenant, to backfill the blocks of historical data created outside of Cortex.")
 	f.IntVar(&l.CompactorTenantShardSize, "compactor.tenant-shard-size", 0, "The default tenant's shard size when the shuffle-sharding strategy is used by the compactor. When this setting is specified in the per-tenant overrides, a value of 0 disables shuffle sharding for the tenant.")
+	f.IntVar(&l.CompactorPartitionCount, "compactor.partition-count", 1, "[Experimental] The number of partitions the series of the tenant are split into by the hash of their labels when the partitioning compaction mode is used. The blocks are first compacted into a block per partition, and the blocks of each partition are then compacted independently, so that they can be compacted in parallel. 1 to compact all the series into a single block.")
 
 	// Store-gateway.
 	f.Float64Var(&l.StoreGatewayTenantShardSize, "store-gateway.tenant-shard-size", 0, "The default tenant's shard size when the shuffle-sharding strategy is used. Must be set when the store-gateway sharding is enabled with the shuffle-sharding strategy. When this setting is specified in the per-tenant overrides, a value of 0 disables shuffle sharding for the tenant. If the value is < 1 the shard size will be a percentage of the total store-gateways.")
 	f.IntVar(&l.MaxDownloadedBytesPerRequest, "store-gateway.max-downloaded-bytes-per-request", 0, "The maximum number of data bytes to download per gRPC request in Store Gateway, including Series/LabelNames/LabelValues requests. 0 to disable.")
+	f.IntVar(&l.MaxPostingsBytesPerRequest, "store-gateway.max-postings-bytes-per-request", 0, "The maximum number of bytes of the postings touched per gRPC request in Store Gateway, including the postings fetched from cache or object storage. 0 to disable.")
+	f.IntVar(&l.MaxConcurrentSeriesRequestsPerTenant, "store-gateway.max-concurrent-series-requests-per-tenant", 0, "The maximum number of Series requests of the tenant executed concurrently by each Store Gateway. The requests above the limit wait for their turn, up to -blocks-storage.bucket-store.max-concurrent-queue-timeout. 0 to disable.")
+	f.Var(&l.StoreGatewaySyncInterval, "store-gateway.tenant-sync-interval", "[Experimental] Per-tenant interval at which the Store Gateway syncs the blocks of the tenant, in addition to the -blocks-storage.bucket-store.sync-interval. It's checked every minute, so only values shorter than -blocks-storage.bucket-store.sync-interval and longer than 1m are effective. 0 to disable.")
 
 	// Alertmanager.
 	f.Var(&l.AlertmanagerReceiversBlockCIDRNetworks, "alertmanager.receivers-firewall-block-cidr-networks", "Comma-separated list of network CIDRs to block in Alertmanager receiver integrations.")
@@ -324,6 +382,7 @@ func (l *Limits) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		*l = *defaultLimits
 		// Make copy of default limits. Otherwise unmarshalling would modify map in default limits.
 		l.copyNotificationIntegrationLimits(defaultLimits.NotificationRateLimitPerIntegration)
+		l.copyActiveSeriesCustomTrackers(defaultLimits.ActiveSeriesCustomTrackers)
 	}
 	type plain Limits
 	if err := unmarshal((*plain)(l)); err != nil {
@@ -338,6 +397,10 @@ func (l *Limits) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 
+	if err := l.validateActiveSeriesCustomTrackers(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -350,6 +413,7 @@ func (l *Limits) UnmarshalJSON(data []byte) error {
 		*l = *defaultLimits
 		// Make copy of default limits. Otherwise unmarshalling would modify map in default limits.
 		l.copyNotificationIntegrationLimits(defaultLimits.NotificationRateLimitPerIntegration)
+		l.copyActiveSeriesCustomTrackers(defaultLimits.ActiveSeriesCustomTrackers)
 	}
 
 	type plain Limits
@@ -368,6 +432,10 @@ func (l *Limits) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	if err := l.validateActiveSeriesCustomTrackers(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -387,6 +455,30 @@ func (l *Limits) calculateMaxSeriesPerLabelSetId() error {
 	return nil
 }
 
+func (l *Limits) validateActiveSeriesCustomTrackers() error {
+	for name, selector := range l.ActiveSeriesCustomTrackers {
+		if name == "" {
+			return fmt.Errorf("%w: the name must not be empty", errInvalidActiveSeriesCustomTracker)
+		}
+		if _, err := parser.ParseMetricSelector(selector); err != nil {
+			return fmt.Errorf("%w %s: %s", errInvalidActiveSeriesCustomTracker, name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (l *Limits) copyActiveSeriesCustomTrackers(defaults map[string]string) {
+	if defaults == nil {
+		return
+	}
+
+	l.ActiveSeriesCustomTrackers = make(map[string]string, len(defaults))
+	for k, v := range defaults {
+		l.ActiveSeriesCustomTrackers[k] = v
+	}
+}
+
 func (l *Limits) copyNotificationIntegrationLimits(defaults NotificationRateLimitMap) {
 	l.NotificationRateLimitPerIntegration = make(map[string]float64, len(defaults))
 	for k, v := range defaults {
@@ -645,6 +737,11 @@ func (o *Overrides) LimitsPerLabelSet(userID string) []LimitsPerLabelSet {
 	return o.GetOverridesForUser(userID).LimitsPerLabelSet
 }
 
+// ActiveSeriesCustomTrackers returns the custom trackers of the active series, by tracker name, for a given user.
+func (o *Overrides) ActiveSeriesCustomTrackers(userID string) map[string]string {
+	return o.GetOverridesForUser(userID).ActiveSeriesCustomTrackers
+}
+
 // MaxChunksPerQueryFromStore returns the maximum number of chunks allowed per query when fetching
 // chunks from the long-term storage.
 func (o *Overrides) MaxChunksPerQueryFromStore(userID string) int {
@@ -679,6 +776,23 @@ func (o *Overrides) MaxDownloadedBytesPerRequest(userID string) int {
 	return o.GetOverridesForUser(userID).MaxDownloadedBytesPerRequest
 }
 
+// MaxPostingsBytesPerRequest returns the maximum number of bytes of the postings touched for each gRPC request
+// in Store Gateway, including the postings fetched from cache or object storage.
+func (o *Overrides) MaxPostingsBytesPerRequest(userID string) int {
+	return o.GetOverridesForUser(userID).MaxPostingsBytesPerRequest
+}
+
+// MaxConcurrentSeriesRequestsPerTenant returns the maximum number of Series requests of the user executed
+// concurrently by each Store Gateway.
+func (o *Overrides) MaxConcurrentSeriesRequestsPerTenant(userID string) int {
+	return o.GetOverridesForUser(userID).MaxConcurrentSeriesRequestsPerTenant
+}
+
+// StoreGatewaySyncInterval returns the interval at which the Store Gateway syncs the blocks of the user.
+func (o *Overrides) StoreGatewaySyncInterval(userID string) time.Duration {
+	return time.Duration(o.GetOverridesForUser(userID).StoreGatewaySyncInterval)
+}
+
 // MaxQueryLookback returns the max lookback period of queries.
 func (o *Overrides) MaxQueryLookback(userID string) time.Duration {
 	return time.Duration(o.GetOverridesForUser(userID).MaxQueryLookback)
@@ -689,12 +803,67 @@ func (o *Overrides) MaxQueryLength(userID string) time.Duration {
 	return time.Duration(o.GetOverridesForUser(userID).MaxQueryLength)
 }
 
+// ClampMaxQueryLength returns whether the queries longer than the max query length are clamped instead of rejected.
+func (o *Overrides) ClampMaxQueryLength(userID string) bool {
+	return o.GetOverridesForUser(userID).ClampMaxQueryLength
+}
+
+// MaxPointsPerSeries returns the limit of the number of points per series of the range queries.
+func (o *Overrides) MaxPointsPerSeries(userID string) int {
+	return o.GetOverridesForUser(userID).MaxPointsPerSeries
+}
+
+// AdjustStepToMaxPoints returns whether the step of the range queries exceeding the max points per series is increased instead of rejected.
+func (o *Overrides) AdjustStepToMaxPoints(userID string) bool {
+	return o.GetOverridesForUser(userID).AdjustStepToMaxPoints
+}
+
 // MaxCacheFreshness returns the period after which results are cacheable,
 // to prevent caching of very recent results.
 func (o *Overrides) MaxCacheFreshness(userID string) time.Duration {
 	return time.Duration(o.GetOverridesForUser(userID).MaxCacheFreshness)
 }
 
+// ResultsCacheTTL returns how long cached results can be reused.
+func (o *Overrides) ResultsCacheTTL(userID string) time.Duration {
+	return time.Duration(o.GetOverridesForUser(userID).ResultsCacheTTL)
+}
+
+// MetadataCacheTTL returns how long the responses of the labels and series APIs can be cached.
+func (o *Overrides) MetadataCacheTTL(userID string) time.Duration {
+	return time.Duration(o.GetOverridesForUser(userID).MetadataCacheTTL)
+}
+
+// InstantQueryCacheTTL returns how long the results of the instant queries can be cached.
+func (o *Overrides) InstantQueryCacheTTL(userID string) time.Duration {
+	return time.Duration(o.GetOverridesForUser(userID).InstantQueryCacheTTL)
+}
+
+// UnalignedQueryCacheTTL returns how long the results of the range queries not aligned with their step can be cached.
+func (o *Overrides) UnalignedQueryCacheTTL(userID string) time.Duration {
+	return time.Duration(o.GetOverridesForUser(userID).UnalignedQueryCacheTTL)
+}
+
+// AlignQueriesWithStep returns whether the start and end of the queries are aligned with their step.
+func (o *Overrides) AlignQueriesWithStep(userID string) bool {
+	return o.GetOverridesForUser(userID).AlignQueriesWithStep
+}
+
+// MaxSamplesPerQuery returns the maximum number of samples a query can load into the query engine.
+func (o *Overrides) MaxSamplesPerQuery(userID string) int {
+	return o.GetOverridesForUser(userID).MaxSamplesPerQuery
+}
+
+// QueryPartialResults returns whether the queries return partial results when some ingesters or store-gateways fail.
+func (o *Overrides) QueryPartialResults(userID string) bool {
+	return o.GetOverridesForUser(userID).QueryPartialResults
+}
+
+// MaxEstimatedMemoryPerQuery returns the maximum estimated memory in bytes a query can load into the query engine.
+func (o *Overrides) MaxEstimatedMemoryPerQuery(userID string) int {
+	return o.GetOverridesForUser(userID).MaxEstimatedMemoryPerQuery
+}
+
 // MaxQueriersPerUser returns the maximum number of queriers that can handle requests for this user.
 func (o *Overrides) MaxQueriersPerUser(userID string) float64 {
 	return o.GetOverridesForUser(userID).MaxQueriersPerTenant
@@ -705,18 +874,49 @@ func (o *Overrides) QueryVerticalShardSize(userID string) int {
 	return o.GetOverridesForUser(userID).QueryVerticalShardSize
 }
 
+// SplitInstantQueriesByInterval returns the interval to split instant queries with a long range selector by.
+func (o *Overrides) SplitInstantQueriesByInterval(userID string) time.Duration {
+	return time.Duration(o.GetOverridesForUser(userID).SplitInstantQueriesInterval)
+}
+
+// SplitQueriesByInterval returns the interval to split range queries by, overriding -querier.split-queries-by-interval.
+func (o *Overrides) SplitQueriesByInterval(userID string) time.Duration {
+	return time.Duration(o.GetOverridesForUser(userID).SplitQueriesByInterval)
+}
+
+// QueryRetryBudgetRatio returns the maximum ratio of retries to requests the query-frontend dispatches to the queriers.
+func (o *Overrides) QueryRetryBudgetRatio(userID string) float64 {
+	return o.GetOverridesForUser(userID).QueryRetryBudgetRatio
+}
+
 // MaxQueryParallelism returns the limit to the number of split queries the
 // frontend will process in parallel.
 func (o *Overrides) MaxQueryParallelism(userID string) int {
 	return o.GetOverridesForUser(userID).MaxQueryParallelism
 }
 
+// FederationAllowedTenants returns the tenants whose data can be queried together with the data of the tenant.
+func (o *Overrides) FederationAllowedTenants(userID string) []string {
+	return o.GetOverridesForUser(userID).FederationAllowedTenants
+}
+
 // MaxOutstandingPerTenant returns the limit to the maximum number
 // of outstanding requests per tenant per request queue.
 func (o *Overrides) MaxOutstandingPerTenant(userID string) int {
 	return o.GetOverridesForUser(userID).MaxOutstandingPerTenant
 }
 
+// MaxConcurrentPerTenant returns the limit to the maximum number of requests
+// per tenant per request queue handled by the queriers at the same time.
+func (o *Overrides) MaxConcurrentPerTenant(userID string) int {
+	return o.GetOverridesForUser(userID).MaxConcurrentPerTenant
+}
+
+// QueryQueueWeight returns the number of requests of the tenant the queriers handle in a row from the request queue.
+func (o *Overrides) QueryQueueWeight(userID string) int {
+	return o.GetOverridesForUser(userID).QueryQueueWeight
+}
+
 // QueryPriority returns the query priority config for the tenant, including different priorities and their attributes
 func (o *Overrides) QueryPriority(userID string) QueryPriority {
 	return o.GetOverridesForUser(userID).QueryPriority
@@ -783,6 +983,16 @@ func (o *Overrides) CompactorTenantShardSize(userID string) int {
 	return o.GetOverridesForUser(userID).CompactorTenantShardSize
 }
 
+// CompactorPartitionCount returns the number of partitions the series of this tenant are split into when using the partitioning compaction mode.
+func (o *Overrides) CompactorPartitionCount(userID string) int {
+	return o.GetOverridesForUser(userID).CompactorPartitionCount
+}
+
+// CompactorBlockUploadEnabled returns whether the block upload API is enabled for this tenant.
+func (o *Overrides) CompactorBlockUploadEnabled(userID string) bool {
+	return o.GetOverridesForUser(userID).CompactorBlockUploadEnabled
+}
+
 // MetricRelabelConfigs returns the metric relabel configs for a given user.
 func (o *Overrides) MetricRelabelConfigs(userID string) []*relabel.Config {
 	return o.GetOverridesForUser(userID).MetricRelabelConfigs
@@ -851,6 +1061,18 @@ func (o *Overrides) AlertmanagerReceiversBlockPrivateAddresses(user string) bool
 	return o.GetOverridesForUser(user).AlertmanagerReceiversBlockPrivateAddresses
 }
 
+// TSDBBlockDuration returns the duration of the TSDB blocks cut by the ingester for a given user.
+// 0 means the ingester's configured block range is used.
+func (o *Overrides) TSDBBlockDuration(userID string) model.Duration {
+	return o.GetOverridesForUser(userID).TSDBBlockDuration
+}
+
+// TSDBRetentionPeriod returns the retention of the TSDB blocks in the ingester for a given user.
+// 0 means the ingester's configured retention is used.
+func (o *Overrides) TSDBRetentionPeriod(userID string) model.Duration {
+	return o.GetOverridesForUser(userID).TSDBRetentionPeriod
+}
+
 // MaxExemplars gets the maximum number of exemplars that will be stored per user. 0 or less means disabled.
 func (o *Overrides) MaxExemplars(userID string) int {
 	return o.GetOverridesForUser(userID).MaxExemplars
@@ -1011,6 +1233,17 @@ func SmallestPositiveNonZeroDurationPerTenant(tenantIDs []string, f func(string)
 	return *result
 }
 
+// AllTrueBooleansPerTenant returns true only if the supplied limit function is
+// true for all given tenants. Without tenants given it will return false.
+func AllTrueBooleansPerTenant(tenantIDs []string, f func(string) bool) bool {
+	for _, tenantID := range tenantIDs {
+		if !f(tenantID) {
+			return false
+		}
+	}
+	return len(tenantIDs) > 0
+}
+
 // MaxDurationPerTenant is returning the maximum duration per tenant. Without
 // tenants given it will return a time.Duration(0).
 func MaxDurationPerTenant(tenantIDs []string, f func(string) time.Duration) time.Duration {
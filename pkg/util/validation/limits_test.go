@@ -205,6 +205,33 @@ limits_per_label_set:
 	require.Equal(t, err, errDuplicatePerLabelSetLimit)
 }
 
+func TestOverrides_ActiveSeriesCustomTrackers(t *testing.T) {
+	inputYAML := `
+active_series_custom_trackers:
+  team_a: '{job="api"}'
+  team_b: 'up{job=~"db.*"}'
+`
+
+	limitsYAML := Limits{}
+	require.NoError(t, yaml.Unmarshal([]byte(inputYAML), &limitsYAML))
+	require.Equal(t, map[string]string{"team_a": `{job="api"}`, "team_b": `up{job=~"db.*"}`}, limitsYAML.ActiveSeriesCustomTrackers)
+
+	overrides, err := NewOverrides(limitsYAML, nil)
+	require.NoError(t, err)
+	require.Equal(t, limitsYAML.ActiveSeriesCustomTrackers, overrides.ActiveSeriesCustomTrackers("user-1"))
+
+	invalidInputYAML := `
+active_series_custom_trackers:
+  team_a: '{job="api"'
+`
+	err = yaml.Unmarshal([]byte(invalidInputYAML), &Limits{})
+	require.ErrorIs(t, err, errInvalidActiveSeriesCustomTracker)
+
+	invalidInputJSON := `{"active_series_custom_trackers": {"team_a": "{job=~\"[\"}"}}`
+	err = json.Unmarshal([]byte(invalidInputJSON), &Limits{})
+	require.ErrorIs(t, err, errInvalidActiveSeriesCustomTracker)
+}
+
 func TestLimitsStringDurationYamlMatchJson(t *testing.T) {
 	inputYAML := `
 max_query_lookback: 1s
@@ -358,6 +385,36 @@ func TestSmallestPositiveNonZeroDurationPerTenant(t *testing.T) {
 	}
 }
 
+func TestAllTrueBooleansPerTenant(t *testing.T) {
+	tenantLimits := map[string]*Limits{
+		"tenant-a": {
+			ClampMaxQueryLength: true,
+		},
+		"tenant-b": {
+			ClampMaxQueryLength: true,
+		},
+	}
+
+	defaults := Limits{
+		ClampMaxQueryLength: false,
+	}
+	ov, err := NewOverrides(defaults, newMockTenantLimits(tenantLimits))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		tenantIDs []string
+		expLimit  bool
+	}{
+		{tenantIDs: []string{}, expLimit: false},
+		{tenantIDs: []string{"tenant-a"}, expLimit: true},
+		{tenantIDs: []string{"tenant-c"}, expLimit: false},
+		{tenantIDs: []string{"tenant-a", "tenant-b"}, expLimit: true},
+		{tenantIDs: []string{"tenant-a", "tenant-b", "tenant-c"}, expLimit: false},
+	} {
+		assert.Equal(t, tc.expLimit, AllTrueBooleansPerTenant(tc.tenantIDs, ov.ClampMaxQueryLength))
+	}
+}
+
 func TestAlertmanagerNotificationLimits(t *testing.T) {
 	for name, tc := range map[string]struct {
 		inputYAML         string
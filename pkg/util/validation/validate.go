@@ -36,6 +36,9 @@ const (
 	// ErrQueryTooLong is used in chunk store, querier and query frontend.
 	ErrQueryTooLong = "the query time range exceeds the limit (query length: %s, limit: %s)"
 
+	// ErrTooManyPointsPerSeries is used in query frontend.
+	ErrTooManyPointsPerSeries = "the query resolution exceeds the limit of points per series, try increasing the step (points: %d, limit: %d)"
+
 	missingMetricName       = "missing_metric_name"
 	invalidMetricName       = "metric_name_invalid"
 	greaterThanMaxSampleAge = "greater_than_max_sample_age"
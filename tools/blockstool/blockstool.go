@@ -0,0 +1,239 @@
+package blockstool
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+)
+
+// BlocksTool runs the operator commands inspecting and marking the blocks in a Cortex bucket.
+type BlocksTool struct {
+	logger log.Logger
+	bkt    objstore.InstrumentedBucket
+
+	// The marking functions require the counters of the marked blocks, which are not exposed by the tool.
+	blocksMarkedForDeletion     prometheus.Counter
+	blocksMarkedForNoCompaction prometheus.Counter
+}
+
+// BlockInfo describes a block of a tenant.
+type BlockInfo struct {
+	ID              ulid.ULID
+	MinTime         int64
+	MaxTime         int64
+	CompactionLevel int
+	NumSeries       uint64
+	NumSamples      uint64
+	SizeBytes       int64
+
+	// DeletionTime is the time the block has been marked for deletion, zero if it's not marked.
+	DeletionTime time.Time
+	NoCompact    bool
+}
+
+// TenantUsage is the storage usage of a tenant.
+type TenantUsage struct {
+	NumBlocks            int
+	NumBlocksForDeletion int
+	NumSeries            uint64
+	NumSamples           uint64
+	SizeBytes            int64
+}
+
+// NewBlocksTool creates a BlocksTool running against the bucket of the given config.
+func NewBlocksTool(ctx context.Context, cfg bucket.Config, logger log.Logger) (*BlocksTool, error) {
+	bkt, err := bucket.NewClient(ctx, cfg, "blockstool", logger, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBlocksTool(bkt, logger), nil
+}
+
+func newBlocksTool(bkt objstore.InstrumentedBucket, logger log.Logger) *BlocksTool {
+	return &BlocksTool{
+		// The blocks are marked both in the block location and in the global markers location,
+		// like the compactor does.
+		bkt:                         bucketindex.BucketWithGlobalMarkers(bkt),
+		logger:                      logger,
+		blocksMarkedForDeletion:     prometheus.NewCounter(prometheus.CounterOpts{Name: "blocks_marked_for_deletion_total"}),
+		blocksMarkedForNoCompaction: prometheus.NewCounter(prometheus.CounterOpts{Name: "blocks_marked_for_no_compaction_total"}),
+	}
+}
+
+// ListBlocks returns the blocks of the tenant, sorted by min time. Partial blocks, missing
+// the meta.json, are skipped.
+func (t *BlocksTool) ListBlocks(ctx context.Context, userID string) ([]BlockInfo, error) {
+	userBkt := t.userBucket(userID)
+
+	var blocks []BlockInfo
+	err := userBkt.Iter(ctx, "", func(name string) error {
+		blockID, ok := block.IsBlockDir(name)
+		if !ok {
+			return nil
+		}
+
+		meta, err := block.DownloadMeta(ctx, t.logger, userBkt, blockID)
+		if userBkt.IsObjNotFoundErr(errors.Cause(err)) {
+			level.Warn(t.logger).Log("msg", "skipped partial block", "user", userID, "block", blockID)
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "read meta.json of block %s", blockID)
+		}
+
+		info := BlockInfo{
+			ID:              blockID,
+			MinTime:         meta.MinTime,
+			MaxTime:         meta.MaxTime,
+			CompactionLevel: meta.Compaction.Level,
+			NumSeries:       meta.Stats.NumSeries,
+			NumSamples:      meta.Stats.NumSamples,
+		}
+		for _, f := range meta.Thanos.Files {
+			info.SizeBytes += f.SizeBytes
+		}
+
+		if info.DeletionTime, err = t.readDeletionTime(ctx, userBkt, blockID); err != nil {
+			return err
+		}
+		if info.NoCompact, err = userBkt.Exists(ctx, path.Join(blockID.String(), metadata.NoCompactMarkFilename)); err != nil {
+			return errors.Wrapf(err, "check no-compact mark of block %s", blockID)
+		}
+
+		blocks = append(blocks, info)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].MinTime != blocks[j].MinTime {
+			return blocks[i].MinTime < blocks[j].MinTime
+		}
+		return blocks[i].ID.Compare(blocks[j].ID) < 0
+	})
+	return blocks, nil
+}
+
+// CheckIndex downloads the index of the block to a temporary directory within the given
+// directory, and verifies its integrity.
+func (t *BlocksTool) CheckIndex(ctx context.Context, userID string, blockID ulid.ULID, dir string) error {
+	userBkt := t.userBucket(userID)
+
+	meta, err := block.DownloadMeta(ctx, t.logger, userBkt, blockID)
+	if err != nil {
+		return errors.Wrap(err, "read meta.json")
+	}
+
+	tmpDir, err := os.MkdirTemp(dir, "blockstool-"+blockID.String())
+	if err != nil {
+		return errors.Wrap(err, "create temporary directory")
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	indexPath := filepath.Join(tmpDir, block.IndexFilename)
+	if err := objstore.DownloadFile(ctx, t.logger, userBkt, path.Join(blockID.String(), block.IndexFilename), indexPath); err != nil {
+		return errors.Wrap(err, "download index")
+	}
+
+	return block.VerifyIndex(ctx, t.logger, indexPath, meta.MinTime, meta.MaxTime)
+}
+
+// MarkForDeletion marks the block of the tenant for deletion. The block is deleted by the
+// compactor once the deletion delay has elapsed.
+func (t *BlocksTool) MarkForDeletion(ctx context.Context, userID string, blockID ulid.ULID, details string) error {
+	userBkt := t.userBucket(userID)
+	if err := t.checkBlockExists(ctx, userBkt, blockID); err != nil {
+		return err
+	}
+
+	return block.MarkForDeletion(ctx, t.logger, userBkt, blockID, details, t.blocksMarkedForDeletion)
+}
+
+// MarkForNoCompact marks the block of the tenant for no compaction.
+func (t *BlocksTool) MarkForNoCompact(ctx context.Context, userID string, blockID ulid.ULID, details string) error {
+	userBkt := t.userBucket(userID)
+	if err := t.checkBlockExists(ctx, userBkt, blockID); err != nil {
+		return err
+	}
+
+	return block.MarkForNoCompact(ctx, t.logger, userBkt, blockID, metadata.ManualNoCompactReason, details, t.blocksMarkedForNoCompaction)
+}
+
+// Usage returns the storage usage of the given tenants, or of all the tenants in the bucket if
+// no tenant is given.
+func (t *BlocksTool) Usage(ctx context.Context, userIDs []string) (map[string]TenantUsage, error) {
+	if len(userIDs) == 0 {
+		var err error
+		if userIDs, _, err = cortex_tsdb.NewUsersScanner(t.bkt, cortex_tsdb.AllUsers, t.logger).ScanUsers(ctx); err != nil {
+			return nil, errors.Wrap(err, "scan users")
+		}
+	}
+
+	usage := make(map[string]TenantUsage, len(userIDs))
+	for _, userID := range userIDs {
+		blocks, err := t.ListBlocks(ctx, userID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "list blocks of user %s", userID)
+		}
+
+		u := TenantUsage{}
+		for _, b := range blocks {
+			u.NumBlocks++
+			u.NumSeries += b.NumSeries
+			u.NumSamples += b.NumSamples
+			u.SizeBytes += b.SizeBytes
+			if !b.DeletionTime.IsZero() {
+				u.NumBlocksForDeletion++
+			}
+		}
+		usage[userID] = u
+	}
+	return usage, nil
+}
+
+func (t *BlocksTool) userBucket(userID string) objstore.InstrumentedBucket {
+	// No per-tenant config provider because the tool doesn't support it.
+	return bucket.NewUserBucketClient(userID, t.bkt, nil)
+}
+
+func (t *BlocksTool) checkBlockExists(ctx context.Context, userBkt objstore.Bucket, blockID ulid.ULID) error {
+	exists, err := userBkt.Exists(ctx, path.Join(blockID.String(), metadata.MetaFilename))
+	if err != nil {
+		return errors.Wrapf(err, "check meta.json of block %s", blockID)
+	}
+	if !exists {
+		return errors.Errorf("block %s not found", blockID)
+	}
+	return nil
+}
+
+func (t *BlocksTool) readDeletionTime(ctx context.Context, userBkt objstore.InstrumentedBucket, blockID ulid.ULID) (time.Time, error) {
+	mark := metadata.DeletionMark{}
+	err := metadata.ReadMarker(ctx, t.logger, userBkt, blockID.String(), &mark)
+	if errors.Is(err, metadata.ErrorMarkerNotFound) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "read deletion mark of block %s", blockID)
+	}
+	return time.Unix(mark.DeletionTime, 0), nil
+}
@@ -0,0 +1,122 @@
+package blockstool
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	cortex_testutil "github.com/cortexproject/cortex/pkg/storage/tsdb/testutil"
+)
+
+func TestBlocksTool(t *testing.T) {
+	ctx := context.Background()
+	bkt, _ := cortex_testutil.PrepareFilesystemBucket(t)
+	tool := newBlocksTool(bkt, log.NewNopLogger())
+
+	block1 := createBlock(t, bkt, "user-1", 0, 10)
+	block2 := createBlock(t, bkt, "user-1", 10, 20)
+	block3 := createBlock(t, bkt, "user-2", 0, 10)
+
+	// A partial block, without the meta.json, is skipped.
+	partial := ulid.MustNew(100, nil)
+	require.NoError(t, bkt.Upload(ctx, path.Join("user-1", partial.String(), block.IndexFilename), strings.NewReader("index")))
+
+	t.Run("list blocks", func(t *testing.T) {
+		blocks, err := tool.ListBlocks(ctx, "user-1")
+		require.NoError(t, err)
+		require.Len(t, blocks, 2)
+		assert.Equal(t, block1, blocks[0].ID)
+		assert.Equal(t, block2, blocks[1].ID)
+		assert.Equal(t, int64(10), blocks[1].MinTime)
+		assert.Equal(t, uint64(1), blocks[0].NumSeries)
+		assert.Equal(t, uint64(10), blocks[0].NumSamples)
+		assert.Greater(t, blocks[0].SizeBytes, int64(0))
+		assert.True(t, blocks[0].DeletionTime.IsZero())
+		assert.False(t, blocks[0].NoCompact)
+	})
+
+	t.Run("check index", func(t *testing.T) {
+		require.NoError(t, tool.CheckIndex(ctx, "user-1", block1, t.TempDir()))
+
+		// Corrupt the index of the block.
+		require.NoError(t, bkt.Upload(ctx, path.Join("user-2", block3.String(), block.IndexFilename), strings.NewReader("corrupted")))
+		require.Error(t, tool.CheckIndex(ctx, "user-2", block3, t.TempDir()))
+	})
+
+	t.Run("mark blocks", func(t *testing.T) {
+		require.NoError(t, tool.MarkForDeletion(ctx, "user-1", block1, "test"))
+		require.NoError(t, tool.MarkForNoCompact(ctx, "user-1", block2, "test"))
+		require.Error(t, tool.MarkForDeletion(ctx, "user-1", ulid.MustNew(200, nil), "test"))
+
+		// The marks are also uploaded to the global markers location.
+		for _, name := range []string{
+			bucketindex.BlockDeletionMarkFilepath(block1),
+			bucketindex.NoCompactMarkFilenameMarkFilepath(block2),
+		} {
+			exists, err := bkt.Exists(ctx, path.Join("user-1", name))
+			require.NoError(t, err)
+			assert.True(t, exists, name)
+		}
+
+		blocks, err := tool.ListBlocks(ctx, "user-1")
+		require.NoError(t, err)
+		require.Len(t, blocks, 2)
+		assert.False(t, blocks[0].DeletionTime.IsZero())
+		assert.False(t, blocks[0].NoCompact)
+		assert.True(t, blocks[1].DeletionTime.IsZero())
+		assert.True(t, blocks[1].NoCompact)
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		usage, err := tool.Usage(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, usage, 2)
+		assert.Equal(t, 2, usage["user-1"].NumBlocks)
+		assert.Equal(t, 1, usage["user-1"].NumBlocksForDeletion)
+		assert.Equal(t, uint64(20), usage["user-1"].NumSamples)
+		assert.Equal(t, 1, usage["user-2"].NumBlocks)
+
+		usage, err = tool.Usage(ctx, []string{"user-2"})
+		require.NoError(t, err)
+		assert.Len(t, usage, 1)
+		assert.Contains(t, usage, "user-2")
+	})
+}
+
+func createBlock(t *testing.T, bkt objstore.Bucket, userID string, minT, maxT int64) ulid.ULID {
+	series := []storage.Series{
+		storage.NewListSeries(labels.FromStrings("series", "1"), chunks.GenerateSamples(int(minT), int(maxT-minT))),
+	}
+
+	dir, err := tsdb.CreateBlock(series, t.TempDir(), 0, log.NewNopLogger())
+	require.NoError(t, err)
+
+	_, err = metadata.InjectThanos(log.NewNopLogger(), dir, metadata.Thanos{
+		Labels: map[string]string{cortex_tsdb.TenantIDExternalLabel: userID},
+		Source: metadata.TestSource,
+	}, nil)
+	require.NoError(t, err)
+	require.NoError(t, block.Upload(context.Background(), log.NewNopLogger(), bucket.NewUserBucketClient(userID, bkt, nil), dir, metadata.NoneFunc))
+
+	blockID, err := ulid.Parse(path.Base(dir))
+	require.NoError(t, err)
+	require.NoError(t, os.RemoveAll(dir))
+	return blockID
+}
@@ -0,0 +1,68 @@
+package blockstool
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+)
+
+// MigrateLayout copies all the objects of the source bucket to the target bucket, which is
+// expected to be configured with a different storage prefix or tenant layout. The objects
+// already existing in the target bucket are skipped, so that an interrupted migration can be
+// resumed, and the objects of the source bucket are not deleted. The tenantLayoutDepth is the
+// number of path segments before the tenant ID in the object keys. It returns the number of
+// copied objects.
+func MigrateLayout(ctx context.Context, src, dst objstore.Bucket, tenantLayoutDepth int, logger log.Logger) (int, error) {
+	// The objects are listed before being copied, because the source and target buckets
+	// may overlap.
+	var names []string
+	err := src.Iter(ctx, "", func(name string) error {
+		// When migrating to the hashed layout within the same storage prefix, the objects
+		// already migrated are listed by the source bucket too.
+		parts := strings.Split(name, objstore.DirDelim)
+		if len(parts) > tenantLayoutDepth+2 && bucket.IsTenantHashPrefix(parts[tenantLayoutDepth]) {
+			return nil
+		}
+
+		names = append(names, name)
+		return nil
+	}, objstore.WithRecursiveIter)
+	if err != nil {
+		return 0, errors.Wrap(err, "list objects")
+	}
+
+	copied := 0
+	for _, name := range names {
+		exists, err := dst.Exists(ctx, name)
+		if err != nil {
+			return copied, errors.Wrapf(err, "check object %s", name)
+		}
+		if exists {
+			continue
+		}
+
+		if err := copyObject(ctx, src, dst, name); err != nil {
+			return copied, err
+		}
+		copied++
+		level.Debug(logger).Log("msg", "copied object", "object", name)
+	}
+
+	return copied, nil
+}
+
+func copyObject(ctx context.Context, src, dst objstore.Bucket, name string) error {
+	r, err := src.Get(ctx, name)
+	if err != nil {
+		return errors.Wrapf(err, "read object %s", name)
+	}
+	defer r.Close() //nolint:errcheck
+
+	return errors.Wrapf(dst.Upload(ctx, name, r), "upload object %s", name)
+}
@@ -0,0 +1,48 @@
+package blockstool
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+)
+
+func TestMigrateLayout(t *testing.T) {
+	ctx := context.Background()
+	objects := []string{"user-1/block-1/meta.json", "user-1/block-1/index", "user-2/block-1/meta.json", "user-2/bucket-index.json.gz"}
+
+	// The source and target buckets share the same underlying bucket.
+	raw := objstore.NewInMemBucket()
+	src := raw
+	dst := bucket.NewHashedTenantBucketClient(raw, 0)
+
+	for _, name := range objects {
+		require.NoError(t, src.Upload(ctx, name, strings.NewReader(name)))
+	}
+
+	copied, err := MigrateLayout(ctx, src, dst, 0, log.NewNopLogger())
+	require.NoError(t, err)
+	assert.Equal(t, len(objects), copied)
+
+	for _, name := range objects {
+		r, err := dst.Get(ctx, name)
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, name, string(content))
+	}
+
+	// Running the migration again should not copy any object, nor copy the already migrated
+	// objects listed by the source bucket.
+	copied, err = MigrateLayout(ctx, src, dst, 0, log.NewNopLogger())
+	require.NoError(t, err)
+	assert.Equal(t, 0, copied)
+	assert.Len(t, raw.Objects(), 2*len(objects))
+}